@@ -26,15 +26,15 @@ describe('AuthProvider', () => {
   });
 
   it('refreshes token on mount (success) and sets user', async () => {
-    // seed cookie to trigger refresh
-    document.cookie = 'token=seed';
+    // seed the refresh token to trigger the refresh
+    document.cookie = 'refresh_token=seed';
     render(<Probe />);
     await waitFor(() => expect(screen.getByTestId('has-user').textContent).toBe('yes'));
     expect(screen.getByTestId('token').textContent).not.toBe('');
   });
 
   it('refresh token failure logs out', async () => {
-    document.cookie = 'token=seed';
+    document.cookie = 'refresh_token=seed';
     server.use(
       http.post(`${API_BASE}/auth/refresh-token`, () => HttpResponse.json({ message: 'nope' }, { status: 401 }))
     );
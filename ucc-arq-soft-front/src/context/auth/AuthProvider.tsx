@@ -28,6 +28,19 @@ export const AuthProvider: FC<AuthProviderProps> = ({ children }) => {
     refreshToken();
   }, []);
 
+  // the refresh token is what restores the session on the next visit
+  const saveSession = (data: AuthResponse) => {
+    cookies.set("token", data.token);
+    if (data.refresh_token) {
+      cookies.set("refresh_token", data.refresh_token, { sameSite: "strict" });
+    }
+  };
+
+  const clearSession = () => {
+    cookies.remove("token");
+    cookies.remove("refresh_token");
+  };
+
   const register = async (registerDto: RegisterDto): Promise<boolean> => {
     try {
       if (
@@ -51,7 +64,7 @@ export const AuthProvider: FC<AuthProviderProps> = ({ children }) => {
       );
       if (reponse.ok) {
         const data: AuthResponse = await reponse.json();
-        cookies.set("token", data.token);
+        saveSession(data);
         dispatch({
           type: "[Auth] - Login",
           payload: { userData: data.user, token: data.token },
@@ -62,7 +75,7 @@ export const AuthProvider: FC<AuthProviderProps> = ({ children }) => {
     } catch (error) {
       console.log(error);
       showToast("An error occurred", "error");
-      cookies.remove("token");
+      clearSession();
       return false;
     }
   };
@@ -87,21 +100,21 @@ export const AuthProvider: FC<AuthProviderProps> = ({ children }) => {
           type: "[Auth] - Login",
           payload: { userData: data.user, token: data.token },
         });
-        cookies.set("token", data.token);
+        saveSession(data);
         return true;
       }
       return false;
     } catch (error) {
       console.error(error);
       showToast("An error occurred", "error");
-      cookies.remove("token");
+      clearSession();
       return false;
     }
   };
 
   const refreshToken = async () => {
-    const token = cookies.get("token");
-    if (!token) {
+    const refresh = cookies.get("refresh_token");
+    if (!refresh) {
       return;
     }
     try {
@@ -110,17 +123,20 @@ export const AuthProvider: FC<AuthProviderProps> = ({ children }) => {
         {
           method: "POST",
           headers: {
-            Authorization: `Bearer ${token}`,
+            "Content-Type": "application/json",
           },
+          body: JSON.stringify({ refresh_token: refresh }),
         }
       );
       if (reponse.ok) {
         const data: AuthResponse = await reponse.json();
+        saveSession(data);
         dispatch({
           type: "[Auth] - Login",
           payload: { userData: data.user, token: data.token },
         });
       } else {
+        clearSession();
         dispatch({ type: "[Auth] - Logout" });
       }
     } catch (error) {
@@ -129,7 +145,7 @@ export const AuthProvider: FC<AuthProviderProps> = ({ children }) => {
   };
 
   const logout = () => {
-    cookies.remove("token");
+    clearSession();
     dispatch({ type: "[Auth] - Logout" });
   };
 
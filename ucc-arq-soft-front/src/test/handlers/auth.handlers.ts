@@ -11,6 +11,7 @@ export const handlers = [
     return HttpResponse.json(
       {
         token: 'test-token',
+        refresh_token: 'test-refresh-token',
         user: {
           id: 'u1',
           email: body.Email,
@@ -33,6 +34,7 @@ export const handlers = [
     return HttpResponse.json(
       {
         token: 'test-token',
+        refresh_token: 'test-refresh-token',
         user: {
           id: 'u2',
           email: body.Email,
@@ -47,10 +49,15 @@ export const handlers = [
     );
   }),
 
-  http.post(`${API_BASE}/auth/refresh-token`, () =>
-    HttpResponse.json(
+  http.post(`${API_BASE}/auth/refresh-token`, async ({ request }) => {
+    const body = await request.json().catch(() => ({} as any));
+    if (!body?.refresh_token) {
+      return HttpResponse.json({ message: 'refresh_token is required' }, { status: 400 });
+    }
+    return HttpResponse.json(
       {
         token: 'refreshed-token',
+        refresh_token: 'rotated-refresh-token',
         user: {
           id: 'u1',
           email: 'test@example.com',
@@ -62,6 +69,6 @@ export const handlers = [
         },
       },
       { status: 200 }
-    )
-  ),
+    );
+  }),
 ];
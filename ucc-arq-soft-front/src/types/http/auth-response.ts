@@ -5,4 +5,5 @@ export type AuthResponse = {
     message: string; 
     user: User
     token: string;
+    refresh_token?: string;
 }
\ No newline at end of file
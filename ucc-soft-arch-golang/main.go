@@ -5,9 +5,11 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/adapter"
 	"github.com/Guidotss/ucc-soft-arch-golang.git/src/config"
 	middlewares "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware"
 	"github.com/Guidotss/ucc-soft-arch-golang.git/src/routes"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
@@ -22,16 +24,31 @@ func main() {
 	corsConfig := cors.DefaultConfig()
 	corsConfig.AllowAllOrigins = true
 	corsConfig.AllowMethods = []string{"POST", "GET", "PUT", "OPTIONS", "DELETE"}
-	corsConfig.AllowHeaders = []string{"Content-Type", "Authorization"}
+	corsConfig.AllowHeaders = []string{"Content-Type", "Authorization", "X-API-Key"}
 	corsConfig.ExposeHeaders = []string{"Content-Length"}
 	corsConfig.AllowCredentials = true
 	corsConfig.MaxAge = 12 * time.Hour
 
+	// Cargar las claves de firma de los tokens antes de atender requests
+	_, signingKeyService := adapter.SigningKeyAdapter(db)
+	if err := signingKeyService.Rotate(); err != nil {
+		panic(err)
+	}
+	go services.RunSigningKeyRotation(signingKeyService, config.GetDuration(envs, "JWT_KEY_CHECK_INTERVAL", time.Hour), nil)
+
 	router := gin.Default()
 	router.Use(cors.New(corsConfig))
 	router.Use(middlewares.ErrorHandler())
 	routes.AppRoutes(router, db)
 
+	// Limpiar periodicamente la denylist de tokens revocados ya expirados
+	_, revocationService := adapter.TokenAdapter(db)
+	go services.RunRevocationCleanup(revocationService, config.GetDuration(envs, "REVOCATION_CLEANUP_INTERVAL", time.Hour), nil)
+
+	// Borrar las cuentas cuyo periodo de gracia ya termino
+	_, privacyService := adapter.PrivacyAdapter(db)
+	go services.RunAccountDeletion(privacyService, config.GetDuration(envs, "ACCOUNT_DELETION_INTERVAL", time.Hour), nil)
+
 	// Iniciar el servidor
 	startServer(router, envs)
 }
@@ -16,7 +16,7 @@ func setupDB(t *testing.T) *gorm.DB {
 		t.Fatalf("failed to open sqlite db: %v", err)
 	}
 	// migrate commonly used tables to ensure relationships exist if exercised
-	require.NoError(t, db.AutoMigrate(&model.User{}, &model.Category{}, &model.Course{}, &model.Comment{}, &model.Rating{}, &model.Inscripto{}))
+	require.NoError(t, db.AutoMigrate(&model.User{}, &model.Category{}, &model.Course{}, &model.Comment{}, &model.Rating{}, &model.Inscripto{}, &model.Cohort{}))
 	return db
 }
 
@@ -29,8 +29,9 @@ func TestUserAdapter(t *testing.T) {
 
 func TestCourseAdapter(t *testing.T) {
 	db := setupDB(t)
-	ctrl := CourseAdapter(db)
+	ctrl, svc := CourseAdapter(db)
 	require.NotNil(t, ctrl)
+	require.NotNil(t, svc)
 }
 
 func TestCategoryAdapter(t *testing.T) {
@@ -52,6 +53,19 @@ func TestInscriptionsAdapter(t *testing.T) {
 	require.NotNil(t, svc)
 }
 
+func TestOIDCAdapter(t *testing.T) {
+	db := setupDB(t)
+	ctrl := OIDCAdapter(db)
+	require.NotNil(t, ctrl)
+}
+
+func TestTwoFactorAdapter(t *testing.T) {
+	db := setupDB(t)
+	ctrl, svc := TwoFactorAdapter(db)
+	require.NotNil(t, ctrl)
+	require.NotNil(t, svc)
+}
+
 func TestAuthAdapter(t *testing.T) {
 	db := setupDB(t)
 	ctrl := AuthAdapter(db)
@@ -63,3 +77,116 @@ func TestRatingAdapter(t *testing.T) {
 	ctrl := RatingAdapter(db)
 	require.NotNil(t, ctrl)
 }
+
+func TestPasswordResetAdapter(t *testing.T) {
+	db := setupDB(t)
+	ctrl, svc := PasswordResetAdapter(db)
+	require.NotNil(t, ctrl)
+	require.NotNil(t, svc)
+}
+
+func TestEmailVerificationAdapter(t *testing.T) {
+	db := setupDB(t)
+	ctrl, svc := EmailVerificationAdapter(db)
+	require.NotNil(t, ctrl)
+	require.NotNil(t, svc)
+}
+
+func TestSecurityAdapter(t *testing.T) {
+	db := setupDB(t)
+	ctrl := SecurityAdapter(db)
+	require.NotNil(t, ctrl)
+}
+
+func TestAdminUsersAdapter(t *testing.T) {
+	db := setupDB(t)
+	ctrl, svc := AdminUsersAdapter(db)
+	require.NotNil(t, ctrl)
+	require.NotNil(t, svc)
+}
+
+func TestPrivacyAdapter(t *testing.T) {
+	db := setupDB(t)
+	ctrl, svc := PrivacyAdapter(db)
+	require.NotNil(t, ctrl)
+	require.NotNil(t, svc)
+}
+
+func TestPermissionAdapter(t *testing.T) {
+	db := setupDB(t)
+	require.NotNil(t, PermissionAdapter(db))
+}
+
+func TestAPIKeyAdapter(t *testing.T) {
+	db := setupDB(t)
+	ctrl, svc := APIKeyAdapter(db)
+	require.NotNil(t, ctrl)
+	require.NotNil(t, svc)
+}
+
+func TestSigningKeyAdapter(t *testing.T) {
+	db := setupDB(t)
+	ctrl, svc := SigningKeyAdapter(db)
+	require.NotNil(t, ctrl)
+	require.NotNil(t, svc)
+}
+
+func TestSessionAdapter(t *testing.T) {
+	db := setupDB(t)
+	ctrl, svc := SessionAdapter(db)
+	require.NotNil(t, ctrl)
+	require.NotNil(t, svc)
+}
+
+func TestPasswordPolicyAdapter(t *testing.T) {
+	require.NotNil(t, PasswordPolicyAdapter(setupDB(t)))
+}
+
+func TestImpersonationAdapter(t *testing.T) {
+	db := setupDB(t)
+	ctrl, svc := ImpersonationAdapter(db)
+	require.NotNil(t, ctrl)
+	require.NotNil(t, svc)
+}
+
+func TestSearchAdapter(t *testing.T) {
+	db := setupDB(t)
+	ctrl, svc := SearchAdapter(db)
+	require.NotNil(t, ctrl)
+	require.NotNil(t, svc)
+}
+
+func TestCurriculumAdapter(t *testing.T) {
+	db := setupDB(t)
+	ctrl, svc := CurriculumAdapter(db)
+	require.NotNil(t, ctrl)
+	require.NotNil(t, svc)
+}
+
+func TestCohortAdapter(t *testing.T) {
+	db := setupDB(t)
+	ctrl, svc := CohortAdapter(db)
+	require.NotNil(t, ctrl)
+	require.NotNil(t, svc)
+}
+
+func TestProgressAdapter(t *testing.T) {
+	db := setupDB(t)
+	ctrl, svc := ProgressAdapter(db)
+	require.NotNil(t, ctrl)
+	require.NotNil(t, svc)
+}
+
+func TestPrerequisitesAdapter(t *testing.T) {
+	db := setupDB(t)
+	ctrl, svc := PrerequisitesAdapter(db)
+	require.NotNil(t, ctrl)
+	require.NotNil(t, svc)
+}
+
+func TestCourseLifecycleAdapter(t *testing.T) {
+	db := setupDB(t)
+	ctrl, svc := CourseLifecycleAdapter(db)
+	require.NotNil(t, ctrl)
+	require.NotNil(t, svc)
+}
@@ -0,0 +1,20 @@
+package adapter
+
+import (
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/users"
+	controller "github.com/Guidotss/ucc-soft-arch-golang.git/src/controllers/admin"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"gorm.io/gorm"
+)
+
+func AdminUsersAdapter(db *gorm.DB) (*controller.UsersController, services.IUserAdminService) {
+	_, revocationService := TokenAdapter(db)
+	_, passwordResetService := PasswordResetAdapter(db)
+	service := services.NewUserAdminService(
+		users.NewUsersClient(db),
+		PermissionAdapter(db),
+		revocationService,
+		passwordResetService,
+		AuditAdapter(db))
+	return controller.NewUsersController(service), service
+}
@@ -0,0 +1,18 @@
+package adapter
+
+import (
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/apikeys"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/users"
+	controller "github.com/Guidotss/ucc-soft-arch-golang.git/src/controllers/admin"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"gorm.io/gorm"
+)
+
+func APIKeyAdapter(db *gorm.DB) (*controller.APIKeysController, services.IAPIKeyService) {
+	service := services.NewAPIKeyService(
+		apikeys.NewAPIKeysClient(db),
+		users.NewUsersClient(db),
+		PermissionAdapter(db),
+		AuditAdapter(db))
+	return controller.NewAPIKeysController(service), service
+}
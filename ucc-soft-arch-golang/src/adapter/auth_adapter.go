@@ -9,7 +9,9 @@ import (
 
 func AuthAdapter(Db *gorm.DB) *controller.AuthController {
 	client := users.NewUsersClient(Db)
-	userService := services.NewUserService(client)
-	authService := services.NewAuthService(&userService, client)
+	tokenService, revocationService := TokenAdapter(Db)
+	userService := services.NewUserService(client, revocationService, PasswordPolicyAdapter(Db))
+	_, twoFactorService := TwoFactorAdapter(Db)
+	authService := services.NewAuthService(&userService, client, tokenService, revocationService, LoginThrottleAdapter(Db), twoFactorService)
 	return controller.NewAuthController(&authService)
 }
@@ -0,0 +1,14 @@
+package adapter
+
+import (
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/cohorts"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/inscriptos"
+	controllers "github.com/Guidotss/ucc-soft-arch-golang.git/src/controllers/courses"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"gorm.io/gorm"
+)
+
+func CohortAdapter(db *gorm.DB) (*controllers.CohortController, services.ICohortService) {
+	service := services.NewCohortService(cohorts.NewCohortsClient(db), inscriptos.NewInscriptionClient(db))
+	return controllers.NewCohortController(service), service
+}
@@ -2,13 +2,14 @@ package adapter
 
 import (
 	client "github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/courses"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/inscriptos"
 	controllers "github.com/Guidotss/ucc-soft-arch-golang.git/src/controllers/courses"
 	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
 	"gorm.io/gorm"
 )
 
-func CourseAdapter(db *gorm.DB) *controllers.CourseController {
+func CourseAdapter(db *gorm.DB) (*controllers.CourseController, services.ICourseService) {
 	client := client.NewCourseClient(db)
-	service := services.NewCourseService(client)
-	return controllers.NewCourseController(service)
+	service := services.NewCourseService(client, inscriptos.NewInscriptionClient(db))
+	return controllers.NewCourseController(service), service
 }
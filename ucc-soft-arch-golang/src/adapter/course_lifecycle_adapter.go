@@ -0,0 +1,13 @@
+package adapter
+
+import (
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/courses"
+	controllers "github.com/Guidotss/ucc-soft-arch-golang.git/src/controllers/courses"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"gorm.io/gorm"
+)
+
+func CourseLifecycleAdapter(db *gorm.DB) (*controllers.LifecycleController, services.ICourseLifecycleService) {
+	service := services.NewCourseLifecycleService(courses.NewCourseClient(db), PermissionAdapter(db))
+	return controllers.NewLifecycleController(service), service
+}
@@ -0,0 +1,13 @@
+package adapter
+
+import (
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/curriculum"
+	controllers "github.com/Guidotss/ucc-soft-arch-golang.git/src/controllers/courses"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"gorm.io/gorm"
+)
+
+func CurriculumAdapter(db *gorm.DB) (*controllers.CurriculumController, services.ICurriculumService) {
+	service := services.NewCurriculumService(curriculum.NewCurriculumClient(db))
+	return controllers.NewCurriculumController(service), service
+}
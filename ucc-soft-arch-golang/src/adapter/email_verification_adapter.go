@@ -0,0 +1,19 @@
+package adapter
+
+import (
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/emailverification"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/users"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/config"
+	controller "github.com/Guidotss/ucc-soft-arch-golang.git/src/controllers/auth"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/mailer"
+	"gorm.io/gorm"
+)
+
+func EmailVerificationAdapter(db *gorm.DB) (*controller.EmailVerificationController, services.IEmailVerificationService) {
+	service := services.NewEmailVerificationService(
+		users.NewUsersClient(db),
+		emailverification.NewEmailVerificationClient(db),
+		mailer.NewFromEnv(config.LoadEnvs(".env")))
+	return controller.NewEmailVerificationController(service), service
+}
@@ -0,0 +1,18 @@
+package adapter
+
+import (
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/impersonations"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/users"
+	controller "github.com/Guidotss/ucc-soft-arch-golang.git/src/controllers/admin"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"gorm.io/gorm"
+)
+
+func ImpersonationAdapter(db *gorm.DB) (*controller.ImpersonationController, services.IImpersonationService) {
+	service := services.NewImpersonationService(
+		impersonations.NewImpersonationsClient(db),
+		users.NewUsersClient(db),
+		PermissionAdapter(db),
+		AuditAdapter(db))
+	return controller.NewImpersonationController(service), service
+}
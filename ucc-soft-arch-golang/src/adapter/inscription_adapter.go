@@ -2,6 +2,7 @@ package adapter
 
 import (
 	client "github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/inscriptos"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/progress"
 	controllers "github.com/Guidotss/ucc-soft-arch-golang.git/src/controllers/inscriptions"
 	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
 	"gorm.io/gorm"
@@ -9,6 +10,6 @@ import (
 
 func InscriptionsAdapter(db *gorm.DB) (*controllers.InscriptionController, services.IInscriptionService) {
 	client := client.NewInscriptionClient(db)
-	service := services.NewInscriptionService(client)
+	service := services.NewInscriptionService(client, progress.NewProgressClient(db))
 	return controllers.NewInscriptionController(service), service
 }
@@ -0,0 +1,37 @@
+package adapter
+
+import (
+	"strings"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/identities"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/oauthstates"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/users"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/config"
+	controller "github.com/Guidotss/ucc-soft-arch-golang.git/src/controllers/auth"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/oidc"
+	"gorm.io/gorm"
+)
+
+func OIDCAdapter(db *gorm.DB) *controller.OIDCController {
+	envs := config.LoadEnvs(".env")
+	frontend := envs.Get("FRONTEND_URL")
+	if frontend == "" {
+		frontend = services.DefaultFrontendURL
+	}
+
+	var provider *oidc.Provider
+	if cfg, enabled := oidc.ConfigFromEnv(envs, strings.TrimRight(frontend, "/")+"/auth/oidc/callback"); enabled {
+		provider = oidc.NewProvider(cfg)
+	}
+	tokenService, _ := TokenAdapter(db)
+	_, twoFactorService := TwoFactorAdapter(db)
+	service := services.NewOIDCService(
+		provider,
+		users.NewUsersClient(db),
+		identities.NewIdentitiesClient(db),
+		oauthstates.NewOAuthStatesClient(db),
+		tokenService,
+		twoFactorService)
+	return controller.NewOIDCController(service)
+}
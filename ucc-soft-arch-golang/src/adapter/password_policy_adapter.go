@@ -0,0 +1,14 @@
+package adapter
+
+import (
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/passwordhistory"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/users"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"gorm.io/gorm"
+)
+
+func PasswordPolicyAdapter(db *gorm.DB) services.IPasswordPolicyService {
+	return services.NewPasswordPolicyService(
+		passwordhistory.NewPasswordHistoryClient(db),
+		users.NewUsersClient(db))
+}
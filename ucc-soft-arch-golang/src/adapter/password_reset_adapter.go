@@ -0,0 +1,22 @@
+package adapter
+
+import (
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/passwordreset"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/users"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/config"
+	controller "github.com/Guidotss/ucc-soft-arch-golang.git/src/controllers/auth"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/mailer"
+	"gorm.io/gorm"
+)
+
+func PasswordResetAdapter(db *gorm.DB) (*controller.PasswordResetController, services.IPasswordResetService) {
+	_, revocationService := TokenAdapter(db)
+	service := services.NewPasswordResetService(
+		users.NewUsersClient(db),
+		passwordreset.NewPasswordResetClient(db),
+		mailer.NewFromEnv(config.LoadEnvs(".env")),
+		revocationService,
+		PasswordPolicyAdapter(db))
+	return controller.NewPasswordResetController(service), service
+}
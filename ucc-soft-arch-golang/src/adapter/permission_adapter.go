@@ -0,0 +1,11 @@
+package adapter
+
+import (
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/roles"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"gorm.io/gorm"
+)
+
+func PermissionAdapter(db *gorm.DB) services.IPermissionService {
+	return services.NewPermissionService(roles.NewRolesClient(db))
+}
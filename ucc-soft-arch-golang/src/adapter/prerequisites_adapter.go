@@ -0,0 +1,13 @@
+package adapter
+
+import (
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/prerequisites"
+	controllers "github.com/Guidotss/ucc-soft-arch-golang.git/src/controllers/courses"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"gorm.io/gorm"
+)
+
+func PrerequisitesAdapter(db *gorm.DB) (*controllers.PrerequisitesController, services.IPrerequisiteService) {
+	service := services.NewPrerequisiteService(prerequisites.NewPrerequisitesClient(db))
+	return controllers.NewPrerequisitesController(service), service
+}
@@ -0,0 +1,17 @@
+package adapter
+
+import (
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/privacy"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/users"
+	controller "github.com/Guidotss/ucc-soft-arch-golang.git/src/controllers/users"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"gorm.io/gorm"
+)
+
+func PrivacyAdapter(db *gorm.DB) (*controller.PrivacyController, services.IPrivacyService) {
+	service := services.NewPrivacyService(
+		privacy.NewPrivacyClient(db),
+		users.NewUsersClient(db),
+		AuditAdapter(db))
+	return controller.NewPrivacyController(service), service
+}
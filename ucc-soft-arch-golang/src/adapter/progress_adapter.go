@@ -0,0 +1,15 @@
+package adapter
+
+import (
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/curriculum"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/inscriptos"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/progress"
+	controllers "github.com/Guidotss/ucc-soft-arch-golang.git/src/controllers/inscriptions"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"gorm.io/gorm"
+)
+
+func ProgressAdapter(db *gorm.DB) (*controllers.ProgressController, services.IProgressService) {
+	service := services.NewProgressService(progress.NewProgressClient(db), curriculum.NewCurriculumClient(db), inscriptos.NewInscriptionClient(db))
+	return controllers.NewProgressController(service), service
+}
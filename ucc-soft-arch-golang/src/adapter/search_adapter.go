@@ -0,0 +1,14 @@
+package adapter
+
+import (
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/courses"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/search"
+	controllers "github.com/Guidotss/ucc-soft-arch-golang.git/src/controllers/courses"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"gorm.io/gorm"
+)
+
+func SearchAdapter(db *gorm.DB) (*controllers.SearchController, services.ISearchService) {
+	service := services.NewSearchService(search.NewSearchClient(db), courses.NewCourseClient(db))
+	return controllers.NewSearchController(service), service
+}
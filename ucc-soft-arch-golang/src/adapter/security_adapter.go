@@ -0,0 +1,26 @@
+package adapter
+
+import (
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/audit"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/loginattempts"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/users"
+	controller "github.com/Guidotss/ucc-soft-arch-golang.git/src/controllers/admin"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"gorm.io/gorm"
+)
+
+func AuditAdapter(db *gorm.DB) services.IAuditService {
+	return services.NewAuditService(audit.NewAuditClient(db))
+}
+
+func LoginThrottleAdapter(db *gorm.DB) services.ILoginThrottleService {
+	return services.NewLoginThrottleService(
+		loginattempts.NewLoginAttemptsClient(db),
+		users.NewUsersClient(db),
+		AuditAdapter(db))
+}
+
+func SecurityAdapter(db *gorm.DB) *controller.SecurityController {
+	_, twoFactorService := TwoFactorAdapter(db)
+	return controller.NewSecurityController(LoginThrottleAdapter(db), AuditAdapter(db), twoFactorService)
+}
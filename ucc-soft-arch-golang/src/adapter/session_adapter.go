@@ -0,0 +1,13 @@
+package adapter
+
+import (
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/sessions"
+	controller "github.com/Guidotss/ucc-soft-arch-golang.git/src/controllers/auth"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"gorm.io/gorm"
+)
+
+func SessionAdapter(db *gorm.DB) (*controller.SessionsController, services.ISessionService) {
+	service := services.NewSessionService(sessions.NewSessionsClient(db), AuditAdapter(db))
+	return controller.NewSessionsController(service), service
+}
@@ -0,0 +1,13 @@
+package adapter
+
+import (
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/signingkeys"
+	controller "github.com/Guidotss/ucc-soft-arch-golang.git/src/controllers/auth"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"gorm.io/gorm"
+)
+
+func SigningKeyAdapter(db *gorm.DB) (*controller.JWKSController, services.ISigningKeyService) {
+	service := services.NewSigningKeyService(signingkeys.NewSigningKeysClient(db))
+	return controller.NewJWKSController(service), service
+}
@@ -0,0 +1,26 @@
+package adapter
+
+import (
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/revocation"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/sessions"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/tokens"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/users"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"gorm.io/gorm"
+)
+
+// TokenAdapter builds the services the auth middlewares depend on.
+func TokenAdapter(db *gorm.DB) (services.ITokenService, services.IRevocationService) {
+	refreshClient := tokens.NewRefreshTokensClient(db)
+	sessionsClient := sessions.NewSessionsClient(db)
+	usersClient := users.NewUsersClient(db)
+	revocationService := services.NewRevocationService(
+		revocation.NewRevocationClient(db),
+		usersClient,
+		refreshClient,
+		sessionsClient)
+	_, apiKeyService := APIKeyAdapter(db)
+	_, sessionService := SessionAdapter(db)
+	_, impersonationService := ImpersonationAdapter(db)
+	return services.NewTokenService(refreshClient, usersClient, revocationService, apiKeyService, sessionService, impersonationService), revocationService
+}
@@ -0,0 +1,20 @@
+package adapter
+
+import (
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/twofactor"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/users"
+	controller "github.com/Guidotss/ucc-soft-arch-golang.git/src/controllers/auth"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"gorm.io/gorm"
+)
+
+func TwoFactorAdapter(db *gorm.DB) (*controller.TwoFactorController, services.ITwoFactorService) {
+	tokenService, _ := TokenAdapter(db)
+	service := services.NewTwoFactorService(
+		twofactor.NewTwoFactorClient(db),
+		users.NewUsersClient(db),
+		tokenService,
+		AuditAdapter(db),
+		LoginThrottleAdapter(db))
+	return controller.NewTwoFactorController(service), service
+}
@@ -9,6 +9,8 @@ import (
 
 func UserAdapter(db *gorm.DB) (*controllers.UsersController, services.IUserService) {
 	client := users.NewUsersClient(db)
-	service := services.NewUserService(client)
-	return controllers.NewUserController(service), service
+	tokenService, revocationService := TokenAdapter(db)
+	service := services.NewUserService(client, revocationService, PasswordPolicyAdapter(db))
+	_, verificationService := EmailVerificationAdapter(db)
+	return controllers.NewUserController(service, tokenService, verificationService), service
 }
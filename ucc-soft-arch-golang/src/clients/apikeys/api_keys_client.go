@@ -0,0 +1,89 @@
+package apikeys
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type APIKeysClient struct {
+	Db *gorm.DB
+}
+
+func NewAPIKeysClient(db *gorm.DB) *APIKeysClient {
+	return &APIKeysClient{Db: db}
+}
+
+func (c *APIKeysClient) Create(key model.APIKey) (model.APIKey, error) {
+	if err := c.Db.Create(&key).Error; err != nil {
+		return model.APIKey{}, customError.DBError(err)
+	}
+	return key, nil
+}
+
+func (c *APIKeysClient) FindById(id uuid.UUID) (model.APIKey, error) {
+	var key model.APIKey
+	err := c.Db.Where("id = ?", id).First(&key).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.APIKey{}, customError.NewError("NOT_FOUND", "API key not found", http.StatusNotFound)
+		}
+		return model.APIKey{}, customError.DBError(err)
+	}
+	return key, nil
+}
+
+func (c *APIKeysClient) FindByHash(hash string) (model.APIKey, error) {
+	var key model.APIKey
+	err := c.Db.Where("key_hash = ?", hash).First(&key).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.APIKey{}, customError.NewError("NOT_FOUND", "API key not found", http.StatusNotFound)
+		}
+		return model.APIKey{}, customError.DBError(err)
+	}
+	return key, nil
+}
+
+// List returns every key, newest first. A nil ownerId lists the keys of all
+// owners.
+func (c *APIKeysClient) List(ownerId uuid.UUID) (model.APIKeys, error) {
+	var keys model.APIKeys
+	query := c.Db.Order("created_at DESC")
+	if ownerId != uuid.Nil {
+		query = query.Where("owner_id = ?", ownerId)
+	}
+	if err := query.Find(&keys).Error; err != nil {
+		return nil, customError.DBError(err)
+	}
+	return keys, nil
+}
+
+// Revoke reports false when the key was already revoked.
+func (c *APIKeysClient) Revoke(id uuid.UUID, at time.Time) (bool, error) {
+	result := c.Db.Model(&model.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at)
+	if result.Error != nil {
+		return false, customError.DBError(result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// TouchLastUsed records a use of the key. The row is only written when the
+// stored value is older than before, so busy keys don't cause a write on
+// every request.
+func (c *APIKeysClient) TouchLastUsed(id uuid.UUID, at time.Time, before time.Time) error {
+	result := c.Db.Model(&model.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, before).
+		Update("last_used_at", at)
+	if result.Error != nil {
+		return customError.DBError(result.Error)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package apikeys
+
+import (
+	"testing"
+	"time"
+
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	github_com_glebarez_sqlite "github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func makeDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(github_com_glebarez_sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	require.NoError(t, db.AutoMigrate(&model.APIKey{}))
+	return db
+}
+
+func TestAPIKeysClient_CreateFindAndRevoke(t *testing.T) {
+	c := NewAPIKeysClient(makeDB(t))
+	ownerId := uuid.New()
+	created, err := c.Create(model.APIKey{Name: "reports", Prefix: "ucc_abcd1234", KeyHash: "hash-1", OwnerId: ownerId, Scopes: "audit:read"})
+	require.NoError(t, err)
+	require.NotEqual(t, uuid.Nil, created.Id)
+
+	found, err := c.FindByHash("hash-1")
+	require.NoError(t, err)
+	require.Equal(t, created.Id, found.Id)
+	_, err = c.FindByHash("other")
+	require.Equal(t, "NOT_FOUND", err.(*customError.Error).Code)
+
+	revoked, err := c.Revoke(created.Id, time.Now())
+	require.NoError(t, err)
+	require.True(t, revoked)
+	revoked, err = c.Revoke(created.Id, time.Now())
+	require.NoError(t, err)
+	require.False(t, revoked)
+}
+
+func TestAPIKeysClient_List(t *testing.T) {
+	c := NewAPIKeysClient(makeDB(t))
+	ownerId := uuid.New()
+	_, err := c.Create(model.APIKey{Name: "a", Prefix: "ucc_a", KeyHash: "a", OwnerId: ownerId})
+	require.NoError(t, err)
+	_, err = c.Create(model.APIKey{Name: "b", Prefix: "ucc_b", KeyHash: "b", OwnerId: uuid.New()})
+	require.NoError(t, err)
+
+	all, err := c.List(uuid.Nil)
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	mine, err := c.List(ownerId)
+	require.NoError(t, err)
+	require.Len(t, mine, 1)
+	require.Equal(t, "a", mine[0].Name)
+}
+
+func TestAPIKeysClient_TouchLastUsed(t *testing.T) {
+	c := NewAPIKeysClient(makeDB(t))
+	key, err := c.Create(model.APIKey{Name: "a", Prefix: "ucc_a", KeyHash: "a"})
+	require.NoError(t, err)
+
+	first := time.Now().Truncate(time.Second)
+	require.NoError(t, c.TouchLastUsed(key.Id, first, first.Add(-time.Minute)))
+	// a second use inside the window is not written
+	require.NoError(t, c.TouchLastUsed(key.Id, first.Add(10*time.Second), first.Add(-50*time.Second)))
+
+	stored, err := c.FindById(key.Id)
+	require.NoError(t, err)
+	require.NotNil(t, stored.LastUsedAt)
+	require.True(t, stored.LastUsedAt.Equal(first))
+}
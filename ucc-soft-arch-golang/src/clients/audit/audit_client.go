@@ -0,0 +1,40 @@
+package audit
+
+import (
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AuditClient struct {
+	Db *gorm.DB
+}
+
+func NewAuditClient(db *gorm.DB) *AuditClient {
+	return &AuditClient{Db: db}
+}
+
+func (c *AuditClient) Create(entry model.AuditLog) (model.AuditLog, error) {
+	result := c.Db.Create(&entry)
+	if result.Error != nil {
+		return model.AuditLog{}, customError.DBError(result.Error)
+	}
+	return entry, nil
+}
+
+// List returns the newest entries first. Empty filters are ignored.
+func (c *AuditClient) List(event string, userId uuid.UUID, limit int) (model.AuditLogs, error) {
+	var entries model.AuditLogs
+	query := c.Db.Order("created_at DESC, id DESC").Limit(limit)
+	if event != "" {
+		query = query.Where("event = ?", event)
+	}
+	if userId != uuid.Nil {
+		query = query.Where("user_id = ?", userId)
+	}
+	if err := query.Find(&entries).Error; err != nil {
+		return nil, customError.DBError(err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,42 @@
+package audit
+
+import (
+	"testing"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	github_com_glebarez_sqlite "github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestAuditClient_CreateAndList(t *testing.T) {
+	db, err := gorm.Open(github_com_glebarez_sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	require.NoError(t, db.AutoMigrate(&model.AuditLog{}))
+	c := NewAuditClient(db)
+
+	user := uuid.New()
+	_, err = c.Create(model.AuditLog{Event: "login_locked", UserId: user, Email: "a@b.com"})
+	require.NoError(t, err)
+	_, err = c.Create(model.AuditLog{Event: "account_unlocked", UserId: user})
+	require.NoError(t, err)
+	_, err = c.Create(model.AuditLog{Event: "login_locked", Ip: "10.0.0.1"})
+	require.NoError(t, err)
+
+	all, err := c.List("", uuid.Nil, 10)
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	require.Equal(t, "login_locked", all[0].Event)
+	require.Equal(t, "10.0.0.1", all[0].Ip)
+
+	locked, err := c.List("login_locked", uuid.Nil, 10)
+	require.NoError(t, err)
+	require.Len(t, locked, 2)
+
+	forUser, err := c.List("", user, 10)
+	require.NoError(t, err)
+	require.Len(t, forUser, 2)
+}
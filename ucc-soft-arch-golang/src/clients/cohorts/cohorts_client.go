@@ -0,0 +1,114 @@
+package cohorts
+
+import (
+	"errors"
+	"net/http"
+
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CohortsClient struct {
+	Db *gorm.DB
+}
+
+func NewCohortsClient(db *gorm.DB) *CohortsClient {
+	return &CohortsClient{Db: db}
+}
+
+func (c *CohortsClient) CourseExists(courseId uuid.UUID) (bool, error) {
+	var count int64
+	if err := c.Db.Model(&model.Course{}).Where("id = ?", courseId).Count(&count).Error; err != nil {
+		return false, customError.DBError(err)
+	}
+	return count > 0, nil
+}
+
+// GetCohorts returns the cohorts of a course, the earliest first.
+func (c *CohortsClient) GetCohorts(courseId uuid.UUID) (model.Cohorts, error) {
+	cohorts := model.Cohorts{}
+	err := c.Db.Where("course_id = ?", courseId).
+		Order("starts_at, created_at").
+		Find(&cohorts).Error
+	if err != nil {
+		return nil, customError.DBError(err)
+	}
+	return cohorts, nil
+}
+
+func (c *CohortsClient) GetCohort(courseId uuid.UUID, cohortId uuid.UUID) (model.Cohort, error) {
+	var cohort model.Cohort
+	err := c.Db.Where("id = ? AND course_id = ?", cohortId, courseId).First(&cohort).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.Cohort{}, customError.NewError("COHORT_NOT_FOUND", "Cohort not found", http.StatusNotFound)
+		}
+		return model.Cohort{}, customError.DBError(err)
+	}
+	return cohort, nil
+}
+
+func (c *CohortsClient) CreateCohort(cohort model.Cohort) (model.Cohort, error) {
+	if err := c.Db.Create(&cohort).Error; err != nil {
+		return model.Cohort{}, customError.DBError(err)
+	}
+	return cohort, nil
+}
+
+func (c *CohortsClient) UpdateCohort(cohort model.Cohort) (model.Cohort, error) {
+	err := c.Db.Model(&model.Cohort{}).Where("id = ?", cohort.Id).Updates(map[string]interface{}{
+		"name":                 cohort.Name,
+		"starts_at":            cohort.StartsAt,
+		"ends_at":              cohort.EndsAt,
+		"time_zone":            cohort.TimeZone,
+		"enrollment_opens_at":  cohort.EnrollmentOpensAt,
+		"enrollment_closes_at": cohort.EnrollmentClosesAt,
+		"capacity":             cohort.Capacity,
+	}).Error
+	if err != nil {
+		return model.Cohort{}, customError.DBError(err)
+	}
+	return cohort, nil
+}
+
+// DeleteCohort deletes a cohort nobody is enrolled in or waiting for.
+func (c *CohortsClient) DeleteCohort(cohort model.Cohort) error {
+	var enrollments int64
+	err := c.Db.Model(&model.Inscripto{}).Where("cohort_id = ?", cohort.Id).Count(&enrollments).Error
+	if err != nil {
+		return customError.DBError(err)
+	}
+	if enrollments > 0 {
+		return customError.NewError("COHORT_HAS_ENROLLMENTS", "A cohort with students can't be deleted", http.StatusConflict)
+	}
+	if err := c.Db.Where("id = ?", cohort.Id).Delete(&model.Cohort{}).Error; err != nil {
+		return customError.DBError(err)
+	}
+	return nil
+}
+
+// SeatsTaken counts the active enrollments of each cohort.
+func (c *CohortsClient) SeatsTaken(cohortIds []uuid.UUID) (map[uuid.UUID]int, error) {
+	taken := make(map[uuid.UUID]int, len(cohortIds))
+	if len(cohortIds) == 0 {
+		return taken, nil
+	}
+	var rows []struct {
+		CohortId uuid.UUID
+		Seats    int
+	}
+	err := c.Db.Model(&model.Inscripto{}).
+		Select("cohort_id, COUNT(*) AS seats").
+		Where("cohort_id IN ? AND status = ?", cohortIds, model.EnrollmentActive).
+		Group("cohort_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, customError.DBError(err)
+	}
+	for _, row := range rows {
+		taken[row.CohortId] = row.Seats
+	}
+	return taken, nil
+}
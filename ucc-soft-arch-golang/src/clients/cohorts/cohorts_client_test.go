@@ -0,0 +1,70 @@
+package cohorts
+
+import (
+	"testing"
+	"time"
+
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	github_com_glebarez_sqlite "github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func makeDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(github_com_glebarez_sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	require.NoError(t, db.AutoMigrate(&model.Course{}, &model.Cohort{}, &model.Inscripto{}))
+	return db
+}
+
+func requireCode(t *testing.T, err error, code string) {
+	t.Helper()
+	var e *customError.Error
+	require.ErrorAs(t, err, &e)
+	require.Equal(t, code, e.Code)
+}
+
+func TestCohortsClient_Cohorts(t *testing.T) {
+	db := makeDB(t)
+	c := NewCohortsClient(db)
+	courseId := uuid.New()
+	now := time.Now().UTC()
+
+	june, err := c.CreateCohort(model.Cohort{CourseId: courseId, Name: "June", StartsAt: now.Add(60 * 24 * time.Hour), EndsAt: now.Add(90 * 24 * time.Hour), TimeZone: "UTC", Capacity: 2})
+	require.NoError(t, err)
+	march, err := c.CreateCohort(model.Cohort{CourseId: courseId, Name: "March", StartsAt: now.Add(24 * time.Hour), EndsAt: now.Add(30 * 24 * time.Hour), TimeZone: "UTC", Capacity: 2})
+	require.NoError(t, err)
+
+	list, err := c.GetCohorts(courseId)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	require.Equal(t, march.Id, list[0].Id)
+
+	_, err = c.GetCohort(uuid.New(), june.Id)
+	requireCode(t, err, "COHORT_NOT_FOUND")
+
+	june.Name = "July"
+	june.Capacity = 5
+	_, err = c.UpdateCohort(june)
+	require.NoError(t, err)
+	got, err := c.GetCohort(courseId, june.Id)
+	require.NoError(t, err)
+	require.Equal(t, "July", got.Name)
+	require.Equal(t, 5, got.Capacity)
+
+	require.NoError(t, db.Create(&model.Inscripto{CourseId: courseId, UserId: uuid.New(), CohortId: &march.Id, Status: model.EnrollmentActive}).Error)
+	require.NoError(t, db.Create(&model.Inscripto{CourseId: courseId, UserId: uuid.New(), CohortId: &march.Id, Status: model.EnrollmentWaitlisted}).Error)
+	taken, err := c.SeatsTaken([]uuid.UUID{march.Id, june.Id})
+	require.NoError(t, err)
+	require.Equal(t, map[uuid.UUID]int{march.Id: 1}, taken)
+
+	requireCode(t, c.DeleteCohort(march), "COHORT_HAS_ENROLLMENTS")
+	require.NoError(t, c.DeleteCohort(june))
+	list, err = c.GetCohorts(courseId)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+}
@@ -27,7 +27,7 @@ func TestCommentsClient_NewAndUpdateAndGet(t *testing.T) {
 	// seed user and course
 	u := model.User{Name: "Alice", Avatar: "pic.png", Email: "a@b.com", Password: "x"}
 	require.NoError(t, db.Create(&u).Error)
-	course := model.Course{CourseName: "Golang", CourseDescription: "intro", CoursePrice: 10, CourseDuration: 8, CourseInitDate: "2025-01-01", CourseState: false, CourseCapacity: 30, CourseImage: "img"}
+	course := model.Course{CourseName: "Golang", CourseDescription: "intro", CoursePrice: 10, CourseDuration: 8, CourseInitDate: "2025-01-01", CourseStatus: model.CourseDraft, CourseCapacity: 30, CourseImage: "img"}
 	require.NoError(t, db.Create(&course).Error)
 
 	// new comment
@@ -58,7 +58,7 @@ func TestCommentsClient_GetCourseComments_NotFound(t *testing.T) {
 	c := NewCommentsClient(db)
 
 	// seed a course but no comments
-	course := model.Course{CourseName: "Empty", CourseDescription: "", CoursePrice: 0, CourseDuration: 1, CourseInitDate: "2025-01-01", CourseState: false, CourseCapacity: 1, CourseImage: ""}
+	course := model.Course{CourseName: "Empty", CourseDescription: "", CoursePrice: 0, CourseDuration: 1, CourseInitDate: "2025-01-01", CourseStatus: model.CourseDraft, CourseCapacity: 1, CourseImage: ""}
 	require.NoError(t, db.Create(&course).Error)
 
 	_, err := c.GetCourseComments(course.Id)
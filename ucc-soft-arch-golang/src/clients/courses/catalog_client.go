@@ -0,0 +1,250 @@
+package courses
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	SortNewest       = "newest"
+	SortPriceAsc     = "price"
+	SortPriceDesc    = "-price"
+	SortRatingAsc    = "rating"
+	SortRatingDesc   = "-rating"
+	SortInitDateAsc  = "init_date"
+	SortInitDateDesc = "-init_date"
+)
+
+// catalogSorts maps each sort to the catalog column it orders by. The course
+// id breaks ties so keyset pages never skip or repeat a row.
+var catalogSorts = map[string]struct {
+	column string
+	desc   bool
+}{
+	SortNewest:       {"created_at", true},
+	SortPriceAsc:     {"course_price", false},
+	SortPriceDesc:    {"course_price", true},
+	SortRatingAsc:    {"ratingavg", false},
+	SortRatingDesc:   {"ratingavg", true},
+	SortInitDateAsc:  {"course_init_date", false},
+	SortInitDateDesc: {"course_init_date", true},
+}
+
+// catalogQuery is the course listing of GetAll as a subquery, so filters
+// and keysets can use the rating average like any other column.
+const catalogQuery = `SELECT
+		courses.*,
+		categories.category_name,
+		COALESCE(r.ratingavg, 0) as ratingavg
+	FROM
+		courses
+	LEFT JOIN
+		(SELECT course_id, AVG(rating) as ratingavg
+		FROM ratings
+		GROUP BY course_id) as r ON
+		courses.id = r.course_id
+	JOIN
+		categories
+	ON
+		courses.category_id = categories.id
+	WHERE
+		courses.deleted_at IS NULL`
+
+// Keyset is the position a cursor points at: the sort value and id of a
+// course. Before asks for the page that ends right before it.
+type Keyset struct {
+	Value  string
+	Id     uuid.UUID
+	Before bool
+}
+
+// CatalogQuery filters the catalog. Nil filters are not applied; Keyset,
+// when set, replaces Offset.
+type CatalogQuery struct {
+	Text        string
+	CategoryId  *uuid.UUID
+	MinPrice    *float64
+	MaxPrice    *float64
+	MinRating   *float64
+	Status      string
+	MinDuration *int
+	MaxDuration *int
+	Sort        string
+	Keyset      *Keyset
+	Offset      int
+	Limit       int
+	// LiveAt, when set, keeps only the courses everyone can see at that
+	// time.
+	LiveAt *time.Time
+	// Schedule, when set, keeps only the courses with a cohort in that
+	// model.CohortSchedules schedule at ScheduleAt.
+	Schedule   string
+	ScheduleAt time.Time
+}
+
+type CatalogPage struct {
+	Courses model.Courses
+	// Total counts every course matching the filters, not just this page.
+	Total int64
+	// HasMore tells whether there are more courses past the page in the
+	// direction it was read.
+	HasMore bool
+}
+
+// IsCatalogSort reports whether sort is one Search understands.
+func IsCatalogSort(sort string) bool {
+	_, ok := catalogSorts[sort]
+	return ok
+}
+
+// SortKey returns the value a cursor keeps for course under sort.
+func SortKey(sort string, course model.Course) string {
+	switch catalogSorts[sort].column {
+	case "course_price":
+		return strconv.FormatFloat(course.CoursePrice, 'g', -1, 64)
+	case "ratingavg":
+		return strconv.FormatFloat(course.RatingAvg, 'g', -1, 64)
+	case "course_init_date":
+		return course.CourseInitDate
+	default:
+		return course.CreatedAt.Format(time.RFC3339Nano)
+	}
+}
+
+// Search returns one page of the catalog, always in the order of the
+// query's sort, even when reading backwards from a keyset.
+func (c *CourseClient) Search(query CatalogQuery) (CatalogPage, error) {
+	sort, ok := catalogSorts[query.Sort]
+	if !ok {
+		return CatalogPage{}, customError.NewError("INVALID_SORT", "Unknown sort", http.StatusBadRequest)
+	}
+	filtered := c.filterCatalog(query)
+
+	var total int64
+	if err := filtered.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return CatalogPage{}, customError.NewError("DB_ERROR", "Error retrieving course from database", http.StatusInternalServerError)
+	}
+
+	desc := sort.desc
+	page := filtered.Session(&gorm.Session{})
+	if query.Keyset != nil {
+		value, err := keysetValue(sort.column, query.Keyset.Value)
+		if err != nil {
+			return CatalogPage{}, customError.NewError("INVALID_CURSOR", "Invalid cursor", http.StatusBadRequest)
+		}
+		// reading backwards flips the order, the rows are put back below
+		if query.Keyset.Before {
+			desc = !desc
+		}
+		op := ">"
+		if desc {
+			op = "<"
+		}
+		page = page.Where("(catalog."+sort.column+" "+op+" ? OR (catalog."+sort.column+" = ? AND catalog.id "+op+" ?))",
+			value, value, query.Keyset.Id)
+	} else {
+		page = page.Offset(query.Offset)
+	}
+	direction := " ASC"
+	if desc {
+		direction = " DESC"
+	}
+
+	var rawResults []map[string]interface{}
+	err := page.Order("catalog." + sort.column + direction).
+		Order("catalog.id" + direction).
+		Limit(query.Limit + 1).
+		Scan(&rawResults).Error
+	if err != nil {
+		return CatalogPage{}, customError.NewError("DB_ERROR", "Error retrieving course from database", http.StatusInternalServerError)
+	}
+
+	result := CatalogPage{Courses: model.Courses{}, Total: total}
+	if len(rawResults) > query.Limit {
+		result.HasMore = true
+		rawResults = rawResults[:query.Limit]
+	}
+	for _, data := range rawResults {
+		result.Courses = append(result.Courses, courseFromRow(data))
+	}
+	if query.Keyset != nil && query.Keyset.Before {
+		for i, j := 0, len(result.Courses)-1; i < j; i, j = i+1, j-1 {
+			result.Courses[i], result.Courses[j] = result.Courses[j], result.Courses[i]
+		}
+	}
+	return result, nil
+}
+
+func (c *CourseClient) filterCatalog(query CatalogQuery) *gorm.DB {
+	db := c.Db.Table("(?) AS catalog", c.Db.Raw(catalogQuery))
+	if query.Text != "" {
+		like := "%" + query.Text + "%"
+		db = db.Where("(LOWER(catalog.course_name) LIKE LOWER(?) OR LOWER(catalog.course_description) LIKE LOWER(?) OR LOWER(catalog.category_name) LIKE LOWER(?))",
+			like, like, like)
+	}
+	if query.CategoryId != nil {
+		db = db.Where("catalog.category_id = ?", *query.CategoryId)
+	}
+	if query.MinPrice != nil {
+		db = db.Where("catalog.course_price >= ?", *query.MinPrice)
+	}
+	if query.MaxPrice != nil {
+		db = db.Where("catalog.course_price <= ?", *query.MaxPrice)
+	}
+	if query.MinRating != nil {
+		db = db.Where("catalog.ratingavg >= ?", *query.MinRating)
+	}
+	if query.Status != "" {
+		db = db.Where("catalog.course_status = ?", query.Status)
+	}
+	if query.LiveAt != nil {
+		db = db.Where(model.LiveCourses("catalog", *query.LiveAt))
+	}
+	if query.Schedule != "" {
+		db = db.Where(model.CoursesWithCohorts("catalog", query.Schedule, query.ScheduleAt))
+	}
+	if query.MinDuration != nil {
+		db = db.Where("catalog.course_duration >= ?", *query.MinDuration)
+	}
+	if query.MaxDuration != nil {
+		db = db.Where("catalog.course_duration <= ?", *query.MaxDuration)
+	}
+	return db
+}
+
+func keysetValue(column string, value string) (interface{}, error) {
+	switch column {
+	case "course_price", "ratingavg":
+		return strconv.ParseFloat(value, 64)
+	case "course_init_date":
+		return value, nil
+	default:
+		return time.Parse(time.RFC3339Nano, value)
+	}
+}
+
+// GetByIds returns the listed courses as the catalog shows them, in no
+// particular order. Unknown and deleted ids are left out.
+func (c *CourseClient) GetByIds(ids []uuid.UUID) (model.Courses, error) {
+	courses := model.Courses{}
+	if len(ids) == 0 {
+		return courses, nil
+	}
+	var rawResults []map[string]interface{}
+	err := c.Db.Table("(?) AS catalog", c.Db.Raw(catalogQuery)).
+		Where("catalog.id IN ?", ids).
+		Scan(&rawResults).Error
+	if err != nil {
+		return nil, customError.NewError("DB_ERROR", "Error retrieving course from database", http.StatusInternalServerError)
+	}
+	for _, data := range rawResults {
+		courses = append(courses, courseFromRow(data))
+	}
+	return courses, nil
+}
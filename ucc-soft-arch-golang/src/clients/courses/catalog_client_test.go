@@ -0,0 +1,182 @@
+package courses
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+)
+
+func seedCatalog(t *testing.T, db *gorm.DB) (model.Category, model.Category) {
+	backend := model.Category{CategoryName: "Backend"}
+	frontend := model.Category{CategoryName: "Frontend"}
+	require.NoError(t, db.Create(&backend).Error)
+	require.NoError(t, db.Create(&frontend).Error)
+	user := model.User{Email: "r@b.com", Password: "pw", Name: "R"}
+	require.NoError(t, db.Create(&user).Error)
+
+	base := time.Now().Add(-time.Hour)
+	for i := 0; i < 5; i++ {
+		category := backend
+		if i%2 == 1 {
+			category = frontend
+		}
+		course := model.Course{
+			CourseName:     fmt.Sprintf("Course %d", i),
+			CoursePrice:    float64(10 * (i + 1)),
+			CourseDuration: 4 * (i + 1),
+			CourseInitDate: fmt.Sprintf("2025-0%d-01", 5-i),
+			CourseStatus:   model.CoursePublished,
+			CourseImage:    "img",
+			CategoryID:     category.Id,
+		}
+		if i == 4 {
+			course.CourseStatus = model.CourseDraft
+		}
+		course.CreatedAt = base.Add(time.Duration(i) * time.Minute)
+		require.NoError(t, db.Create(&course).Error)
+		require.NoError(t, db.Create(&model.Rating{CourseId: course.Id, UserId: user.Id, Rating: i + 1}).Error)
+	}
+	return backend, frontend
+}
+
+func names(courses model.Courses) []string {
+	var result []string
+	for _, course := range courses {
+		result = append(result, course.CourseName)
+	}
+	return result
+}
+
+func TestCourseClient_Search_FiltersAndTotal(t *testing.T) {
+	db := setupCoursesDB(t)
+	backend, _ := seedCatalog(t, db)
+	c := NewCourseClient(db)
+
+	minPrice, maxPrice, minRating := 20.0, 50.0, 3.0
+	maxDuration := 16
+	page, err := c.Search(CatalogQuery{
+		CategoryId:  &backend.Id,
+		MinPrice:    &minPrice,
+		MaxPrice:    &maxPrice,
+		MinRating:   &minRating,
+		Status:      model.CoursePublished,
+		MaxDuration: &maxDuration,
+		Sort:        SortPriceAsc,
+		Limit:       10,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"Course 2"}, names(page.Courses))
+	require.Equal(t, int64(1), page.Total)
+	require.False(t, page.HasMore)
+	require.InDelta(t, 3.0, page.Courses[0].RatingAvg, 0.0001)
+
+	page, err = c.Search(CatalogQuery{Text: "frontend", Sort: SortNewest, Limit: 10})
+	require.NoError(t, err)
+	require.Equal(t, []string{"Course 3", "Course 1"}, names(page.Courses))
+
+	_, err = c.Search(CatalogQuery{Sort: "popularity", Limit: 10})
+	require.Equal(t, "INVALID_SORT", err.(*customError.Error).Code)
+}
+
+func TestCourseClient_Search_LiveCourses(t *testing.T) {
+	db := setupCoursesDB(t)
+	seedCatalog(t, db)
+	now := time.Now()
+	later, earlier := now.Add(time.Hour), now.Add(-time.Minute)
+	require.NoError(t, db.Model(&model.Course{}).Where("course_name = ?", "Course 0").Update("publish_at", later).Error)
+	require.NoError(t, db.Model(&model.Course{}).Where("course_name = ?", "Course 1").Update("unpublish_at", earlier).Error)
+	c := NewCourseClient(db)
+
+	page, err := c.Search(CatalogQuery{LiveAt: &now, Sort: SortNewest, Limit: 10})
+	require.NoError(t, err)
+	require.Equal(t, []string{"Course 3", "Course 2"}, names(page.Courses))
+	require.Equal(t, int64(2), page.Total)
+
+	// once the scheduled publication comes, the course shows up
+	tomorrow := now.Add(24 * time.Hour)
+	page, err = c.Search(CatalogQuery{LiveAt: &tomorrow, Sort: SortNewest, Limit: 10})
+	require.NoError(t, err)
+	require.Equal(t, []string{"Course 3", "Course 2", "Course 0"}, names(page.Courses))
+}
+
+func TestCourseClient_Search_CohortSchedule(t *testing.T) {
+	db := setupCoursesDB(t)
+	require.NoError(t, db.AutoMigrate(&model.Cohort{}))
+	seedCatalog(t, db)
+	now := time.Now().UTC()
+	day := 24 * time.Hour
+	cohort := func(courseName string, startsAt time.Time, endsAt time.Time) model.Cohort {
+		var course model.Course
+		require.NoError(t, db.Where("course_name = ?", courseName).First(&course).Error)
+		cohort := model.Cohort{CourseId: course.Id, Name: courseName, StartsAt: startsAt, EndsAt: endsAt, TimeZone: "UTC", Capacity: 10}
+		require.NoError(t, db.Create(&cohort).Error)
+		return cohort
+	}
+	cohort("Course 0", now.Add(day), now.Add(2*day))
+	cohort("Course 1", now.Add(-day), now.Add(day))
+	cohort("Course 1", now.Add(3*day), now.Add(4*day))
+	cohort("Course 2", now.Add(-2*day), now.Add(-day))
+	deleted := cohort("Course 3", now.Add(day), now.Add(2*day))
+	require.NoError(t, db.Delete(&deleted).Error)
+	c := NewCourseClient(db)
+
+	for schedule, expected := range map[string][]string{
+		model.CohortUpcoming: {"Course 1", "Course 0"},
+		model.CohortOngoing:  {"Course 1"},
+		model.CohortFinished: {"Course 2"},
+	} {
+		page, err := c.Search(CatalogQuery{Schedule: schedule, ScheduleAt: now, Sort: SortNewest, Limit: 10})
+		require.NoError(t, err)
+		require.Equal(t, expected, names(page.Courses), schedule)
+		require.Equal(t, int64(len(expected)), page.Total)
+	}
+
+	// a week later every cohort is over
+	page, err := c.Search(CatalogQuery{Schedule: model.CohortFinished, ScheduleAt: now.Add(7 * day), Sort: SortNewest, Limit: 10})
+	require.NoError(t, err)
+	require.Equal(t, []string{"Course 2", "Course 1", "Course 0"}, names(page.Courses))
+}
+
+func TestCourseClient_Search_OffsetAndKeyset(t *testing.T) {
+	db := setupCoursesDB(t)
+	seedCatalog(t, db)
+	c := NewCourseClient(db)
+
+	for _, sort := range []string{SortNewest, SortPriceDesc, SortRatingAsc, SortInitDateAsc} {
+		page, err := c.Search(CatalogQuery{Sort: sort, Limit: 2})
+		require.NoError(t, err)
+		require.Equal(t, int64(5), page.Total)
+		require.True(t, page.HasMore)
+		first := page.Courses
+
+		page, err = c.Search(CatalogQuery{Sort: sort, Offset: 2, Limit: 2})
+		require.NoError(t, err)
+		byOffset := page.Courses
+
+		last := first[len(first)-1]
+		page, err = c.Search(CatalogQuery{Sort: sort, Limit: 2, Keyset: &Keyset{Value: SortKey(sort, last), Id: last.Id}})
+		require.NoError(t, err, sort)
+		require.Equal(t, names(byOffset), names(page.Courses), sort)
+		require.True(t, page.HasMore)
+
+		// going back from the second page lands on the first one again
+		head := page.Courses[0]
+		page, err = c.Search(CatalogQuery{Sort: sort, Limit: 2, Keyset: &Keyset{Value: SortKey(sort, head), Id: head.Id, Before: true}})
+		require.NoError(t, err)
+		require.Equal(t, names(first), names(page.Courses), sort)
+		require.False(t, page.HasMore)
+	}
+
+	page, err := c.Search(CatalogQuery{Sort: SortNewest, Limit: 2})
+	require.NoError(t, err)
+	require.Equal(t, []string{"Course 4", "Course 3"}, names(page.Courses))
+
+	_, err = c.Search(CatalogQuery{Sort: SortPriceAsc, Limit: 2, Keyset: &Keyset{Value: "cheap"}})
+	require.Equal(t, "INVALID_CURSOR", err.(*customError.Error).Code)
+}
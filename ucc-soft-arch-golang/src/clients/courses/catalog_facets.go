@@ -0,0 +1,179 @@
+package courses
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Bucket is a range of a numeric column: Min is inclusive, Max exclusive
+// and nil for the last, open ended one.
+type Bucket struct {
+	Key string
+	Min float64
+	Max *float64
+}
+
+func upTo(max float64) *float64 { return &max }
+
+var PriceBuckets = []Bucket{
+	{Key: "0-25", Min: 0, Max: upTo(25)},
+	{Key: "25-50", Min: 25, Max: upTo(50)},
+	{Key: "50-100", Min: 50, Max: upTo(100)},
+	{Key: "100-200", Min: 100, Max: upTo(200)},
+	{Key: "200+", Min: 200},
+}
+
+// RatingBuckets overlap on purpose, they match the min_rating filter.
+var RatingBuckets = []Bucket{
+	{Key: "4+", Min: 4},
+	{Key: "3+", Min: 3},
+	{Key: "2+", Min: 2},
+	{Key: "1+", Min: 1},
+}
+
+type CategoryFacet struct {
+	CategoryId   uuid.UUID
+	CategoryName string
+	Count        int64
+}
+
+type BucketFacet struct {
+	Bucket
+	Count int64
+}
+
+type StatusFacet struct {
+	Status string
+	Count  int64
+}
+
+type CatalogFacets struct {
+	Categories []CategoryFacet
+	Prices     []BucketFacet
+	Ratings    []BucketFacet
+	Statuses   []StatusFacet
+}
+
+// Facets counts the courses matching query per category, price bucket,
+// rating bucket and status. Each facet ignores its own filter, so picking a
+// category still shows how many courses the other categories have.
+func (c *CourseClient) Facets(query CatalogQuery) (CatalogFacets, error) {
+	facets := CatalogFacets{Categories: []CategoryFacet{}, Statuses: []StatusFacet{}}
+
+	withoutCategory := query
+	withoutCategory.CategoryId = nil
+	err := c.filterCatalog(withoutCategory).
+		Select("catalog.category_id, catalog.category_name, COUNT(*) AS count").
+		Group("catalog.category_id, catalog.category_name").
+		Order("count DESC, catalog.category_name").
+		Scan(&facets.Categories).Error
+	if err != nil {
+		return CatalogFacets{}, facetsError()
+	}
+
+	withoutPrice := query
+	withoutPrice.MinPrice, withoutPrice.MaxPrice = nil, nil
+	if facets.Prices, err = c.bucketCounts(c.filterCatalog(withoutPrice), "catalog.course_price", PriceBuckets); err != nil {
+		return CatalogFacets{}, err
+	}
+
+	withoutRating := query
+	withoutRating.MinRating = nil
+	if facets.Ratings, err = c.bucketCounts(c.filterCatalog(withoutRating), "catalog.ratingavg", RatingBuckets); err != nil {
+		return CatalogFacets{}, err
+	}
+
+	withoutStatus := query
+	withoutStatus.Status = ""
+	err = c.filterCatalog(withoutStatus).
+		Select("catalog.course_status AS status, COUNT(*) AS count").
+		Group("catalog.course_status").
+		Order("catalog.course_status").
+		Scan(&facets.Statuses).Error
+	if err != nil {
+		return CatalogFacets{}, facetsError()
+	}
+	return facets, nil
+}
+
+// bucketCounts counts every bucket in a single pass over the catalog.
+func (c *CourseClient) bucketCounts(db *gorm.DB, column string, buckets []Bucket) ([]BucketFacet, error) {
+	var (
+		columns []string
+		args    []interface{}
+	)
+	for i, bucket := range buckets {
+		if bucket.Max == nil {
+			columns = append(columns, fmt.Sprintf("SUM(CASE WHEN %s >= ? THEN 1 ELSE 0 END) AS bucket_%d", column, i))
+			args = append(args, bucket.Min)
+		} else {
+			columns = append(columns, fmt.Sprintf("SUM(CASE WHEN %s >= ? AND %s < ? THEN 1 ELSE 0 END) AS bucket_%d", column, column, i))
+			args = append(args, bucket.Min, *bucket.Max)
+		}
+	}
+	var row map[string]interface{}
+	if err := db.Select(strings.Join(columns, ", "), args...).Scan(&row).Error; err != nil {
+		return nil, facetsError()
+	}
+	facets := make([]BucketFacet, 0, len(buckets))
+	for i, bucket := range buckets {
+		// SUM over no rows is NULL, which toInt reads as 0
+		facets = append(facets, BucketFacet{Bucket: bucket, Count: int64(toInt(row[fmt.Sprintf("bucket_%d", i)]))})
+	}
+	return facets, nil
+}
+
+type Suggestion struct {
+	Id   uuid.UUID
+	Text string
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// Suggest returns up to limit course names and up to limit category names
+// where a word starts with prefix. Names that start with it come first,
+// then the shortest. Only courses live at now are suggested.
+func (c *CourseClient) Suggest(prefix string, limit int, now time.Time) ([]Suggestion, []Suggestion, error) {
+	courses, err := c.suggest(c.Db.Model(&model.Course{}).Where(model.LiveCourses("courses", now)), "course_name", prefix, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+	categories, err := c.suggest(c.Db.Model(&model.Category{}), "category_name", prefix, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+	return courses, categories, nil
+}
+
+func (c *CourseClient) suggest(db *gorm.DB, column string, prefix string, limit int) ([]Suggestion, error) {
+	escaped := likeEscaper.Replace(strings.ToLower(prefix))
+	starts, wordStarts := escaped+"%", "% "+escaped+"%"
+	suggestions := []Suggestion{}
+	err := db.
+		Select("id, "+column+" AS text").
+		Where("(LOWER("+column+`) LIKE ? ESCAPE '\' OR LOWER(`+column+`) LIKE ? ESCAPE '\')`, starts, wordStarts).
+		Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:                "CASE WHEN LOWER(" + column + `) LIKE ? ESCAPE '\' THEN 0 ELSE 1 END`,
+			Vars:               []interface{}{starts},
+			WithoutParentheses: true,
+		}}).
+		Order("LENGTH(" + column + "), " + column).
+		Limit(limit).
+		Scan(&suggestions).Error
+	if err != nil {
+		return nil, customError.NewError("DB_ERROR", "Error retrieving suggestions from database", http.StatusInternalServerError)
+	}
+	return suggestions, nil
+}
+
+func facetsError() error {
+	return customError.NewError("DB_ERROR", "Error retrieving course from database", http.StatusInternalServerError)
+}
@@ -0,0 +1,75 @@
+package courses
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+)
+
+func bucketCounts(facets []BucketFacet) map[string]int64 {
+	counts := map[string]int64{}
+	for _, facet := range facets {
+		counts[facet.Key] = facet.Count
+	}
+	return counts
+}
+
+func TestCourseClient_Facets(t *testing.T) {
+	db := setupCoursesDB(t)
+	backend, frontend := seedCatalog(t, db)
+	c := NewCourseClient(db)
+
+	facets, err := c.Facets(CatalogQuery{})
+	require.NoError(t, err)
+	require.Equal(t, []CategoryFacet{
+		{CategoryId: backend.Id, CategoryName: "Backend", Count: 3},
+		{CategoryId: frontend.Id, CategoryName: "Frontend", Count: 2},
+	}, facets.Categories)
+	require.Equal(t, map[string]int64{"0-25": 2, "25-50": 2, "50-100": 1, "100-200": 0, "200+": 0}, bucketCounts(facets.Prices))
+	require.Equal(t, map[string]int64{"4+": 2, "3+": 3, "2+": 4, "1+": 5}, bucketCounts(facets.Ratings))
+	require.Equal(t, []StatusFacet{{Status: model.CourseDraft, Count: 1}, {Status: model.CoursePublished, Count: 4}}, facets.Statuses)
+
+	// a facet ignores its own filter but follows the others
+	maxPrice := 30.0
+	facets, err = c.Facets(CatalogQuery{CategoryId: &backend.Id, MaxPrice: &maxPrice})
+	require.NoError(t, err)
+	require.Len(t, facets.Categories, 2)
+	require.Equal(t, map[string]int64{"0-25": 1, "25-50": 1, "50-100": 1, "100-200": 0, "200+": 0}, bucketCounts(facets.Prices))
+	require.Equal(t, map[string]int64{"4+": 0, "3+": 1, "2+": 1, "1+": 2}, bucketCounts(facets.Ratings))
+	require.Equal(t, []StatusFacet{{Status: model.CoursePublished, Count: 2}}, facets.Statuses)
+}
+
+func TestCourseClient_Suggest(t *testing.T) {
+	db := setupCoursesDB(t)
+	category := model.Category{CategoryName: "Programming"}
+	require.NoError(t, db.Create(&category).Error)
+	for _, name := range []string{"Advanced Go Programming", "Go", "Golang web", "Rust", "100% Go_lang"} {
+		require.NoError(t, db.Create(&model.Course{CourseName: name, CourseImage: "img", CourseStatus: model.CoursePublished, CategoryID: category.Id}).Error)
+	}
+	c := NewCourseClient(db)
+
+	courses, categories, err := c.Suggest("GO", 10, time.Now())
+	require.NoError(t, err)
+	var texts []string
+	for _, suggestion := range courses {
+		texts = append(texts, suggestion.Text)
+	}
+	require.Equal(t, []string{"Go", "Golang web", "100% Go_lang", "Advanced Go Programming"}, texts)
+	require.Empty(t, categories)
+
+	courses, categories, err = c.Suggest("prog", 1, time.Now())
+	require.NoError(t, err)
+	require.Len(t, courses, 1)
+	require.Equal(t, []Suggestion{{Id: category.Id, Text: "Programming"}}, categories)
+
+	// wildcards are matched literally
+	courses, _, err = c.Suggest("100%", 10, time.Now())
+	require.NoError(t, err)
+	require.Len(t, courses, 1)
+	courses, _, err = c.Suggest("gol_ng", 10, time.Now())
+	require.NoError(t, err)
+	require.Empty(t, courses)
+}
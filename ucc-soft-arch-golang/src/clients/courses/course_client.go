@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
 	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
@@ -107,28 +108,34 @@ func (c *CourseClient) GetAll(filter string) (model.Courses, error) {
 		}
 	}
 	for _, data := range rawResults {
-		course := model.Course{
-			Id:                parseUUID(data["id"]),
-			CourseName:        data["course_name"].(string),
-			CourseDescription: data["course_description"].(string),
-			CoursePrice:       toFloat64(data["course_price"]),
-			CourseDuration:    toInt(data["course_duration"]),
-			CourseInitDate:    data["course_init_date"].(string),
-			CourseState:       toBool(data["course_state"]),
-			CourseCapacity:    toInt(data["course_capacity"]),
-			CourseImage:       data["course_image"].(string),
-			CategoryID:        parseUUID(data["category_id"]),
-			Category: model.Category{
-				CategoryName: data["category_name"].(string),
-			},
-			RatingAvg: toFloat64(data["ratingavg"]),
-		}
-		courses = append(courses, course)
+		courses = append(courses, courseFromRow(data))
 	}
 
 	return courses, nil
 }
 
+func courseFromRow(data map[string]interface{}) model.Course {
+	return model.Course{
+		Id:                parseUUID(data["id"]),
+		CourseName:        data["course_name"].(string),
+		CourseDescription: data["course_description"].(string),
+		CoursePrice:       toFloat64(data["course_price"]),
+		CourseDuration:    toInt(data["course_duration"]),
+		CourseInitDate:    data["course_init_date"].(string),
+		CourseStatus:      toString(data["course_status"]),
+		PublishAt:         toTimePtr(data["publish_at"]),
+		UnpublishAt:       toTimePtr(data["unpublish_at"]),
+		CourseCapacity:    toInt(data["course_capacity"]),
+		CourseImage:       data["course_image"].(string),
+		CategoryID:        parseUUID(data["category_id"]),
+		Category: model.Category{
+			CategoryName: data["category_name"].(string),
+		},
+		RatingAvg: toFloat64(data["ratingavg"]),
+		Model:     gorm.Model{CreatedAt: toTime(data["created_at"])},
+	}
+}
+
 func (c *CourseClient) GetById(id uuid.UUID) (model.Course, error) {
 	var rawResult map[string]interface{}
 	err := c.Db.Raw(
@@ -160,7 +167,9 @@ func (c *CourseClient) GetById(id uuid.UUID) (model.Course, error) {
 		CoursePrice:       toFloat64(rawResult["course_price"]),
 		CourseDuration:    toInt(rawResult["course_duration"]),
 		CourseInitDate:    rawResult["course_init_date"].(string),
-		CourseState:       toBool(rawResult["course_state"]),
+		CourseStatus:      toString(rawResult["course_status"]),
+		PublishAt:         toTimePtr(rawResult["publish_at"]),
+		UnpublishAt:       toTimePtr(rawResult["unpublish_at"]),
 		CourseCapacity:    toInt(rawResult["course_capacity"]),
 		CourseImage:       rawResult["course_image"].(string),
 		CategoryID:        parseUUID(rawResult["category_id"]),
@@ -200,22 +209,15 @@ func (c *CourseClient) UpdateCourse(course model.Course) (model.Course, error) {
 }
 
 func (c *CourseClient) DeleteCourse(id uuid.UUID) error {
-	result := c.Db.Where("id = ?", id).Delete(&model.Course{})
-	if result.Error != nil {
-		var err error
-		switch {
-		case strings.Contains(result.Error.Error(), "connection"):
-			err = customError.NewError(
-				"DB_CONNECTION_ERROR",
-				"Database connection error. Please try again later.",
-				http.StatusInternalServerError)
-		default:
-			err = customError.NewError(
-				"UNEXPECTED_ERROR",
-				"An unexpected error occurred. Please try again later.",
-				http.StatusInternalServerError)
+	// the instructors go with the course so nobody keeps teaching it
+	err := c.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("course_id = ?", id).Delete(&model.CourseInstructor{}).Error; err != nil {
+			return err
 		}
-		return err
+		return tx.Where("id = ?", id).Delete(&model.Course{}).Error
+	})
+	if err != nil {
+		return customError.DBError(err)
 	}
 	return nil
 }
@@ -273,6 +275,38 @@ func toInt(v interface{}) int {
 	}
 }
 
+func toTime(v interface{}) time.Time {
+	switch t := v.(type) {
+	case time.Time:
+		return t
+	case string:
+		parsed, _ := time.Parse("2006-01-02 15:04:05.999999999-07:00", t)
+		return parsed
+	default:
+		return time.Time{}
+	}
+}
+
+// toTimePtr is toTime for nullable columns.
+func toTimePtr(v interface{}) *time.Time {
+	if v == nil {
+		return nil
+	}
+	t := toTime(v)
+	return &t
+}
+
+func toString(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case []byte:
+		return string(t)
+	default:
+		return ""
+	}
+}
+
 func toFloat64(v interface{}) float64 {
 	switch t := v.(type) {
 	case float64:
@@ -16,7 +16,7 @@ func setupCoursesDB(t *testing.T) *gorm.DB {
 	if err != nil {
 		t.Fatalf("failed to open sqlite db: %v", err)
 	}
-	require.NoError(t, db.AutoMigrate(&model.Course{}, &model.Category{}, &model.User{}, &model.Rating{}))
+	require.NoError(t, db.AutoMigrate(&model.Course{}, &model.Category{}, &model.User{}, &model.Rating{}, &model.CourseInstructor{}))
 	return db
 }
 
@@ -28,7 +28,7 @@ func TestCourseClient_Create_Update_Delete(t *testing.T) {
 	cat := model.Category{CategoryName: "Backend"}
 	require.NoError(t, db.Create(&cat).Error)
 
-	course := model.Course{CourseName: "Golang", CourseDescription: "intro", CoursePrice: 10, CourseDuration: 8, CourseInitDate: "2025-01-01", CourseState: false, CourseCapacity: 30, CourseImage: "img", CategoryID: cat.Id}
+	course := model.Course{CourseName: "Golang", CourseDescription: "intro", CoursePrice: 10, CourseDuration: 8, CourseInitDate: "2025-01-01", CourseStatus: model.CourseDraft, CourseCapacity: 30, CourseImage: "img", CategoryID: cat.Id}
 	created, err := c.Create(course)
 	require.NoError(t, err)
 	require.Equal(t, "Golang", created.CourseName)
@@ -54,7 +54,7 @@ func TestCourseClient_GetAll_And_GetById(t *testing.T) {
 	usr := model.User{Email: "a@b.com", Password: "pw", Name: "A"}
 	require.NoError(t, db.Create(&usr).Error)
 
-	course := model.Course{CourseName: "Golang", CourseDescription: "intro", CoursePrice: 20, CourseDuration: 8, CourseInitDate: "2025-01-01", CourseState: true, CourseCapacity: 25, CourseImage: "img", CategoryID: cat.Id}
+	course := model.Course{CourseName: "Golang", CourseDescription: "intro", CoursePrice: 20, CourseDuration: 8, CourseInitDate: "2025-01-01", CourseStatus: model.CoursePublished, CourseCapacity: 25, CourseImage: "img", CategoryID: cat.Id}
 	require.NoError(t, db.Create(&course).Error)
 
 	// one rating so AVG = rating
@@ -68,14 +68,14 @@ func TestCourseClient_GetAll_And_GetById(t *testing.T) {
 	require.Equal(t, "Golang", all[0].CourseName)
 	require.Equal(t, "Backend", all[0].Category.CategoryName)
 	require.InDelta(t, 4.0, all[0].RatingAvg, 0.0001)
-	require.Equal(t, true, all[0].CourseState)
+	require.Equal(t, model.CoursePublished, all[0].CourseStatus)
 
 	// GetById
 	got, err := c.GetById(course.Id)
 	require.NoError(t, err)
 	require.Equal(t, course.Id, got.Id)
 	require.Equal(t, "Golang", got.CourseName)
-	require.Equal(t, true, got.CourseState)
+	require.Equal(t, model.CoursePublished, got.CourseStatus)
 	require.InDelta(t, 4.0, got.RatingAvg, 0.0001)
 }
 
@@ -86,7 +86,7 @@ func TestCourseClient_FilterAndNotFoundBranches(t *testing.T) {
 	// seed category and a course without ratings
 	cat := model.Category{CategoryName: "Backend"}
 	require.NoError(t, db.Create(&cat).Error)
-	course := model.Course{CourseName: "Rust", CourseDescription: "systems", CoursePrice: 30, CourseDuration: 10, CourseInitDate: "2025-02-01", CourseState: true, CourseCapacity: 50, CourseImage: "img", CategoryID: cat.Id}
+	course := model.Course{CourseName: "Rust", CourseDescription: "systems", CoursePrice: 30, CourseDuration: 10, CourseInitDate: "2025-02-01", CourseStatus: model.CoursePublished, CourseCapacity: 50, CourseImage: "img", CategoryID: cat.Id}
 	require.NoError(t, db.Create(&course).Error)
 
 	// 1) GetAll with a filter triggers the filtered query branch.
@@ -110,7 +110,7 @@ func TestCourseClient_Create_DuplicateName_ErrorMapping(t *testing.T) {
 	require.NoError(t, db.Create(&cat).Error)
 
 	// create one course
-	course := model.Course{CourseName: "UniqueName", CourseDescription: "d", CoursePrice: 10, CourseDuration: 5, CourseInitDate: "2025-01-01", CourseState: true, CourseCapacity: 10, CourseImage: "img", CategoryID: cat.Id}
+	course := model.Course{CourseName: "UniqueName", CourseDescription: "d", CoursePrice: 10, CourseDuration: 5, CourseInitDate: "2025-01-01", CourseStatus: model.CoursePublished, CourseCapacity: 10, CourseImage: "img", CategoryID: cat.Id}
 	_, err := c.Create(course)
 	require.NoError(t, err)
 
@@ -0,0 +1,108 @@
+package courses
+
+import (
+	"net/http"
+
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm/clause"
+)
+
+// GetByInstructor returns the courses a user owns or co-teaches.
+func (c *CourseClient) GetByInstructor(userId uuid.UUID) (model.Courses, error) {
+	var courses model.Courses
+	var rawResults []map[string]interface{}
+	err := c.Db.Raw(
+		`SELECT
+			courses.*,
+			categories.category_name,
+			COALESCE(r.ratingavg, 0) as ratingavg
+		FROM
+			courses
+		JOIN
+			course_instructors ci ON ci.course_id = courses.id AND ci.deleted_at IS NULL
+		LEFT JOIN
+			(SELECT course_id, AVG(rating) as ratingavg
+			FROM ratings
+			GROUP BY course_id) as r ON
+			courses.id = r.course_id
+		JOIN
+			categories
+		ON
+			courses.category_id = categories.id
+		WHERE
+			courses.deleted_at IS NULL AND
+			ci.user_id = ?`, userId).Scan(&rawResults).Error
+	if err != nil {
+		return nil, customError.NewError("DB_ERROR", "Error retrieving course from database", http.StatusInternalServerError)
+	}
+	for _, data := range rawResults {
+		courses = append(courses, courseFromRow(data))
+	}
+	return courses, nil
+}
+
+func (c *CourseClient) GetInstructors(courseId uuid.UUID) (model.CourseInstructors, error) {
+	var instructors model.CourseInstructors
+	err := c.Db.Preload("User").
+		Where("course_id = ?", courseId).
+		Order("is_owner DESC, created_at").
+		Find(&instructors).Error
+	if err != nil {
+		return nil, customError.DBError(err)
+	}
+	return instructors, nil
+}
+
+func (c *CourseClient) IsInstructor(courseId uuid.UUID, userId uuid.UUID) (bool, error) {
+	var count int64
+	err := c.Db.Model(&model.CourseInstructor{}).
+		Where("course_id = ? AND user_id = ?", courseId, userId).
+		Count(&count).Error
+	if err != nil {
+		return false, customError.DBError(err)
+	}
+	return count > 0, nil
+}
+
+func (c *CourseClient) IsOwner(courseId uuid.UUID, userId uuid.UUID) (bool, error) {
+	var count int64
+	err := c.Db.Model(&model.CourseInstructor{}).
+		Where("course_id = ? AND user_id = ? AND is_owner = ?", courseId, userId, true).
+		Count(&count).Error
+	if err != nil {
+		return false, customError.DBError(err)
+	}
+	return count > 0, nil
+}
+
+// AddInstructor adds a co-instructor. Adding one twice is a no-op.
+func (c *CourseClient) AddInstructor(instructor model.CourseInstructor) error {
+	var users int64
+	if err := c.Db.Model(&model.User{}).Where("id = ?", instructor.UserId).Count(&users).Error; err != nil {
+		return customError.DBError(err)
+	}
+	if users == 0 {
+		return customError.NewError("NOT_FOUND", "User not found", http.StatusNotFound)
+	}
+	result := c.Db.Clauses(clause.OnConflict{DoNothing: true}).Create(&instructor)
+	if result.Error != nil {
+		return customError.DBError(result.Error)
+	}
+	return nil
+}
+
+// RemoveInstructor removes a co-instructor; the owner can't be removed.
+func (c *CourseClient) RemoveInstructor(courseId uuid.UUID, userId uuid.UUID) error {
+	result := c.Db.Unscoped().
+		Where("course_id = ? AND user_id = ? AND is_owner = ?", courseId, userId, false).
+		Delete(&model.CourseInstructor{})
+	if result.Error != nil {
+		return customError.DBError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return customError.NewError("NOT_FOUND", "Co-instructor not found", http.StatusNotFound)
+	}
+	return nil
+}
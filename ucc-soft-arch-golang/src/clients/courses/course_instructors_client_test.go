@@ -0,0 +1,88 @@
+package courses
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+)
+
+func TestCourseClient_Instructors(t *testing.T) {
+	db := setupCoursesDB(t)
+	c := NewCourseClient(db)
+
+	owner := model.User{Email: "owner@ex.com", Name: "Owner"}
+	co := model.User{Email: "co@ex.com", Name: "Co"}
+	require.NoError(t, db.Create(&owner).Error)
+	require.NoError(t, db.Create(&co).Error)
+	cat := model.Category{CategoryName: "Backend"}
+	require.NoError(t, db.Create(&cat).Error)
+
+	created, err := c.Create(model.Course{
+		CourseName: "Go", CourseDescription: "d", CourseInitDate: "2025-01-01", CourseImage: "img", CategoryID: cat.Id,
+		Instructors: model.CourseInstructors{{UserId: owner.Id, IsOwner: true}},
+	})
+	require.NoError(t, err)
+
+	ok, err := c.IsInstructor(created.Id, owner.Id)
+	require.NoError(t, err)
+	require.True(t, ok)
+	ok, err = c.IsInstructor(created.Id, co.Id)
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.Error(t, c.AddInstructor(model.CourseInstructor{CourseId: created.Id, UserId: uuid.New()}))
+
+	require.NoError(t, c.AddInstructor(model.CourseInstructor{CourseId: created.Id, UserId: co.Id}))
+	require.NoError(t, c.AddInstructor(model.CourseInstructor{CourseId: created.Id, UserId: co.Id}))
+	instructors, err := c.GetInstructors(created.Id)
+	require.NoError(t, err)
+	require.Len(t, instructors, 2)
+	require.True(t, instructors[0].IsOwner)
+	require.Equal(t, "Co", instructors[1].User.Name)
+	isOwner, err := c.IsOwner(created.Id, co.Id)
+	require.NoError(t, err)
+	require.False(t, isOwner)
+	isOwner, err = c.IsOwner(created.Id, owner.Id)
+	require.NoError(t, err)
+	require.True(t, isOwner)
+
+	taught, err := c.GetByInstructor(co.Id)
+	require.NoError(t, err)
+	require.Len(t, taught, 1)
+	require.Equal(t, "Go", taught[0].CourseName)
+
+	// the owner stays, co-instructors can be removed
+	require.Error(t, c.RemoveInstructor(created.Id, owner.Id))
+	require.NoError(t, c.RemoveInstructor(created.Id, co.Id))
+	taught, err = c.GetByInstructor(co.Id)
+	require.NoError(t, err)
+	require.Empty(t, taught)
+
+	none, err := c.GetByInstructor(uuid.New())
+	require.NoError(t, err)
+	require.Empty(t, none)
+}
+
+func TestCourseClient_DeleteCourse_RemovesInstructors(t *testing.T) {
+	db := setupCoursesDB(t)
+	c := NewCourseClient(db)
+	owner := model.User{Email: "owner@ex.com", Name: "Owner"}
+	require.NoError(t, db.Create(&owner).Error)
+	cat := model.Category{CategoryName: "Backend"}
+	require.NoError(t, db.Create(&cat).Error)
+	created, err := c.Create(model.Course{
+		CourseName: "Go", CourseDescription: "d", CourseInitDate: "2025-01-01", CourseImage: "img", CategoryID: cat.Id,
+		Instructors: model.CourseInstructors{{UserId: owner.Id, IsOwner: true}},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, c.DeleteCourse(created.Id))
+	var left int64
+	require.NoError(t, db.Unscoped().Model(&model.CourseInstructor{}).Where("course_id = ?", created.Id).Count(&left).Error)
+	require.Zero(t, left)
+	taught, err := c.GetByInstructor(owner.Id)
+	require.NoError(t, err)
+	require.Empty(t, taught)
+}
@@ -0,0 +1,49 @@
+package courses
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GetLifecycle returns the course with its status and publication window.
+func (c *CourseClient) GetLifecycle(courseId uuid.UUID) (model.Course, error) {
+	var course model.Course
+	err := c.Db.Select("id", "course_status", "publish_at", "unpublish_at").First(&course, "id = ?", courseId).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.Course{}, customError.NewError("NOT_FOUND", "Course not found", http.StatusNotFound)
+		}
+		return model.Course{}, customError.DBError(err)
+	}
+	return course, nil
+}
+
+// SetStatus moves the course from one status to another. It reports false,
+// changing nothing, when the course is no longer in from, so two requests
+// can't both act on the same status.
+func (c *CourseClient) SetStatus(courseId uuid.UUID, from string, to string) (bool, error) {
+	result := c.Db.Model(&model.Course{}).
+		Where("id = ? AND course_status = ?", courseId, from).
+		Update("course_status", to)
+	if result.Error != nil {
+		return false, customError.DBError(result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// SetSchedule saves the publication window of a course; nil clears a bound.
+func (c *CourseClient) SetSchedule(courseId uuid.UUID, publishAt *time.Time, unpublishAt *time.Time) error {
+	err := c.Db.Model(&model.Course{}).
+		Where("id = ?", courseId).
+		Updates(map[string]interface{}{"publish_at": publishAt, "unpublish_at": unpublishAt}).Error
+	if err != nil {
+		return customError.DBError(err)
+	}
+	return nil
+}
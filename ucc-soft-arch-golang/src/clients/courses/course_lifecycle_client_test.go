@@ -0,0 +1,48 @@
+package courses
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+)
+
+func TestCourseClient_Lifecycle(t *testing.T) {
+	db := setupCoursesDB(t)
+	c := NewCourseClient(db)
+	cat := model.Category{CategoryName: "Backend"}
+	require.NoError(t, db.Create(&cat).Error)
+	course := model.Course{CourseName: "Go", CourseImage: "img", CategoryID: cat.Id}
+	require.NoError(t, db.Create(&course).Error)
+
+	got, err := c.GetLifecycle(course.Id)
+	require.NoError(t, err)
+	require.Equal(t, model.CourseDraft, got.CourseStatus)
+	_, err = c.GetLifecycle(uuid.New())
+	require.Error(t, err)
+
+	changed, err := c.SetStatus(course.Id, model.CourseDraft, model.CourseInReview)
+	require.NoError(t, err)
+	require.True(t, changed)
+	// the course already left draft
+	changed, err = c.SetStatus(course.Id, model.CourseDraft, model.CourseInReview)
+	require.NoError(t, err)
+	require.False(t, changed)
+
+	publishAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	require.NoError(t, c.SetSchedule(course.Id, &publishAt, nil))
+	got, err = c.GetLifecycle(course.Id)
+	require.NoError(t, err)
+	require.Equal(t, model.CourseInReview, got.CourseStatus)
+	require.NotNil(t, got.PublishAt)
+	require.True(t, publishAt.Equal(*got.PublishAt))
+	require.Nil(t, got.UnpublishAt)
+
+	require.NoError(t, c.SetSchedule(course.Id, nil, nil))
+	got, err = c.GetLifecycle(course.Id)
+	require.NoError(t, err)
+	require.Nil(t, got.PublishAt)
+}
@@ -0,0 +1,223 @@
+package curriculum
+
+import (
+	"errors"
+	"net/http"
+
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SectionOrder is where a section goes in a reorder: its lessons, in
+// order. Lessons may come from another section of the same course.
+type SectionOrder struct {
+	SectionId uuid.UUID
+	LessonIds []uuid.UUID
+}
+
+type CurriculumClient struct {
+	Db *gorm.DB
+}
+
+func NewCurriculumClient(db *gorm.DB) *CurriculumClient {
+	return &CurriculumClient{Db: db}
+}
+
+func (c *CurriculumClient) CourseExists(courseId uuid.UUID) (bool, error) {
+	var count int64
+	if err := c.Db.Model(&model.Course{}).Where("id = ?", courseId).Count(&count).Error; err != nil {
+		return false, customError.DBError(err)
+	}
+	return count > 0, nil
+}
+
+// GetOutline returns the sections and lessons of a course in order. The
+// lessons come without their body and url.
+func (c *CurriculumClient) GetOutline(courseId uuid.UUID) (model.Sections, model.Lessons, error) {
+	sections := model.Sections{}
+	err := c.Db.Where("course_id = ?", courseId).
+		Order("position, created_at").
+		Find(&sections).Error
+	if err != nil {
+		return nil, nil, customError.DBError(err)
+	}
+	lessons := model.Lessons{}
+	err = c.Db.Omit("body", "url").
+		Where("course_id = ?", courseId).
+		Order("position, created_at").
+		Find(&lessons).Error
+	if err != nil {
+		return nil, nil, customError.DBError(err)
+	}
+	return sections, lessons, nil
+}
+
+func (c *CurriculumClient) GetSection(courseId uuid.UUID, sectionId uuid.UUID) (model.Section, error) {
+	var section model.Section
+	err := c.Db.Where("id = ? AND course_id = ?", sectionId, courseId).First(&section).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.Section{}, customError.NewError("SECTION_NOT_FOUND", "Section not found", http.StatusNotFound)
+		}
+		return model.Section{}, customError.DBError(err)
+	}
+	return section, nil
+}
+
+// CreateSection adds the section after the last one of its course.
+func (c *CurriculumClient) CreateSection(section model.Section) (model.Section, error) {
+	err := c.Db.Transaction(func(tx *gorm.DB) error {
+		position, err := nextPosition(tx.Model(&model.Section{}).Where("course_id = ?", section.CourseId))
+		if err != nil {
+			return err
+		}
+		section.Position = position
+		return tx.Create(&section).Error
+	})
+	if err != nil {
+		return model.Section{}, customError.DBError(err)
+	}
+	return section, nil
+}
+
+func (c *CurriculumClient) UpdateSection(section model.Section) (model.Section, error) {
+	err := c.Db.Model(&model.Section{}).Where("id = ?", section.Id).Update("title", section.Title).Error
+	if err != nil {
+		return model.Section{}, customError.DBError(err)
+	}
+	return section, nil
+}
+
+// DeleteSection deletes a section with its lessons.
+func (c *CurriculumClient) DeleteSection(section model.Section) error {
+	err := c.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("section_id = ?", section.Id).Delete(&model.Lesson{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", section.Id).Delete(&model.Section{}).Error
+	})
+	if err != nil {
+		return customError.DBError(err)
+	}
+	return nil
+}
+
+func (c *CurriculumClient) GetLesson(courseId uuid.UUID, lessonId uuid.UUID) (model.Lesson, error) {
+	var lesson model.Lesson
+	err := c.Db.Where("id = ? AND course_id = ?", lessonId, courseId).First(&lesson).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.Lesson{}, customError.NewError("LESSON_NOT_FOUND", "Lesson not found", http.StatusNotFound)
+		}
+		return model.Lesson{}, customError.DBError(err)
+	}
+	return lesson, nil
+}
+
+// CreateLesson adds the lesson after the last one of its section.
+func (c *CurriculumClient) CreateLesson(lesson model.Lesson) (model.Lesson, error) {
+	err := c.Db.Transaction(func(tx *gorm.DB) error {
+		position, err := nextPosition(tx.Model(&model.Lesson{}).Where("section_id = ?", lesson.SectionId))
+		if err != nil {
+			return err
+		}
+		lesson.Position = position
+		return tx.Create(&lesson).Error
+	})
+	if err != nil {
+		return model.Lesson{}, customError.DBError(err)
+	}
+	return lesson, nil
+}
+
+// UpdateLesson saves the content of a lesson; its section and position are
+// changed with Reorder.
+func (c *CurriculumClient) UpdateLesson(lesson model.Lesson) (model.Lesson, error) {
+	err := c.Db.Model(&model.Lesson{}).Where("id = ?", lesson.Id).Updates(map[string]interface{}{
+		"title":    lesson.Title,
+		"type":     lesson.Type,
+		"body":     lesson.Body,
+		"url":      lesson.Url,
+		"duration": lesson.Duration,
+	}).Error
+	if err != nil {
+		return model.Lesson{}, customError.DBError(err)
+	}
+	return lesson, nil
+}
+
+func (c *CurriculumClient) DeleteLesson(lesson model.Lesson) error {
+	if err := c.Db.Where("id = ?", lesson.Id).Delete(&model.Lesson{}).Error; err != nil {
+		return customError.DBError(err)
+	}
+	return nil
+}
+
+// Reorder rewrites the positions of every section and lesson of a course.
+// The order has to list each section and each lesson of the course exactly
+// once, so a stale order can't lose or duplicate anything.
+func (c *CurriculumClient) Reorder(courseId uuid.UUID, order []SectionOrder) error {
+	invalid := customError.NewError("INVALID_ORDER", "The order must list every section and lesson of the course once", http.StatusBadRequest)
+	err := c.Db.Transaction(func(tx *gorm.DB) error {
+		var sectionIds, lessonIds []uuid.UUID
+		if err := tx.Model(&model.Section{}).Where("course_id = ?", courseId).Pluck("id", &sectionIds).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.Lesson{}).Where("course_id = ?", courseId).Pluck("id", &lessonIds).Error; err != nil {
+			return err
+		}
+		sections, lessons := setOf(sectionIds), setOf(lessonIds)
+		for _, section := range order {
+			if !sections[section.SectionId] {
+				return invalid
+			}
+			delete(sections, section.SectionId)
+			for _, lessonId := range section.LessonIds {
+				if !lessons[lessonId] {
+					return invalid
+				}
+				delete(lessons, lessonId)
+			}
+		}
+		if len(sections) > 0 || len(lessons) > 0 {
+			return invalid
+		}
+
+		for i, section := range order {
+			if err := tx.Model(&model.Section{}).Where("id = ?", section.SectionId).Update("position", i).Error; err != nil {
+				return err
+			}
+			for j, lessonId := range section.LessonIds {
+				err := tx.Model(&model.Lesson{}).Where("id = ?", lessonId).
+					Updates(map[string]interface{}{"section_id": section.SectionId, "position": j}).Error
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if err == invalid {
+			return err
+		}
+		return customError.DBError(err)
+	}
+	return nil
+}
+
+func nextPosition(db *gorm.DB) (int, error) {
+	var position int
+	err := db.Select("COALESCE(MAX(position), -1) + 1").Scan(&position).Error
+	return position, err
+}
+
+func setOf(ids []uuid.UUID) map[uuid.UUID]bool {
+	set := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
@@ -0,0 +1,114 @@
+package curriculum
+
+import (
+	"testing"
+
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	github_com_glebarez_sqlite "github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func makeDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(github_com_glebarez_sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	require.NoError(t, db.AutoMigrate(&model.Course{}, &model.Section{}, &model.Lesson{}))
+	return db
+}
+
+func requireCode(t *testing.T, err error, code string) {
+	t.Helper()
+	var e *customError.Error
+	require.ErrorAs(t, err, &e)
+	require.Equal(t, code, e.Code)
+}
+
+func TestCurriculumClient_SectionsAndLessons(t *testing.T) {
+	c := NewCurriculumClient(makeDB(t))
+	courseId := uuid.New()
+
+	intro, err := c.CreateSection(model.Section{CourseId: courseId, Title: "Intro"})
+	require.NoError(t, err)
+	basics, err := c.CreateSection(model.Section{CourseId: courseId, Title: "Basics"})
+	require.NoError(t, err)
+	require.Equal(t, 0, intro.Position)
+	require.Equal(t, 1, basics.Position)
+
+	welcome, err := c.CreateLesson(model.Lesson{CourseId: courseId, SectionId: intro.Id, Title: "Welcome", Type: model.LessonText, Body: "# Hi"})
+	require.NoError(t, err)
+	video, err := c.CreateLesson(model.Lesson{CourseId: courseId, SectionId: intro.Id, Title: "Setup", Type: model.LessonVideo, Url: "https://videos.example.com/1"})
+	require.NoError(t, err)
+	require.Equal(t, 1, video.Position)
+
+	sections, lessons, err := c.GetOutline(courseId)
+	require.NoError(t, err)
+	require.Len(t, sections, 2)
+	require.Len(t, lessons, 2)
+	// the outline leaves the content out
+	require.Empty(t, lessons[0].Body)
+	require.Empty(t, lessons[1].Url)
+
+	found, err := c.GetLesson(courseId, welcome.Id)
+	require.NoError(t, err)
+	require.Equal(t, "# Hi", found.Body)
+	_, err = c.GetLesson(uuid.New(), welcome.Id)
+	requireCode(t, err, "LESSON_NOT_FOUND")
+	_, err = c.GetSection(uuid.New(), intro.Id)
+	requireCode(t, err, "SECTION_NOT_FOUND")
+
+	found.Title, found.Body = "Welcome!", "# Hello"
+	_, err = c.UpdateLesson(found)
+	require.NoError(t, err)
+	found, err = c.GetLesson(courseId, welcome.Id)
+	require.NoError(t, err)
+	require.Equal(t, "Welcome!", found.Title)
+	require.Equal(t, "# Hello", found.Body)
+
+	require.NoError(t, c.DeleteSection(intro))
+	sections, lessons, err = c.GetOutline(courseId)
+	require.NoError(t, err)
+	require.Len(t, sections, 1)
+	require.Empty(t, lessons)
+}
+
+func TestCurriculumClient_Reorder(t *testing.T) {
+	c := NewCurriculumClient(makeDB(t))
+	courseId := uuid.New()
+	first, err := c.CreateSection(model.Section{CourseId: courseId, Title: "First"})
+	require.NoError(t, err)
+	second, err := c.CreateSection(model.Section{CourseId: courseId, Title: "Second"})
+	require.NoError(t, err)
+	a, err := c.CreateLesson(model.Lesson{CourseId: courseId, SectionId: first.Id, Title: "A", Type: model.LessonText})
+	require.NoError(t, err)
+	b, err := c.CreateLesson(model.Lesson{CourseId: courseId, SectionId: first.Id, Title: "B", Type: model.LessonText})
+	require.NoError(t, err)
+
+	// swap the sections and move A into the second one
+	require.NoError(t, c.Reorder(courseId, []SectionOrder{
+		{SectionId: second.Id, LessonIds: []uuid.UUID{a.Id}},
+		{SectionId: first.Id, LessonIds: []uuid.UUID{b.Id}},
+	}))
+	sections, lessons, err := c.GetOutline(courseId)
+	require.NoError(t, err)
+	require.Equal(t, "Second", sections[0].Title)
+	moved, err := c.GetLesson(courseId, a.Id)
+	require.NoError(t, err)
+	require.Equal(t, second.Id, moved.SectionId)
+	require.Equal(t, 0, moved.Position)
+	require.Len(t, lessons, 2)
+
+	// missing, repeated or foreign ids are rejected
+	requireCode(t, c.Reorder(courseId, []SectionOrder{{SectionId: first.Id, LessonIds: []uuid.UUID{a.Id, b.Id}}}), "INVALID_ORDER")
+	requireCode(t, c.Reorder(courseId, []SectionOrder{
+		{SectionId: first.Id, LessonIds: []uuid.UUID{a.Id, a.Id}},
+		{SectionId: second.Id, LessonIds: []uuid.UUID{b.Id}},
+	}), "INVALID_ORDER")
+	requireCode(t, c.Reorder(courseId, []SectionOrder{
+		{SectionId: first.Id, LessonIds: []uuid.UUID{a.Id, b.Id, uuid.New()}},
+		{SectionId: second.Id},
+	}), "INVALID_ORDER")
+}
@@ -0,0 +1,62 @@
+package emailverification
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type EmailVerificationClient struct {
+	Db *gorm.DB
+}
+
+func NewEmailVerificationClient(db *gorm.DB) *EmailVerificationClient {
+	return &EmailVerificationClient{Db: db}
+}
+
+func (c *EmailVerificationClient) Create(verification model.EmailVerification) (model.EmailVerification, error) {
+	result := c.Db.Create(&verification)
+	if result.Error != nil {
+		return model.EmailVerification{}, customError.DBError(result.Error)
+	}
+	return verification, nil
+}
+
+func (c *EmailVerificationClient) FindByHash(hash string) (model.EmailVerification, error) {
+	var verification model.EmailVerification
+	err := c.Db.Where("token_hash = ?", hash).First(&verification).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.EmailVerification{}, customError.NewError("NOT_FOUND", "Email verification not found", http.StatusNotFound)
+		}
+		return model.EmailVerification{}, customError.NewError("DB_ERROR", "Error retrieving email verification from database", http.StatusInternalServerError)
+	}
+	return verification, nil
+}
+
+// MarkUsed consumes a verification token; it reports false if it was already used.
+func (c *EmailVerificationClient) MarkUsed(id uint, at time.Time) (bool, error) {
+	result := c.Db.Model(&model.EmailVerification{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", at)
+	if result.Error != nil {
+		return false, customError.DBError(result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// InvalidateForUser consumes every outstanding verification token of a user.
+func (c *EmailVerificationClient) InvalidateForUser(userId uuid.UUID, at time.Time) error {
+	result := c.Db.Model(&model.EmailVerification{}).
+		Where("user_id = ? AND used_at IS NULL", userId).
+		Update("used_at", at)
+	if result.Error != nil {
+		return customError.DBError(result.Error)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package identities
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"gorm.io/gorm"
+)
+
+type IdentitiesClient struct {
+	Db *gorm.DB
+}
+
+func NewIdentitiesClient(db *gorm.DB) *IdentitiesClient {
+	return &IdentitiesClient{Db: db}
+}
+
+func (c *IdentitiesClient) FindBySubject(provider string, subject string) (model.ExternalIdentity, error) {
+	var identity model.ExternalIdentity
+	err := c.Db.Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.ExternalIdentity{}, customError.NewError("NOT_FOUND", "External identity not found", http.StatusNotFound)
+		}
+		return model.ExternalIdentity{}, customError.NewError("DB_ERROR", "Error retrieving external identity from database", http.StatusInternalServerError)
+	}
+	return identity, nil
+}
+
+func (c *IdentitiesClient) Create(identity model.ExternalIdentity) (model.ExternalIdentity, error) {
+	result := c.Db.Create(&identity)
+	if result.Error != nil {
+		return model.ExternalIdentity{}, dbError(result.Error)
+	}
+	return identity, nil
+}
+
+func dbError(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrDuplicatedKey),
+		strings.Contains(err.Error(), "duplicate key value violates unique constraint"),
+		strings.Contains(err.Error(), "UNIQUE constraint failed"):
+		return customError.NewError(
+			"DUPLICATE_IDENTITY",
+			"This external account is already linked to a user.",
+			http.StatusConflict)
+	default:
+		return customError.DBError(err)
+	}
+}
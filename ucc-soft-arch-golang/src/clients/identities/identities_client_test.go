@@ -0,0 +1,43 @@
+package identities
+
+import (
+	"testing"
+
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	github_com_glebarez_sqlite "github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func makeDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(github_com_glebarez_sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	require.NoError(t, db.AutoMigrate(&model.ExternalIdentity{}))
+	return db
+}
+
+func TestIdentitiesClient_CreateAndFind(t *testing.T) {
+	c := NewIdentitiesClient(makeDB(t))
+	userId := uuid.New()
+	_, err := c.Create(model.ExternalIdentity{UserId: userId, Provider: "https://idp", Subject: "sub-1"})
+	require.NoError(t, err)
+
+	found, err := c.FindBySubject("https://idp", "sub-1")
+	require.NoError(t, err)
+	require.Equal(t, userId, found.UserId)
+
+	_, err = c.FindBySubject("https://other-idp", "sub-1")
+	require.Equal(t, "NOT_FOUND", err.(*customError.Error).Code)
+}
+
+func TestIdentitiesClient_CreateDuplicate(t *testing.T) {
+	c := NewIdentitiesClient(makeDB(t))
+	_, err := c.Create(model.ExternalIdentity{UserId: uuid.New(), Provider: "https://idp", Subject: "sub-1"})
+	require.NoError(t, err)
+	_, err = c.Create(model.ExternalIdentity{UserId: uuid.New(), Provider: "https://idp", Subject: "sub-1"})
+	require.Equal(t, "DUPLICATE_IDENTITY", err.(*customError.Error).Code)
+}
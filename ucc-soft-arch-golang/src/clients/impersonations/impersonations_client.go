@@ -0,0 +1,50 @@
+package impersonations
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ImpersonationsClient struct {
+	Db *gorm.DB
+}
+
+func NewImpersonationsClient(db *gorm.DB) *ImpersonationsClient {
+	return &ImpersonationsClient{Db: db}
+}
+
+func (c *ImpersonationsClient) Create(impersonation model.Impersonation) (model.Impersonation, error) {
+	if err := c.Db.Create(&impersonation).Error; err != nil {
+		return model.Impersonation{}, customError.DBError(err)
+	}
+	return impersonation, nil
+}
+
+func (c *ImpersonationsClient) FindById(id uuid.UUID) (model.Impersonation, error) {
+	var impersonation model.Impersonation
+	err := c.Db.Where("id = ?", id).First(&impersonation).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.Impersonation{}, customError.NewError("NOT_FOUND", "Impersonation not found", http.StatusNotFound)
+		}
+		return model.Impersonation{}, customError.DBError(err)
+	}
+	return impersonation, nil
+}
+
+// End ends an impersonation. It reports false when it had already ended.
+func (c *ImpersonationsClient) End(id uuid.UUID, at time.Time) (bool, error) {
+	result := c.Db.Model(&model.Impersonation{}).
+		Where("id = ? AND ended_at IS NULL", id).
+		Update("ended_at", at)
+	if result.Error != nil {
+		return false, customError.DBError(result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,49 @@
+package impersonations
+
+import (
+	"testing"
+	"time"
+
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	github_com_glebarez_sqlite "github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func makeDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(github_com_glebarez_sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	require.NoError(t, db.AutoMigrate(&model.Impersonation{}))
+	return db
+}
+
+func TestImpersonationsClient_CreateFindEnd(t *testing.T) {
+	c := NewImpersonationsClient(makeDB(t))
+	now := time.Now()
+	created, err := c.Create(model.Impersonation{ActorId: uuid.New(), UserId: uuid.New(), Reason: "ticket 42", ExpiresAt: now.Add(time.Hour)})
+	require.NoError(t, err)
+	require.NotEqual(t, uuid.Nil, created.Id)
+
+	found, err := c.FindById(created.Id)
+	require.NoError(t, err)
+	require.Equal(t, "ticket 42", found.Reason)
+	require.Nil(t, found.EndedAt)
+
+	ended, err := c.End(created.Id, now)
+	require.NoError(t, err)
+	require.True(t, ended)
+	ended, err = c.End(created.Id, now)
+	require.NoError(t, err)
+	require.False(t, ended)
+	found, _ = c.FindById(created.Id)
+	require.NotNil(t, found.EndedAt)
+
+	_, err = c.FindById(uuid.New())
+	ce, ok := err.(*customError.Error)
+	require.True(t, ok)
+	require.Equal(t, "NOT_FOUND", ce.Code)
+}
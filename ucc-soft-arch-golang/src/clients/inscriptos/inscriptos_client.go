@@ -5,11 +5,13 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
 	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type InscriptosClient struct {
@@ -20,34 +22,268 @@ func NewInscriptionClient(db *gorm.DB) *InscriptosClient {
 	return &InscriptosClient{Db: db}
 }
 
-func (c *InscriptosClient) Enroll(inscripto model.Inscripto) (model.Inscripto, error) {
-	result := c.Db.Create(&inscripto)
+// Enroll takes a seat in the course, or puts the user on its waitlist when
+// it is full. Courses with cohorts are enrolled in through one of them,
+// while its enrollment window is open at now, and the seats are the
+// cohort's. The course and cohort rows are locked on postgres so
+// concurrent enrollments can't take more seats than there are.
+func (c *InscriptosClient) Enroll(inscripto model.Inscripto, now time.Time) (model.Inscripto, error) {
+	err := c.Db.Transaction(func(tx *gorm.DB) error {
+		course, err := lockCourse(tx, inscripto.CourseId)
+		if err != nil {
+			return err
+		}
+		pool := seatPool{CourseId: course.Id, Capacity: course.CourseCapacity}
+		if inscripto.CohortId == nil {
+			var cohorts int64
+			if err := tx.Model(&model.Cohort{}).Where("course_id = ?", course.Id).Count(&cohorts).Error; err != nil {
+				return err
+			}
+			if cohorts > 0 {
+				return customError.NewError("COHORT_REQUIRED", "This course is enrolled in through one of its cohorts", http.StatusBadRequest)
+			}
+		} else {
+			cohort, err := lockCohort(tx, *inscripto.CohortId)
+			if err != nil {
+				return err
+			}
+			if cohort.CourseId != course.Id || cohort.DeletedAt.Valid {
+				return customError.NewError("COHORT_NOT_FOUND", "Cohort not found", http.StatusNotFound)
+			}
+			if !cohort.EnrollmentOpen(now) {
+				return customError.NewError("ENROLLMENT_CLOSED", "Enrollment in this cohort is closed", http.StatusConflict)
+			}
+			pool = seatPool{CourseId: course.Id, CohortId: &cohort.Id, Capacity: cohort.Capacity}
+		}
+		var existing model.Inscripto
+		err = tx.Where("user_id = ? AND course_id = ?", inscripto.UserId, inscripto.CourseId).First(&existing).Error
+		if err == nil {
+			if existing.Status == model.EnrollmentWaitlisted {
+				return customError.NewError("ALREADY_WAITLISTED", "User is already on the waitlist", http.StatusConflict)
+			}
+			return customError.NewError("USER_ALREADY_ENROLLED", "User is already enrolled", http.StatusBadRequest)
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
 
-	if result.Error != nil {
-		var err error
-		switch {
-		case strings.Contains(result.Error.Error(), "connection"):
-			err = customError.NewError(
-				"DB_CONNECTION_ERROR",
-				"Database connection error. Please try again later.",
-				http.StatusInternalServerError)
-		default:
-			err = customError.NewError(
-				"UNEXPECTED_ERROR",
-				"An unexpected error occurred. Please try again later.",
-				http.StatusInternalServerError)
+		// people already waiting go first if seats were added meanwhile
+		if _, err := fillSeats(tx, pool); err != nil {
+			return err
 		}
-		return model.Inscripto{}, err
+		active, err := countActive(tx, pool)
+		if err != nil {
+			return err
+		}
+		inscripto.Status = model.EnrollmentActive
+		if active >= int64(pool.Capacity) {
+			inscripto.Status = model.EnrollmentWaitlisted
+		}
+		return tx.Create(&inscripto).Error
+	})
+	if err != nil {
+		return model.Inscripto{}, txError(err)
 	}
 	return inscripto, nil
 }
 
+// Unenroll removes the user from the course or its waitlist. A freed seat
+// goes to the next users waiting for the same course or cohort, whose ids
+// are returned.
+func (c *InscriptosClient) Unenroll(userId uuid.UUID, courseId uuid.UUID) ([]uuid.UUID, error) {
+	var promoted []uuid.UUID
+	err := c.Db.Transaction(func(tx *gorm.DB) error {
+		course, err := lockCourse(tx, courseId)
+		if err != nil {
+			return err
+		}
+		var enrollment model.Inscripto
+		err = tx.Where("user_id = ? AND course_id = ?", userId, courseId).First(&enrollment).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return customError.NewError("NOT_ENROLLED", "User is not enrolled in this course", http.StatusNotFound)
+		}
+		if err != nil {
+			return err
+		}
+		// completed enrollments are what prerequisites are checked against
+		if enrollment.CompletedAt != nil {
+			return customError.NewError("COURSE_COMPLETED", "A completed course can't be left", http.StatusConflict)
+		}
+		pool := seatPool{CourseId: course.Id, Capacity: course.CourseCapacity}
+		if enrollment.CohortId != nil {
+			cohort, err := lockCohort(tx, *enrollment.CohortId)
+			if err != nil {
+				return err
+			}
+			pool = seatPool{CourseId: course.Id, CohortId: &cohort.Id, Capacity: cohort.Capacity}
+		}
+		if err := tx.Delete(&enrollment).Error; err != nil {
+			return err
+		}
+		promoted, err = fillSeats(tx, pool)
+		return err
+	})
+	if err != nil {
+		return nil, txError(err)
+	}
+	return promoted, nil
+}
+
+// FillSeats promotes users waiting for the course itself while it has free
+// seats, for when its capacity grows.
+func (c *InscriptosClient) FillSeats(courseId uuid.UUID) ([]uuid.UUID, error) {
+	var promoted []uuid.UUID
+	err := c.Db.Transaction(func(tx *gorm.DB) error {
+		course, err := lockCourse(tx, courseId)
+		if err != nil {
+			return err
+		}
+		promoted, err = fillSeats(tx, seatPool{CourseId: course.Id, Capacity: course.CourseCapacity})
+		return err
+	})
+	if err != nil {
+		return nil, txError(err)
+	}
+	return promoted, nil
+}
+
+// FillCohortSeats is FillSeats for the users waiting for a cohort.
+func (c *InscriptosClient) FillCohortSeats(cohortId uuid.UUID) ([]uuid.UUID, error) {
+	var promoted []uuid.UUID
+	err := c.Db.Transaction(func(tx *gorm.DB) error {
+		cohort, err := lockCohort(tx, cohortId)
+		if err != nil {
+			return err
+		}
+		promoted, err = fillSeats(tx, seatPool{CourseId: cohort.CourseId, CohortId: &cohort.Id, Capacity: cohort.Capacity})
+		return err
+	})
+	if err != nil {
+		return nil, txError(err)
+	}
+	return promoted, nil
+}
+
+// WaitlistPositions returns, for each course the user is waiting for, how
+// many people are ahead of them in the same course or cohort plus one.
+func (c *InscriptosClient) WaitlistPositions(userId uuid.UUID) (map[uuid.UUID]int, error) {
+	var rows []map[string]interface{}
+	err := c.Db.Raw(`SELECT me.course_id, COUNT(ahead.id) AS position
+		FROM inscriptos me
+		JOIN inscriptos ahead ON ahead.course_id = me.course_id
+			AND (ahead.cohort_id = me.cohort_id OR (ahead.cohort_id IS NULL AND me.cohort_id IS NULL))
+			AND ahead.status = ?
+			AND ahead.deleted_at IS NULL
+			AND ahead.id <= me.id
+		WHERE me.user_id = ? AND me.status = ? AND me.deleted_at IS NULL
+		GROUP BY me.course_id`, model.EnrollmentWaitlisted, userId, model.EnrollmentWaitlisted).Scan(&rows).Error
+	if err != nil {
+		return nil, customError.DBError(err)
+	}
+	positions := make(map[uuid.UUID]int, len(rows))
+	for _, row := range rows {
+		positions[parseUUID(row["course_id"])] = toInt(row["position"])
+	}
+	return positions, nil
+}
+
+// seatPool is what enrollments compete for: the seats of a course, or of
+// one of its cohorts when CohortId is set.
+type seatPool struct {
+	CourseId uuid.UUID
+	CohortId *uuid.UUID
+	Capacity int
+}
+
+func (p seatPool) enrollments(tx *gorm.DB) *gorm.DB {
+	query := tx.Model(&model.Inscripto{}).Where("course_id = ?", p.CourseId)
+	if p.CohortId == nil {
+		return query.Where("cohort_id IS NULL")
+	}
+	return query.Where("cohort_id = ?", *p.CohortId)
+}
+
+// lockCourse reads the course capacity, holding the row until the
+// transaction ends on postgres. Deleted courses are still found so their
+// students can leave.
+func lockCourse(tx *gorm.DB, courseId uuid.UUID) (model.Course, error) {
+	query := tx.Unscoped().Select("id", "course_capacity")
+	if tx.Dialector.Name() == "postgres" {
+		query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+	var course model.Course
+	if err := query.First(&course, "id = ?", courseId).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.Course{}, customError.NewError("NOT_FOUND", "Course not found", http.StatusNotFound)
+		}
+		return model.Course{}, err
+	}
+	return course, nil
+}
+
+// lockCohort is lockCourse for a cohort. Deleted cohorts are found too;
+// callers enrolling in one must check.
+func lockCohort(tx *gorm.DB, cohortId uuid.UUID) (model.Cohort, error) {
+	query := tx.Unscoped()
+	if tx.Dialector.Name() == "postgres" {
+		query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+	var cohort model.Cohort
+	if err := query.First(&cohort, "id = ?", cohortId).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.Cohort{}, customError.NewError("COHORT_NOT_FOUND", "Cohort not found", http.StatusNotFound)
+		}
+		return model.Cohort{}, err
+	}
+	return cohort, nil
+}
+
+func countActive(tx *gorm.DB, pool seatPool) (int64, error) {
+	var count int64
+	err := pool.enrollments(tx).
+		Where("status = ?", model.EnrollmentActive).
+		Count(&count).Error
+	return count, err
+}
+
+// fillSeats must run with the course or cohort locked.
+func fillSeats(tx *gorm.DB, pool seatPool) ([]uuid.UUID, error) {
+	active, err := countActive(tx, pool)
+	if err != nil {
+		return nil, err
+	}
+	free := int64(pool.Capacity) - active
+	if free <= 0 {
+		return nil, nil
+	}
+	var next []model.Inscripto
+	err = pool.enrollments(tx).
+		Where("status = ?", model.EnrollmentWaitlisted).
+		Order("id").
+		Limit(int(free)).
+		Find(&next).Error
+	if err != nil || len(next) == 0 {
+		return nil, err
+	}
+	ids := make([]uint, 0, len(next))
+	promoted := make([]uuid.UUID, 0, len(next))
+	for _, enrollment := range next {
+		ids = append(ids, enrollment.ID)
+		promoted = append(promoted, enrollment.UserId)
+	}
+	err = tx.Model(&model.Inscripto{}).Where("id IN ?", ids).Update("status", model.EnrollmentActive).Error
+	if err != nil {
+		return nil, err
+	}
+	return promoted, nil
+}
+
 func (c *InscriptosClient) GetMyCourses(id uuid.UUID) (model.Courses, error) {
 	var rawResults []map[string]interface{}
 	err := c.Db.Raw(`
 	SELECT C.*, CAT.category_name
 		FROM courses C
-		JOIN inscriptos I ON I.course_id = C.id
+		JOIN inscriptos I ON I.course_id = C.id AND I.deleted_at IS NULL
 		JOIN users U ON I.user_id = U.id
 		JOIN categories CAT ON C.category_id = CAT.id
 		WHERE I.user_id = ?
@@ -63,6 +299,7 @@ func (c *InscriptosClient) GetMyCourses(id uuid.UUID) (model.Courses, error) {
 	}
 	var courses model.Courses
 	for i := 0; i < len(rawResults); i++ {
+		status, _ := rawResults[i]["course_status"].(string)
 		course := model.Course{
 			Id:                parseUUID(rawResults[i]["id"]),
 			CourseName:        rawResults[i]["course_name"].(string),
@@ -70,7 +307,7 @@ func (c *InscriptosClient) GetMyCourses(id uuid.UUID) (model.Courses, error) {
 			CoursePrice:       toFloat64(rawResults[i]["course_price"]),
 			CourseDuration:    toInt(rawResults[i]["course_duration"]),
 			CourseInitDate:    rawResults[i]["course_init_date"].(string),
-			CourseState:       toBool(rawResults[i]["course_state"]),
+			CourseStatus:      status,
 			CourseCapacity:    toInt(rawResults[i]["course_capacity"]),
 			CourseImage:       rawResults[i]["course_image"].(string),
 			CategoryID:        parseUUID(rawResults[i]["category_id"]),
@@ -88,8 +325,8 @@ func (c *InscriptosClient) GetMyStudents(id uuid.UUID) (model.Users, error) {
 	err := c.Db.Raw(`
 		SELECT  U.name, U.avatar, U.id as User_id
 		FROM inscriptos I, users U
-		WHERE I.user_id = U.id AND I.course_id = ?
-	`, id).Scan(&rawResults).Error
+		WHERE I.user_id = U.id AND I.course_id = ? AND I.status = ? AND I.deleted_at IS NULL
+	`, id, model.EnrollmentActive).Scan(&rawResults).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, customError.NewError("STUDENTS_NOT_FOUND", "No Students found for the specified course", http.StatusNotFound)
@@ -116,29 +353,41 @@ func (c *InscriptosClient) GetMyStudents(id uuid.UUID) (model.Users, error) {
 }
 
 // MIDDLEWARE FUNC
+// IsUserEnrolled reports whether the user has a seat; waiting doesn't count.
 func (c *InscriptosClient) IsUserEnrolled(userID uuid.UUID, courseID uuid.UUID) (bool, error) {
 	var count int64
 	err := c.Db.Model(&model.Inscripto{}).
-		Where("user_id = ? AND course_id = ?", userID, courseID).
+		Where("user_id = ? AND course_id = ? AND status = ?", userID, courseID, model.EnrollmentActive).
 		Count(&count).Error
 	if err != nil {
 		return false, err
 	}
-	fmt.Println("Enrolled count: ", count)
 	return count > 0, nil
 }
-func (c *InscriptosClient) CourseExist(course_id uuid.UUID) (bool, error) {
+
+// CourseExist reports whether the course exists and is open to everyone
+// at now; drafts can't be enrolled in.
+func (c *InscriptosClient) CourseExist(course_id uuid.UUID, now time.Time) (bool, error) {
 	var count int64
 	err := c.Db.Model(&model.Course{}).
 		Where("Id = ?", course_id).
+		Where(model.LiveCourses("courses", now)).
 		Count(&count).Error
 	if err != nil {
 		return false, err
 	}
-	fmt.Println("Count: ", count)
 	return count > 0, nil
 }
 
+// txError keeps the errors a transaction vetoed with and hides the rest.
+func txError(err error) error {
+	var vetoed *customError.Error
+	if errors.As(err, &vetoed) {
+		return vetoed
+	}
+	return customError.DBError(err)
+}
+
 // FUNCION PARA PARSEAR UUID
 func parseUUID(value interface{}) uuid.UUID {
 	if value != nil {
@@ -2,12 +2,14 @@ package inscriptos
 
 import (
 	"testing"
+	"time"
 
 	github_com_glebarez_sqlite "github.com/glebarez/sqlite"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
 
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
 	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
 )
 
@@ -16,7 +18,7 @@ func setupInscriptosDB(t *testing.T) *gorm.DB {
 	if err != nil {
 		t.Fatalf("failed to open sqlite db: %v", err)
 	}
-	require.NoError(t, db.AutoMigrate(&model.User{}, &model.Course{}, &model.Inscripto{}, &model.Category{}))
+	require.NoError(t, db.AutoMigrate(&model.User{}, &model.Course{}, &model.Inscripto{}, &model.Category{}, &model.Cohort{}))
 	return db
 }
 
@@ -27,19 +29,19 @@ func TestInscriptosClient_Enroll_IsUserEnrolled_CourseExist_GetMyStudents(t *tes
 	// seed category, course, user
 	cat := model.Category{CategoryName: "Backend"}
 	require.NoError(t, db.Create(&cat).Error)
-	course := model.Course{CourseName: "Golang", CourseDescription: "intro", CoursePrice: 10, CourseDuration: 8, CourseInitDate: "2025-01-01", CourseState: false, CourseCapacity: 30, CourseImage: "img", CategoryID: cat.Id}
+	course := model.Course{CourseName: "Golang", CourseDescription: "intro", CoursePrice: 10, CourseDuration: 8, CourseInitDate: "2025-01-01", CourseStatus: model.CoursePublished, CourseCapacity: 30, CourseImage: "img", CategoryID: cat.Id}
 	require.NoError(t, db.Create(&course).Error)
 	u := model.User{Name: "Alice", Avatar: "pic.png", Email: "a@b.com", Password: "x"}
 	require.NoError(t, db.Create(&u).Error)
 
-	// CourseExist should be true after creating course
-	ok, err := c.CourseExist(course.Id)
+	// CourseExist should be true after publishing the course
+	ok, err := c.CourseExist(course.Id, time.Now())
 	require.NoError(t, err)
 	require.True(t, ok)
 
 	// Enroll
 	ins := model.Inscripto{UserId: u.Id, CourseId: course.Id}
-	_, err = c.Enroll(ins)
+	_, err = c.Enroll(ins, time.Now())
 	require.NoError(t, err)
 
 	// IsUserEnrolled
@@ -63,7 +65,7 @@ func TestInscriptosClient_IsUserEnrolled_And_CourseExist_False(t *testing.T) {
 	// Create a course but no enrollment
 	cat := model.Category{CategoryName: "Backend"}
 	require.NoError(t, db.Create(&cat).Error)
-	course := model.Course{CourseName: "Golang", CourseDescription: "intro", CoursePrice: 10, CourseDuration: 8, CourseInitDate: "2025-01-01", CourseState: false, CourseCapacity: 30, CourseImage: "img", CategoryID: cat.Id}
+	course := model.Course{CourseName: "Golang", CourseDescription: "intro", CoursePrice: 10, CourseDuration: 8, CourseInitDate: "2025-01-01", CourseStatus: model.CourseDraft, CourseCapacity: 30, CourseImage: "img", CategoryID: cat.Id}
 	require.NoError(t, db.Create(&course).Error)
 	u := model.User{Name: "Bob", Avatar: "a.png", Email: "b@b.com", Password: "x"}
 	require.NoError(t, db.Create(&u).Error)
@@ -72,7 +74,12 @@ func TestInscriptosClient_IsUserEnrolled_And_CourseExist_False(t *testing.T) {
 	require.NoError(t, err)
 	require.False(t, enrolled)
 
-	exists, err := c.CourseExist(uuid.New())
+	exists, err := c.CourseExist(uuid.New(), time.Now())
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	// drafts can't be enrolled in
+	exists, err = c.CourseExist(course.Id, time.Now())
 	require.NoError(t, err)
 	require.False(t, exists)
 }
@@ -84,7 +91,7 @@ func TestInscriptosClient_GetMyCourses(t *testing.T) {
 	// seed category, course, user, and enrollment
 	cat := model.Category{CategoryName: "Backend"}
 	require.NoError(t, db.Create(&cat).Error)
-	course := model.Course{CourseName: "Golang", CourseDescription: "intro", CoursePrice: 15.5, CourseDuration: 12, CourseInitDate: "2025-01-01", CourseState: true, CourseCapacity: 100, CourseImage: "img", CategoryID: cat.Id}
+	course := model.Course{CourseName: "Golang", CourseDescription: "intro", CoursePrice: 15.5, CourseDuration: 12, CourseInitDate: "2025-01-01", CourseStatus: model.CoursePublished, CourseCapacity: 100, CourseImage: "img", CategoryID: cat.Id}
 	require.NoError(t, db.Create(&course).Error)
 	u := model.User{Name: "Carol", Avatar: "c.png", Email: "c@c.com", Password: "x"}
 	require.NoError(t, db.Create(&u).Error)
@@ -100,10 +107,154 @@ func TestInscriptosClient_GetMyCourses(t *testing.T) {
 	// Type-normalized fields
 	require.InDelta(t, 15.5, got.CoursePrice, 0.0001)
 	require.Equal(t, 12, got.CourseDuration)
-	require.True(t, got.CourseState)
+	require.Equal(t, model.CoursePublished, got.CourseStatus)
 	require.Equal(t, 100, got.CourseCapacity)
 }
 
+func TestInscriptosClient_Enroll_Waitlist(t *testing.T) {
+	db := setupInscriptosDB(t)
+	c := NewInscriptionClient(db)
+	cat := model.Category{CategoryName: "Backend"}
+	require.NoError(t, db.Create(&cat).Error)
+	course := model.Course{CourseName: "Golang", CourseStatus: model.CoursePublished, CourseCapacity: 1, CourseImage: "img", CategoryID: cat.Id}
+	require.NoError(t, db.Create(&course).Error)
+	var users []model.User
+	for _, name := range []string{"Ann", "Ben", "Cid"} {
+		u := model.User{Name: name, Email: name + "@ex.com", Password: "x"}
+		require.NoError(t, db.Create(&u).Error)
+		users = append(users, u)
+	}
+
+	statuses := []string{}
+	for _, u := range users {
+		enrollment, err := c.Enroll(model.Inscripto{UserId: u.Id, CourseId: course.Id}, time.Now())
+		require.NoError(t, err)
+		statuses = append(statuses, enrollment.Status)
+	}
+	require.Equal(t, []string{model.EnrollmentActive, model.EnrollmentWaitlisted, model.EnrollmentWaitlisted}, statuses)
+
+	_, err := c.Enroll(model.Inscripto{UserId: users[0].Id, CourseId: course.Id}, time.Now())
+	require.Equal(t, "USER_ALREADY_ENROLLED", err.(*customError.Error).Code)
+	_, err = c.Enroll(model.Inscripto{UserId: users[1].Id, CourseId: course.Id}, time.Now())
+	require.Equal(t, "ALREADY_WAITLISTED", err.(*customError.Error).Code)
+	_, err = c.Enroll(model.Inscripto{UserId: users[0].Id, CourseId: uuid.New()}, time.Now())
+	require.Equal(t, "NOT_FOUND", err.(*customError.Error).Code)
+
+	// waiting doesn't give access to the course
+	enrolled, err := c.IsUserEnrolled(users[1].Id, course.Id)
+	require.NoError(t, err)
+	require.False(t, enrolled)
+	positions, err := c.WaitlistPositions(users[2].Id)
+	require.NoError(t, err)
+	require.Equal(t, map[uuid.UUID]int{course.Id: 2}, positions)
+	students, err := c.GetMyStudents(course.Id)
+	require.NoError(t, err)
+	require.Len(t, students, 1)
+
+	// the first user waiting takes the freed seat
+	promoted, err := c.Unenroll(users[0].Id, course.Id)
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{users[1].Id}, promoted)
+	enrolled, err = c.IsUserEnrolled(users[1].Id, course.Id)
+	require.NoError(t, err)
+	require.True(t, enrolled)
+	positions, err = c.WaitlistPositions(users[2].Id)
+	require.NoError(t, err)
+	require.Equal(t, map[uuid.UUID]int{course.Id: 1}, positions)
+	mine, err := c.GetMyCourses(users[0].Id)
+	require.NoError(t, err)
+	require.Empty(t, mine)
+
+	_, err = c.Unenroll(users[0].Id, course.Id)
+	require.Equal(t, "NOT_ENROLLED", err.(*customError.Error).Code)
+
+	// leaving the waitlist frees no seat
+	promoted, err = c.Unenroll(users[2].Id, course.Id)
+	require.NoError(t, err)
+	require.Empty(t, promoted)
+}
+
+func TestInscriptosClient_FillSeats(t *testing.T) {
+	db := setupInscriptosDB(t)
+	c := NewInscriptionClient(db)
+	cat := model.Category{CategoryName: "Backend"}
+	require.NoError(t, db.Create(&cat).Error)
+	course := model.Course{CourseName: "Golang", CourseStatus: model.CoursePublished, CourseCapacity: 1, CourseImage: "img", CategoryID: cat.Id}
+	require.NoError(t, db.Create(&course).Error)
+	var ids []uuid.UUID
+	for _, name := range []string{"Ann", "Ben", "Cid", "Dee"} {
+		u := model.User{Name: name, Email: name + "@ex.com", Password: "x"}
+		require.NoError(t, db.Create(&u).Error)
+		_, err := c.Enroll(model.Inscripto{UserId: u.Id, CourseId: course.Id}, time.Now())
+		require.NoError(t, err)
+		ids = append(ids, u.Id)
+	}
+
+	require.NoError(t, db.Model(&course).Update("course_capacity", 3).Error)
+	promoted, err := c.FillSeats(course.Id)
+	require.NoError(t, err)
+	require.Equal(t, ids[1:3], promoted)
+
+	// a completed course is kept, prerequisites depend on it
+	require.NoError(t, db.Model(&model.Inscripto{}).Where("user_id = ?", ids[1]).Update("completed_at", time.Now()).Error)
+	_, err = c.Unenroll(ids[1], course.Id)
+	require.Equal(t, "COURSE_COMPLETED", err.(*customError.Error).Code)
+}
+
+func TestInscriptosClient_Enroll_Cohorts(t *testing.T) {
+	db := setupInscriptosDB(t)
+	c := NewInscriptionClient(db)
+	cat := model.Category{CategoryName: "Backend"}
+	require.NoError(t, db.Create(&cat).Error)
+	course := model.Course{CourseName: "Golang", CourseStatus: model.CoursePublished, CourseCapacity: 10, CourseImage: "img", CategoryID: cat.Id}
+	require.NoError(t, db.Create(&course).Error)
+	other := model.Course{CourseName: "Rust", CourseStatus: model.CoursePublished, CourseCapacity: 10, CourseImage: "img", CategoryID: cat.Id}
+	require.NoError(t, db.Create(&other).Error)
+	now := time.Now().UTC()
+	closed := now.Add(-time.Hour)
+	march := model.Cohort{CourseId: course.Id, Name: "March", StartsAt: now.Add(24 * time.Hour), EndsAt: now.Add(48 * time.Hour), TimeZone: "UTC", Capacity: 1}
+	april := model.Cohort{CourseId: course.Id, Name: "April", StartsAt: now.Add(24 * time.Hour), EndsAt: now.Add(48 * time.Hour), TimeZone: "UTC", Capacity: 1, EnrollmentClosesAt: &closed}
+	foreign := model.Cohort{CourseId: other.Id, Name: "Rust March", StartsAt: now.Add(24 * time.Hour), EndsAt: now.Add(48 * time.Hour), TimeZone: "UTC", Capacity: 1}
+	for _, cohort := range []*model.Cohort{&march, &april, &foreign} {
+		require.NoError(t, db.Create(cohort).Error)
+	}
+	var users []model.User
+	for _, name := range []string{"Ann", "Ben"} {
+		u := model.User{Name: name, Email: name + "@ex.com", Password: "x"}
+		require.NoError(t, db.Create(&u).Error)
+		users = append(users, u)
+	}
+
+	_, err := c.Enroll(model.Inscripto{UserId: users[0].Id, CourseId: course.Id}, now)
+	require.Equal(t, "COHORT_REQUIRED", err.(*customError.Error).Code)
+	_, err = c.Enroll(model.Inscripto{UserId: users[0].Id, CourseId: course.Id, CohortId: &foreign.Id}, now)
+	require.Equal(t, "COHORT_NOT_FOUND", err.(*customError.Error).Code)
+	_, err = c.Enroll(model.Inscripto{UserId: users[0].Id, CourseId: course.Id, CohortId: &april.Id}, now)
+	require.Equal(t, "ENROLLMENT_CLOSED", err.(*customError.Error).Code)
+
+	// seats are counted per cohort, not per course
+	first, err := c.Enroll(model.Inscripto{UserId: users[0].Id, CourseId: course.Id, CohortId: &march.Id}, now)
+	require.NoError(t, err)
+	require.Equal(t, model.EnrollmentActive, first.Status)
+	second, err := c.Enroll(model.Inscripto{UserId: users[1].Id, CourseId: course.Id, CohortId: &march.Id}, now)
+	require.NoError(t, err)
+	require.Equal(t, model.EnrollmentWaitlisted, second.Status)
+	positions, err := c.WaitlistPositions(users[1].Id)
+	require.NoError(t, err)
+	require.Equal(t, map[uuid.UUID]int{course.Id: 1}, positions)
+
+	require.NoError(t, db.Model(&march).Update("capacity", 2).Error)
+	promoted, err := c.FillCohortSeats(march.Id)
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{users[1].Id}, promoted)
+
+	// the cohort ended, so it can't be enrolled in anymore
+	_, err = c.Unenroll(users[1].Id, course.Id)
+	require.NoError(t, err)
+	_, err = c.Enroll(model.Inscripto{UserId: users[1].Id, CourseId: course.Id, CohortId: &march.Id}, now.Add(72*time.Hour))
+	require.Equal(t, "ENROLLMENT_CLOSED", err.(*customError.Error).Code)
+}
+
 func TestInscriptosClient_GetMyStudents_NotFound(t *testing.T) {
 	db := setupInscriptosDB(t)
 	c := NewInscriptionClient(db)
@@ -111,7 +262,7 @@ func TestInscriptosClient_GetMyStudents_NotFound(t *testing.T) {
 	// Seed only a course, but no enrollments
 	cat := model.Category{CategoryName: "X"}
 	require.NoError(t, db.Create(&cat).Error)
-	course := model.Course{CourseName: "NoStudents", CourseDescription: "", CoursePrice: 0, CourseDuration: 1, CourseInitDate: "2025-01-01", CourseState: false, CourseCapacity: 10, CourseImage: "", CategoryID: cat.Id}
+	course := model.Course{CourseName: "NoStudents", CourseDescription: "", CoursePrice: 0, CourseDuration: 1, CourseInitDate: "2025-01-01", CourseStatus: model.CourseDraft, CourseCapacity: 10, CourseImage: "", CategoryID: cat.Id}
 	require.NoError(t, db.Create(&course).Error)
 
 	_, err := c.GetMyStudents(course.Id)
@@ -126,7 +277,7 @@ func TestInscriptosClient_Enroll_DBError(t *testing.T) {
 	}
 	c := NewInscriptionClient(rawDB)
 
-	_, err = c.Enroll(model.Inscripto{})
+	_, err = c.Enroll(model.Inscripto{}, time.Now())
 	require.Error(t, err)
 }
 
@@ -173,7 +324,7 @@ func TestInscriptosClient_CourseExist_DBError(t *testing.T) {
 	}
 	c := NewInscriptionClient(rawDB)
 
-	exists, err := c.CourseExist(uuid.New())
+	exists, err := c.CourseExist(uuid.New(), time.Now())
 	require.Error(t, err)
 	require.False(t, exists)
 }
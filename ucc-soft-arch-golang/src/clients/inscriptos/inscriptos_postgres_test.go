@@ -0,0 +1,73 @@
+package inscriptos
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+)
+
+// The seat locks only exist on postgres, so concurrent enrollments are
+// tested against a real database when TEST_POSTGRES_DSN points to one.
+func setupPostgresDB(t *testing.T) *gorm.DB {
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN is not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open postgres db: %v", err)
+	}
+	require.NoError(t, db.AutoMigrate(&model.User{}, &model.Course{}, &model.Inscripto{}, &model.Category{}, &model.Cohort{}))
+	return db
+}
+
+func TestInscriptosClient_Enroll_ConcurrentlyOnPostgres(t *testing.T) {
+	db := setupPostgresDB(t)
+	c := NewInscriptionClient(db)
+	const students, capacity = 20, 5
+
+	course := model.Course{CourseName: "Concurrency", CourseStatus: model.CoursePublished, CourseCapacity: capacity}
+	require.NoError(t, db.Create(&course).Error)
+	userIds := make([]uuid.UUID, 0, students)
+	for i := 0; i < students; i++ {
+		u := model.User{Name: fmt.Sprintf("Student %d", i), Email: fmt.Sprintf("%s@test.com", uuid.New()), Password: "x"}
+		require.NoError(t, db.Create(&u).Error)
+		userIds = append(userIds, u.Id)
+	}
+	t.Cleanup(func() {
+		db.Unscoped().Where("course_id = ?", course.Id).Delete(&model.Inscripto{})
+		db.Unscoped().Where("id IN ?", userIds).Delete(&model.User{})
+		db.Unscoped().Delete(&course)
+	})
+
+	var wg sync.WaitGroup
+	errs := make(chan error, students)
+	for _, userId := range userIds {
+		wg.Add(1)
+		go func(userId uuid.UUID) {
+			defer wg.Done()
+			_, err := c.Enroll(model.Inscripto{UserId: userId, CourseId: course.Id}, time.Now())
+			errs <- err
+		}(userId)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	var active, waitlisted int64
+	require.NoError(t, db.Model(&model.Inscripto{}).Where("course_id = ? AND status = ?", course.Id, model.EnrollmentActive).Count(&active).Error)
+	require.NoError(t, db.Model(&model.Inscripto{}).Where("course_id = ? AND status = ?", course.Id, model.EnrollmentWaitlisted).Count(&waitlisted).Error)
+	require.Equal(t, int64(capacity), active)
+	require.Equal(t, int64(students-capacity), waitlisted)
+}
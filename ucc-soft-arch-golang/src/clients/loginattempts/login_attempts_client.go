@@ -0,0 +1,66 @@
+package loginattempts
+
+import (
+	"errors"
+
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LoginAttemptsClient struct {
+	Db *gorm.DB
+}
+
+func NewLoginAttemptsClient(db *gorm.DB) *LoginAttemptsClient {
+	return &LoginAttemptsClient{Db: db}
+}
+
+// FindByIdentifier returns the attempts row for identifier, or an empty row
+// when there were no failures.
+func (c *LoginAttemptsClient) FindByIdentifier(identifier string) (model.LoginAttempt, error) {
+	var attempt model.LoginAttempt
+	err := c.Db.Where("identifier = ?", identifier).First(&attempt).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.LoginAttempt{Identifier: identifier}, nil
+		}
+		return model.LoginAttempt{}, customError.DBError(err)
+	}
+	return attempt, nil
+}
+
+// Update loads the row for identifier (creating it if needed) under a row
+// lock and saves whatever change apply makes, so concurrent failures are all
+// counted.
+func (c *LoginAttemptsClient) Update(identifier string, apply func(attempt *model.LoginAttempt)) (model.LoginAttempt, error) {
+	var attempt model.LoginAttempt
+	err := c.Db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&model.LoginAttempt{Identifier: identifier}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("identifier = ?", identifier).
+			First(&attempt).Error
+		if err != nil {
+			return err
+		}
+		apply(&attempt)
+		return tx.Save(&attempt).Error
+	})
+	if err != nil {
+		return model.LoginAttempt{}, customError.DBError(err)
+	}
+	return attempt, nil
+}
+
+func (c *LoginAttemptsClient) Reset(identifier string) error {
+	result := c.Db.Unscoped().Where("identifier = ?", identifier).Delete(&model.LoginAttempt{})
+	if result.Error != nil {
+		return customError.DBError(result.Error)
+	}
+	return nil
+}
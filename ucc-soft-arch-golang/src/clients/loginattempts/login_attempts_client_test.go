@@ -0,0 +1,45 @@
+package loginattempts
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	github_com_glebarez_sqlite "github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func makeDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(github_com_glebarez_sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	require.NoError(t, db.AutoMigrate(&model.LoginAttempt{}))
+	return db
+}
+
+func TestLoginAttemptsClient_UpdateAndReset(t *testing.T) {
+	c := NewLoginAttemptsClient(makeDB(t))
+
+	empty, err := c.FindByIdentifier("email:a@b.com")
+	require.NoError(t, err)
+	require.Zero(t, empty.Failures)
+
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		_, err := c.Update("email:a@b.com", func(a *model.LoginAttempt) {
+			a.Failures++
+			a.LastFailureAt = now
+		})
+		require.NoError(t, err)
+	}
+	found, err := c.FindByIdentifier("email:a@b.com")
+	require.NoError(t, err)
+	require.Equal(t, 3, found.Failures)
+
+	require.NoError(t, c.Reset("email:a@b.com"))
+	found, err = c.FindByIdentifier("email:a@b.com")
+	require.NoError(t, err)
+	require.Zero(t, found.Failures)
+}
@@ -0,0 +1,59 @@
+package oauthstates
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"gorm.io/gorm"
+)
+
+type OAuthStatesClient struct {
+	Db *gorm.DB
+}
+
+func NewOAuthStatesClient(db *gorm.DB) *OAuthStatesClient {
+	return &OAuthStatesClient{Db: db}
+}
+
+func (c *OAuthStatesClient) Create(state model.OAuthState) (model.OAuthState, error) {
+	result := c.Db.Create(&state)
+	if result.Error != nil {
+		return model.OAuthState{}, customError.DBError(result.Error)
+	}
+	return state, nil
+}
+
+func (c *OAuthStatesClient) FindByHash(hash string) (model.OAuthState, error) {
+	var state model.OAuthState
+	err := c.Db.Where("state_hash = ?", hash).First(&state).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.OAuthState{}, customError.NewError("NOT_FOUND", "OAuth state not found", http.StatusNotFound)
+		}
+		return model.OAuthState{}, customError.NewError("DB_ERROR", "Error retrieving OAuth state from database", http.StatusInternalServerError)
+	}
+	return state, nil
+}
+
+// MarkUsed consumes a state; it reports false if it was already used.
+func (c *OAuthStatesClient) MarkUsed(id uint, at time.Time) (bool, error) {
+	result := c.Db.Model(&model.OAuthState{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", at)
+	if result.Error != nil {
+		return false, customError.DBError(result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// DeleteExpired removes states older than before, used or not.
+func (c *OAuthStatesClient) DeleteExpired(before time.Time) error {
+	result := c.Db.Unscoped().Where("expires_at < ?", before).Delete(&model.OAuthState{})
+	if result.Error != nil {
+		return customError.DBError(result.Error)
+	}
+	return nil
+}
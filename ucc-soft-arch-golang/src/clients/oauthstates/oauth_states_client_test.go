@@ -0,0 +1,53 @@
+package oauthstates
+
+import (
+	"testing"
+	"time"
+
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	github_com_glebarez_sqlite "github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func makeDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(github_com_glebarez_sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	require.NoError(t, db.AutoMigrate(&model.OAuthState{}))
+	return db
+}
+
+func TestOAuthStatesClient_CreateFindAndUse(t *testing.T) {
+	c := NewOAuthStatesClient(makeDB(t))
+	created, err := c.Create(model.OAuthState{StateHash: "h1", CodeVerifier: "v", Nonce: "n", ExpiresAt: time.Now().Add(time.Minute)})
+	require.NoError(t, err)
+
+	found, err := c.FindByHash("h1")
+	require.NoError(t, err)
+	require.Equal(t, created.ID, found.ID)
+	require.Equal(t, "v", found.CodeVerifier)
+
+	marked, err := c.MarkUsed(found.ID, time.Now())
+	require.NoError(t, err)
+	require.True(t, marked)
+	marked, err = c.MarkUsed(found.ID, time.Now())
+	require.NoError(t, err)
+	require.False(t, marked)
+}
+
+func TestOAuthStatesClient_DeleteExpired(t *testing.T) {
+	c := NewOAuthStatesClient(makeDB(t))
+	_, err := c.Create(model.OAuthState{StateHash: "old", ExpiresAt: time.Now().Add(-time.Hour)})
+	require.NoError(t, err)
+	_, err = c.Create(model.OAuthState{StateHash: "new", ExpiresAt: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+
+	require.NoError(t, c.DeleteExpired(time.Now()))
+	_, err = c.FindByHash("old")
+	require.Equal(t, "NOT_FOUND", err.(*customError.Error).Code)
+	_, err = c.FindByHash("new")
+	require.NoError(t, err)
+}
@@ -0,0 +1,52 @@
+package passwordhistory
+
+import (
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PasswordHistoryClient struct {
+	Db *gorm.DB
+}
+
+func NewPasswordHistoryClient(db *gorm.DB) *PasswordHistoryClient {
+	return &PasswordHistoryClient{Db: db}
+}
+
+// Add records a password hash and drops the entries of the user beyond the
+// keep most recent ones.
+func (c *PasswordHistoryClient) Add(entry model.PasswordHistory, keep int) error {
+	err := c.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&entry).Error; err != nil {
+			return err
+		}
+		var kept []uint
+		err := tx.Model(&model.PasswordHistory{}).
+			Where("user_id = ?", entry.UserId).
+			Order("id DESC").
+			Limit(keep).
+			Pluck("id", &kept).Error
+		if err != nil {
+			return err
+		}
+		return tx.Unscoped().
+			Where("user_id = ? AND id NOT IN ?", entry.UserId, kept).
+			Delete(&model.PasswordHistory{}).Error
+	})
+	if err != nil {
+		return customError.DBError(err)
+	}
+	return nil
+}
+
+// ListRecent returns the last limit hashes of a user, newest first.
+func (c *PasswordHistoryClient) ListRecent(userId uuid.UUID, limit int) (model.PasswordHistories, error) {
+	var entries model.PasswordHistories
+	err := c.Db.Where("user_id = ?", userId).Order("id DESC").Limit(limit).Find(&entries).Error
+	if err != nil {
+		return nil, customError.DBError(err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,46 @@
+package passwordhistory
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	github_com_glebarez_sqlite "github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func makeDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(github_com_glebarez_sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	require.NoError(t, db.AutoMigrate(&model.PasswordHistory{}))
+	return db
+}
+
+func TestPasswordHistoryClient_AddKeepsMostRecent(t *testing.T) {
+	db := makeDB(t)
+	c := NewPasswordHistoryClient(db)
+	userId := uuid.New()
+	other := uuid.New()
+	require.NoError(t, c.Add(model.PasswordHistory{UserId: other, Hash: "other"}, 3))
+	for i := 0; i < 5; i++ {
+		require.NoError(t, c.Add(model.PasswordHistory{UserId: userId, Hash: fmt.Sprintf("h%d", i)}, 3))
+	}
+
+	recent, err := c.ListRecent(userId, 10)
+	require.NoError(t, err)
+	require.Len(t, recent, 3)
+	require.Equal(t, "h4", recent[0].Hash)
+	require.Equal(t, "h2", recent[2].Hash)
+
+	var total int64
+	require.NoError(t, db.Unscoped().Model(&model.PasswordHistory{}).Count(&total).Error)
+	require.EqualValues(t, 4, total, "older entries are deleted, other users are untouched")
+
+	recent, err = c.ListRecent(userId, 1)
+	require.NoError(t, err)
+	require.Len(t, recent, 1)
+}
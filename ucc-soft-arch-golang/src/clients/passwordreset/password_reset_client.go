@@ -0,0 +1,62 @@
+package passwordreset
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PasswordResetClient struct {
+	Db *gorm.DB
+}
+
+func NewPasswordResetClient(db *gorm.DB) *PasswordResetClient {
+	return &PasswordResetClient{Db: db}
+}
+
+func (c *PasswordResetClient) Create(reset model.PasswordReset) (model.PasswordReset, error) {
+	result := c.Db.Create(&reset)
+	if result.Error != nil {
+		return model.PasswordReset{}, customError.DBError(result.Error)
+	}
+	return reset, nil
+}
+
+func (c *PasswordResetClient) FindByHash(hash string) (model.PasswordReset, error) {
+	var reset model.PasswordReset
+	err := c.Db.Where("token_hash = ?", hash).First(&reset).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.PasswordReset{}, customError.NewError("NOT_FOUND", "Password reset not found", http.StatusNotFound)
+		}
+		return model.PasswordReset{}, customError.NewError("DB_ERROR", "Error retrieving password reset from database", http.StatusInternalServerError)
+	}
+	return reset, nil
+}
+
+// MarkUsed consumes a reset token; it reports false if it was already used.
+func (c *PasswordResetClient) MarkUsed(id uint, at time.Time) (bool, error) {
+	result := c.Db.Model(&model.PasswordReset{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", at)
+	if result.Error != nil {
+		return false, customError.DBError(result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// InvalidateForUser consumes every outstanding reset token of a user.
+func (c *PasswordResetClient) InvalidateForUser(userId uuid.UUID, at time.Time) error {
+	result := c.Db.Model(&model.PasswordReset{}).
+		Where("user_id = ? AND used_at IS NULL", userId).
+		Update("used_at", at)
+	if result.Error != nil {
+		return customError.DBError(result.Error)
+	}
+	return nil
+}
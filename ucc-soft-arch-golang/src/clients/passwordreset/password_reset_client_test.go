@@ -0,0 +1,54 @@
+package passwordreset
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	github_com_glebarez_sqlite "github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func makeDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(github_com_glebarez_sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	require.NoError(t, db.AutoMigrate(&model.PasswordReset{}))
+	return db
+}
+
+func TestPasswordResetClient_CreateFindAndUse(t *testing.T) {
+	c := NewPasswordResetClient(makeDB(t))
+	created, err := c.Create(model.PasswordReset{UserId: uuid.New(), TokenHash: "h1", ExpiresAt: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+
+	found, err := c.FindByHash("h1")
+	require.NoError(t, err)
+	require.Equal(t, created.ID, found.ID)
+
+	ok, err := c.MarkUsed(found.ID, time.Now())
+	require.NoError(t, err)
+	require.True(t, ok)
+	ok, err = c.MarkUsed(found.ID, time.Now())
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestPasswordResetClient_FindByHash_NotFound(t *testing.T) {
+	c := NewPasswordResetClient(makeDB(t))
+	_, err := c.FindByHash("missing")
+	require.Error(t, err)
+}
+
+func TestPasswordResetClient_InvalidateForUser(t *testing.T) {
+	c := NewPasswordResetClient(makeDB(t))
+	user := uuid.New()
+	_, _ = c.Create(model.PasswordReset{UserId: user, TokenHash: "h1", ExpiresAt: time.Now().Add(time.Hour)})
+	_, _ = c.Create(model.PasswordReset{UserId: user, TokenHash: "h2", ExpiresAt: time.Now().Add(time.Hour)})
+	require.NoError(t, c.InvalidateForUser(user, time.Now()))
+	found, _ := c.FindByHash("h2")
+	require.NotNil(t, found.UsedAt)
+}
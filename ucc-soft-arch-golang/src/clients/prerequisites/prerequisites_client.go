@@ -0,0 +1,161 @@
+package prerequisites
+
+import (
+	"net/http"
+
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Graph maps each course to the courses it requires.
+type Graph map[uuid.UUID][]uuid.UUID
+
+type PrerequisitesClient struct {
+	Db *gorm.DB
+}
+
+func NewPrerequisitesClient(db *gorm.DB) *PrerequisitesClient {
+	return &PrerequisitesClient{Db: db}
+}
+
+// FindCourses returns the courses among ids that exist.
+func (c *PrerequisitesClient) FindCourses(ids []uuid.UUID) (model.Courses, error) {
+	courses := model.Courses{}
+	if len(ids) == 0 {
+		return courses, nil
+	}
+	if err := c.Db.Where("id IN ?", ids).Order("course_name").Find(&courses).Error; err != nil {
+		return nil, customError.DBError(err)
+	}
+	return courses, nil
+}
+
+// GetPrerequisites returns the courses a course requires. Deleted courses
+// are left out.
+func (c *PrerequisitesClient) GetPrerequisites(courseId uuid.UUID) (model.Courses, error) {
+	courses := model.Courses{}
+	err := c.Db.
+		Joins("JOIN course_prerequisites ON course_prerequisites.prerequisite_id = courses.id AND course_prerequisites.deleted_at IS NULL").
+		Where("course_prerequisites.course_id = ?", courseId).
+		Order("courses.course_name").
+		Find(&courses).Error
+	if err != nil {
+		return nil, customError.DBError(err)
+	}
+	return courses, nil
+}
+
+// Replace sets the prerequisites of a course. check gets the whole graph
+// with the new prerequisites in place and can veto the change by returning
+// an error. On postgres the table is locked until the transaction ends so
+// two saves can't build a cycle between them.
+func (c *PrerequisitesClient) Replace(courseId uuid.UUID, prerequisiteIds []uuid.UUID, check func(Graph) error) error {
+	var vetoed error
+	err := c.Db.Transaction(func(tx *gorm.DB) error {
+		if tx.Dialector.Name() == "postgres" {
+			if err := tx.Exec("LOCK TABLE course_prerequisites IN SHARE ROW EXCLUSIVE MODE").Error; err != nil {
+				return err
+			}
+		}
+		var edges model.CoursePrerequisites
+		if err := tx.Where("course_id <> ?", courseId).Find(&edges).Error; err != nil {
+			return err
+		}
+		graph := Graph{courseId: prerequisiteIds}
+		for _, edge := range edges {
+			graph[edge.CourseId] = append(graph[edge.CourseId], edge.PrerequisiteId)
+		}
+		if vetoed = check(graph); vetoed != nil {
+			return vetoed
+		}
+
+		if err := tx.Unscoped().Where("course_id = ?", courseId).Delete(&model.CoursePrerequisite{}).Error; err != nil {
+			return err
+		}
+		for _, prerequisiteId := range prerequisiteIds {
+			if err := tx.Create(&model.CoursePrerequisite{CourseId: courseId, PrerequisiteId: prerequisiteId}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if vetoed != nil {
+			return vetoed
+		}
+		return customError.DBError(err)
+	}
+	return nil
+}
+
+// Unmet returns the prerequisites of a course the user hasn't completed.
+func (c *PrerequisitesClient) Unmet(userId uuid.UUID, courseId uuid.UUID) (model.Courses, error) {
+	courses := model.Courses{}
+	err := c.Db.
+		Joins("JOIN course_prerequisites ON course_prerequisites.prerequisite_id = courses.id AND course_prerequisites.deleted_at IS NULL").
+		Where("course_prerequisites.course_id = ?", courseId).
+		Where(`NOT EXISTS (SELECT 1 FROM inscriptos
+			WHERE inscriptos.course_id = courses.id
+				AND inscriptos.user_id = ?
+				AND inscriptos.completed_at IS NOT NULL
+				AND inscriptos.deleted_at IS NULL)`, userId).
+		Order("courses.course_name").
+		Find(&courses).Error
+	if err != nil {
+		return nil, customError.DBError(err)
+	}
+	return courses, nil
+}
+
+func (c *PrerequisitesClient) UserExists(userId uuid.UUID) (bool, error) {
+	var count int64
+	if err := c.Db.Model(&model.User{}).Where("id = ?", userId).Count(&count).Error; err != nil {
+		return false, customError.DBError(err)
+	}
+	return count > 0, nil
+}
+
+func (c *PrerequisitesClient) HasWaiver(userId uuid.UUID, courseId uuid.UUID) (bool, error) {
+	var count int64
+	err := c.Db.Model(&model.PrerequisiteWaiver{}).
+		Where("user_id = ? AND course_id = ?", userId, courseId).
+		Count(&count).Error
+	if err != nil {
+		return false, customError.DBError(err)
+	}
+	return count > 0, nil
+}
+
+// GrantWaiver saves the waiver; granting it again only changes who granted
+// it.
+func (c *PrerequisitesClient) GrantWaiver(waiver model.PrerequisiteWaiver) (model.PrerequisiteWaiver, error) {
+	err := c.Db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "course_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"granted_by", "updated_at"}),
+	}).Create(&waiver).Error
+	if err != nil {
+		return model.PrerequisiteWaiver{}, customError.DBError(err)
+	}
+	var saved model.PrerequisiteWaiver
+	err = c.Db.Where("course_id = ? AND user_id = ?", waiver.CourseId, waiver.UserId).First(&saved).Error
+	if err != nil {
+		return model.PrerequisiteWaiver{}, customError.DBError(err)
+	}
+	return saved, nil
+}
+
+func (c *PrerequisitesClient) RevokeWaiver(courseId uuid.UUID, userId uuid.UUID) error {
+	result := c.Db.Unscoped().
+		Where("course_id = ? AND user_id = ?", courseId, userId).
+		Delete(&model.PrerequisiteWaiver{})
+	if result.Error != nil {
+		return customError.DBError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return customError.NewError("WAIVER_NOT_FOUND", "The user has no waiver for this course", http.StatusNotFound)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package prerequisites
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	github_com_glebarez_sqlite "github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func makeDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(github_com_glebarez_sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	require.NoError(t, db.AutoMigrate(&model.Category{}, &model.Course{}, &model.Inscripto{}, &model.CoursePrerequisite{}, &model.PrerequisiteWaiver{}))
+	return db
+}
+
+func seedCourse(t *testing.T, db *gorm.DB, name string) model.Course {
+	course := model.Course{CourseName: name, CategoryID: uuid.New()}
+	require.NoError(t, db.Create(&course).Error)
+	return course
+}
+
+func TestPrerequisitesClient_Replace(t *testing.T) {
+	db := makeDB(t)
+	c := NewPrerequisitesClient(db)
+	basics, advanced, expert := seedCourse(t, db, "Basics"), seedCourse(t, db, "Advanced"), seedCourse(t, db, "Expert")
+
+	require.NoError(t, c.Replace(advanced.Id, []uuid.UUID{basics.Id}, func(Graph) error { return nil }))
+	var seen Graph
+	require.NoError(t, c.Replace(expert.Id, []uuid.UUID{advanced.Id, basics.Id}, func(g Graph) error {
+		seen = g
+		return nil
+	}))
+	require.Equal(t, []uuid.UUID{basics.Id}, seen[advanced.Id])
+	require.Equal(t, []uuid.UUID{advanced.Id, basics.Id}, seen[expert.Id])
+
+	required, err := c.GetPrerequisites(expert.Id)
+	require.NoError(t, err)
+	require.Len(t, required, 2)
+	require.Equal(t, "Advanced", required[0].CourseName)
+
+	// a vetoed change leaves the prerequisites as they were
+	veto := customError.NewError("PREREQUISITE_CYCLE", "cycle", 409)
+	err = c.Replace(expert.Id, nil, func(Graph) error { return veto })
+	require.True(t, errors.Is(err, veto))
+	required, err = c.GetPrerequisites(expert.Id)
+	require.NoError(t, err)
+	require.Len(t, required, 2)
+
+	require.NoError(t, c.Replace(expert.Id, []uuid.UUID{advanced.Id}, func(Graph) error { return nil }))
+	required, err = c.GetPrerequisites(expert.Id)
+	require.NoError(t, err)
+	require.Len(t, required, 1)
+
+	found, err := c.FindCourses([]uuid.UUID{basics.Id, uuid.New()})
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+}
+
+func TestPrerequisitesClient_Unmet(t *testing.T) {
+	db := makeDB(t)
+	c := NewPrerequisitesClient(db)
+	basics, tools, advanced := seedCourse(t, db, "Basics"), seedCourse(t, db, "Tools"), seedCourse(t, db, "Advanced")
+	require.NoError(t, c.Replace(advanced.Id, []uuid.UUID{basics.Id, tools.Id}, func(Graph) error { return nil }))
+	userId := uuid.New()
+	now := time.Now()
+	require.NoError(t, db.Create(&model.Inscripto{UserId: userId, CourseId: basics.Id, CompletedAt: &now}).Error)
+	// enrolled but not completed yet
+	require.NoError(t, db.Create(&model.Inscripto{UserId: userId, CourseId: tools.Id}).Error)
+
+	unmet, err := c.Unmet(userId, advanced.Id)
+	require.NoError(t, err)
+	require.Len(t, unmet, 1)
+	require.Equal(t, tools.Id, unmet[0].Id)
+
+	// deleted courses don't block anyone
+	require.NoError(t, db.Delete(&tools).Error)
+	unmet, err = c.Unmet(userId, advanced.Id)
+	require.NoError(t, err)
+	require.Empty(t, unmet)
+}
+
+func TestPrerequisitesClient_Waivers(t *testing.T) {
+	c := NewPrerequisitesClient(makeDB(t))
+	courseId, userId, adminId := uuid.New(), uuid.New(), uuid.New()
+
+	waived, err := c.HasWaiver(userId, courseId)
+	require.NoError(t, err)
+	require.False(t, waived)
+
+	_, err = c.GrantWaiver(model.PrerequisiteWaiver{CourseId: courseId, UserId: userId, GrantedBy: uuid.New()})
+	require.NoError(t, err)
+	saved, err := c.GrantWaiver(model.PrerequisiteWaiver{CourseId: courseId, UserId: userId, GrantedBy: adminId})
+	require.NoError(t, err)
+	require.Equal(t, adminId, saved.GrantedBy)
+	waived, err = c.HasWaiver(userId, courseId)
+	require.NoError(t, err)
+	require.True(t, waived)
+
+	require.NoError(t, c.RevokeWaiver(courseId, userId))
+	err = c.RevokeWaiver(courseId, userId)
+	require.Equal(t, "WAIVER_NOT_FOUND", err.(*customError.Error).Code)
+
+	// it can be granted again after being revoked
+	_, err = c.GrantWaiver(model.PrerequisiteWaiver{CourseId: courseId, UserId: userId, GrantedBy: adminId})
+	require.NoError(t, err)
+}
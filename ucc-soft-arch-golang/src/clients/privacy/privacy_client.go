@@ -0,0 +1,226 @@
+package privacy
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PrivacyClient reads and erases everything stored about a user across
+// tables, for data exports and account deletion.
+type PrivacyClient struct {
+	Db *gorm.DB
+}
+
+func NewPrivacyClient(db *gorm.DB) *PrivacyClient {
+	return &PrivacyClient{Db: db}
+}
+
+func (c *PrivacyClient) Enrollments(userId uuid.UUID) ([]model.Inscripto, error) {
+	var inscriptos []model.Inscripto
+	if err := c.Db.Where("user_id = ?", userId).Order("created_at").Find(&inscriptos).Error; err != nil {
+		return nil, customError.DBError(err)
+	}
+	return inscriptos, nil
+}
+
+func (c *PrivacyClient) LessonProgress(userId uuid.UUID) (model.LessonProgresses, error) {
+	var progress model.LessonProgresses
+	if err := c.Db.Where("user_id = ?", userId).Order("created_at").Find(&progress).Error; err != nil {
+		return nil, customError.DBError(err)
+	}
+	return progress, nil
+}
+
+func (c *PrivacyClient) Sessions(userId uuid.UUID) (model.Sessions, error) {
+	var sessions model.Sessions
+	if err := c.Db.Where("user_id = ?", userId).Order("created_at").Find(&sessions).Error; err != nil {
+		return nil, customError.DBError(err)
+	}
+	return sessions, nil
+}
+
+func (c *PrivacyClient) Ratings(userId uuid.UUID) (model.Ratings, error) {
+	var ratings model.Ratings
+	if err := c.Db.Where("user_id = ?", userId).Order("created_at").Find(&ratings).Error; err != nil {
+		return nil, customError.DBError(err)
+	}
+	return ratings, nil
+}
+
+func (c *PrivacyClient) Comments(userId uuid.UUID) (model.Comments, error) {
+	var comments model.Comments
+	if err := c.Db.Where("user_id = ?", userId).Order("created_at").Find(&comments).Error; err != nil {
+		return nil, customError.DBError(err)
+	}
+	return comments, nil
+}
+
+// CourseNames maps course ids to their names. Unknown ids are left out.
+func (c *PrivacyClient) CourseNames(ids []uuid.UUID) (map[uuid.UUID]string, error) {
+	names := map[uuid.UUID]string{}
+	if len(ids) == 0 {
+		return names, nil
+	}
+	var rows []struct {
+		Id         string
+		CourseName string
+	}
+	if err := c.Db.Model(&model.Course{}).Select("id, course_name").Where("id IN ?", ids).Scan(&rows).Error; err != nil {
+		return nil, customError.DBError(err)
+	}
+	for _, row := range rows {
+		if id, err := uuid.Parse(row.Id); err == nil {
+			names[id] = row.CourseName
+		}
+	}
+	return names, nil
+}
+
+// CohortNames maps cohort ids to their names. Unknown ids are left out.
+func (c *PrivacyClient) CohortNames(ids []uuid.UUID) (map[uuid.UUID]string, error) {
+	names := map[uuid.UUID]string{}
+	if len(ids) == 0 {
+		return names, nil
+	}
+	var rows []struct {
+		Id   string
+		Name string
+	}
+	if err := c.Db.Model(&model.Cohort{}).Select("id, name").Where("id IN ?", ids).Scan(&rows).Error; err != nil {
+		return nil, customError.DBError(err)
+	}
+	for _, row := range rows {
+		if id, err := uuid.Parse(row.Id); err == nil {
+			names[id] = row.Name
+		}
+	}
+	return names, nil
+}
+
+// errNoCourseHeir stops an erasure that would leave a course without owner.
+var errNoCourseHeir = errors.New("no one to transfer the course to")
+
+// personalTables hold rows that only make sense for the person and are
+// removed with the account.
+var personalTables = []struct {
+	model  interface{}
+	column string
+}{
+	{&model.Session{}, "user_id"},
+	{&model.RefreshToken{}, "user_id"},
+	{&model.RevokedToken{}, "user_id"},
+	{&model.APIKey{}, "owner_id"},
+	{&model.TwoFactor{}, "user_id"},
+	{&model.RecoveryCode{}, "user_id"},
+	{&model.TwoFactorChallenge{}, "user_id"},
+	{&model.ExternalIdentity{}, "user_id"},
+	{&model.PasswordHistory{}, "user_id"},
+	{&model.PasswordReset{}, "user_id"},
+	{&model.EmailVerification{}, "user_id"},
+	{&model.CourseInstructor{}, "user_id"},
+	{&model.LessonProgress{}, "user_id"},
+	{&model.PrerequisiteWaiver{}, "user_id"},
+}
+
+// Erase anonymizes an account in one transaction. The user row is kept as
+// a soft deleted tombstone so enrollments, ratings and comments keep
+// pointing somewhere, but its personal fields are overwritten; rows that
+// only identify the person are hard deleted, as are the places held on
+// waitlists, and audit entries lose their email and IP. The courses the
+// user owns go to someone else first, see transferOwnedCourses.
+// loginIdentifier is the login throttle entry to drop.
+func (c *PrivacyClient) Erase(userId uuid.UUID, loginIdentifier string, at time.Time) error {
+	err := c.Db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.User{}).Where("id = ?", userId).Updates(map[string]interface{}{
+			"email":                   fmt.Sprintf("deleted-%s@users.invalid", userId),
+			"name":                    model.DeletedUserName,
+			"avatar":                  model.DeletedUserAvatar,
+			"password":                "",
+			"email_verified":          false,
+			"two_factor_required":     false,
+			"suspended_at":            nil,
+			"suspended_reason":        "",
+			"password_reset_required": false,
+			"deletion_scheduled_at":   nil,
+			"token_version":           gorm.Expr("token_version + 1"),
+			"deleted_at":              at,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if err := transferOwnedCourses(tx, userId); err != nil {
+			return err
+		}
+		err := tx.Unscoped().Where("user_id = ? AND status = ?", userId, model.EnrollmentWaitlisted).Delete(&model.Inscripto{}).Error
+		if err != nil {
+			return err
+		}
+		for _, table := range personalTables {
+			if err := tx.Unscoped().Where(table.column+" = ?", userId).Delete(table.model).Error; err != nil {
+				return err
+			}
+		}
+		if loginIdentifier != "" {
+			if err := tx.Unscoped().Where("identifier = ?", loginIdentifier).Delete(&model.LoginAttempt{}).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&model.AuditLog{}).Where("user_id = ?", userId).
+			Updates(map[string]interface{}{"email": "", "ip": ""}).Error
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return customError.NewError("NOT_FOUND", "User not found", http.StatusNotFound)
+		}
+		if errors.Is(err, errNoCourseHeir) {
+			return customError.NewError("COURSE_OWNER", "The account owns courses and there is no one to hand them to", http.StatusConflict)
+		}
+		return customError.DBError(err)
+	}
+	return nil
+}
+
+// transferOwnedCourses hands each course the user owns to its oldest
+// co-instructor or, if it has none, to the oldest admin.
+func transferOwnedCourses(tx *gorm.DB, userId uuid.UUID) error {
+	var owned []uuid.UUID
+	err := tx.Model(&model.CourseInstructor{}).Where("user_id = ? AND is_owner = ?", userId, true).Pluck("course_id", &owned).Error
+	if err != nil {
+		return err
+	}
+	for _, courseId := range owned {
+		var heir model.CourseInstructor
+		result := tx.Where("course_id = ? AND user_id <> ?", courseId, userId).Order("created_at").Limit(1).Find(&heir)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			if err := tx.Model(&heir).Update("is_owner", true).Error; err != nil {
+				return err
+			}
+			continue
+		}
+		var admin model.User
+		result = tx.Where("role_name = ? AND id <> ?", model.RoleAdmin, userId).Order("created_at").Limit(1).Find(&admin)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errNoCourseHeir
+		}
+		if err := tx.Create(&model.CourseInstructor{CourseId: courseId, UserId: admin.Id, IsOwner: true}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
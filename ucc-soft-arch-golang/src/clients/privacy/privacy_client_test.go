@@ -0,0 +1,192 @@
+package privacy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	github_com_glebarez_sqlite "github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func makeDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(github_com_glebarez_sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	err = db.AutoMigrate(&model.User{}, &model.Course{}, &model.Inscripto{}, &model.Rating{}, &model.Comment{},
+		&model.Session{}, &model.RefreshToken{}, &model.RevokedToken{}, &model.APIKey{}, &model.TwoFactor{},
+		&model.RecoveryCode{}, &model.TwoFactorChallenge{}, &model.ExternalIdentity{}, &model.PasswordHistory{},
+		&model.PasswordReset{}, &model.EmailVerification{}, &model.CourseInstructor{}, &model.LoginAttempt{}, &model.AuditLog{},
+		&model.LessonProgress{}, &model.PrerequisiteWaiver{}, &model.Cohort{})
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+func seed(t *testing.T, db *gorm.DB) (model.User, model.User, model.Course) {
+	user := model.User{Email: "gone@ex.com", Name: "Gone", Password: "hash"}
+	other := model.User{Email: "stays@ex.com", Name: "Stays", Password: "hash"}
+	course := model.Course{CourseName: "Go"}
+	for _, row := range []interface{}{&user, &other, &course} {
+		require.NoError(t, db.Create(row).Error)
+	}
+	for _, u := range []model.User{user, other} {
+		require.NoError(t, db.Create(&model.Inscripto{UserId: u.Id, CourseId: course.Id}).Error)
+		require.NoError(t, db.Create(&model.Rating{UserId: u.Id, CourseId: course.Id, Rating: 4}).Error)
+		require.NoError(t, db.Create(&model.Comment{UserId: u.Id, CourseId: course.Id, Text: "nice"}).Error)
+		require.NoError(t, db.Create(&model.Session{UserId: u.Id, Ip: "10.0.0.1"}).Error)
+		require.NoError(t, db.Create(&model.LessonProgress{UserId: u.Id, CourseId: course.Id, LessonId: uuid.New(), Position: 30}).Error)
+		require.NoError(t, db.Create(&model.AuditLog{UserId: u.Id, Email: u.Email, Ip: "10.0.0.1", Event: "login"}).Error)
+	}
+	return user, other, course
+}
+
+func TestPrivacyClient_Reads(t *testing.T) {
+	db := makeDB(t)
+	user, _, course := seed(t, db)
+	client := NewPrivacyClient(db)
+
+	enrollments, err := client.Enrollments(user.Id)
+	require.NoError(t, err)
+	require.Len(t, enrollments, 1)
+	ratings, err := client.Ratings(user.Id)
+	require.NoError(t, err)
+	require.Len(t, ratings, 1)
+	require.Equal(t, 4, ratings[0].Rating)
+	comments, err := client.Comments(user.Id)
+	require.NoError(t, err)
+	require.Len(t, comments, 1)
+	progress, err := client.LessonProgress(user.Id)
+	require.NoError(t, err)
+	require.Len(t, progress, 1)
+	require.Equal(t, 30, progress[0].Position)
+	sessions, err := client.Sessions(user.Id)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+
+	names, err := client.CourseNames([]uuid.UUID{course.Id, uuid.New()})
+	require.NoError(t, err)
+	require.Equal(t, map[uuid.UUID]string{course.Id: "Go"}, names)
+	names, err = client.CourseNames(nil)
+	require.NoError(t, err)
+	require.Empty(t, names)
+
+	cohort := model.Cohort{CourseId: course.Id, Name: "Spring"}
+	require.NoError(t, db.Create(&cohort).Error)
+	cohorts, err := client.CohortNames([]uuid.UUID{cohort.Id, uuid.New()})
+	require.NoError(t, err)
+	require.Equal(t, map[uuid.UUID]string{cohort.Id: "Spring"}, cohorts)
+}
+
+func TestPrivacyClient_Erase(t *testing.T) {
+	db := makeDB(t)
+	user, other, _ := seed(t, db)
+	require.NoError(t, db.Create(&model.LoginAttempt{Identifier: "email:gone@ex.com", Failures: 2}).Error)
+	client := NewPrivacyClient(db)
+
+	require.NoError(t, client.Erase(user.Id, "email:gone@ex.com", time.Now()))
+
+	var tombstone model.User
+	require.NoError(t, db.Unscoped().Where("id = ?", user.Id).First(&tombstone).Error)
+	require.Equal(t, model.DeletedUserName, tombstone.Name)
+	require.NotContains(t, tombstone.Email, "gone")
+	require.Empty(t, tombstone.Password)
+	require.Equal(t, 1, tombstone.TokenVersion)
+	require.True(t, tombstone.DeletedAt.Valid)
+
+	// comments and ratings stay, tied to the tombstone
+	var count int64
+	db.Model(&model.Comment{}).Where("user_id = ?", user.Id).Count(&count)
+	require.Equal(t, int64(1), count)
+	db.Model(&model.Rating{}).Where("user_id = ?", user.Id).Count(&count)
+	require.Equal(t, int64(1), count)
+
+	db.Unscoped().Model(&model.Session{}).Where("user_id = ?", user.Id).Count(&count)
+	require.Zero(t, count)
+	db.Unscoped().Model(&model.LessonProgress{}).Where("user_id = ?", user.Id).Count(&count)
+	require.Zero(t, count)
+	db.Unscoped().Model(&model.LoginAttempt{}).Count(&count)
+	require.Zero(t, count)
+	var entry model.AuditLog
+	require.NoError(t, db.Where("user_id = ?", user.Id).First(&entry).Error)
+	require.Empty(t, entry.Email)
+	require.Empty(t, entry.Ip)
+
+	// nothing of the other user is touched
+	db.Model(&model.Session{}).Where("user_id = ?", other.Id).Count(&count)
+	require.Equal(t, int64(1), count)
+	var otherEntry model.AuditLog
+	require.NoError(t, db.Where("user_id = ?", other.Id).First(&otherEntry).Error)
+	require.Equal(t, other.Email, otherEntry.Email)
+
+	require.Error(t, client.Erase(uuid.New(), "", time.Now()))
+}
+
+func TestPrivacyClient_Erase_DropsWaitlistedPlaces(t *testing.T) {
+	db := makeDB(t)
+	user, _, _ := seed(t, db)
+	waitlisted := model.Course{CourseName: "Full"}
+	require.NoError(t, db.Create(&waitlisted).Error)
+	require.NoError(t, db.Create(&model.Inscripto{UserId: user.Id, CourseId: waitlisted.Id, Status: model.EnrollmentWaitlisted}).Error)
+
+	require.NoError(t, NewPrivacyClient(db).Erase(user.Id, "", time.Now()))
+
+	var count int64
+	db.Unscoped().Model(&model.Inscripto{}).Where("course_id = ?", waitlisted.Id).Count(&count)
+	require.Zero(t, count)
+	// the active enrollment stays with the tombstone
+	db.Model(&model.Inscripto{}).Where("user_id = ?", user.Id).Count(&count)
+	require.Equal(t, int64(1), count)
+}
+
+func TestPrivacyClient_Erase_TransfersOwnedCourses(t *testing.T) {
+	db := makeDB(t)
+	user, other, course := seed(t, db)
+	admin := model.User{Email: "admin@ex.com", Name: "Admin", Password: "hash", Role: model.RoleAdmin}
+	require.NoError(t, db.Create(&admin).Error)
+	solo := model.Course{CourseName: "Solo"}
+	require.NoError(t, db.Create(&solo).Error)
+	require.NoError(t, db.Create(&model.CourseInstructor{CourseId: course.Id, UserId: user.Id, IsOwner: true}).Error)
+	require.NoError(t, db.Create(&model.CourseInstructor{CourseId: course.Id, UserId: other.Id}).Error)
+	require.NoError(t, db.Create(&model.CourseInstructor{CourseId: solo.Id, UserId: user.Id, IsOwner: true}).Error)
+
+	require.NoError(t, NewPrivacyClient(db).Erase(user.Id, "", time.Now()))
+
+	// the co-instructor takes over, and the admin gets the course nobody else teaches
+	var owners []model.CourseInstructor
+	require.NoError(t, db.Where("is_owner = ?", true).Order("course_id").Find(&owners).Error)
+	require.Len(t, owners, 2)
+	byCourse := map[uuid.UUID]uuid.UUID{}
+	for _, owner := range owners {
+		byCourse[owner.CourseId] = owner.UserId
+	}
+	require.Equal(t, other.Id, byCourse[course.Id])
+	require.Equal(t, admin.Id, byCourse[solo.Id])
+	var count int64
+	db.Unscoped().Model(&model.CourseInstructor{}).Where("user_id = ?", user.Id).Count(&count)
+	require.Zero(t, count)
+	db.Model(&model.User{}).Count(&count)
+	require.Equal(t, int64(2), count)
+}
+
+func TestPrivacyClient_Erase_RefusesWithoutCourseHeir(t *testing.T) {
+	db := makeDB(t)
+	user, _, course := seed(t, db)
+	require.NoError(t, db.Create(&model.CourseInstructor{CourseId: course.Id, UserId: user.Id, IsOwner: true}).Error)
+
+	err := NewPrivacyClient(db).Erase(user.Id, "", time.Now())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "COURSE_OWNER")
+
+	// nothing was erased
+	var stored model.User
+	require.NoError(t, db.Where("id = ?", user.Id).First(&stored).Error)
+	require.Equal(t, "gone@ex.com", stored.Email)
+	var count int64
+	db.Model(&model.CourseInstructor{}).Where("user_id = ? AND is_owner = ?", user.Id, true).Count(&count)
+	require.Equal(t, int64(1), count)
+}
@@ -0,0 +1,279 @@
+package progress
+
+import (
+	"errors"
+	"time"
+
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Progress sums up an enrollment: how many lessons the course has, how many
+// of them the student completed and when they last viewed one.
+type Progress struct {
+	Lessons      int64
+	Completed    int64
+	LastViewedAt *time.Time
+	CompletedAt  *time.Time
+}
+
+// Percent is the share of lessons completed, rounded down.
+func (p Progress) Percent() int {
+	if p.Lessons == 0 {
+		return 0
+	}
+	return int(p.Completed * 100 / p.Lessons)
+}
+
+type ProgressClient struct {
+	Db *gorm.DB
+}
+
+func NewProgressClient(db *gorm.DB) *ProgressClient {
+	return &ProgressClient{Db: db}
+}
+
+// Record saves the position in a lesson. A lesson stays completed once it
+// was, whatever later records say.
+func (c *ProgressClient) Record(progress model.LessonProgress) (model.LessonProgress, error) {
+	updates := map[string]interface{}{
+		"position":       progress.Position,
+		"last_viewed_at": progress.LastViewedAt,
+		"updated_at":     progress.LastViewedAt,
+	}
+	if progress.CompletedAt != nil {
+		updates["completed_at"] = gorm.Expr("COALESCE(lesson_progresses.completed_at, ?)", *progress.CompletedAt)
+	}
+	err := c.Db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "lesson_id"}},
+		DoUpdates: clause.Assignments(updates),
+	}).Create(&progress).Error
+	if err != nil {
+		return model.LessonProgress{}, customError.DBError(err)
+	}
+	var saved model.LessonProgress
+	err = c.Db.Where("user_id = ? AND lesson_id = ?", progress.UserId, progress.LessonId).First(&saved).Error
+	if err != nil {
+		return model.LessonProgress{}, customError.DBError(err)
+	}
+	return saved, nil
+}
+
+// CompletedLessons lists the lessons of a course the user completed.
+func (c *ProgressClient) CompletedLessons(userId uuid.UUID, courseId uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := c.Db.Model(&model.LessonProgress{}).
+		Where("user_id = ? AND course_id = ? AND completed_at IS NOT NULL", userId, courseId).
+		Pluck("lesson_id", &ids).Error
+	if err != nil {
+		return nil, customError.DBError(err)
+	}
+	return ids, nil
+}
+
+// LastViewed returns the lesson of the course the user viewed last.
+func (c *ProgressClient) LastViewed(userId uuid.UUID, courseId uuid.UUID) (model.LessonProgress, bool, error) {
+	var progress model.LessonProgress
+	err := c.Db.Where("user_id = ? AND course_id = ?", userId, courseId).
+		Order("last_viewed_at DESC").
+		First(&progress).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.LessonProgress{}, false, nil
+		}
+		return model.LessonProgress{}, false, customError.DBError(err)
+	}
+	return progress, true, nil
+}
+
+// ForUser sums up the user's enrollments in courseIds, by course.
+func (c *ProgressClient) ForUser(userId uuid.UUID, courseIds []uuid.UUID) (map[uuid.UUID]Progress, error) {
+	result := map[uuid.UUID]Progress{}
+	if len(courseIds) == 0 {
+		return result, nil
+	}
+	var rows []map[string]interface{}
+	err := c.Db.Raw(`SELECT lessons.course_id,
+			COUNT(*) AS lessons,
+			COUNT(lesson_progresses.completed_at) AS completed,
+			MAX(lesson_progresses.last_viewed_at) AS last_viewed_at
+		FROM lessons
+		LEFT JOIN lesson_progresses ON lesson_progresses.lesson_id = lessons.id
+			AND lesson_progresses.user_id = ?
+			AND lesson_progresses.deleted_at IS NULL
+		WHERE lessons.deleted_at IS NULL AND lessons.course_id IN ?
+		GROUP BY lessons.course_id`, userId, courseIds).Scan(&rows).Error
+	if err != nil {
+		return nil, customError.DBError(err)
+	}
+	for _, row := range rows {
+		result[parseUUID(row["course_id"])] = Progress{
+			Lessons:      toInt64(row["lessons"]),
+			Completed:    toInt64(row["completed"]),
+			LastViewedAt: toTime(row["last_viewed_at"]),
+		}
+	}
+
+	var enrollments []struct {
+		CourseId    uuid.UUID
+		CompletedAt *time.Time
+	}
+	err = c.Db.Model(&model.Inscripto{}).
+		Select("course_id, completed_at").
+		Where("user_id = ? AND course_id IN ? AND completed_at IS NOT NULL", userId, courseIds).
+		Scan(&enrollments).Error
+	if err != nil {
+		return nil, customError.DBError(err)
+	}
+	for _, enrollment := range enrollments {
+		progress := result[enrollment.CourseId]
+		progress.CompletedAt = enrollment.CompletedAt
+		result[enrollment.CourseId] = progress
+	}
+	return result, nil
+}
+
+// ForCourse sums up every enrollment in a course, by user.
+func (c *ProgressClient) ForCourse(courseId uuid.UUID) (map[uuid.UUID]Progress, error) {
+	var lessons int64
+	if err := c.Db.Model(&model.Lesson{}).Where("course_id = ?", courseId).Count(&lessons).Error; err != nil {
+		return nil, customError.DBError(err)
+	}
+	var rows []map[string]interface{}
+	err := c.Db.Raw(`SELECT inscriptos.user_id,
+			inscriptos.completed_at,
+			COUNT(lessons.id) AS completed,
+			MAX(lesson_progresses.last_viewed_at) AS last_viewed_at
+		FROM inscriptos
+		LEFT JOIN lesson_progresses ON lesson_progresses.user_id = inscriptos.user_id
+			AND lesson_progresses.course_id = inscriptos.course_id
+			AND lesson_progresses.deleted_at IS NULL
+		LEFT JOIN lessons ON lessons.id = lesson_progresses.lesson_id
+			AND lessons.deleted_at IS NULL
+			AND lesson_progresses.completed_at IS NOT NULL
+		WHERE inscriptos.course_id = ? AND inscriptos.deleted_at IS NULL
+		GROUP BY inscriptos.user_id, inscriptos.completed_at`, courseId).Scan(&rows).Error
+	if err != nil {
+		return nil, customError.DBError(err)
+	}
+	result := make(map[uuid.UUID]Progress, len(rows))
+	for _, row := range rows {
+		result[parseUUID(row["user_id"])] = Progress{
+			Lessons:      lessons,
+			Completed:    toInt64(row["completed"]),
+			LastViewedAt: toTime(row["last_viewed_at"]),
+			CompletedAt:  toTime(row["completed_at"]),
+		}
+	}
+	return result, nil
+}
+
+// EnrollmentCompletedAt returns when the user completed the course, nil if
+// they haven't yet.
+func (c *ProgressClient) EnrollmentCompletedAt(userId uuid.UUID, courseId uuid.UUID) (*time.Time, error) {
+	var enrollment model.Inscripto
+	err := c.Db.Where("user_id = ? AND course_id = ?", userId, courseId).First(&enrollment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, customError.DBError(err)
+	}
+	return enrollment.CompletedAt, nil
+}
+
+// CompleteEnrollment marks the enrollment completed. It reports false when
+// it already was.
+func (c *ProgressClient) CompleteEnrollment(userId uuid.UUID, courseId uuid.UUID, at time.Time) (bool, error) {
+	result := c.Db.Model(&model.Inscripto{}).
+		Where("user_id = ? AND course_id = ? AND completed_at IS NULL", userId, courseId).
+		Update("completed_at", at)
+	if result.Error != nil {
+		return false, customError.DBError(result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// PendingStudents lists the users with a seat in a course that haven't
+// completed it.
+func (c *ProgressClient) PendingStudents(courseId uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := c.Db.Model(&model.Inscripto{}).
+		Where("course_id = ? AND status = ? AND completed_at IS NULL", courseId, model.EnrollmentActive).
+		Pluck("user_id", &ids).Error
+	if err != nil {
+		return nil, customError.DBError(err)
+	}
+	return ids, nil
+}
+
+// GetRule returns the completion rule of a course and whether it has one.
+func (c *ProgressClient) GetRule(courseId uuid.UUID) (model.CompletionRule, bool, error) {
+	var rule model.CompletionRule
+	err := c.Db.Where("course_id = ?", courseId).First(&rule).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.CompletionRule{}, false, nil
+		}
+		return model.CompletionRule{}, false, customError.DBError(err)
+	}
+	return rule, true, nil
+}
+
+func (c *ProgressClient) SaveRule(rule model.CompletionRule) (model.CompletionRule, error) {
+	err := c.Db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "course_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"min_percent", "required_lessons", "updated_at"}),
+	}).Create(&rule).Error
+	if err != nil {
+		return model.CompletionRule{}, customError.DBError(err)
+	}
+	return rule, nil
+}
+
+// the summaries are scanned into maps, where sqlite hands back MAX() over a
+// timestamp as text
+
+func parseUUID(value interface{}) uuid.UUID {
+	switch v := value.(type) {
+	case string:
+		id, _ := uuid.Parse(v)
+		return id
+	case []byte:
+		id, _ := uuid.ParseBytes(v)
+		return id
+	default:
+		return uuid.Nil
+	}
+}
+
+func toInt64(value interface{}) int64 {
+	switch v := value.(type) {
+	case int64:
+		return v
+	case int:
+		return int64(v)
+	case int32:
+		return int64(v)
+	default:
+		return 0
+	}
+}
+
+func toTime(v interface{}) *time.Time {
+	switch t := v.(type) {
+	case time.Time:
+		return &t
+	case string:
+		parsed, err := time.Parse("2006-01-02 15:04:05.999999999-07:00", t)
+		if err != nil {
+			return nil
+		}
+		return &parsed
+	default:
+		return nil
+	}
+}
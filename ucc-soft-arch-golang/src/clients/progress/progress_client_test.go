@@ -0,0 +1,124 @@
+package progress
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	github_com_glebarez_sqlite "github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func makeDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(github_com_glebarez_sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	require.NoError(t, db.AutoMigrate(&model.Inscripto{}, &model.Lesson{}, &model.LessonProgress{}, &model.CompletionRule{}))
+	return db
+}
+
+func seedLessons(t *testing.T, db *gorm.DB, courseId uuid.UUID, n int) []model.Lesson {
+	var lessons []model.Lesson
+	for i := 0; i < n; i++ {
+		lesson := model.Lesson{CourseId: courseId, SectionId: uuid.New(), Title: "L", Type: model.LessonText, Position: i}
+		require.NoError(t, db.Create(&lesson).Error)
+		lessons = append(lessons, lesson)
+	}
+	return lessons
+}
+
+func TestProgressClient_Record(t *testing.T) {
+	db := makeDB(t)
+	c := NewProgressClient(db)
+	userId, courseId := uuid.New(), uuid.New()
+	lessons := seedLessons(t, db, courseId, 2)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	saved, err := c.Record(model.LessonProgress{UserId: userId, CourseId: courseId, LessonId: lessons[0].Id, Position: 30, LastViewedAt: now, CompletedAt: &now})
+	require.NoError(t, err)
+	require.NotNil(t, saved.CompletedAt)
+
+	// viewing it again moves the position but keeps it completed
+	later := now.Add(time.Minute)
+	saved, err = c.Record(model.LessonProgress{UserId: userId, CourseId: courseId, LessonId: lessons[0].Id, Position: 5, LastViewedAt: later})
+	require.NoError(t, err)
+	require.Equal(t, 5, saved.Position)
+	require.NotNil(t, saved.CompletedAt)
+	require.True(t, saved.CompletedAt.Equal(now))
+
+	_, err = c.Record(model.LessonProgress{UserId: userId, CourseId: courseId, LessonId: lessons[1].Id, Position: 1, LastViewedAt: later.Add(time.Minute)})
+	require.NoError(t, err)
+	last, found, err := c.LastViewed(userId, courseId)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, lessons[1].Id, last.LessonId)
+
+	completed, err := c.CompletedLessons(userId, courseId)
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{lessons[0].Id}, completed)
+}
+
+func TestProgressClient_Summaries(t *testing.T) {
+	db := makeDB(t)
+	c := NewProgressClient(db)
+	alice, bob, courseId := uuid.New(), uuid.New(), uuid.New()
+	require.NoError(t, db.Create(&model.Inscripto{UserId: alice, CourseId: courseId}).Error)
+	require.NoError(t, db.Create(&model.Inscripto{UserId: bob, CourseId: courseId}).Error)
+	lessons := seedLessons(t, db, courseId, 3)
+	now := time.Now().UTC()
+	for _, lesson := range lessons[:2] {
+		_, err := c.Record(model.LessonProgress{UserId: alice, CourseId: courseId, LessonId: lesson.Id, LastViewedAt: now, CompletedAt: &now})
+		require.NoError(t, err)
+	}
+	_, err := c.Record(model.LessonProgress{UserId: bob, CourseId: courseId, LessonId: lessons[0].Id, LastViewedAt: now})
+	require.NoError(t, err)
+
+	completed, err := c.CompleteEnrollment(alice, courseId, now)
+	require.NoError(t, err)
+	require.True(t, completed)
+	completed, err = c.CompleteEnrollment(alice, courseId, now)
+	require.NoError(t, err)
+	require.False(t, completed)
+
+	mine, err := c.ForUser(alice, []uuid.UUID{courseId, uuid.New()})
+	require.NoError(t, err)
+	require.Len(t, mine, 1)
+	require.Equal(t, 66, mine[courseId].Percent())
+	require.NotNil(t, mine[courseId].CompletedAt)
+	require.NotNil(t, mine[courseId].LastViewedAt)
+
+	// deleted lessons don't count
+	require.NoError(t, db.Delete(&lessons[2]).Error)
+	roster, err := c.ForCourse(courseId)
+	require.NoError(t, err)
+	require.Len(t, roster, 2)
+	require.Equal(t, 100, roster[alice].Percent())
+	require.Equal(t, 0, roster[bob].Percent())
+	require.Nil(t, roster[bob].CompletedAt)
+	require.NotNil(t, roster[bob].LastViewedAt)
+
+	pending, err := c.PendingStudents(courseId)
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{bob}, pending)
+}
+
+func TestProgressClient_Rules(t *testing.T) {
+	c := NewProgressClient(makeDB(t))
+	courseId := uuid.New()
+	_, found, err := c.GetRule(courseId)
+	require.NoError(t, err)
+	require.False(t, found)
+
+	_, err = c.SaveRule(model.CompletionRule{CourseId: courseId, MinPercent: 80})
+	require.NoError(t, err)
+	_, err = c.SaveRule(model.CompletionRule{CourseId: courseId, MinPercent: 50, RequiredLessons: "x"})
+	require.NoError(t, err)
+	rule, found, err := c.GetRule(courseId)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, 50, rule.MinPercent)
+	require.Equal(t, "x", rule.RequiredLessons)
+}
@@ -27,7 +27,7 @@ func TestRatingClient_CRUD(t *testing.T) {
 	// seed user and course
 	u := model.User{Name: "Alice", Email: "a@b.com", Password: "x"}
 	require.NoError(t, db.Create(&u).Error)
-	course := model.Course{CourseName: "Golang", CourseDescription: "intro", CoursePrice: 10, CourseDuration: 8, CourseInitDate: "2025-01-01", CourseState: false, CourseCapacity: 30, CourseImage: "img"}
+	course := model.Course{CourseName: "Golang", CourseDescription: "intro", CoursePrice: 10, CourseDuration: 8, CourseInitDate: "2025-01-01", CourseStatus: model.CourseDraft, CourseCapacity: 30, CourseImage: "img"}
 	require.NoError(t, db.Create(&course).Error)
 
 	r := model.Rating{UserId: u.Id, CourseId: course.Id, Rating: 4}
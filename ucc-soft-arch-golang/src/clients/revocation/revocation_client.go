@@ -0,0 +1,49 @@
+package revocation
+
+import (
+	"time"
+
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RevocationClient struct {
+	Db *gorm.DB
+}
+
+func NewRevocationClient(db *gorm.DB) *RevocationClient {
+	return &RevocationClient{Db: db}
+}
+
+// Revoke adds a token to the denylist. Revoking the same token twice is a no-op.
+func (c *RevocationClient) Revoke(token model.RevokedToken) error {
+	result := c.Db.Clauses(clause.OnConflict{DoNothing: true}).Create(&token)
+	if result.Error != nil {
+		return customError.DBError(result.Error)
+	}
+	return nil
+}
+
+func (c *RevocationClient) IsRevoked(tokenId string) (bool, error) {
+	var count int64
+	err := c.Db.Model(&model.RevokedToken{}).
+		Where("token_id = ?", tokenId).
+		Count(&count).Error
+	if err != nil {
+		return false, customError.DBError(err)
+	}
+	return count > 0, nil
+}
+
+// PurgeExpired hard deletes denylist entries whose token already expired.
+func (c *RevocationClient) PurgeExpired(now time.Time) (int64, error) {
+	result := c.Db.Unscoped().
+		Where("expires_at < ?", now).
+		Delete(&model.RevokedToken{})
+	if result.Error != nil {
+		return 0, customError.DBError(result.Error)
+	}
+	return result.RowsAffected, nil
+}
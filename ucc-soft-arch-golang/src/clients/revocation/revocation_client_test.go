@@ -0,0 +1,53 @@
+package revocation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	github_com_glebarez_sqlite "github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func makeDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(github_com_glebarez_sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	require.NoError(t, db.AutoMigrate(&model.RevokedToken{}))
+	return db
+}
+
+func TestRevocationClient_RevokeAndCheck(t *testing.T) {
+	c := NewRevocationClient(makeDB(t))
+	entry := model.RevokedToken{TokenId: "jti-1", UserId: uuid.New(), ExpiresAt: time.Now().Add(time.Hour)}
+	require.NoError(t, c.Revoke(entry))
+	// revoking twice must not fail
+	require.NoError(t, c.Revoke(entry))
+
+	revoked, err := c.IsRevoked("jti-1")
+	require.NoError(t, err)
+	require.True(t, revoked)
+
+	revoked, err = c.IsRevoked("jti-2")
+	require.NoError(t, err)
+	require.False(t, revoked)
+}
+
+func TestRevocationClient_PurgeExpired(t *testing.T) {
+	c := NewRevocationClient(makeDB(t))
+	now := time.Now()
+	require.NoError(t, c.Revoke(model.RevokedToken{TokenId: "old", ExpiresAt: now.Add(-time.Minute)}))
+	require.NoError(t, c.Revoke(model.RevokedToken{TokenId: "live", ExpiresAt: now.Add(time.Minute)}))
+
+	purged, err := c.PurgeExpired(now)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), purged)
+
+	revoked, _ := c.IsRevoked("live")
+	require.True(t, revoked)
+	revoked, _ = c.IsRevoked("old")
+	require.False(t, revoked)
+}
@@ -0,0 +1,52 @@
+package roles
+
+import (
+	"errors"
+	"net/http"
+
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"gorm.io/gorm"
+)
+
+type RolesClient struct {
+	Db *gorm.DB
+}
+
+func NewRolesClient(db *gorm.DB) *RolesClient {
+	return &RolesClient{Db: db}
+}
+
+func (c *RolesClient) FindByName(name string) (model.Role, error) {
+	var role model.Role
+	err := c.Db.Preload("Permissions").Where("name = ?", name).First(&role).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.Role{}, customError.NewError("NOT_FOUND", "Role not found", http.StatusNotFound)
+		}
+		return model.Role{}, customError.DBError(err)
+	}
+	return role, nil
+}
+
+func (c *RolesClient) FindAll() (model.Roles, error) {
+	var roles model.Roles
+	if err := c.Db.Preload("Permissions").Order("name").Find(&roles).Error; err != nil {
+		return nil, customError.DBError(err)
+	}
+	return roles, nil
+}
+
+// HasPermission reports whether the role is granted the permission.
+func (c *RolesClient) HasPermission(role string, permission string) (bool, error) {
+	var count int64
+	err := c.Db.Table("role_permissions").
+		Joins("JOIN roles ON roles.id = role_permissions.role_id AND roles.deleted_at IS NULL").
+		Joins("JOIN permissions ON permissions.id = role_permissions.permission_id AND permissions.deleted_at IS NULL").
+		Where("roles.name = ? AND permissions.name = ?", role, permission).
+		Count(&count).Error
+	if err != nil {
+		return false, customError.DBError(err)
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,56 @@
+package roles
+
+import (
+	"testing"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/config"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	github_com_glebarez_sqlite "github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func makeDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(github_com_glebarez_sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	require.NoError(t, db.AutoMigrate(&model.Permission{}, &model.Role{}))
+	require.NoError(t, config.SeedRoles(db))
+	return db
+}
+
+func TestRolesClient_HasPermission(t *testing.T) {
+	c := NewRolesClient(makeDB(t))
+
+	ok, err := c.HasPermission(model.RoleAdmin, model.PermissionCoursesWrite)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = c.HasPermission(model.RoleInstructor, model.PermissionCoursesWrite)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = c.HasPermission(model.RoleStudent, model.PermissionCoursesWrite)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	ok, err = c.HasPermission("unknown", model.PermissionCoursesWrite)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestRolesClient_FindByNameAndAll(t *testing.T) {
+	c := NewRolesClient(makeDB(t))
+
+	role, err := c.FindByName(model.RoleModerator)
+	require.NoError(t, err)
+	require.Len(t, role.Permissions, 1)
+
+	_, err = c.FindByName("unknown")
+	require.Error(t, err)
+
+	all, err := c.FindAll()
+	require.NoError(t, err)
+	require.Len(t, all, len(model.DefaultRolePermissions))
+}
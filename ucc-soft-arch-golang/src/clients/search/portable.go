@@ -0,0 +1,246 @@
+package search
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/google/uuid"
+)
+
+// field weights, the same ts_rank_cd gives to A, B and C on Postgres
+const (
+	nameWeight        = 1.0
+	descriptionWeight = 0.4
+	categoryWeight    = 0.2
+)
+
+// snippetWords is how many words of the description a snippet keeps.
+const snippetWords = 30
+
+// stopWords are left out of queries, like the Postgres configuration does.
+var stopWords = map[string]bool{
+	"a": true, "al": true, "con": true, "de": true, "del": true, "el": true, "en": true, "la": true,
+	"las": true, "los": true, "o": true, "para": true, "por": true, "un": true, "una": true, "y": true,
+	"and": true, "for": true, "in": true, "of": true, "the": true, "to": true,
+}
+
+var accents = strings.NewReplacer("á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u", "à", "a", "è", "e", "ì", "i", "ò", "o", "ù", "u")
+
+type token struct {
+	word       string
+	start, end int
+}
+
+// portableCourses ranks in Go for databases without text search. Every
+// course is read, which is fine for the small catalogs of tests and
+// development but is what the Postgres index avoids.
+func (c *SearchClient) portableCourses(text string, now time.Time, offset int, limit int) ([]Hit, int64, error) {
+	terms := queryTerms(text)
+	if len(terms) == 0 {
+		return []Hit{}, 0, nil
+	}
+	var rows []struct {
+		Id                uuid.UUID
+		CourseName        string
+		CourseDescription string
+		CategoryName      string
+	}
+	err := c.Db.Table("courses").
+		Select("courses.id, courses.course_name, courses.course_description, categories.category_name").
+		Joins("JOIN categories ON courses.category_id = categories.id").
+		Where("courses.deleted_at IS NULL").
+		Where(model.LiveCourses("courses", now)).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var hits []Hit
+	for _, row := range rows {
+		name, description, category := tokenize(row.CourseName), tokenize(row.CourseDescription), tokenize(row.CategoryName)
+		score := 0.0
+		matched := true
+		for _, term := range terms {
+			best := nameWeight * bestMatch(term, name)
+			best = max(best, descriptionWeight*bestMatch(term, description))
+			best = max(best, categoryWeight*bestMatch(term, category))
+			if best == 0 {
+				matched = false
+				break
+			}
+			score += best
+		}
+		if !matched {
+			continue
+		}
+		hits = append(hits, Hit{
+			CourseId:           row.Id,
+			Score:              score,
+			NameSnippet:        renderSnippet(highlight(row.CourseName, name, terms, len(name))),
+			DescriptionSnippet: renderSnippet(highlight(row.CourseDescription, description, terms, snippetWords)),
+		})
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].CourseId.String() < hits[j].CourseId.String()
+	})
+	total := int64(len(hits))
+	if offset >= len(hits) {
+		return []Hit{}, total, nil
+	}
+	hits = hits[offset:]
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, total, nil
+}
+
+func queryTerms(text string) []string {
+	var terms []string
+	for _, t := range tokenize(text) {
+		if !stopWords[t.word] {
+			terms = append(terms, t.word)
+		}
+	}
+	return terms
+}
+
+// tokenize splits text into lowercase words without accents, keeping where
+// each one is in the original text.
+func tokenize(text string) []token {
+	var tokens []token
+	start := -1
+	for i, r := range text {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if isWord && start < 0 {
+			start = i
+		}
+		if !isWord && start >= 0 {
+			tokens = append(tokens, token{word: fold(text[start:i]), start: start, end: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, token{word: fold(text[start:]), start: start, end: len(text)})
+	}
+	return tokens
+}
+
+func fold(word string) string {
+	return accents.Replace(strings.ToLower(word))
+}
+
+// stem drops plural endings, enough for "cursos" to find "curso".
+func stem(word string) string {
+	switch {
+	case len(word) > 4 && strings.HasSuffix(word, "es"):
+		return word[:len(word)-2]
+	case len(word) > 3 && strings.HasSuffix(word, "s"):
+		return word[:len(word)-1]
+	}
+	return word
+}
+
+// match scores how well a query term matches a word: 1 for the same stem,
+// 0.8 when the word starts with the term and 0.5 for a likely typo.
+func match(term string, word string) float64 {
+	termStem, wordStem := stem(term), stem(word)
+	switch {
+	case termStem == wordStem:
+		return 1
+	case len(term) >= 3 && strings.HasPrefix(word, term):
+		return 0.8
+	}
+	allowed := 0
+	switch n := len([]rune(termStem)); {
+	case n >= 8:
+		allowed = 2
+	case n >= 4:
+		allowed = 1
+	}
+	if allowed > 0 && levenshtein(termStem, wordStem) <= allowed {
+		return 0.5
+	}
+	return 0
+}
+
+func bestMatch(term string, tokens []token) float64 {
+	best := 0.0
+	for _, t := range tokens {
+		best = max(best, match(term, t.word))
+	}
+	return best
+}
+
+// highlight keeps up to words words of text, starting a little before the
+// first match (or at the start without one), and marks the words that match
+// a term.
+func highlight(text string, tokens []token, terms []string, words int) string {
+	if len(tokens) == 0 {
+		return text
+	}
+	matches := make([]bool, len(tokens))
+	first := -1
+	for i, t := range tokens {
+		for _, term := range terms {
+			if match(term, t.word) > 0 {
+				matches[i] = true
+				break
+			}
+		}
+		if matches[i] && first < 0 {
+			first = i
+		}
+	}
+	// a few words of context before the match, fewer in short snippets
+	from := max(0, first-min(5, words/3))
+	to := min(len(tokens), from+words)
+
+	var snippet strings.Builder
+	if from > 0 {
+		snippet.WriteString("… ")
+	}
+	cursor := tokens[from].start
+	for i := from; i < to; i++ {
+		snippet.WriteString(text[cursor:tokens[i].start])
+		if matches[i] {
+			snippet.WriteString(markStart + text[tokens[i].start:tokens[i].end] + markStop)
+		} else {
+			snippet.WriteString(text[tokens[i].start:tokens[i].end])
+		}
+		cursor = tokens[i].end
+	}
+	if to < len(tokens) {
+		snippet.WriteString(" …")
+	} else {
+		snippet.WriteString(text[cursor:])
+	}
+	return snippet.String()
+}
+
+func levenshtein(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current := make([]int, len(rb)+1)
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(rb)]
+}
@@ -0,0 +1,121 @@
+package search
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// the name is weighted A, the description B and the category name C, so
+// ts_rank_cd favors matches in that order
+var postgresMigrations = []string{
+	`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+	`ALTER TABLE courses ADD COLUMN IF NOT EXISTS search_vector tsvector`,
+	fmt.Sprintf(`CREATE OR REPLACE FUNCTION courses_search_vector() RETURNS trigger AS $$
+	BEGIN
+		NEW.search_vector :=
+			setweight(to_tsvector('%[1]s', coalesce(NEW.course_name, '')), 'A') ||
+			setweight(to_tsvector('%[1]s', coalesce(NEW.course_description, '')), 'B') ||
+			setweight(to_tsvector('%[1]s', coalesce((SELECT category_name FROM categories WHERE id = NEW.category_id), '')), 'C');
+		RETURN NEW;
+	END
+	$$ LANGUAGE plpgsql`, Language),
+	`DROP TRIGGER IF EXISTS courses_search_vector ON courses`,
+	`CREATE TRIGGER courses_search_vector
+		BEFORE INSERT OR UPDATE OF course_name, course_description, category_id ON courses
+		FOR EACH ROW EXECUTE FUNCTION courses_search_vector()`,
+	// renaming a category rebuilds the vectors of its courses
+	`CREATE OR REPLACE FUNCTION categories_search_vector() RETURNS trigger AS $$
+	BEGIN
+		UPDATE courses SET category_id = category_id WHERE category_id = NEW.id;
+		RETURN NULL;
+	END
+	$$ LANGUAGE plpgsql`,
+	`DROP TRIGGER IF EXISTS categories_search_vector ON categories`,
+	`CREATE TRIGGER categories_search_vector
+		AFTER UPDATE OF category_name ON categories
+		FOR EACH ROW EXECUTE FUNCTION categories_search_vector()`,
+	`UPDATE courses SET category_id = category_id WHERE search_vector IS NULL`,
+	`CREATE INDEX IF NOT EXISTS idx_courses_search_vector ON courses USING GIN (search_vector)`,
+	`CREATE INDEX IF NOT EXISTS idx_courses_name_trgm ON courses USING GIN (course_name gin_trgm_ops)`,
+}
+
+func migratePostgres(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, statement := range postgresMigrations {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// a live course matches when the stemmed query hits its vector, or when
+// the text is close enough to a word of the name to forgive a typo. The
+// live check is model.LiveCourses spelled out for the named parameters.
+const postgresMatch = `FROM courses, (SELECT websearch_to_tsquery(@language::regconfig, @text) AS query) AS q
+	WHERE courses.deleted_at IS NULL
+		AND courses.course_status = @published
+		AND (courses.publish_at IS NULL OR courses.publish_at <= @now)
+		AND (courses.unpublish_at IS NULL OR courses.unpublish_at > @now)
+		AND (courses.search_vector @@ q.query OR @text <% courses.course_name)`
+
+// the snippets are built only for the page, ts_headline is expensive
+const postgresSearch = `SELECT
+		ranked.id,
+		ranked.score,
+		ts_headline(@language::regconfig, ranked.course_name, ranked.query, @nameOptions) AS name_snippet,
+		ts_headline(@language::regconfig, ranked.course_description, ranked.query, @descriptionOptions) AS description_snippet
+	FROM (
+		SELECT
+			courses.id,
+			courses.course_name,
+			courses.course_description,
+			q.query,
+			ts_rank_cd(courses.search_vector, q.query) + word_similarity(@text, courses.course_name) AS score
+		` + postgresMatch + `
+		ORDER BY score DESC, courses.id
+		LIMIT @limit OFFSET @offset
+	) AS ranked
+	ORDER BY ranked.score DESC, ranked.id`
+
+func (c *SearchClient) postgresCourses(text string, now time.Time, offset int, limit int) ([]Hit, int64, error) {
+	params := map[string]interface{}{
+		"language":           Language,
+		"text":               text,
+		"published":          model.CoursePublished,
+		"now":                now,
+		"limit":              limit,
+		"offset":             offset,
+		"nameOptions":        "StartSel=" + markStart + ", StopSel=" + markStop + ", HighlightAll=true",
+		"descriptionOptions": "StartSel=" + markStart + ", StopSel=" + markStop + `, MaxWords=35, MinWords=15, MaxFragments=2, FragmentDelimiter=" … "`,
+	}
+
+	var total int64
+	if err := c.Db.Raw(`SELECT COUNT(*) `+postgresMatch, params).Scan(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var rows []struct {
+		Id                 uuid.UUID
+		Score              float64
+		NameSnippet        string
+		DescriptionSnippet string
+	}
+	if err := c.Db.Raw(postgresSearch, params).Scan(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	hits := make([]Hit, 0, len(rows))
+	for _, row := range rows {
+		hits = append(hits, Hit{
+			CourseId:           row.Id,
+			Score:              row.Score,
+			NameSnippet:        renderSnippet(row.NameSnippet),
+			DescriptionSnippet: renderSnippet(row.DescriptionSnippet),
+		})
+	}
+	return hits, total, nil
+}
@@ -0,0 +1,77 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"time"
+
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Language is the text search configuration used to stem course texts on
+// Postgres.
+const Language = "spanish"
+
+// snippets come back with these around the matched words and are turned
+// into <mark> tags once the rest of the text is escaped
+const (
+	markStart = "⟦"
+	markStop  = "⟧"
+)
+
+// Hit is a course matching a search, with its relevance and the name and
+// description with the matched words wrapped in <mark>.
+type Hit struct {
+	CourseId           uuid.UUID
+	Score              float64
+	NameSnippet        string
+	DescriptionSnippet string
+}
+
+// SearchClient ranks courses by how well they match a text. Postgres uses
+// the weighted tsvector kept by Migrate plus trigram similarity on the
+// name; any other database falls back to ranking in Go.
+type SearchClient struct {
+	Db *gorm.DB
+}
+
+func NewSearchClient(db *gorm.DB) *SearchClient {
+	return &SearchClient{Db: db}
+}
+
+// Courses returns the hits for text, best first, and how many there are.
+// Only courses live at now are searched.
+func (c *SearchClient) Courses(text string, now time.Time, offset int, limit int) ([]Hit, int64, error) {
+	var (
+		hits  []Hit
+		total int64
+		err   error
+	)
+	if c.Db.Dialector.Name() == "postgres" {
+		hits, total, err = c.postgresCourses(text, now, offset, limit)
+	} else {
+		hits, total, err = c.portableCourses(text, now, offset, limit)
+	}
+	if err != nil {
+		return nil, 0, customError.DBError(err)
+	}
+	return hits, total, nil
+}
+
+// Migrate sets up the search index. Only Postgres needs one; the portable
+// search reads the tables as they are.
+func Migrate(db *gorm.DB) error {
+	if db.Dialector.Name() != "postgres" {
+		return nil
+	}
+	return migratePostgres(db)
+}
+
+// renderSnippet escapes text and turns the markers into <mark> tags.
+func renderSnippet(text string) string {
+	text = html.EscapeString(text)
+	text = strings.ReplaceAll(text, markStart, "<mark>")
+	return strings.ReplaceAll(text, markStop, "</mark>")
+}
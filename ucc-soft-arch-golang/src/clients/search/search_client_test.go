@@ -0,0 +1,100 @@
+package search
+
+import (
+	"testing"
+	"time"
+
+	github_com_glebarez_sqlite "github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+)
+
+func makeDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(github_com_glebarez_sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	require.NoError(t, db.AutoMigrate(&model.Course{}, &model.Category{}))
+	require.NoError(t, Migrate(db))
+	return db
+}
+
+func seedCourses(t *testing.T, db *gorm.DB) map[string]model.Course {
+	backend := model.Category{CategoryName: "Programación"}
+	design := model.Category{CategoryName: "Diseño"}
+	require.NoError(t, db.Create(&backend).Error)
+	require.NoError(t, db.Create(&design).Error)
+	courses := map[string]model.Course{}
+	for _, course := range []model.Course{
+		{CourseName: "Golang desde cero", CourseDescription: "Aprendé concurrencia y <b>APIs</b> con Go.", CourseStatus: model.CoursePublished, CategoryID: backend.Id},
+		{CourseName: "Arquitectura de software", CourseDescription: "Patrones para escribir programas en Golang y Java.", CourseStatus: model.CoursePublished, CategoryID: backend.Id},
+		{CourseName: "Figma", CourseDescription: "Prototipos para programación visual.", CourseStatus: model.CoursePublished, CategoryID: design.Id},
+		{CourseName: "Golang avanzado", CourseDescription: "Todavía en borrador.", CourseStatus: model.CourseDraft, CategoryID: backend.Id},
+	} {
+		require.NoError(t, db.Create(&course).Error)
+		courses[course.CourseName] = course
+	}
+	require.NoError(t, db.Delete(&model.Course{}, "course_name = ?", "Figma").Error)
+	return courses
+}
+
+func TestSearchClient_RanksNameOverDescription(t *testing.T) {
+	db := makeDB(t)
+	courses := seedCourses(t, db)
+	c := NewSearchClient(db)
+
+	hits, total, err := c.Courses("golang", time.Now(), 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), total)
+	require.Equal(t, courses["Golang desde cero"].Id, hits[0].CourseId)
+	require.Equal(t, courses["Arquitectura de software"].Id, hits[1].CourseId)
+	require.Greater(t, hits[0].Score, hits[1].Score)
+	require.Equal(t, "<mark>Golang</mark> desde cero", hits[0].NameSnippet)
+	require.Contains(t, hits[1].DescriptionSnippet, "<mark>Golang</mark>")
+
+	// the text is escaped around the marks
+	require.Contains(t, hits[0].DescriptionSnippet, "&lt;b&gt;APIs&lt;/b&gt;")
+
+	hits, total, err = c.Courses("golang", time.Now(), 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), total)
+	require.Len(t, hits, 1)
+}
+
+func TestSearchClient_StemsTyposAndAccents(t *testing.T) {
+	db := makeDB(t)
+	courses := seedCourses(t, db)
+	c := NewSearchClient(db)
+
+	for _, text := range []string{"arquitecturas", "arqitectura", "ARQUI", "programacion de software"} {
+		hits, _, err := c.Courses(text, time.Now(), 0, 10)
+		require.NoError(t, err, text)
+		require.NotEmpty(t, hits, text)
+		require.Equal(t, courses["Arquitectura de software"].Id, hits[0].CourseId, text)
+	}
+
+	// every term has to match and soft deleted or unpublished courses are
+	// left out
+	hits, total, err := c.Courses("golang figma", time.Now(), 0, 10)
+	require.NoError(t, err)
+	require.Zero(t, total)
+	require.Empty(t, hits)
+	hits, _, err = c.Courses("de", time.Now(), 0, 10)
+	require.NoError(t, err)
+	require.Empty(t, hits)
+	hits, _, err = c.Courses("avanzado", time.Now(), 0, 10)
+	require.NoError(t, err)
+	require.Empty(t, hits)
+}
+
+func TestHighlight_Window(t *testing.T) {
+	text := "uno dos tres cuatro cinco seis siete ocho nueve diez"
+	snippet := highlight(text, tokenize(text), []string{"ocho"}, 4)
+	require.Equal(t, "… siete "+markStart+"ocho"+markStop+" nueve diez", snippet)
+	snippet = highlight(text, tokenize(text), []string{"dos"}, 3)
+	require.Equal(t, "uno "+markStart+"dos"+markStop+" tres …", snippet)
+	snippet = highlight(text, tokenize(text), []string{"nada"}, 2)
+	require.Equal(t, "uno dos …", snippet)
+}
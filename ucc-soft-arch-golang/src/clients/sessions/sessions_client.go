@@ -0,0 +1,129 @@
+package sessions
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type SessionsClient struct {
+	Db *gorm.DB
+}
+
+func NewSessionsClient(db *gorm.DB) *SessionsClient {
+	return &SessionsClient{Db: db}
+}
+
+func (c *SessionsClient) Create(session model.Session) (model.Session, error) {
+	if err := c.Db.Create(&session).Error; err != nil {
+		return model.Session{}, customError.DBError(err)
+	}
+	return session, nil
+}
+
+func (c *SessionsClient) FindById(id uuid.UUID) (model.Session, error) {
+	var session model.Session
+	err := c.Db.Where("id = ?", id).First(&session).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.Session{}, customError.NewError("NOT_FOUND", "Session not found", http.StatusNotFound)
+		}
+		return model.Session{}, customError.DBError(err)
+	}
+	return session, nil
+}
+
+// ListActive returns the sessions of a user that are neither ended nor
+// expired, most recently used first.
+func (c *SessionsClient) ListActive(userId uuid.UUID, now time.Time) (model.Sessions, error) {
+	var sessions model.Sessions
+	err := c.Db.
+		Where("user_id = ? AND ended_at IS NULL AND expires_at > ?", userId, now).
+		Order("last_activity_at DESC").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, customError.DBError(err)
+	}
+	return sessions, nil
+}
+
+// Extend is called when the refresh token of a session is rotated.
+func (c *SessionsClient) Extend(id uuid.UUID, ip string, userAgent string, at time.Time, expiresAt time.Time) error {
+	updates := map[string]interface{}{"last_activity_at": at, "expires_at": expiresAt}
+	if ip != "" {
+		updates["ip"] = ip
+	}
+	if userAgent != "" {
+		updates["user_agent"] = userAgent
+	}
+	result := c.Db.Model(&model.Session{}).Where("id = ?", id).Updates(updates)
+	if result.Error != nil {
+		return customError.DBError(result.Error)
+	}
+	return nil
+}
+
+// TouchActivity records activity on a session. The row is only written when
+// the stored value is older than before, so each request doesn't cause a
+// write.
+func (c *SessionsClient) TouchActivity(id uuid.UUID, at time.Time, before time.Time) error {
+	result := c.Db.Model(&model.Session{}).
+		Where("id = ? AND last_activity_at < ?", id, before).
+		Update("last_activity_at", at)
+	if result.Error != nil {
+		return customError.DBError(result.Error)
+	}
+	return nil
+}
+
+// End ends a session of the user and revokes its refresh tokens. It reports
+// false when there was no active session with that id.
+func (c *SessionsClient) End(id uuid.UUID, userId uuid.UUID, at time.Time) (bool, error) {
+	var ended bool
+	err := c.Db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Session{}).
+			Where("id = ? AND user_id = ? AND ended_at IS NULL", id, userId).
+			Update("ended_at", at)
+		if result.Error != nil {
+			return result.Error
+		}
+		ended = result.RowsAffected > 0
+		return tx.Model(&model.RefreshToken{}).
+			Where("family_id = ? AND revoked_at IS NULL", id).
+			Update("revoked_at", at).Error
+	})
+	if err != nil {
+		return false, customError.DBError(err)
+	}
+	return ended, nil
+}
+
+// EndAllForUser ends every session of the user but exceptId (uuid.Nil ends
+// them all) and revokes their refresh tokens. It returns how many sessions
+// were ended.
+func (c *SessionsClient) EndAllForUser(userId uuid.UUID, exceptId uuid.UUID, at time.Time) (int64, error) {
+	var ended int64
+	err := c.Db.Transaction(func(tx *gorm.DB) error {
+		sessions := tx.Model(&model.Session{}).Where("user_id = ? AND ended_at IS NULL", userId)
+		tokens := tx.Model(&model.RefreshToken{}).Where("user_id = ? AND revoked_at IS NULL", userId)
+		if exceptId != uuid.Nil {
+			sessions = sessions.Where("id <> ?", exceptId)
+			tokens = tokens.Where("family_id <> ?", exceptId)
+		}
+		result := sessions.Update("ended_at", at)
+		if result.Error != nil {
+			return result.Error
+		}
+		ended = result.RowsAffected
+		return tokens.Update("revoked_at", at).Error
+	})
+	if err != nil {
+		return 0, customError.DBError(err)
+	}
+	return ended, nil
+}
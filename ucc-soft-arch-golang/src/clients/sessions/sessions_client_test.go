@@ -0,0 +1,121 @@
+package sessions
+
+import (
+	"testing"
+	"time"
+
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	github_com_glebarez_sqlite "github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func makeDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(github_com_glebarez_sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	require.NoError(t, db.AutoMigrate(&model.Session{}, &model.RefreshToken{}))
+	return db
+}
+
+func TestSessionsClient_CreateAndListActive(t *testing.T) {
+	c := NewSessionsClient(makeDB(t))
+	now := time.Now()
+	userId := uuid.New()
+	older, err := c.Create(model.Session{UserId: userId, Ip: "10.0.0.1", LastActivityAt: now.Add(-time.Hour), ExpiresAt: now.Add(time.Hour)})
+	require.NoError(t, err)
+	newer, err := c.Create(model.Session{UserId: userId, Ip: "10.0.0.2", LastActivityAt: now, ExpiresAt: now.Add(time.Hour)})
+	require.NoError(t, err)
+	_, err = c.Create(model.Session{UserId: userId, LastActivityAt: now, ExpiresAt: now.Add(-time.Minute)})
+	require.NoError(t, err)
+	_, err = c.Create(model.Session{UserId: uuid.New(), LastActivityAt: now, ExpiresAt: now.Add(time.Hour)})
+	require.NoError(t, err)
+
+	active, err := c.ListActive(userId, now)
+	require.NoError(t, err)
+	require.Len(t, active, 2)
+	require.Equal(t, newer.Id, active[0].Id)
+	require.Equal(t, older.Id, active[1].Id)
+
+	found, err := c.FindById(older.Id)
+	require.NoError(t, err)
+	require.Equal(t, "10.0.0.1", found.Ip)
+	_, err = c.FindById(uuid.New())
+	require.Equal(t, "NOT_FOUND", err.(*customError.Error).Code)
+}
+
+func TestSessionsClient_TouchActivity(t *testing.T) {
+	c := NewSessionsClient(makeDB(t))
+	start := time.Now()
+	session, err := c.Create(model.Session{UserId: uuid.New(), LastActivityAt: start, ExpiresAt: start.Add(time.Hour)})
+	require.NoError(t, err)
+
+	// still fresh, nothing is written
+	require.NoError(t, c.TouchActivity(session.Id, start.Add(time.Second), start.Add(-time.Minute)))
+	found, _ := c.FindById(session.Id)
+	require.WithinDuration(t, start, found.LastActivityAt, time.Millisecond)
+
+	later := start.Add(2 * time.Minute)
+	require.NoError(t, c.TouchActivity(session.Id, later, later.Add(-time.Minute)))
+	found, _ = c.FindById(session.Id)
+	require.WithinDuration(t, later, found.LastActivityAt, time.Millisecond)
+}
+
+func TestSessionsClient_EndRevokesRefreshTokens(t *testing.T) {
+	db := makeDB(t)
+	c := NewSessionsClient(db)
+	now := time.Now()
+	userId := uuid.New()
+	session, err := c.Create(model.Session{UserId: userId, LastActivityAt: now, ExpiresAt: now.Add(time.Hour)})
+	require.NoError(t, err)
+	require.NoError(t, db.Create(&model.RefreshToken{UserId: userId, FamilyId: session.Id, TokenHash: "h1", ExpiresAt: now.Add(time.Hour)}).Error)
+
+	// another user can't end it
+	ended, err := c.End(session.Id, uuid.New(), now)
+	require.NoError(t, err)
+	require.False(t, ended)
+
+	ended, err = c.End(session.Id, userId, now)
+	require.NoError(t, err)
+	require.True(t, ended)
+	ended, err = c.End(session.Id, userId, now)
+	require.NoError(t, err)
+	require.False(t, ended)
+
+	var token model.RefreshToken
+	require.NoError(t, db.Where("token_hash = ?", "h1").First(&token).Error)
+	require.NotNil(t, token.RevokedAt)
+}
+
+func TestSessionsClient_EndAllForUser(t *testing.T) {
+	db := makeDB(t)
+	c := NewSessionsClient(db)
+	now := time.Now()
+	userId := uuid.New()
+	var ids []uuid.UUID
+	for i := 0; i < 3; i++ {
+		session, err := c.Create(model.Session{UserId: userId, LastActivityAt: now, ExpiresAt: now.Add(time.Hour)})
+		require.NoError(t, err)
+		require.NoError(t, db.Create(&model.RefreshToken{UserId: userId, FamilyId: session.Id, TokenHash: session.Id.String(), ExpiresAt: now.Add(time.Hour)}).Error)
+		ids = append(ids, session.Id)
+	}
+
+	ended, err := c.EndAllForUser(userId, ids[0], now)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, ended)
+	active, err := c.ListActive(userId, now)
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+	require.Equal(t, ids[0], active[0].Id)
+
+	var live int64
+	require.NoError(t, db.Model(&model.RefreshToken{}).Where("revoked_at IS NULL").Count(&live).Error)
+	require.EqualValues(t, 1, live)
+
+	ended, err = c.EndAllForUser(userId, uuid.Nil, now)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, ended)
+}
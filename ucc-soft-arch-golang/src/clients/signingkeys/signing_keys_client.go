@@ -0,0 +1,47 @@
+package signingkeys
+
+import (
+	"time"
+
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SigningKeysClient struct {
+	Db *gorm.DB
+}
+
+func NewSigningKeysClient(db *gorm.DB) *SigningKeysClient {
+	return &SigningKeysClient{Db: db}
+}
+
+// CreateIfMissing stores the key unless the slot (algorithm and activation
+// time) already has one, e.g. because another instance created it first.
+func (c *SigningKeysClient) CreateIfMissing(key model.SigningKey) (bool, error) {
+	result := c.Db.Clauses(clause.OnConflict{DoNothing: true}).Create(&key)
+	if result.Error != nil {
+		return false, customError.DBError(result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// FindUnexpired returns the keys still published at now, oldest first.
+func (c *SigningKeysClient) FindUnexpired(now time.Time) (model.SigningKeys, error) {
+	var keys model.SigningKeys
+	err := c.Db.Where("expires_at > ?", now).Order("activates_at").Find(&keys).Error
+	if err != nil {
+		return nil, customError.DBError(err)
+	}
+	return keys, nil
+}
+
+// DeleteExpired hard deletes keys no valid token can refer to anymore.
+func (c *SigningKeysClient) DeleteExpired(now time.Time) (int64, error) {
+	result := c.Db.Unscoped().Where("expires_at <= ?", now).Delete(&model.SigningKey{})
+	if result.Error != nil {
+		return 0, customError.DBError(result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,55 @@
+package signingkeys
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	github_com_glebarez_sqlite "github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func makeDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(github_com_glebarez_sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	require.NoError(t, db.AutoMigrate(&model.SigningKey{}))
+	return db
+}
+
+func TestSigningKeysClient_FindUnexpiredAndDelete(t *testing.T) {
+	c := NewSigningKeysClient(makeDB(t))
+	now := time.Now()
+	_, err := c.CreateIfMissing(model.SigningKey{Kid: "old", ActivatesAt: now.Add(-48 * time.Hour), ExpiresAt: now.Add(-time.Hour)})
+	require.NoError(t, err)
+	_, err = c.CreateIfMissing(model.SigningKey{Kid: "next", ActivatesAt: now.Add(time.Hour), ExpiresAt: now.Add(72 * time.Hour)})
+	require.NoError(t, err)
+	_, err = c.CreateIfMissing(model.SigningKey{Kid: "current", ActivatesAt: now.Add(-time.Hour), ExpiresAt: now.Add(24 * time.Hour)})
+	require.NoError(t, err)
+
+	keys, err := c.FindUnexpired(now)
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	require.Equal(t, "current", keys[0].Kid)
+	require.Equal(t, "next", keys[1].Kid)
+
+	deleted, err := c.DeleteExpired(now)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), deleted)
+}
+
+func TestSigningKeysClient_CreateIfMissing_OneKeyPerSlot(t *testing.T) {
+	c := NewSigningKeysClient(makeDB(t))
+	slot := time.Now().Truncate(time.Hour)
+	created, err := c.CreateIfMissing(model.SigningKey{Kid: "a", Algorithm: "RS256", ActivatesAt: slot, ExpiresAt: slot.Add(2 * time.Hour)})
+	require.NoError(t, err)
+	require.True(t, created)
+	created, err = c.CreateIfMissing(model.SigningKey{Kid: "b", Algorithm: "RS256", ActivatesAt: slot, ExpiresAt: slot.Add(2 * time.Hour)})
+	require.NoError(t, err)
+	require.False(t, created)
+	created, err = c.CreateIfMissing(model.SigningKey{Kid: "c", Algorithm: "EdDSA", ActivatesAt: slot, ExpiresAt: slot.Add(2 * time.Hour)})
+	require.NoError(t, err)
+	require.True(t, created)
+}
@@ -0,0 +1,72 @@
+package tokens
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type RefreshTokensClient struct {
+	Db *gorm.DB
+}
+
+func NewRefreshTokensClient(db *gorm.DB) *RefreshTokensClient {
+	return &RefreshTokensClient{Db: db}
+}
+
+func (c *RefreshTokensClient) Create(token model.RefreshToken) (model.RefreshToken, error) {
+	result := c.Db.Create(&token)
+	if result.Error != nil {
+		return model.RefreshToken{}, customError.DBError(result.Error)
+	}
+	return token, nil
+}
+
+func (c *RefreshTokensClient) FindByHash(hash string) (model.RefreshToken, error) {
+	var token model.RefreshToken
+	err := c.Db.Where("token_hash = ?", hash).First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.RefreshToken{}, customError.NewError("NOT_FOUND", "Refresh token not found", http.StatusNotFound)
+		}
+		return model.RefreshToken{}, customError.NewError("DB_ERROR", "Error retrieving refresh token from database", http.StatusInternalServerError)
+	}
+	return token, nil
+}
+
+// MarkUsed flags a token as consumed. It only succeeds once per token, so two
+// concurrent refreshes with the same token can't both win.
+func (c *RefreshTokensClient) MarkUsed(id uuid.UUID, at time.Time) (bool, error) {
+	result := c.Db.Model(&model.RefreshToken{}).
+		Where("id = ? AND used_at IS NULL AND revoked_at IS NULL", id).
+		Update("used_at", at)
+	if result.Error != nil {
+		return false, customError.DBError(result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (c *RefreshTokensClient) RevokeFamily(familyId uuid.UUID, at time.Time) error {
+	result := c.Db.Model(&model.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyId).
+		Update("revoked_at", at)
+	if result.Error != nil {
+		return customError.DBError(result.Error)
+	}
+	return nil
+}
+
+func (c *RefreshTokensClient) RevokeAllForUser(userId uuid.UUID, at time.Time) error {
+	result := c.Db.Model(&model.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userId).
+		Update("revoked_at", at)
+	if result.Error != nil {
+		return customError.DBError(result.Error)
+	}
+	return nil
+}
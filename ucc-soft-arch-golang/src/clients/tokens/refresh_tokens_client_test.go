@@ -0,0 +1,87 @@
+package tokens
+
+import (
+	"testing"
+	"time"
+
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	github_com_glebarez_sqlite "github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func makeDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(github_com_glebarez_sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	require.NoError(t, db.AutoMigrate(&model.RefreshToken{}))
+	return db
+}
+
+func seedToken(t *testing.T, c *RefreshTokensClient, userId, familyId uuid.UUID, hash string) model.RefreshToken {
+	token, err := c.Create(model.RefreshToken{
+		UserId:    userId,
+		FamilyId:  familyId,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	return token
+}
+
+func TestRefreshTokensClient_CreateAndFindByHash(t *testing.T) {
+	c := NewRefreshTokensClient(makeDB(t))
+	created := seedToken(t, c, uuid.New(), uuid.New(), "hash-1")
+	got, err := c.FindByHash("hash-1")
+	require.NoError(t, err)
+	require.Equal(t, created.Id, got.Id)
+}
+
+func TestRefreshTokensClient_FindByHash_NotFound(t *testing.T) {
+	c := NewRefreshTokensClient(makeDB(t))
+	_, err := c.FindByHash("missing")
+	ce, ok := err.(*customError.Error)
+	require.True(t, ok)
+	require.Equal(t, "NOT_FOUND", ce.Code)
+}
+
+func TestRefreshTokensClient_MarkUsed_OnlyOnce(t *testing.T) {
+	c := NewRefreshTokensClient(makeDB(t))
+	token := seedToken(t, c, uuid.New(), uuid.New(), "hash-1")
+	ok, err := c.MarkUsed(token.Id, time.Now())
+	require.NoError(t, err)
+	require.True(t, ok)
+	ok, err = c.MarkUsed(token.Id, time.Now())
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestRefreshTokensClient_RevokeFamily(t *testing.T) {
+	c := NewRefreshTokensClient(makeDB(t))
+	family := uuid.New()
+	seedToken(t, c, uuid.New(), family, "hash-1")
+	seedToken(t, c, uuid.New(), family, "hash-2")
+	other := seedToken(t, c, uuid.New(), uuid.New(), "hash-3")
+	require.NoError(t, c.RevokeFamily(family, time.Now()))
+
+	got, _ := c.FindByHash("hash-2")
+	require.NotNil(t, got.RevokedAt)
+	got, _ = c.FindByHash(other.TokenHash)
+	require.Nil(t, got.RevokedAt)
+}
+
+func TestRefreshTokensClient_RevokeAllForUser(t *testing.T) {
+	c := NewRefreshTokensClient(makeDB(t))
+	user := uuid.New()
+	seedToken(t, c, user, uuid.New(), "hash-1")
+	seedToken(t, c, user, uuid.New(), "hash-2")
+	require.NoError(t, c.RevokeAllForUser(user, time.Now()))
+	got, _ := c.FindByHash("hash-1")
+	require.NotNil(t, got.RevokedAt)
+	ok, err := c.MarkUsed(got.Id, time.Now())
+	require.NoError(t, err)
+	require.False(t, ok)
+}
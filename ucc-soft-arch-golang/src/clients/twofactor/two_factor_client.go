@@ -0,0 +1,176 @@
+package twofactor
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TwoFactorClient struct {
+	Db *gorm.DB
+}
+
+func NewTwoFactorClient(db *gorm.DB) *TwoFactorClient {
+	return &TwoFactorClient{Db: db}
+}
+
+func (c *TwoFactorClient) FindByUser(userId uuid.UUID) (model.TwoFactor, error) {
+	var twoFactor model.TwoFactor
+	err := c.Db.Where("user_id = ?", userId).First(&twoFactor).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.TwoFactor{}, customError.NewError("NOT_FOUND", "Two-factor authentication is not set up", http.StatusNotFound)
+		}
+		return model.TwoFactor{}, customError.DBError(err)
+	}
+	return twoFactor, nil
+}
+
+// SavePending stores a new, still disabled secret for the user, replacing a
+// previous enrollment that was never confirmed.
+func (c *TwoFactorClient) SavePending(userId uuid.UUID, secret string) error {
+	err := c.Db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"secret": secret, "enabled": false, "last_used_step": 0, "updated_at": time.Now()}),
+	}).Create(&model.TwoFactor{UserId: userId, Secret: secret}).Error
+	if err != nil {
+		return customError.DBError(err)
+	}
+	return nil
+}
+
+func (c *TwoFactorClient) Enable(userId uuid.UUID, step int64) error {
+	result := c.Db.Model(&model.TwoFactor{}).
+		Where("user_id = ?", userId).
+		Updates(map[string]interface{}{"enabled": true, "last_used_step": step})
+	if result.Error != nil {
+		return customError.DBError(result.Error)
+	}
+	return nil
+}
+
+// UseStep records that the code of step was used. It reports false when that
+// step or a later one was already used, so a code can't be replayed.
+func (c *TwoFactorClient) UseStep(userId uuid.UUID, step int64) (bool, error) {
+	result := c.Db.Model(&model.TwoFactor{}).
+		Where("user_id = ? AND last_used_step < ?", userId, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		return false, customError.DBError(result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Delete turns 2FA off and drops the recovery codes.
+func (c *TwoFactorClient) Delete(userId uuid.UUID) error {
+	err := c.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("user_id = ?", userId).Delete(&model.TwoFactor{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("user_id = ?", userId).Delete(&model.RecoveryCode{}).Error
+	})
+	if err != nil {
+		return customError.DBError(err)
+	}
+	return nil
+}
+
+// ReplaceRecoveryCodes drops the old recovery codes and stores the new hashes.
+func (c *TwoFactorClient) ReplaceRecoveryCodes(userId uuid.UUID, hashes []string) error {
+	err := c.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("user_id = ?", userId).Delete(&model.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		codes := model.RecoveryCodes{}
+		for _, hash := range hashes {
+			codes = append(codes, model.RecoveryCode{UserId: userId, CodeHash: hash})
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return tx.Create(&codes).Error
+	})
+	if err != nil {
+		return customError.DBError(err)
+	}
+	return nil
+}
+
+// UseRecoveryCode consumes a recovery code; it reports false if the code
+// doesn't exist or was already used.
+func (c *TwoFactorClient) UseRecoveryCode(userId uuid.UUID, hash string, at time.Time) (bool, error) {
+	result := c.Db.Model(&model.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userId, hash).
+		Update("used_at", at)
+	if result.Error != nil {
+		return false, customError.DBError(result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (c *TwoFactorClient) CountRecoveryCodes(userId uuid.UUID) (int64, error) {
+	var count int64
+	err := c.Db.Model(&model.RecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userId).
+		Count(&count).Error
+	if err != nil {
+		return 0, customError.DBError(err)
+	}
+	return count, nil
+}
+
+func (c *TwoFactorClient) CreateChallenge(challenge model.TwoFactorChallenge) (model.TwoFactorChallenge, error) {
+	result := c.Db.Create(&challenge)
+	if result.Error != nil {
+		return model.TwoFactorChallenge{}, customError.DBError(result.Error)
+	}
+	return challenge, nil
+}
+
+func (c *TwoFactorClient) FindChallengeByHash(hash string) (model.TwoFactorChallenge, error) {
+	var challenge model.TwoFactorChallenge
+	err := c.Db.Where("token_hash = ?", hash).First(&challenge).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.TwoFactorChallenge{}, customError.NewError("NOT_FOUND", "Two-factor challenge not found", http.StatusNotFound)
+		}
+		return model.TwoFactorChallenge{}, customError.DBError(err)
+	}
+	return challenge, nil
+}
+
+// RegisterChallengeFailure counts a wrong code and returns the new count.
+func (c *TwoFactorClient) RegisterChallengeFailure(id uint) (int, error) {
+	var challenge model.TwoFactorChallenge
+	err := c.Db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.TwoFactorChallenge{}).
+			Where("id = ?", id).
+			Update("attempts", gorm.Expr("attempts + 1")).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).First(&challenge).Error
+	})
+	if err != nil {
+		return 0, customError.DBError(err)
+	}
+	return challenge.Attempts, nil
+}
+
+// MarkChallengeUsed consumes a challenge; it reports false if it was already
+// used.
+func (c *TwoFactorClient) MarkChallengeUsed(id uint, at time.Time) (bool, error) {
+	result := c.Db.Model(&model.TwoFactorChallenge{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", at)
+	if result.Error != nil {
+		return false, customError.DBError(result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,95 @@
+package twofactor
+
+import (
+	"testing"
+	"time"
+
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	github_com_glebarez_sqlite "github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func makeDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(github_com_glebarez_sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	require.NoError(t, db.AutoMigrate(&model.TwoFactor{}, &model.RecoveryCode{}, &model.TwoFactorChallenge{}))
+	return db
+}
+
+func TestTwoFactorClient_EnrollEnableAndReplay(t *testing.T) {
+	c := NewTwoFactorClient(makeDB(t))
+	userId := uuid.New()
+
+	_, err := c.FindByUser(userId)
+	require.Equal(t, "NOT_FOUND", err.(*customError.Error).Code)
+
+	require.NoError(t, c.SavePending(userId, "FIRST"))
+	require.NoError(t, c.SavePending(userId, "SECOND"))
+	found, err := c.FindByUser(userId)
+	require.NoError(t, err)
+	require.Equal(t, "SECOND", found.Secret)
+	require.False(t, found.Enabled)
+
+	require.NoError(t, c.Enable(userId, 10))
+	used, err := c.UseStep(userId, 10)
+	require.NoError(t, err)
+	require.False(t, used, "the step used to enable can't be used again")
+	used, err = c.UseStep(userId, 11)
+	require.NoError(t, err)
+	require.True(t, used)
+
+	require.NoError(t, c.Delete(userId))
+	_, err = c.FindByUser(userId)
+	require.Error(t, err)
+}
+
+func TestTwoFactorClient_RecoveryCodes(t *testing.T) {
+	c := NewTwoFactorClient(makeDB(t))
+	userId := uuid.New()
+	require.NoError(t, c.ReplaceRecoveryCodes(userId, []string{"h1", "h2"}))
+
+	used, err := c.UseRecoveryCode(userId, "h1", time.Now())
+	require.NoError(t, err)
+	require.True(t, used)
+	used, err = c.UseRecoveryCode(userId, "h1", time.Now())
+	require.NoError(t, err)
+	require.False(t, used)
+	used, err = c.UseRecoveryCode(uuid.New(), "h2", time.Now())
+	require.NoError(t, err)
+	require.False(t, used, "codes belong to a single user")
+
+	count, err := c.CountRecoveryCodes(userId)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+
+	require.NoError(t, c.ReplaceRecoveryCodes(userId, []string{"h3"}))
+	used, err = c.UseRecoveryCode(userId, "h2", time.Now())
+	require.NoError(t, err)
+	require.False(t, used, "old codes are dropped on regeneration")
+}
+
+func TestTwoFactorClient_Challenges(t *testing.T) {
+	c := NewTwoFactorClient(makeDB(t))
+	created, err := c.CreateChallenge(model.TwoFactorChallenge{UserId: uuid.New(), TokenHash: "c1", ExpiresAt: time.Now().Add(time.Minute)})
+	require.NoError(t, err)
+
+	found, err := c.FindChallengeByHash("c1")
+	require.NoError(t, err)
+	require.Equal(t, created.ID, found.ID)
+
+	attempts, err := c.RegisterChallengeFailure(found.ID)
+	require.NoError(t, err)
+	require.Equal(t, 1, attempts)
+
+	marked, err := c.MarkChallengeUsed(found.ID, time.Now())
+	require.NoError(t, err)
+	require.True(t, marked)
+	marked, err = c.MarkChallengeUsed(found.ID, time.Now())
+	require.NoError(t, err)
+	require.False(t, marked)
+}
@@ -1,11 +1,10 @@
 package users
 
 import (
-	"fmt"
-
 	"errors"
 	"net/http"
 	"strings"
+	"time"
 
 	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
 	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
@@ -61,14 +60,14 @@ func (c *UsersClient) FindById(id uuid.UUID) (model.User, error) {
 
 func (c *UsersClient) FindByEmail(email string) (model.User, error) {
 	var user model.User
-	err := c.Db.Where("email = ?", email).First(&user).Error
+	// emails are matched case-insensitively, the way they are throttled
+	err := c.Db.Where("LOWER(email) = LOWER(?)", email).First(&user).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return model.User{}, customError.NewError("NOT_FOUND", "User not found", http.StatusNotFound)
 		}
 		return model.User{}, customError.NewError("DB_ERROR", "Error retrieving User from database", http.StatusInternalServerError)
 	}
-	fmt.Println("Result: ", user)
 	return user, nil
 }
 
@@ -97,3 +96,136 @@ func (c *UsersClient) UpdateUser(user model.User) (model.User, error) {
 	}
 	return user, nil
 }
+
+// IncrementTokenVersion invalidates every token previously issued to the user.
+func (c *UsersClient) IncrementTokenVersion(id uuid.UUID) error {
+	result := c.Db.Model(&model.User{}).
+		Where("id = ?", id).
+		Update("token_version", gorm.Expr("token_version + 1"))
+	if result.Error != nil {
+		return customError.NewError("DB_ERROR", "Error updating User in database", http.StatusInternalServerError)
+	}
+	if result.RowsAffected == 0 {
+		return customError.NewError("NOT_FOUND", "User not found", http.StatusNotFound)
+	}
+	return nil
+}
+
+// MarkEmailUnverified is for when the user changes their email: the new one
+// must be verified again.
+func (c *UsersClient) MarkEmailUnverified(id uuid.UUID) error {
+	result := c.Db.Model(&model.User{}).
+		Where("id = ?", id).
+		Update("email_verified", false)
+	if result.Error != nil {
+		return customError.NewError("DB_ERROR", "Error updating User in database", http.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (c *UsersClient) MarkEmailVerified(id uuid.UUID) error {
+	result := c.Db.Model(&model.User{}).
+		Where("id = ?", id).
+		Update("email_verified", true)
+	if result.Error != nil {
+		return customError.NewError("DB_ERROR", "Error updating User in database", http.StatusInternalServerError)
+	}
+	if result.RowsAffected == 0 {
+		return customError.NewError("NOT_FOUND", "User not found", http.StatusNotFound)
+	}
+	return nil
+}
+
+func (c *UsersClient) SetTwoFactorRequired(id uuid.UUID, required bool) error {
+	result := c.Db.Model(&model.User{}).
+		Where("id = ?", id).
+		Update("two_factor_required", required)
+	if result.Error != nil {
+		return customError.NewError("DB_ERROR", "Error updating User in database", http.StatusInternalServerError)
+	}
+	if result.RowsAffected == 0 {
+		return customError.NewError("NOT_FOUND", "User not found", http.StatusNotFound)
+	}
+	return nil
+}
+
+// User statuses accepted by Search.
+const (
+	StatusActive    = "active"
+	StatusSuspended = "suspended"
+)
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// Search returns a page of users ordered by creation, newest first, and the
+// number of users matching. query matches name or email, role and status
+// ("", StatusActive or StatusSuspended) are exact filters.
+func (c *UsersClient) Search(query string, role string, status string, offset int, limit int) (model.Users, int64, error) {
+	db := c.Db.Model(&model.User{})
+	if query = strings.TrimSpace(query); query != "" {
+		pattern := "%" + likeEscaper.Replace(strings.ToLower(query)) + "%"
+		db = db.Where(`LOWER(name) LIKE ? ESCAPE '\' OR LOWER(email) LIKE ? ESCAPE '\'`, pattern, pattern)
+	}
+	if role != "" {
+		db = db.Where("role_name = ?", role)
+	}
+	switch status {
+	case StatusActive:
+		db = db.Where("suspended_at IS NULL")
+	case StatusSuspended:
+		db = db.Where("suspended_at IS NOT NULL")
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, customError.NewError("DB_ERROR", "Error retrieving Users from database", http.StatusInternalServerError)
+	}
+	var users model.Users
+	err := db.Order("created_at DESC").Order("id").Offset(offset).Limit(limit).Find(&users).Error
+	if err != nil {
+		return nil, 0, customError.NewError("DB_ERROR", "Error retrieving Users from database", http.StatusInternalServerError)
+	}
+	return users, total, nil
+}
+
+func (c *UsersClient) SetRole(id uuid.UUID, role string) error {
+	return c.updateColumns(id, map[string]interface{}{"role_name": role})
+}
+
+// SetSuspended suspends the user when at is set and reactivates it when at
+// is nil.
+func (c *UsersClient) SetSuspended(id uuid.UUID, at *time.Time, reason string) error {
+	return c.updateColumns(id, map[string]interface{}{"suspended_at": at, "suspended_reason": reason})
+}
+
+func (c *UsersClient) SetPasswordResetRequired(id uuid.UUID, required bool) error {
+	return c.updateColumns(id, map[string]interface{}{"password_reset_required": required})
+}
+
+// SetDeletionScheduled schedules the erasure of the account, or cancels it
+// when at is nil.
+func (c *UsersClient) SetDeletionScheduled(id uuid.UUID, at *time.Time) error {
+	return c.updateColumns(id, map[string]interface{}{"deletion_scheduled_at": at})
+}
+
+// FindDueForDeletion returns up to limit users whose erasure is due.
+func (c *UsersClient) FindDueForDeletion(now time.Time, limit int) (model.Users, error) {
+	var users model.Users
+	err := c.Db.Where("deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= ?", now).
+		Order("deletion_scheduled_at").Limit(limit).Find(&users).Error
+	if err != nil {
+		return nil, customError.NewError("DB_ERROR", "Error retrieving Users from database", http.StatusInternalServerError)
+	}
+	return users, nil
+}
+
+func (c *UsersClient) updateColumns(id uuid.UUID, columns map[string]interface{}) error {
+	result := c.Db.Model(&model.User{}).Where("id = ?", id).Updates(columns)
+	if result.Error != nil {
+		return customError.NewError("DB_ERROR", "Error updating User in database", http.StatusInternalServerError)
+	}
+	if result.RowsAffected == 0 {
+		return customError.NewError("NOT_FOUND", "User not found", http.StatusNotFound)
+	}
+	return nil
+}
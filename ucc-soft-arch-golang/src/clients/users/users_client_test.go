@@ -3,10 +3,11 @@ package users
 import (
 	"errors"
 	"testing"
+	"time"
 
 	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
 	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
-	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/bcrypt"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/password"
 	github_com_glebarez_sqlite "github.com/glebarez/sqlite"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -24,9 +25,9 @@ func makeDB(t *testing.T) *gorm.DB {
 	return db
 }
 
-func seedUser(t *testing.T, db *gorm.DB, email string, password string) model.User {
-	hashed, _ := bcrypt.HasPassword(password)
-	u := model.User{Email: email, Password: hashed, Name: "Name", Role: 1}
+func seedUser(t *testing.T, db *gorm.DB, email string, plain string) model.User {
+	hashed, _ := password.Hash(plain)
+	u := model.User{Email: email, Password: hashed, Name: "Name", Role: "admin"}
 	if err := db.Create(&u).Error; err != nil {
 		t.Fatalf("seed user: %v", err)
 	}
@@ -103,7 +104,7 @@ func TestUsersClient_Create_Duplicate(t *testing.T) {
 	db := makeDB(t)
 	client := NewUsersClient(db)
 	seedUser(t, db, "dup@ex.com", "pw")
-	hashed, _ := bcrypt.HasPassword("pw")
+	hashed, _ := password.Hash("pw")
 	dup := model.User{Email: "dup@ex.com", Password: hashed, Name: "X"}
 	_, err := client.Create(dup)
 	if err == nil {
@@ -119,7 +120,7 @@ func TestUsersClient_Create_Duplicate(t *testing.T) {
 func TestUsersClient_Create_Success(t *testing.T) {
 	db := makeDB(t)
 	client := NewUsersClient(db)
-	hashed, _ := bcrypt.HasPassword("pw")
+	hashed, _ := password.Hash("pw")
 	u := model.User{Email: "ok@ex.com", Password: hashed, Name: "Ok"}
 	created, err := client.Create(u)
 	if err != nil {
@@ -175,3 +176,163 @@ func TestUsersClient_UpdateUser_DBError(t *testing.T) {
 		t.Fatalf("expected customError, got %#v", err)
 	}
 }
+
+func TestUsersClient_IncrementTokenVersion(t *testing.T) {
+	db := makeDB(t)
+	u := seedUser(t, db, "v@ex.com", "pw")
+	client := NewUsersClient(db)
+	if err := client.IncrementTokenVersion(u.Id); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, _ := client.FindById(u.Id)
+	if got.TokenVersion != 1 {
+		t.Fatalf("expected token version 1, got %d", got.TokenVersion)
+	}
+	if err := client.IncrementTokenVersion(uuid.New()); err == nil {
+		t.Fatalf("expected error for unknown user")
+	}
+}
+
+func TestUsersClient_MarkEmailVerified(t *testing.T) {
+	db := makeDB(t)
+	u := seedUser(t, db, "verify@ex.com", "pw")
+	client := NewUsersClient(db)
+	if err := client.MarkEmailVerified(u.Id); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, _ := client.FindById(u.Id)
+	if !got.EmailVerified {
+		t.Fatalf("expected email to be verified")
+	}
+	if err := client.MarkEmailVerified(uuid.New()); err == nil {
+		t.Fatalf("expected error for unknown user")
+	}
+}
+
+func TestUsersClient_SetTwoFactorRequired(t *testing.T) {
+	db := makeDB(t)
+	u := seedUser(t, db, "2fa@ex.com", "pw")
+	client := NewUsersClient(db)
+	if err := client.SetTwoFactorRequired(u.Id, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, _ := client.FindById(u.Id)
+	if !got.TwoFactorRequired {
+		t.Fatalf("expected two-factor to be required")
+	}
+	if err := client.SetTwoFactorRequired(uuid.New(), true); err == nil {
+		t.Fatalf("expected error for unknown user")
+	}
+}
+
+func TestUsersClient_Search(t *testing.T) {
+	db := makeDB(t)
+	client := NewUsersClient(db)
+	alice := seedUser(t, db, "alice@ex.com", "pw")
+	seedUser(t, db, "bob@ex.com", "pw")
+	seedUser(t, db, "100%@ex.com", "pw")
+	if err := client.SetRole(alice.Id, "student"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	users, total, err := client.Search("ALICE", "", "", 0, 10)
+	if err != nil || total != 1 || len(users) != 1 || users[0].Id != alice.Id {
+		t.Fatalf("query search: %v %d %v", users, total, err)
+	}
+	_, total, _ = client.Search("%", "", "", 0, 10)
+	if total != 1 {
+		t.Fatalf("expected wildcard to be escaped, got %d", total)
+	}
+	_, total, _ = client.Search("", "admin", "", 0, 10)
+	if total != 2 {
+		t.Fatalf("expected 2 admins, got %d", total)
+	}
+	users, total, _ = client.Search("", "", "", 1, 1)
+	if total != 3 || len(users) != 1 {
+		t.Fatalf("expected one user of 3, got %d of %d", len(users), total)
+	}
+
+	now := time.Now()
+	if err := client.SetSuspended(alice.Id, &now, "spam"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	users, total, _ = client.Search("", "", StatusSuspended, 0, 10)
+	if total != 1 || users[0].SuspendedReason != "spam" {
+		t.Fatalf("expected alice suspended, got %v", users)
+	}
+	_, total, _ = client.Search("", "", StatusActive, 0, 10)
+	if total != 2 {
+		t.Fatalf("expected 2 active users, got %d", total)
+	}
+}
+
+func TestUsersClient_SetSuspended(t *testing.T) {
+	db := makeDB(t)
+	u := seedUser(t, db, "susp@ex.com", "pw")
+	client := NewUsersClient(db)
+	now := time.Now()
+	if err := client.SetSuspended(u.Id, &now, "abuse"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, _ := client.FindById(u.Id)
+	if got.SuspendedAt == nil || got.SuspendedReason != "abuse" {
+		t.Fatalf("expected user to be suspended: %+v", got)
+	}
+	if err := client.SetSuspended(u.Id, nil, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, _ = client.FindById(u.Id)
+	if got.SuspendedAt != nil {
+		t.Fatalf("expected user to be reactivated")
+	}
+	if err := client.SetSuspended(uuid.New(), nil, ""); err == nil {
+		t.Fatalf("expected error for unknown user")
+	}
+}
+
+func TestUsersClient_SetPasswordResetRequired(t *testing.T) {
+	db := makeDB(t)
+	u := seedUser(t, db, "reset@ex.com", "pw")
+	client := NewUsersClient(db)
+	if err := client.SetPasswordResetRequired(u.Id, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, _ := client.FindById(u.Id)
+	if !got.PasswordResetRequired {
+		t.Fatalf("expected password reset to be required")
+	}
+	if err := client.SetRole(uuid.New(), "admin"); err == nil {
+		t.Fatalf("expected error for unknown user")
+	}
+}
+
+func TestUsersClient_DeletionSchedule(t *testing.T) {
+	db := makeDB(t)
+	due := seedUser(t, db, "due@ex.com", "pw")
+	later := seedUser(t, db, "later@ex.com", "pw")
+	seedUser(t, db, "kept@ex.com", "pw")
+	client := NewUsersClient(db)
+	now := time.Now()
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+	if err := client.SetDeletionScheduled(due.Id, &past); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := client.SetDeletionScheduled(later.Id, &future); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	users, err := client.FindDueForDeletion(now, 10)
+	if err != nil || len(users) != 1 || users[0].Id != due.Id {
+		t.Fatalf("expected only the due user, got %v (%v)", users, err)
+	}
+	if err := client.SetDeletionScheduled(due.Id, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	users, _ = client.FindDueForDeletion(now, 10)
+	if len(users) != 0 {
+		t.Fatalf("expected the deletion to be canceled")
+	}
+	if err := client.SetDeletionScheduled(uuid.New(), nil); err == nil {
+		t.Fatalf("expected error for unknown user")
+	}
+}
@@ -1,7 +1,5 @@
 package config
 
-import "github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
-
 import (
 	"fmt"
 
@@ -17,7 +15,9 @@ func NewConnection(dsn string) *gorm.DB {
 
 	fmt.Println("Connection Opened to Database")
 
-	db.AutoMigrate(model.User{}, model.Course{}, model.Categories{}, model.Inscripto{}, model.Ratings{}, model.Comments{})
+	if err := Migrate(db); err != nil {
+		panic("failed to migrate database: " + err.Error())
+	}
 
 	return db
 	// defer db.Close()
@@ -3,6 +3,8 @@ package config
 import (
 	"errors"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -39,3 +41,37 @@ func LoadEnvs(filename ...string) Envs {
 	}
 	return &envsImpl{}
 }
+
+// GetDuration reads a duration (e.g. "15m", "720h") from the environment.
+// When the key is unset or cannot be parsed the fallback is returned.
+func GetDuration(envs Envs, key string, fallback time.Duration) time.Duration {
+	value := envs.Get(key)
+	if value == "" {
+		return fallback
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return fallback
+	}
+	return duration
+}
+
+// GetInt reads a positive integer from the environment. When the key is unset
+// or cannot be parsed the fallback is returned.
+func GetInt(envs Envs, key string, fallback int) int {
+	value, err := strconv.Atoi(envs.Get(key))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
+
+// GetBool reads a boolean (true, false, 1, 0...) from the environment. When
+// the key is unset or cannot be parsed the fallback is returned.
+func GetBool(envs Envs, key string, fallback bool) bool {
+	value, err := strconv.ParseBool(envs.Get(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
@@ -5,6 +5,7 @@ import (
 	"os/signal"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 // Test LoadEnvs without file returns valid Envs and reads from environment
@@ -43,3 +44,48 @@ func TestLoadEnvs_PanicOnUnexpectedError(t *testing.T) {
 	}()
 	_ = LoadEnvs(dir) // godotenv.Load on a directory should error with non-ENOENT -> panic
 }
+
+func TestGetDuration(t *testing.T) {
+	env := LoadEnvs()
+	t.Setenv("SOME_TTL", "90s")
+	if got := GetDuration(env, "SOME_TTL", time.Minute); got != 90*time.Second {
+		t.Fatalf("expected 90s, got %s", got)
+	}
+	t.Setenv("SOME_TTL", "not-a-duration")
+	if got := GetDuration(env, "SOME_TTL", time.Minute); got != time.Minute {
+		t.Fatalf("expected fallback for invalid value, got %s", got)
+	}
+	if got := GetDuration(env, "MISSING_TTL", time.Hour); got != time.Hour {
+		t.Fatalf("expected fallback for missing key, got %s", got)
+	}
+}
+
+func TestGetInt(t *testing.T) {
+	env := LoadEnvs()
+	t.Setenv("SOME_LIMIT", "7")
+	if got := GetInt(env, "SOME_LIMIT", 3); got != 7 {
+		t.Fatalf("expected 7, got %d", got)
+	}
+	t.Setenv("SOME_LIMIT", "seven")
+	if got := GetInt(env, "SOME_LIMIT", 3); got != 3 {
+		t.Fatalf("expected fallback for invalid value, got %d", got)
+	}
+	if got := GetInt(env, "MISSING_LIMIT", 3); got != 3 {
+		t.Fatalf("expected fallback for missing key, got %d", got)
+	}
+}
+
+func TestGetBool(t *testing.T) {
+	env := LoadEnvs()
+	t.Setenv("SOME_FLAG", "false")
+	if got := GetBool(env, "SOME_FLAG", true); got {
+		t.Fatalf("expected false")
+	}
+	t.Setenv("SOME_FLAG", "maybe")
+	if got := GetBool(env, "SOME_FLAG", true); !got {
+		t.Fatalf("expected fallback for invalid value")
+	}
+	if got := GetBool(env, "MISSING_FLAG", true); !got {
+		t.Fatalf("expected fallback for missing key")
+	}
+}
@@ -0,0 +1,178 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/search"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Migrate creates or updates every table and runs the data migrations.
+func Migrate(db *gorm.DB) error {
+	// users that signed up before email verification existed keep their access
+	grandfatherEmails := db.Migrator().HasTable(&model.User{}) && !db.Migrator().HasColumn(&model.User{}, "EmailVerified")
+	err := db.AutoMigrate(
+		model.User{}, model.Course{}, model.Categories{}, model.Inscripto{}, model.Ratings{}, model.Comments{},
+		model.RefreshToken{}, model.RevokedToken{}, model.PasswordReset{}, model.EmailVerification{},
+		model.LoginAttempt{}, model.AuditLog{}, model.Permission{}, model.Role{}, model.CourseInstructor{},
+		model.ExternalIdentity{}, model.OAuthState{}, model.TwoFactor{}, model.RecoveryCode{}, model.TwoFactorChallenge{},
+		model.APIKey{}, model.SigningKey{}, model.Session{}, model.PasswordHistory{}, model.Impersonation{},
+		model.Section{}, model.Lesson{}, model.LessonProgress{}, model.CompletionRule{},
+		model.CoursePrerequisite{}, model.PrerequisiteWaiver{}, model.Cohort{})
+	if err != nil {
+		return err
+	}
+	if grandfatherEmails {
+		fmt.Println("Marking existing users' emails as verified")
+		if err := db.Exec("UPDATE users SET email_verified = ?", true).Error; err != nil {
+			return err
+		}
+	}
+	if err := search.Migrate(db); err != nil {
+		return err
+	}
+	if err := SeedRoles(db); err != nil {
+		return err
+	}
+	if err := migrateLegacyRoles(db); err != nil {
+		return err
+	}
+	if err := migrateCourseState(db); err != nil {
+		return err
+	}
+	return migrateCourseOwners(db)
+}
+
+// SeedRoles creates the default roles and permissions that don't exist yet.
+// A permission created here is also granted to the roles that have it by
+// default; everything else already in the database is left alone.
+func SeedRoles(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		created := map[string]bool{}
+		for _, permission := range model.DefaultPermissions {
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&permission)
+			if result.Error != nil {
+				return result.Error
+			}
+			created[permission.Name] = result.RowsAffected > 0
+		}
+		for name := range model.DefaultRolePermissions {
+			permissionNames := defaultPermissionsFor(name)
+			var role model.Role
+			result := tx.Where("name = ?", name).Limit(1).Find(&role)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected > 0 {
+				// existing role: only add the permissions that are new
+				var added []string
+				for _, permissionName := range permissionNames {
+					if created[permissionName] {
+						added = append(added, permissionName)
+					}
+				}
+				permissionNames = added
+			} else {
+				role = model.Role{Name: name}
+				if err := tx.Create(&role).Error; err != nil {
+					return err
+				}
+			}
+			if len(permissionNames) == 0 {
+				continue
+			}
+			var permissions model.Permissions
+			if err := tx.Where("name IN ?", permissionNames).Find(&permissions).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&role).Association("Permissions").Append(&permissions); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func defaultPermissionsFor(role string) []string {
+	if role != model.RoleAdmin {
+		return model.DefaultRolePermissions[role]
+	}
+	var names []string
+	for _, permission := range model.DefaultPermissions {
+		names = append(names, permission.Name)
+	}
+	return names
+}
+
+// migrateLegacyRoles moves the old integer users.role column (0 = student,
+// anything else = admin) into role_name and drops it.
+func migrateLegacyRoles(db *gorm.DB) error {
+	if !db.Migrator().HasColumn("users", "role") {
+		return nil
+	}
+	fmt.Println("Migrating legacy user roles")
+	// the old column only goes away once every role has been copied
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Table("users").
+			Where("role <> 0").
+			Update("role_name", model.RoleAdmin).Error
+		if err != nil {
+			return err
+		}
+		return tx.Exec("ALTER TABLE users DROP COLUMN role").Error
+	})
+}
+
+// migrateCourseState moves the old courses.course_state flag into the
+// lifecycle: active courses become published, the rest stay drafts.
+func migrateCourseState(db *gorm.DB) error {
+	if !db.Migrator().HasColumn("courses", "course_state") {
+		return nil
+	}
+	fmt.Println("Migrating legacy course states")
+	// the old column only goes away once every state has been copied
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Table("courses").
+			Where("course_state = ?", true).
+			Update("course_status", model.CoursePublished).Error
+		if err != nil {
+			return err
+		}
+		return tx.Exec("ALTER TABLE courses DROP COLUMN course_state").Error
+	})
+}
+
+// migrateCourseOwners gives the courses created before ownership existed
+// to the oldest admin, who could manage them until now.
+func migrateCourseOwners(db *gorm.DB) error {
+	var courseIds []uuid.UUID
+	err := db.Model(&model.Course{}).
+		Where("NOT EXISTS (SELECT 1 FROM course_instructors WHERE course_instructors.course_id = courses.id AND course_instructors.is_owner AND course_instructors.deleted_at IS NULL)").
+		Pluck("id", &courseIds).Error
+	if err != nil || len(courseIds) == 0 {
+		return err
+	}
+	var admin model.User
+	result := db.Where("role_name = ?", model.RoleAdmin).Order("created_at").Limit(1).Find(&admin)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return result.Error
+	}
+	fmt.Println("Assigning owners to existing courses")
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, courseId := range courseIds {
+			owner := model.CourseInstructor{CourseId: courseId, UserId: admin.Id, IsOwner: true}
+			// an admin that was already a co-instructor becomes the owner
+			err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "course_id"}, {Name: "user_id"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{"is_owner": true}),
+			}).Create(&owner).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
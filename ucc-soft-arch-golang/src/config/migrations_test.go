@@ -0,0 +1,158 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	github_com_glebarez_sqlite "github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// legacyUser is the users table as it was before named roles.
+type legacyUser struct {
+	gorm.Model
+	Id    uuid.UUID
+	Email string `gorm:"unique"`
+	Role  int    `gorm:"default:0"`
+}
+
+func (legacyUser) TableName() string { return "users" }
+
+// legacyCourse is the courses table as it was before the lifecycle.
+type legacyCourse struct {
+	gorm.Model
+	Id          uuid.UUID
+	CourseName  string
+	CourseState bool
+}
+
+func (legacyCourse) TableName() string { return "courses" }
+
+func openTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(github_com_glebarez_sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	return db
+}
+
+func TestMigrate_ConvertsLegacyRoles(t *testing.T) {
+	db := openTestDB(t)
+	require.NoError(t, db.AutoMigrate(&legacyUser{}))
+	require.NoError(t, db.Create(&legacyUser{Id: uuid.New(), Email: "student@ex.com", Role: 0}).Error)
+	require.NoError(t, db.Create(&legacyUser{Id: uuid.New(), Email: "admin@ex.com", Role: 1}).Error)
+
+	require.NoError(t, Migrate(db))
+	require.False(t, db.Migrator().HasColumn("users", "role"))
+
+	var student, admin model.User
+	require.NoError(t, db.Where("email = ?", "student@ex.com").First(&student).Error)
+	require.NoError(t, db.Where("email = ?", "admin@ex.com").First(&admin).Error)
+	require.Equal(t, model.RoleStudent, student.Role)
+	require.Equal(t, model.RoleAdmin, admin.Role)
+
+	// running it again is a no-op
+	require.NoError(t, Migrate(db))
+}
+
+func TestMigrate_GrandfathersEmailVerification(t *testing.T) {
+	db := openTestDB(t)
+	require.NoError(t, db.AutoMigrate(&legacyUser{}))
+	require.NoError(t, db.Create(&legacyUser{Id: uuid.New(), Email: "old@ex.com"}).Error)
+
+	require.NoError(t, Migrate(db))
+	var old model.User
+	require.NoError(t, db.Where("email = ?", "old@ex.com").First(&old).Error)
+	require.True(t, old.EmailVerified)
+
+	// users signing up afterwards still have to verify
+	require.NoError(t, db.Create(&model.User{Email: "new@ex.com", Password: "x"}).Error)
+	require.NoError(t, Migrate(db))
+	var signedUp model.User
+	require.NoError(t, db.Where("email = ?", "new@ex.com").First(&signedUp).Error)
+	require.False(t, signedUp.EmailVerified)
+}
+
+func TestMigrate_ConvertsLegacyCourseStates(t *testing.T) {
+	db := openTestDB(t)
+	require.NoError(t, db.AutoMigrate(&legacyCourse{}))
+	require.NoError(t, db.Create(&legacyCourse{Id: uuid.New(), CourseName: "Active", CourseState: true}).Error)
+	require.NoError(t, db.Create(&legacyCourse{Id: uuid.New(), CourseName: "Hidden"}).Error)
+
+	require.NoError(t, Migrate(db))
+	require.False(t, db.Migrator().HasColumn("courses", "course_state"))
+
+	var active, hidden model.Course
+	require.NoError(t, db.Where("course_name = ?", "Active").First(&active).Error)
+	require.NoError(t, db.Where("course_name = ?", "Hidden").First(&hidden).Error)
+	require.Equal(t, model.CoursePublished, active.CourseStatus)
+	require.Equal(t, model.CourseDraft, hidden.CourseStatus)
+}
+
+func TestMigrate_BackfillsCourseOwners(t *testing.T) {
+	db := openTestDB(t)
+	require.NoError(t, db.AutoMigrate(&legacyUser{}, &legacyCourse{}))
+	first := legacyUser{Id: uuid.New(), Email: "first@ex.com", Role: 1}
+	require.NoError(t, db.Create(&first).Error)
+	require.NoError(t, db.Create(&legacyUser{Id: uuid.New(), Email: "second@ex.com", Role: 1}).Error)
+	course := legacyCourse{Id: uuid.New(), CourseName: "Old"}
+	require.NoError(t, db.Create(&course).Error)
+
+	require.NoError(t, Migrate(db))
+	require.NoError(t, Migrate(db))
+	var instructors model.CourseInstructors
+	require.NoError(t, db.Where("course_id = ?", course.Id).Find(&instructors).Error)
+	require.Len(t, instructors, 1)
+	require.Equal(t, first.Id, instructors[0].UserId)
+	require.True(t, instructors[0].IsOwner)
+}
+
+func TestSeedRoles(t *testing.T) {
+	db := openTestDB(t)
+	require.NoError(t, db.AutoMigrate(&model.Permission{}, &model.Role{}))
+	require.NoError(t, SeedRoles(db))
+	require.NoError(t, SeedRoles(db))
+
+	var roles model.Roles
+	require.NoError(t, db.Preload("Permissions").Find(&roles).Error)
+	require.Len(t, roles, len(model.DefaultRolePermissions))
+	for _, role := range roles {
+		switch role.Name {
+		case model.RoleAdmin:
+			require.Len(t, role.Permissions, len(model.DefaultPermissions))
+		case model.RoleStudent:
+			require.Empty(t, role.Permissions)
+		case model.RoleInstructor:
+			require.Len(t, role.Permissions, len(model.DefaultRolePermissions[model.RoleInstructor]))
+		}
+	}
+}
+
+func TestSeedRoles_GrantsNewPermissions(t *testing.T) {
+	db := openTestDB(t)
+	require.NoError(t, db.AutoMigrate(&model.Permission{}, &model.Role{}))
+	require.NoError(t, SeedRoles(db))
+
+	// simulate a database seeded before courses:manage_all existed
+	var permission model.Permission
+	require.NoError(t, db.Where("name = ?", model.PermissionCoursesManageAll).First(&permission).Error)
+	require.NoError(t, db.Exec("DELETE FROM role_permissions WHERE permission_id = ?", permission.ID).Error)
+	require.NoError(t, db.Unscoped().Delete(&permission).Error)
+	// and an admin that removed a permission on purpose
+	var admin model.Role
+	require.NoError(t, db.Where("name = ?", model.RoleAdmin).First(&admin).Error)
+	var audit model.Permission
+	require.NoError(t, db.Where("name = ?", model.PermissionAuditRead).First(&audit).Error)
+	require.NoError(t, db.Model(&admin).Association("Permissions").Delete(&audit))
+
+	require.NoError(t, SeedRoles(db))
+	require.NoError(t, db.Preload("Permissions").First(&admin, admin.ID).Error)
+	names := map[string]bool{}
+	for _, p := range admin.Permissions {
+		names[p.Name] = true
+	}
+	require.True(t, names[model.PermissionCoursesManageAll])
+	require.False(t, names[model.PermissionAuditRead])
+}
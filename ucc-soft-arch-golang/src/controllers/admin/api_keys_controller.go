@@ -0,0 +1,79 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/apikeys"
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type APIKeysController struct {
+	service services.IAPIKeyService
+}
+
+type IAPIKeysController interface {
+	Create(c *gin.Context)
+	List(c *gin.Context)
+	Revoke(c *gin.Context)
+}
+
+func NewAPIKeysController(service services.IAPIKeyService) *APIKeysController {
+	return &APIKeysController{service: service}
+}
+
+func (a *APIKeysController) Create(c *gin.Context) {
+	var createDto apikeys.CreateAPIKeyRequestDto
+	if err := c.ShouldBindJSON(&createDto); err != nil {
+		c.Error(customError.NewError("INVALID_BODY", "Invalid request body", http.StatusBadRequest))
+		return
+	}
+	ownerId, _ := c.Get("userID")
+
+	created, err := a.service.Create(ownerId.(uuid.UUID), createDto)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"ok":      true,
+		"message": "API key created, store it now: it won't be shown again",
+		"key":     created.Key,
+		"api_key": created.APIKey,
+	})
+}
+
+func (a *APIKeysController) List(c *gin.Context) {
+	keys, err := a.service.List()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"ok":       true,
+		"api_keys": keys,
+	})
+}
+
+func (a *APIKeysController) Revoke(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(customError.NewError("INVALID_UUID", "Invalid UUID", http.StatusBadRequest))
+		return
+	}
+	actorId, _ := c.Get("userID")
+
+	if err := a.service.Revoke(id, actorId.(uuid.UUID)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"ok":      true,
+		"message": "API key revoked",
+	})
+}
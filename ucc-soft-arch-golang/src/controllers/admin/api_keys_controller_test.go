@@ -0,0 +1,79 @@
+package admin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/apikeys"
+	middlewares "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type stubAPIKeyService struct {
+	services.IAPIKeyService
+	owner   uuid.UUID
+	request apikeys.CreateAPIKeyRequestDto
+	revoked uuid.UUID
+}
+
+func (s *stubAPIKeyService) Create(ownerId uuid.UUID, dto apikeys.CreateAPIKeyRequestDto) (apikeys.CreatedAPIKeyDto, error) {
+	s.owner = ownerId
+	s.request = dto
+	return apikeys.CreatedAPIKeyDto{Key: "ucc_12345678.secret"}, nil
+}
+
+func (s *stubAPIKeyService) Revoke(id uuid.UUID, actorId uuid.UUID) error {
+	s.revoked = id
+	return nil
+}
+
+func makeAPIKeysRouter(service *stubAPIKeyService, adminId uuid.UUID) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	ctrl := NewAPIKeysController(service)
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	r.Use(func(c *gin.Context) { c.Set("userID", adminId); c.Next() })
+	r.POST("/admin/api-keys", ctrl.Create)
+	r.DELETE("/admin/api-keys/:id", ctrl.Revoke)
+	return r
+}
+
+func TestAPIKeysController_Create(t *testing.T) {
+	service := &stubAPIKeyService{}
+	adminId := uuid.New()
+	r := makeAPIKeysRouter(service, adminId)
+	req := httptest.NewRequest(http.MethodPost, "/admin/api-keys", strings.NewReader(`{"name":"reports","scopes":["audit:read"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", w.Code)
+	}
+	if service.owner != adminId || service.request.Name != "reports" || len(service.request.Scopes) != 1 {
+		t.Fatalf("request not forwarded: %+v", service.request)
+	}
+	if !strings.Contains(w.Body.String(), "ucc_12345678.secret") {
+		t.Fatalf("expected the new key in the response")
+	}
+}
+
+func TestAPIKeysController_Revoke(t *testing.T) {
+	service := &stubAPIKeyService{}
+	keyId := uuid.New()
+	r := makeAPIKeysRouter(service, uuid.New())
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/admin/api-keys/"+keyId.String(), nil))
+	if w.Code != http.StatusOK || service.revoked != keyId {
+		t.Fatalf("expected key %s to be revoked, got %d", keyId, w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/admin/api-keys/not-a-uuid", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
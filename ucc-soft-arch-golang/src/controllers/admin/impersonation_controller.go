@@ -0,0 +1,64 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/users"
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/jwt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ImpersonationController struct {
+	service services.IImpersonationService
+}
+
+type IImpersonationController interface {
+	Start(c *gin.Context)
+	End(c *gin.Context)
+}
+
+func NewImpersonationController(service services.IImpersonationService) *ImpersonationController {
+	return &ImpersonationController{service: service}
+}
+
+func (i *ImpersonationController) Start(c *gin.Context) {
+	userId, ok := userIdParam(c)
+	if !ok {
+		return
+	}
+	var startDto users.StartImpersonationRequestDto
+	if err := c.ShouldBindJSON(&startDto); err != nil {
+		c.Error(customError.NewError("INVALID_BODY", "Invalid request body", http.StatusBadRequest))
+		return
+	}
+	adminId, _ := c.Get("userID")
+
+	impersonation, err := i.service.Start(adminId.(uuid.UUID), userId, startDto.Reason, users.ClientInfoDto{Ip: c.ClientIP(), UserAgent: c.Request.UserAgent()})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"ok":            true,
+		"impersonation": impersonation,
+	})
+}
+
+// End is called with the impersonation token itself.
+func (i *ImpersonationController) End(c *gin.Context) {
+	claims, _ := c.Get("claims")
+
+	if err := i.service.End(claims.(*jwt.CustomClaims)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"ok":      true,
+		"message": "Impersonation ended",
+	})
+}
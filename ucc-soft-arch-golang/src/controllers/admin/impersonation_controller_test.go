@@ -0,0 +1,77 @@
+package admin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/users"
+	middlewares "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/jwt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type stubImpersonationService struct {
+	services.IImpersonationService
+	actor, target uuid.UUID
+	reason        string
+	ended         *jwt.CustomClaims
+}
+
+func (s *stubImpersonationService) Start(actorId uuid.UUID, userId uuid.UUID, reason string, client users.ClientInfoDto) (users.ImpersonationDto, error) {
+	s.actor, s.target, s.reason = actorId, userId, reason
+	return users.ImpersonationDto{AccessToken: "imp-token"}, nil
+}
+
+func (s *stubImpersonationService) End(claims *jwt.CustomClaims) error {
+	s.ended = claims
+	return nil
+}
+
+func TestImpersonationController_Start(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	service := &stubImpersonationService{}
+	ctrl := NewImpersonationController(service)
+	adminId, userId := uuid.New(), uuid.New()
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	r.Use(func(c *gin.Context) { c.Set("userID", adminId); c.Next() })
+	r.POST("/admin/users/:id/impersonate", ctrl.Start)
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/users/"+userId.String()+"/impersonate", strings.NewReader(`{"reason":"ticket 42"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || service.actor != adminId || service.target != userId || service.reason != "ticket 42" {
+		t.Fatalf("start not forwarded: %d %+v", w.Code, service)
+	}
+	if !strings.Contains(w.Body.String(), "imp-token") {
+		t.Fatalf("unexpected body: %s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/users/"+userId.String()+"/impersonate", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without a body, got %d", w.Code)
+	}
+}
+
+func TestImpersonationController_End(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	service := &stubImpersonationService{}
+	ctrl := NewImpersonationController(service)
+	claims := jwt.NewCustomClaims(uuid.New(), "student")
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	r.Use(func(c *gin.Context) { c.Set("claims", claims); c.Next() })
+	r.POST("/auth/impersonation/end", ctrl.End)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/auth/impersonation/end", nil))
+	if w.Code != http.StatusOK || service.ended != claims {
+		t.Fatalf("end not forwarded: %d", w.Code)
+	}
+}
@@ -0,0 +1,97 @@
+package admin
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/users"
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type SecurityController struct {
+	throttle  services.ILoginThrottleService
+	audit     services.IAuditService
+	twoFactor services.ITwoFactorService
+}
+
+type ISecurityController interface {
+	UnlockUser(c *gin.Context)
+	GetAuditLogs(c *gin.Context)
+	SetTwoFactorRequired(c *gin.Context)
+}
+
+func NewSecurityController(throttle services.ILoginThrottleService, audit services.IAuditService, twoFactor services.ITwoFactorService) *SecurityController {
+	return &SecurityController{throttle: throttle, audit: audit, twoFactor: twoFactor}
+}
+
+func (s *SecurityController) UnlockUser(c *gin.Context) {
+	userId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(customError.NewError("INVALID_UUID", "Invalid UUID", http.StatusBadRequest))
+		return
+	}
+	adminId, _ := c.Get("userID")
+
+	if err := s.throttle.Unlock(userId, adminId.(uuid.UUID)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"ok":      true,
+		"message": "User unlocked",
+	})
+}
+
+// GetAuditLogs accepts optional ?event=, ?user_id= and ?limit= filters.
+func (s *SecurityController) GetAuditLogs(c *gin.Context) {
+	userId := uuid.Nil
+	if raw := c.Query("user_id"); raw != "" {
+		parsed, err := uuid.Parse(raw)
+		if err != nil {
+			c.Error(customError.NewError("INVALID_UUID", "Invalid UUID", http.StatusBadRequest))
+			return
+		}
+		userId = parsed
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	entries, err := s.audit.List(c.Query("event"), userId, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"ok":   true,
+		"logs": entries,
+	})
+}
+
+func (s *SecurityController) SetTwoFactorRequired(c *gin.Context) {
+	userId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(customError.NewError("INVALID_UUID", "Invalid UUID", http.StatusBadRequest))
+		return
+	}
+	var requirementDto users.TwoFactorRequirementRequestDto
+	if err := c.ShouldBindJSON(&requirementDto); err != nil {
+		c.Error(customError.NewError("INVALID_BODY", "required must be a boolean", http.StatusBadRequest))
+		return
+	}
+	adminId, _ := c.Get("userID")
+
+	if err := s.twoFactor.SetRequired(userId, requirementDto.Required, adminId.(uuid.UUID)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"ok":       true,
+		"message":  "Two-factor requirement updated",
+		"required": requirementDto.Required,
+	})
+}
@@ -0,0 +1,127 @@
+package admin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	middlewares "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type stubThrottleService struct {
+	unlocked uuid.UUID
+	admin    uuid.UUID
+}
+
+func (s *stubThrottleService) Check(email string, ip string) error           { return nil }
+func (s *stubThrottleService) RegisterFailure(email string, ip string) error { return nil }
+func (s *stubThrottleService) RegisterSuccess(email string, ip string) error { return nil }
+func (s *stubThrottleService) Unlock(userId uuid.UUID, adminId uuid.UUID) error {
+	s.unlocked = userId
+	s.admin = adminId
+	return nil
+}
+
+type stubAuditService struct {
+	event string
+	user  uuid.UUID
+}
+
+func (s *stubAuditService) Record(entry model.AuditLog) error { return nil }
+func (s *stubAuditService) List(event string, userId uuid.UUID, limit int) (model.AuditLogs, error) {
+	s.event = event
+	s.user = userId
+	return model.AuditLogs{{Event: event}}, nil
+}
+
+type stubTwoFactorService struct {
+	services.ITwoFactorService
+	user     uuid.UUID
+	required bool
+}
+
+func (s *stubTwoFactorService) SetRequired(userId uuid.UUID, required bool, adminId uuid.UUID) error {
+	s.user = userId
+	s.required = required
+	return nil
+}
+
+func makeSecurityRouter(throttle *stubThrottleService, audit *stubAuditService, adminId uuid.UUID) *gin.Engine {
+	return makeSecurityRouterWithTwoFactor(throttle, audit, &stubTwoFactorService{}, adminId)
+}
+
+func makeSecurityRouterWithTwoFactor(throttle *stubThrottleService, audit *stubAuditService, twoFactor *stubTwoFactorService, adminId uuid.UUID) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	ctrl := NewSecurityController(throttle, audit, twoFactor)
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	r.Use(func(c *gin.Context) { c.Set("userID", adminId); c.Next() })
+	r.POST("/admin/users/:id/unlock", ctrl.UnlockUser)
+	r.GET("/admin/audit-logs", ctrl.GetAuditLogs)
+	r.PUT("/admin/users/:id/two-factor", ctrl.SetTwoFactorRequired)
+	return r
+}
+
+func TestSecurityController_UnlockUser(t *testing.T) {
+	throttle := &stubThrottleService{}
+	adminId, userId := uuid.New(), uuid.New()
+	r := makeSecurityRouter(throttle, &stubAuditService{}, adminId)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/users/"+userId.String()+"/unlock", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if throttle.unlocked != userId || throttle.admin != adminId {
+		t.Fatalf("expected unlock of %s by %s", userId, adminId)
+	}
+}
+
+func TestSecurityController_UnlockUser_InvalidId(t *testing.T) {
+	r := makeSecurityRouter(&stubThrottleService{}, &stubAuditService{}, uuid.New())
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/users/not-a-uuid/unlock", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestSecurityController_GetAuditLogs(t *testing.T) {
+	audit := &stubAuditService{}
+	userId := uuid.New()
+	r := makeSecurityRouter(&stubThrottleService{}, audit, uuid.New())
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/audit-logs?event=login_locked&user_id="+userId.String(), nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if audit.event != "login_locked" || audit.user != userId {
+		t.Fatalf("filters not forwarded: %q %s", audit.event, audit.user)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/audit-logs?user_id=bad", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestSecurityController_SetTwoFactorRequired(t *testing.T) {
+	twoFactor := &stubTwoFactorService{}
+	userId := uuid.New()
+	r := makeSecurityRouterWithTwoFactor(&stubThrottleService{}, &stubAuditService{}, twoFactor, uuid.New())
+	req := httptest.NewRequest(http.MethodPut, "/admin/users/"+userId.String()+"/two-factor", strings.NewReader(`{"required":true}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if twoFactor.user != userId || !twoFactor.required {
+		t.Fatalf("expected 2FA to be required for %s", userId)
+	}
+}
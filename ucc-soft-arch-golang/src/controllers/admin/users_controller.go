@@ -0,0 +1,168 @@
+package admin
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/users"
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type UsersController struct {
+	service services.IUserAdminService
+}
+
+type IUsersController interface {
+	List(c *gin.Context)
+	Get(c *gin.Context)
+	ChangeRole(c *gin.Context)
+	Suspend(c *gin.Context)
+	Reactivate(c *gin.Context)
+	ForcePasswordReset(c *gin.Context)
+}
+
+func NewUsersController(service services.IUserAdminService) *UsersController {
+	return &UsersController{service: service}
+}
+
+// List accepts optional ?q=, ?role=, ?status=, ?page= and ?limit= filters.
+func (u *UsersController) List(c *gin.Context) {
+	page, _ := strconv.Atoi(c.Query("page"))
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	result, err := u.service.List(c.Query("q"), c.Query("role"), c.Query("status"), page, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"ok":    true,
+		"users": result.Users,
+		"total": result.Total,
+		"page":  result.Page,
+		"limit": result.Limit,
+	})
+}
+
+func (u *UsersController) Get(c *gin.Context) {
+	userId, ok := userIdParam(c)
+	if !ok {
+		return
+	}
+
+	user, err := u.service.Get(userId)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"ok":   true,
+		"user": user,
+	})
+}
+
+func (u *UsersController) ChangeRole(c *gin.Context) {
+	userId, ok := userIdParam(c)
+	if !ok {
+		return
+	}
+	var roleDto users.ChangeRoleRequestDto
+	if err := c.ShouldBindJSON(&roleDto); err != nil {
+		c.Error(customError.NewError("INVALID_BODY", "Invalid request body", http.StatusBadRequest))
+		return
+	}
+	adminId, _ := c.Get("userID")
+
+	user, err := u.service.ChangeRole(userId, roleDto.Role, adminId.(uuid.UUID))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"ok":      true,
+		"message": "Role updated",
+		"user":    user,
+	})
+}
+
+func (u *UsersController) Suspend(c *gin.Context) {
+	userId, ok := userIdParam(c)
+	if !ok {
+		return
+	}
+	// the reason is optional, so is the body
+	var suspendDto users.SuspendUserRequestDto
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&suspendDto); err != nil {
+			c.Error(customError.NewError("INVALID_BODY", "Invalid request body", http.StatusBadRequest))
+			return
+		}
+	}
+	adminId, _ := c.Get("userID")
+
+	user, err := u.service.Suspend(userId, suspendDto.Reason, adminId.(uuid.UUID))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"ok":      true,
+		"message": "User suspended",
+		"user":    user,
+	})
+}
+
+func (u *UsersController) Reactivate(c *gin.Context) {
+	userId, ok := userIdParam(c)
+	if !ok {
+		return
+	}
+	adminId, _ := c.Get("userID")
+
+	user, err := u.service.Reactivate(userId, adminId.(uuid.UUID))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"ok":      true,
+		"message": "User reactivated",
+		"user":    user,
+	})
+}
+
+func (u *UsersController) ForcePasswordReset(c *gin.Context) {
+	userId, ok := userIdParam(c)
+	if !ok {
+		return
+	}
+	adminId, _ := c.Get("userID")
+
+	if err := u.service.ForcePasswordReset(userId, adminId.(uuid.UUID)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"ok":      true,
+		"message": "Password reset required, a reset link was sent to the user",
+	})
+}
+
+// userIdParam parses the :id path parameter and reports a bad one.
+func userIdParam(c *gin.Context) (uuid.UUID, bool) {
+	userId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(customError.NewError("INVALID_UUID", "Invalid UUID", http.StatusBadRequest))
+		return uuid.Nil, false
+	}
+	return userId, true
+}
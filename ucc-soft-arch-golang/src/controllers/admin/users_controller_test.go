@@ -0,0 +1,123 @@
+package admin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/users"
+	middlewares "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type stubUserAdminService struct {
+	services.IUserAdminService
+	query, role, status string
+	page, limit         int
+	target, actor       uuid.UUID
+	newRole, reason     string
+	reset               bool
+}
+
+func (s *stubUserAdminService) List(query string, role string, status string, page int, limit int) (users.AdminUsersPageDto, error) {
+	s.query, s.role, s.status, s.page, s.limit = query, role, status, page, limit
+	return users.AdminUsersPageDto{Users: []users.AdminUserDto{{Email: "a@test.com"}}, Total: 1, Page: 1, Limit: 20}, nil
+}
+
+func (s *stubUserAdminService) ChangeRole(id uuid.UUID, role string, actorId uuid.UUID) (users.AdminUserDto, error) {
+	s.target, s.newRole, s.actor = id, role, actorId
+	return users.AdminUserDto{Id: id, Role: role}, nil
+}
+
+func (s *stubUserAdminService) Suspend(id uuid.UUID, reason string, actorId uuid.UUID) (users.AdminUserDto, error) {
+	s.target, s.reason, s.actor = id, reason, actorId
+	return users.AdminUserDto{Id: id, Suspended: true}, nil
+}
+
+func (s *stubUserAdminService) ForcePasswordReset(id uuid.UUID, actorId uuid.UUID) error {
+	s.target, s.actor, s.reset = id, actorId, true
+	return nil
+}
+
+func makeUsersRouter(service *stubUserAdminService, adminId uuid.UUID) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	ctrl := NewUsersController(service)
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	r.Use(func(c *gin.Context) { c.Set("userID", adminId); c.Next() })
+	r.GET("/admin/users", ctrl.List)
+	r.GET("/admin/users/:id", ctrl.Get)
+	r.PUT("/admin/users/:id/role", ctrl.ChangeRole)
+	r.POST("/admin/users/:id/suspend", ctrl.Suspend)
+	r.POST("/admin/users/:id/password-reset", ctrl.ForcePasswordReset)
+	return r
+}
+
+func TestUsersController_List(t *testing.T) {
+	service := &stubUserAdminService{}
+	r := makeUsersRouter(service, uuid.New())
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/users?q=ana&role=student&status=active&page=2&limit=5", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if service.query != "ana" || service.role != "student" || service.status != "active" || service.page != 2 || service.limit != 5 {
+		t.Fatalf("filters not forwarded: %+v", service)
+	}
+	if !strings.Contains(w.Body.String(), `"total":1`) || !strings.Contains(w.Body.String(), "a@test.com") {
+		t.Fatalf("unexpected body: %s", w.Body.String())
+	}
+}
+
+func TestUsersController_ChangeRole(t *testing.T) {
+	service := &stubUserAdminService{}
+	adminId, userId := uuid.New(), uuid.New()
+	r := makeUsersRouter(service, adminId)
+	req := httptest.NewRequest(http.MethodPut, "/admin/users/"+userId.String()+"/role", strings.NewReader(`{"role":"instructor"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || service.target != userId || service.newRole != "instructor" || service.actor != adminId {
+		t.Fatalf("role change not forwarded: %d %+v", w.Code, service)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/admin/users/not-a-uuid/role", strings.NewReader(`{"role":"instructor"}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestUsersController_Suspend(t *testing.T) {
+	service := &stubUserAdminService{}
+	userId := uuid.New()
+	r := makeUsersRouter(service, uuid.New())
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/users/"+userId.String()+"/suspend", nil))
+	if w.Code != http.StatusOK || service.target != userId || service.reason != "" {
+		t.Fatalf("expected suspension without a reason, got %d %+v", w.Code, service)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/users/"+userId.String()+"/suspend", strings.NewReader(`{"reason":"spam"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || service.reason != "spam" {
+		t.Fatalf("expected reason to be forwarded, got %d %q", w.Code, service.reason)
+	}
+}
+
+func TestUsersController_ForcePasswordReset(t *testing.T) {
+	service := &stubUserAdminService{}
+	userId := uuid.New()
+	r := makeUsersRouter(service, uuid.New())
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/users/"+userId.String()+"/password-reset", nil))
+	if w.Code != http.StatusOK || !service.reset || service.target != userId {
+		t.Fatalf("expected a forced reset, got %d", w.Code)
+	}
+}
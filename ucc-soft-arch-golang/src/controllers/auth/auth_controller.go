@@ -1,11 +1,10 @@
 package auth
 
 import (
-	"fmt"
-
 	"github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/users"
 	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
 	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/jwt"
 	"github.com/gin-gonic/gin"
 )
 
@@ -16,6 +15,7 @@ type AuthController struct {
 type IAuthController interface {
 	RefreshToken(c *gin.Context)
 	Login(c *gin.Context)
+	Logout(c *gin.Context)
 }
 
 func NewAuthController(service *services.IAuthService) *AuthController {
@@ -23,17 +23,14 @@ func NewAuthController(service *services.IAuthService) *AuthController {
 }
 
 func (a *AuthController) RefreshToken(c *gin.Context) {
-	authorization := c.GetHeader("Authorization")
-
-	if authorization == "" {
-		err := customError.NewError("AUTHORIZATION_REQUIRED", "Authorization header is required", 400)
+	var refreshDto users.RefreshTokenRequestDto
+	if err := c.ShouldBindJSON(&refreshDto); err != nil || refreshDto.RefreshToken == "" {
+		err := customError.NewError("REFRESH_TOKEN_REQUIRED", "refresh_token is required", 400)
 		c.Error(err)
 		return
 	}
 
-	authorization = authorization[7:]
-
-	user, token, err := a.service.RefreshToken(authorization)
+	user, tokens, err := a.service.RefreshToken(refreshDto.RefreshToken, clientInfo(c))
 	if err != nil {
 		c.Error(err)
 		return
@@ -41,10 +38,12 @@ func (a *AuthController) RefreshToken(c *gin.Context) {
 	c.Header("Access-Control-Allow-Origin", "*")
 	c.Header("Content-Type", "application/json")
 	c.JSON(200, gin.H{
-		"ok":      true,
-		"message": "Token refreshed",
-		"token":   token,
-		"user":    user,
+		"ok":            true,
+		"message":       "Token refreshed",
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+		"user":          user,
 	})
 }
 
@@ -56,18 +55,70 @@ func (a *AuthController) Login(c *gin.Context) {
 		return
 	}
 
-	fmt.Println("LoginDTO: ", loginDto)
+	loginDto.ClientIp = c.ClientIP()
+	loginDto.UserAgent = c.Request.UserAgent()
 
-	user, token, err := a.service.Login(loginDto)
+	result, err := a.service.Login(loginDto)
 	if err != nil {
 		c.Error(err)
 		return
 	}
+	writeLoginResult(c, result)
+}
+
+// clientInfo describes the device a request comes from, for the session it
+// may start.
+func clientInfo(c *gin.Context) users.ClientInfoDto {
+	return users.ClientInfoDto{Ip: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+}
+
+// writeLoginResult answers a login step: the session, or the challenge when a
+// second factor is still needed.
+func writeLoginResult(c *gin.Context, result users.LoginResultDto) {
+	if result.Challenge != nil {
+		c.JSON(200, gin.H{
+			"ok":                  true,
+			"message":             "Two-factor authentication required",
+			"two_factor_required": true,
+			"challenge_token":     result.Challenge.ChallengeToken,
+			"expires_in":          result.Challenge.ExpiresIn,
+			"setup_required":      result.Challenge.SetupRequired,
+		})
+		return
+	}
+
+	response := gin.H{
+		"ok":            true,
+		"message":       "User logged in",
+		"user":          result.User,
+		"token":         result.Tokens.AccessToken,
+		"refresh_token": result.Tokens.RefreshToken,
+		"expires_in":    result.Tokens.ExpiresIn,
+	}
+	if len(result.RecoveryCodes) > 0 {
+		response["recovery_codes"] = result.RecoveryCodes
+	}
+	c.JSON(200, response)
+}
+
+func (a *AuthController) Logout(c *gin.Context) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		c.Error(customError.NewError("AUTHORIZATION_REQUIRED", "Authorization header is required", 400))
+		return
+	}
+
+	// the refresh token is optional, without it only the access token is revoked
+	var refreshDto users.RefreshTokenRequestDto
+	_ = c.ShouldBindJSON(&refreshDto)
+
+	if err := a.service.Logout(claims.(*jwt.CustomClaims), refreshDto.RefreshToken); err != nil {
+		c.Error(err)
+		return
+	}
 
 	c.JSON(200, gin.H{
 		"ok":      true,
-		"message": "User logged in",
-		"user":    user,
-		"token":   token,
+		"message": "User logged out",
 	})
 }
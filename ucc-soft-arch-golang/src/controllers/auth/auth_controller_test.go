@@ -11,26 +11,38 @@ import (
 	userDtos "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/users"
 	middlewares "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware"
 	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/jwt"
 	"github.com/gin-gonic/gin"
 )
 
 // stubAuthService implements services.IAuthService behavior we need
 type stubAuthService struct {
-	loginUser    userDtos.GetUserDto
-	loginToken   string
-	loginErr     error
-	refreshUser  userDtos.GetUserDto
-	refreshToken string
-	refreshErr   error
+	loginUser     userDtos.GetUserDto
+	loginToken    userDtos.TokenPairDto
+	loginErr      error
+	challenge     *userDtos.TwoFactorChallengeDto
+	refreshUser   userDtos.GetUserDto
+	refreshToken  userDtos.TokenPairDto
+	refreshErr    error
+	logoutErr     error
+	logoutRefresh string
 }
 
-func (s *stubAuthService) Login(dto userDtos.LoginRequestDto) (userDtos.GetUserDto, string, error) {
-	return s.loginUser, s.loginToken, s.loginErr
+func (s *stubAuthService) Login(dto userDtos.LoginRequestDto) (userDtos.LoginResultDto, error) {
+	if s.challenge != nil {
+		return userDtos.LoginResultDto{Challenge: s.challenge}, s.loginErr
+	}
+	return userDtos.LoginResultDto{User: s.loginUser, Tokens: s.loginToken}, s.loginErr
 }
-func (s *stubAuthService) RefreshToken(token string) (userDtos.GetUserDto, string, error) {
+func (s *stubAuthService) RefreshToken(token string, client userDtos.ClientInfoDto) (userDtos.GetUserDto, userDtos.TokenPairDto, error) {
 	return s.refreshUser, s.refreshToken, s.refreshErr
 }
 
+func (s *stubAuthService) Logout(claims *jwt.CustomClaims, refreshToken string) error {
+	s.logoutRefresh = refreshToken
+	return s.logoutErr
+}
+
 func makeAuthController(s *stubAuthService) *AuthController {
 	var as services.IAuthService = s
 	return NewAuthController(&as)
@@ -38,7 +50,7 @@ func makeAuthController(s *stubAuthService) *AuthController {
 
 func TestAuthController_Login_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
-	svc := &stubAuthService{loginUser: userDtos.GetUserDto{Email: "x@ex.com"}, loginToken: "tok"}
+	svc := &stubAuthService{loginUser: userDtos.GetUserDto{Email: "x@ex.com"}, loginToken: userDtos.TokenPairDto{AccessToken: "tok", RefreshToken: "ref"}}
 	ctrl := makeAuthController(svc)
 	r := gin.New()
 	r.Use(middlewares.ErrorHandler())
@@ -56,6 +68,32 @@ func TestAuthController_Login_Success(t *testing.T) {
 	}
 }
 
+func TestAuthController_Login_TwoFactorChallenge(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubAuthService{challenge: &userDtos.TwoFactorChallengeDto{ChallengeToken: "chal", ExpiresIn: 300}}
+	ctrl := makeAuthController(svc)
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	r.POST("/login", ctrl.Login)
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"email":"x@ex.com","password":"pw"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	var js map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &js); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+	if js["two_factor_required"] != true || js["challenge_token"] != "chal" {
+		t.Fatalf("expected a challenge, got %s", w.Body.String())
+	}
+	if _, hasToken := js["token"]; hasToken {
+		t.Fatalf("no session token must be issued before the second factor: %s", w.Body.String())
+	}
+}
+
 func TestAuthController_Login_InvalidJSON(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	svc := &stubAuthService{}
@@ -72,14 +110,14 @@ func TestAuthController_Login_InvalidJSON(t *testing.T) {
 	}
 }
 
-func TestAuthController_RefreshToken_MissingHeader(t *testing.T) {
+func TestAuthController_RefreshToken_MissingBody(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	svc := &stubAuthService{}
 	ctrl := makeAuthController(svc)
 	r := gin.New()
 	r.Use(middlewares.ErrorHandler())
-	r.GET("/refresh", ctrl.RefreshToken)
-	req := httptest.NewRequest(http.MethodGet, "/refresh", nil)
+	r.POST("/refresh", ctrl.RefreshToken)
+	req := httptest.NewRequest(http.MethodPost, "/refresh", nil)
 	w := httptest.NewRecorder()
 	r.ServeHTTP(w, req)
 	if w.Code != http.StatusBadRequest {
@@ -89,13 +127,13 @@ func TestAuthController_RefreshToken_MissingHeader(t *testing.T) {
 
 func TestAuthController_RefreshToken_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
-	svc := &stubAuthService{refreshUser: userDtos.GetUserDto{Email: "r@ex.com"}, refreshToken: "newtok"}
+	svc := &stubAuthService{refreshUser: userDtos.GetUserDto{Email: "r@ex.com"}, refreshToken: userDtos.TokenPairDto{AccessToken: "newtok", RefreshToken: "newref"}}
 	ctrl := makeAuthController(svc)
 	r := gin.New()
 	r.Use(middlewares.ErrorHandler())
-	r.GET("/refresh", ctrl.RefreshToken)
-	req := httptest.NewRequest(http.MethodGet, "/refresh", nil)
-	req.Header.Set("Authorization", "Bearer old")
+	r.POST("/refresh", ctrl.RefreshToken)
+	req := httptest.NewRequest(http.MethodPost, "/refresh", strings.NewReader(`{"refresh_token":"old"}`))
+	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	r.ServeHTTP(w, req)
 	if w.Code != http.StatusOK {
@@ -108,6 +146,9 @@ func TestAuthController_RefreshToken_Success(t *testing.T) {
 	if js["token"] != "newtok" {
 		t.Fatalf("expected newtok token, got %v", js["token"])
 	}
+	if js["refresh_token"] != "newref" {
+		t.Fatalf("expected rotated refresh token, got %v", js["refresh_token"])
+	}
 }
 
 func TestAuthController_RefreshToken_Error(t *testing.T) {
@@ -116,12 +157,47 @@ func TestAuthController_RefreshToken_Error(t *testing.T) {
 	ctrl := makeAuthController(svc)
 	r := gin.New()
 	r.Use(middlewares.ErrorHandler())
-	r.GET("/refresh", ctrl.RefreshToken)
-	req := httptest.NewRequest(http.MethodGet, "/refresh", nil)
-	req.Header.Set("Authorization", "Bearer old")
+	r.POST("/refresh", ctrl.RefreshToken)
+	req := httptest.NewRequest(http.MethodPost, "/refresh", strings.NewReader(`{"refresh_token":"old"}`))
+	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	r.ServeHTTP(w, req)
 	if w.Code != http.StatusInternalServerError && w.Code != http.StatusBadRequest {
 		t.Fatalf("expected error status, got %d", w.Code)
 	}
 }
+
+func TestAuthController_Logout_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubAuthService{}
+	ctrl := makeAuthController(svc)
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	r.POST("/logout", func(c *gin.Context) {
+		c.Set("claims", &jwt.CustomClaims{TokenId: "jti"})
+		ctrl.Logout(c)
+	})
+	req := httptest.NewRequest(http.MethodPost, "/logout", strings.NewReader(`{"refresh_token":"ref"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if svc.logoutRefresh != "ref" {
+		t.Fatalf("expected refresh token to be forwarded, got %q", svc.logoutRefresh)
+	}
+}
+
+func TestAuthController_Logout_MissingClaims(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := makeAuthController(&stubAuthService{})
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	r.POST("/logout", ctrl.Logout)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/logout", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/users"
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/gin-gonic/gin"
+)
+
+type EmailVerificationController struct {
+	service services.IEmailVerificationService
+}
+
+type IEmailVerificationController interface {
+	VerifyEmail(c *gin.Context)
+	ResendVerification(c *gin.Context)
+}
+
+func NewEmailVerificationController(service services.IEmailVerificationService) *EmailVerificationController {
+	return &EmailVerificationController{service: service}
+}
+
+func (e *EmailVerificationController) VerifyEmail(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.Error(customError.NewError("TOKEN_REQUIRED", "token is required", 400))
+		return
+	}
+
+	if err := e.service.Verify(token); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"ok":      true,
+		"message": "Email verified",
+	})
+}
+
+func (e *EmailVerificationController) ResendVerification(c *gin.Context) {
+	var resendDto users.ResendVerificationRequestDto
+	if err := c.ShouldBindJSON(&resendDto); err != nil || resendDto.Email == "" {
+		c.Error(customError.NewError("EMAIL_REQUIRED", "email is required", 400))
+		return
+	}
+
+	if err := e.service.Resend(resendDto.Email); err != nil {
+		c.Error(err)
+		return
+	}
+
+	// same answer whether the account exists or not
+	c.JSON(200, gin.H{
+		"ok":      true,
+		"message": "If the email is registered and not verified you will receive a new link",
+	})
+}
@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	middlewares "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type stubEmailVerificationService struct {
+	verified  string
+	resent    string
+	verifyErr error
+}
+
+func (s *stubEmailVerificationService) SendVerification(userId uuid.UUID) error {
+	return nil
+}
+
+func (s *stubEmailVerificationService) Resend(email string) error {
+	s.resent = email
+	return nil
+}
+
+func (s *stubEmailVerificationService) Verify(token string) error {
+	s.verified = token
+	return s.verifyErr
+}
+
+func makeEmailVerificationRouter(s *stubEmailVerificationService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	ctrl := NewEmailVerificationController(s)
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	r.GET("/verify", ctrl.VerifyEmail)
+	r.POST("/resend", ctrl.ResendVerification)
+	return r
+}
+
+func TestEmailVerificationController_Verify(t *testing.T) {
+	svc := &stubEmailVerificationService{}
+	req := httptest.NewRequest(http.MethodGet, "/verify?token=abc", nil)
+	w := httptest.NewRecorder()
+	makeEmailVerificationRouter(svc).ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if svc.verified != "abc" {
+		t.Fatalf("expected token abc, got %q", svc.verified)
+	}
+}
+
+func TestEmailVerificationController_Verify_MissingToken(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/verify", nil)
+	w := httptest.NewRecorder()
+	makeEmailVerificationRouter(&stubEmailVerificationService{}).ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestEmailVerificationController_Verify_InvalidToken(t *testing.T) {
+	svc := &stubEmailVerificationService{verifyErr: customError.NewError("INVALID_VERIFICATION_TOKEN", "Invalid or expired verification token", 400)}
+	req := httptest.NewRequest(http.MethodGet, "/verify?token=abc", nil)
+	w := httptest.NewRecorder()
+	makeEmailVerificationRouter(svc).ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestEmailVerificationController_Resend(t *testing.T) {
+	svc := &stubEmailVerificationService{}
+	w := post(makeEmailVerificationRouter(svc), "/resend", `{"email":"a@b.com"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if svc.resent != "a@b.com" {
+		t.Fatalf("expected resend for a@b.com, got %q", svc.resent)
+	}
+	w = post(makeEmailVerificationRouter(svc), "/resend", `{}`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
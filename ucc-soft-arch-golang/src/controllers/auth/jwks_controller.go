@@ -0,0 +1,25 @@
+package auth
+
+import (
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/gin-gonic/gin"
+)
+
+type JWKSController struct {
+	service services.ISigningKeyService
+}
+
+type IJWKSController interface {
+	GetJWKS(c *gin.Context)
+}
+
+func NewJWKSController(service services.ISigningKeyService) *JWKSController {
+	return &JWKSController{service: service}
+}
+
+// GetJWKS serves the standard JWKS document, not the usual {ok: ...} body,
+// so JWT libraries can consume it directly.
+func (j *JWKSController) GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(200, j.service.JWKS())
+}
@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/jwt"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+type stubSigningKeyService struct {
+	services.ISigningKeyService
+	keys jwt.JWKSet
+}
+
+func (s stubSigningKeyService) JWKS() jwt.JWKSet { return s.keys }
+
+func TestJWKSController_GetJWKS(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	service := stubSigningKeyService{keys: jwt.JWKSet{Keys: []jwt.JWK{{Kty: "OKP", Use: "sig", Alg: jwt.AlgEdDSA, Kid: "k1", Crv: "Ed25519", X: "abc"}}}}
+	r := gin.New()
+	r.GET("/.well-known/jwks.json", NewJWKSController(service).GetJWKS)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Header().Get("Cache-Control"), "max-age")
+
+	var body jwt.JWKSet
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Equal(t, service.keys, body)
+}
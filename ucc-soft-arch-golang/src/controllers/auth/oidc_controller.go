@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/users"
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/gin-gonic/gin"
+)
+
+type OIDCController struct {
+	service services.IOIDCService
+}
+
+type IOIDCController interface {
+	Login(c *gin.Context)
+	Callback(c *gin.Context)
+}
+
+// oidcStateCookie ties an external login to the browser that started it, so
+// a callback with someone else's state is refused.
+const oidcStateCookie = "oidc_state"
+
+func NewOIDCController(service services.IOIDCService) *OIDCController {
+	return &OIDCController{service: service}
+}
+
+// Login sends the browser to the identity provider.
+func (o *OIDCController) Login(c *gin.Context) {
+	url, state, err := o.service.AuthorizationURL()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	setOIDCStateCookie(c, state, 0)
+	c.Redirect(http.StatusFound, url)
+}
+
+// Callback finishes the login and answers like AuthController.Login.
+func (o *OIDCController) Callback(c *gin.Context) {
+	var callbackDto users.OIDCCallbackRequestDto
+	if err := c.ShouldBindJSON(&callbackDto); err != nil || callbackDto.Code == "" || callbackDto.State == "" {
+		c.Error(customError.NewError("CODE_REQUIRED", "code and state are required", 400))
+		return
+	}
+	browserState, err := c.Cookie(oidcStateCookie)
+	if err != nil || subtle.ConstantTimeCompare([]byte(browserState), []byte(callbackDto.State)) != 1 {
+		c.Error(customError.NewError("INVALID_OIDC_STATE", "Login session is invalid or expired, please try again", http.StatusBadRequest))
+		return
+	}
+	setOIDCStateCookie(c, "", -1)
+
+	result, err := o.service.Callback(callbackDto.Code, callbackDto.State, clientInfo(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	writeLoginResult(c, result)
+}
+
+func setOIDCStateCookie(c *gin.Context, state string, maxAge int) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	secure := c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+	c.SetCookie(oidcStateCookie, state, maxAge, "/auth/oidc", "", secure, true)
+}
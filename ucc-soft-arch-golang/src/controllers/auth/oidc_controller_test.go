@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	userDtos "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/users"
+	middlewares "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+type stubOIDCService struct {
+	code  string
+	state string
+}
+
+func (s *stubOIDCService) AuthorizationURL() (string, string, error) {
+	return "https://idp.example.com/authorize?state=xyz", "xyz", nil
+}
+
+func (s *stubOIDCService) Callback(code string, state string, client userDtos.ClientInfoDto) (userDtos.LoginResultDto, error) {
+	s.code, s.state = code, state
+	return userDtos.LoginResultDto{
+		User:   userDtos.GetUserDto{Email: "sso@uni.edu"},
+		Tokens: userDtos.TokenPairDto{AccessToken: "tok", RefreshToken: "ref"},
+	}, nil
+}
+
+func makeOIDCRouter(s *stubOIDCService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	ctrl := NewOIDCController(s)
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	r.GET("/oidc/login", ctrl.Login)
+	r.POST("/oidc/callback", ctrl.Callback)
+	return r
+}
+
+func TestOIDCController_Login_Redirects(t *testing.T) {
+	w := httptest.NewRecorder()
+	makeOIDCRouter(&stubOIDCService{}).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/oidc/login", nil))
+	if w.Code != http.StatusFound {
+		t.Fatalf("expected 302, got %d", w.Code)
+	}
+	if !strings.HasPrefix(w.Header().Get("Location"), "https://idp.example.com/authorize") {
+		t.Fatalf("unexpected redirect: %s", w.Header().Get("Location"))
+	}
+}
+
+// postCallback sends the callback from a browser holding the given state
+// cookie; an empty state sends none.
+func postCallback(r *gin.Engine, body string, cookieState string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/oidc/callback", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if cookieState != "" {
+		req.AddCookie(&http.Cookie{Name: oidcStateCookie, Value: cookieState})
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestOIDCController_Login_SetsStateCookie(t *testing.T) {
+	w := httptest.NewRecorder()
+	makeOIDCRouter(&stubOIDCService{}).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/oidc/login", nil))
+	cookie := w.Header().Get("Set-Cookie")
+	for _, want := range []string{oidcStateCookie + "=xyz", "HttpOnly", "SameSite=Lax"} {
+		if !strings.Contains(cookie, want) {
+			t.Fatalf("expected %q in cookie, got %q", want, cookie)
+		}
+	}
+}
+
+func TestOIDCController_Callback_RequiresStateCookie(t *testing.T) {
+	for name, cookieState := range map[string]string{"missing": "", "other browser": "s2"} {
+		svc := &stubOIDCService{}
+		w := postCallback(makeOIDCRouter(svc), `{"code":"c1","state":"s1"}`, cookieState)
+		if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "INVALID_OIDC_STATE") {
+			t.Fatalf("%s: expected INVALID_OIDC_STATE, got %d %s", name, w.Code, w.Body.String())
+		}
+		if svc.code != "" {
+			t.Fatalf("%s: the service should not be called", name)
+		}
+	}
+}
+
+func TestOIDCController_Callback(t *testing.T) {
+	svc := &stubOIDCService{}
+	w := postCallback(makeOIDCRouter(svc), `{"code":"c1","state":"s1"}`, "s1")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if svc.code != "c1" || svc.state != "s1" {
+		t.Fatalf("expected code and state to be forwarded, got %q %q", svc.code, svc.state)
+	}
+	if !strings.Contains(w.Body.String(), "tok") {
+		t.Fatalf("expected token in body: %s", w.Body.String())
+	}
+}
+
+func TestOIDCController_Callback_MissingCode(t *testing.T) {
+	w := post(makeOIDCRouter(&stubOIDCService{}), "/oidc/callback", `{"state":"s1"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
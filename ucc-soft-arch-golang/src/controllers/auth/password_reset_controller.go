@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/users"
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/gin-gonic/gin"
+)
+
+type PasswordResetController struct {
+	service services.IPasswordResetService
+}
+
+type IPasswordResetController interface {
+	ForgotPassword(c *gin.Context)
+	ResetPassword(c *gin.Context)
+}
+
+func NewPasswordResetController(service services.IPasswordResetService) *PasswordResetController {
+	return &PasswordResetController{service: service}
+}
+
+func (p *PasswordResetController) ForgotPassword(c *gin.Context) {
+	var forgotDto users.ForgotPasswordRequestDto
+	if err := c.ShouldBindJSON(&forgotDto); err != nil || forgotDto.Email == "" {
+		c.Error(customError.NewError("EMAIL_REQUIRED", "email is required", 400))
+		return
+	}
+
+	if err := p.service.RequestReset(forgotDto.Email); err != nil {
+		c.Error(err)
+		return
+	}
+
+	// same answer whether the account exists or not
+	c.JSON(200, gin.H{
+		"ok":      true,
+		"message": "If the email is registered you will receive a reset link",
+	})
+}
+
+func (p *PasswordResetController) ResetPassword(c *gin.Context) {
+	var resetDto users.ResetPasswordRequestDto
+	if err := c.ShouldBindJSON(&resetDto); err != nil {
+		c.Error(customError.NewError("INVALID_REQUEST", "token and password are required", 400))
+		return
+	}
+
+	if err := p.service.ResetPassword(resetDto.Token, resetDto.Password); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"ok":      true,
+		"message": "Password updated",
+	})
+}
@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	middlewares "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+type stubPasswordResetService struct {
+	requested  string
+	resetErr   error
+	requestErr error
+}
+
+func (s *stubPasswordResetService) RequestReset(email string) error {
+	s.requested = email
+	return s.requestErr
+}
+
+func (s *stubPasswordResetService) ResetPassword(token string, password string) error {
+	return s.resetErr
+}
+
+func makePasswordResetRouter(s *stubPasswordResetService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	ctrl := NewPasswordResetController(s)
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	r.POST("/forgot", ctrl.ForgotPassword)
+	r.POST("/reset", ctrl.ResetPassword)
+	return r
+}
+
+func post(r *gin.Engine, path string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestPasswordResetController_Forgot(t *testing.T) {
+	svc := &stubPasswordResetService{}
+	w := post(makePasswordResetRouter(svc), "/forgot", `{"email":"a@b.com"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if svc.requested != "a@b.com" {
+		t.Fatalf("expected reset requested for a@b.com, got %q", svc.requested)
+	}
+}
+
+func TestPasswordResetController_Forgot_MissingEmail(t *testing.T) {
+	w := post(makePasswordResetRouter(&stubPasswordResetService{}), "/forgot", `{}`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestPasswordResetController_Forgot_ServiceError(t *testing.T) {
+	svc := &stubPasswordResetService{requestErr: errors.New("boom")}
+	w := post(makePasswordResetRouter(svc), "/forgot", `{"email":"a@b.com"}`)
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", w.Code)
+	}
+}
+
+func TestPasswordResetController_Reset(t *testing.T) {
+	w := post(makePasswordResetRouter(&stubPasswordResetService{}), "/reset", `{"token":"t","password":"p"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+}
+
+func TestPasswordResetController_Reset_InvalidToken(t *testing.T) {
+	svc := &stubPasswordResetService{resetErr: customError.NewError("INVALID_RESET_TOKEN", "Invalid or expired reset token", 400)}
+	w := post(makePasswordResetRouter(svc), "/reset", `{"token":"t","password":"p"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"net/http"
+
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/jwt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type SessionsController struct {
+	service services.ISessionService
+}
+
+type ISessionsController interface {
+	List(c *gin.Context)
+	End(c *gin.Context)
+	EndOthers(c *gin.Context)
+}
+
+func NewSessionsController(service services.ISessionService) *SessionsController {
+	return &SessionsController{service: service}
+}
+
+func (s *SessionsController) List(c *gin.Context) {
+	userId, _ := c.Get("userID")
+	sessions, err := s.service.List(userId.(uuid.UUID), currentSession(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"ok":       true,
+		"sessions": sessions,
+	})
+}
+
+func (s *SessionsController) End(c *gin.Context) {
+	sessionId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(customError.NewError("INVALID_ID", "Invalid session id", http.StatusBadRequest))
+		return
+	}
+	userId, _ := c.Get("userID")
+
+	if err := s.service.End(userId.(uuid.UUID), sessionId); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"ok":      true,
+		"message": "Session ended",
+	})
+}
+
+// EndOthers signs out everywhere but the device making the request.
+func (s *SessionsController) EndOthers(c *gin.Context) {
+	current := currentSession(c)
+	if current == uuid.Nil {
+		c.Error(customError.NewError("SESSION_REQUIRED", "Sign in again to manage your sessions", http.StatusBadRequest))
+		return
+	}
+	userId, _ := c.Get("userID")
+
+	ended, err := s.service.EndOthers(userId.(uuid.UUID), current)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"ok":      true,
+		"message": "Signed out of all other sessions",
+		"ended":   ended,
+	})
+}
+
+// currentSession returns the session of the access token, uuid.Nil for
+// tokens issued before sessions existed.
+func currentSession(c *gin.Context) uuid.UUID {
+	claims, exists := c.Get("claims")
+	if !exists {
+		return uuid.Nil
+	}
+	sessionId, err := uuid.Parse(claims.(*jwt.CustomClaims).SessionId)
+	if err != nil {
+		return uuid.Nil
+	}
+	return sessionId
+}
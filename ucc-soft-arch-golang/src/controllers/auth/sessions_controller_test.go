@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	userDtos "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/users"
+	middlewares "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/jwt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+type stubSessionService struct {
+	services.ISessionService
+	current uuid.UUID
+	ended   uuid.UUID
+	kept    uuid.UUID
+}
+
+func (s *stubSessionService) List(userId uuid.UUID, currentId uuid.UUID) (userDtos.SessionsDto, error) {
+	s.current = currentId
+	return userDtos.SessionsDto{{Id: currentId, Current: true}}, nil
+}
+
+func (s *stubSessionService) End(userId uuid.UUID, sessionId uuid.UUID) error {
+	s.ended = sessionId
+	return nil
+}
+
+func (s *stubSessionService) EndOthers(userId uuid.UUID, currentId uuid.UUID) (int64, error) {
+	s.kept = currentId
+	return 2, nil
+}
+
+func makeSessionsRouter(service *stubSessionService, sessionId string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	ctrl := NewSessionsController(service)
+	userId := uuid.New()
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	r.Use(func(c *gin.Context) {
+		c.Set("userID", userId)
+		c.Set("claims", &jwt.CustomClaims{Id: userId, SessionId: sessionId})
+		c.Next()
+	})
+	r.GET("/auth/sessions", ctrl.List)
+	r.DELETE("/auth/sessions", ctrl.EndOthers)
+	r.DELETE("/auth/sessions/:id", ctrl.End)
+	return r
+}
+
+func TestSessionsController_List(t *testing.T) {
+	service := &stubSessionService{}
+	current := uuid.New()
+	r := makeSessionsRouter(service, current.String())
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/auth/sessions", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, current, service.current)
+	require.True(t, strings.Contains(w.Body.String(), `"current":true`))
+}
+
+func TestSessionsController_End(t *testing.T) {
+	service := &stubSessionService{}
+	r := makeSessionsRouter(service, uuid.NewString())
+	target := uuid.New()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/auth/sessions/"+target.String(), nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, target, service.ended)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/auth/sessions/not-a-uuid", nil))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSessionsController_EndOthers(t *testing.T) {
+	service := &stubSessionService{}
+	current := uuid.New()
+	w := httptest.NewRecorder()
+	makeSessionsRouter(service, current.String()).ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/auth/sessions", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, current, service.kept)
+
+	// a token without a session can't tell which one to keep
+	service = &stubSessionService{}
+	w = httptest.NewRecorder()
+	makeSessionsRouter(service, "").ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/auth/sessions", nil))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Equal(t, uuid.Nil, service.kept)
+}
@@ -0,0 +1,163 @@
+package auth
+
+import (
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/users"
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type TwoFactorController struct {
+	service services.ITwoFactorService
+}
+
+type ITwoFactorController interface {
+	Enroll(c *gin.Context)
+	Enable(c *gin.Context)
+	Disable(c *gin.Context)
+	RegenerateRecoveryCodes(c *gin.Context)
+	Setup(c *gin.Context)
+	Verify(c *gin.Context)
+}
+
+func NewTwoFactorController(service services.ITwoFactorService) *TwoFactorController {
+	return &TwoFactorController{service: service}
+}
+
+func (t *TwoFactorController) Enroll(c *gin.Context) {
+	userId, exists := c.Get("userID")
+	if !exists {
+		c.Error(customError.NewError("AUTHORIZATION_REQUIRED", "Authorization header is required", 401))
+		return
+	}
+
+	setup, err := t.service.BeginEnrollment(userId.(uuid.UUID))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"ok":               true,
+		"message":          "Scan the QR code and confirm with a code",
+		"secret":           setup.Secret,
+		"provisioning_uri": setup.ProvisioningURI,
+	})
+}
+
+func (t *TwoFactorController) Enable(c *gin.Context) {
+	userId, code, ok := bindCode(c)
+	if !ok {
+		return
+	}
+
+	recoveryCodes, err := t.service.Enable(userId, code)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"ok":             true,
+		"message":        "Two-factor authentication enabled",
+		"recovery_codes": recoveryCodes,
+	})
+}
+
+func (t *TwoFactorController) Disable(c *gin.Context) {
+	userId, code, ok := bindCode(c)
+	if !ok {
+		return
+	}
+
+	if err := t.service.Disable(userId, code); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"ok":      true,
+		"message": "Two-factor authentication disabled",
+	})
+}
+
+func (t *TwoFactorController) RegenerateRecoveryCodes(c *gin.Context) {
+	userId, code, ok := bindCode(c)
+	if !ok {
+		return
+	}
+
+	recoveryCodes, err := t.service.RegenerateRecoveryCodes(userId, code)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"ok":             true,
+		"message":        "Recovery codes regenerated",
+		"recovery_codes": recoveryCodes,
+	})
+}
+
+// Setup enrolls, during the login, a user that is required to use 2FA.
+func (t *TwoFactorController) Setup(c *gin.Context) {
+	var setupDto users.TwoFactorSetupRequestDto
+	if err := c.ShouldBindJSON(&setupDto); err != nil || setupDto.ChallengeToken == "" {
+		c.Error(customError.NewError("CHALLENGE_TOKEN_REQUIRED", "challenge_token is required", 400))
+		return
+	}
+
+	setup, err := t.service.SetupChallenge(setupDto.ChallengeToken)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"ok":               true,
+		"message":          "Scan the QR code and confirm with a code",
+		"secret":           setup.Secret,
+		"provisioning_uri": setup.ProvisioningURI,
+	})
+}
+
+// Verify is the second login step.
+func (t *TwoFactorController) Verify(c *gin.Context) {
+	var verifyDto users.TwoFactorVerifyRequestDto
+	if err := c.ShouldBindJSON(&verifyDto); err != nil || verifyDto.ChallengeToken == "" {
+		c.Error(customError.NewError("CHALLENGE_TOKEN_REQUIRED", "challenge_token is required", 400))
+		return
+	}
+	if verifyDto.Code == "" && verifyDto.RecoveryCode == "" {
+		c.Error(customError.NewError("CODE_REQUIRED", "code or recovery_code is required", 400))
+		return
+	}
+
+	verifyDto.ClientIp = c.ClientIP()
+	verifyDto.UserAgent = c.Request.UserAgent()
+
+	result, err := t.service.VerifyChallenge(verifyDto)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	writeLoginResult(c, result)
+}
+
+// bindCode reads the authenticated user and the code from the body,
+// answering the request itself when one of them is missing.
+func bindCode(c *gin.Context) (uuid.UUID, string, bool) {
+	userId, exists := c.Get("userID")
+	if !exists {
+		c.Error(customError.NewError("AUTHORIZATION_REQUIRED", "Authorization header is required", 401))
+		return uuid.Nil, "", false
+	}
+	var codeDto users.TwoFactorCodeRequestDto
+	if err := c.ShouldBindJSON(&codeDto); err != nil || codeDto.Code == "" {
+		c.Error(customError.NewError("CODE_REQUIRED", "code is required", 400))
+		return uuid.Nil, "", false
+	}
+	return userId.(uuid.UUID), codeDto.Code, true
+}
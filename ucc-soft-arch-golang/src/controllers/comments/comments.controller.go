@@ -24,6 +24,10 @@ func (c *CommentsController) NewComment(g *gin.Context) {
 		g.Error(err)
 		return
 	}
+	// the author is whoever is logged in, not whatever the body says
+	if userID, exists := g.Get("userID"); exists {
+		commentDto.UserId = userID.(uuid.UUID)
+	}
 
 	response, err := c.CommentsService.NewComment(commentDto)
 	if err != nil {
@@ -57,6 +61,9 @@ func (c *CommentsController) UpdateComment(g *gin.Context) {
 		g.Error(customError.NewError("INVALID_INPUTS", "Invalid fields", http.StatusBadRequest))
 		return
 	}
+	if userID, exists := g.Get("userID"); exists {
+		commentDto.UserId = userID.(uuid.UUID)
+	}
 	response, err := c.CommentsService.UpdateComment(commentDto)
 	if err != nil {
 		g.Error(err)
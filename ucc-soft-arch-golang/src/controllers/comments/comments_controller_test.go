@@ -20,9 +20,11 @@ type stubCommentsService struct {
 	getErr  error
 	updResp dto.CommentRequestResponseDto
 	updErr  error
+	got     dto.CommentRequestResponseDto
 }
 
 func (s *stubCommentsService) NewComment(d dto.CommentRequestResponseDto) (dto.CommentRequestResponseDto, error) {
+	s.got = d
 	return s.newResp, s.newErr
 }
 func (s *stubCommentsService) GetCourseComments(id uuid.UUID) (dto.GetCommentsResponse, error) {
@@ -48,6 +50,24 @@ func TestCommentsController_NewComment_Success(t *testing.T) {
 	}
 }
 
+func TestCommentsController_NewComment_UsesLoggedUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubCommentsService{}
+	ctrl := NewCommentsController(svc)
+	userID := uuid.New()
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	r.POST("/comments", func(c *gin.Context) { c.Set("userID", userID); ctrl.NewComment(c) })
+	body := `{"text":"ok","user_id":"` + uuid.NewString() + `"}`
+	req := httptest.NewRequest(http.MethodPost, "/comments", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if svc.got.UserId != userID {
+		t.Fatalf("expected comment author to be the logged user")
+	}
+}
+
 func TestCommentsController_GetCourseComments_InvalidUUID(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	svc := &stubCommentsService{}
@@ -0,0 +1,99 @@
+package courses
+
+import (
+	"net/http"
+
+	dto "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/courses"
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/gin-gonic/gin"
+)
+
+type CohortController struct {
+	service services.ICohortService
+}
+
+func NewCohortController(service services.ICohortService) *CohortController {
+	return &CohortController{service: service}
+}
+
+func (c *CohortController) GetCohorts(g *gin.Context) {
+	courseId, ok := uuidParam(g, "id")
+	if !ok {
+		return
+	}
+	response, err := c.service.GetCohorts(courseId)
+	if err != nil {
+		g.Error(err)
+		return
+	}
+	g.JSON(200, gin.H{
+		"ok":      true,
+		"cohorts": response,
+	})
+}
+
+func (c *CohortController) CreateCohort(g *gin.Context) {
+	courseId, ok := uuidParam(g, "id")
+	if !ok {
+		return
+	}
+	var request dto.CohortRequestDto
+	if err := g.ShouldBindJSON(&request); err != nil {
+		g.Error(customError.NewError("INVALID_INPUTS", "Invalid fields", http.StatusBadRequest))
+		return
+	}
+	response, err := c.service.CreateCohort(courseId, request)
+	if err != nil {
+		g.Error(err)
+		return
+	}
+	g.JSON(201, gin.H{
+		"ok":     true,
+		"cohort": response,
+	})
+}
+
+func (c *CohortController) UpdateCohort(g *gin.Context) {
+	courseId, ok := uuidParam(g, "id")
+	if !ok {
+		return
+	}
+	cohortId, ok := uuidParam(g, "cid")
+	if !ok {
+		return
+	}
+	var request dto.CohortRequestDto
+	if err := g.ShouldBindJSON(&request); err != nil {
+		g.Error(customError.NewError("INVALID_INPUTS", "Invalid fields", http.StatusBadRequest))
+		return
+	}
+	response, err := c.service.UpdateCohort(courseId, cohortId, request)
+	if err != nil {
+		g.Error(err)
+		return
+	}
+	g.JSON(200, gin.H{
+		"ok":     true,
+		"cohort": response,
+	})
+}
+
+func (c *CohortController) DeleteCohort(g *gin.Context) {
+	courseId, ok := uuidParam(g, "id")
+	if !ok {
+		return
+	}
+	cohortId, ok := uuidParam(g, "cid")
+	if !ok {
+		return
+	}
+	if err := c.service.DeleteCohort(courseId, cohortId); err != nil {
+		g.Error(err)
+		return
+	}
+	g.JSON(200, gin.H{
+		"ok":      true,
+		"message": "Cohort deleted successfully",
+	})
+}
@@ -0,0 +1,86 @@
+package courses
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	domain "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/courses"
+	middlewares "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type stubCohortService struct {
+	services.ICohortService
+	courseId, cohortId uuid.UUID
+	cohort             domain.CohortRequestDto
+}
+
+func (s *stubCohortService) CreateCohort(courseId uuid.UUID, cohort domain.CohortRequestDto) (domain.CohortDto, error) {
+	s.courseId, s.cohort = courseId, cohort
+	return domain.CohortDto{Id: uuid.New(), CourseId: courseId, Name: cohort.Name}, nil
+}
+
+func (s *stubCohortService) UpdateCohort(courseId uuid.UUID, cohortId uuid.UUID, cohort domain.CohortRequestDto) (domain.CohortDto, error) {
+	s.courseId, s.cohortId, s.cohort = courseId, cohortId, cohort
+	return domain.CohortDto{Id: cohortId, CourseId: courseId, Name: cohort.Name}, nil
+}
+
+func newCohortRouter(svc services.ICohortService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	ctrl := NewCohortController(svc)
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	r.POST("/courses/:id/cohorts", ctrl.CreateCohort)
+	r.PUT("/courses/:id/cohorts/:cid", ctrl.UpdateCohort)
+	return r
+}
+
+func TestCohortController_CreateCohort(t *testing.T) {
+	svc := &stubCohortService{}
+	r := newCohortRouter(svc)
+	courseId := uuid.New()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/courses/"+courseId.String()+"/cohorts",
+		strings.NewReader(`{"name":"March","starts_at":"2030-03-02T09:00:00-03:00","ends_at":"2030-04-02T09:00:00-03:00","time_zone":"America/Argentina/Cordoba","capacity":20}`)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	startsAt := time.Date(2030, 3, 2, 12, 0, 0, 0, time.UTC)
+	if svc.courseId != courseId || !svc.cohort.StartsAt.Equal(startsAt) || svc.cohort.TimeZone != "America/Argentina/Cordoba" || svc.cohort.Capacity != 20 {
+		t.Fatalf("request not forwarded: %+v", svc)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/courses/"+courseId.String()+"/cohorts", strings.NewReader(`{"starts_at":"next monday"}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestCohortController_UpdateCohort(t *testing.T) {
+	svc := &stubCohortService{}
+	r := newCohortRouter(svc)
+	courseId, cohortId := uuid.New(), uuid.New()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/courses/"+courseId.String()+"/cohorts/"+cohortId.String(),
+		strings.NewReader(`{"name":"April","capacity":5}`)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if svc.courseId != courseId || svc.cohortId != cohortId || svc.cohort.Name != "April" {
+		t.Fatalf("request not forwarded: %+v", svc)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/courses/"+courseId.String()+"/cohorts/nope", strings.NewReader(`{}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
@@ -3,9 +3,12 @@ package courses
 import (
 	"fmt"
 	"net/http"
+	"slices"
+	"strconv"
 
 	coursesDomain "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/courses"
 	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
 	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -25,6 +28,10 @@ func (c *CourseController) Create(g *gin.Context) {
 		g.Error(err)
 		return
 	}
+	// whoever creates the course becomes its owner
+	if userId, exists := g.Get("userID"); exists {
+		courseDto.OwnerId = userId.(uuid.UUID)
+	}
 	response, err := c.CourseService.CreateCourse(courseDto)
 	if err != nil {
 		g.Error(err)
@@ -37,14 +44,94 @@ func (c *CourseController) Create(g *gin.Context) {
 	})
 }
 
+// GetAll accepts ?filter=, ?category_id=, ?min_price=, ?max_price=,
+// ?min_rating=, ?status=, ?min_duration=, ?max_duration= and ?sort=, paged
+// with ?page= and ?limit= or with the cursors of a previous response. Facet
+// counts come along unless ?facets=false.
 func (c *CourseController) GetAll(g *gin.Context) {
-	filter := g.Query("filter")
-	response, err := c.CourseService.FindAllCourses(filter)
+	query, err := catalogQuery(g)
 	if err != nil {
 		g.Error(err)
 		return
 	}
-	g.JSON(200, response)
+	response, err := c.CourseService.FindAllCourses(query)
+	if err != nil {
+		g.Error(err)
+		return
+	}
+	g.JSON(200, gin.H{
+		"ok":          true,
+		"data":        response.Courses,
+		"total":       response.Total,
+		"limit":       response.Limit,
+		"page":        response.Page,
+		"next_cursor": response.NextCursor,
+		"prev_cursor": response.PrevCursor,
+		"facets":      response.Facets,
+	})
+}
+
+func catalogQuery(g *gin.Context) (coursesDomain.CatalogQueryDto, error) {
+	page, _ := strconv.Atoi(g.Query("page"))
+	limit, _ := strconv.Atoi(g.Query("limit"))
+	query := coursesDomain.CatalogQueryDto{
+		Filter: g.Query("filter"),
+		Sort:   g.Query("sort"),
+		Cursor: g.Query("cursor"),
+		Page:   page,
+		Limit:  limit,
+		Facets: g.Query("facets") != "false",
+	}
+	invalid := func(param string) error {
+		return customError.NewError("INVALID_QUERY", "Invalid "+param, http.StatusBadRequest)
+	}
+	if value := g.Query("category_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return query, invalid("category_id")
+		}
+		query.CategoryId = &id
+	}
+	for param, target := range map[string]**float64{
+		"min_price":  &query.MinPrice,
+		"max_price":  &query.MaxPrice,
+		"min_rating": &query.MinRating,
+	} {
+		if value := g.Query(param); value != "" {
+			number, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return query, invalid(param)
+			}
+			*target = &number
+		}
+	}
+	for param, target := range map[string]**int{
+		"min_duration": &query.MinDuration,
+		"max_duration": &query.MaxDuration,
+	} {
+		if value := g.Query(param); value != "" {
+			number, err := strconv.Atoi(value)
+			if err != nil {
+				return query, invalid(param)
+			}
+			*target = &number
+		}
+	}
+	if value := g.Query("status"); value != "" {
+		if !slices.Contains(model.CourseStatuses, value) {
+			return query, invalid("status")
+		}
+		query.Status = value
+	}
+	if value := g.Query("schedule"); value != "" {
+		if !slices.Contains(model.CohortSchedules, value) {
+			return query, invalid("schedule")
+		}
+		query.Schedule = value
+	}
+	// StaffView decides whether unpublished courses show up at all
+	query.IncludeUnpublished = g.GetBool("staffView")
+	return query, nil
 }
 
 func (c *CourseController) GetById(g *gin.Context) {
@@ -56,7 +143,7 @@ func (c *CourseController) GetById(g *gin.Context) {
 		return
 	}
 
-	response, err := c.CourseService.FindOneCourse(uuid)
+	response, err := c.CourseService.FindOneCourse(uuid, g.GetBool("staffView"))
 	if err != nil {
 		g.Error(err)
 		return
@@ -103,6 +190,76 @@ func (c *CourseController) DeleteCourse(g *gin.Context) {
 	})
 }
 
+func (c *CourseController) GetInstructorCourses(g *gin.Context) {
+	userId, exists := g.Get("userID")
+	if !exists {
+		g.Error(customError.NewError("AUTHORIZATION_REQUIRED", "Authorization header is required", http.StatusUnauthorized))
+		return
+	}
+	response, err := c.CourseService.FindInstructorCourses(userId.(uuid.UUID))
+	if err != nil {
+		g.Error(err)
+		return
+	}
+	g.JSON(200, response)
+}
+
+func (c *CourseController) GetInstructors(g *gin.Context) {
+	courseId, err := uuid.Parse(g.Param("id"))
+	if err != nil {
+		g.Error(customError.NewError("INVALID_UUID", "Invalid UUID", http.StatusBadRequest))
+		return
+	}
+	response, err := c.CourseService.GetInstructors(courseId)
+	if err != nil {
+		g.Error(err)
+		return
+	}
+	g.JSON(200, response)
+}
+
+func (c *CourseController) AddInstructor(g *gin.Context) {
+	courseId, err := uuid.Parse(g.Param("id"))
+	if err != nil {
+		g.Error(customError.NewError("INVALID_UUID", "Invalid UUID", http.StatusBadRequest))
+		return
+	}
+	var instructorDto coursesDomain.AddInstructorRequestDto
+	if err := g.ShouldBindJSON(&instructorDto); err != nil || instructorDto.UserId == uuid.Nil {
+		g.Error(customError.NewError("INVALID_USER_ID", "user_id is required", http.StatusBadRequest))
+		return
+	}
+	if err := c.CourseService.AddInstructor(courseId, instructorDto.UserId); err != nil {
+		g.Error(err)
+		return
+	}
+	g.JSON(201, gin.H{
+		"ok":      true,
+		"message": "Instructor added successfully",
+	})
+}
+
+func (c *CourseController) RemoveInstructor(g *gin.Context) {
+	courseId, err := uuid.Parse(g.Param("id"))
+	if err != nil {
+		g.Error(customError.NewError("INVALID_UUID", "Invalid UUID", http.StatusBadRequest))
+		return
+	}
+	userId, err := uuid.Parse(g.Param("uid"))
+	if err != nil {
+		g.Error(customError.NewError("INVALID_UUID", "Invalid UUID", http.StatusBadRequest))
+		return
+	}
+	if err := c.CourseService.RemoveInstructor(courseId, userId); err != nil {
+		g.Error(err)
+		return
+	}
+	g.JSON(200, gin.H{
+		"ok":      true,
+		"message": "Instructor removed successfully",
+	})
+}
+
 // FUNCION PARA PARSEAR UUID
 func parseUUID(value interface{}) uuid.UUID {
 	if value != nil {
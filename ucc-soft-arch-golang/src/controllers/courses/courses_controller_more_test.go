@@ -20,8 +20,10 @@ type fakeCourseService struct {
 func (f *fakeCourseService) CreateCourse(_ domain.CreateCoursesRequestDto) (domain.CreateCoursesResponseDto, error) {
 	return domain.CreateCoursesResponseDto{}, nil
 }
-func (f *fakeCourseService) FindAllCourses(_ string) (domain.GetAllCourses, error) { return nil, nil }
-func (f *fakeCourseService) FindOneCourse(_ uuid.UUID) (domain.GetCourseDto, error) {
+func (f *fakeCourseService) FindAllCourses(_ domain.CatalogQueryDto) (domain.CoursesPageDto, error) {
+	return domain.CoursesPageDto{}, nil
+}
+func (f *fakeCourseService) FindOneCourse(_ uuid.UUID, _ bool) (domain.GetCourseDto, error) {
 	return domain.GetCourseDto{}, nil
 }
 func (f *fakeCourseService) UpdateCourse(req domain.UpdateRequestDto) (domain.UpdateResponseDto, error) {
@@ -34,11 +36,22 @@ func (f *fakeCourseService) UpdateCourse(req domain.UpdateRequestDto) (domain.Up
 	return resp, nil
 }
 func (f *fakeCourseService) DeleteCourse(_ uuid.UUID) error { return nil }
+func (f *fakeCourseService) FindInstructorCourses(_ uuid.UUID) (domain.GetAllCourses, error) {
+	return nil, nil
+}
+func (f *fakeCourseService) IsInstructor(_ uuid.UUID, _ uuid.UUID) (bool, error) { return true, nil }
+func (f *fakeCourseService) IsOwner(_ uuid.UUID, _ uuid.UUID) (bool, error)      { return true, nil }
+func (f *fakeCourseService) IsLive(_ uuid.UUID) (bool, error)                    { return true, nil }
+func (f *fakeCourseService) GetInstructors(_ uuid.UUID) (domain.InstructorsDto, error) {
+	return nil, nil
+}
+func (f *fakeCourseService) AddInstructor(_ uuid.UUID, _ uuid.UUID) error    { return nil }
+func (f *fakeCourseService) RemoveInstructor(_ uuid.UUID, _ uuid.UUID) error { return nil }
 
 var _ interface {
 	CreateCourse(domain.CreateCoursesRequestDto) (domain.CreateCoursesResponseDto, error)
-	FindAllCourses(string) (domain.GetAllCourses, error)
-	FindOneCourse(uuid.UUID) (domain.GetCourseDto, error)
+	FindAllCourses(domain.CatalogQueryDto) (domain.CoursesPageDto, error)
+	FindOneCourse(uuid.UUID, bool) (domain.GetCourseDto, error)
 	UpdateCourse(domain.UpdateRequestDto) (domain.UpdateResponseDto, error)
 	DeleteCourse(uuid.UUID) error
 } = (*fakeCourseService)(nil)
@@ -120,3 +133,87 @@ func TestCourseController_Delete_Success(t *testing.T) {
 		t.Fatalf("expected 200, got %d", w.Code)
 	}
 }
+
+func TestCourseController_Create_SetsOwner(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubCourseService{}
+	ctrl := NewCourseController(svc)
+	owner := uuid.New()
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	r.POST("/courses/create", func(c *gin.Context) { c.Set("userID", owner) }, ctrl.Create)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/courses/create", bytes.NewBufferString(`{"course_name":"Go"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", w.Code)
+	}
+	if svc.created.OwnerId != owner {
+		t.Fatalf("expected owner %s, got %s", owner, svc.created.OwnerId)
+	}
+}
+
+func TestCourseController_AddInstructor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubCourseService{}
+	ctrl := NewCourseController(svc)
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	r.POST("/courses/:id/instructors", ctrl.AddInstructor)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/courses/"+uuid.New().String()+"/instructors", bytes.NewBufferString(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without user_id, got %d", w.Code)
+	}
+
+	userId := uuid.New()
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/courses/"+uuid.New().String()+"/instructors", bytes.NewBufferString(`{"user_id":"`+userId.String()+`"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", w.Code)
+	}
+	if svc.added != userId {
+		t.Fatalf("expected %s to be added, got %s", userId, svc.added)
+	}
+}
+
+func TestCourseController_RemoveInstructor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubCourseService{}
+	ctrl := NewCourseController(svc)
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	r.DELETE("/courses/:id/instructors/:uid", ctrl.RemoveInstructor)
+	userId := uuid.New()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/courses/"+uuid.New().String()+"/instructors/"+userId.String(), nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if svc.removed != userId {
+		t.Fatalf("expected %s to be removed, got %s", userId, svc.removed)
+	}
+}
+
+func TestCourseController_GetInstructorCourses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubCourseService{findAllResp: domain.GetAllCourses{{CourseName: "Mine"}}}
+	ctrl := NewCourseController(svc)
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	r.GET("/instructor/courses", func(c *gin.Context) { c.Set("userID", uuid.New()) }, ctrl.GetInstructorCourses)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/instructor/courses", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if !contains(w.Body.String(), "Mine") {
+		t.Fatalf("expected instructor courses in body: %s", w.Body.String())
+	}
+}
@@ -18,17 +18,24 @@ import (
 type stubCourseService struct {
 	findAllResp domain.GetAllCourses
 	findAllErr  error
+	query       domain.CatalogQueryDto
 	findOneResp domain.GetCourseDto
 	findOneErr  error
+	created     domain.CreateCoursesRequestDto
+	instructors domain.InstructorsDto
+	added       uuid.UUID
+	removed     uuid.UUID
 }
 
-func (s *stubCourseService) CreateCourse(_ domain.CreateCoursesRequestDto) (domain.CreateCoursesResponseDto, error) {
+func (s *stubCourseService) CreateCourse(req domain.CreateCoursesRequestDto) (domain.CreateCoursesResponseDto, error) {
+	s.created = req
 	return domain.CreateCoursesResponseDto{}, nil
 }
-func (s *stubCourseService) FindAllCourses(_ string) (domain.GetAllCourses, error) {
-	return s.findAllResp, s.findAllErr
+func (s *stubCourseService) FindAllCourses(query domain.CatalogQueryDto) (domain.CoursesPageDto, error) {
+	s.query = query
+	return domain.CoursesPageDto{Courses: s.findAllResp, Total: int64(len(s.findAllResp))}, s.findAllErr
 }
-func (s *stubCourseService) FindOneCourse(_ uuid.UUID) (domain.GetCourseDto, error) {
+func (s *stubCourseService) FindOneCourse(_ uuid.UUID, _ bool) (domain.GetCourseDto, error) {
 	if s.findOneErr != nil {
 		return domain.GetCourseDto{}, s.findOneErr
 	}
@@ -38,6 +45,23 @@ func (s *stubCourseService) UpdateCourse(_ domain.UpdateRequestDto) (domain.Upda
 	return domain.UpdateResponseDto{}, nil
 }
 func (s *stubCourseService) DeleteCourse(_ uuid.UUID) error { return nil }
+func (s *stubCourseService) FindInstructorCourses(_ uuid.UUID) (domain.GetAllCourses, error) {
+	return s.findAllResp, s.findAllErr
+}
+func (s *stubCourseService) IsInstructor(_ uuid.UUID, _ uuid.UUID) (bool, error) { return true, nil }
+func (s *stubCourseService) IsOwner(_ uuid.UUID, _ uuid.UUID) (bool, error)      { return true, nil }
+func (s *stubCourseService) IsLive(_ uuid.UUID) (bool, error)                    { return true, nil }
+func (s *stubCourseService) GetInstructors(_ uuid.UUID) (domain.InstructorsDto, error) {
+	return s.instructors, nil
+}
+func (s *stubCourseService) AddInstructor(_ uuid.UUID, userId uuid.UUID) error {
+	s.added = userId
+	return nil
+}
+func (s *stubCourseService) RemoveInstructor(_ uuid.UUID, userId uuid.UUID) error {
+	s.removed = userId
+	return nil
+}
 
 func TestCourseController_GetAll_OK(t *testing.T) {
 	gin.SetMode(gin.TestMode)
@@ -63,6 +87,59 @@ func TestCourseController_GetAll_OK(t *testing.T) {
 	if !contains(w.Body.String(), "Intro Go") {
 		t.Fatalf("expected response to include course name 'Intro Go', got: %s", w.Body.String())
 	}
+	// the frontend reads the list from data
+	var body struct {
+		Data []json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || len(body.Data) != 1 {
+		t.Fatalf("expected the courses under data, got: %s", w.Body.String())
+	}
+}
+
+func TestCourseController_GetAll_ParsesQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubCourseService{}
+	ctrl := NewCourseController(svc)
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	r.GET("/courses", ctrl.GetAll)
+
+	categoryId := uuid.New()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/courses?category_id="+categoryId.String()+
+		"&min_price=10&max_price=99.5&min_rating=4&status=published&schedule=upcoming&min_duration=2&max_duration=8&sort=-price&page=3&limit=5", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	q := svc.query
+	if *q.CategoryId != categoryId || *q.MinPrice != 10 || *q.MaxPrice != 99.5 || *q.MinRating != 4 || q.Status != "published" || q.Schedule != "upcoming" || q.IncludeUnpublished ||
+		*q.MinDuration != 2 || *q.MaxDuration != 8 || q.Sort != "-price" || q.Page != 3 || q.Limit != 5 || !q.Facets {
+		t.Fatalf("query not forwarded: %+v", q)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/courses?facets=false", nil))
+	if w.Code != http.StatusOK || svc.query.Facets {
+		t.Fatalf("facets not turned off: %d %+v", w.Code, svc.query)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/courses?min_price=cheap", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/courses?status=hidden", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown status, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/courses?schedule=soon", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown schedule, got %d", w.Code)
+	}
 }
 
 func TestCourseController_GetById_InvalidUUID(t *testing.T) {
@@ -0,0 +1,223 @@
+package courses
+
+import (
+	"net/http"
+
+	dto "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/courses"
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CurriculumController struct {
+	service services.ICurriculumService
+}
+
+func NewCurriculumController(service services.ICurriculumService) *CurriculumController {
+	return &CurriculumController{service: service}
+}
+
+func (c *CurriculumController) GetCurriculum(g *gin.Context) {
+	courseId, ok := uuidParam(g, "id")
+	if !ok {
+		return
+	}
+	response, err := c.service.GetCurriculum(courseId)
+	if err != nil {
+		g.Error(err)
+		return
+	}
+	g.JSON(200, gin.H{
+		"ok":         true,
+		"curriculum": response,
+	})
+}
+
+func (c *CurriculumController) Reorder(g *gin.Context) {
+	courseId, ok := uuidParam(g, "id")
+	if !ok {
+		return
+	}
+	var order dto.CurriculumOrderDto
+	if err := g.ShouldBindJSON(&order); err != nil {
+		g.Error(customError.NewError("INVALID_INPUTS", "Invalid fields", http.StatusBadRequest))
+		return
+	}
+	response, err := c.service.Reorder(courseId, order)
+	if err != nil {
+		g.Error(err)
+		return
+	}
+	g.JSON(200, gin.H{
+		"ok":         true,
+		"curriculum": response,
+	})
+}
+
+func (c *CurriculumController) CreateSection(g *gin.Context) {
+	courseId, ok := uuidParam(g, "id")
+	if !ok {
+		return
+	}
+	var request dto.SectionRequestDto
+	if err := g.ShouldBindJSON(&request); err != nil {
+		g.Error(customError.NewError("INVALID_INPUTS", "Invalid fields", http.StatusBadRequest))
+		return
+	}
+	response, err := c.service.CreateSection(courseId, request)
+	if err != nil {
+		g.Error(err)
+		return
+	}
+	g.JSON(201, gin.H{
+		"ok":      true,
+		"section": response,
+	})
+}
+
+func (c *CurriculumController) UpdateSection(g *gin.Context) {
+	courseId, ok := uuidParam(g, "id")
+	if !ok {
+		return
+	}
+	sectionId, ok := uuidParam(g, "sid")
+	if !ok {
+		return
+	}
+	var request dto.SectionRequestDto
+	if err := g.ShouldBindJSON(&request); err != nil {
+		g.Error(customError.NewError("INVALID_INPUTS", "Invalid fields", http.StatusBadRequest))
+		return
+	}
+	response, err := c.service.UpdateSection(courseId, sectionId, request)
+	if err != nil {
+		g.Error(err)
+		return
+	}
+	g.JSON(200, gin.H{
+		"ok":      true,
+		"section": response,
+	})
+}
+
+func (c *CurriculumController) DeleteSection(g *gin.Context) {
+	courseId, ok := uuidParam(g, "id")
+	if !ok {
+		return
+	}
+	sectionId, ok := uuidParam(g, "sid")
+	if !ok {
+		return
+	}
+	if err := c.service.DeleteSection(courseId, sectionId); err != nil {
+		g.Error(err)
+		return
+	}
+	g.JSON(200, gin.H{
+		"ok":      true,
+		"message": "Section deleted successfully",
+	})
+}
+
+// GetLesson returns a lesson with its content; the route only lets enrolled
+// users, instructors and admins in.
+func (c *CurriculumController) GetLesson(g *gin.Context) {
+	courseId, ok := uuidParam(g, "id")
+	if !ok {
+		return
+	}
+	lessonId, ok := uuidParam(g, "lid")
+	if !ok {
+		return
+	}
+	response, err := c.service.GetLesson(courseId, lessonId)
+	if err != nil {
+		g.Error(err)
+		return
+	}
+	g.JSON(200, gin.H{
+		"ok":     true,
+		"lesson": response,
+	})
+}
+
+func (c *CurriculumController) CreateLesson(g *gin.Context) {
+	courseId, ok := uuidParam(g, "id")
+	if !ok {
+		return
+	}
+	sectionId, ok := uuidParam(g, "sid")
+	if !ok {
+		return
+	}
+	var request dto.LessonRequestDto
+	if err := g.ShouldBindJSON(&request); err != nil {
+		g.Error(customError.NewError("INVALID_INPUTS", "Invalid fields", http.StatusBadRequest))
+		return
+	}
+	response, err := c.service.CreateLesson(courseId, sectionId, request)
+	if err != nil {
+		g.Error(err)
+		return
+	}
+	g.JSON(201, gin.H{
+		"ok":     true,
+		"lesson": response,
+	})
+}
+
+func (c *CurriculumController) UpdateLesson(g *gin.Context) {
+	courseId, ok := uuidParam(g, "id")
+	if !ok {
+		return
+	}
+	lessonId, ok := uuidParam(g, "lid")
+	if !ok {
+		return
+	}
+	var request dto.LessonRequestDto
+	if err := g.ShouldBindJSON(&request); err != nil {
+		g.Error(customError.NewError("INVALID_INPUTS", "Invalid fields", http.StatusBadRequest))
+		return
+	}
+	response, err := c.service.UpdateLesson(courseId, lessonId, request)
+	if err != nil {
+		g.Error(err)
+		return
+	}
+	g.JSON(200, gin.H{
+		"ok":     true,
+		"lesson": response,
+	})
+}
+
+func (c *CurriculumController) DeleteLesson(g *gin.Context) {
+	courseId, ok := uuidParam(g, "id")
+	if !ok {
+		return
+	}
+	lessonId, ok := uuidParam(g, "lid")
+	if !ok {
+		return
+	}
+	if err := c.service.DeleteLesson(courseId, lessonId); err != nil {
+		g.Error(err)
+		return
+	}
+	g.JSON(200, gin.H{
+		"ok":      true,
+		"message": "Lesson deleted successfully",
+	})
+}
+
+// uuidParam parses a route parameter, reporting INVALID_UUID when it isn't
+// one.
+func uuidParam(g *gin.Context, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(g.Param(name))
+	if err != nil {
+		g.Error(customError.NewError("INVALID_UUID", "Invalid UUID", http.StatusBadRequest))
+		return uuid.Nil, false
+	}
+	return id, true
+}
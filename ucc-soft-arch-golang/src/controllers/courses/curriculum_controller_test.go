@@ -0,0 +1,85 @@
+package courses
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	domain "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/courses"
+	middlewares "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type stubCurriculumService struct {
+	services.ICurriculumService
+	courseId, sectionId uuid.UUID
+	lesson              domain.LessonRequestDto
+	order               domain.CurriculumOrderDto
+}
+
+func (s *stubCurriculumService) CreateLesson(courseId uuid.UUID, sectionId uuid.UUID, lesson domain.LessonRequestDto) (domain.LessonDto, error) {
+	s.courseId, s.sectionId, s.lesson = courseId, sectionId, lesson
+	return domain.LessonDto{Id: uuid.New(), SectionId: sectionId, Title: lesson.Title, Type: lesson.Type}, nil
+}
+
+func (s *stubCurriculumService) Reorder(courseId uuid.UUID, order domain.CurriculumOrderDto) (domain.CurriculumDto, error) {
+	s.courseId, s.order = courseId, order
+	return domain.CurriculumDto{CourseId: courseId, Sections: []domain.SectionDto{}}, nil
+}
+
+func newCurriculumRouter(svc services.ICurriculumService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	ctrl := NewCurriculumController(svc)
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	r.POST("/courses/:id/sections/:sid/lessons", ctrl.CreateLesson)
+	r.PUT("/courses/:id/curriculum/order", ctrl.Reorder)
+	return r
+}
+
+func TestCurriculumController_CreateLesson(t *testing.T) {
+	svc := &stubCurriculumService{}
+	r := newCurriculumRouter(svc)
+	courseId, sectionId := uuid.New(), uuid.New()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/courses/"+courseId.String()+"/sections/"+sectionId.String()+"/lessons",
+		strings.NewReader(`{"title":"Intro","type":"video","url":"https://v.example.com/1","duration":5}`)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if svc.courseId != courseId || svc.sectionId != sectionId || svc.lesson.Url != "https://v.example.com/1" || svc.lesson.Duration != 5 {
+		t.Fatalf("request not forwarded: %+v", svc)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/courses/"+courseId.String()+"/sections/nope/lessons", strings.NewReader(`{}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestCurriculumController_Reorder(t *testing.T) {
+	svc := &stubCurriculumService{}
+	r := newCurriculumRouter(svc)
+	courseId, sectionId, lessonId := uuid.New(), uuid.New(), uuid.New()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/courses/"+courseId.String()+"/curriculum/order",
+		strings.NewReader(`{"sections":[{"id":"`+sectionId.String()+`","lessons":["`+lessonId.String()+`"]}]}`)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(svc.order.Sections) != 1 || svc.order.Sections[0].Id != sectionId || svc.order.Sections[0].Lessons[0] != lessonId {
+		t.Fatalf("order not forwarded: %+v", svc.order)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/courses/"+courseId.String()+"/curriculum/order", strings.NewReader(`{"sections":"x"}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
@@ -0,0 +1,64 @@
+package courses
+
+import (
+	"net/http"
+
+	dto "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/courses"
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/jwt"
+	"github.com/gin-gonic/gin"
+)
+
+type LifecycleController struct {
+	service services.ICourseLifecycleService
+}
+
+func NewLifecycleController(service services.ICourseLifecycleService) *LifecycleController {
+	return &LifecycleController{service: service}
+}
+
+// ChangeStatus moves the course to the status in the body. Who may do it
+// depends on the transition, so the service checks it.
+func (c *LifecycleController) ChangeStatus(g *gin.Context) {
+	courseId, ok := uuidParam(g, "id")
+	if !ok {
+		return
+	}
+	var request dto.CourseStatusRequestDto
+	if err := g.ShouldBindJSON(&request); err != nil {
+		g.Error(customError.NewError("INVALID_INPUTS", "Invalid fields", http.StatusBadRequest))
+		return
+	}
+	claims, _ := g.Get("claims")
+	response, err := c.service.ChangeStatus(courseId, request.Status, claims.(*jwt.CustomClaims))
+	if err != nil {
+		g.Error(err)
+		return
+	}
+	g.JSON(200, gin.H{
+		"ok":     true,
+		"course": response,
+	})
+}
+
+func (c *LifecycleController) Schedule(g *gin.Context) {
+	courseId, ok := uuidParam(g, "id")
+	if !ok {
+		return
+	}
+	var request dto.CourseScheduleDto
+	if err := g.ShouldBindJSON(&request); err != nil {
+		g.Error(customError.NewError("INVALID_INPUTS", "Invalid fields", http.StatusBadRequest))
+		return
+	}
+	response, err := c.service.Schedule(courseId, request)
+	if err != nil {
+		g.Error(err)
+		return
+	}
+	g.JSON(200, gin.H{
+		"ok":     true,
+		"course": response,
+	})
+}
@@ -0,0 +1,83 @@
+package courses
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	domain "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/courses"
+	middlewares "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/jwt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type stubLifecycleService struct {
+	services.ICourseLifecycleService
+	status   string
+	claims   *jwt.CustomClaims
+	schedule domain.CourseScheduleDto
+}
+
+func (s *stubLifecycleService) ChangeStatus(courseId uuid.UUID, status string, claims *jwt.CustomClaims) (domain.CourseLifecycleDto, error) {
+	s.status, s.claims = status, claims
+	return domain.CourseLifecycleDto{Id: courseId, Status: status}, nil
+}
+
+func (s *stubLifecycleService) Schedule(courseId uuid.UUID, schedule domain.CourseScheduleDto) (domain.CourseLifecycleDto, error) {
+	s.schedule = schedule
+	return domain.CourseLifecycleDto{Id: courseId, PublishAt: schedule.PublishAt}, nil
+}
+
+func TestLifecycleController_ChangeStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubLifecycleService{}
+	ctrl := NewLifecycleController(svc)
+	claims := jwt.NewCustomClaims(uuid.New(), model.RoleAdmin)
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	r.Use(func(c *gin.Context) { c.Set("claims", claims); c.Next() })
+	r.POST("/courses/:id/status", ctrl.ChangeStatus)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/courses/"+uuid.NewString()+"/status", strings.NewReader(`{"status":"published"}`)))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"status":"published"`) {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if svc.status != model.CoursePublished || svc.claims != claims {
+		t.Fatalf("request not forwarded: %q %v", svc.status, svc.claims)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/courses/"+uuid.NewString()+"/status", strings.NewReader(`{}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without a status, got %d", w.Code)
+	}
+}
+
+func TestLifecycleController_Schedule(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubLifecycleService{}
+	ctrl := NewLifecycleController(svc)
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	r.PUT("/courses/:id/schedule", ctrl.Schedule)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/courses/"+uuid.NewString()+"/schedule", strings.NewReader(`{"publish_at":"2025-05-01T09:00:00Z"}`)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if svc.schedule.PublishAt == nil || svc.schedule.UnpublishAt != nil {
+		t.Fatalf("schedule not forwarded: %+v", svc.schedule)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/courses/"+uuid.NewString()+"/schedule", strings.NewReader(`{"publish_at":"tomorrow"}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
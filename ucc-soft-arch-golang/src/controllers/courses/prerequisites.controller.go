@@ -0,0 +1,116 @@
+package courses
+
+import (
+	"net/http"
+
+	dto "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/courses"
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type PrerequisitesController struct {
+	service services.IPrerequisiteService
+}
+
+func NewPrerequisitesController(service services.IPrerequisiteService) *PrerequisitesController {
+	return &PrerequisitesController{service: service}
+}
+
+func (c *PrerequisitesController) GetPrerequisites(g *gin.Context) {
+	courseId, ok := uuidParam(g, "id")
+	if !ok {
+		return
+	}
+	response, err := c.service.GetPrerequisites(courseId)
+	if err != nil {
+		g.Error(err)
+		return
+	}
+	g.JSON(200, gin.H{
+		"ok":            true,
+		"prerequisites": response,
+	})
+}
+
+func (c *PrerequisitesController) SetPrerequisites(g *gin.Context) {
+	courseId, ok := uuidParam(g, "id")
+	if !ok {
+		return
+	}
+	var request dto.PrerequisitesRequestDto
+	if err := g.ShouldBindJSON(&request); err != nil {
+		g.Error(customError.NewError("INVALID_INPUTS", "Invalid fields", http.StatusBadRequest))
+		return
+	}
+	response, err := c.service.SetPrerequisites(courseId, request)
+	if err != nil {
+		g.Error(err)
+		return
+	}
+	g.JSON(200, gin.H{
+		"ok":            true,
+		"prerequisites": response,
+	})
+}
+
+// GetMyUnmetPrerequisites lists what the logged in user still has to
+// complete before enrolling in the course.
+func (c *PrerequisitesController) GetMyUnmetPrerequisites(g *gin.Context) {
+	courseId, ok := uuidParam(g, "id")
+	if !ok {
+		return
+	}
+	userID, _ := g.Get("userID")
+	response, err := c.service.UnmetPrerequisites(userID.(uuid.UUID), courseId)
+	if err != nil {
+		g.Error(err)
+		return
+	}
+	g.JSON(200, gin.H{
+		"ok":            true,
+		"prerequisites": response,
+	})
+}
+
+// GrantWaiver lets a user enroll in the course without its prerequisites.
+func (c *PrerequisitesController) GrantWaiver(g *gin.Context) {
+	courseId, ok := uuidParam(g, "id")
+	if !ok {
+		return
+	}
+	userId, ok := uuidParam(g, "uid")
+	if !ok {
+		return
+	}
+	adminID, _ := g.Get("userID")
+	response, err := c.service.GrantWaiver(courseId, userId, adminID.(uuid.UUID))
+	if err != nil {
+		g.Error(err)
+		return
+	}
+	g.JSON(200, gin.H{
+		"ok":     true,
+		"waiver": response,
+	})
+}
+
+func (c *PrerequisitesController) RevokeWaiver(g *gin.Context) {
+	courseId, ok := uuidParam(g, "id")
+	if !ok {
+		return
+	}
+	userId, ok := uuidParam(g, "uid")
+	if !ok {
+		return
+	}
+	if err := c.service.RevokeWaiver(courseId, userId); err != nil {
+		g.Error(err)
+		return
+	}
+	g.JSON(200, gin.H{
+		"ok":      true,
+		"message": "Waiver revoked successfully",
+	})
+}
@@ -0,0 +1,74 @@
+package courses
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	domain "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/courses"
+	middlewares "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type stubPrerequisiteService struct {
+	services.IPrerequisiteService
+	request           domain.PrerequisitesRequestDto
+	userId, grantedBy uuid.UUID
+}
+
+func (s *stubPrerequisiteService) SetPrerequisites(courseId uuid.UUID, request domain.PrerequisitesRequestDto) (domain.PrerequisitesDto, error) {
+	s.request = request
+	return domain.PrerequisitesDto{{Id: request.CourseIds[0], CourseName: "Basics"}}, nil
+}
+
+func (s *stubPrerequisiteService) GrantWaiver(courseId uuid.UUID, userId uuid.UUID, grantedBy uuid.UUID) (domain.PrerequisiteWaiverDto, error) {
+	s.userId, s.grantedBy = userId, grantedBy
+	return domain.PrerequisiteWaiverDto{CourseId: courseId, UserId: userId, GrantedBy: grantedBy}, nil
+}
+
+func TestPrerequisitesController_SetPrerequisites(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubPrerequisiteService{}
+	ctrl := NewPrerequisitesController(svc)
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	r.PUT("/courses/:id/prerequisites", ctrl.SetPrerequisites)
+	basics := uuid.New()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/courses/"+uuid.NewString()+"/prerequisites", strings.NewReader(`{"course_ids":["`+basics.String()+`"]}`)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(svc.request.CourseIds) != 1 || svc.request.CourseIds[0] != basics {
+		t.Fatalf("request not forwarded: %+v", svc.request)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/courses/"+uuid.NewString()+"/prerequisites", strings.NewReader(`{"course_ids":["nope"]}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestPrerequisitesController_GrantWaiver(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubPrerequisiteService{}
+	ctrl := NewPrerequisitesController(svc)
+	adminId, userId := uuid.New(), uuid.New()
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	r.PUT("/courses/:id/prerequisite-waivers/:uid", func(c *gin.Context) { c.Set("userID", adminId); ctrl.GrantWaiver(c) })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/courses/"+uuid.NewString()+"/prerequisite-waivers/"+userId.String(), nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if svc.userId != userId || svc.grantedBy != adminId {
+		t.Fatalf("request not forwarded: %+v", svc)
+	}
+}
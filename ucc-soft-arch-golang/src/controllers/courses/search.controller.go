@@ -0,0 +1,51 @@
+package courses
+
+import (
+	"strconv"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/gin-gonic/gin"
+)
+
+type SearchController struct {
+	service services.ISearchService
+}
+
+func NewSearchController(service services.ISearchService) *SearchController {
+	return &SearchController{service: service}
+}
+
+// Search accepts ?q= with optional ?page= and ?limit=.
+func (s *SearchController) Search(g *gin.Context) {
+	page, _ := strconv.Atoi(g.Query("page"))
+	limit, _ := strconv.Atoi(g.Query("limit"))
+
+	response, err := s.service.SearchCourses(g.Query("q"), page, limit)
+	if err != nil {
+		g.Error(err)
+		return
+	}
+	g.JSON(200, gin.H{
+		"ok":      true,
+		"results": response.Results,
+		"total":   response.Total,
+		"page":    response.Page,
+		"limit":   response.Limit,
+	})
+}
+
+// Suggest accepts ?q= with an optional ?limit=.
+func (s *SearchController) Suggest(g *gin.Context) {
+	limit, _ := strconv.Atoi(g.Query("limit"))
+
+	response, err := s.service.Suggest(g.Query("q"), limit)
+	if err != nil {
+		g.Error(err)
+		return
+	}
+	g.JSON(200, gin.H{
+		"ok":         true,
+		"courses":    response.Courses,
+		"categories": response.Categories,
+	})
+}
@@ -0,0 +1,76 @@
+package courses
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	domain "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/courses"
+	middlewares "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+type stubSearchService struct {
+	text        string
+	page, limit int
+}
+
+func (s *stubSearchService) SearchCourses(text string, page int, limit int) (domain.SearchResultsDto, error) {
+	s.text, s.page, s.limit = text, page, limit
+	return domain.SearchResultsDto{
+		Results: []domain.SearchHitDto{{Course: domain.GetCourseDto{CourseName: "Golang"}, Score: 0.9,
+			Highlights: domain.SearchHighlightsDto{CourseName: "<mark>Golang</mark>"}}},
+		Total: 1, Page: page, Limit: limit,
+	}, nil
+}
+
+func (s *stubSearchService) Suggest(prefix string, limit int) (domain.SuggestionsDto, error) {
+	s.text, s.limit = prefix, limit
+	return domain.SuggestionsDto{
+		Courses:    []domain.SuggestionDto{{Text: "Golang"}},
+		Categories: []domain.SuggestionDto{},
+	}, nil
+}
+
+func TestSearchController_Search(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubSearchService{}
+	ctrl := NewSearchController(svc)
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	r.GET("/courses/search", ctrl.Search)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/courses/search?q=golang&page=2&limit=5", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if svc.text != "golang" || svc.page != 2 || svc.limit != 5 {
+		t.Fatalf("query not forwarded: %+v", svc)
+	}
+	if !strings.Contains(w.Body.String(), `"score":0.9`) || !strings.Contains(w.Body.String(), `\u003cmark\u003eGolang`) {
+		t.Fatalf("unexpected body: %s", w.Body.String())
+	}
+}
+
+func TestSearchController_Suggest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubSearchService{}
+	ctrl := NewSearchController(svc)
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	r.GET("/courses/suggest", ctrl.Suggest)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/courses/suggest?q=go&limit=3", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if svc.text != "go" || svc.limit != 3 {
+		t.Fatalf("query not forwarded: %+v", svc)
+	}
+	if !strings.Contains(w.Body.String(), `"text":"Golang"`) || !strings.Contains(w.Body.String(), `"categories":[]`) {
+		t.Fatalf("unexpected body: %s", w.Body.String())
+	}
+}
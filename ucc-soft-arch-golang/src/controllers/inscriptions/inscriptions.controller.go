@@ -5,6 +5,7 @@ import (
 
 	dto "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/inscription"
 	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
 
 	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
 	"github.com/gin-gonic/gin"
@@ -86,6 +87,10 @@ func (c *InscriptionController) Create(g *gin.Context) {
 	// Asignar valores a enrollDto
 	enrollDto.UserId = uid
 	enrollDto.CourseId = cid
+	if cohortID, ok := g.Get("cohortID"); ok {
+		cohortId := cohortID.(uuid.UUID)
+		enrollDto.CohortId = &cohortId
+	}
 
 	// Llamar al servicio de inscripción
 	response, err := c.InscriptionService.Enroll(enrollDto)
@@ -94,6 +99,15 @@ func (c *InscriptionController) Create(g *gin.Context) {
 		return
 	}
 
+	// Un curso completo deja al usuario en la lista de espera
+	if response.Status == model.EnrollmentWaitlisted {
+		g.JSON(http.StatusAccepted, gin.H{
+			"response": response,
+			"message":  "El curso está completo, el usuario quedó en la lista de espera",
+		})
+		return
+	}
+
 	// Responder con éxito
 	g.JSON(http.StatusCreated, gin.H{
 		"response": response,
@@ -101,6 +115,24 @@ func (c *InscriptionController) Create(g *gin.Context) {
 	})
 }
 
+// Unenroll takes the logged in user out of the course or its waitlist.
+func (c *InscriptionController) Unenroll(g *gin.Context) {
+	courseId, err := uuid.Parse(g.Param("cid"))
+	if err != nil {
+		g.Error(customError.NewError("INVALID_UUID", "Invalid UUID", http.StatusBadRequest))
+		return
+	}
+	userID, _ := g.Get("userID")
+	if err := c.InscriptionService.Unenroll(userID.(uuid.UUID), courseId); err != nil {
+		g.Error(err)
+		return
+	}
+	g.JSON(200, gin.H{
+		"ok":      true,
+		"message": "Unenrolled",
+	})
+}
+
 func (c *InscriptionController) GetMyCourses(g *gin.Context) {
 	userID, _ := g.Get("userID")
 	id := userID.(uuid.UUID)
@@ -131,13 +163,19 @@ func (c *InscriptionController) GetMyStudents(g *gin.Context) {
 }
 
 func (c *InscriptionController) IsAlredyEnrolled(g *gin.Context) {
-	cid := g.Param("cid")
-	course_id := parseUUID(cid)
-	uid, _ := g.Get("userID")
-	user_id := parseUUID(uid)
-	flag, _ := c.InscriptionService.IsUserEnrolled(user_id, course_id)
+	courseId, err := uuid.Parse(g.Param("cid"))
+	if err != nil {
+		g.Error(customError.NewError("INVALID_UUID", "Invalid UUID", http.StatusBadRequest))
+		return
+	}
+	flag, err := c.InscriptionService.IsUserEnrolled(g.MustGet("userID").(uuid.UUID), courseId)
+	if err != nil {
+		g.Error(err)
+		return
+	}
 	if flag {
 		g.Error(customError.NewError("USER_ALREADY_ENROLLED", "User is already enrolled", http.StatusBadRequest))
+		return
 	}
 	g.JSON(200, gin.H{"message": "User is not enrolled"})
 }
@@ -145,12 +183,3 @@ func (c *InscriptionController) CourseExist(course_id uuid.UUID) bool {
 	flag, _ := c.InscriptionService.CourseExist(course_id)
 	return flag
 }
-
-// FUNCION PARA PARSEAR UUID
-func parseUUID(value interface{}) uuid.UUID {
-	if value != nil {
-		id, _ := uuid.Parse(value.(string))
-		return id
-	}
-	return uuid.Nil
-}
@@ -3,19 +3,22 @@ package inscriptions
 import (
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	courseDto "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/courses"
 	inDto "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/inscription"
 	middlewares "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
 type stubInscriptionService struct {
+	enrolled       inDto.EnrollRequestResponseDto
 	enrollResp     inDto.EnrollRequestResponseDto
 	enrollErr      error
-	myCourses      courseDto.GetAllCourses
+	myCourses      inDto.EnrolledCourses
 	myCoursesErr   error
 	students       inDto.StudentsInCourse
 	studentsErr    error
@@ -23,12 +26,19 @@ type stubInscriptionService struct {
 	isEnrolledErr  error
 	courseExist    bool
 	courseExistErr error
+	unenrolled     uuid.UUID
+	unenrollErr    error
 }
 
 func (s *stubInscriptionService) Enroll(d inDto.EnrollRequestResponseDto) (inDto.EnrollRequestResponseDto, error) {
+	s.enrolled = d
 	return s.enrollResp, s.enrollErr
 }
-func (s *stubInscriptionService) GetMyCourses(id uuid.UUID) (courseDto.GetAllCourses, error) {
+func (s *stubInscriptionService) Unenroll(u, c uuid.UUID) error {
+	s.unenrolled = c
+	return s.unenrollErr
+}
+func (s *stubInscriptionService) GetMyCourses(id uuid.UUID) (inDto.EnrolledCourses, error) {
 	return s.myCourses, s.myCoursesErr
 }
 func (s *stubInscriptionService) GetMyStudents(id uuid.UUID) (inDto.StudentsInCourse, error) {
@@ -76,9 +86,65 @@ func TestInscriptionController_Create_Success(t *testing.T) {
 	}
 }
 
+func TestInscriptionController_Create_Waitlisted(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubInscriptionService{enrollResp: inDto.EnrollRequestResponseDto{Status: model.EnrollmentWaitlisted, WaitlistPosition: 2}}
+	ctrl := NewInscriptionController(svc)
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	r.POST("/inscriptions", func(c *gin.Context) { c.Set("userID", uuid.New()); c.Set("courseID", uuid.NewString()); ctrl.Create(c) })
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/inscriptions", nil))
+	if w.Code != http.StatusAccepted || !strings.Contains(w.Body.String(), `"waitlist_position":2`) {
+		t.Fatalf("expected 202 with the waitlist position, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestInscriptionController_Create_Cohort(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubInscriptionService{}
+	ctrl := NewInscriptionController(svc)
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	cohortId := uuid.New()
+	r.POST("/inscriptions", func(c *gin.Context) {
+		c.Set("userID", uuid.New())
+		c.Set("courseID", uuid.NewString())
+		c.Set("cohortID", cohortId)
+		ctrl.Create(c)
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/inscriptions", nil))
+	if w.Code != http.StatusCreated || svc.enrolled.CohortId == nil || *svc.enrolled.CohortId != cohortId {
+		t.Fatalf("cohort not forwarded: %d %+v", w.Code, svc.enrolled)
+	}
+}
+
+func TestInscriptionController_Unenroll(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubInscriptionService{}
+	ctrl := NewInscriptionController(svc)
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	r.DELETE("/enroll/:cid", func(c *gin.Context) { c.Set("userID", uuid.New()); ctrl.Unenroll(c) })
+	courseId := uuid.New()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/enroll/"+courseId.String(), nil))
+	if w.Code != http.StatusOK || svc.unenrolled != courseId {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/enroll/nope", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
+
 func TestInscriptionController_GetMyCourses_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
-	svc := &stubInscriptionService{myCourses: courseDto.GetAllCourses{}}
+	svc := &stubInscriptionService{myCourses: inDto.EnrolledCourses{{GetCourseDto: courseDto.GetCourseDto{CourseName: "Go"}, Progress: 40}}}
 	ctrl := NewInscriptionController(svc)
 	r := gin.New()
 	r.Use(middlewares.ErrorHandler())
@@ -88,6 +154,10 @@ func TestInscriptionController_GetMyCourses_Success(t *testing.T) {
 	if w.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d", w.Code)
 	}
+	// the course fields stay at the top level next to the progress
+	if !strings.Contains(w.Body.String(), `"course_name":"Go"`) || !strings.Contains(w.Body.String(), `"progress":40`) {
+		t.Fatalf("unexpected body: %s", w.Body.String())
+	}
 }
 
 func TestInscriptionController_GetMyStudents_InvalidUUID(t *testing.T) {
@@ -125,14 +195,14 @@ func TestInscriptionController_IsAlreadyEnrolled_True(t *testing.T) {
 	r := gin.New()
 	r.Use(middlewares.ErrorHandler())
 	r.GET("/enrolled/:cid", func(c *gin.Context) {
-		c.Set("userID", uuid.New().String())
+		c.Set("userID", uuid.New())
 		c.Params = append(c.Params, gin.Param{Key: "cid", Value: uuid.New().String()})
 		ctrl.IsAlredyEnrolled(c)
 	})
 	w := httptest.NewRecorder()
 	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/enrolled/"+uuid.New().String(), nil))
-	if w.Code != http.StatusOK {
-		t.Fatalf("expected 200 (controller continues after error), got %d", w.Code)
+	if w.Code != http.StatusBadRequest || strings.Contains(w.Body.String(), "not enrolled") {
+		t.Fatalf("expected only the 400, got %d: %s", w.Code, w.Body.String())
 	}
 }
 
@@ -143,7 +213,7 @@ func TestInscriptionController_IsAlreadyEnrolled_False(t *testing.T) {
 	r := gin.New()
 	r.Use(middlewares.ErrorHandler())
 	r.GET("/enrolled/:cid", func(c *gin.Context) {
-		c.Set("userID", uuid.New().String())
+		c.Set("userID", uuid.New())
 		c.Params = append(c.Params, gin.Param{Key: "cid", Value: uuid.New().String()})
 		ctrl.IsAlredyEnrolled(c)
 	})
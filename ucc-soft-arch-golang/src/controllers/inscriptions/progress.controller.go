@@ -0,0 +1,106 @@
+package inscriptions
+
+import (
+	"net/http"
+
+	dto "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/inscription"
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ProgressController struct {
+	service services.IProgressService
+}
+
+func NewProgressController(service services.IProgressService) *ProgressController {
+	return &ProgressController{service: service}
+}
+
+// RecordProgress saves where the logged in user is in a lesson.
+func (c *ProgressController) RecordProgress(g *gin.Context) {
+	courseId, err := uuid.Parse(g.Param("id"))
+	if err != nil {
+		g.Error(customError.NewError("INVALID_UUID", "Invalid UUID", http.StatusBadRequest))
+		return
+	}
+	lessonId, err := uuid.Parse(g.Param("lid"))
+	if err != nil {
+		g.Error(customError.NewError("INVALID_UUID", "Invalid UUID", http.StatusBadRequest))
+		return
+	}
+	var request dto.LessonProgressRequestDto
+	if err := g.ShouldBindJSON(&request); err != nil {
+		g.Error(customError.NewError("INVALID_INPUTS", "Invalid fields", http.StatusBadRequest))
+		return
+	}
+	userID, _ := g.Get("userID")
+	response, err := c.service.RecordProgress(userID.(uuid.UUID), courseId, lessonId, request)
+	if err != nil {
+		g.Error(err)
+		return
+	}
+	g.JSON(200, gin.H{
+		"ok":       true,
+		"progress": response,
+	})
+}
+
+// GetProgress returns the progress of the logged in user in a course.
+func (c *ProgressController) GetProgress(g *gin.Context) {
+	courseId, err := uuid.Parse(g.Param("id"))
+	if err != nil {
+		g.Error(customError.NewError("INVALID_UUID", "Invalid UUID", http.StatusBadRequest))
+		return
+	}
+	userID, _ := g.Get("userID")
+	response, err := c.service.GetProgress(userID.(uuid.UUID), courseId)
+	if err != nil {
+		g.Error(err)
+		return
+	}
+	g.JSON(200, gin.H{
+		"ok":       true,
+		"progress": response,
+	})
+}
+
+func (c *ProgressController) GetCompletionRules(g *gin.Context) {
+	courseId, err := uuid.Parse(g.Param("id"))
+	if err != nil {
+		g.Error(customError.NewError("INVALID_UUID", "Invalid UUID", http.StatusBadRequest))
+		return
+	}
+	response, err := c.service.GetCompletionRules(courseId)
+	if err != nil {
+		g.Error(err)
+		return
+	}
+	g.JSON(200, gin.H{
+		"ok":    true,
+		"rules": response,
+	})
+}
+
+func (c *ProgressController) UpdateCompletionRules(g *gin.Context) {
+	courseId, err := uuid.Parse(g.Param("id"))
+	if err != nil {
+		g.Error(customError.NewError("INVALID_UUID", "Invalid UUID", http.StatusBadRequest))
+		return
+	}
+	var request dto.CompletionRulesDto
+	if err := g.ShouldBindJSON(&request); err != nil {
+		g.Error(customError.NewError("INVALID_INPUTS", "Invalid fields", http.StatusBadRequest))
+		return
+	}
+	response, err := c.service.UpdateCompletionRules(courseId, request)
+	if err != nil {
+		g.Error(err)
+		return
+	}
+	g.JSON(200, gin.H{
+		"ok":    true,
+		"rules": response,
+	})
+}
@@ -0,0 +1,99 @@
+package inscriptions
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	inDto "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/inscription"
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	middlewares "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type stubProgressService struct {
+	recorded inDto.LessonProgressRequestDto
+	progress inDto.CourseProgressDto
+	rules    inDto.CompletionRulesDto
+	err      error
+}
+
+func (s *stubProgressService) RecordProgress(userId, courseId, lessonId uuid.UUID, request inDto.LessonProgressRequestDto) (inDto.CourseProgressDto, error) {
+	s.recorded = request
+	return s.progress, s.err
+}
+func (s *stubProgressService) GetProgress(userId, courseId uuid.UUID) (inDto.CourseProgressDto, error) {
+	return s.progress, s.err
+}
+func (s *stubProgressService) GetCompletionRules(courseId uuid.UUID) (inDto.CompletionRulesDto, error) {
+	return s.rules, s.err
+}
+func (s *stubProgressService) UpdateCompletionRules(courseId uuid.UUID, rules inDto.CompletionRulesDto) (inDto.CompletionRulesDto, error) {
+	s.rules = rules
+	return rules, s.err
+}
+
+func progressRouter(svc *stubProgressService) *gin.Engine {
+	ctrl := NewProgressController(svc)
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	r.Use(func(c *gin.Context) { c.Set("userID", uuid.New()) })
+	r.GET("/courses/:id/progress", ctrl.GetProgress)
+	r.PUT("/courses/:id/lessons/:lid/progress", ctrl.RecordProgress)
+	r.GET("/courses/:id/completion-rules", ctrl.GetCompletionRules)
+	r.PUT("/courses/:id/completion-rules", ctrl.UpdateCompletionRules)
+	return r
+}
+
+func TestProgressController_RecordProgress(t *testing.T) {
+	svc := &stubProgressService{progress: inDto.CourseProgressDto{Progress: 50}}
+	r := progressRouter(svc)
+	w := httptest.NewRecorder()
+	url := "/courses/" + uuid.NewString() + "/lessons/" + uuid.NewString() + "/progress"
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, url, strings.NewReader(`{"position":90,"completed":true}`)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if svc.recorded.Position != 90 || !svc.recorded.Completed {
+		t.Fatalf("unexpected request: %+v", svc.recorded)
+	}
+	if !strings.Contains(w.Body.String(), `"progress":50`) {
+		t.Fatalf("unexpected body: %s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/courses/bad/lessons/"+uuid.NewString()+"/progress", strings.NewReader(`{}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestProgressController_GetProgress_NotEnrolled(t *testing.T) {
+	svc := &stubProgressService{err: customError.NewError("NOT_ENROLLED", "You have to be enrolled in this course", http.StatusForbidden)}
+	w := httptest.NewRecorder()
+	progressRouter(svc).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/courses/"+uuid.NewString()+"/progress", nil))
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", w.Code)
+	}
+}
+
+func TestProgressController_CompletionRules(t *testing.T) {
+	svc := &stubProgressService{}
+	r := progressRouter(svc)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/courses/"+uuid.NewString()+"/completion-rules", strings.NewReader(`{"min_percent":80}`)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if svc.rules.MinPercent != 80 {
+		t.Fatalf("unexpected rules: %+v", svc.rules)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/courses/"+uuid.NewString()+"/completion-rules", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"min_percent":80`) {
+		t.Fatalf("unexpected response %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,155 @@
+package users
+
+import (
+	"fmt"
+	"net/http"
+
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// PrivacyController serves the data export and account deletion of the
+// signed in user (/users/me) and of any user for admins (/admin/users/:id).
+type PrivacyController struct {
+	service services.IPrivacyService
+}
+
+type IPrivacyController interface {
+	ExportMe(c *gin.Context)
+	DeleteMe(c *gin.Context)
+	CancelMyDeletion(c *gin.Context)
+	ExportUser(c *gin.Context)
+	DeleteUser(c *gin.Context)
+	CancelUserDeletion(c *gin.Context)
+}
+
+func NewPrivacyController(service services.IPrivacyService) *PrivacyController {
+	return &PrivacyController{service: service}
+}
+
+// ExportMe returns the export as JSON, or as a zip with ?format=zip.
+func (p *PrivacyController) ExportMe(c *gin.Context) {
+	userId, _ := c.Get("userID")
+	p.export(c, userId.(uuid.UUID))
+}
+
+func (p *PrivacyController) DeleteMe(c *gin.Context) {
+	userId, _ := c.Get("userID")
+	p.scheduleDeletion(c, userId.(uuid.UUID))
+}
+
+func (p *PrivacyController) CancelMyDeletion(c *gin.Context) {
+	userId, _ := c.Get("userID")
+	p.cancelDeletion(c, userId.(uuid.UUID))
+}
+
+func (p *PrivacyController) ExportUser(c *gin.Context) {
+	userId, ok := userIdParam(c)
+	if !ok {
+		return
+	}
+	p.export(c, userId)
+}
+
+// DeleteUser schedules the deletion like DeleteMe; ?immediate=true skips
+// the grace period.
+func (p *PrivacyController) DeleteUser(c *gin.Context) {
+	userId, ok := userIdParam(c)
+	if !ok {
+		return
+	}
+	if c.Query("immediate") != "true" {
+		p.scheduleDeletion(c, userId)
+		return
+	}
+	adminId, _ := c.Get("userID")
+
+	if err := p.service.Erase(userId, adminId.(uuid.UUID)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"ok":      true,
+		"message": "Account erased",
+	})
+}
+
+func (p *PrivacyController) CancelUserDeletion(c *gin.Context) {
+	userId, ok := userIdParam(c)
+	if !ok {
+		return
+	}
+	p.cancelDeletion(c, userId)
+}
+
+func (p *PrivacyController) export(c *gin.Context, userId uuid.UUID) {
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "zip" {
+		c.Error(customError.NewError("INVALID_FORMAT", "format must be json or zip", http.StatusBadRequest))
+		return
+	}
+	actorId, _ := c.Get("userID")
+
+	export, err := p.service.Export(userId, actorId.(uuid.UUID))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if format == "json" {
+		c.JSON(200, gin.H{
+			"ok":   true,
+			"data": export,
+		})
+		return
+	}
+	archive, err := services.ExportArchive(export)
+	if err != nil {
+		c.Error(customError.NewError("UNEXPECTED_ERROR", "Could not build the export", http.StatusInternalServerError))
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="export-%s.zip"`, userId))
+	c.Data(http.StatusOK, "application/zip", archive)
+}
+
+func (p *PrivacyController) scheduleDeletion(c *gin.Context, userId uuid.UUID) {
+	actorId, _ := c.Get("userID")
+
+	deletion, err := p.service.ScheduleDeletion(userId, actorId.(uuid.UUID))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"ok":       true,
+		"message":  "Account deletion scheduled, it can be canceled until then",
+		"deletion": deletion,
+	})
+}
+
+func (p *PrivacyController) cancelDeletion(c *gin.Context, userId uuid.UUID) {
+	actorId, _ := c.Get("userID")
+
+	if err := p.service.CancelDeletion(userId, actorId.(uuid.UUID)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"ok":      true,
+		"message": "Account deletion canceled",
+	})
+}
+
+func userIdParam(c *gin.Context) (uuid.UUID, bool) {
+	userId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(customError.NewError("INVALID_UUID", "Invalid UUID", http.StatusBadRequest))
+		return uuid.Nil, false
+	}
+	return userId, true
+}
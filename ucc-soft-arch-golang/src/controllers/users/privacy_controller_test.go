@@ -0,0 +1,134 @@
+package users
+
+import (
+	"archive/zip"
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	userDtos "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/users"
+	middlewares "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type stubPrivacyService struct {
+	services.IPrivacyService
+	target, actor uuid.UUID
+	scheduled     bool
+	erased        bool
+	canceled      bool
+}
+
+func (s *stubPrivacyService) Export(userId uuid.UUID, actorId uuid.UUID) (userDtos.DataExportDto, error) {
+	s.target, s.actor = userId, actorId
+	return userDtos.DataExportDto{Profile: userDtos.DataExportProfileDto{Id: userId, Email: "me@test.com"}}, nil
+}
+
+func (s *stubPrivacyService) ScheduleDeletion(userId uuid.UUID, actorId uuid.UUID) (userDtos.AccountDeletionDto, error) {
+	s.target, s.actor, s.scheduled = userId, actorId, true
+	return userDtos.AccountDeletionDto{ScheduledAt: time.Now()}, nil
+}
+
+func (s *stubPrivacyService) CancelDeletion(userId uuid.UUID, actorId uuid.UUID) error {
+	s.target, s.actor, s.canceled = userId, actorId, true
+	return nil
+}
+
+func (s *stubPrivacyService) Erase(userId uuid.UUID, actorId uuid.UUID) error {
+	s.target, s.actor, s.erased = userId, actorId, true
+	return nil
+}
+
+func makePrivacyRouter(service *stubPrivacyService, userId uuid.UUID) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	ctrl := NewPrivacyController(service)
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	r.Use(func(c *gin.Context) { c.Set("userID", userId); c.Next() })
+	r.GET("/users/me/export", ctrl.ExportMe)
+	r.DELETE("/users/me", ctrl.DeleteMe)
+	r.POST("/users/me/deletion/cancel", ctrl.CancelMyDeletion)
+	r.GET("/admin/users/:id/export", ctrl.ExportUser)
+	r.DELETE("/admin/users/:id", ctrl.DeleteUser)
+	return r
+}
+
+func TestPrivacyController_ExportMe(t *testing.T) {
+	service := &stubPrivacyService{}
+	userId := uuid.New()
+	r := makePrivacyRouter(service, userId)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/me/export", nil))
+	if w.Code != http.StatusOK || service.target != userId || !strings.Contains(w.Body.String(), "me@test.com") {
+		t.Fatalf("unexpected JSON export: %d %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/me/export?format=zip", nil))
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/zip" {
+		t.Fatalf("expected a zip, got %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+	if _, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len())); err != nil {
+		t.Fatalf("invalid zip: %v", err)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/me/export?format=xml", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestPrivacyController_DeleteMe(t *testing.T) {
+	service := &stubPrivacyService{}
+	userId := uuid.New()
+	r := makePrivacyRouter(service, userId)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/users/me", nil))
+	if w.Code != http.StatusAccepted || !service.scheduled || service.target != userId {
+		t.Fatalf("expected deletion to be scheduled, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/users/me/deletion/cancel", nil))
+	if w.Code != http.StatusOK || !service.canceled {
+		t.Fatalf("expected deletion to be canceled, got %d", w.Code)
+	}
+}
+
+func TestPrivacyController_AdminVariants(t *testing.T) {
+	service := &stubPrivacyService{}
+	adminId, userId := uuid.New(), uuid.New()
+	r := makePrivacyRouter(service, adminId)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/users/"+userId.String()+"/export", nil))
+	if w.Code != http.StatusOK || service.target != userId || service.actor != adminId {
+		t.Fatalf("expected export of %s by %s, got %d", userId, adminId, w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/admin/users/"+userId.String(), nil))
+	if w.Code != http.StatusAccepted || !service.scheduled || service.erased {
+		t.Fatalf("expected a scheduled deletion, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/admin/users/"+userId.String()+"?immediate=true", nil))
+	if w.Code != http.StatusOK || !service.erased {
+		t.Fatalf("expected the account to be erased, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/admin/users/not-a-uuid", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
@@ -8,17 +8,18 @@ import (
 
 	"github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/users"
 	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
-	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/jwt"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
 type UsersController struct {
-	service services.IUserService
+	service      services.IUserService
+	tokenService services.ITokenService
+	verification services.IEmailVerificationService
 }
 
-func NewUserController(service services.IUserService) *UsersController {
-	return &UsersController{service: service}
+func NewUserController(service services.IUserService, tokenService services.ITokenService, verification services.IEmailVerificationService) *UsersController {
+	return &UsersController{service: service, tokenService: tokenService, verification: verification}
 }
 func (u *UsersController) FindByEmail(g *gin.Context) {
 	email, exists := g.Get("email")
@@ -49,19 +50,28 @@ func (u *UsersController) CreateUser(g *gin.Context) {
 	user.Email = userEmail.(string)
 	user.Username = userName.(string)
 	user.Password = userPassword.(string)
-	fmt.Println("UserRequest: ", user)
 
 	response, err := u.service.CreateUser(user)
 	if err != nil {
 		g.Error(err)
 		return
 	}
-	token := jwt.SignDocument(response.Id, response.Role)
+	// the account exists already, a failed email can be sent again from /auth/verify-email/resend
+	if err := u.verification.SendVerification(response.Id); err != nil {
+		fmt.Println("Error sending verification email: ", err)
+	}
+	tokens, err := u.tokenService.IssueTokens(response.Id, response.Role, uuid.Nil, users.ClientInfoDto{Ip: g.ClientIP(), UserAgent: g.Request.UserAgent()})
+	if err != nil {
+		g.Error(err)
+		return
+	}
 	g.JSON(201, gin.H{
-		"ok":      true,
-		"message": "User created successfully",
-		"user":    response,
-		"token":   token,
+		"ok":            true,
+		"message":       "User created successfully",
+		"user":          response,
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
 	})
 }
 
@@ -69,20 +79,26 @@ func (u *UsersController) UpdateUser(g *gin.Context) {
 	var user users.UpdateRequestDto
 	userID, _ := g.Get("userID")
 
-	user.Id = userID.(uuid.UUID)
-
 	err := g.BindJSON(&user)
 	if err != nil {
 		err := customError.NewError("INVALID_REQUEST", "Invalid request", http.StatusBadRequest)
 		g.Error(err)
 		return
 	}
+	// only the logged in user can be updated, whatever the body says
+	user.Id = userID.(uuid.UUID)
 
 	response, err := u.service.UpdateUser(user)
 	if err != nil {
 		g.Error(err)
 		return
 	}
+	// a new email is verified like the first one; the link can be resent
+	if user.Email != "" && !response.EmailVerified {
+		if err := u.verification.SendVerification(response.Id); err != nil {
+			fmt.Println("Error sending verification email: ", err)
+		}
+	}
 
 	g.JSON(201, gin.H{
 		"ok":      true,
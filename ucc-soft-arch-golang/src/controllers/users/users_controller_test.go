@@ -9,6 +9,8 @@ import (
 
 	userDtos "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/users"
 	middlewares "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/jwt"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -20,6 +22,7 @@ type stubUserService struct {
 	getEmailErr  error
 	updateResp   userDtos.UpdateResponseDto
 	updateErr    error
+	updated      userDtos.UpdateRequestDto
 }
 
 func (s *stubUserService) CreateUser(r userDtos.RegisterRequest) (userDtos.RegisterResponse, error) {
@@ -32,13 +35,51 @@ func (s *stubUserService) GetUserByEmail(email string) (userDtos.GetUserDto, err
 	return s.getEmailResp, s.getEmailErr
 }
 func (s *stubUserService) UpdateUser(r userDtos.UpdateRequestDto) (userDtos.UpdateResponseDto, error) {
+	s.updated = r
 	return s.updateResp, s.updateErr
 }
 
+type stubTokenService struct{}
+
+func (s *stubTokenService) IssueTokens(userId uuid.UUID, role string, familyId uuid.UUID, client userDtos.ClientInfoDto) (userDtos.TokenPairDto, error) {
+	return userDtos.TokenPairDto{AccessToken: "tok", RefreshToken: "ref"}, nil
+}
+func (s *stubTokenService) ConsumeRefreshToken(refreshToken string) (model.RefreshToken, error) {
+	return model.RefreshToken{}, nil
+}
+func (s *stubTokenService) RevokeRefreshToken(userId uuid.UUID, refreshToken string) error {
+	return nil
+}
+func (s *stubTokenService) VerifyAccessToken(token string) (*jwt.CustomClaims, error) {
+	return nil, nil
+}
+func (s *stubTokenService) VerifyAPIKey(key string) (*jwt.CustomClaims, error) {
+	return nil, nil
+}
+func (s *stubTokenService) AuditImpersonation(claims *jwt.CustomClaims, request userDtos.RequestInfoDto) error {
+	return nil
+}
+
+type stubEmailVerificationService struct {
+	sentTo uuid.UUID
+}
+
+func (s *stubEmailVerificationService) SendVerification(userId uuid.UUID) error {
+	s.sentTo = userId
+	return nil
+}
+func (s *stubEmailVerificationService) Resend(email string) error {
+	return nil
+}
+func (s *stubEmailVerificationService) Verify(token string) error {
+	return nil
+}
+
 func TestUsersController_CreateUser_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	svc := &stubUserService{createResp: userDtos.RegisterResponse{Id: uuid.New(), Email: "new@ex.com"}}
-	ctrl := NewUserController(svc)
+	verification := &stubEmailVerificationService{}
+	ctrl := NewUserController(svc, &stubTokenService{}, verification)
 	r := gin.New()
 	r.Use(middlewares.ErrorHandler())
 	r.POST("/users", func(c *gin.Context) {
@@ -52,11 +93,14 @@ func TestUsersController_CreateUser_Success(t *testing.T) {
 	if w.Code != http.StatusCreated {
 		t.Fatalf("expected 201, got %d", w.Code)
 	}
+	if verification.sentTo != svc.createResp.Id {
+		t.Fatalf("expected verification email for the new user")
+	}
 }
 
 func TestUsersController_FindByEmail_MissingEmail(t *testing.T) {
 	gin.SetMode(gin.TestMode)
-	ctrl := NewUserController(&stubUserService{})
+	ctrl := NewUserController(&stubUserService{}, &stubTokenService{}, &stubEmailVerificationService{})
 	r := gin.New()
 	r.Use(middlewares.ErrorHandler())
 	r.GET("/users/email", ctrl.FindByEmail)
@@ -70,7 +114,7 @@ func TestUsersController_FindByEmail_MissingEmail(t *testing.T) {
 func TestUsersController_FindByEmail_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	svc := &stubUserService{getEmailResp: userDtos.GetUserDto{Email: "x@ex.com"}}
-	ctrl := NewUserController(svc)
+	ctrl := NewUserController(svc, &stubTokenService{}, &stubEmailVerificationService{})
 	r := gin.New()
 	r.Use(middlewares.ErrorHandler())
 	r.GET("/users/email", func(c *gin.Context) { c.Set("email", "x@ex.com"); ctrl.FindByEmail(c) })
@@ -83,7 +127,7 @@ func TestUsersController_FindByEmail_Success(t *testing.T) {
 
 func TestUsersController_UpdateUser_InvalidBody(t *testing.T) {
 	gin.SetMode(gin.TestMode)
-	ctrl := NewUserController(&stubUserService{})
+	ctrl := NewUserController(&stubUserService{}, &stubTokenService{}, &stubEmailVerificationService{})
 	r := gin.New()
 	r.Use(middlewares.ErrorHandler())
 	r.PUT("/users", func(c *gin.Context) { c.Set("userID", uuid.New()); ctrl.UpdateUser(c) })
@@ -98,8 +142,10 @@ func TestUsersController_UpdateUser_InvalidBody(t *testing.T) {
 
 func TestUsersController_UpdateUser_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
-	svc := &stubUserService{updateResp: userDtos.UpdateResponseDto{Email: "u@ex.com"}}
-	ctrl := NewUserController(svc)
+	id := uuid.New()
+	svc := &stubUserService{updateResp: userDtos.UpdateResponseDto{Id: id, Email: "u@ex.com"}}
+	verification := &stubEmailVerificationService{}
+	ctrl := NewUserController(svc, &stubTokenService{}, verification)
 	r := gin.New()
 	r.Use(middlewares.ErrorHandler())
 	r.PUT("/users", func(c *gin.Context) { c.Set("userID", uuid.New()); ctrl.UpdateUser(c) })
@@ -110,12 +156,36 @@ func TestUsersController_UpdateUser_Success(t *testing.T) {
 	if w.Code != http.StatusCreated {
 		t.Fatalf("expected 201, got %d", w.Code)
 	}
+	// the new email needs verifying
+	if verification.sentTo != id {
+		t.Fatalf("expected a verification email, got %s", verification.sentTo)
+	}
+}
+
+func TestUsersController_UpdateUser_IgnoresBodyId(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubUserService{}
+	ctrl := NewUserController(svc, &stubTokenService{}, &stubEmailVerificationService{})
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	me, victim := uuid.New(), uuid.New()
+	r.PUT("/users", func(c *gin.Context) { c.Set("userID", me); ctrl.UpdateUser(c) })
+	req := httptest.NewRequest(http.MethodPut, "/users", strings.NewReader(`{"id":"`+victim.String()+`","password":"Secret123!"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", w.Code)
+	}
+	if svc.updated.Id != me {
+		t.Fatalf("expected the logged in user to be updated, got %s", svc.updated.Id)
+	}
 }
 
 func TestUsersController_FindByEmail_Error(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	svc := &stubUserService{getEmailErr: errors.New("fail")}
-	ctrl := NewUserController(svc)
+	ctrl := NewUserController(svc, &stubTokenService{}, &stubEmailVerificationService{})
 	r := gin.New()
 	r.Use(middlewares.ErrorHandler())
 	r.GET("/users/email", func(c *gin.Context) { c.Set("email", "x@ex.com"); ctrl.FindByEmail(c) })
@@ -0,0 +1,35 @@
+package apikeys
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreateAPIKeyRequestDto asks for a new key. Scopes are permission names and
+// ExpiresAt is optional; keys without it never expire.
+type CreateAPIKeyRequestDto struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type APIKeyDto struct {
+	Id         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	OwnerId    uuid.UUID  `json:"owner_id"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+}
+
+type APIKeysDto []APIKeyDto
+
+// CreatedAPIKeyDto is the only place the plain key is ever returned.
+type CreatedAPIKeyDto struct {
+	Key    string    `json:"key"`
+	APIKey APIKeyDto `json:"api_key"`
+}
@@ -0,0 +1,68 @@
+package courses
+
+import "github.com/google/uuid"
+
+// CatalogQueryDto holds the GET /courses query. Nil filters are not
+// applied; a cursor takes precedence over page.
+type CatalogQueryDto struct {
+	Filter     string
+	CategoryId *uuid.UUID
+	MinPrice   *float64
+	MaxPrice   *float64
+	MinRating  *float64
+	Status     string
+	// Schedule keeps the courses with an upcoming, ongoing or finished
+	// cohort.
+	Schedule    string
+	MinDuration *int
+	MaxDuration *int
+	Sort        string
+	Cursor      string
+	Page        int
+	Limit       int
+	// IncludeUnpublished lists courses that aren't live too; the controller
+	// sets it for staff only.
+	IncludeUnpublished bool
+	// Facets asks for the facet counts along with the page.
+	Facets bool
+}
+
+type CoursesPageDto struct {
+	Courses GetAllCourses `json:"courses"`
+	Total   int64         `json:"total"`
+	Limit   int           `json:"limit"`
+	// Page is only set for offset pagination.
+	Page       int               `json:"page,omitempty"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+	PrevCursor string            `json:"prev_cursor,omitempty"`
+	Facets     *CatalogFacetsDto `json:"facets,omitempty"`
+}
+
+// CatalogFacetsDto counts the courses matching the query per value of each
+// filter. Every facet ignores its own filter.
+type CatalogFacetsDto struct {
+	Categories []CategoryFacetDto `json:"categories"`
+	Prices     []BucketFacetDto   `json:"prices"`
+	Ratings    []BucketFacetDto   `json:"ratings"`
+	Statuses   []StatusFacetDto   `json:"statuses"`
+}
+
+type CategoryFacetDto struct {
+	CategoryId   uuid.UUID `json:"category_id"`
+	CategoryName string    `json:"category_name"`
+	Count        int64     `json:"count"`
+}
+
+// BucketFacetDto is a range of prices or ratings: min is inclusive, max
+// exclusive and missing for the last bucket.
+type BucketFacetDto struct {
+	Key   string   `json:"key"`
+	Min   float64  `json:"min"`
+	Max   *float64 `json:"max,omitempty"`
+	Count int64    `json:"count"`
+}
+
+type StatusFacetDto struct {
+	Status string `json:"status"`
+	Count  int64  `json:"count"`
+}
@@ -0,0 +1,37 @@
+package courses
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CohortRequestDto creates or replaces a cohort. Times carry their own
+// offset; TimeZone is the IANA zone the cohort is shown in and defaults to
+// UTC. Leaving a bound of the enrollment window out removes it.
+type CohortRequestDto struct {
+	Name               string     `json:"name"`
+	StartsAt           time.Time  `json:"starts_at"`
+	EndsAt             time.Time  `json:"ends_at"`
+	TimeZone           string     `json:"time_zone"`
+	EnrollmentOpensAt  *time.Time `json:"enrollment_opens_at"`
+	EnrollmentClosesAt *time.Time `json:"enrollment_closes_at"`
+	Capacity           int        `json:"capacity"`
+}
+
+// CohortDto is a cohort with its times in its own time zone. Schedule is
+// upcoming, ongoing or finished.
+type CohortDto struct {
+	Id                 uuid.UUID  `json:"id"`
+	CourseId           uuid.UUID  `json:"course_id"`
+	Name               string     `json:"name"`
+	StartsAt           time.Time  `json:"starts_at"`
+	EndsAt             time.Time  `json:"ends_at"`
+	TimeZone           string     `json:"time_zone"`
+	EnrollmentOpensAt  *time.Time `json:"enrollment_opens_at"`
+	EnrollmentClosesAt *time.Time `json:"enrollment_closes_at"`
+	Capacity           int        `json:"capacity"`
+	SeatsTaken         int        `json:"seats_taken"`
+	Schedule           string     `json:"schedule"`
+	EnrollmentOpen     bool       `json:"enrollment_open"`
+}
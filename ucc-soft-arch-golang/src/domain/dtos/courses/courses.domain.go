@@ -10,8 +10,9 @@ type CreateCoursesRequestDto struct {
 	CourseCapacity    int       `json:"capacity"`
 	CategoryID        uuid.UUID `json:"category_id"`
 	CourseInitDate    string    `json:"init_date"`
-	CourseState       bool      `json:"state"`
 	CourseImage       string    `json:"image"`
+	// OwnerId is the logged user, set by the controller.
+	OwnerId uuid.UUID `json:"-"`
 }
 
 type CreateCoursesResponseDto struct {
@@ -31,6 +32,6 @@ type GetOneCourseResponseDto struct {
 	CourseDuration    int       `json:"duration"`
 	CourseCapacity    int       `json:"capacity"`
 	CourseInitDate    string    `json:"init_date"`
-	CourseState       bool      `json:"state"`
+	CourseStatus      string    `json:"status"`
 	CourseImage       string    `json:"image"`
 }
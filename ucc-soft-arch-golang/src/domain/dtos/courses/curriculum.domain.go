@@ -0,0 +1,61 @@
+package courses
+
+import "github.com/google/uuid"
+
+type SectionRequestDto struct {
+	Title string `json:"title"`
+}
+
+// LessonRequestDto creates or replaces a lesson. Text lessons need a
+// markdown body; video, file and link lessons need a url.
+type LessonRequestDto struct {
+	Title    string `json:"title"`
+	Type     string `json:"type"`
+	Body     string `json:"body"`
+	Url      string `json:"url"`
+	Duration int    `json:"duration"`
+}
+
+// LessonSummaryDto is a lesson as the curriculum lists it, without content.
+type LessonSummaryDto struct {
+	Id       uuid.UUID `json:"id"`
+	Title    string    `json:"title"`
+	Type     string    `json:"type"`
+	Position int       `json:"position"`
+	Duration int       `json:"duration"`
+}
+
+// LessonDto is a lesson with its content, only for enrolled users.
+type LessonDto struct {
+	Id        uuid.UUID `json:"id"`
+	SectionId uuid.UUID `json:"section_id"`
+	Title     string    `json:"title"`
+	Type      string    `json:"type"`
+	Position  int       `json:"position"`
+	Duration  int       `json:"duration"`
+	Body      string    `json:"body,omitempty"`
+	Url       string    `json:"url,omitempty"`
+}
+
+type SectionDto struct {
+	Id       uuid.UUID          `json:"id"`
+	Title    string             `json:"title"`
+	Position int                `json:"position"`
+	Lessons  []LessonSummaryDto `json:"lessons"`
+}
+
+type CurriculumDto struct {
+	CourseId uuid.UUID    `json:"course_id"`
+	Sections []SectionDto `json:"sections"`
+}
+
+type SectionOrderDto struct {
+	Id      uuid.UUID   `json:"id"`
+	Lessons []uuid.UUID `json:"lessons"`
+}
+
+// CurriculumOrderDto lists every section of a course with its lessons in
+// their new order.
+type CurriculumOrderDto struct {
+	Sections []SectionOrderDto `json:"sections"`
+}
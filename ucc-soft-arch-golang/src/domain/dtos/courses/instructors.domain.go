@@ -0,0 +1,16 @@
+package courses
+
+import "github.com/google/uuid"
+
+type AddInstructorRequestDto struct {
+	UserId uuid.UUID `json:"user_id"`
+}
+
+type InstructorDto struct {
+	UserId   uuid.UUID `json:"user_id"`
+	UserName string    `json:"username"`
+	Avatar   string    `json:"avatar"`
+	IsOwner  bool      `json:"is_owner"`
+}
+
+type InstructorsDto []InstructorDto
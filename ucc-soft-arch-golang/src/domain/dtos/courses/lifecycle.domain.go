@@ -0,0 +1,25 @@
+package courses
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CourseStatusRequestDto struct {
+	Status string `json:"status" binding:"required"`
+}
+
+// CourseScheduleDto is the window in which a published course is shown.
+// Leaving a bound out removes it.
+type CourseScheduleDto struct {
+	PublishAt   *time.Time `json:"publish_at"`
+	UnpublishAt *time.Time `json:"unpublish_at"`
+}
+
+type CourseLifecycleDto struct {
+	Id          uuid.UUID  `json:"id"`
+	Status      string     `json:"status"`
+	PublishAt   *time.Time `json:"publish_at"`
+	UnpublishAt *time.Time `json:"unpublish_at"`
+}
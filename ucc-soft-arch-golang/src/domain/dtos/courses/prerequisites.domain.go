@@ -0,0 +1,26 @@
+package courses
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PrerequisitesRequestDto replaces the prerequisites of a course.
+type PrerequisitesRequestDto struct {
+	CourseIds []uuid.UUID `json:"course_ids"`
+}
+
+type PrerequisiteDto struct {
+	Id         uuid.UUID `json:"id"`
+	CourseName string    `json:"course_name"`
+}
+
+type PrerequisitesDto []PrerequisiteDto
+
+type PrerequisiteWaiverDto struct {
+	CourseId  uuid.UUID `json:"course_id"`
+	UserId    uuid.UUID `json:"user_id"`
+	GrantedBy uuid.UUID `json:"granted_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
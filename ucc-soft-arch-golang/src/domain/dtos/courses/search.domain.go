@@ -0,0 +1,33 @@
+package courses
+
+import "github.com/google/uuid"
+
+// SearchHitDto is a course found by a search. The highlights are HTML: the
+// text is escaped and the matched words are wrapped in <mark>.
+type SearchHitDto struct {
+	Course     GetCourseDto        `json:"course"`
+	Score      float64             `json:"score"`
+	Highlights SearchHighlightsDto `json:"highlights"`
+}
+
+type SearchHighlightsDto struct {
+	CourseName  string `json:"course_name"`
+	Description string `json:"description"`
+}
+
+type SearchResultsDto struct {
+	Results []SearchHitDto `json:"results"`
+	Total   int64          `json:"total"`
+	Page    int            `json:"page"`
+	Limit   int            `json:"limit"`
+}
+
+type SuggestionDto struct {
+	Id   uuid.UUID `json:"id"`
+	Text string    `json:"text"`
+}
+
+type SuggestionsDto struct {
+	Courses    []SuggestionDto `json:"courses"`
+	Categories []SuggestionDto `json:"categories"`
+}
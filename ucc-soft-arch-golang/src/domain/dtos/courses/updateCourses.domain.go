@@ -11,7 +11,6 @@ type UpdateRequestDto struct {
 	CourseCapacity    *int       `json:"capacity"`
 	CategoryID        *uuid.UUID `json:"category_id"`
 	CourseInitDate    *string    `json:"init_date"`
-	CourseState       *bool      `json:"state"`
 	CourseImage       *string    `json:"image"`
 }
 type UpdateResponseDto struct {
@@ -23,6 +22,6 @@ type UpdateResponseDto struct {
 	CourseCapacity    int       `json:"capacity"`
 	CategoryID        uuid.UUID `json:"category_id"`
 	CourseInitDate    string    `json:"init_date"`
-	CourseState       bool      `json:"state"`
+	CourseStatus      string    `json:"status"`
 	CourseImage       string    `json:"image"`
 }
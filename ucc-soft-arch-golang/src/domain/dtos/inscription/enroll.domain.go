@@ -1,19 +1,47 @@
 package inscription
 
-import "github.com/google/uuid"
+import (
+	"time"
 
+	courses "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/courses"
+	"github.com/google/uuid"
+)
+
+// EnrollRequestResponseDto answers with the enrollment status; a user that
+// didn't get a seat also gets their place on the waitlist.
 type EnrollRequestResponseDto struct {
-	CourseId uuid.UUID `json:"course_id"`
-	UserId   uuid.UUID `json:"user_id"`
+	CourseId         uuid.UUID  `json:"course_id"`
+	CohortId         *uuid.UUID `json:"cohort_id,omitempty"`
+	UserId           uuid.UUID  `json:"user_id"`
+	Status           string     `json:"status,omitempty"`
+	WaitlistPosition int        `json:"waitlist_position,omitempty"`
 }
 type Student struct {
-	UserId   uuid.UUID `json:"user_id"`
-	UserName string    `json:"user_name"`
-	Avatar   string    `json:"avatar"`
+	UserId       uuid.UUID  `json:"user_id"`
+	UserName     string     `json:"user_name"`
+	Avatar       string     `json:"avatar"`
+	Progress     int        `json:"progress"`
+	LastViewedAt *time.Time `json:"last_viewed_at"`
+	CompletedAt  *time.Time `json:"completed_at"`
+}
+
+// EnrolledCourse is a course of /myCourses/ with how far the student got.
+// Progress is the percentage of lessons completed. Courses the student is
+// waiting for come with their place on the waitlist.
+type EnrolledCourse struct {
+	courses.GetCourseDto
+	EnrollmentStatus string     `json:"enrollment_status"`
+	WaitlistPosition int        `json:"waitlist_position,omitempty"`
+	Progress         int        `json:"progress"`
+	LastViewedAt     *time.Time `json:"last_viewed_at"`
+	CompletedAt      *time.Time `json:"completed_at"`
 }
 
+// CourseIdString is the body of /enroll. CohortId is needed for courses
+// with cohorts.
 type CourseIdString struct {
 	CourseId string `json:"course_id"`
+	CohortId string `json:"cohort_id"`
 }
 type MyCourse struct {
 	Id          uuid.UUID `json:"course_id"`
@@ -22,4 +50,5 @@ type MyCourse struct {
 }
 
 type StudentsInCourse []Student
+type EnrolledCourses []EnrolledCourse
 type MyCourses []MyCourse
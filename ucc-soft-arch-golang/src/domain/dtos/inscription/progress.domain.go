@@ -0,0 +1,32 @@
+package inscription
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LessonProgressRequestDto reports where the student is in a lesson and
+// whether they finished it.
+type LessonProgressRequestDto struct {
+	Position  int  `json:"position"`
+	Completed bool `json:"completed"`
+}
+
+type CourseProgressDto struct {
+	CourseId         uuid.UUID   `json:"course_id"`
+	Progress         int         `json:"progress"`
+	TotalLessons     int         `json:"total_lessons"`
+	CompletedLessons []uuid.UUID `json:"completed_lessons"`
+	LastLessonId     *uuid.UUID  `json:"last_lesson_id"`
+	LastPosition     int         `json:"last_position"`
+	LastViewedAt     *time.Time  `json:"last_viewed_at"`
+	CompletedAt      *time.Time  `json:"completed_at"`
+}
+
+// CompletionRulesDto is when an enrollment counts as completed: at least
+// min_percent of the lessons done, required_lessons among them.
+type CompletionRulesDto struct {
+	MinPercent      int         `json:"min_percent"`
+	RequiredLessons []uuid.UUID `json:"required_lessons"`
+}
@@ -0,0 +1,39 @@
+package users
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AdminUserDto is what admins see of an account, including its status.
+type AdminUserDto struct {
+	Id                    uuid.UUID  `json:"id"`
+	Email                 string     `json:"email"`
+	Role                  string     `json:"role"`
+	UserName              string     `json:"username"`
+	Avatar                string     `json:"avatar"`
+	EmailVerified         bool       `json:"email_verified"`
+	TwoFactorRequired     bool       `json:"two_factor_required"`
+	Suspended             bool       `json:"suspended"`
+	SuspendedAt           *time.Time `json:"suspended_at,omitempty"`
+	SuspendedReason       string     `json:"suspended_reason,omitempty"`
+	PasswordResetRequired bool       `json:"password_reset_required"`
+	DeletionScheduledAt   *time.Time `json:"deletion_scheduled_at,omitempty"`
+	CreatedAt             time.Time  `json:"created_at"`
+}
+
+type AdminUsersPageDto struct {
+	Users []AdminUserDto `json:"users"`
+	Total int64          `json:"total"`
+	Page  int            `json:"page"`
+	Limit int            `json:"limit"`
+}
+
+type ChangeRoleRequestDto struct {
+	Role string `json:"role"`
+}
+
+type SuspendUserRequestDto struct {
+	Reason string `json:"reason"`
+}
@@ -5,11 +5,33 @@ import "github.com/google/uuid"
 type LoginRequestDto struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
+	// ClientIp and UserAgent are filled by the controller, never read from
+	// the body.
+	ClientIp  string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+// ClientInfoDto describes the device a session was started from.
+type ClientInfoDto struct {
+	Ip        string
+	UserAgent string
 }
 type LoginResponseDto struct {
 	Id       uuid.UUID `json:"id"`
 	Username string    `json:"username"`
 	Email    string    `json:"email"`
 	Avatar   string    `json:"avatar"`
-	Role     int       `json:"role"`
+	Role     string    `json:"role"`
+}
+
+type RefreshTokenRequestDto struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// TokenPairDto is returned on login and refresh. The access token keeps the
+// historical "token" key so existing clients keep working.
+type TokenPairDto struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
 }
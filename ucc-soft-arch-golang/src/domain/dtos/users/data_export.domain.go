@@ -0,0 +1,82 @@
+package users
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DataExportDto bundles everything stored about a user.
+type DataExportDto struct {
+	ExportedAt     time.Time                     `json:"exported_at"`
+	Profile        DataExportProfileDto          `json:"profile"`
+	Enrollments    []DataExportEnrollmentDto     `json:"enrollments"`
+	LessonProgress []DataExportLessonProgressDto `json:"lesson_progress"`
+	Ratings        []DataExportRatingDto         `json:"ratings"`
+	Comments       []DataExportCommentDto        `json:"comments"`
+	Sessions       []DataExportSessionDto        `json:"sessions"`
+}
+
+type DataExportProfileDto struct {
+	Id                  uuid.UUID  `json:"id"`
+	Email               string     `json:"email"`
+	UserName            string     `json:"username"`
+	Avatar              string     `json:"avatar"`
+	Role                string     `json:"role"`
+	EmailVerified       bool       `json:"email_verified"`
+	TwoFactorRequired   bool       `json:"two_factor_required"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
+}
+
+// DataExportEnrollmentDto is an enrollment, active or on the waitlist.
+type DataExportEnrollmentDto struct {
+	CourseId    uuid.UUID  `json:"course_id"`
+	CourseName  string     `json:"course_name"`
+	CohortId    *uuid.UUID `json:"cohort_id,omitempty"`
+	CohortName  string     `json:"cohort_name,omitempty"`
+	Status      string     `json:"status"`
+	EnrolledAt  time.Time  `json:"enrolled_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+type DataExportLessonProgressDto struct {
+	CourseId     uuid.UUID  `json:"course_id"`
+	CourseName   string     `json:"course_name"`
+	LessonId     uuid.UUID  `json:"lesson_id"`
+	Position     int        `json:"position"`
+	LastViewedAt time.Time  `json:"last_viewed_at"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty"`
+}
+
+type DataExportRatingDto struct {
+	CourseId   uuid.UUID `json:"course_id"`
+	CourseName string    `json:"course_name"`
+	Rating     int       `json:"rating"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type DataExportCommentDto struct {
+	CourseId   uuid.UUID `json:"course_id"`
+	CourseName string    `json:"course_name"`
+	Text       string    `json:"text"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type DataExportSessionDto struct {
+	Id             uuid.UUID  `json:"id"`
+	UserAgent      string     `json:"user_agent"`
+	Ip             string     `json:"ip"`
+	CreatedAt      time.Time  `json:"created_at"`
+	LastActivityAt time.Time  `json:"last_activity_at"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	EndedAt        *time.Time `json:"ended_at,omitempty"`
+}
+
+// AccountDeletionDto tells when a scheduled deletion happens.
+type AccountDeletionDto struct {
+	ScheduledAt time.Time `json:"scheduled_at"`
+}
@@ -0,0 +1,5 @@
+package users
+
+type ResendVerificationRequestDto struct {
+	Email string `json:"email"`
+}
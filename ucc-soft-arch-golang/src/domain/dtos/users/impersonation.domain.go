@@ -0,0 +1,28 @@
+package users
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type StartImpersonationRequestDto struct {
+	Reason string `json:"reason"`
+}
+
+// ImpersonationDto carries the token an admin uses to act as the user. It
+// can't be refreshed: a new impersonation is needed once it expires.
+type ImpersonationDto struct {
+	Id          uuid.UUID  `json:"id"`
+	AccessToken string     `json:"token"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	ExpiresIn   int64      `json:"expires_in"`
+	User        GetUserDto `json:"user"`
+}
+
+// RequestInfoDto describes a request for the audit log.
+type RequestInfoDto struct {
+	Method string
+	Path   string
+	Ip     string
+}
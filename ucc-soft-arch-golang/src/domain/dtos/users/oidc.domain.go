@@ -0,0 +1,8 @@
+package users
+
+// OIDCCallbackRequestDto carries what the identity provider appended to the
+// redirect URL; the frontend forwards it as is.
+type OIDCCallbackRequestDto struct {
+	Code  string `json:"code"`
+	State string `json:"state"`
+}
@@ -0,0 +1,10 @@
+package users
+
+type ForgotPasswordRequestDto struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordRequestDto struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
@@ -14,5 +14,5 @@ type RegisterResponse struct {
 	Username string    `json:"username"`
 	Email    string    `json:"email"`
 	Avatar   string    `json:"avatar"`
-	Role     int       `json:"role"`
+	Role     string    `json:"role"`
 }
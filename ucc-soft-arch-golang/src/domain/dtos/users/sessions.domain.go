@@ -0,0 +1,21 @@
+package users
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SessionDto is one signed in device. Current marks the session the request
+// was made from.
+type SessionDto struct {
+	Id             uuid.UUID `json:"id"`
+	UserAgent      string    `json:"user_agent"`
+	Ip             string    `json:"ip"`
+	CreatedAt      time.Time `json:"created_at"`
+	LastActivityAt time.Time `json:"last_activity_at"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	Current        bool      `json:"current"`
+}
+
+type SessionsDto []SessionDto
@@ -0,0 +1,49 @@
+package users
+
+// TwoFactorSetupDto is returned when enrolling; ProvisioningURI is meant to
+// be shown as a QR code.
+type TwoFactorSetupDto struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type TwoFactorCodeRequestDto struct {
+	Code string `json:"code"`
+}
+
+// TwoFactorVerifyRequestDto finishes a two step login with either a TOTP
+// code or a recovery code.
+type TwoFactorVerifyRequestDto struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+	RecoveryCode   string `json:"recovery_code"`
+	// ClientIp and UserAgent are filled by the controller.
+	ClientIp  string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+type TwoFactorSetupRequestDto struct {
+	ChallengeToken string `json:"challenge_token"`
+}
+
+// TwoFactorChallengeDto replaces the session tokens when a second factor is
+// needed. SetupRequired means the user must enroll before verifying.
+type TwoFactorChallengeDto struct {
+	ChallengeToken string `json:"challenge_token"`
+	ExpiresIn      int64  `json:"expires_in"`
+	SetupRequired  bool   `json:"setup_required"`
+}
+
+type TwoFactorRequirementRequestDto struct {
+	Required bool `json:"required"`
+}
+
+// LoginResultDto is the outcome of a login: either a session (User and
+// Tokens) or, when a second factor is needed, only a Challenge.
+type LoginResultDto struct {
+	User      GetUserDto
+	Tokens    TokenPairDto
+	Challenge *TwoFactorChallengeDto
+	// RecoveryCodes is only set when 2FA was enabled during this login.
+	RecoveryCodes []string
+}
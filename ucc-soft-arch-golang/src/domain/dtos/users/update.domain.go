@@ -2,12 +2,15 @@ package users
 
 import "github.com/google/uuid"
 
+// UpdateRequestDto changes the logged in user. Id comes from the token,
+// never from the body. A new Password needs the CurrentPassword.
 type UpdateRequestDto struct {
-	Id       uuid.UUID `json:"id"`
-	Username string    `json:"username"`
-	Email    string    `json:"email"`
-	Password string    `json:"password"`
-	Avatar   string    `json:"avatar"`
+	Id              uuid.UUID `json:"-"`
+	Username        string    `json:"username"`
+	Email           string    `json:"email"`
+	Password        string    `json:"password"`
+	CurrentPassword string    `json:"current_password"`
+	Avatar          string    `json:"avatar"`
 }
 
 type UpdateResponseDto struct {
@@ -15,5 +18,8 @@ type UpdateResponseDto struct {
 	Username string    `json:"username"`
 	Email    string    `json:"email"`
 	Avatar   string    `json:"avatar"`
-	Role     int       `json:"role"`
+	Role     string    `json:"role"`
+	// EmailVerified is false after a change of email until the new one is
+	// verified.
+	EmailVerified bool `json:"email_verified"`
 }
@@ -3,11 +3,12 @@ package users
 import "github.com/google/uuid"
 
 type GetUserDto struct {
-	Id       uuid.UUID `json:"id"`
-	Email    string    `json:"email"`
-	Role     int       `json:"role"`
-	UserName string    `json:"username"`
-	Avatar   string    `json:"avatar"`
+	Id            uuid.UUID `json:"id"`
+	Email         string    `json:"email"`
+	Role          string    `json:"role"`
+	UserName      string    `json:"username"`
+	Avatar        string    `json:"avatar"`
+	EmailVerified bool      `json:"email_verified"`
 }
 
 type GetAllUsersDto []GetUserDto
@@ -0,0 +1,23 @@
+package errors
+
+import (
+	"net/http"
+	"strings"
+)
+
+// DBError is what clients answer with when the database fails: the cause
+// stays in the server, the caller only learns whether to retry.
+func DBError(err error) error {
+	switch {
+	case strings.Contains(err.Error(), "connection"):
+		return NewError(
+			"DB_CONNECTION_ERROR",
+			"Database connection error. Please try again later.",
+			http.StatusInternalServerError)
+	default:
+		return NewError(
+			"UNEXPECTED_ERROR",
+			"An unexpected error occurred. Please try again later.",
+			http.StatusInternalServerError)
+	}
+}
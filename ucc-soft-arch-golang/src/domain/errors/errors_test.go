@@ -1,6 +1,7 @@
 package errors
 
 import (
+	"errors"
 	"net/http"
 	"testing"
 
@@ -14,3 +15,10 @@ func TestNewErrorAndErrorString(t *testing.T) {
 	require.Equal(t, http.StatusNotFound, e.HTTPStatusCode)
 	require.Equal(t, "NOT_FOUND: missing", e.Error())
 }
+
+func TestDBError(t *testing.T) {
+	err := DBError(errors.New("dial tcp: connection refused")).(*Error)
+	require.Equal(t, "DB_CONNECTION_ERROR", err.Code)
+	require.Equal(t, http.StatusInternalServerError, err.HTTPStatusCode)
+	require.Equal(t, "UNEXPECTED_ERROR", DBError(errors.New("syntax error")).(*Error).Code)
+}
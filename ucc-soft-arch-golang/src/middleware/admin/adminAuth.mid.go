@@ -1,63 +0,0 @@
-package admin
-
-import (
-	"fmt"
-	"net/http"
-	"strings"
-
-	"github.com/Guidotss/ucc-soft-arch-golang.git/src/config"
-	utilsJWT "github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/jwt"
-	"github.com/gin-gonic/gin"
-	jwt "github.com/golang-jwt/jwt"
-)
-
-// AuthMiddleware verifica el token JWT y el rol del usuario
-func AdminAuthMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		fmt.Println("auth:", authHeader)
-		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
-			c.Abort()
-			return
-		}
-
-		tokenParts := strings.Split(authHeader, "Bearer ")
-		if len(tokenParts) != 2 {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization token required"})
-			c.Abort()
-			return
-		}
-
-		tokenString := tokenParts[1]
-
-		claims := &utilsJWT.CustomClaims{}
-		envs := config.LoadEnvs(".env")
-		secret := []byte(envs.Get("JWT_SECRET"))
-
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			return secret, nil
-		})
-
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-			c.Abort()
-			return
-		}
-
-		if !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-			c.Abort()
-			return
-		}
-
-		if claims.Role == 0 {
-			c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to access this resource"})
-			c.Abort()
-			return
-		}
-
-		//c.Set("userID", claims.Id)
-		c.Next()
-	}
-}
@@ -0,0 +1,66 @@
+package course
+
+import (
+	"net/http"
+
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/jwt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// IsCourseInstructor lets the request through only if the user teaches the
+// course named by the param route parameter. Roles with courses:manage_all
+// bypass the check. It must run after user.AuthMiddleware.
+func IsCourseInstructor(service services.ICourseService, permissionService services.IPermissionService, param string) gin.HandlerFunc {
+	return requireCourseRole(service.IsInstructor, permissionService, param)
+}
+
+// IsCourseOwner is like IsCourseInstructor but only accepts the course owner.
+func IsCourseOwner(service services.ICourseService, permissionService services.IPermissionService, param string) gin.HandlerFunc {
+	return requireCourseRole(service.IsOwner, permissionService, param)
+}
+
+func requireCourseRole(check func(courseId uuid.UUID, userId uuid.UUID) (bool, error), permissionService services.IPermissionService, param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, exists := c.Get("claims")
+		if !exists {
+			c.Error(customError.NewError("AUTHORIZATION_REQUIRED", "Authorization header is required", http.StatusUnauthorized))
+			c.Abort()
+			return
+		}
+		claims := value.(*jwt.CustomClaims)
+
+		manageAll, err := permissionService.Allows(claims, model.PermissionCoursesManageAll)
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+		if manageAll {
+			c.Next()
+			return
+		}
+
+		courseId, err := uuid.Parse(c.Param(param))
+		if err != nil {
+			c.Error(customError.NewError("INVALID_UUID", "Invalid UUID", http.StatusBadRequest))
+			c.Abort()
+			return
+		}
+		allowed, err := check(courseId, claims.Id)
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+		if !allowed {
+			c.Error(customError.NewError("FORBIDDEN", "You don't have permission to manage this course", http.StatusForbidden))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,75 @@
+package course
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	middlewares "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/jwt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// fakeCourseService only answers the ownership questions.
+type fakeCourseService struct {
+	services.ICourseService
+	instructor uuid.UUID
+	owner      uuid.UUID
+}
+
+func (f fakeCourseService) IsInstructor(_ uuid.UUID, userId uuid.UUID) (bool, error) {
+	return userId == f.instructor || userId == f.owner, nil
+}
+func (f fakeCourseService) IsOwner(_ uuid.UUID, userId uuid.UUID) (bool, error) {
+	return userId == f.owner, nil
+}
+
+type fakePermissionService struct{}
+
+func (fakePermissionService) HasPermission(role string, permission string) (bool, error) {
+	return role == model.RoleAdmin && permission == model.PermissionCoursesManageAll, nil
+}
+func (f fakePermissionService) Allows(claims *jwt.CustomClaims, permission string) (bool, error) {
+	return f.HasPermission(claims.Role, permission)
+}
+func (fakePermissionService) GetRoles() (model.Roles, error)       { return nil, nil }
+func (fakePermissionService) RoleExists(role string) (bool, error) { return true, nil }
+
+func runOwnership(middleware func(services.ICourseService, services.IPermissionService, string) gin.HandlerFunc, service fakeCourseService, userId uuid.UUID, role string) int {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	r.Use(func(c *gin.Context) { c.Set("claims", jwt.NewCustomClaims(userId, role)); c.Next() })
+	r.GET("/courses/:id", middleware(service, fakePermissionService{}, "id"), func(c *gin.Context) { c.Status(http.StatusOK) })
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/courses/"+uuid.New().String(), nil))
+	return w.Code
+}
+
+func TestIsCourseInstructor(t *testing.T) {
+	service := fakeCourseService{instructor: uuid.New(), owner: uuid.New()}
+
+	if code := runOwnership(IsCourseInstructor, service, service.instructor, model.RoleInstructor); code != http.StatusOK {
+		t.Fatalf("expected co-instructor to pass, got %d", code)
+	}
+	if code := runOwnership(IsCourseInstructor, service, uuid.New(), model.RoleInstructor); code != http.StatusForbidden {
+		t.Fatalf("expected other instructor to get 403, got %d", code)
+	}
+	if code := runOwnership(IsCourseInstructor, service, uuid.New(), model.RoleAdmin); code != http.StatusOK {
+		t.Fatalf("expected admin to pass, got %d", code)
+	}
+}
+
+func TestIsCourseOwner(t *testing.T) {
+	service := fakeCourseService{instructor: uuid.New(), owner: uuid.New()}
+
+	if code := runOwnership(IsCourseOwner, service, service.owner, model.RoleInstructor); code != http.StatusOK {
+		t.Fatalf("expected owner to pass, got %d", code)
+	}
+	if code := runOwnership(IsCourseOwner, service, service.instructor, model.RoleInstructor); code != http.StatusForbidden {
+		t.Fatalf("expected co-instructor to get 403, got %d", code)
+	}
+}
@@ -0,0 +1,40 @@
+package course
+
+import (
+	"net/http"
+
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequireVisible answers 404 for the course in param unless it is live or
+// StaffView let the user see it, so the pages hanging from a course don't
+// give away the ones that aren't published. It must run after StaffView.
+func RequireVisible(service services.ICourseService, param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetBool("staffView") {
+			c.Next()
+			return
+		}
+		// a malformed id is left for the handler to reject
+		courseId, err := uuid.Parse(c.Param(param))
+		if err != nil {
+			c.Next()
+			return
+		}
+		live, err := service.IsLive(courseId)
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+		if !live {
+			c.Error(customError.NewError("NOT_FOUND", "Course not found", http.StatusNotFound))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,51 @@
+package course
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	middlewares "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// liveCourseService only finds the live course.
+type liveCourseService struct {
+	fakeCourseService
+	live uuid.UUID
+}
+
+func (f liveCourseService) IsLive(id uuid.UUID) (bool, error) {
+	return id == f.live, nil
+}
+
+func runRequireVisible(service liveCourseService, staffView bool, courseId string) int {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	r.Use(func(c *gin.Context) { c.Set("staffView", staffView); c.Next() })
+	r.GET("/courses/:id/cohorts", RequireVisible(service, "id"), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/courses/"+courseId+"/cohorts", nil))
+	return w.Code
+}
+
+func TestRequireVisible(t *testing.T) {
+	service := liveCourseService{live: uuid.New()}
+
+	if code := runRequireVisible(service, false, service.live.String()); code != http.StatusOK {
+		t.Fatalf("expected live courses to be visible, got %d", code)
+	}
+	if code := runRequireVisible(service, false, uuid.NewString()); code != http.StatusNotFound {
+		t.Fatalf("expected 404 for a course that isn't live, got %d", code)
+	}
+	if code := runRequireVisible(service, true, uuid.NewString()); code != http.StatusOK {
+		t.Fatalf("expected staff to see courses that aren't live, got %d", code)
+	}
+	if code := runRequireVisible(service, false, "not-a-uuid"); code != http.StatusOK {
+		t.Fatalf("expected malformed ids to reach the handler, got %d", code)
+	}
+}
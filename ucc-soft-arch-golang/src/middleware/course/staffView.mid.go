@@ -0,0 +1,53 @@
+package course
+
+import (
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/jwt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// StaffView sets "staffView" when the user may see courses that aren't
+// published: reviewers, roles with courses:manage_all and, when param names
+// a course, its instructors. Everyone else only sees published courses. It
+// must run after user.OptionalAuth.
+func StaffView(service services.ICourseService, permissionService services.IPermissionService, param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, exists := c.Get("claims")
+		if !exists {
+			c.Next()
+			return
+		}
+		claims := value.(*jwt.CustomClaims)
+
+		for _, permission := range []string{model.PermissionCoursesManageAll, model.PermissionCoursesPublish} {
+			allowed, err := permissionService.Allows(claims, permission)
+			if err != nil {
+				c.Error(err)
+				c.Abort()
+				return
+			}
+			if allowed {
+				c.Set("staffView", true)
+				c.Next()
+				return
+			}
+		}
+
+		// an API key only gets what its scopes allow
+		if param != "" && !claims.IsAPIKey() {
+			// a malformed id is left for the handler to reject
+			if courseId, err := uuid.Parse(c.Param(param)); err == nil {
+				teaches, err := service.IsInstructor(courseId, claims.Id)
+				if err != nil {
+					c.Error(err)
+					c.Abort()
+					return
+				}
+				c.Set("staffView", teaches)
+			}
+		}
+		c.Next()
+	}
+}
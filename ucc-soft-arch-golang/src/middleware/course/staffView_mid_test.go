@@ -0,0 +1,50 @@
+package course
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	middlewares "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/jwt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func runStaffView(service fakeCourseService, claims *jwt.CustomClaims, param string) bool {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	if claims != nil {
+		r.Use(func(c *gin.Context) { c.Set("claims", claims); c.Next() })
+	}
+	staffView := false
+	r.GET("/courses/:id", StaffView(service, fakePermissionService{}, param), func(c *gin.Context) {
+		staffView = c.GetBool("staffView")
+		c.Status(http.StatusOK)
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/courses/"+uuid.New().String(), nil))
+	return w.Code == http.StatusOK && staffView
+}
+
+func TestStaffView(t *testing.T) {
+	service := fakeCourseService{instructor: uuid.New(), owner: uuid.New()}
+
+	if runStaffView(service, nil, "id") {
+		t.Fatalf("expected anonymous users to see published courses only")
+	}
+	if runStaffView(service, jwt.NewCustomClaims(uuid.New(), model.RoleStudent), "id") {
+		t.Fatalf("expected students to see published courses only")
+	}
+	if !runStaffView(service, jwt.NewCustomClaims(uuid.New(), model.RoleAdmin), "") {
+		t.Fatalf("expected admins to see every course")
+	}
+	if !runStaffView(service, jwt.NewCustomClaims(service.instructor, model.RoleInstructor), "id") {
+		t.Fatalf("expected instructors to see their course")
+	}
+	if runStaffView(service, jwt.NewCustomClaims(service.instructor, model.RoleInstructor), "") {
+		t.Fatalf("expected instructors to see published courses only in the catalog")
+	}
+}
@@ -1,8 +1,6 @@
 package enroll
 
 import (
-	"fmt"
-
 	dto "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/inscription"
 	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
 	"github.com/gin-gonic/gin"
@@ -14,7 +12,6 @@ func CourseExist(service services.IInscriptionService) gin.HandlerFunc {
 		var courseRequestString dto.CourseIdString
 		err := c.BindJSON(&courseRequestString)
 		if err != nil {
-			fmt.Println("Error al pasar el UUID")
 			c.JSON(400, gin.H{"error": "Invalid request format"})
 			c.Abort()
 			return
@@ -22,23 +19,32 @@ func CourseExist(service services.IInscriptionService) gin.HandlerFunc {
 
 		course_id, err := uuid.Parse(courseRequestString.CourseId)
 		if err != nil {
-			fmt.Println("Error al parsear el UUID")
 			c.JSON(400, gin.H{"error": "Invalid UUID format"})
 			c.Abort()
 			return
 		}
 
 		exist, err := service.CourseExist(course_id)
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
 		if !exist {
 			c.JSON(400, gin.H{"error": "Course doesn't exist"})
 			c.Abort()
 			return
 		}
-		if err != nil {
-			c.Error(err)
+		if courseRequestString.CohortId != "" {
+			cohort_id, err := uuid.Parse(courseRequestString.CohortId)
+			if err != nil {
+				c.JSON(400, gin.H{"error": "Invalid UUID format"})
+				c.Abort()
+				return
+			}
+			c.Set("cohortID", cohort_id)
 		}
 		c.Set("courseID", courseRequestString.CourseId)
-		fmt.Println("Paso el Exist middleware")
 		c.Next()
 	}
 }
@@ -7,8 +7,9 @@ import (
 	"net/http/httptest"
 	"testing"
 
-	courseDto "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/courses"
 	dto "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/inscription"
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	middlewares "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware"
 	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -18,13 +19,17 @@ import (
 // fakeInscriptionService implements services.IInscriptionService for middleware tests
 type fakeInscriptionService struct {
 	courseExists bool
+	courseErr    error
 	isEnrolled   bool
 }
 
 func (f *fakeInscriptionService) Enroll(d dto.EnrollRequestResponseDto) (dto.EnrollRequestResponseDto, error) {
 	return dto.EnrollRequestResponseDto{}, nil
 }
-func (f *fakeInscriptionService) GetMyCourses(id uuid.UUID) (courseDto.GetAllCourses, error) {
+func (f *fakeInscriptionService) Unenroll(userID uuid.UUID, courseID uuid.UUID) error {
+	return nil
+}
+func (f *fakeInscriptionService) GetMyCourses(id uuid.UUID) (dto.EnrolledCourses, error) {
 	return nil, nil
 }
 func (f *fakeInscriptionService) GetMyStudents(id uuid.UUID) (dto.StudentsInCourse, error) {
@@ -34,7 +39,7 @@ func (f *fakeInscriptionService) IsUserEnrolled(userID uuid.UUID, courseID uuid.
 	return f.isEnrolled, nil
 }
 func (f *fakeInscriptionService) CourseExist(course_id uuid.UUID) (bool, error) {
-	return f.courseExists, nil
+	return f.courseExists, f.courseErr
 }
 
 func setupRouter() *gin.Engine {
@@ -81,6 +86,32 @@ func TestCourseExistMiddleware_NotExists(t *testing.T) {
 	require.Equal(t, http.StatusBadRequest, w.Code)
 }
 
+func TestCourseExistMiddleware_LookupFails(t *testing.T) {
+	r := setupRouter()
+	r.Use(middlewares.ErrorHandler())
+	// a failed lookup is not a missing course, whatever exist says
+	svc := &fakeInscriptionService{
+		courseExists: true,
+		courseErr:    customError.NewError("UNEXPECTED_ERROR", "An unexpected error occurred. Please try again later.", http.StatusInternalServerError),
+	}
+	r.POST("/enroll", CourseExist(svc), func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	payload := map[string]string{"course_id": uuid.New().String()}
+	b, _ := json.Marshal(payload)
+	req := httptest.NewRequest(http.MethodPost, "/enroll", bytes.NewBuffer(b))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+
+	svc.courseExists = false
+	req = httptest.NewRequest(http.MethodPost, "/enroll", bytes.NewBuffer(b))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
 func TestCourseExistMiddleware_SetsContextAndNext(t *testing.T) {
 	r := setupRouter()
 	svc := &fakeInscriptionService{courseExists: true}
@@ -103,6 +134,36 @@ func TestCourseExistMiddleware_SetsContextAndNext(t *testing.T) {
 	require.Equal(t, http.StatusNoContent, w.Code)
 }
 
+func TestCourseExistMiddleware_CohortID(t *testing.T) {
+	r := setupRouter()
+	svc := &fakeInscriptionService{courseExists: true}
+	cohortId := uuid.New()
+	r.POST("/enroll", CourseExist(svc), func(c *gin.Context) {
+		v, ok := c.Get("cohortID")
+		if !ok || v != cohortId {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+
+	payload := map[string]string{"course_id": uuid.New().String(), "cohort_id": cohortId.String()}
+	b, _ := json.Marshal(payload)
+	req := httptest.NewRequest(http.MethodPost, "/enroll", bytes.NewBuffer(b))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	payload["cohort_id"] = "not-a-uuid"
+	b, _ = json.Marshal(payload)
+	req = httptest.NewRequest(http.MethodPost, "/enroll", bytes.NewBuffer(b))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
+
 func TestIsAlreadyEnrollMiddleware_MissingUserID(t *testing.T) {
 	r := setupRouter()
 	svc := &fakeInscriptionService{isEnrolled: false}
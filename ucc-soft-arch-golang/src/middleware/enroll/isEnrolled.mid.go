@@ -0,0 +1,55 @@
+package enroll
+
+import (
+	"net/http"
+
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/jwt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// IsEnrolled lets the request through only if the user is enrolled in the
+// course named by the param route parameter. The course's instructors and
+// roles with courses:manage_all pass too. It must run after
+// user.AuthMiddleware.
+func IsEnrolled(service services.IInscriptionService, courseService services.ICourseService, permissionService services.IPermissionService, param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, exists := c.Get("claims")
+		if !exists {
+			c.Error(customError.NewError("AUTHORIZATION_REQUIRED", "Authorization header is required", http.StatusUnauthorized))
+			c.Abort()
+			return
+		}
+		claims := value.(*jwt.CustomClaims)
+
+		courseId, err := uuid.Parse(c.Param(param))
+		if err != nil {
+			c.Error(customError.NewError("INVALID_UUID", "Invalid UUID", http.StatusBadRequest))
+			c.Abort()
+			return
+		}
+
+		checks := []func() (bool, error){
+			func() (bool, error) { return service.IsUserEnrolled(claims.Id, courseId) },
+			func() (bool, error) { return courseService.IsInstructor(courseId, claims.Id) },
+			func() (bool, error) { return permissionService.Allows(claims, model.PermissionCoursesManageAll) },
+		}
+		for _, check := range checks {
+			allowed, err := check()
+			if err != nil {
+				c.Error(err)
+				c.Abort()
+				return
+			}
+			if allowed {
+				c.Next()
+				return
+			}
+		}
+		c.Error(customError.NewError("NOT_ENROLLED", "You have to be enrolled in this course", http.StatusForbidden))
+		c.Abort()
+	}
+}
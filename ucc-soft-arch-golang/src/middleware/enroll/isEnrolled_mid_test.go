@@ -0,0 +1,54 @@
+package enroll
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	middlewares "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/jwt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCourseService struct {
+	services.ICourseService
+	instructor uuid.UUID
+}
+
+func (f fakeCourseService) IsInstructor(_ uuid.UUID, userId uuid.UUID) (bool, error) {
+	return userId == f.instructor, nil
+}
+
+type fakePermissionService struct {
+	services.IPermissionService
+}
+
+func (fakePermissionService) Allows(claims *jwt.CustomClaims, permission string) (bool, error) {
+	return claims.Role == model.RoleAdmin && permission == model.PermissionCoursesManageAll, nil
+}
+
+func runIsEnrolled(svc *fakeInscriptionService, courses fakeCourseService, userId uuid.UUID, role string, courseId string) int {
+	r := setupRouter()
+	r.Use(middlewares.ErrorHandler())
+	r.Use(func(c *gin.Context) { c.Set("claims", jwt.NewCustomClaims(userId, role)); c.Next() })
+	r.GET("/courses/:id/lessons", IsEnrolled(svc, courses, fakePermissionService{}, "id"), func(c *gin.Context) { c.Status(http.StatusOK) })
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/courses/"+courseId+"/lessons", nil))
+	return w.Code
+}
+
+func TestIsEnrolledMiddleware(t *testing.T) {
+	courses := fakeCourseService{instructor: uuid.New()}
+	courseId := uuid.New().String()
+
+	require.Equal(t, http.StatusOK, runIsEnrolled(&fakeInscriptionService{isEnrolled: true}, courses, uuid.New(), model.RoleStudent, courseId))
+	require.Equal(t, http.StatusForbidden, runIsEnrolled(&fakeInscriptionService{}, courses, uuid.New(), model.RoleStudent, courseId))
+	// teachers and admins read without enrolling
+	require.Equal(t, http.StatusOK, runIsEnrolled(&fakeInscriptionService{}, courses, courses.instructor, model.RoleInstructor, courseId))
+	require.Equal(t, http.StatusOK, runIsEnrolled(&fakeInscriptionService{}, courses, uuid.New(), model.RoleAdmin, courseId))
+	require.Equal(t, http.StatusBadRequest, runIsEnrolled(&fakeInscriptionService{isEnrolled: true}, courses, uuid.New(), model.RoleStudent, "nope"))
+}
@@ -0,0 +1,66 @@
+package enroll
+
+import (
+	"net/http"
+
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/jwt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// MeetsPrerequisites rejects the enrollment with the prerequisites of the
+// course the user hasn't completed. Roles with prerequisites:override skip
+// the check. It must run after CourseExist, which sets courseID.
+func MeetsPrerequisites(service services.IPrerequisiteService, permissionService services.IPermissionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, exists := c.Get("claims")
+		if !exists {
+			c.Error(customError.NewError("AUTHORIZATION_REQUIRED", "Authorization header is required", http.StatusUnauthorized))
+			c.Abort()
+			return
+		}
+		claims := value.(*jwt.CustomClaims)
+
+		courseID, exists := c.Get("courseID")
+		if !exists {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Course ID not found"})
+			c.Abort()
+			return
+		}
+		courseId, err := uuid.Parse(courseID.(string))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid Course ID"})
+			c.Abort()
+			return
+		}
+
+		override, err := permissionService.Allows(claims, model.PermissionPrerequisitesOverride)
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+		if override {
+			c.Next()
+			return
+		}
+		unmet, err := service.UnmetPrerequisites(claims.Id, courseId)
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+		if len(unmet) > 0 {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":         "PREREQUISITES_NOT_MET: Complete the prerequisites of this course first",
+				"prerequisites": unmet,
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
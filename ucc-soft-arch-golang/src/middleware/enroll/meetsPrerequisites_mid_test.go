@@ -0,0 +1,60 @@
+package enroll
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	dto "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/courses"
+	middlewares "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/jwt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePrerequisiteService struct {
+	services.IPrerequisiteService
+	unmet dto.PrerequisitesDto
+}
+
+func (f fakePrerequisiteService) UnmetPrerequisites(_ uuid.UUID, _ uuid.UUID) (dto.PrerequisitesDto, error) {
+	return f.unmet, nil
+}
+
+type fakeOverridePermissionService struct {
+	services.IPermissionService
+}
+
+func (fakeOverridePermissionService) Allows(claims *jwt.CustomClaims, permission string) (bool, error) {
+	return claims.Role == model.RoleAdmin && permission == model.PermissionPrerequisitesOverride, nil
+}
+
+func runMeetsPrerequisites(svc fakePrerequisiteService, role string) *httptest.ResponseRecorder {
+	r := setupRouter()
+	r.Use(middlewares.ErrorHandler())
+	r.Use(func(c *gin.Context) {
+		c.Set("claims", jwt.NewCustomClaims(uuid.New(), role))
+		c.Set("courseID", uuid.NewString())
+		c.Next()
+	})
+	r.POST("/enroll", MeetsPrerequisites(svc, fakeOverridePermissionService{}), func(c *gin.Context) { c.Status(http.StatusOK) })
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/enroll", nil))
+	return w
+}
+
+func TestMeetsPrerequisitesMiddleware(t *testing.T) {
+	require.Equal(t, http.StatusOK, runMeetsPrerequisites(fakePrerequisiteService{unmet: dto.PrerequisitesDto{}}, model.RoleStudent).Code)
+
+	unmet := fakePrerequisiteService{unmet: dto.PrerequisitesDto{{Id: uuid.New(), CourseName: "Go basics"}}}
+	w := runMeetsPrerequisites(unmet, model.RoleStudent)
+	require.Equal(t, http.StatusForbidden, w.Code)
+	require.True(t, strings.Contains(w.Body.String(), `"course_name":"Go basics"`), w.Body.String())
+
+	// admins enroll anyway
+	require.Equal(t, http.StatusOK, runMeetsPrerequisites(unmet, model.RoleAdmin).Code)
+}
@@ -0,0 +1,35 @@
+package permission
+
+import (
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/jwt"
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission lets the request through only if the role in the token
+// has been granted permission and, for API keys, the key is scoped to it. It
+// must run after user.AuthMiddleware.
+func RequirePermission(service services.IPermissionService, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, exists := c.Get("claims")
+		if !exists {
+			c.Error(customError.NewError("AUTHORIZATION_REQUIRED", "Authorization header is required", 401))
+			c.Abort()
+			return
+		}
+
+		allowed, err := service.Allows(claims.(*jwt.CustomClaims), permission)
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+		if !allowed {
+			c.Error(customError.NewError("FORBIDDEN", "You don't have permission to access this resource", 403))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,59 @@
+package permission
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	middlewares "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/jwt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type fakePermissionService struct {
+	granted map[string]bool
+}
+
+func (f fakePermissionService) HasPermission(role string, permission string) (bool, error) {
+	return f.granted[role+"|"+permission], nil
+}
+func (f fakePermissionService) Allows(claims *jwt.CustomClaims, permission string) (bool, error) {
+	return f.HasPermission(claims.Role, permission)
+}
+func (f fakePermissionService) GetRoles() (model.Roles, error)       { return nil, nil }
+func (f fakePermissionService) RoleExists(role string) (bool, error) { return true, nil }
+
+func run(role string, withClaims bool) int {
+	gin.SetMode(gin.TestMode)
+	service := fakePermissionService{granted: map[string]bool{"instructor|courses:write": true}}
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	if withClaims {
+		r.Use(func(c *gin.Context) { c.Set("claims", jwt.NewCustomClaims(uuid.New(), role)); c.Next() })
+	}
+	r.Use(RequirePermission(service, "courses:write"))
+	r.GET("/path", func(c *gin.Context) { c.Status(http.StatusOK) })
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/path", nil))
+	return w.Code
+}
+
+func TestRequirePermission_Granted(t *testing.T) {
+	if code := run("instructor", true); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+}
+
+func TestRequirePermission_Denied(t *testing.T) {
+	if code := run("student", true); code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", code)
+	}
+}
+
+func TestRequirePermission_NoClaims(t *testing.T) {
+	if code := run("instructor", false); code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", code)
+	}
+}
@@ -12,13 +12,16 @@ import (
 )
 
 // fake implementation of IUserService
-type fakeUserService struct{ exists bool }
+type fakeUserService struct {
+	exists   bool
+	verified bool
+}
 
 func (f fakeUserService) CreateUser(user users.RegisterRequest) (users.RegisterResponse, error) {
 	return users.RegisterResponse{}, nil
 }
 func (f fakeUserService) GetUserById(id uuid.UUID) (users.GetUserDto, error) {
-	return users.GetUserDto{}, nil
+	return users.GetUserDto{Id: id, EmailVerified: f.verified}, nil
 }
 func (f fakeUserService) GetUserByEmail(email string) (users.GetUserDto, error) {
 	if f.exists {
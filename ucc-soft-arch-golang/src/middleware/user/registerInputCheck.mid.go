@@ -3,11 +3,15 @@ package user
 import (
 	"github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/users"
 	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/google/uuid"
 
 	"github.com/gin-gonic/gin"
 )
 
-func RegisterInputCheckMiddleware() gin.HandlerFunc {
+// RegisterInputCheckMiddleware checks the registration body, including that
+// the password follows the password policy.
+func RegisterInputCheckMiddleware(policy services.IPasswordPolicyService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var user users.RegisterRequest
 		err := c.BindJSON(&user)
@@ -23,6 +27,11 @@ func RegisterInputCheckMiddleware() gin.HandlerFunc {
 			c.Abort()
 			return
 		}
+		if err := policy.Validate(uuid.Nil, user.Password); err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
 		c.Set("Username", user.Username)
 		c.Set("Email", user.Email)
 		c.Set("Password", user.Password)
@@ -6,15 +6,25 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
 	middlewares "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
+type stubPasswordPolicy struct {
+	services.IPasswordPolicyService
+	err error
+}
+
+func (s stubPasswordPolicy) Validate(userId uuid.UUID, plain string) error { return s.err }
+
 func TestRegisterInputCheck_InvalidBody(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	r := gin.New()
 	r.Use(middlewares.ErrorHandler())
-	r.Use(RegisterInputCheckMiddleware())
+	r.Use(RegisterInputCheckMiddleware(stubPasswordPolicy{}))
 	r.POST("/register", func(c *gin.Context) { c.Status(http.StatusOK) })
 	w := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodPost, "/register", bytes.NewBufferString("not-json"))
@@ -29,7 +39,7 @@ func TestRegisterInputCheck_MissingFields(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	r := gin.New()
 	r.Use(middlewares.ErrorHandler())
-	r.Use(RegisterInputCheckMiddleware())
+	r.Use(RegisterInputCheckMiddleware(stubPasswordPolicy{}))
 	r.POST("/register", func(c *gin.Context) { c.Status(http.StatusOK) })
 	body := bytes.NewBufferString(`{"email":"e@x.com"}`)
 	req := httptest.NewRequest(http.MethodPost, "/register", body)
@@ -44,7 +54,7 @@ func TestRegisterInputCheck_MissingFields(t *testing.T) {
 func TestRegisterInputCheck_SetsContext(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	r := gin.New()
-	r.Use(RegisterInputCheckMiddleware())
+	r.Use(RegisterInputCheckMiddleware(stubPasswordPolicy{}))
 	r.POST("/register", func(c *gin.Context) {
 		if c.GetString("Email") == "" || c.GetString("Username") == "" || c.GetString("Password") == "" {
 			t.Fatalf("expected context keys to be set")
@@ -60,3 +70,21 @@ func TestRegisterInputCheck_SetsContext(t *testing.T) {
 		t.Fatalf("expected 200, got %d", w.Code)
 	}
 }
+
+func TestRegisterInputCheck_RejectsWeakPassword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	r.Use(RegisterInputCheckMiddleware(stubPasswordPolicy{err: customError.NewError("WEAK_PASSWORD", "too short", http.StatusBadRequest)}))
+	r.POST("/register", func(c *gin.Context) {
+		t.Fatalf("handler must not run for a weak password")
+	})
+	body := bytes.NewBufferString(`{"email":"e@x.com","username":"john","password":"p"}`)
+	req := httptest.NewRequest(http.MethodPost, "/register", body)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
@@ -0,0 +1,35 @@
+package user
+
+import (
+	"net/http"
+
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/jwt"
+	"github.com/gin-gonic/gin"
+)
+
+// RequireUserSession rejects requests made with an API key or with an
+// impersonation token, for endpoints that only make sense for a signed in
+// person (their own profile, password, 2FA, logout, minting more keys...).
+// It must run after AuthMiddleware.
+func RequireUserSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, exists := c.Get("claims")
+		if !exists {
+			c.Error(customError.NewError("AUTHORIZATION_REQUIRED", "Authorization header is required", http.StatusUnauthorized))
+			c.Abort()
+			return
+		}
+		if claims.(*jwt.CustomClaims).IsAPIKey() {
+			c.Error(customError.NewError("USER_SESSION_REQUIRED", "This endpoint can't be used with an API key", http.StatusForbidden))
+			c.Abort()
+			return
+		}
+		if claims.(*jwt.CustomClaims).IsImpersonation() {
+			c.Error(customError.NewError("IMPERSONATION_NOT_ALLOWED", "This endpoint can't be used while impersonating", http.StatusForbidden))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,53 @@
+package user
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	middlewares "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/jwt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func runRequireUserSession(claims *jwt.CustomClaims) int {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	if claims != nil {
+		r.Use(func(c *gin.Context) { c.Set("claims", claims); c.Next() })
+	}
+	r.Use(RequireUserSession())
+	r.GET("/path", func(c *gin.Context) { c.Status(http.StatusOK) })
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/path", nil))
+	return w.Code
+}
+
+func TestRequireUserSession_PassesSessions(t *testing.T) {
+	if code := runRequireUserSession(jwt.NewCustomClaims(uuid.New(), "student")); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+}
+
+func TestRequireUserSession_BlocksAPIKeys(t *testing.T) {
+	claims := &jwt.CustomClaims{Id: uuid.New(), Role: "admin", APIKeyId: uuid.New()}
+	if code := runRequireUserSession(claims); code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", code)
+	}
+}
+
+func TestRequireUserSession_BlocksImpersonation(t *testing.T) {
+	claims := jwt.NewCustomClaims(uuid.New(), "student")
+	claims.Actor = &jwt.Actor{Subject: uuid.New()}
+	if code := runRequireUserSession(claims); code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", code)
+	}
+}
+
+func TestRequireUserSession_RequiresAuth(t *testing.T) {
+	if code := runRequireUserSession(nil); code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", code)
+	}
+}
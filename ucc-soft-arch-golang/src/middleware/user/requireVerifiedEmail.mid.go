@@ -0,0 +1,34 @@
+package user
+
+import (
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequireVerifiedEmail blocks users that haven't confirmed their email yet.
+// It must run after AuthMiddleware.
+func RequireVerifiedEmail(service services.IUserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := c.Get("userID")
+		if !exists {
+			c.Error(customError.NewError("AUTHORIZATION_REQUIRED", "Authorization header is required", 400))
+			c.Abort()
+			return
+		}
+
+		user, err := service.GetUserById(userID.(uuid.UUID))
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+		if !user.EmailVerified {
+			c.Error(customError.NewError("EMAIL_NOT_VERIFIED", "Please verify your email first", 403))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,43 @@
+package user
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	middlewares "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func runRequireVerified(service fakeUserService, setUser bool) int {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	if setUser {
+		r.Use(func(c *gin.Context) { c.Set("userID", uuid.New()); c.Next() })
+	}
+	r.Use(RequireVerifiedEmail(service))
+	r.GET("/path", func(c *gin.Context) { c.Status(http.StatusOK) })
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/path", nil))
+	return w.Code
+}
+
+func TestRequireVerifiedEmail_BlocksUnverified(t *testing.T) {
+	if code := runRequireVerified(fakeUserService{verified: false}, true); code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", code)
+	}
+}
+
+func TestRequireVerifiedEmail_PassesVerified(t *testing.T) {
+	if code := runRequireVerified(fakeUserService{verified: true}, true); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+}
+
+func TestRequireVerifiedEmail_RequiresAuth(t *testing.T) {
+	if code := runRequireVerified(fakeUserService{verified: true}, false); code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", code)
+	}
+}
@@ -1,22 +1,54 @@
 package user
 
 import (
+	userDtos "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/users"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/adapter"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/config"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/apikeys"
 	middlewares "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
 	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/jwt"
 	"github.com/gin-gonic/gin"
+	github_com_glebarez_sqlite "github.com/glebarez/sqlite"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
+// setupTokenService wires a real token service over sqlite and seeds a user
+func setupTokenServiceWithDB(t *testing.T) (services.ITokenService, services.IRevocationService, uuid.UUID, *gorm.DB) {
+	db, err := gorm.Open(github_com_glebarez_sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(&model.User{}, &model.RefreshToken{}, &model.RevokedToken{}, &model.Session{}, &model.AuditLog{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	u := model.User{Email: "user@ex.com", Password: "x", Name: "User"}
+	if err := db.Create(&u).Error; err != nil {
+		t.Fatalf("seed user: %v", err)
+	}
+	tokenService, revocationService := adapter.TokenAdapter(db)
+	return tokenService, revocationService, u.Id, db
+}
+
+func setupTokenService(t *testing.T) (services.ITokenService, services.IRevocationService, uuid.UUID) {
+	tokenService, revocationService, userId, _ := setupTokenServiceWithDB(t)
+	return tokenService, revocationService, userId
+}
+
 func TestAuthMiddleware_MissingHeader(t *testing.T) {
 	gin.SetMode(gin.TestMode)
+	svc, _, _ := setupTokenService(t)
 	r := gin.New()
 	r.Use(middlewares.ErrorHandler())
-	r.Use(AuthMiddleware())
+	r.Use(AuthMiddleware(svc))
 	r.GET("/secure", func(c *gin.Context) { c.Status(http.StatusOK) })
 	w := httptest.NewRecorder()
 	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/secure", nil))
@@ -27,9 +59,10 @@ func TestAuthMiddleware_MissingHeader(t *testing.T) {
 
 func TestAuthMiddleware_InvalidFormat(t *testing.T) {
 	gin.SetMode(gin.TestMode)
+	svc, _, _ := setupTokenService(t)
 	r := gin.New()
 	r.Use(middlewares.ErrorHandler())
-	r.Use(AuthMiddleware())
+	r.Use(AuthMiddleware(svc))
 	r.GET("/secure", func(c *gin.Context) { c.Status(http.StatusOK) })
 	req := httptest.NewRequest(http.MethodGet, "/secure", nil)
 	req.Header.Set("Authorization", "Token xxx")
@@ -43,17 +76,17 @@ func TestAuthMiddleware_InvalidFormat(t *testing.T) {
 func TestAuthMiddleware_SetsUserIDOnSuccess(t *testing.T) {
 	os.Setenv("JWT_SECRET", "testsecret")
 	gin.SetMode(gin.TestMode)
+	svc, _, userId := setupTokenService(t)
 	r := gin.New()
 	r.Use(middlewares.ErrorHandler())
-	r.Use(AuthMiddleware())
+	r.Use(AuthMiddleware(svc))
 	r.GET("/secure", func(c *gin.Context) {
 		if _, exists := c.Get("userID"); !exists {
 			t.Fatalf("userID not set")
 		}
 		c.Status(http.StatusOK)
 	})
-	uid := uuid.New()
-	token := jwt.SignDocument(uid, 0)
+	token := jwt.SignDocument(userId, "student")
 	req := httptest.NewRequest(http.MethodGet, "/secure", nil)
 	req.Header.Set("Authorization", "Bearer "+token)
 	w := httptest.NewRecorder()
@@ -62,3 +95,261 @@ func TestAuthMiddleware_SetsUserIDOnSuccess(t *testing.T) {
 		t.Fatalf("expected 200, got %d", w.Code)
 	}
 }
+
+func TestOptionalAuth(t *testing.T) {
+	os.Setenv("JWT_SECRET", "testsecret")
+	gin.SetMode(gin.TestMode)
+	svc, _, userId := setupTokenService(t)
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	r.Use(OptionalAuth(svc))
+	r.GET("/public", func(c *gin.Context) {
+		if _, exists := c.Get("userID"); exists {
+			c.Status(http.StatusAccepted)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/public", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected anonymous request to pass, got %d", w.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/public", nil)
+	req.Header.Set("Authorization", "Bearer "+jwt.SignDocument(userId, "student"))
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected the user to be set, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/public", nil)
+	req.Header.Set("Authorization", "Bearer nope")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code == http.StatusOK || w.Code == http.StatusAccepted {
+		t.Fatalf("expected a bad token to be rejected, got %d", w.Code)
+	}
+}
+
+func TestAuthMiddleware_RejectsExpiredToken(t *testing.T) {
+	os.Setenv("JWT_SECRET", "testsecret")
+	gin.SetMode(gin.TestMode)
+	svc, _, userId := setupTokenService(t)
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	r.Use(AuthMiddleware(svc))
+	r.GET("/secure", func(c *gin.Context) { c.Status(http.StatusOK) })
+	claims := jwt.NewCustomClaims(userId, "student")
+	claims.ExpiresAt = time.Now().Add(-time.Second).Unix()
+	req := httptest.NewRequest(http.MethodGet, "/secure", nil)
+	req.Header.Set("Authorization", "Bearer "+jwt.SignClaims(claims))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code == http.StatusOK {
+		t.Fatalf("expected expired token to be rejected")
+	}
+}
+
+func TestAuthMiddleware_RejectsTokensRevokedForUser(t *testing.T) {
+	os.Setenv("JWT_SECRET", "testsecret")
+	gin.SetMode(gin.TestMode)
+	svc, revocation, userId := setupTokenService(t)
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	r.Use(AuthMiddleware(svc))
+	r.GET("/secure", func(c *gin.Context) { c.Status(http.StatusOK) })
+	pair, err := svc.IssueTokens(userId, "student", uuid.Nil, userDtos.ClientInfoDto{})
+	if err != nil {
+		t.Fatalf("issue tokens: %v", err)
+	}
+	if err := revocation.RevokeAllForUser(userId); err != nil {
+		t.Fatalf("revoke all: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/secure", nil)
+	req.Header.Set("Authorization", "Bearer "+pair.AccessToken)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 after revoking all tokens, got %d", w.Code)
+	}
+	// tokens issued afterwards carry the new version and are accepted
+	fresh, _ := svc.IssueTokens(userId, "student", uuid.Nil, userDtos.ClientInfoDto{})
+	req.Header.Set("Authorization", "Bearer "+fresh.AccessToken)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 for a token issued after revocation, got %d", w.Code)
+	}
+}
+
+func TestAuthMiddleware_RejectsEndedSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc, _, userId := setupTokenService(t)
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	r.Use(AuthMiddleware(svc))
+	r.GET("/secure", func(c *gin.Context) { c.Status(http.StatusOK) })
+	pair, err := svc.IssueTokens(userId, "student", uuid.Nil, userDtos.ClientInfoDto{})
+	if err != nil {
+		t.Fatalf("issue tokens: %v", err)
+	}
+	// revoking the refresh token ends the session its access token belongs to
+	if err := svc.RevokeRefreshToken(userId, pair.RefreshToken); err != nil {
+		t.Fatalf("end session: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/secure", nil)
+	req.Header.Set("Authorization", "Bearer "+pair.AccessToken)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for an ended session, got %d", w.Code)
+	}
+}
+
+func TestAuthMiddleware_AcceptsAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := gorm.Open(github_com_glebarez_sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(&model.User{}, &model.RefreshToken{}, &model.RevokedToken{}, &model.Session{}, &model.APIKey{}, &model.AuditLog{}, &model.Permission{}, &model.Role{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if err := config.SeedRoles(db); err != nil {
+		t.Fatalf("seed roles: %v", err)
+	}
+	owner := model.User{Email: "reports@ex.com", Password: "x", Name: "Reports", Role: model.RoleAdmin}
+	if err := db.Create(&owner).Error; err != nil {
+		t.Fatalf("seed user: %v", err)
+	}
+	_, apiKeys := adapter.APIKeyAdapter(db)
+	created, err := apiKeys.Create(owner.Id, apikeys.CreateAPIKeyRequestDto{Name: "reports", Scopes: []string{model.PermissionAuditRead}})
+	if err != nil {
+		t.Fatalf("create key: %v", err)
+	}
+	svc, _ := adapter.TokenAdapter(db)
+
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	r.GET("/secure", ScopedAuthMiddleware(svc), func(c *gin.Context) {
+		claims, _ := c.Get("claims")
+		if !claims.(*jwt.CustomClaims).IsAPIKey() {
+			t.Fatalf("expected API key claims")
+		}
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/secure", nil)
+	req.Header.Set("X-API-Key", created.Key)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+
+	req.Header.Set("X-API-Key", "ucc_unknown.key")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for an unknown key, got %d", w.Code)
+	}
+}
+
+func TestAuthMiddleware_RejectsAPIKeyOnUnscopedRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc, _, _ := setupTokenService(t)
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	r.Use(AuthMiddleware(svc))
+	r.POST("/enroll", func(c *gin.Context) {
+		t.Fatalf("an API key must not reach an unscoped route")
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/enroll", nil)
+	req.Header.Set("X-API-Key", "ucc_any.key")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", w.Code)
+	}
+}
+
+func TestAuthMiddleware_RejectsSuspendedUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc, _, userId, db := setupTokenServiceWithDB(t)
+	pair, err := svc.IssueTokens(userId, "student", uuid.Nil, userDtos.ClientInfoDto{})
+	if err != nil {
+		t.Fatalf("issue tokens: %v", err)
+	}
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	r.Use(AuthMiddleware(svc))
+	r.GET("/secure", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	if err := db.Model(&model.User{}).Where("id = ?", userId).Update("suspended_at", time.Now()).Error; err != nil {
+		t.Fatalf("suspend: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/secure", nil)
+	req.Header.Set("Authorization", "Bearer "+pair.AccessToken)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected Forbidden for a suspended user, got %d", w.Code)
+	}
+}
+
+func TestAuthMiddleware_ImpersonationIsReadOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := gorm.Open(github_com_glebarez_sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(&model.User{}, &model.RefreshToken{}, &model.RevokedToken{}, &model.Session{}, &model.AuditLog{}, &model.Permission{}, &model.Role{}, &model.Impersonation{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if err := config.SeedRoles(db); err != nil {
+		t.Fatalf("seed roles: %v", err)
+	}
+	admin := model.User{Email: "admin@ex.com", Password: "x", Name: "Admin", Role: model.RoleAdmin}
+	student := model.User{Email: "student@ex.com", Password: "x", Name: "Student"}
+	for _, u := range []*model.User{&admin, &student} {
+		if err := db.Create(u).Error; err != nil {
+			t.Fatalf("seed user: %v", err)
+		}
+	}
+	_, impersonations := adapter.ImpersonationAdapter(db)
+	impersonation, err := impersonations.Start(admin.Id, student.Id, "ticket 42", userDtos.ClientInfoDto{})
+	if err != nil {
+		t.Fatalf("start impersonation: %v", err)
+	}
+	svc, _ := adapter.TokenAdapter(db)
+
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	r.GET("/courses", AuthMiddleware(svc), ok)
+	r.POST("/enroll", AuthMiddleware(svc), ok)
+	r.PUT("/comments/1", ScopedAuthMiddleware(svc), ok)
+	r.POST("/auth/impersonation/end", EndImpersonationAuth(svc), ok)
+
+	for _, tc := range []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodGet, "/courses", http.StatusOK},
+		{http.MethodPost, "/enroll", http.StatusForbidden},
+		{http.MethodPut, "/comments/1", http.StatusForbidden},
+		{http.MethodPost, "/auth/impersonation/end", http.StatusOK},
+	} {
+		req := httptest.NewRequest(tc.method, tc.path, nil)
+		req.Header.Set("Authorization", "Bearer "+impersonation.AccessToken)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tc.want {
+			t.Fatalf("%s %s: expected %d, got %d", tc.method, tc.path, tc.want, w.Code)
+		}
+	}
+}
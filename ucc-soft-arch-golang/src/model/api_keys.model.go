@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// APIKey lets another service call the API without a user session. The key
+// acts as its owner but only for the permissions listed in Scopes (comma
+// separated). Only the hash of the key is stored; Prefix is its public part,
+// shown in listings so a key can be recognised.
+type APIKey struct {
+	gorm.Model
+	Id         uuid.UUID `sql:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Name       string
+	Prefix     string    `gorm:"uniqueIndex"`
+	KeyHash    string    `gorm:"uniqueIndex"`
+	OwnerId    uuid.UUID `gorm:"index"`
+	Scopes     string
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
+
+func (model *APIKey) BeforeCreate(tx *gorm.DB) (err error) {
+	model.Id = uuid.New()
+	return
+}
+
+type APIKeys []APIKey
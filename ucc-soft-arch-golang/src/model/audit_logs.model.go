@@ -0,0 +1,20 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AuditLog records security relevant events (lockouts, unlocks...). ActorId is
+// who triggered the event and UserId who it affected; either may be uuid.Nil.
+type AuditLog struct {
+	gorm.Model
+	Event   string    `gorm:"index"`
+	ActorId uuid.UUID `gorm:"index"`
+	UserId  uuid.UUID `gorm:"index"`
+	Email   string
+	Ip      string
+	Details string
+}
+
+type AuditLogs []AuditLog
@@ -0,0 +1,83 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Where a cohort is in time, and what the catalog can be filtered by.
+const (
+	CohortUpcoming = "upcoming"
+	CohortOngoing  = "ongoing"
+	CohortFinished = "finished"
+)
+
+var CohortSchedules = []string{CohortUpcoming, CohortOngoing, CohortFinished}
+
+// Cohort is a run of a course. StartsAt, EndsAt and the enrollment window
+// are stored in UTC; TimeZone is the IANA zone they are shown in. Without
+// EnrollmentOpensAt enrollment is open right away, and without
+// EnrollmentClosesAt it closes when the cohort ends. Once a course has
+// cohorts, students enroll in one of them and Capacity replaces the
+// course's own capacity.
+type Cohort struct {
+	gorm.Model
+	Id                 uuid.UUID `sql:"type:uuid;primary_key;default:gen_random_uuid()"`
+	CourseId           uuid.UUID `gorm:"index"`
+	Name               string
+	StartsAt           time.Time `gorm:"index"`
+	EndsAt             time.Time `gorm:"index"`
+	TimeZone           string
+	EnrollmentOpensAt  *time.Time
+	EnrollmentClosesAt *time.Time
+	Capacity           int
+}
+
+func (model *Cohort) BeforeCreate(tx *gorm.DB) (err error) {
+	model.Id = uuid.New()
+	return
+}
+
+type Cohorts []Cohort
+
+// Schedule tells whether the cohort is upcoming, ongoing or finished at
+// the given time.
+func (c Cohort) Schedule(at time.Time) string {
+	switch {
+	case c.StartsAt.After(at):
+		return CohortUpcoming
+	case c.EndsAt.After(at):
+		return CohortOngoing
+	default:
+		return CohortFinished
+	}
+}
+
+// EnrollmentOpen reports whether students can enroll in the cohort at the
+// given time.
+func (c Cohort) EnrollmentOpen(at time.Time) bool {
+	closesAt := c.EndsAt
+	if c.EnrollmentClosesAt != nil {
+		closesAt = *c.EnrollmentClosesAt
+	}
+	return (c.EnrollmentOpensAt == nil || !c.EnrollmentOpensAt.After(at)) && closesAt.After(at)
+}
+
+// CoursesWithCohorts matches the courses of table that have a cohort in
+// the given schedule at the given time. A course is finished once all of
+// its cohorts are, so it can be upcoming and ongoing at once but never
+// finished and anything else.
+func CoursesWithCohorts(table string, schedule string, at time.Time) clause.Expr {
+	cohorts := "SELECT 1 FROM cohorts WHERE cohorts.course_id = " + table + ".id AND cohorts.deleted_at IS NULL"
+	switch schedule {
+	case CohortUpcoming:
+		return gorm.Expr("EXISTS ("+cohorts+" AND cohorts.starts_at > ?)", at)
+	case CohortOngoing:
+		return gorm.Expr("EXISTS ("+cohorts+" AND cohorts.starts_at <= ? AND cohorts.ends_at > ?)", at, at)
+	default:
+		return gorm.Expr("EXISTS ("+cohorts+") AND NOT EXISTS ("+cohorts+" AND cohorts.ends_at > ?)", at)
+	}
+}
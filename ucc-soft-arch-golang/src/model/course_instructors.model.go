@@ -0,0 +1,18 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CourseInstructor links a course to a user that teaches it. The user that
+// created the course is the owner, the rest are co-instructors.
+type CourseInstructor struct {
+	gorm.Model
+	CourseId uuid.UUID `gorm:"uniqueIndex:idx_course_instructor"`
+	UserId   uuid.UUID `gorm:"uniqueIndex:idx_course_instructor;index"`
+	IsOwner  bool      `gorm:"default:false"`
+	User     User      `gorm:"foreignKey:UserId"`
+}
+
+type CourseInstructors []CourseInstructor
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Course statuses, stored in Course.CourseStatus.
+const (
+	CourseDraft     = "draft"
+	CourseInReview  = "in_review"
+	CoursePublished = "published"
+	CourseArchived  = "archived"
+)
+
+var CourseStatuses = []string{CourseDraft, CourseInReview, CoursePublished, CourseArchived}
+
+// Who may move a course between statuses: its instructors (or anyone with
+// courses:manage_all) and reviewers, the roles with courses:publish.
+const (
+	CourseActorInstructor = "instructor"
+	CourseActorReviewer   = "reviewer"
+)
+
+// CourseTransitions lists, for each status, the statuses a course can move
+// to and who may move it there.
+var CourseTransitions = map[string]map[string][]string{
+	CourseDraft: {
+		CourseInReview: {CourseActorInstructor},
+	},
+	CourseInReview: {
+		CourseDraft:     {CourseActorInstructor, CourseActorReviewer},
+		CoursePublished: {CourseActorReviewer},
+	},
+	CoursePublished: {
+		CourseArchived: {CourseActorInstructor, CourseActorReviewer},
+	},
+	CourseArchived: {
+		CourseDraft:     {CourseActorInstructor},
+		CoursePublished: {CourseActorReviewer},
+	},
+}
+
+// IsLive reports whether everyone can see the course at the given time: it
+// is published and inside its publication window.
+func (c Course) IsLive(at time.Time) bool {
+	return c.CourseStatus == CoursePublished &&
+		(c.PublishAt == nil || !c.PublishAt.After(at)) &&
+		(c.UnpublishAt == nil || c.UnpublishAt.After(at))
+}
+
+// LiveCourses is IsLive as a query condition on the courses of table.
+func LiveCourses(table string, at time.Time) clause.Expr {
+	return gorm.Expr(table+".course_status = ? AND ("+table+".publish_at IS NULL OR "+table+".publish_at <= ?) AND ("+table+".unpublish_at IS NULL OR "+table+".unpublish_at > ?)",
+		CoursePublished, at, at)
+}
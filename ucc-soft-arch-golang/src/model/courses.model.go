@@ -1,6 +1,8 @@
 package model
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -13,13 +15,16 @@ type Course struct {
 	CoursePrice       float64   `gorm:"price"`
 	CourseDuration    int       `gorm:"duration"`
 	CourseInitDate    string    `gorm:"init_date"`
-	CourseState       bool      `gorm:"state;default:false"`
-	CourseCapacity    int       `gorm:"cupo;default:15"`
-	CourseImage       string    `gorm:"image;default:https://upload.wikimedia.org/wikipedia/commons/a/a3/Image-not-found.png"`
+	CourseStatus      string    `gorm:"index;default:draft"`
+	PublishAt         *time.Time
+	UnpublishAt       *time.Time
+	CourseCapacity    int    `gorm:"cupo;default:15"`
+	CourseImage       string `gorm:"image;default:https://upload.wikimedia.org/wikipedia/commons/a/a3/Image-not-found.png"`
 	CategoryID        uuid.UUID
-	Category          Category `gorm:"foreignKey:CategoryID"`
-	Ratings           Ratings  `gorm:"foreignKey:CourseId"`
-	RatingAvg         float64  `gorm:"-" json:"ratingavg"`
+	Category          Category          `gorm:"foreignKey:CategoryID"`
+	Ratings           Ratings           `gorm:"foreignKey:CourseId"`
+	Instructors       CourseInstructors `gorm:"foreignKey:CourseId"`
+	RatingAvg         float64           `gorm:"-" json:"ratingavg"`
 }
 
 func (model *Course) BeforeCreate(tx *gorm.DB) (err error) {
@@ -0,0 +1,55 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Lesson types.
+const (
+	LessonVideo = "video"
+	LessonText  = "text"
+	LessonFile  = "file"
+	LessonLink  = "link"
+)
+
+var LessonTypes = []string{LessonVideo, LessonText, LessonFile, LessonLink}
+
+// Section groups the lessons of a course. Position orders the sections of a
+// course and the lessons of a section.
+type Section struct {
+	gorm.Model
+	Id       uuid.UUID `sql:"type:uuid;primary_key;default:gen_random_uuid()"`
+	CourseId uuid.UUID `gorm:"index"`
+	Title    string
+	Position int
+}
+
+func (model *Section) BeforeCreate(tx *gorm.DB) (err error) {
+	model.Id = uuid.New()
+	return
+}
+
+// Lesson is a piece of content of a section. Text lessons keep their
+// markdown in Body; video, file and link lessons point at Url. Duration is
+// in minutes.
+type Lesson struct {
+	gorm.Model
+	Id        uuid.UUID `sql:"type:uuid;primary_key;default:gen_random_uuid()"`
+	CourseId  uuid.UUID `gorm:"index"`
+	SectionId uuid.UUID `gorm:"index"`
+	Title     string
+	Type      string
+	Position  int
+	Body      string `gorm:"type:text"`
+	Url       string
+	Duration  int
+}
+
+func (model *Lesson) BeforeCreate(tx *gorm.DB) (err error) {
+	model.Id = uuid.New()
+	return
+}
+
+type Sections []Section
+type Lessons []Lesson
@@ -0,0 +1,19 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// EmailVerification stores the hash of the token mailed to confirm an address.
+type EmailVerification struct {
+	gorm.Model
+	UserId    uuid.UUID `gorm:"index"`
+	TokenHash string    `gorm:"uniqueIndex"`
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+}
+
+type EmailVerifications []EmailVerification
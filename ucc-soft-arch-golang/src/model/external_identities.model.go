@@ -0,0 +1,18 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ExternalIdentity links a user to an account at an OpenID Connect provider.
+// Provider holds the issuer URL and Subject the provider's stable user id.
+type ExternalIdentity struct {
+	gorm.Model
+	UserId   uuid.UUID `gorm:"index"`
+	Provider string    `gorm:"uniqueIndex:idx_provider_subject"`
+	Subject  string    `gorm:"uniqueIndex:idx_provider_subject"`
+	Email    string
+}
+
+type ExternalIdentities []ExternalIdentity
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Impersonation is an admin (ActorId) acting as a user (UserId). Its Id is
+// the sid claim of the impersonation token, so ending it rejects the token.
+type Impersonation struct {
+	gorm.Model
+	Id        uuid.UUID `sql:"type:uuid;primary_key;default:gen_random_uuid()"`
+	ActorId   uuid.UUID `gorm:"index"`
+	UserId    uuid.UUID `gorm:"index"`
+	Reason    string
+	Ip        string
+	ExpiresAt time.Time
+	EndedAt   *time.Time
+}
+
+func (model *Impersonation) BeforeCreate(tx *gorm.DB) (err error) {
+	model.Id = uuid.New()
+	return
+}
+
+type Impersonations []Impersonation
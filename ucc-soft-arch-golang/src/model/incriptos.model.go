@@ -1,14 +1,32 @@
 package model
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// Enrollment statuses, stored in Inscripto.Status. Only active enrollments
+// take a seat and give access to the course.
+const (
+	EnrollmentActive     = "active"
+	EnrollmentWaitlisted = "waitlisted"
+)
+
 type Inscripto struct {
 	gorm.Model
-	CourseId uuid.UUID
+	CourseId uuid.UUID `gorm:"index:idx_inscriptos_seats"`
 	UserId   uuid.UUID
+	// CohortId is the run of the course the user enrolled in, when the
+	// course has cohorts. Seats are then counted per cohort.
+	CohortId *uuid.UUID `gorm:"index"`
+	// Status is EnrollmentWaitlisted while the course is full. The waitlist
+	// is served in ID order.
+	Status string `gorm:"index:idx_inscriptos_seats;default:active"`
+	// CompletedAt is set once the course's completion rules are met and is
+	// kept even if lessons are added later.
+	CompletedAt *time.Time
 
 	User   User   `gorm:"foreignKey:UserId"`
 	Course Course `gorm:"foreignKey:CourseId"`
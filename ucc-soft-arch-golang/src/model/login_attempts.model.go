@@ -0,0 +1,20 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// LoginAttempt tracks consecutive failed logins for one identifier, either
+// "email:<address>" or "ip:<address>".
+type LoginAttempt struct {
+	gorm.Model
+	Identifier    string `gorm:"uniqueIndex"`
+	Failures      int
+	LastFailureAt time.Time
+	// BlockedUntil holds both the backoff delay and the lockout.
+	BlockedUntil time.Time
+}
+
+type LoginAttempts []LoginAttempt
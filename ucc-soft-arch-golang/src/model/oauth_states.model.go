@@ -0,0 +1,21 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// OAuthState remembers an external login in progress. Only the hash of the
+// state sent to the provider is stored; the PKCE verifier and nonce are
+// needed in clear to finish the login.
+type OAuthState struct {
+	gorm.Model
+	StateHash    string `gorm:"uniqueIndex"`
+	CodeVerifier string
+	Nonce        string
+	ExpiresAt    time.Time
+	UsedAt       *time.Time
+}
+
+type OAuthStates []OAuthState
@@ -0,0 +1,16 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PasswordHistory keeps the hashes of the last passwords of a user so they
+// can't be reused.
+type PasswordHistory struct {
+	gorm.Model
+	UserId uuid.UUID `gorm:"index"`
+	Hash   string
+}
+
+type PasswordHistories []PasswordHistory
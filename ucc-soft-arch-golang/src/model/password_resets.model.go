@@ -0,0 +1,19 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PasswordReset stores the hash of a single use password reset token.
+type PasswordReset struct {
+	gorm.Model
+	UserId    uuid.UUID `gorm:"index"`
+	TokenHash string    `gorm:"uniqueIndex"`
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+}
+
+type PasswordResets []PasswordReset
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CoursePrerequisite says that CourseId requires completing PrerequisiteId
+// first. The relation can't form cycles.
+type CoursePrerequisite struct {
+	gorm.Model
+	CourseId       uuid.UUID `gorm:"uniqueIndex:idx_course_prerequisite"`
+	PrerequisiteId uuid.UUID `gorm:"uniqueIndex:idx_course_prerequisite;index"`
+}
+
+// PrerequisiteWaiver lets a user enroll in a course without meeting its
+// prerequisites. GrantedBy is the admin that waived them.
+type PrerequisiteWaiver struct {
+	gorm.Model
+	CourseId  uuid.UUID `gorm:"uniqueIndex:idx_prerequisite_waiver"`
+	UserId    uuid.UUID `gorm:"uniqueIndex:idx_prerequisite_waiver;index"`
+	GrantedBy uuid.UUID
+}
+
+type CoursePrerequisites []CoursePrerequisite
+type PrerequisiteWaivers []PrerequisiteWaiver
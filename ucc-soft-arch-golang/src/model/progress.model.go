@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// LessonProgress is how far a student got in a lesson. Position is where
+// they left it (seconds into a video, how far down a text) as the client
+// reports it; CompletedAt is set the first time the lesson is completed.
+type LessonProgress struct {
+	gorm.Model
+	UserId       uuid.UUID `gorm:"uniqueIndex:idx_lesson_progress"`
+	LessonId     uuid.UUID `gorm:"uniqueIndex:idx_lesson_progress"`
+	CourseId     uuid.UUID `gorm:"index"`
+	Position     int
+	LastViewedAt time.Time
+	CompletedAt  *time.Time
+}
+
+// CompletionRule says when an enrollment counts as completed: at least
+// MinPercent of the lessons done, RequiredLessons (comma separated ids)
+// among them. Courses without one use the default percentage.
+type CompletionRule struct {
+	gorm.Model
+	CourseId        uuid.UUID `gorm:"uniqueIndex"`
+	MinPercent      int
+	RequiredLessons string
+}
+
+type LessonProgresses []LessonProgress
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RefreshToken is an opaque, single use token. Every rotation creates a new
+// row in the same family, so reusing an old token can revoke the whole chain.
+type RefreshToken struct {
+	gorm.Model
+	Id        uuid.UUID `sql:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserId    uuid.UUID `gorm:"index"`
+	FamilyId  uuid.UUID `gorm:"index"`
+	TokenHash string    `gorm:"uniqueIndex"`
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+}
+
+func (model *RefreshToken) BeforeCreate(tx *gorm.DB) (err error) {
+	model.Id = uuid.New()
+	return
+}
+
+type RefreshTokens []RefreshToken
@@ -0,0 +1,19 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RevokedToken is a denylist entry for a single access token (by jti). It is
+// only needed until the token would have expired anyway.
+type RevokedToken struct {
+	gorm.Model
+	TokenId   string    `gorm:"uniqueIndex"`
+	UserId    uuid.UUID `gorm:"index"`
+	ExpiresAt time.Time `gorm:"index"`
+}
+
+type RevokedTokens []RevokedToken
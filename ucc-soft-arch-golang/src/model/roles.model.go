@@ -0,0 +1,66 @@
+package model
+
+import "gorm.io/gorm"
+
+// Role names stored in User.Role.
+const (
+	RoleStudent    = "student"
+	RoleInstructor = "instructor"
+	RoleModerator  = "moderator"
+	RoleAdmin      = "admin"
+)
+
+// Permission names checked by the RequirePermission middleware.
+const (
+	PermissionCoursesWrite     = "courses:write"
+	PermissionCoursesManageAll = "courses:manage_all"
+	PermissionCategoriesWrite  = "categories:write"
+	PermissionInscriptionsRead = "inscriptions:read"
+	PermissionCommentsModerate = "comments:moderate"
+	PermissionUsersManage      = "users:manage"
+	PermissionUsersImpersonate = "users:impersonate"
+	PermissionAuditRead        = "audit:read"
+	PermissionAPIKeysManage    = "api_keys:manage"
+
+	PermissionPrerequisitesOverride = "prerequisites:override"
+	PermissionCoursesPublish        = "courses:publish"
+)
+
+type Permission struct {
+	gorm.Model
+	Name        string `gorm:"uniqueIndex"`
+	Description string
+}
+
+type Role struct {
+	gorm.Model
+	Name        string       `gorm:"uniqueIndex"`
+	Permissions []Permission `gorm:"many2many:role_permissions"`
+}
+
+type Permissions []Permission
+type Roles []Role
+
+// DefaultPermissions are created on startup if missing.
+var DefaultPermissions = Permissions{
+	{Name: PermissionCoursesWrite, Description: "Create, update and delete courses"},
+	{Name: PermissionCoursesManageAll, Description: "Manage courses the user doesn't teach"},
+	{Name: PermissionCategoriesWrite, Description: "Create categories"},
+	{Name: PermissionInscriptionsRead, Description: "See the students enrolled in a course"},
+	{Name: PermissionCommentsModerate, Description: "Edit or remove any comment"},
+	{Name: PermissionUsersManage, Description: "Manage user accounts"},
+	{Name: PermissionUsersImpersonate, Description: "Act as another user to reproduce problems"},
+	{Name: PermissionAuditRead, Description: "Read the audit log"},
+	{Name: PermissionAPIKeysManage, Description: "Create, list and revoke API keys"},
+	{Name: PermissionPrerequisitesOverride, Description: "Enroll without meeting course prerequisites and waive them for others"},
+	{Name: PermissionCoursesPublish, Description: "Review courses and publish or archive any of them"},
+}
+
+// DefaultRolePermissions is the permission set each role starts with. Admins
+// get every permission in DefaultPermissions.
+var DefaultRolePermissions = map[string][]string{
+	RoleStudent:    {},
+	RoleInstructor: {PermissionCoursesWrite, PermissionInscriptionsRead},
+	RoleModerator:  {PermissionCommentsModerate},
+	RoleAdmin:      {},
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Session is one login of a user, on one device. Its Id is also the family of
+// the refresh tokens issued for it and the sid claim of its access tokens, so
+// ending a session ends both.
+type Session struct {
+	gorm.Model
+	Id             uuid.UUID `sql:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserId         uuid.UUID `gorm:"index"`
+	UserAgent      string
+	Ip             string
+	LastActivityAt time.Time
+	ExpiresAt      time.Time
+	EndedAt        *time.Time
+}
+
+func (model *Session) BeforeCreate(tx *gorm.DB) (err error) {
+	model.Id = uuid.New()
+	return
+}
+
+type Sessions []Session
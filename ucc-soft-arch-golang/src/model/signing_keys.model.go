@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SigningKey is an asymmetric key access tokens are signed with. A key signs
+// between ActivatesAt and RetiresAt and is published in the JWKS until
+// ExpiresAt, when no token it signed can still be valid. There is one key
+// per algorithm and rotation slot, so instances rotating at the same time
+// agree on it. PrivateKey is encrypted.
+type SigningKey struct {
+	gorm.Model
+	Kid         string    `gorm:"uniqueIndex"`
+	Algorithm   string    `gorm:"uniqueIndex:idx_signing_key_slot"`
+	ActivatesAt time.Time `gorm:"uniqueIndex:idx_signing_key_slot"`
+	RetiresAt   time.Time
+	ExpiresAt   time.Time `gorm:"index"`
+	PrivateKey  string
+}
+
+type SigningKeys []SigningKey
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TwoFactor holds a user's TOTP secret. It is created disabled during
+// enrollment and enabled once the user proves the authenticator works.
+// LastUsedStep keeps a code from being accepted twice.
+type TwoFactor struct {
+	gorm.Model
+	UserId       uuid.UUID `gorm:"uniqueIndex"`
+	Secret       string
+	Enabled      bool `gorm:"default:false"`
+	LastUsedStep int64
+}
+
+type TwoFactors []TwoFactor
+
+// RecoveryCode is a single use backup code; only its hash is stored.
+type RecoveryCode struct {
+	gorm.Model
+	UserId   uuid.UUID `gorm:"index"`
+	CodeHash string    `gorm:"uniqueIndex"`
+	UsedAt   *time.Time
+}
+
+type RecoveryCodes []RecoveryCode
+
+// TwoFactorChallenge is the short lived token handed out by the first login
+// step. It can only be exchanged for a session with a valid second factor.
+type TwoFactorChallenge struct {
+	gorm.Model
+	UserId    uuid.UUID `gorm:"index"`
+	TokenHash string    `gorm:"uniqueIndex"`
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	Attempts  int `gorm:"default:0"`
+}
+
+type TwoFactorChallenges []TwoFactorChallenge
@@ -1,6 +1,8 @@
 package model
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -10,9 +12,23 @@ type User struct {
 	Id       uuid.UUID `sql:"type:uuid;primary_key;default:gen_random_uuid()"`
 	Password string    `gorm:"password"`
 	Email    string    `gorm:"email;unique"`
-	Role     int       `gorm:"role;default:0"`
+	Role     string    `gorm:"column:role_name;index;default:student"`
 	Name     string    `gorm:"user_name"`
 	Avatar   string    `gorm:"avatar;default:https://i.postimg.cc/wTgNFWhR/profile.png"`
+	// TokenVersion is bumped to invalidate every token issued to the user.
+	TokenVersion  int  `gorm:"default:0"`
+	EmailVerified bool `gorm:"default:false"`
+	// TwoFactorRequired is set by an admin to force the user to use 2FA.
+	TwoFactorRequired bool `gorm:"default:false"`
+	// SuspendedAt is set while an admin keeps the user from signing in.
+	SuspendedAt     *time.Time
+	SuspendedReason string
+	// PasswordResetRequired blocks password logins until the user sets a
+	// new password through the reset flow.
+	PasswordResetRequired bool `gorm:"default:false"`
+	// DeletionScheduledAt is when the account will be erased, set while
+	// the user can still change their mind.
+	DeletionScheduledAt *time.Time `gorm:"index"`
 }
 
 func (u *User) BeforeCreate(tx *gorm.DB) (err error) {
@@ -21,3 +37,10 @@ func (u *User) BeforeCreate(tx *gorm.DB) (err error) {
 }
 
 type Users []User
+
+// Erased accounts keep their row, so their comments and ratings still add
+// up, but lose everything that identifies the person.
+const (
+	DeletedUserName   = "Deleted user"
+	DeletedUserAvatar = "https://i.postimg.cc/wTgNFWhR/profile.png"
+)
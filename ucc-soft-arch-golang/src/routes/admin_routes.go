@@ -0,0 +1,66 @@
+package routes
+
+import (
+	controller "github.com/Guidotss/ucc-soft-arch-golang.git/src/controllers/admin"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware/permission"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware/user"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/gin-gonic/gin"
+)
+
+func AdminRoutes(engine *gin.Engine, security *controller.SecurityController, apiKeys *controller.APIKeysController, users *controller.UsersController, tokenService services.ITokenService, permissionService services.IPermissionService) {
+	engine.GET("/admin/users",
+		user.ScopedAuthMiddleware(tokenService),
+		permission.RequirePermission(permissionService, model.PermissionUsersManage),
+		users.List)
+	engine.GET("/admin/users/:id",
+		user.ScopedAuthMiddleware(tokenService),
+		permission.RequirePermission(permissionService, model.PermissionUsersManage),
+		users.Get)
+	engine.PUT("/admin/users/:id/role",
+		user.ScopedAuthMiddleware(tokenService),
+		permission.RequirePermission(permissionService, model.PermissionUsersManage),
+		users.ChangeRole)
+	engine.POST("/admin/users/:id/suspend",
+		user.ScopedAuthMiddleware(tokenService),
+		permission.RequirePermission(permissionService, model.PermissionUsersManage),
+		users.Suspend)
+	engine.POST("/admin/users/:id/reactivate",
+		user.ScopedAuthMiddleware(tokenService),
+		permission.RequirePermission(permissionService, model.PermissionUsersManage),
+		users.Reactivate)
+	engine.POST("/admin/users/:id/password-reset",
+		user.ScopedAuthMiddleware(tokenService),
+		permission.RequirePermission(permissionService, model.PermissionUsersManage),
+		users.ForcePasswordReset)
+	engine.POST("/admin/users/:id/unlock",
+		user.ScopedAuthMiddleware(tokenService),
+		permission.RequirePermission(permissionService, model.PermissionUsersManage),
+		security.UnlockUser)
+	engine.PUT("/admin/users/:id/two-factor",
+		user.ScopedAuthMiddleware(tokenService),
+		permission.RequirePermission(permissionService, model.PermissionUsersManage),
+		security.SetTwoFactorRequired)
+	engine.GET("/admin/audit-logs",
+		user.ScopedAuthMiddleware(tokenService),
+		permission.RequirePermission(permissionService, model.PermissionAuditRead),
+		security.GetAuditLogs)
+
+	// API keys can't manage other API keys
+	engine.POST("/admin/api-keys",
+		user.AuthMiddleware(tokenService),
+		user.RequireUserSession(),
+		permission.RequirePermission(permissionService, model.PermissionAPIKeysManage),
+		apiKeys.Create)
+	engine.GET("/admin/api-keys",
+		user.AuthMiddleware(tokenService),
+		user.RequireUserSession(),
+		permission.RequirePermission(permissionService, model.PermissionAPIKeysManage),
+		apiKeys.List)
+	engine.DELETE("/admin/api-keys/:id",
+		user.AuthMiddleware(tokenService),
+		user.RequireUserSession(),
+		permission.RequirePermission(permissionService, model.PermissionAPIKeysManage),
+		apiKeys.Revoke)
+}
@@ -2,10 +2,13 @@ package routes
 
 import (
 	controller "github.com/Guidotss/ucc-soft-arch-golang.git/src/controllers/auth"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware/user"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
 	"github.com/gin-gonic/gin"
 )
 
-func AuthRoutes(engine *gin.Engine, controller *controller.AuthController) {
+func AuthRoutes(engine *gin.Engine, controller *controller.AuthController, tokenService services.ITokenService) {
 	engine.POST("/auth/refresh-token", controller.RefreshToken)
 	engine.POST("/auth/login", controller.Login)
+	engine.POST("/auth/logout", user.AuthMiddleware(tokenService), user.RequireUserSession(), controller.Logout)
 }
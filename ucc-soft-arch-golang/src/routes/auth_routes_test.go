@@ -20,7 +20,8 @@ func TestAuthRoutes_Register(t *testing.T) {
 	if err != nil {
 		t.Fatalf("open db: %v", err)
 	}
-	AuthRoutes(r, adapter.AuthAdapter(db))
+	tokenService, _ := adapter.TokenAdapter(db)
+	AuthRoutes(r, adapter.AuthAdapter(db), tokenService)
 
 	// exercise a route minimally
 	w := httptest.NewRecorder()
@@ -2,10 +2,17 @@ package routes
 
 import (
 	"github.com/Guidotss/ucc-soft-arch-golang.git/src/controllers/categories"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware/permission"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware/user"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
 	"github.com/gin-gonic/gin"
 )
 
-func CategoriesRoutes(engine *gin.Engine, controller *categories.CategoriesController) {
-	engine.POST("/category/create", controller.Create)
+func CategoriesRoutes(engine *gin.Engine, controller *categories.CategoriesController, tokenService services.ITokenService, permissionService services.IPermissionService) {
+	engine.POST("/category/create",
+		user.ScopedAuthMiddleware(tokenService),
+		permission.RequirePermission(permissionService, model.PermissionCategoriesWrite),
+		controller.Create)
 	engine.GET("/categories", controller.GetAll)
 }
@@ -1,16 +1,22 @@
 package routes
 
 import (
+	userDtos "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/users"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	github_com_glebarez_sqlite "github.com/glebarez/sqlite"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
 
 	"github.com/Guidotss/ucc-soft-arch-golang.git/src/adapter"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/config"
+	middlewares "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
 )
 
 func TestCategoryRoutes_RegisterAndGetAll(t *testing.T) {
@@ -20,7 +26,8 @@ func TestCategoryRoutes_RegisterAndGetAll(t *testing.T) {
 	if err != nil {
 		t.Fatalf("open db: %v", err)
 	}
-	CategoriesRoutes(r, adapter.CategoryAdapter(db))
+	tokenService, _ := adapter.TokenAdapter(db)
+	CategoriesRoutes(r, adapter.CategoryAdapter(db), tokenService, adapter.PermissionAdapter(db))
 
 	// call GET /categories should be 200 with empty slice
 	w := httptest.NewRecorder()
@@ -28,3 +35,32 @@ func TestCategoryRoutes_RegisterAndGetAll(t *testing.T) {
 	r.ServeHTTP(w, req)
 	require.Equal(t, http.StatusOK, w.Code)
 }
+
+func TestCategoryRoutes_CreateRequiresPermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	db, err := gorm.Open(github_com_glebarez_sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	require.NoError(t, config.Migrate(db))
+	tokenService, _ := adapter.TokenAdapter(db)
+	CategoriesRoutes(r, adapter.CategoryAdapter(db), tokenService, adapter.PermissionAdapter(db))
+
+	create := func(role string) int {
+		u := model.User{Email: role + "@ex.com", Password: "x", Name: role, Role: role}
+		require.NoError(t, db.Create(&u).Error)
+		pair, err := tokenService.IssueTokens(u.Id, u.Role, uuid.Nil, userDtos.ClientInfoDto{})
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodPost, "/category/create", strings.NewReader(`{"category_name":"go"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+pair.AccessToken)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	require.Equal(t, http.StatusForbidden, create(model.RoleStudent))
+	require.NotEqual(t, http.StatusForbidden, create(model.RoleAdmin))
+}
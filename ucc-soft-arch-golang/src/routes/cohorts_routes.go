@@ -0,0 +1,36 @@
+package routes
+
+import (
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/controllers/courses"
+	middlewareCourse "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware/course"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware/permission"
+	isLogged "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware/user"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/gin-gonic/gin"
+)
+
+func CohortsRoutes(g *gin.Engine, controller *courses.CohortController, courseService services.ICourseService, tokenService services.ITokenService, permissionService services.IPermissionService) {
+	// students pick a cohort to enroll in
+	g.GET("/courses/:id/cohorts",
+		isLogged.OptionalAuth(tokenService),
+		middlewareCourse.StaffView(courseService, permissionService, "id"),
+		middlewareCourse.RequireVisible(courseService, "id"),
+		controller.GetCohorts)
+
+	g.POST("/courses/:id/cohorts",
+		isLogged.ScopedAuthMiddleware(tokenService),
+		permission.RequirePermission(permissionService, model.PermissionCoursesWrite),
+		middlewareCourse.IsCourseInstructor(courseService, permissionService, "id"),
+		controller.CreateCohort)
+	g.PUT("/courses/:id/cohorts/:cid",
+		isLogged.ScopedAuthMiddleware(tokenService),
+		permission.RequirePermission(permissionService, model.PermissionCoursesWrite),
+		middlewareCourse.IsCourseInstructor(courseService, permissionService, "id"),
+		controller.UpdateCohort)
+	g.DELETE("/courses/:id/cohorts/:cid",
+		isLogged.ScopedAuthMiddleware(tokenService),
+		permission.RequirePermission(permissionService, model.PermissionCoursesWrite),
+		middlewareCourse.IsCourseInstructor(courseService, permissionService, "id"),
+		controller.DeleteCohort)
+}
@@ -2,11 +2,19 @@ package routes
 
 import (
 	controller "github.com/Guidotss/ucc-soft-arch-golang.git/src/controllers/comments"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware/user"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
 	"github.com/gin-gonic/gin"
 )
 
-func CommentsRoutes(g *gin.Engine, controller *controller.CommentsController) {
-	g.POST("/comment", controller.NewComment)
+func CommentsRoutes(g *gin.Engine, controller *controller.CommentsController, userService services.IUserService, tokenService services.ITokenService) {
+	g.POST("/comment",
+		user.AuthMiddleware(tokenService),
+		user.RequireVerifiedEmail(userService),
+		controller.NewComment)
 	g.GET("/comment/:id", controller.GetCourseComments)
-	g.PUT("/comment", controller.UpdateComment)
+	g.PUT("/comment",
+		user.AuthMiddleware(tokenService),
+		user.RequireVerifiedEmail(userService),
+		controller.UpdateComment)
 }
@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/controllers/courses"
+	middlewareCourse "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware/course"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware/permission"
+	isLogged "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware/user"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/gin-gonic/gin"
+)
+
+func CourseLifecycleRoutes(g *gin.Engine, controller *courses.LifecycleController, courseService services.ICourseService, tokenService services.ITokenService, permissionService services.IPermissionService) {
+	// instructors and reviewers are allowed different transitions, the
+	// service checks which one applies
+	g.POST("/courses/:id/status",
+		isLogged.AuthMiddleware(tokenService),
+		controller.ChangeStatus)
+	g.PUT("/courses/:id/schedule",
+		isLogged.ScopedAuthMiddleware(tokenService),
+		permission.RequirePermission(permissionService, model.PermissionCoursesWrite),
+		middlewareCourse.IsCourseInstructor(courseService, permissionService, "id"),
+		controller.Schedule)
+}
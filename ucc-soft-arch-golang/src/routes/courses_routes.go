@@ -2,23 +2,58 @@ package routes
 
 import (
 	"github.com/Guidotss/ucc-soft-arch-golang.git/src/controllers/courses"
-	middlewareAdmin "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware/admin"
 	middlewareCourse "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware/course"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware/permission"
+	isLogged "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware/user"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
 	"github.com/gin-gonic/gin"
 )
 
-func CoursesRoutes(g *gin.Engine, controller *courses.CourseController) {
+func CoursesRoutes(g *gin.Engine, controller *courses.CourseController, service services.ICourseService, tokenService services.ITokenService, permissionService services.IPermissionService) {
 
 	g.POST("/courses/create",
-		middlewareAdmin.AdminAuthMiddleware(),
+		isLogged.ScopedAuthMiddleware(tokenService),
+		permission.RequirePermission(permissionService, model.PermissionCoursesWrite),
 		controller.Create)
-	g.GET("/courses", controller.GetAll)
+	g.GET("/courses",
+		isLogged.OptionalAuth(tokenService),
+		middlewareCourse.StaffView(service, permissionService, ""),
+		controller.GetAll)
 	g.PUT("/courses/update/:id",
 		middlewareCourse.CheckCourseId(),
-		middlewareAdmin.AdminAuthMiddleware(),
+		isLogged.ScopedAuthMiddleware(tokenService),
+		permission.RequirePermission(permissionService, model.PermissionCoursesWrite),
+		middlewareCourse.IsCourseInstructor(service, permissionService, "id"),
 		controller.UpdateCourse)
-	g.GET("/courses/:id", controller.GetById)
+	g.GET("/courses/:id",
+		isLogged.OptionalAuth(tokenService),
+		middlewareCourse.StaffView(service, permissionService, "id"),
+		controller.GetById)
 	g.DELETE("/courses/:id",
-		middlewareAdmin.AdminAuthMiddleware(),
+		isLogged.ScopedAuthMiddleware(tokenService),
+		permission.RequirePermission(permissionService, model.PermissionCoursesWrite),
+		middlewareCourse.IsCourseInstructor(service, permissionService, "id"),
 		controller.DeleteCourse)
+
+	g.GET("/courses/:id/instructors",
+		isLogged.OptionalAuth(tokenService),
+		middlewareCourse.StaffView(service, permissionService, "id"),
+		middlewareCourse.RequireVisible(service, "id"),
+		controller.GetInstructors)
+	g.POST("/courses/:id/instructors",
+		isLogged.ScopedAuthMiddleware(tokenService),
+		permission.RequirePermission(permissionService, model.PermissionCoursesWrite),
+		middlewareCourse.IsCourseOwner(service, permissionService, "id"),
+		controller.AddInstructor)
+	g.DELETE("/courses/:id/instructors/:uid",
+		isLogged.ScopedAuthMiddleware(tokenService),
+		permission.RequirePermission(permissionService, model.PermissionCoursesWrite),
+		middlewareCourse.IsCourseOwner(service, permissionService, "id"),
+		controller.RemoveInstructor)
+
+	g.GET("/instructor/courses",
+		isLogged.ScopedAuthMiddleware(tokenService),
+		permission.RequirePermission(permissionService, model.PermissionCoursesWrite),
+		controller.GetInstructorCourses)
 }
@@ -0,0 +1,61 @@
+package routes
+
+import (
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/controllers/courses"
+	middlewareCourse "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware/course"
+	enroll "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware/enroll"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware/permission"
+	isLogged "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware/user"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/gin-gonic/gin"
+)
+
+func CurriculumRoutes(g *gin.Engine, controller *courses.CurriculumController, courseService services.ICourseService, inscriptionService services.IInscriptionService, tokenService services.ITokenService, permissionService services.IPermissionService) {
+	// the outline of a live course is public, the content of the lessons is not
+	g.GET("/courses/:id/curriculum",
+		isLogged.OptionalAuth(tokenService),
+		middlewareCourse.StaffView(courseService, permissionService, "id"),
+		middlewareCourse.RequireVisible(courseService, "id"),
+		controller.GetCurriculum)
+
+	g.PUT("/courses/:id/curriculum/order",
+		isLogged.ScopedAuthMiddleware(tokenService),
+		permission.RequirePermission(permissionService, model.PermissionCoursesWrite),
+		middlewareCourse.IsCourseInstructor(courseService, permissionService, "id"),
+		controller.Reorder)
+	g.POST("/courses/:id/sections",
+		isLogged.ScopedAuthMiddleware(tokenService),
+		permission.RequirePermission(permissionService, model.PermissionCoursesWrite),
+		middlewareCourse.IsCourseInstructor(courseService, permissionService, "id"),
+		controller.CreateSection)
+	g.PUT("/courses/:id/sections/:sid",
+		isLogged.ScopedAuthMiddleware(tokenService),
+		permission.RequirePermission(permissionService, model.PermissionCoursesWrite),
+		middlewareCourse.IsCourseInstructor(courseService, permissionService, "id"),
+		controller.UpdateSection)
+	g.DELETE("/courses/:id/sections/:sid",
+		isLogged.ScopedAuthMiddleware(tokenService),
+		permission.RequirePermission(permissionService, model.PermissionCoursesWrite),
+		middlewareCourse.IsCourseInstructor(courseService, permissionService, "id"),
+		controller.DeleteSection)
+	g.POST("/courses/:id/sections/:sid/lessons",
+		isLogged.ScopedAuthMiddleware(tokenService),
+		permission.RequirePermission(permissionService, model.PermissionCoursesWrite),
+		middlewareCourse.IsCourseInstructor(courseService, permissionService, "id"),
+		controller.CreateLesson)
+	g.GET("/courses/:id/lessons/:lid",
+		isLogged.AuthMiddleware(tokenService),
+		enroll.IsEnrolled(inscriptionService, courseService, permissionService, "id"),
+		controller.GetLesson)
+	g.PUT("/courses/:id/lessons/:lid",
+		isLogged.ScopedAuthMiddleware(tokenService),
+		permission.RequirePermission(permissionService, model.PermissionCoursesWrite),
+		middlewareCourse.IsCourseInstructor(courseService, permissionService, "id"),
+		controller.UpdateLesson)
+	g.DELETE("/courses/:id/lessons/:lid",
+		isLogged.ScopedAuthMiddleware(tokenService),
+		permission.RequirePermission(permissionService, model.PermissionCoursesWrite),
+		middlewareCourse.IsCourseInstructor(courseService, permissionService, "id"),
+		controller.DeleteLesson)
+}
@@ -0,0 +1,11 @@
+package routes
+
+import (
+	controller "github.com/Guidotss/ucc-soft-arch-golang.git/src/controllers/auth"
+	"github.com/gin-gonic/gin"
+)
+
+func EmailVerificationRoutes(engine *gin.Engine, controller *controller.EmailVerificationController) {
+	engine.GET("/auth/verify-email", controller.VerifyEmail)
+	engine.POST("/auth/verify-email/resend", controller.ResendVerification)
+}
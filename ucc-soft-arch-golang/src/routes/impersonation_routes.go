@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/controllers/admin"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware/permission"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware/user"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/gin-gonic/gin"
+)
+
+func ImpersonationRoutes(engine *gin.Engine, controller *admin.ImpersonationController, tokenService services.ITokenService, permissionService services.IPermissionService) {
+	// RequireUserSession also keeps impersonation tokens from chaining
+	engine.POST("/admin/users/:id/impersonate",
+		user.AuthMiddleware(tokenService),
+		user.RequireUserSession(),
+		permission.RequirePermission(permissionService, model.PermissionUsersImpersonate),
+		controller.Start)
+	engine.POST("/auth/impersonation/end", user.EndImpersonationAuth(tokenService), controller.End)
+}
@@ -2,30 +2,39 @@ package routes
 
 import (
 	controller "github.com/Guidotss/ucc-soft-arch-golang.git/src/controllers/inscriptions"
-	isAdmin "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware/admin"
+	middlewareCourse "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware/course"
 	enroll "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware/enroll"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware/permission"
 	isLogged "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware/user"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
 	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
 	"github.com/gin-gonic/gin"
 )
 
-func InscriptionsRoutes(g *gin.Engine, controller *controller.InscriptionController, service services.IInscriptionService) {
+func InscriptionsRoutes(g *gin.Engine, controller *controller.InscriptionController, service services.IInscriptionService, courseService services.ICourseService, prerequisiteService services.IPrerequisiteService, userService services.IUserService, tokenService services.ITokenService, permissionService services.IPermissionService) {
 
 	g.POST("/enroll",
-		isLogged.AuthMiddleware(),
+		isLogged.AuthMiddleware(tokenService),
+		isLogged.RequireVerifiedEmail(userService),
 		enroll.CourseExist(service),
 		enroll.IsAlredyEnroll(service),
+		enroll.MeetsPrerequisites(prerequisiteService, permissionService),
 		controller.Create)
+	g.DELETE("/enroll/:cid",
+		isLogged.AuthMiddleware(tokenService),
+		controller.Unenroll)
 
 	g.GET("/myCourses/",
-		isLogged.AuthMiddleware(),
+		isLogged.AuthMiddleware(tokenService),
 		controller.GetMyCourses)
 
 	g.GET("/studentsInThisCourse/:cid",
-		isAdmin.AdminAuthMiddleware(),
+		isLogged.ScopedAuthMiddleware(tokenService),
+		permission.RequirePermission(permissionService, model.PermissionInscriptionsRead),
+		middlewareCourse.IsCourseInstructor(courseService, permissionService, "cid"),
 		controller.GetMyStudents)
 
 	g.GET("/isEnrolled/:cid",
-		isLogged.AuthMiddleware(),
+		isLogged.AuthMiddleware(tokenService),
 		controller.IsAlredyEnrolled)
 }
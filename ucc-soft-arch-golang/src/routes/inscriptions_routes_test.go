@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+)
+
+func TestInscriptionsRoutes_IsEnrolled(t *testing.T) {
+	r, db := newTestApp(t)
+	user, token := signIn(t, db, model.RoleStudent)
+	course := model.Course{CourseName: "Go", CourseStatus: model.CoursePublished, CourseCapacity: 10}
+	require.NoError(t, db.Create(&course).Error)
+
+	w := get(r, "/isEnrolled/"+course.Id.String(), token)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Contains(t, w.Body.String(), "User is not enrolled")
+
+	require.NoError(t, db.Create(&model.Inscripto{UserId: user.Id, CourseId: course.Id, Status: model.EnrollmentActive}).Error)
+	w = get(r, "/isEnrolled/"+course.Id.String(), token)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), "USER_ALREADY_ENROLLED")
+	require.NotContains(t, w.Body.String(), "not enrolled")
+
+	w = get(r, "/isEnrolled/not-a-uuid", token)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,10 @@
+package routes
+
+import (
+	controller "github.com/Guidotss/ucc-soft-arch-golang.git/src/controllers/auth"
+	"github.com/gin-gonic/gin"
+)
+
+func JWKSRoutes(engine *gin.Engine, controller *controller.JWKSController) {
+	engine.GET("/.well-known/jwks.json", controller.GetJWKS)
+}
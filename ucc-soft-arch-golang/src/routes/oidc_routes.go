@@ -0,0 +1,11 @@
+package routes
+
+import (
+	controller "github.com/Guidotss/ucc-soft-arch-golang.git/src/controllers/auth"
+	"github.com/gin-gonic/gin"
+)
+
+func OIDCRoutes(engine *gin.Engine, controller *controller.OIDCController) {
+	engine.GET("/auth/oidc/login", controller.Login)
+	engine.POST("/auth/oidc/callback", controller.Callback)
+}
@@ -0,0 +1,11 @@
+package routes
+
+import (
+	controller "github.com/Guidotss/ucc-soft-arch-golang.git/src/controllers/auth"
+	"github.com/gin-gonic/gin"
+)
+
+func PasswordResetRoutes(engine *gin.Engine, controller *controller.PasswordResetController) {
+	engine.POST("/auth/forgot-password", controller.ForgotPassword)
+	engine.POST("/auth/reset-password", controller.ResetPassword)
+}
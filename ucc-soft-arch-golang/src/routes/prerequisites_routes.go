@@ -0,0 +1,38 @@
+package routes
+
+import (
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/controllers/courses"
+	middlewareCourse "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware/course"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware/permission"
+	isLogged "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware/user"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/gin-gonic/gin"
+)
+
+func PrerequisitesRoutes(g *gin.Engine, controller *courses.PrerequisitesController, courseService services.ICourseService, tokenService services.ITokenService, permissionService services.IPermissionService) {
+	g.GET("/courses/:id/prerequisites",
+		isLogged.OptionalAuth(tokenService),
+		middlewareCourse.StaffView(courseService, permissionService, "id"),
+		middlewareCourse.RequireVisible(courseService, "id"),
+		controller.GetPrerequisites)
+	g.GET("/courses/:id/prerequisites/unmet",
+		isLogged.AuthMiddleware(tokenService),
+		middlewareCourse.StaffView(courseService, permissionService, "id"),
+		middlewareCourse.RequireVisible(courseService, "id"),
+		controller.GetMyUnmetPrerequisites)
+	g.PUT("/courses/:id/prerequisites",
+		isLogged.ScopedAuthMiddleware(tokenService),
+		permission.RequirePermission(permissionService, model.PermissionCoursesWrite),
+		middlewareCourse.IsCourseInstructor(courseService, permissionService, "id"),
+		controller.SetPrerequisites)
+
+	g.PUT("/courses/:id/prerequisite-waivers/:uid",
+		isLogged.ScopedAuthMiddleware(tokenService),
+		permission.RequirePermission(permissionService, model.PermissionPrerequisitesOverride),
+		controller.GrantWaiver)
+	g.DELETE("/courses/:id/prerequisite-waivers/:uid",
+		isLogged.ScopedAuthMiddleware(tokenService),
+		permission.RequirePermission(permissionService, model.PermissionPrerequisitesOverride),
+		controller.RevokeWaiver)
+}
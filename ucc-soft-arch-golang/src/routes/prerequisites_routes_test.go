@@ -0,0 +1,11 @@
+package routes
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestPrerequisitesRoutes_ListHiddenUnlessLive(t *testing.T) {
+	requireHiddenUnlessLive(t, func(id uuid.UUID) string { return "/courses/" + id.String() + "/prerequisites" })
+}
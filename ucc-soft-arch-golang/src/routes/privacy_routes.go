@@ -0,0 +1,33 @@
+package routes
+
+import (
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/controllers/users"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware/permission"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware/user"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/gin-gonic/gin"
+)
+
+func PrivacyRoutes(engine *gin.Engine, controller *users.PrivacyController, tokenService services.ITokenService, permissionService services.IPermissionService) {
+	// API keys act for integrations, not for the person
+	engine.GET("/users/me/export", user.AuthMiddleware(tokenService), user.RequireUserSession(), controller.ExportMe)
+	engine.DELETE("/users/me", user.AuthMiddleware(tokenService), user.RequireUserSession(), controller.DeleteMe)
+	engine.POST("/users/me/deletion/cancel", user.AuthMiddleware(tokenService), user.RequireUserSession(), controller.CancelMyDeletion)
+
+	engine.GET("/admin/users/:id/export",
+		user.AuthMiddleware(tokenService),
+		user.RequireUserSession(),
+		permission.RequirePermission(permissionService, model.PermissionUsersManage),
+		controller.ExportUser)
+	engine.DELETE("/admin/users/:id",
+		user.AuthMiddleware(tokenService),
+		user.RequireUserSession(),
+		permission.RequirePermission(permissionService, model.PermissionUsersManage),
+		controller.DeleteUser)
+	engine.POST("/admin/users/:id/deletion/cancel",
+		user.AuthMiddleware(tokenService),
+		user.RequireUserSession(),
+		permission.RequirePermission(permissionService, model.PermissionUsersManage),
+		controller.CancelUserDeletion)
+}
@@ -0,0 +1,31 @@
+package routes
+
+import (
+	controller "github.com/Guidotss/ucc-soft-arch-golang.git/src/controllers/inscriptions"
+	middlewareCourse "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware/course"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware/permission"
+	isLogged "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware/user"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/gin-gonic/gin"
+)
+
+func ProgressRoutes(g *gin.Engine, controller *controller.ProgressController, courseService services.ICourseService, tokenService services.ITokenService, permissionService services.IPermissionService) {
+	g.GET("/courses/:id/progress",
+		isLogged.AuthMiddleware(tokenService),
+		controller.GetProgress)
+	g.PUT("/courses/:id/lessons/:lid/progress",
+		isLogged.AuthMiddleware(tokenService),
+		controller.RecordProgress)
+
+	g.GET("/courses/:id/completion-rules",
+		isLogged.ScopedAuthMiddleware(tokenService),
+		permission.RequirePermission(permissionService, model.PermissionCoursesWrite),
+		middlewareCourse.IsCourseInstructor(courseService, permissionService, "id"),
+		controller.GetCompletionRules)
+	g.PUT("/courses/:id/completion-rules",
+		isLogged.ScopedAuthMiddleware(tokenService),
+		permission.RequirePermission(permissionService, model.PermissionCoursesWrite),
+		middlewareCourse.IsCourseInstructor(courseService, permissionService, "id"),
+		controller.UpdateCompletionRules)
+}
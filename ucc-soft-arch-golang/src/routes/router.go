@@ -11,16 +11,49 @@ import (
 func AppRoutes(engine *gin.Engine, db *gorm.DB) {
 	// Registrar rutas de health para checks simples
 	HealthRoutes(engine.Group("/"))
+	TokenService, _ := adapter.TokenAdapter(db)
+	PermissionService := adapter.PermissionAdapter(db)
 	InscriptionController, InscriptionService := adapter.InscriptionsAdapter(db)
 	UserController, UserService := adapter.UserAdapter(db)
+	CourseController, CourseService := adapter.CourseAdapter(db)
 
-	CoursesRoutes(engine, adapter.CourseAdapter(db))
-	CategoriesRoutes(engine, adapter.CategoryAdapter(db))
-	UsersRoutes(engine, UserController, UserService)
-	AuthRoutes(engine, adapter.AuthAdapter(db))
-	InscriptionsRoutes(engine, InscriptionController, InscriptionService)
+	CoursesRoutes(engine, CourseController, CourseService, TokenService, PermissionService)
+	CourseLifecycleController, _ := adapter.CourseLifecycleAdapter(db)
+	CourseLifecycleRoutes(engine, CourseLifecycleController, CourseService, TokenService, PermissionService)
+	CurriculumController, _ := adapter.CurriculumAdapter(db)
+	CurriculumRoutes(engine, CurriculumController, CourseService, InscriptionService, TokenService, PermissionService)
+	CohortController, _ := adapter.CohortAdapter(db)
+	CohortsRoutes(engine, CohortController, CourseService, TokenService, PermissionService)
+	SearchController, _ := adapter.SearchAdapter(db)
+	SearchRoutes(engine, SearchController)
+	CategoriesRoutes(engine, adapter.CategoryAdapter(db), TokenService, PermissionService)
+	UsersRoutes(engine, UserController, UserService, TokenService, adapter.PasswordPolicyAdapter(db))
+	AuthRoutes(engine, adapter.AuthAdapter(db), TokenService)
+	OIDCRoutes(engine, adapter.OIDCAdapter(db))
+	JWKSController, _ := adapter.SigningKeyAdapter(db)
+	JWKSRoutes(engine, JWKSController)
+	TwoFactorController, _ := adapter.TwoFactorAdapter(db)
+	TwoFactorRoutes(engine, TwoFactorController, TokenService)
+	SessionsController, _ := adapter.SessionAdapter(db)
+	SessionRoutes(engine, SessionsController, TokenService)
+	PasswordResetController, _ := adapter.PasswordResetAdapter(db)
+	PasswordResetRoutes(engine, PasswordResetController)
+	EmailVerificationController, _ := adapter.EmailVerificationAdapter(db)
+	EmailVerificationRoutes(engine, EmailVerificationController)
+	PrerequisitesController, PrerequisiteService := adapter.PrerequisitesAdapter(db)
+	PrerequisitesRoutes(engine, PrerequisitesController, CourseService, TokenService, PermissionService)
+	InscriptionsRoutes(engine, InscriptionController, InscriptionService, CourseService, PrerequisiteService, UserService, TokenService, PermissionService)
+	ProgressController, _ := adapter.ProgressAdapter(db)
+	ProgressRoutes(engine, ProgressController, CourseService, TokenService, PermissionService)
 	RatingRoutes(engine, adapter.RatingAdapter(db))
-	CommentsRoutes(engine, adapter.CommentAdapter(db))
+	APIKeysController, _ := adapter.APIKeyAdapter(db)
+	PrivacyController, _ := adapter.PrivacyAdapter(db)
+	PrivacyRoutes(engine, PrivacyController, TokenService, PermissionService)
+	AdminUsersController, _ := adapter.AdminUsersAdapter(db)
+	AdminRoutes(engine, adapter.SecurityAdapter(db), APIKeysController, AdminUsersController, TokenService, PermissionService)
+	ImpersonationController, _ := adapter.ImpersonationAdapter(db)
+	ImpersonationRoutes(engine, ImpersonationController, TokenService, PermissionService)
+	CommentsRoutes(engine, adapter.CommentAdapter(db), UserService, TokenService)
 
 	engine.NoRoute(func(c *gin.Context) {
 		c.Error(errors.NewError("NOT_FOUND", "Route not found", 404))
@@ -7,10 +7,72 @@ import (
 
 	"github.com/gin-gonic/gin"
 	github_com_glebarez_sqlite "github.com/glebarez/sqlite"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/adapter"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/config"
+	userDtos "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/users"
+	middlewares "github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
 )
 
+// newTestApp wires every route over a migrated in-memory db, the way main does.
+func newTestApp(t *testing.T) (*gin.Engine, *gorm.DB) {
+	gin.SetMode(gin.TestMode)
+	db, err := gorm.Open(github_com_glebarez_sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open sqlite db: %v", err)
+	}
+	require.NoError(t, config.Migrate(db))
+	r := gin.New()
+	r.Use(middlewares.ErrorHandler())
+	AppRoutes(r, db)
+	return r, db
+}
+
+// signIn creates a user with the role and returns an access token for them.
+func signIn(t *testing.T, db *gorm.DB, role string) (model.User, string) {
+	user := model.User{Email: uuid.NewString() + "@test.com", Name: "User", Password: "x", Role: role, EmailVerified: true}
+	require.NoError(t, db.Create(&user).Error)
+	tokens, _ := adapter.TokenAdapter(db)
+	pair, err := tokens.IssueTokens(user.Id, role, uuid.Nil, userDtos.ClientInfoDto{})
+	require.NoError(t, err)
+	return user, pair.AccessToken
+}
+
+// requireHiddenUnlessLive checks that the page of a course answers 404
+// while the course isn't live, except to its instructors and to admins.
+func requireHiddenUnlessLive(t *testing.T, page func(courseId uuid.UUID) string) {
+	r, db := newTestApp(t)
+	owner, ownerToken := signIn(t, db, model.RoleInstructor)
+	_, studentToken := signIn(t, db, model.RoleStudent)
+	_, adminToken := signIn(t, db, model.RoleAdmin)
+	course := model.Course{CourseName: "Draft", CourseStatus: model.CourseDraft, CourseCapacity: 10,
+		Instructors: model.CourseInstructors{{UserId: owner.Id, IsOwner: true}}}
+	require.NoError(t, db.Create(&course).Error)
+	path := page(course.Id)
+
+	require.Equal(t, http.StatusNotFound, get(r, path, "").Code, "anonymous users")
+	require.Equal(t, http.StatusNotFound, get(r, path, studentToken).Code, "students")
+	require.Equal(t, http.StatusOK, get(r, path, ownerToken).Code, "the instructor")
+	require.Equal(t, http.StatusOK, get(r, path, adminToken).Code, "admins")
+
+	require.NoError(t, db.Model(&course).Update("course_status", model.CoursePublished).Error)
+	require.Equal(t, http.StatusOK, get(r, path, studentToken).Code, "students once it is live")
+}
+
+func get(r *gin.Engine, path string, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
 func TestAppRoutes_NoRouteReturnsError(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	r := gin.New()
@@ -0,0 +1,11 @@
+package routes
+
+import (
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/controllers/courses"
+	"github.com/gin-gonic/gin"
+)
+
+func SearchRoutes(g *gin.Engine, controller *courses.SearchController) {
+	g.GET("/courses/search", controller.Search)
+	g.GET("/courses/suggest", controller.Suggest)
+}
@@ -0,0 +1,15 @@
+package routes
+
+import (
+	controller "github.com/Guidotss/ucc-soft-arch-golang.git/src/controllers/auth"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware/user"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/gin-gonic/gin"
+)
+
+func SessionRoutes(engine *gin.Engine, controller *controller.SessionsController, tokenService services.ITokenService) {
+	engine.GET("/auth/sessions", user.AuthMiddleware(tokenService), user.RequireUserSession(), controller.List)
+	// signs out everywhere else
+	engine.DELETE("/auth/sessions", user.AuthMiddleware(tokenService), user.RequireUserSession(), controller.EndOthers)
+	engine.DELETE("/auth/sessions/:id", user.AuthMiddleware(tokenService), user.RequireUserSession(), controller.End)
+}
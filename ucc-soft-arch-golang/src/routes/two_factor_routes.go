@@ -0,0 +1,19 @@
+package routes
+
+import (
+	controller "github.com/Guidotss/ucc-soft-arch-golang.git/src/controllers/auth"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/middleware/user"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/services"
+	"github.com/gin-gonic/gin"
+)
+
+func TwoFactorRoutes(engine *gin.Engine, controller *controller.TwoFactorController, tokenService services.ITokenService) {
+	// second login step, authenticated by the challenge token
+	engine.POST("/auth/2fa/setup", controller.Setup)
+	engine.POST("/auth/2fa/verify", controller.Verify)
+
+	engine.POST("/auth/2fa/enroll", user.AuthMiddleware(tokenService), user.RequireUserSession(), controller.Enroll)
+	engine.POST("/auth/2fa/enable", user.AuthMiddleware(tokenService), user.RequireUserSession(), controller.Enable)
+	engine.POST("/auth/2fa/disable", user.AuthMiddleware(tokenService), user.RequireUserSession(), controller.Disable)
+	engine.POST("/auth/2fa/recovery-codes", user.AuthMiddleware(tokenService), user.RequireUserSession(), controller.RegenerateRecoveryCodes)
+}
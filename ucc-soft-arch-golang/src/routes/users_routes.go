@@ -7,11 +7,11 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func UsersRoutes(engine *gin.Engine, controller *users.UsersController, service services.IUserService) {
+func UsersRoutes(engine *gin.Engine, controller *users.UsersController, service services.IUserService, tokenService services.ITokenService, passwordPolicy services.IPasswordPolicyService) {
 	engine.POST("/users/register",
-		user.RegisterInputCheckMiddleware(),
+		user.RegisterInputCheckMiddleware(passwordPolicy),
 		user.IsEmailAvailable(service),
 		controller.CreateUser)
 
-	engine.PUT("/users/update", user.AuthMiddleware(), controller.UpdateUser)
+	engine.PUT("/users/update", user.AuthMiddleware(tokenService), user.RequireUserSession(), controller.UpdateUser)
 }
@@ -0,0 +1,223 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/apikeys"
+	usersClient "github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/users"
+	dtos "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/apikeys"
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/jwt"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/securetoken"
+	"github.com/google/uuid"
+)
+
+// APIKeyPrefix starts every key, so leaked keys are easy to spot in logs and
+// secret scanners.
+const APIKeyPrefix = "ucc_"
+
+// APIKeyLastUsedResolution is how stale LastUsedAt may get before a request
+// updates it.
+const APIKeyLastUsedResolution = time.Minute
+
+// Audit events recorded by the API key service.
+const (
+	AuditAPIKeyCreated = "api_key_created"
+	AuditAPIKeyRevoked = "api_key_revoked"
+)
+
+type IAPIKeyService interface {
+	// Create issues a key owned by ownerId. The key can only be scoped to
+	// permissions the owner's role has, and the plain key is only returned here.
+	Create(ownerId uuid.UUID, dto dtos.CreateAPIKeyRequestDto) (dtos.CreatedAPIKeyDto, error)
+	List() (dtos.APIKeysDto, error)
+	Revoke(id uuid.UUID, actorId uuid.UUID) error
+	// Authenticate checks a key sent in the X-API-Key header and returns claims
+	// for its owner, limited to the key scopes.
+	Authenticate(key string) (*jwt.CustomClaims, error)
+}
+
+type apiKeyService struct {
+	client      apikeys.APIKeysClient
+	users       usersClient.UsersClient
+	permissions IPermissionService
+	audit       IAuditService
+	now         func() time.Time
+}
+
+func NewAPIKeyService(client *apikeys.APIKeysClient, usersClient *usersClient.UsersClient, permissions IPermissionService, audit IAuditService) IAPIKeyService {
+	return &apiKeyService{
+		client:      *client,
+		users:       *usersClient,
+		permissions: permissions,
+		audit:       audit,
+		now:         time.Now,
+	}
+}
+
+func (s *apiKeyService) Create(ownerId uuid.UUID, dto dtos.CreateAPIKeyRequestDto) (dtos.CreatedAPIKeyDto, error) {
+	name := strings.TrimSpace(dto.Name)
+	if name == "" {
+		return dtos.CreatedAPIKeyDto{}, customError.NewError("INVALID_REQUEST", "name is required", http.StatusBadRequest)
+	}
+	if len(dto.Scopes) == 0 {
+		return dtos.CreatedAPIKeyDto{}, customError.NewError("INVALID_SCOPE", "At least one scope is required", http.StatusBadRequest)
+	}
+	if dto.ExpiresAt != nil && !dto.ExpiresAt.After(s.now()) {
+		return dtos.CreatedAPIKeyDto{}, customError.NewError("INVALID_REQUEST", "expires_at must be in the future", http.StatusBadRequest)
+	}
+
+	owner, err := s.users.FindById(ownerId)
+	if err != nil {
+		return dtos.CreatedAPIKeyDto{}, err
+	}
+	scopes := make([]string, 0, len(dto.Scopes))
+	seen := map[string]bool{}
+	for _, scope := range dto.Scopes {
+		scope = strings.TrimSpace(scope)
+		if scope == "" || seen[scope] {
+			continue
+		}
+		// also rejects unknown permissions, no role has those
+		allowed, err := s.permissions.HasPermission(owner.Role, scope)
+		if err != nil {
+			return dtos.CreatedAPIKeyDto{}, err
+		}
+		if !allowed {
+			return dtos.CreatedAPIKeyDto{}, customError.NewError("INVALID_SCOPE", fmt.Sprintf("You can't grant the scope %q", scope), http.StatusBadRequest)
+		}
+		seen[scope] = true
+		scopes = append(scopes, scope)
+	}
+
+	secret, err := securetoken.Generate(securetoken.DefaultSize)
+	if err != nil {
+		return dtos.CreatedAPIKeyDto{}, customError.NewError("TOKEN_SIGNING_ERROR", "Could not generate API key", http.StatusInternalServerError)
+	}
+	prefix := APIKeyPrefix + securetoken.Hash(secret)[:8]
+	key := prefix + "." + secret
+
+	created, err := s.client.Create(model.APIKey{
+		Name:      name,
+		Prefix:    prefix,
+		KeyHash:   securetoken.Hash(key),
+		OwnerId:   ownerId,
+		Scopes:    strings.Join(scopes, ","),
+		ExpiresAt: dto.ExpiresAt,
+	})
+	if err != nil {
+		return dtos.CreatedAPIKeyDto{}, err
+	}
+	err = s.audit.Record(model.AuditLog{
+		Event:   AuditAPIKeyCreated,
+		ActorId: ownerId,
+		UserId:  ownerId,
+		Details: fmt.Sprintf("key=%s scopes=%s", prefix, created.Scopes),
+	})
+	if err != nil {
+		return dtos.CreatedAPIKeyDto{}, err
+	}
+	return dtos.CreatedAPIKeyDto{Key: key, APIKey: apiKeyDto(created)}, nil
+}
+
+func (s *apiKeyService) List() (dtos.APIKeysDto, error) {
+	keys, err := s.client.List(uuid.Nil)
+	if err != nil {
+		return nil, err
+	}
+	result := make(dtos.APIKeysDto, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, apiKeyDto(key))
+	}
+	return result, nil
+}
+
+func (s *apiKeyService) Revoke(id uuid.UUID, actorId uuid.UUID) error {
+	key, err := s.client.FindById(id)
+	if err != nil {
+		return err
+	}
+	revoked, err := s.client.Revoke(id, s.now())
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return customError.NewError("API_KEY_REVOKED", "API key was already revoked", http.StatusConflict)
+	}
+	return s.audit.Record(model.AuditLog{
+		Event:   AuditAPIKeyRevoked,
+		ActorId: actorId,
+		UserId:  key.OwnerId,
+		Details: fmt.Sprintf("key=%s", key.Prefix),
+	})
+}
+
+func (s *apiKeyService) Authenticate(raw string) (*jwt.CustomClaims, error) {
+	invalid := customError.NewError("INVALID_API_KEY", "Invalid API key", http.StatusUnauthorized)
+	if !strings.HasPrefix(raw, APIKeyPrefix) {
+		return nil, invalid
+	}
+	key, err := s.client.FindByHash(securetoken.Hash(raw))
+	if err != nil {
+		if isNotFound(err) {
+			return nil, invalid
+		}
+		return nil, err
+	}
+	now := s.now()
+	if key.RevokedAt != nil {
+		return nil, customError.NewError("API_KEY_REVOKED", "API key has been revoked", http.StatusUnauthorized)
+	}
+	if key.ExpiresAt != nil && !now.Before(*key.ExpiresAt) {
+		return nil, customError.NewError("API_KEY_EXPIRED", "API key expired", http.StatusUnauthorized)
+	}
+
+	// the role is read on every request so a demoted owner can't keep
+	// using scopes it lost
+	owner, err := s.users.FindById(key.OwnerId)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, invalid
+		}
+		return nil, err
+	}
+	if owner.SuspendedAt != nil {
+		return nil, accountSuspended()
+	}
+
+	if err := s.client.TouchLastUsed(key.Id, now, now.Add(-APIKeyLastUsedResolution)); err != nil {
+		log.Printf("could not record API key use: %v", err)
+	}
+	return &jwt.CustomClaims{
+		Id:       owner.Id,
+		Role:     owner.Role,
+		APIKeyId: key.Id,
+		Scopes:   splitScopes(key.Scopes),
+	}, nil
+}
+
+func apiKeyDto(key model.APIKey) dtos.APIKeyDto {
+	return dtos.APIKeyDto{
+		Id:         key.Id,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		OwnerId:    key.OwnerId,
+		Scopes:     splitScopes(key.Scopes),
+		CreatedAt:  key.CreatedAt,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+	}
+}
+
+func splitScopes(scopes string) []string {
+	if scopes == "" {
+		return []string{}
+	}
+	return strings.Split(scopes, ",")
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	apiKeysClient "github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/apikeys"
+	auditClient "github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/audit"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/roles"
+	usersClient "github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/users"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/config"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/apikeys"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	github_com_glebarez_sqlite "github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupAPIKeys(t *testing.T) (*apiKeyService, *gorm.DB, model.User) {
+	db, err := gorm.Open(github_com_glebarez_sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	require.NoError(t, db.AutoMigrate(&model.User{}, &model.APIKey{}, &model.AuditLog{}, &model.Permission{}, &model.Role{}))
+	require.NoError(t, config.SeedRoles(db))
+	admin := model.User{Email: "admin@test.com", Name: "admin", Password: "x", Role: model.RoleAdmin}
+	require.NoError(t, db.Create(&admin).Error)
+
+	svc := NewAPIKeyService(
+		apiKeysClient.NewAPIKeysClient(db),
+		usersClient.NewUsersClient(db),
+		NewPermissionService(roles.NewRolesClient(db)),
+		NewAuditService(auditClient.NewAuditClient(db))).(*apiKeyService)
+	return svc, db, admin
+}
+
+func TestAPIKeyService_CreateAndAuthenticate(t *testing.T) {
+	svc, db, admin := setupAPIKeys(t)
+
+	created, err := svc.Create(admin.Id, apikeys.CreateAPIKeyRequestDto{Name: "reports", Scopes: []string{model.PermissionAuditRead}})
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(created.Key, created.APIKey.Prefix+"."))
+	require.Equal(t, []string{model.PermissionAuditRead}, created.APIKey.Scopes)
+
+	var stored model.APIKey
+	require.NoError(t, db.First(&stored, "id = ?", created.APIKey.Id).Error)
+	require.NotContains(t, stored.KeyHash, created.Key)
+	require.Nil(t, stored.LastUsedAt)
+
+	claims, err := svc.Authenticate(created.Key)
+	require.NoError(t, err)
+	require.True(t, claims.IsAPIKey())
+	require.Equal(t, admin.Id, claims.Id)
+	require.Equal(t, model.RoleAdmin, claims.Role)
+	require.Equal(t, []string{model.PermissionAuditRead}, claims.Scopes)
+
+	require.NoError(t, db.First(&stored, "id = ?", created.APIKey.Id).Error)
+	require.NotNil(t, stored.LastUsedAt)
+
+	_, err = svc.Authenticate(created.Key + "x")
+	requireErrorCode(t, err, "INVALID_API_KEY")
+	_, err = svc.Authenticate("Bearer something")
+	requireErrorCode(t, err, "INVALID_API_KEY")
+
+	var entry model.AuditLog
+	require.NoError(t, db.Where("event = ?", AuditAPIKeyCreated).First(&entry).Error)
+	require.Contains(t, entry.Details, created.APIKey.Prefix)
+}
+
+func TestAPIKeyService_CreateValidatesScopes(t *testing.T) {
+	svc, db, admin := setupAPIKeys(t)
+
+	_, err := svc.Create(admin.Id, apikeys.CreateAPIKeyRequestDto{Name: "empty"})
+	requireErrorCode(t, err, "INVALID_SCOPE")
+	_, err = svc.Create(admin.Id, apikeys.CreateAPIKeyRequestDto{Name: "unknown", Scopes: []string{"everything"}})
+	requireErrorCode(t, err, "INVALID_SCOPE")
+	past := time.Now().Add(-time.Hour)
+	_, err = svc.Create(admin.Id, apikeys.CreateAPIKeyRequestDto{Name: "old", Scopes: []string{model.PermissionAuditRead}, ExpiresAt: &past})
+	requireErrorCode(t, err, "INVALID_REQUEST")
+
+	// owners can't grant what their role doesn't have
+	instructor := model.User{Email: "teacher@test.com", Name: "teacher", Password: "x", Role: model.RoleInstructor}
+	require.NoError(t, db.Create(&instructor).Error)
+	_, err = svc.Create(instructor.Id, apikeys.CreateAPIKeyRequestDto{Name: "audit", Scopes: []string{model.PermissionAuditRead}})
+	requireErrorCode(t, err, "INVALID_SCOPE")
+	_, err = svc.Create(instructor.Id, apikeys.CreateAPIKeyRequestDto{Name: "lms", Scopes: []string{model.PermissionInscriptionsRead}})
+	require.NoError(t, err)
+}
+
+func TestAPIKeyService_RevokedAndExpiredKeys(t *testing.T) {
+	svc, _, admin := setupAPIKeys(t)
+	expiresAt := time.Now().Add(time.Hour)
+	created, err := svc.Create(admin.Id, apikeys.CreateAPIKeyRequestDto{Name: "lms", Scopes: []string{model.PermissionInscriptionsRead}, ExpiresAt: &expiresAt})
+	require.NoError(t, err)
+
+	svc.now = func() time.Time { return expiresAt.Add(time.Second) }
+	_, err = svc.Authenticate(created.Key)
+	requireErrorCode(t, err, "API_KEY_EXPIRED")
+
+	svc.now = time.Now
+	require.NoError(t, svc.Revoke(created.APIKey.Id, admin.Id))
+	_, err = svc.Authenticate(created.Key)
+	requireErrorCode(t, err, "API_KEY_REVOKED")
+	requireErrorCode(t, svc.Revoke(created.APIKey.Id, admin.Id), "API_KEY_REVOKED")
+	requireErrorCode(t, svc.Revoke(uuid.New(), admin.Id), "NOT_FOUND")
+
+	keys, err := svc.List()
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	require.NotNil(t, keys[0].RevokedAt)
+}
+
+func TestAPIKeyService_UsesOwnersCurrentRole(t *testing.T) {
+	svc, db, admin := setupAPIKeys(t)
+	created, err := svc.Create(admin.Id, apikeys.CreateAPIKeyRequestDto{Name: "reports", Scopes: []string{model.PermissionAuditRead}})
+	require.NoError(t, err)
+
+	require.NoError(t, db.Model(&model.User{}).Where("id = ?", admin.Id).Update("role_name", model.RoleStudent).Error)
+	claims, err := svc.Authenticate(created.Key)
+	require.NoError(t, err)
+	require.Equal(t, model.RoleStudent, claims.Role)
+}
+
+func TestAPIKeyService_RejectsSuspendedOwner(t *testing.T) {
+	svc, db, admin := setupAPIKeys(t)
+	created, err := svc.Create(admin.Id, apikeys.CreateAPIKeyRequestDto{Name: "reports", Scopes: []string{model.PermissionAuditRead}})
+	require.NoError(t, err)
+
+	require.NoError(t, db.Model(&model.User{}).Where("id = ?", admin.Id).Update("suspended_at", time.Now()).Error)
+	_, err = svc.Authenticate(created.Key)
+	requireErrorCode(t, err, "ACCOUNT_SUSPENDED")
+}
@@ -0,0 +1,35 @@
+package services
+
+import (
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/audit"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/google/uuid"
+)
+
+// DefaultAuditListLimit caps how many audit entries List returns.
+const DefaultAuditListLimit = 100
+
+type IAuditService interface {
+	Record(entry model.AuditLog) error
+	List(event string, userId uuid.UUID, limit int) (model.AuditLogs, error)
+}
+
+type auditService struct {
+	client audit.AuditClient
+}
+
+func NewAuditService(client *audit.AuditClient) IAuditService {
+	return &auditService{client: *client}
+}
+
+func (a *auditService) Record(entry model.AuditLog) error {
+	_, err := a.client.Create(entry)
+	return err
+}
+
+func (a *auditService) List(event string, userId uuid.UUID, limit int) (model.AuditLogs, error) {
+	if limit <= 0 || limit > DefaultAuditListLimit {
+		limit = DefaultAuditListLimit
+	}
+	return a.client.List(event, userId, limit)
+}
@@ -0,0 +1,33 @@
+package services
+
+import (
+	"testing"
+
+	github_com_glebarez_sqlite "github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	auditClient "github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/audit"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+)
+
+func TestAuditService_RecordAndList(t *testing.T) {
+	db, err := gorm.Open(github_com_glebarez_sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	require.NoError(t, db.AutoMigrate(&model.AuditLog{}))
+	svc := NewAuditService(auditClient.NewAuditClient(db))
+
+	for i := 0; i < DefaultAuditListLimit+5; i++ {
+		require.NoError(t, svc.Record(model.AuditLog{Event: "test", UserId: uuid.Nil}))
+	}
+	entries, err := svc.List("test", uuid.Nil, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, DefaultAuditListLimit)
+
+	entries, err = svc.List("test", uuid.Nil, 5)
+	require.NoError(t, err)
+	require.Len(t, entries, 5)
+}
@@ -1,82 +1,174 @@
 package services
 
 import (
-	"fmt"
+	"log"
+	"net/http"
+	"sync"
 
 	client "github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/users"
 	"github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/users"
 	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
-	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/bcrypt"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
 	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/jwt"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/password"
 	"github.com/google/uuid"
 )
 
 type AuthService struct {
-	userService IUserService
-	client      client.UsersClient
+	userService  IUserService
+	client       client.UsersClient
+	tokenService ITokenService
+	revocation   IRevocationService
+	throttle     ILoginThrottleService
+	twoFactor    ITwoFactorService
+}
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     string
+)
+
+// compareWithDummy spends the same time as a real password check so unknown
+// emails can't be told apart by response time.
+func compareWithDummy(plain string) {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = password.Hash("dummy-password")
+	})
+	password.Compare(plain, dummyHash)
 }
 
 type IAuthService interface {
-	RefreshToken(token string) (users.GetUserDto, string, error)
-	Login(loginDto users.LoginRequestDto) (users.GetUserDto, string, error)
+	RefreshToken(refreshToken string, client users.ClientInfoDto) (users.GetUserDto, users.TokenPairDto, error)
+	// Login checks the password. When the user has to pass a second factor
+	// the result only carries a challenge for ITwoFactorService.VerifyChallenge.
+	Login(loginDto users.LoginRequestDto) (users.LoginResultDto, error)
+	// Logout revokes the presented access token and ends its session. The
+	// refresh token, when given, is revoked as well.
+	Logout(claims *jwt.CustomClaims, refreshToken string) error
 }
 
-func NewAuthService(userService *IUserService, client *client.UsersClient) IAuthService {
+func NewAuthService(userService *IUserService, client *client.UsersClient, tokenService ITokenService, revocation IRevocationService, throttle ILoginThrottleService, twoFactor ITwoFactorService) IAuthService {
 	return &AuthService{
-		userService: *userService,
-		client:      *client,
+		userService:  *userService,
+		client:       *client,
+		tokenService: tokenService,
+		revocation:   revocation,
+		throttle:     throttle,
+		twoFactor:    twoFactor,
 	}
 }
 
-func (a *AuthService) RefreshToken(token string) (users.GetUserDto, string, error) {
-	claims, err := jwt.VerifyToken(token)
+func (a *AuthService) RefreshToken(refreshToken string, client users.ClientInfoDto) (users.GetUserDto, users.TokenPairDto, error) {
+	stored, err := a.tokenService.ConsumeRefreshToken(refreshToken)
 	if err != nil {
-		return users.GetUserDto{}, "", customError.NewError("INVALID TOKEN", "Invalid token", 401)
+		return users.GetUserDto{}, users.TokenPairDto{}, err
 	}
 
-	fmt.Println(claims)
-	id, err := uuid.Parse(claims["id"].(string))
+	checkUser, err := a.userService.GetUserById(stored.UserId)
 	if err != nil {
-		return users.GetUserDto{}, "", customError.NewError("INVALID ID", "Invalid ID", 401)
+		return users.GetUserDto{}, users.TokenPairDto{}, err
 	}
 
-	roleInterface := claims["role"].(float64)
-	role := int(roleInterface)
+	if checkUser.Id == uuid.Nil {
+		return users.GetUserDto{}, users.TokenPairDto{}, customError.NewError("USER NOT FOUND", "User not found", 404)
+	}
 
-	checkUser, err := a.userService.GetUserById(id)
+	// Role is read from the user again so role changes apply on the next refresh.
+	tokens, err := a.tokenService.IssueTokens(checkUser.Id, checkUser.Role, stored.FamilyId, client)
 	if err != nil {
-		return users.GetUserDto{}, "", err
+		return users.GetUserDto{}, users.TokenPairDto{}, err
 	}
 
-	if checkUser.Id == uuid.Nil {
-		return users.GetUserDto{}, "", customError.NewError("USER NOT FOUND", "User not found", 404)
+	return checkUser, tokens, nil
+}
+
+func (a *AuthService) Login(loginDto users.LoginRequestDto) (users.LoginResultDto, error) {
+	// the throttle and the lookup must agree on who is logging in
+	loginDto.Email = normalizeEmail(loginDto.Email)
+	if err := a.throttle.Check(loginDto.Email, loginDto.ClientIp); err != nil {
+		return users.LoginResultDto{}, err
 	}
 
-	newToken := jwt.SignDocument(id, role)
+	// unknown email and wrong password get the same answer
+	invalid := customError.NewError("INVALID CREDENTIALS", "Invalid credentials", 401)
+	user, err := a.client.FindByEmail(loginDto.Email)
+	if err != nil {
+		if ce, ok := err.(*customError.Error); !ok || ce.Code != "NOT_FOUND" {
+			return users.LoginResultDto{}, err
+		}
+		compareWithDummy(loginDto.Password)
+		if err := a.throttle.RegisterFailure(loginDto.Email, loginDto.ClientIp); err != nil {
+			return users.LoginResultDto{}, err
+		}
+		return users.LoginResultDto{}, invalid
+	}
 
-	return checkUser, newToken, nil
-}
+	if !password.Compare(loginDto.Password, user.Password) {
+		if err := a.throttle.RegisterFailure(loginDto.Email, loginDto.ClientIp); err != nil {
+			return users.LoginResultDto{}, err
+		}
+		return users.LoginResultDto{}, invalid
+	}
+	// only told after the password check so it doesn't leak account state
+	if user.SuspendedAt != nil {
+		return users.LoginResultDto{}, accountSuspended()
+	}
+	if user.PasswordResetRequired {
+		return users.LoginResultDto{}, customError.NewError("PASSWORD_RESET_REQUIRED", "A password reset is required, check your email", http.StatusForbidden)
+	}
+	a.upgradeHash(user.Id, loginDto.Password, user.Password)
 
-func (a *AuthService) Login(loginDto users.LoginRequestDto) (users.GetUserDto, string, error) {
-	var userDto users.GetUserDto
-	user, err := a.client.FindByEmail(loginDto.Email)
+	challenge, err := a.twoFactor.Challenge(user)
+	if err != nil {
+		return users.LoginResultDto{}, err
+	}
+	if challenge != nil {
+		// the failures are only forgotten once the second factor passes too
+		return users.LoginResultDto{Challenge: challenge}, nil
+	}
+	if err := a.throttle.RegisterSuccess(loginDto.Email, loginDto.ClientIp); err != nil {
+		return users.LoginResultDto{}, err
+	}
+
+	tokens, err := a.tokenService.IssueTokens(user.Id, user.Role, uuid.Nil, users.ClientInfoDto{Ip: loginDto.ClientIp, UserAgent: loginDto.UserAgent})
 	if err != nil {
-		return users.GetUserDto{}, "", err
+		return users.LoginResultDto{}, err
 	}
 
-	if !bcrypt.ComparePassword(loginDto.Password, user.Password) {
-		return users.GetUserDto{}, "", customError.NewError("INVALID CREDENTIALS", "Invalid credentials", 401)
+	userDto := users.GetUserDto{
+		Id:            user.Id,
+		Email:         user.Email,
+		Role:          user.Role,
+		UserName:      user.Name,
+		Avatar:        user.Avatar,
+		EmailVerified: user.EmailVerified,
 	}
 
-	newToken := jwt.SignDocument(user.Id, user.Role)
+	return users.LoginResultDto{User: userDto, Tokens: tokens}, nil
+}
 
-	userDto = users.GetUserDto{
-		Id:       user.Id,
-		Email:    user.Email,
-		Role:     user.Role,
-		UserName: user.Name,
-		Avatar:   user.Avatar,
+// upgradeHash rehashes the password with the current algorithm when the
+// stored hash is older (bcrypt) or weaker. A failure only means the upgrade
+// waits for the next login.
+func (a *AuthService) upgradeHash(userId uuid.UUID, plain string, stored string) {
+	if !password.NeedsRehash(stored) {
+		return
+	}
+	hash, err := password.Hash(plain)
+	if err == nil {
+		_, err = a.client.UpdateUser(model.User{Id: userId, Password: hash})
+	}
+	if err != nil {
+		log.Printf("could not upgrade password hash of %s: %v", userId, err)
 	}
+}
 
-	return userDto, newToken, nil
+func (a *AuthService) Logout(claims *jwt.CustomClaims, refreshToken string) error {
+	if err := a.revocation.RevokeToken(claims); err != nil {
+		return err
+	}
+	if refreshToken != "" {
+		return a.tokenService.RevokeRefreshToken(claims.Id, refreshToken)
+	}
+	return nil
 }
@@ -3,14 +3,16 @@ package services
 import (
 	"errors"
 	"testing"
+	"time"
 
 	userClient "github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/users"
 	userDtos "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/users"
 	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
-	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/bcrypt"
 	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/jwt"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/password"
 	github_com_glebarez_sqlite "github.com/glebarez/sqlite"
 	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
 )
 
@@ -29,112 +31,282 @@ func (f *fakeUserSvc) UpdateUser(_ userDtos.UpdateRequestDto) (userDtos.UpdateRe
 	return userDtos.UpdateResponseDto{}, nil
 }
 
-func setupUsersClientWithSQLite(t *testing.T) *userClient.UsersClient {
+// allowAllThrottle never blocks a login
+type allowAllThrottle struct{}
+
+func (allowAllThrottle) Check(_ string, _ string) error           { return nil }
+func (allowAllThrottle) RegisterFailure(_ string, _ string) error { return nil }
+func (allowAllThrottle) RegisterSuccess(_ string, _ string) error { return nil }
+func (allowAllThrottle) Unlock(_ uuid.UUID, _ uuid.UUID) error    { return nil }
+
+// passwordOnly never asks for a second factor
+type passwordOnly struct{ ITwoFactorService }
+
+func (passwordOnly) Challenge(_ model.User) (*userDtos.TwoFactorChallengeDto, error) { return nil, nil }
+
+func setupUsersClientWithSQLite(t *testing.T) (*userClient.UsersClient, ITokenService, IRevocationService) {
 	db, err := gorm.Open(github_com_glebarez_sqlite.Open(":memory:"), &gorm.Config{})
 	if err != nil {
 		t.Fatalf("failed to open sqlite db: %v", err)
 	}
-	if err := db.AutoMigrate(&model.User{}); err != nil {
+	if err := db.AutoMigrate(&model.User{}, &model.RefreshToken{}, &model.RevokedToken{}, &model.Session{}); err != nil {
 		t.Fatalf("automigrate: %v", err)
 	}
 	// seed one user
-	hashed, _ := bcrypt.HasPassword("secret")
-	u := model.User{Id: uuid.New(), Email: "test@example.com", Password: hashed, Name: "Tester", Role: 1}
+	hashed := legacyHash("secret")
+	u := model.User{Id: uuid.New(), Email: "test@example.com", Password: hashed, Name: "Tester", Role: "admin"}
 	if err := db.Create(&u).Error; err != nil {
 		t.Fatalf("seed user: %v", err)
 	}
-	return userClient.NewUsersClient(db)
+	tokens, revocation := newTokenStack(db)
+	return userClient.NewUsersClient(db), tokens, revocation
 }
 
 func TestAuthService_Login_Success(t *testing.T) {
-	client := setupUsersClientWithSQLite(t)
+	client, tokens, revocation := setupUsersClientWithSQLite(t)
 	// fake IUserService (not used by Login, but required by constructor)
 	var us IUserService = &fakeUserSvc{}
-	svc := NewAuthService(&us, client)
+	svc := NewAuthService(&us, client, tokens, revocation, allowAllThrottle{}, passwordOnly{})
 
 	dto := userDtos.LoginRequestDto{Email: "test@example.com", Password: "secret"}
-	user, token, err := svc.Login(dto)
+	result, err := svc.Login(dto)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if token == "" {
-		t.Fatalf("expected non-empty token")
+	user, pair := result.User, result.Tokens
+	if pair.AccessToken == "" || pair.RefreshToken == "" {
+		t.Fatalf("expected access and refresh tokens")
+	}
+	if pair.ExpiresIn <= 0 {
+		t.Fatalf("expected a positive expires_in, got %d", pair.ExpiresIn)
 	}
 	if user.Email != "test@example.com" {
 		t.Fatalf("unexpected user email: %s", user.Email)
 	}
+
+	// the email is matched the way it is throttled
+	if _, err := svc.Login(userDtos.LoginRequestDto{Email: " Test@Example.COM ", Password: "secret"}); err != nil {
+		t.Fatalf("expected a case-insensitive email match: %v", err)
+	}
+}
+
+func TestAuthService_Login_UpgradesBcryptHash(t *testing.T) {
+	client, tokens, revocation := setupUsersClientWithSQLite(t)
+	var us IUserService = &fakeUserSvc{}
+	svc := NewAuthService(&us, client, tokens, revocation, allowAllThrottle{}, passwordOnly{})
+
+	_, err := svc.Login(userDtos.LoginRequestDto{Email: "test@example.com", Password: "secret"})
+	require.NoError(t, err)
+	stored, err := client.FindByEmail("test@example.com")
+	require.NoError(t, err)
+	require.False(t, password.NeedsRehash(stored.Password), "the bcrypt hash is replaced on login")
+	require.True(t, password.Compare("secret", stored.Password))
+
+	_, err = svc.Login(userDtos.LoginRequestDto{Email: "test@example.com", Password: "secret"})
+	require.NoError(t, err)
+}
+
+func TestAuthService_Login_RejectsSuspendedAndResetRequired(t *testing.T) {
+	client, tokens, revocation := setupUsersClientWithSQLite(t)
+	var us IUserService = &fakeUserSvc{}
+	svc := NewAuthService(&us, client, tokens, revocation, allowAllThrottle{}, passwordOnly{})
+	seeded, _ := client.FindByEmail("test@example.com")
+	dto := userDtos.LoginRequestDto{Email: "test@example.com", Password: "secret"}
+
+	now := time.Now()
+	require.NoError(t, client.SetSuspended(seeded.Id, &now, "abuse"))
+	_, err := svc.Login(dto)
+	requireErrorCode(t, err, "ACCOUNT_SUSPENDED")
+	// a wrong password still gets the generic answer
+	_, err = svc.Login(userDtos.LoginRequestDto{Email: "test@example.com", Password: "wrong"})
+	requireErrorCode(t, err, "INVALID CREDENTIALS")
+
+	require.NoError(t, client.SetSuspended(seeded.Id, nil, ""))
+	require.NoError(t, client.SetPasswordResetRequired(seeded.Id, true))
+	_, err = svc.Login(dto)
+	requireErrorCode(t, err, "PASSWORD_RESET_REQUIRED")
 }
 
 func TestAuthService_Login_InvalidPassword(t *testing.T) {
-	client := setupUsersClientWithSQLite(t)
+	client, tokens, revocation := setupUsersClientWithSQLite(t)
 	var us IUserService = &fakeUserSvc{}
-	svc := NewAuthService(&us, client)
+	svc := NewAuthService(&us, client, tokens, revocation, allowAllThrottle{}, passwordOnly{})
 
 	dto := userDtos.LoginRequestDto{Email: "test@example.com", Password: "wrong"}
-	_, _, err := svc.Login(dto)
+	_, err := svc.Login(dto)
 	if err == nil {
 		t.Fatalf("expected error for invalid credentials")
 	}
 }
 
 func TestAuthService_RefreshToken_Success(t *testing.T) {
-	// Prepare a user and token
-	id := uuid.New()
-	role := 1
-	token := jwt.SignDocument(id, role)
-	returned := userDtos.GetUserDto{Id: id, Email: "u@ex.com", Role: role, UserName: "U"}
+	client, tokens, revocation := setupUsersClientWithSQLite(t)
+	seeded, _ := client.FindByEmail("test@example.com")
+	returned := userDtos.GetUserDto{Id: seeded.Id, Email: seeded.Email, Role: "instructor", UserName: "U"}
 	var us IUserService = &fakeUserSvc{user: returned, err: nil}
-	// UsersClient not used by RefreshToken
-	dummyClient := &userClient.UsersClient{}
-	svc := NewAuthService(&us, dummyClient)
+	svc := NewAuthService(&us, client, tokens, revocation, allowAllThrottle{}, passwordOnly{})
 
-	user, newToken, err := svc.RefreshToken(token)
+	result, err := svc.Login(userDtos.LoginRequestDto{Email: "test@example.com", Password: "secret"})
+	pair := result.Tokens
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	user, rotated, err := svc.RefreshToken(pair.RefreshToken, userDtos.ClientInfoDto{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if newToken == "" {
-		t.Fatalf("expected new token")
+	if rotated.AccessToken == "" || rotated.RefreshToken == "" || rotated.RefreshToken == pair.RefreshToken {
+		t.Fatalf("expected a new rotated token pair")
 	}
-	if user.Id != id {
+	if user.Id != seeded.Id {
 		t.Fatalf("expected same user id from user service")
 	}
+	claims, err := jwt.ParseToken(rotated.AccessToken)
+	if err != nil || claims.Role != "instructor" {
+		t.Fatalf("expected refreshed access token with current role, got %+v (%v)", claims, err)
+	}
+}
+
+func TestAuthService_RefreshToken_ReuseRevokesFamily(t *testing.T) {
+	client, tokens, revocation := setupUsersClientWithSQLite(t)
+	seeded, _ := client.FindByEmail("test@example.com")
+	var us IUserService = &fakeUserSvc{user: userDtos.GetUserDto{Id: seeded.Id, Role: seeded.Role}}
+	svc := NewAuthService(&us, client, tokens, revocation, allowAllThrottle{}, passwordOnly{})
+
+	result, _ := svc.Login(userDtos.LoginRequestDto{Email: "test@example.com", Password: "secret"})
+	first := result.Tokens
+	_, second, err := svc.RefreshToken(first.RefreshToken, userDtos.ClientInfoDto{})
+	if err != nil {
+		t.Fatalf("first refresh: %v", err)
+	}
+	// replaying the first token must fail and revoke the descendants too
+	if _, _, err := svc.RefreshToken(first.RefreshToken, userDtos.ClientInfoDto{}); err == nil {
+		t.Fatalf("expected reuse of a rotated refresh token to fail")
+	}
+	if _, _, err := svc.RefreshToken(second.RefreshToken, userDtos.ClientInfoDto{}); err == nil {
+		t.Fatalf("expected the whole family to be revoked after reuse")
+	}
 }
 
 func TestAuthService_RefreshToken_Invalid(t *testing.T) {
+	client, tokens, revocation := setupUsersClientWithSQLite(t)
 	var us IUserService = &fakeUserSvc{}
-	dummyClient := &userClient.UsersClient{}
-	svc := NewAuthService(&us, dummyClient)
-	_, _, err := svc.RefreshToken("invalid.token")
+	svc := NewAuthService(&us, client, tokens, revocation, allowAllThrottle{}, passwordOnly{})
+	_, _, err := svc.RefreshToken("invalid.token", userDtos.ClientInfoDto{})
 	if err == nil {
 		t.Fatalf("expected error for invalid token")
 	}
 }
 
+func TestAuthService_RefreshToken_RejectsAccessToken(t *testing.T) {
+	client, tokens, revocation := setupUsersClientWithSQLite(t)
+	var us IUserService = &fakeUserSvc{user: userDtos.GetUserDto{Id: uuid.New()}}
+	svc := NewAuthService(&us, client, tokens, revocation, allowAllThrottle{}, passwordOnly{})
+	// a signed JWT is not a refresh token and must not be re-signed
+	_, _, err := svc.RefreshToken(jwt.SignDocument(uuid.New(), "admin"), userDtos.ClientInfoDto{})
+	if err == nil {
+		t.Fatalf("expected access tokens to be rejected by refresh")
+	}
+}
+
 func TestAuthService_Login_EmailNotFound(t *testing.T) {
 	// empty DB (no user seeded)
 	db, err := gorm.Open(github_com_glebarez_sqlite.Open(":memory:"), &gorm.Config{})
 	if err != nil {
 		t.Fatalf("open db: %v", err)
 	}
-	if err := db.AutoMigrate(&model.User{}); err != nil {
+	if err := db.AutoMigrate(&model.User{}, &model.RefreshToken{}, &model.RevokedToken{}, &model.Session{}); err != nil {
 		t.Fatalf("migrate: %v", err)
 	}
 	client := userClient.NewUsersClient(db)
 	var us IUserService = &fakeUserSvc{}
-	svc := NewAuthService(&us, client)
-	_, _, err = svc.Login(userDtos.LoginRequestDto{Email: "missing@example.com", Password: "pw"})
+	tokens, revocation := newTokenStack(db)
+	svc := NewAuthService(&us, client, tokens, revocation, allowAllThrottle{}, passwordOnly{})
+	_, err = svc.Login(userDtos.LoginRequestDto{Email: "missing@example.com", Password: "pw"})
 	if err == nil {
 		t.Fatalf("expected error when email not found")
 	}
 }
 
 func TestAuthService_RefreshToken_UserLookupError(t *testing.T) {
+	client, tokens, revocation := setupUsersClientWithSQLite(t)
 	badErr := errors.New("db down")
 	var us IUserService = &fakeUserSvc{err: badErr}
-	svc := NewAuthService(&us, &userClient.UsersClient{})
-	token := jwt.SignDocument(uuid.New(), 1)
-	_, _, err := svc.RefreshToken(token)
+	svc := NewAuthService(&us, client, tokens, revocation, allowAllThrottle{}, passwordOnly{})
+	seeded, _ := client.FindByEmail("test@example.com")
+	pair, _ := tokens.IssueTokens(seeded.Id, "admin", uuid.Nil, userDtos.ClientInfoDto{})
+	_, _, err := svc.RefreshToken(pair.RefreshToken, userDtos.ClientInfoDto{})
 	if err == nil {
 		t.Fatalf("expected error when user service fails")
 	}
 }
+
+func TestAuthService_Logout_RevokesAccessAndRefreshTokens(t *testing.T) {
+	client, tokens, revocation := setupUsersClientWithSQLite(t)
+	seeded, _ := client.FindByEmail("test@example.com")
+	var us IUserService = &fakeUserSvc{user: userDtos.GetUserDto{Id: seeded.Id, Role: seeded.Role}}
+	svc := NewAuthService(&us, client, tokens, revocation, allowAllThrottle{}, passwordOnly{})
+
+	result, _ := svc.Login(userDtos.LoginRequestDto{Email: "test@example.com", Password: "secret"})
+	pair := result.Tokens
+	claims, err := tokens.VerifyAccessToken(pair.AccessToken)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if err := svc.Logout(claims, pair.RefreshToken); err != nil {
+		t.Fatalf("logout: %v", err)
+	}
+	if _, err := tokens.VerifyAccessToken(pair.AccessToken); err == nil {
+		t.Fatalf("expected access token to be revoked after logout")
+	}
+	if _, _, err := svc.RefreshToken(pair.RefreshToken, userDtos.ClientInfoDto{}); err == nil {
+		t.Fatalf("expected refresh token to be revoked after logout")
+	}
+}
+
+func TestAuthService_Logout_RejectsAnotherUsersRefreshToken(t *testing.T) {
+	client, tokens, revocation := setupUsersClientWithSQLite(t)
+	seeded, _ := client.FindByEmail("test@example.com")
+	other, err := client.Create(model.User{Email: "other@example.com", Name: "Other", Password: "x"})
+	require.NoError(t, err)
+	var us IUserService = &fakeUserSvc{user: userDtos.GetUserDto{Id: seeded.Id, Role: seeded.Role}}
+	svc := NewAuthService(&us, client, tokens, revocation, allowAllThrottle{}, passwordOnly{})
+
+	mine, err := tokens.IssueTokens(seeded.Id, seeded.Role, uuid.Nil, userDtos.ClientInfoDto{})
+	require.NoError(t, err)
+	theirs, err := tokens.IssueTokens(other.Id, other.Role, uuid.Nil, userDtos.ClientInfoDto{})
+	require.NoError(t, err)
+	claims, err := tokens.VerifyAccessToken(mine.AccessToken)
+	require.NoError(t, err)
+
+	requireErrorCode(t, svc.Logout(claims, theirs.RefreshToken), "INVALID_REFRESH_TOKEN")
+	// the other user stays signed in
+	_, err = tokens.ConsumeRefreshToken(theirs.RefreshToken)
+	require.NoError(t, err)
+}
+
+func TestAuthService_Login_LocksOutAfterFailures(t *testing.T) {
+	client, tokens, revocation := setupUsersClientWithSQLite(t)
+	require.NoError(t, client.Db.AutoMigrate(&model.LoginAttempt{}, &model.AuditLog{}))
+	throttle := newTestThrottle(client.Db)
+	var us IUserService = &fakeUserSvc{}
+	svc := NewAuthService(&us, client, tokens, revocation, throttle, passwordOnly{})
+
+	for i := 0; i < 3; i++ {
+		_, err := svc.Login(userDtos.LoginRequestDto{Email: "test@example.com", Password: "wrong", ClientIp: "10.0.0.1"})
+		require.Error(t, err)
+	}
+	// even the right password is refused while locked
+	_, err := svc.Login(userDtos.LoginRequestDto{Email: "test@example.com", Password: "secret", ClientIp: "10.0.0.2"})
+	requireErrorCode(t, err, "TOO_MANY_ATTEMPTS")
+}
+
+func TestAuthService_Login_GenericErrorForUnknownEmail(t *testing.T) {
+	client, tokens, revocation := setupUsersClientWithSQLite(t)
+	var us IUserService = &fakeUserSvc{}
+	svc := NewAuthService(&us, client, tokens, revocation, allowAllThrottle{}, passwordOnly{})
+
+	_, unknown := svc.Login(userDtos.LoginRequestDto{Email: "missing@example.com", Password: "pw"})
+	_, wrong := svc.Login(userDtos.LoginRequestDto{Email: "test@example.com", Password: "pw"})
+	require.Equal(t, wrong.Error(), unknown.Error())
+}
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	// time zones must load where the system has no zoneinfo
+	_ "time/tzdata"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/cohorts"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/inscriptos"
+	dto "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/courses"
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/google/uuid"
+)
+
+// MaxCohortName is the longest a cohort name can be.
+const MaxCohortName = 100
+
+type ICohortService interface {
+	// GetCohorts lists the cohorts of a course, the earliest first, with
+	// the seats taken in each.
+	GetCohorts(courseId uuid.UUID) ([]dto.CohortDto, error)
+	CreateCohort(courseId uuid.UUID, cohort dto.CohortRequestDto) (dto.CohortDto, error)
+	// UpdateCohort replaces a cohort. Seats added to it go to the users on
+	// its waitlist.
+	UpdateCohort(courseId uuid.UUID, cohortId uuid.UUID, cohort dto.CohortRequestDto) (dto.CohortDto, error)
+	// DeleteCohort deletes a cohort nobody enrolled in.
+	DeleteCohort(courseId uuid.UUID, cohortId uuid.UUID) error
+}
+
+type cohortService struct {
+	client       cohorts.CohortsClient
+	inscriptions inscriptos.InscriptosClient
+	now          func() time.Time
+}
+
+func NewCohortService(client *cohorts.CohortsClient, inscriptionsClient *inscriptos.InscriptosClient) ICohortService {
+	return &cohortService{client: *client, inscriptions: *inscriptionsClient, now: time.Now}
+}
+
+func (s *cohortService) GetCohorts(courseId uuid.UUID) ([]dto.CohortDto, error) {
+	if err := s.requireCourse(courseId); err != nil {
+		return nil, err
+	}
+	list, err := s.client.GetCohorts(courseId)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uuid.UUID, 0, len(list))
+	for _, cohort := range list {
+		ids = append(ids, cohort.Id)
+	}
+	taken, err := s.client.SeatsTaken(ids)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	response := make([]dto.CohortDto, 0, len(list))
+	for _, cohort := range list {
+		response = append(response, toCohortDto(cohort, taken[cohort.Id], now))
+	}
+	return response, nil
+}
+
+func (s *cohortService) CreateCohort(courseId uuid.UUID, request dto.CohortRequestDto) (dto.CohortDto, error) {
+	cohort, err := cohortFromRequest(request)
+	if err != nil {
+		return dto.CohortDto{}, err
+	}
+	if err := s.requireCourse(courseId); err != nil {
+		return dto.CohortDto{}, err
+	}
+	cohort.CourseId = courseId
+	if cohort, err = s.client.CreateCohort(cohort); err != nil {
+		return dto.CohortDto{}, err
+	}
+	return toCohortDto(cohort, 0, s.now()), nil
+}
+
+func (s *cohortService) UpdateCohort(courseId uuid.UUID, cohortId uuid.UUID, request dto.CohortRequestDto) (dto.CohortDto, error) {
+	update, err := cohortFromRequest(request)
+	if err != nil {
+		return dto.CohortDto{}, err
+	}
+	cohort, err := s.client.GetCohort(courseId, cohortId)
+	if err != nil {
+		return dto.CohortDto{}, err
+	}
+	grew := update.Capacity > cohort.Capacity
+	update.Model = cohort.Model
+	update.Id = cohort.Id
+	update.CourseId = cohort.CourseId
+	if cohort, err = s.client.UpdateCohort(update); err != nil {
+		return dto.CohortDto{}, err
+	}
+	if grew {
+		if _, err := s.inscriptions.FillCohortSeats(cohort.Id); err != nil {
+			return dto.CohortDto{}, err
+		}
+	}
+	taken, err := s.client.SeatsTaken([]uuid.UUID{cohort.Id})
+	if err != nil {
+		return dto.CohortDto{}, err
+	}
+	return toCohortDto(cohort, taken[cohort.Id], s.now()), nil
+}
+
+func (s *cohortService) DeleteCohort(courseId uuid.UUID, cohortId uuid.UUID) error {
+	cohort, err := s.client.GetCohort(courseId, cohortId)
+	if err != nil {
+		return err
+	}
+	return s.client.DeleteCohort(cohort)
+}
+
+func (s *cohortService) requireCourse(courseId uuid.UUID) error {
+	exists, err := s.client.CourseExists(courseId)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return customError.NewError("NOT_FOUND", "Course not found", http.StatusNotFound)
+	}
+	return nil
+}
+
+// cohortFromRequest validates a cohort and moves its times to UTC.
+func cohortFromRequest(request dto.CohortRequestDto) (model.Cohort, error) {
+	name := strings.TrimSpace(request.Name)
+	if name == "" || len(name) > MaxCohortName {
+		return model.Cohort{}, invalidCohort(fmt.Sprintf("name is required and can't be longer than %d characters", MaxCohortName))
+	}
+	timeZone := request.TimeZone
+	if timeZone == "" {
+		timeZone = "UTC"
+	}
+	// Local would depend on where the server runs
+	if _, err := time.LoadLocation(timeZone); err != nil || timeZone == "Local" {
+		return model.Cohort{}, invalidCohort("time_zone must be an IANA time zone such as America/Argentina/Cordoba")
+	}
+	if request.StartsAt.IsZero() || !request.EndsAt.After(request.StartsAt) {
+		return model.Cohort{}, invalidCohort("starts_at is required and ends_at must be after it")
+	}
+	if request.Capacity <= 0 {
+		return model.Cohort{}, invalidCohort("capacity must be positive")
+	}
+	opensAt, closesAt := utcTime(request.EnrollmentOpensAt), utcTime(request.EnrollmentClosesAt)
+	if closesAt != nil && closesAt.After(request.EndsAt) {
+		return model.Cohort{}, invalidCohort("enrollment_closes_at can't be after ends_at")
+	}
+	if opensAt != nil && !opensAt.Before(request.EndsAt) {
+		return model.Cohort{}, invalidCohort("enrollment_opens_at must be before ends_at")
+	}
+	if opensAt != nil && closesAt != nil && !closesAt.After(*opensAt) {
+		return model.Cohort{}, invalidCohort("enrollment_closes_at must be after enrollment_opens_at")
+	}
+	return model.Cohort{
+		Name:               name,
+		StartsAt:           request.StartsAt.UTC(),
+		EndsAt:             request.EndsAt.UTC(),
+		TimeZone:           timeZone,
+		EnrollmentOpensAt:  opensAt,
+		EnrollmentClosesAt: closesAt,
+		Capacity:           request.Capacity,
+	}, nil
+}
+
+func invalidCohort(message string) error {
+	return customError.NewError("INVALID_COHORT", message, http.StatusBadRequest)
+}
+
+func utcTime(at *time.Time) *time.Time {
+	if at == nil {
+		return nil
+	}
+	utc := at.UTC()
+	return &utc
+}
+
+// toCohortDto shows the times of a cohort in its time zone.
+func toCohortDto(cohort model.Cohort, seatsTaken int, now time.Time) dto.CohortDto {
+	location, err := time.LoadLocation(cohort.TimeZone)
+	if err != nil {
+		location = time.UTC
+	}
+	local := func(at *time.Time) *time.Time {
+		if at == nil {
+			return nil
+		}
+		in := at.In(location)
+		return &in
+	}
+	return dto.CohortDto{
+		Id:                 cohort.Id,
+		CourseId:           cohort.CourseId,
+		Name:               cohort.Name,
+		StartsAt:           cohort.StartsAt.In(location),
+		EndsAt:             cohort.EndsAt.In(location),
+		TimeZone:           cohort.TimeZone,
+		EnrollmentOpensAt:  local(cohort.EnrollmentOpensAt),
+		EnrollmentClosesAt: local(cohort.EnrollmentClosesAt),
+		Capacity:           cohort.Capacity,
+		SeatsTaken:         seatsTaken,
+		Schedule:           cohort.Schedule(now),
+		EnrollmentOpen:     cohort.EnrollmentOpen(now),
+	}
+}
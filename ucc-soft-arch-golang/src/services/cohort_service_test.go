@@ -0,0 +1,99 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/cohorts"
+	progressClient "github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/progress"
+	dto "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/courses"
+	inscription "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/inscription"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/google/uuid"
+)
+
+func TestCohortService_Validation(t *testing.T) {
+	client := setupInscriptosClientSQLite(t)
+	svc := NewCohortService(cohorts.NewCohortsClient(client.Db), client)
+	startsAt := time.Date(2030, 3, 2, 9, 0, 0, 0, time.UTC)
+	valid := dto.CohortRequestDto{Name: "March", StartsAt: startsAt, EndsAt: startsAt.Add(30 * 24 * time.Hour), Capacity: 10}
+
+	for _, broken := range []func(*dto.CohortRequestDto){
+		func(r *dto.CohortRequestDto) { r.Name = "  " },
+		func(r *dto.CohortRequestDto) { r.TimeZone = "Mars/Olympus" },
+		func(r *dto.CohortRequestDto) { r.TimeZone = "Local" },
+		func(r *dto.CohortRequestDto) { r.EndsAt = r.StartsAt },
+		func(r *dto.CohortRequestDto) { r.Capacity = 0 },
+		func(r *dto.CohortRequestDto) { closesAt := r.EndsAt.Add(time.Hour); r.EnrollmentClosesAt = &closesAt },
+		func(r *dto.CohortRequestDto) {
+			opensAt, closesAt := r.StartsAt, r.StartsAt.Add(-time.Hour)
+			r.EnrollmentOpensAt, r.EnrollmentClosesAt = &opensAt, &closesAt
+		},
+	} {
+		request := valid
+		broken(&request)
+		_, err := svc.CreateCohort(uuid.New(), request)
+		requireErrorCode(t, err, "INVALID_COHORT")
+	}
+	_, err := svc.CreateCohort(uuid.New(), valid)
+	requireErrorCode(t, err, "NOT_FOUND")
+}
+
+func TestCohortService_Cohorts(t *testing.T) {
+	client := setupInscriptosClientSQLite(t)
+	svc := NewCohortService(cohorts.NewCohortsClient(client.Db), client)
+	cat := model.Category{CategoryName: "Cloud"}
+	require.NoError(t, client.Db.Create(&cat).Error)
+	course := model.Course{CourseName: "Azure 101", CourseCapacity: 50, CourseStatus: model.CoursePublished, CategoryID: cat.Id}
+	require.NoError(t, client.Db.Create(&course).Error)
+	cordoba, err := time.LoadLocation("America/Argentina/Cordoba")
+	require.NoError(t, err)
+	startsAt := time.Now().In(cordoba).Add(24 * time.Hour).Truncate(time.Second)
+
+	created, err := svc.CreateCohort(course.Id, dto.CohortRequestDto{
+		Name:     "Next week",
+		StartsAt: startsAt,
+		EndsAt:   startsAt.Add(14 * 24 * time.Hour),
+		TimeZone: "America/Argentina/Cordoba",
+		Capacity: 1,
+	})
+	require.NoError(t, err)
+	require.Equal(t, model.CohortUpcoming, created.Schedule)
+	require.True(t, created.EnrollmentOpen)
+	require.Equal(t, "America/Argentina/Cordoba", created.StartsAt.Location().String())
+
+	// enrolling targets the cohort and takes its seats
+	inscriptions := NewInscriptionService(client, progressClient.NewProgressClient(client.Db))
+	alice := seedUser(t, client.Db, "a@b.com", "Alice")
+	bob := seedUser(t, client.Db, "b@b.com", "Bob")
+	_, err = inscriptions.Enroll(inscription.EnrollRequestResponseDto{CourseId: course.Id, UserId: alice.Id})
+	requireErrorCode(t, err, "COHORT_REQUIRED")
+	first, err := inscriptions.Enroll(inscription.EnrollRequestResponseDto{CourseId: course.Id, CohortId: &created.Id, UserId: alice.Id})
+	require.NoError(t, err)
+	require.Equal(t, created.Id, *first.CohortId)
+	second, err := inscriptions.Enroll(inscription.EnrollRequestResponseDto{CourseId: course.Id, CohortId: &created.Id, UserId: bob.Id})
+	require.NoError(t, err)
+	require.Equal(t, model.EnrollmentWaitlisted, second.Status)
+
+	list, err := svc.GetCohorts(course.Id)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.Equal(t, 1, list[0].SeatsTaken)
+	require.True(t, list[0].StartsAt.Equal(startsAt))
+
+	// a bigger cohort lets the users waiting in
+	updated, err := svc.UpdateCohort(course.Id, created.Id, dto.CohortRequestDto{
+		Name:     "Next week",
+		StartsAt: startsAt,
+		EndsAt:   startsAt.Add(14 * 24 * time.Hour),
+		TimeZone: "America/Argentina/Cordoba",
+		Capacity: 2,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, updated.SeatsTaken)
+
+	requireErrorCode(t, svc.DeleteCohort(course.Id, created.Id), "COHORT_HAS_ENROLLMENTS")
+	requireErrorCode(t, svc.DeleteCohort(course.Id, uuid.New()), "COHORT_NOT_FOUND")
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/courses"
+	dto "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/courses"
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/jwt"
+	"github.com/google/uuid"
+)
+
+type ICourseLifecycleService interface {
+	// ChangeStatus moves a course along model.CourseTransitions. Instructors
+	// send their courses to review and archive them; reviewers publish them
+	// or send them back.
+	ChangeStatus(courseId uuid.UUID, status string, claims *jwt.CustomClaims) (dto.CourseLifecycleDto, error)
+	// Schedule sets when a published course shows up in the catalog and when
+	// it stops showing.
+	Schedule(courseId uuid.UUID, schedule dto.CourseScheduleDto) (dto.CourseLifecycleDto, error)
+}
+
+type courseLifecycleService struct {
+	client      courses.CourseClient
+	permissions IPermissionService
+}
+
+func NewCourseLifecycleService(client *courses.CourseClient, permissions IPermissionService) ICourseLifecycleService {
+	return &courseLifecycleService{client: *client, permissions: permissions}
+}
+
+func (s *courseLifecycleService) ChangeStatus(courseId uuid.UUID, status string, claims *jwt.CustomClaims) (dto.CourseLifecycleDto, error) {
+	if !slices.Contains(model.CourseStatuses, status) {
+		return dto.CourseLifecycleDto{}, customError.NewError("INVALID_STATUS", "status must be one of draft, in_review, published or archived", http.StatusBadRequest)
+	}
+	course, err := s.client.GetLifecycle(courseId)
+	if err != nil {
+		return dto.CourseLifecycleDto{}, err
+	}
+	actors, ok := model.CourseTransitions[course.CourseStatus][status]
+	if !ok {
+		return dto.CourseLifecycleDto{}, customError.NewError("INVALID_TRANSITION", "A "+course.CourseStatus+" course can't become "+status, http.StatusConflict)
+	}
+	allowed := false
+	for _, actor := range actors {
+		if allowed, err = s.actsAs(actor, courseId, claims); err != nil {
+			return dto.CourseLifecycleDto{}, err
+		}
+		if allowed {
+			break
+		}
+	}
+	if !allowed {
+		return dto.CourseLifecycleDto{}, customError.NewError("FORBIDDEN", "You can't make this course "+status, http.StatusForbidden)
+	}
+
+	changed, err := s.client.SetStatus(courseId, course.CourseStatus, status)
+	if err != nil {
+		return dto.CourseLifecycleDto{}, err
+	}
+	if !changed {
+		return dto.CourseLifecycleDto{}, customError.NewError("STATUS_CHANGED", "The course status changed meanwhile, try again", http.StatusConflict)
+	}
+	course.CourseStatus = status
+	return toLifecycleDto(course), nil
+}
+
+func (s *courseLifecycleService) Schedule(courseId uuid.UUID, schedule dto.CourseScheduleDto) (dto.CourseLifecycleDto, error) {
+	if schedule.PublishAt != nil && schedule.UnpublishAt != nil && !schedule.UnpublishAt.After(*schedule.PublishAt) {
+		return dto.CourseLifecycleDto{}, customError.NewError("INVALID_SCHEDULE", "unpublish_at must be after publish_at", http.StatusBadRequest)
+	}
+	course, err := s.client.GetLifecycle(courseId)
+	if err != nil {
+		return dto.CourseLifecycleDto{}, err
+	}
+	if err := s.client.SetSchedule(courseId, schedule.PublishAt, schedule.UnpublishAt); err != nil {
+		return dto.CourseLifecycleDto{}, err
+	}
+	course.PublishAt = schedule.PublishAt
+	course.UnpublishAt = schedule.UnpublishAt
+	return toLifecycleDto(course), nil
+}
+
+// actsAs reports whether the user may act on the course as actor.
+func (s *courseLifecycleService) actsAs(actor string, courseId uuid.UUID, claims *jwt.CustomClaims) (bool, error) {
+	if actor == model.CourseActorReviewer {
+		return s.permissions.Allows(claims, model.PermissionCoursesPublish)
+	}
+	manageAll, err := s.permissions.Allows(claims, model.PermissionCoursesManageAll)
+	if err != nil || manageAll {
+		return manageAll, err
+	}
+	write, err := s.permissions.Allows(claims, model.PermissionCoursesWrite)
+	if err != nil || !write {
+		return false, err
+	}
+	return s.client.IsInstructor(courseId, claims.Id)
+}
+
+func toLifecycleDto(course model.Course) dto.CourseLifecycleDto {
+	return dto.CourseLifecycleDto{
+		Id:          course.Id,
+		Status:      course.CourseStatus,
+		PublishAt:   course.PublishAt,
+		UnpublishAt: course.UnpublishAt,
+	}
+}
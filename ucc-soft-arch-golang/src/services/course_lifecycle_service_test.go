@@ -0,0 +1,84 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	inscClient "github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/inscriptos"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/roles"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/config"
+	dto "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/courses"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/jwt"
+)
+
+func TestCourseLifecycleService_ChangeStatus(t *testing.T) {
+	client := setupCourseClientSQLite(t)
+	require.NoError(t, client.Db.AutoMigrate(&model.Permission{}, &model.Role{}))
+	require.NoError(t, config.SeedRoles(client.Db))
+	svc := NewCourseLifecycleService(client, NewPermissionService(roles.NewRolesClient(client.Db)))
+	courses := NewCourseService(client, inscClient.NewInscriptionClient(client.Db))
+
+	owner := model.User{Email: "owner@ex.com", Name: "Owner", Role: model.RoleInstructor}
+	require.NoError(t, client.Db.Create(&owner).Error)
+	cat := seedCategory(t, client, "Programming")
+	created, err := courses.CreateCourse(dto.CreateCoursesRequestDto{
+		CourseName: "Go", CourseDescription: "d", CategoryID: cat.Id, CourseInitDate: "2024-02-01", CourseImage: "i", OwnerId: owner.Id,
+	})
+	require.NoError(t, err)
+	instructor := jwt.NewCustomClaims(owner.Id, model.RoleInstructor)
+	stranger := jwt.NewCustomClaims(uuid.New(), model.RoleInstructor)
+	reviewer := jwt.NewCustomClaims(uuid.New(), model.RoleAdmin)
+
+	_, err = svc.ChangeStatus(created.CourseId, "live", instructor)
+	requireErrorCode(t, err, "INVALID_STATUS")
+	_, err = svc.ChangeStatus(created.CourseId, model.CoursePublished, reviewer)
+	requireErrorCode(t, err, "INVALID_TRANSITION")
+	_, err = svc.ChangeStatus(created.CourseId, model.CourseInReview, stranger)
+	requireErrorCode(t, err, "FORBIDDEN")
+	_, err = svc.ChangeStatus(uuid.New(), model.CourseInReview, instructor)
+	requireErrorCode(t, err, "NOT_FOUND")
+
+	lifecycle, err := svc.ChangeStatus(created.CourseId, model.CourseInReview, instructor)
+	require.NoError(t, err)
+	require.Equal(t, model.CourseInReview, lifecycle.Status)
+
+	// instructors can't publish their own courses
+	_, err = svc.ChangeStatus(created.CourseId, model.CoursePublished, instructor)
+	requireErrorCode(t, err, "FORBIDDEN")
+	lifecycle, err = svc.ChangeStatus(created.CourseId, model.CoursePublished, reviewer)
+	require.NoError(t, err)
+	require.Equal(t, model.CoursePublished, lifecycle.Status)
+
+	lifecycle, err = svc.ChangeStatus(created.CourseId, model.CourseArchived, instructor)
+	require.NoError(t, err)
+	require.Equal(t, model.CourseArchived, lifecycle.Status)
+}
+
+func TestCourseLifecycleService_Schedule(t *testing.T) {
+	client := setupCourseClientSQLite(t)
+	svc := NewCourseLifecycleService(client, nil)
+	course := seedCourse(t, client, seedCategory(t, client, "Programming"), "Go")
+
+	publishAt := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	unpublishAt := publishAt.Add(-time.Hour)
+	_, err := svc.Schedule(course.Id, dto.CourseScheduleDto{PublishAt: &publishAt, UnpublishAt: &unpublishAt})
+	requireErrorCode(t, err, "INVALID_SCHEDULE")
+	_, err = svc.Schedule(uuid.New(), dto.CourseScheduleDto{PublishAt: &publishAt})
+	requireErrorCode(t, err, "NOT_FOUND")
+
+	unpublishAt = publishAt.Add(30 * 24 * time.Hour)
+	lifecycle, err := svc.Schedule(course.Id, dto.CourseScheduleDto{PublishAt: &publishAt, UnpublishAt: &unpublishAt})
+	require.NoError(t, err)
+	require.Equal(t, model.CoursePublished, lifecycle.Status)
+	require.Equal(t, &publishAt, lifecycle.PublishAt)
+
+	var stored model.Course
+	require.NoError(t, client.Db.First(&stored, "id = ?", course.Id).Error)
+	require.False(t, stored.IsLive(publishAt.Add(-time.Minute)))
+	require.True(t, stored.IsLive(publishAt.Add(time.Minute)))
+	require.False(t, stored.IsLive(unpublishAt))
+}
@@ -1,28 +1,55 @@
 package services
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"time"
 
 	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/courses"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/inscriptos"
 	dto "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/courses"
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
 	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
 	"github.com/google/uuid"
 )
 
 type ICourseService interface {
 	CreateCourse(courseDto dto.CreateCoursesRequestDto) (dto.CreateCoursesResponseDto, error)
-	FindAllCourses(filter string) (dto.GetAllCourses, error)
-	FindOneCourse(id uuid.UUID) (dto.GetCourseDto, error)
+	// FindAllCourses returns one page of the catalog. Pages are read by
+	// offset (page) or by following the next/prev cursors of a page.
+	FindAllCourses(query dto.CatalogQueryDto) (dto.CoursesPageDto, error)
+	// FindOneCourse returns a course. Unless includeUnpublished is set, only
+	// courses that are live are found.
+	FindOneCourse(id uuid.UUID, includeUnpublished bool) (dto.GetCourseDto, error)
+	// IsLive reports whether everyone can see the course right now, see
+	// model.Course.IsLive.
+	IsLive(id uuid.UUID) (bool, error)
 	UpdateCourse(dto dto.UpdateRequestDto) (dto.UpdateResponseDto, error)
 	DeleteCourse(id uuid.UUID) error
+	// FindInstructorCourses lists the courses a user owns or co-teaches.
+	FindInstructorCourses(userId uuid.UUID) (dto.GetAllCourses, error)
+	IsInstructor(courseId uuid.UUID, userId uuid.UUID) (bool, error)
+	IsOwner(courseId uuid.UUID, userId uuid.UUID) (bool, error)
+	GetInstructors(courseId uuid.UUID) (dto.InstructorsDto, error)
+	AddInstructor(courseId uuid.UUID, userId uuid.UUID) error
+	RemoveInstructor(courseId uuid.UUID, userId uuid.UUID) error
 }
 
+const (
+	DefaultCoursesPageSize = 20
+	MaxCoursesPageSize     = 100
+)
+
 type courseService struct {
-	client courses.CourseClient
+	client       courses.CourseClient
+	inscriptions inscriptos.InscriptosClient
+	now          func() time.Time
 }
 
-func NewCourseService(client *courses.CourseClient) ICourseService {
-	return &courseService{client: *client}
+func NewCourseService(client *courses.CourseClient, inscriptionsClient *inscriptos.InscriptosClient) ICourseService {
+	return &courseService{client: *client, inscriptions: *inscriptionsClient, now: time.Now}
 }
 
 func (c *courseService) CreateCourse(courseDto dto.CreateCoursesRequestDto) (dto.CreateCoursesResponseDto, error) {
@@ -35,9 +62,12 @@ func (c *courseService) CreateCourse(courseDto dto.CreateCoursesRequestDto) (dto
 		CourseCapacity:    courseDto.CourseCapacity,
 		CategoryID:        courseDto.CategoryID,
 		CourseInitDate:    courseDto.CourseInitDate,
-		CourseState:       courseDto.CourseState,
+		CourseStatus:      model.CourseDraft,
 		CourseImage:       courseDto.CourseImage,
 	}
+	if courseDto.OwnerId != uuid.Nil {
+		newCourse.Instructors = model.CourseInstructors{{UserId: courseDto.OwnerId, IsOwner: true}}
+	}
 
 	createdCourse, err := c.client.Create(newCourse)
 	if err != nil {
@@ -50,50 +80,109 @@ func (c *courseService) CreateCourse(courseDto dto.CreateCoursesRequestDto) (dto
 	}, nil
 }
 
-func (c *courseService) FindAllCourses(filter string) (dto.GetAllCourses, error) {
-	courses, err := c.client.GetAll(filter)
+func (c *courseService) FindAllCourses(query dto.CatalogQueryDto) (dto.CoursesPageDto, error) {
+	if query.Sort == "" {
+		query.Sort = courses.SortNewest
+	}
+	if !courses.IsCatalogSort(query.Sort) {
+		return dto.CoursesPageDto{}, customError.NewError("INVALID_SORT", "sort must be one of newest, price, -price, rating, -rating, init_date or -init_date", http.StatusBadRequest)
+	}
+	if err := validateCatalogRanges(query); err != nil {
+		return dto.CoursesPageDto{}, err
+	}
+	if query.Limit <= 0 {
+		query.Limit = DefaultCoursesPageSize
+	}
+	if query.Limit > MaxCoursesPageSize {
+		query.Limit = MaxCoursesPageSize
+	}
+	if query.Page < 1 {
+		query.Page = 1
+	}
+
+	search := courses.CatalogQuery{
+		Text:        query.Filter,
+		CategoryId:  query.CategoryId,
+		MinPrice:    query.MinPrice,
+		MaxPrice:    query.MaxPrice,
+		MinRating:   query.MinRating,
+		Status:      query.Status,
+		Schedule:    query.Schedule,
+		ScheduleAt:  c.now(),
+		MinDuration: query.MinDuration,
+		MaxDuration: query.MaxDuration,
+		Sort:        query.Sort,
+		Limit:       query.Limit,
+	}
+	if !query.IncludeUnpublished {
+		now := c.now()
+		search.LiveAt = &now
+	}
+	if query.Cursor != "" {
+		keyset, err := decodeCourseCursor(query.Cursor, query.Sort)
+		if err != nil {
+			return dto.CoursesPageDto{}, err
+		}
+		search.Keyset = &keyset
+	} else {
+		search.Offset = (query.Page - 1) * query.Limit
+	}
+
+	result, err := c.client.Search(search)
 	if err != nil {
-		return nil, err
+		return dto.CoursesPageDto{}, err
 	}
-	var allCoursesDto dto.GetAllCourses
-	for _, result := range courses {
-		var courseDto dto.GetCourseDto
-		courseDto.Id = result.Id
-		courseDto.CategoryID = result.CategoryID
-		courseDto.CourseName = result.CourseName
-		courseDto.CourseDescription = result.CourseDescription
-		courseDto.CoursePrice = result.CoursePrice
-		courseDto.CourseDuration = result.CourseDuration
-		courseDto.CourseCapacity = result.CourseCapacity
-		courseDto.CourseInitDate = result.CourseInitDate
-		courseDto.CourseState = result.CourseState
-		courseDto.CourseImage = result.CourseImage
-		courseDto.CourseCategoryName = result.Category.CategoryName
-		courseDto.RatingAvg = result.RatingAvg
-		allCoursesDto = append(allCoursesDto, courseDto)
+	page := dto.CoursesPageDto{Courses: dto.GetAllCourses{}, Total: result.Total, Limit: query.Limit}
+	for _, course := range result.Courses {
+		page.Courses = append(page.Courses, toCourseDto(course))
 	}
-	return allCoursesDto, nil
+	if search.Keyset == nil {
+		page.Page = query.Page
+	}
+	if query.Facets {
+		facets, err := c.client.Facets(search)
+		if err != nil {
+			return dto.CoursesPageDto{}, err
+		}
+		page.Facets = toFacetsDto(facets)
+	}
+	if len(result.Courses) == 0 {
+		return page, nil
+	}
+
+	// HasMore looks past the page in the direction it was read; the other
+	// side has courses whenever this page didn't start the listing
+	backwards := search.Keyset != nil && search.Keyset.Before
+	hasNext, hasPrev := result.HasMore, search.Keyset != nil || query.Page > 1
+	if backwards {
+		hasNext, hasPrev = true, result.HasMore
+	}
+	if hasNext {
+		page.NextCursor = encodeCourseCursor(query.Sort, result.Courses[len(result.Courses)-1], false)
+	}
+	if hasPrev {
+		page.PrevCursor = encodeCourseCursor(query.Sort, result.Courses[0], true)
+	}
+	return page, nil
 }
 
-func (c *courseService) FindOneCourse(id uuid.UUID) (dto.GetCourseDto, error) {
+func (c *courseService) FindOneCourse(id uuid.UUID, includeUnpublished bool) (dto.GetCourseDto, error) {
 	result, err := c.client.GetById(id)
 	if err != nil {
 		return dto.GetCourseDto{}, err
 	}
-	return dto.GetCourseDto{
-		Id:                 result.Id,
-		CategoryID:         result.CategoryID,
-		CourseName:         result.CourseName,
-		CourseDescription:  result.CourseDescription,
-		CoursePrice:        result.CoursePrice,
-		CourseDuration:     result.CourseDuration,
-		CourseCapacity:     result.CourseCapacity,
-		CourseInitDate:     result.CourseInitDate,
-		CourseState:        result.CourseState,
-		CourseImage:        result.CourseImage,
-		CourseCategoryName: result.Category.CategoryName,
-		RatingAvg:          result.RatingAvg,
-	}, nil
+	if !includeUnpublished && !result.IsLive(c.now()) {
+		return dto.GetCourseDto{}, customError.NewError("NOT_FOUND", "Course not found", http.StatusNotFound)
+	}
+	return toCourseDto(result), nil
+}
+
+func (c *courseService) IsLive(id uuid.UUID) (bool, error) {
+	course, err := c.client.GetLifecycle(id)
+	if err != nil {
+		return false, err
+	}
+	return course.IsLive(c.now()), nil
 }
 
 func (c *courseService) UpdateCourse(newData dto.UpdateRequestDto) (dto.UpdateResponseDto, error) {
@@ -119,9 +208,6 @@ func (c *courseService) UpdateCourse(newData dto.UpdateRequestDto) (dto.UpdateRe
 	if newData.CourseInitDate != nil {
 		course.CourseInitDate = *newData.CourseInitDate
 	}
-	if newData.CourseState != nil {
-		course.CourseState = *newData.CourseState
-	}
 	if newData.CourseImage != nil {
 		course.CourseImage = *newData.CourseImage
 	}
@@ -133,6 +219,12 @@ func (c *courseService) UpdateCourse(newData dto.UpdateRequestDto) (dto.UpdateRe
 	if err != nil {
 		return dto.UpdateResponseDto{}, err
 	}
+	// a bigger course lets people in from its waitlist
+	if newData.CourseCapacity != nil {
+		if _, err := c.inscriptions.FillSeats(course.Id); err != nil {
+			return dto.UpdateResponseDto{}, err
+		}
+	}
 	return dto.UpdateResponseDto{
 		Id:                result.Id,
 		CategoryID:        result.CategoryID,
@@ -142,7 +234,7 @@ func (c *courseService) UpdateCourse(newData dto.UpdateRequestDto) (dto.UpdateRe
 		CourseDuration:    result.CourseDuration,
 		CourseCapacity:    result.CourseCapacity,
 		CourseInitDate:    result.CourseInitDate,
-		CourseState:       result.CourseState,
+		CourseStatus:      result.CourseStatus,
 		CourseImage:       result.CourseImage,
 	}, nil
 }
@@ -154,3 +246,140 @@ func (c *courseService) DeleteCourse(id uuid.UUID) error {
 	}
 	return nil
 }
+
+func (c *courseService) FindInstructorCourses(userId uuid.UUID) (dto.GetAllCourses, error) {
+	courses, err := c.client.GetByInstructor(userId)
+	if err != nil {
+		return nil, err
+	}
+	allCoursesDto := dto.GetAllCourses{}
+	for _, result := range courses {
+		allCoursesDto = append(allCoursesDto, toCourseDto(result))
+	}
+	return allCoursesDto, nil
+}
+
+func (c *courseService) IsInstructor(courseId uuid.UUID, userId uuid.UUID) (bool, error) {
+	return c.client.IsInstructor(courseId, userId)
+}
+
+func (c *courseService) IsOwner(courseId uuid.UUID, userId uuid.UUID) (bool, error) {
+	return c.client.IsOwner(courseId, userId)
+}
+
+func (c *courseService) GetInstructors(courseId uuid.UUID) (dto.InstructorsDto, error) {
+	instructors, err := c.client.GetInstructors(courseId)
+	if err != nil {
+		return nil, err
+	}
+	response := dto.InstructorsDto{}
+	for _, instructor := range instructors {
+		response = append(response, dto.InstructorDto{
+			UserId:   instructor.UserId,
+			UserName: instructor.User.Name,
+			Avatar:   instructor.User.Avatar,
+			IsOwner:  instructor.IsOwner,
+		})
+	}
+	return response, nil
+}
+
+func (c *courseService) AddInstructor(courseId uuid.UUID, userId uuid.UUID) error {
+	return c.client.AddInstructor(model.CourseInstructor{CourseId: courseId, UserId: userId})
+}
+
+func (c *courseService) RemoveInstructor(courseId uuid.UUID, userId uuid.UUID) error {
+	return c.client.RemoveInstructor(courseId, userId)
+}
+
+func toCourseDto(course model.Course) dto.GetCourseDto {
+	return dto.GetCourseDto{
+		Id:                 course.Id,
+		CategoryID:         course.CategoryID,
+		CourseName:         course.CourseName,
+		CourseDescription:  course.CourseDescription,
+		CoursePrice:        course.CoursePrice,
+		CourseDuration:     course.CourseDuration,
+		CourseCapacity:     course.CourseCapacity,
+		CourseInitDate:     course.CourseInitDate,
+		CourseStatus:       course.CourseStatus,
+		PublishAt:          course.PublishAt,
+		UnpublishAt:        course.UnpublishAt,
+		CourseImage:        course.CourseImage,
+		CourseCategoryName: course.Category.CategoryName,
+		RatingAvg:          course.RatingAvg,
+	}
+}
+
+func toFacetsDto(facets courses.CatalogFacets) *dto.CatalogFacetsDto {
+	response := &dto.CatalogFacetsDto{
+		Categories: []dto.CategoryFacetDto{},
+		Prices:     []dto.BucketFacetDto{},
+		Ratings:    []dto.BucketFacetDto{},
+		Statuses:   []dto.StatusFacetDto{},
+	}
+	for _, facet := range facets.Categories {
+		response.Categories = append(response.Categories, dto.CategoryFacetDto{CategoryId: facet.CategoryId, CategoryName: facet.CategoryName, Count: facet.Count})
+	}
+	for _, facet := range facets.Prices {
+		response.Prices = append(response.Prices, dto.BucketFacetDto{Key: facet.Key, Min: facet.Min, Max: facet.Max, Count: facet.Count})
+	}
+	for _, facet := range facets.Ratings {
+		response.Ratings = append(response.Ratings, dto.BucketFacetDto{Key: facet.Key, Min: facet.Min, Max: facet.Max, Count: facet.Count})
+	}
+	for _, facet := range facets.Statuses {
+		response.Statuses = append(response.Statuses, dto.StatusFacetDto{Status: facet.Status, Count: facet.Count})
+	}
+	return response
+}
+
+func validateCatalogRanges(query dto.CatalogQueryDto) error {
+	invalid := func(message string) error {
+		return customError.NewError("INVALID_QUERY", message, http.StatusBadRequest)
+	}
+	if (query.MinPrice != nil && *query.MinPrice < 0) || (query.MaxPrice != nil && *query.MaxPrice < 0) {
+		return invalid("Prices can't be negative")
+	}
+	if query.MinPrice != nil && query.MaxPrice != nil && *query.MinPrice > *query.MaxPrice {
+		return invalid("min_price can't be greater than max_price")
+	}
+	if query.MinRating != nil && (*query.MinRating < 0 || *query.MinRating > 5) {
+		return invalid("min_rating must be between 0 and 5")
+	}
+	if (query.MinDuration != nil && *query.MinDuration < 0) || (query.MaxDuration != nil && *query.MaxDuration < 0) {
+		return invalid("Durations can't be negative")
+	}
+	if query.MinDuration != nil && query.MaxDuration != nil && *query.MinDuration > *query.MaxDuration {
+		return invalid("min_duration can't be greater than max_duration")
+	}
+	return nil
+}
+
+// courseCursor is what a catalog cursor carries, base64 encoded JSON so it
+// stays opaque to clients.
+type courseCursor struct {
+	Sort   string    `json:"s"`
+	Value  string    `json:"v"`
+	Id     uuid.UUID `json:"id"`
+	Before bool      `json:"b,omitempty"`
+}
+
+func encodeCourseCursor(sort string, course model.Course, before bool) string {
+	raw, _ := json.Marshal(courseCursor{Sort: sort, Value: courses.SortKey(sort, course), Id: course.Id, Before: before})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeCourseCursor rejects cursors made for another sort, their value
+// wouldn't line up with the column being compared.
+func decodeCourseCursor(cursor string, sort string) (courses.Keyset, error) {
+	invalid := customError.NewError("INVALID_CURSOR", "Invalid cursor", http.StatusBadRequest)
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return courses.Keyset{}, invalid
+	}
+	var decoded courseCursor
+	if err := json.Unmarshal(raw, &decoded); err != nil || decoded.Sort != sort {
+		return courses.Keyset{}, invalid
+	}
+	return courses.Keyset{Value: decoded.Value, Id: decoded.Id, Before: decoded.Before}, nil
+}
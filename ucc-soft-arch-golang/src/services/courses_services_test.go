@@ -2,12 +2,15 @@ package services
 
 import (
 	"testing"
+	"time"
 
 	github_com_glebarez_sqlite "github.com/glebarez/sqlite"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
 
 	courseClient "github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/courses"
+	inscClient "github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/inscriptos"
 	dto "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/courses"
 	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
 )
@@ -17,7 +20,7 @@ func setupCourseClientSQLite(t *testing.T) *courseClient.CourseClient {
 	if err != nil {
 		t.Fatalf("failed to open sqlite db: %v", err)
 	}
-	require.NoError(t, db.AutoMigrate(&model.Category{}, &model.Course{}, &model.Rating{}))
+	require.NoError(t, db.AutoMigrate(&model.Category{}, &model.Course{}, &model.Rating{}, &model.User{}, &model.CourseInstructor{}))
 	return courseClient.NewCourseClient(db)
 }
 
@@ -35,7 +38,7 @@ func seedCourse(t *testing.T, c *courseClient.CourseClient, cat model.Category,
 		CourseDuration:    5,
 		CourseCapacity:    20,
 		CourseInitDate:    "2024-01-01",
-		CourseState:       true,
+		CourseStatus:      model.CoursePublished,
 		CourseImage:       "img.png",
 		CategoryID:        cat.Id,
 	}
@@ -45,7 +48,7 @@ func seedCourse(t *testing.T, c *courseClient.CourseClient, cat model.Category,
 
 func TestCourseService_Create_FindOne_Update_Delete(t *testing.T) {
 	client := setupCourseClientSQLite(t)
-	svc := NewCourseService(client)
+	svc := NewCourseService(client, inscClient.NewInscriptionClient(client.Db))
 
 	cat := seedCategory(t, client, "Programming")
 
@@ -58,11 +61,13 @@ func TestCourseService_Create_FindOne_Update_Delete(t *testing.T) {
 		CourseCapacity:    50,
 		CategoryID:        cat.Id,
 		CourseInitDate:    "2024-02-01",
-		CourseState:       true,
 		CourseImage:       "go.png",
 	})
 	require.NoError(t, err)
 	require.Equal(t, "Go 101", created.CourseName)
+	var stored model.Course
+	require.NoError(t, client.Db.First(&stored, "id = ?", created.CourseId).Error)
+	require.Equal(t, model.CourseDraft, stored.CourseStatus)
 
 	// seed a rating to satisfy GetById inner join on ratings subquery
 	require.NoError(t, client.Db.Create(&model.Rating{CourseId: created.CourseId, Rating: 5}).Error)
@@ -86,5 +91,216 @@ func TestCourseService_Create_FindOne_Update_Delete(t *testing.T) {
 	require.NoError(t, err)
 }
 
-// NOTE: We intentionally skip testing FindAllCourses due to sqlite boolean scan differences
-// causing panics in the current client implementation (expects bool, gets int64).
+func TestCourseService_FindAllCourses_Paging(t *testing.T) {
+	client := setupCourseClientSQLite(t)
+	svc := NewCourseService(client, inscClient.NewInscriptionClient(client.Db))
+	cat := seedCategory(t, client, "Programming")
+	for _, name := range []string{"A", "B", "C", "D", "E"} {
+		seedCourse(t, client, cat, name)
+	}
+	collect := func(page dto.CoursesPageDto) []string {
+		var names []string
+		for _, course := range page.Courses {
+			names = append(names, course.CourseName)
+		}
+		return names
+	}
+
+	// every course has the same price, the id keeps the order stable
+	first, err := svc.FindAllCourses(dto.CatalogQueryDto{Sort: "price", Limit: 2})
+	require.NoError(t, err)
+	require.Equal(t, int64(5), first.Total)
+	require.Equal(t, 1, first.Page)
+	require.Len(t, first.Courses, 2)
+	require.Empty(t, first.PrevCursor)
+	require.NotEmpty(t, first.NextCursor)
+
+	second, err := svc.FindAllCourses(dto.CatalogQueryDto{Sort: "price", Limit: 2, Cursor: first.NextCursor})
+	require.NoError(t, err)
+	require.Zero(t, second.Page)
+	byOffset, err := svc.FindAllCourses(dto.CatalogQueryDto{Sort: "price", Limit: 2, Page: 2})
+	require.NoError(t, err)
+	require.Equal(t, collect(byOffset), collect(second))
+	require.NotEmpty(t, byOffset.PrevCursor)
+
+	third, err := svc.FindAllCourses(dto.CatalogQueryDto{Sort: "price", Limit: 2, Cursor: second.NextCursor})
+	require.NoError(t, err)
+	require.Len(t, third.Courses, 1)
+	require.Empty(t, third.NextCursor)
+
+	back, err := svc.FindAllCourses(dto.CatalogQueryDto{Sort: "price", Limit: 2, Cursor: third.PrevCursor})
+	require.NoError(t, err)
+	require.Equal(t, collect(second), collect(back))
+	back, err = svc.FindAllCourses(dto.CatalogQueryDto{Sort: "price", Limit: 2, Cursor: back.PrevCursor})
+	require.NoError(t, err)
+	require.Equal(t, collect(first), collect(back))
+	require.Empty(t, back.PrevCursor)
+
+	// limits are clamped
+	all, err := svc.FindAllCourses(dto.CatalogQueryDto{Limit: 1000})
+	require.NoError(t, err)
+	require.Equal(t, MaxCoursesPageSize, all.Limit)
+	require.Len(t, all.Courses, 5)
+}
+
+func TestCourseService_FindAllCourses_Facets(t *testing.T) {
+	client := setupCourseClientSQLite(t)
+	svc := NewCourseService(client, inscClient.NewInscriptionClient(client.Db))
+	programming := seedCategory(t, client, "Programming")
+	design := seedCategory(t, client, "Design")
+	seedCourse(t, client, programming, "Golang")
+	seedCourse(t, client, programming, "Java")
+	seedCourse(t, client, design, "Figma")
+
+	page, err := svc.FindAllCourses(dto.CatalogQueryDto{CategoryId: &design.Id, Facets: true})
+	require.NoError(t, err)
+	require.Len(t, page.Courses, 1)
+	require.NotNil(t, page.Facets)
+	// the category facet ignores the category filter
+	require.Len(t, page.Facets.Categories, 2)
+	require.Equal(t, "Programming", page.Facets.Categories[0].CategoryName)
+	require.Equal(t, int64(2), page.Facets.Categories[0].Count)
+	// the others count only the design course
+	require.Equal(t, "0-25", page.Facets.Prices[0].Key)
+	require.Equal(t, int64(1), page.Facets.Prices[0].Count)
+	require.Equal(t, []dto.StatusFacetDto{{Status: model.CoursePublished, Count: 1}}, page.Facets.Statuses)
+
+	page, err = svc.FindAllCourses(dto.CatalogQueryDto{})
+	require.NoError(t, err)
+	require.Nil(t, page.Facets)
+}
+
+func TestCourseService_HidesUnpublishedCourses(t *testing.T) {
+	client := setupCourseClientSQLite(t)
+	svc := NewCourseService(client, inscClient.NewInscriptionClient(client.Db)).(*courseService)
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	cat := seedCategory(t, client, "Programming")
+	live := seedCourse(t, client, cat, "Live")
+	draft := seedCourse(t, client, cat, "Draft")
+	scheduled := seedCourse(t, client, cat, "Scheduled")
+	require.NoError(t, client.Db.Model(&draft).Update("course_status", model.CourseDraft).Error)
+	require.NoError(t, client.Db.Model(&scheduled).Update("publish_at", now.Add(time.Hour)).Error)
+	// GetById only finds rated courses
+	for _, course := range []model.Course{draft, scheduled} {
+		require.NoError(t, client.Db.Create(&model.Rating{CourseId: course.Id, Rating: 4}).Error)
+	}
+
+	page, err := svc.FindAllCourses(dto.CatalogQueryDto{})
+	require.NoError(t, err)
+	require.Len(t, page.Courses, 1)
+	require.Equal(t, live.Id, page.Courses[0].Id)
+
+	page, err = svc.FindAllCourses(dto.CatalogQueryDto{IncludeUnpublished: true, Status: model.CourseDraft})
+	require.NoError(t, err)
+	require.Len(t, page.Courses, 1)
+	require.Equal(t, model.CourseDraft, page.Courses[0].CourseStatus)
+
+	_, err = svc.FindOneCourse(draft.Id, false)
+	requireErrorCode(t, err, "NOT_FOUND")
+	_, err = svc.FindOneCourse(scheduled.Id, false)
+	requireErrorCode(t, err, "NOT_FOUND")
+	found, err := svc.FindOneCourse(draft.Id, true)
+	require.NoError(t, err)
+	require.Equal(t, "Draft", found.CourseName)
+
+	for id, want := range map[uuid.UUID]bool{live.Id: true, draft.Id: false, scheduled.Id: false} {
+		isLive, err := svc.IsLive(id)
+		require.NoError(t, err)
+		require.Equal(t, want, isLive)
+	}
+	_, err = svc.IsLive(uuid.New())
+	requireErrorCode(t, err, "NOT_FOUND")
+
+	now = now.Add(2 * time.Hour)
+	found, err = svc.FindOneCourse(scheduled.Id, false)
+	require.NoError(t, err)
+	require.Equal(t, "Scheduled", found.CourseName)
+	isLive, err := svc.IsLive(scheduled.Id)
+	require.NoError(t, err)
+	require.True(t, isLive)
+}
+
+func TestCourseService_UpdateCourse_FillsNewSeats(t *testing.T) {
+	client := setupCourseClientSQLite(t)
+	require.NoError(t, client.Db.AutoMigrate(&model.Inscripto{}, &model.Cohort{}))
+	inscriptions := inscClient.NewInscriptionClient(client.Db)
+	svc := NewCourseService(client, inscriptions)
+	course := seedCourse(t, client, seedCategory(t, client, "Programming"), "Go")
+	require.NoError(t, client.Db.Model(&course).Update("course_capacity", 1).Error)
+	var waiting model.User
+	for _, email := range []string{"a@ex.com", "b@ex.com"} {
+		waiting = model.User{Email: email, Name: email}
+		require.NoError(t, client.Db.Create(&waiting).Error)
+		_, err := inscriptions.Enroll(model.Inscripto{UserId: waiting.Id, CourseId: course.Id}, time.Now())
+		require.NoError(t, err)
+	}
+
+	capacity := 2
+	_, err := svc.UpdateCourse(dto.UpdateRequestDto{Id: course.Id, CourseCapacity: &capacity})
+	require.NoError(t, err)
+	enrolled, err := inscriptions.IsUserEnrolled(waiting.Id, course.Id)
+	require.NoError(t, err)
+	require.True(t, enrolled)
+}
+
+func TestCourseService_FindAllCourses_InvalidQuery(t *testing.T) {
+	client := setupCourseClientSQLite(t)
+	svc := NewCourseService(client, inscClient.NewInscriptionClient(client.Db))
+	low, high := 5.0, 1.0
+	rating := 6.0
+
+	_, err := svc.FindAllCourses(dto.CatalogQueryDto{Sort: "popularity"})
+	requireErrorCode(t, err, "INVALID_SORT")
+	_, err = svc.FindAllCourses(dto.CatalogQueryDto{MinPrice: &low, MaxPrice: &high})
+	requireErrorCode(t, err, "INVALID_QUERY")
+	_, err = svc.FindAllCourses(dto.CatalogQueryDto{MinRating: &rating})
+	requireErrorCode(t, err, "INVALID_QUERY")
+	_, err = svc.FindAllCourses(dto.CatalogQueryDto{Cursor: "%%%"})
+	requireErrorCode(t, err, "INVALID_CURSOR")
+
+	// a cursor only works with the sort it was made for
+	cursor := encodeCourseCursor("price", model.Course{}, false)
+	_, err = svc.FindAllCourses(dto.CatalogQueryDto{Sort: "-price", Cursor: cursor})
+	requireErrorCode(t, err, "INVALID_CURSOR")
+}
+
+func TestCourseService_Instructors(t *testing.T) {
+	client := setupCourseClientSQLite(t)
+	svc := NewCourseService(client, inscClient.NewInscriptionClient(client.Db))
+	cat := seedCategory(t, client, "Programming")
+
+	owner := model.User{Email: "owner@ex.com", Name: "Owner"}
+	co := model.User{Email: "co@ex.com", Name: "Co"}
+	require.NoError(t, client.Db.Create(&owner).Error)
+	require.NoError(t, client.Db.Create(&co).Error)
+
+	created, err := svc.CreateCourse(dto.CreateCoursesRequestDto{
+		CourseName: "Owned", CourseDescription: "d", CategoryID: cat.Id, CourseInitDate: "2024-02-01", CourseImage: "i", OwnerId: owner.Id,
+	})
+	require.NoError(t, err)
+
+	isOwner, err := svc.IsOwner(created.CourseId, owner.Id)
+	require.NoError(t, err)
+	require.True(t, isOwner)
+
+	require.NoError(t, svc.AddInstructor(created.CourseId, co.Id))
+	teaches, err := svc.IsInstructor(created.CourseId, co.Id)
+	require.NoError(t, err)
+	require.True(t, teaches)
+
+	instructors, err := svc.GetInstructors(created.CourseId)
+	require.NoError(t, err)
+	require.Len(t, instructors, 2)
+	require.Equal(t, "Owner", instructors[0].UserName)
+
+	mine, err := svc.FindInstructorCourses(co.Id)
+	require.NoError(t, err)
+	require.Len(t, mine, 1)
+	require.Equal(t, "Owned", mine[0].CourseName)
+
+	require.NoError(t, svc.RemoveInstructor(created.CourseId, co.Id))
+	mine, err = svc.FindInstructorCourses(co.Id)
+	require.NoError(t, err)
+	require.Empty(t, mine)
+}
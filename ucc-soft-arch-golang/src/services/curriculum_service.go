@@ -0,0 +1,244 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/curriculum"
+	dto "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/courses"
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/google/uuid"
+)
+
+// MaxCurriculumTitle is the longest a section or lesson title can be.
+const MaxCurriculumTitle = 200
+
+type ICurriculumService interface {
+	// GetCurriculum lists the sections and lessons of a course in order,
+	// without the content of the lessons.
+	GetCurriculum(courseId uuid.UUID) (dto.CurriculumDto, error)
+	CreateSection(courseId uuid.UUID, section dto.SectionRequestDto) (dto.SectionDto, error)
+	UpdateSection(courseId uuid.UUID, sectionId uuid.UUID, section dto.SectionRequestDto) (dto.SectionDto, error)
+	// DeleteSection deletes a section and every lesson in it.
+	DeleteSection(courseId uuid.UUID, sectionId uuid.UUID) error
+	// GetLesson returns a lesson with its content. Callers check the user
+	// may read it.
+	GetLesson(courseId uuid.UUID, lessonId uuid.UUID) (dto.LessonDto, error)
+	CreateLesson(courseId uuid.UUID, sectionId uuid.UUID, lesson dto.LessonRequestDto) (dto.LessonDto, error)
+	UpdateLesson(courseId uuid.UUID, lessonId uuid.UUID, lesson dto.LessonRequestDto) (dto.LessonDto, error)
+	DeleteLesson(courseId uuid.UUID, lessonId uuid.UUID) error
+	// Reorder sets the order of every section and lesson of a course, and
+	// moves lessons between sections.
+	Reorder(courseId uuid.UUID, order dto.CurriculumOrderDto) (dto.CurriculumDto, error)
+}
+
+type curriculumService struct {
+	client curriculum.CurriculumClient
+}
+
+func NewCurriculumService(client *curriculum.CurriculumClient) ICurriculumService {
+	return &curriculumService{client: *client}
+}
+
+func (s *curriculumService) GetCurriculum(courseId uuid.UUID) (dto.CurriculumDto, error) {
+	if err := s.requireCourse(courseId); err != nil {
+		return dto.CurriculumDto{}, err
+	}
+	sections, lessons, err := s.client.GetOutline(courseId)
+	if err != nil {
+		return dto.CurriculumDto{}, err
+	}
+	bySection := map[uuid.UUID][]dto.LessonSummaryDto{}
+	for _, lesson := range lessons {
+		bySection[lesson.SectionId] = append(bySection[lesson.SectionId], dto.LessonSummaryDto{
+			Id:       lesson.Id,
+			Title:    lesson.Title,
+			Type:     lesson.Type,
+			Position: lesson.Position,
+			Duration: lesson.Duration,
+		})
+	}
+	response := dto.CurriculumDto{CourseId: courseId, Sections: []dto.SectionDto{}}
+	for _, section := range sections {
+		sectionDto := toSectionDto(section)
+		if lessons, ok := bySection[section.Id]; ok {
+			sectionDto.Lessons = lessons
+		}
+		response.Sections = append(response.Sections, sectionDto)
+	}
+	return response, nil
+}
+
+func (s *curriculumService) CreateSection(courseId uuid.UUID, request dto.SectionRequestDto) (dto.SectionDto, error) {
+	title, err := curriculumTitle(request.Title)
+	if err != nil {
+		return dto.SectionDto{}, err
+	}
+	if err := s.requireCourse(courseId); err != nil {
+		return dto.SectionDto{}, err
+	}
+	section, err := s.client.CreateSection(model.Section{CourseId: courseId, Title: title})
+	if err != nil {
+		return dto.SectionDto{}, err
+	}
+	return toSectionDto(section), nil
+}
+
+func (s *curriculumService) UpdateSection(courseId uuid.UUID, sectionId uuid.UUID, request dto.SectionRequestDto) (dto.SectionDto, error) {
+	title, err := curriculumTitle(request.Title)
+	if err != nil {
+		return dto.SectionDto{}, err
+	}
+	section, err := s.client.GetSection(courseId, sectionId)
+	if err != nil {
+		return dto.SectionDto{}, err
+	}
+	section.Title = title
+	if section, err = s.client.UpdateSection(section); err != nil {
+		return dto.SectionDto{}, err
+	}
+	return toSectionDto(section), nil
+}
+
+func (s *curriculumService) DeleteSection(courseId uuid.UUID, sectionId uuid.UUID) error {
+	section, err := s.client.GetSection(courseId, sectionId)
+	if err != nil {
+		return err
+	}
+	return s.client.DeleteSection(section)
+}
+
+func (s *curriculumService) GetLesson(courseId uuid.UUID, lessonId uuid.UUID) (dto.LessonDto, error) {
+	lesson, err := s.client.GetLesson(courseId, lessonId)
+	if err != nil {
+		return dto.LessonDto{}, err
+	}
+	return toLessonDto(lesson), nil
+}
+
+func (s *curriculumService) CreateLesson(courseId uuid.UUID, sectionId uuid.UUID, request dto.LessonRequestDto) (dto.LessonDto, error) {
+	lesson, err := lessonFromRequest(request)
+	if err != nil {
+		return dto.LessonDto{}, err
+	}
+	if _, err := s.client.GetSection(courseId, sectionId); err != nil {
+		return dto.LessonDto{}, err
+	}
+	lesson.CourseId, lesson.SectionId = courseId, sectionId
+	if lesson, err = s.client.CreateLesson(lesson); err != nil {
+		return dto.LessonDto{}, err
+	}
+	return toLessonDto(lesson), nil
+}
+
+func (s *curriculumService) UpdateLesson(courseId uuid.UUID, lessonId uuid.UUID, request dto.LessonRequestDto) (dto.LessonDto, error) {
+	changes, err := lessonFromRequest(request)
+	if err != nil {
+		return dto.LessonDto{}, err
+	}
+	lesson, err := s.client.GetLesson(courseId, lessonId)
+	if err != nil {
+		return dto.LessonDto{}, err
+	}
+	lesson.Title, lesson.Type, lesson.Body, lesson.Url, lesson.Duration = changes.Title, changes.Type, changes.Body, changes.Url, changes.Duration
+	if lesson, err = s.client.UpdateLesson(lesson); err != nil {
+		return dto.LessonDto{}, err
+	}
+	return toLessonDto(lesson), nil
+}
+
+func (s *curriculumService) DeleteLesson(courseId uuid.UUID, lessonId uuid.UUID) error {
+	lesson, err := s.client.GetLesson(courseId, lessonId)
+	if err != nil {
+		return err
+	}
+	return s.client.DeleteLesson(lesson)
+}
+
+func (s *curriculumService) Reorder(courseId uuid.UUID, request dto.CurriculumOrderDto) (dto.CurriculumDto, error) {
+	if err := s.requireCourse(courseId); err != nil {
+		return dto.CurriculumDto{}, err
+	}
+	order := make([]curriculum.SectionOrder, 0, len(request.Sections))
+	for _, section := range request.Sections {
+		order = append(order, curriculum.SectionOrder{SectionId: section.Id, LessonIds: section.Lessons})
+	}
+	if err := s.client.Reorder(courseId, order); err != nil {
+		return dto.CurriculumDto{}, err
+	}
+	return s.GetCurriculum(courseId)
+}
+
+func (s *curriculumService) requireCourse(courseId uuid.UUID) error {
+	exists, err := s.client.CourseExists(courseId)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return customError.NewError("NOT_FOUND", "Course not found", http.StatusNotFound)
+	}
+	return nil
+}
+
+func curriculumTitle(title string) (string, error) {
+	title = strings.TrimSpace(title)
+	if title == "" || len(title) > MaxCurriculumTitle {
+		return "", customError.NewError("INVALID_TITLE", fmt.Sprintf("title is required and can't be longer than %d characters", MaxCurriculumTitle), http.StatusBadRequest)
+	}
+	return title, nil
+}
+
+// lessonFromRequest validates a lesson and keeps only the content its type
+// uses.
+func lessonFromRequest(request dto.LessonRequestDto) (model.Lesson, error) {
+	title, err := curriculumTitle(request.Title)
+	if err != nil {
+		return model.Lesson{}, err
+	}
+	if !slices.Contains(model.LessonTypes, request.Type) {
+		return model.Lesson{}, customError.NewError("INVALID_LESSON_TYPE", "type must be one of "+strings.Join(model.LessonTypes, ", "), http.StatusBadRequest)
+	}
+	if request.Duration < 0 {
+		return model.Lesson{}, customError.NewError("INVALID_DURATION", "duration can't be negative", http.StatusBadRequest)
+	}
+	lesson := model.Lesson{Title: title, Type: request.Type, Duration: request.Duration}
+	if request.Type == model.LessonText {
+		if strings.TrimSpace(request.Body) == "" {
+			return model.Lesson{}, customError.NewError("BODY_REQUIRED", "Text lessons need a body", http.StatusBadRequest)
+		}
+		lesson.Body = request.Body
+		return lesson, nil
+	}
+	link, err := url.Parse(strings.TrimSpace(request.Url))
+	if err != nil || (link.Scheme != "http" && link.Scheme != "https") || link.Host == "" {
+		return model.Lesson{}, customError.NewError("INVALID_URL", request.Type+" lessons need an http or https url", http.StatusBadRequest)
+	}
+	lesson.Url = link.String()
+	return lesson, nil
+}
+
+func toSectionDto(section model.Section) dto.SectionDto {
+	return dto.SectionDto{
+		Id:       section.Id,
+		Title:    section.Title,
+		Position: section.Position,
+		Lessons:  []dto.LessonSummaryDto{},
+	}
+}
+
+func toLessonDto(lesson model.Lesson) dto.LessonDto {
+	return dto.LessonDto{
+		Id:        lesson.Id,
+		SectionId: lesson.SectionId,
+		Title:     lesson.Title,
+		Type:      lesson.Type,
+		Position:  lesson.Position,
+		Duration:  lesson.Duration,
+		Body:      lesson.Body,
+		Url:       lesson.Url,
+	}
+}
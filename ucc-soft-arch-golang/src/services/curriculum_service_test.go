@@ -0,0 +1,91 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/curriculum"
+	dto "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/courses"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/google/uuid"
+)
+
+func setupCurriculum(t *testing.T) (ICurriculumService, model.Course) {
+	client := setupCourseClientSQLite(t)
+	require.NoError(t, client.Db.AutoMigrate(&model.Section{}, &model.Lesson{}))
+	course := seedCourse(t, client, seedCategory(t, client, "Programming"), "Golang")
+	return NewCurriculumService(curriculum.NewCurriculumClient(client.Db)), course
+}
+
+func TestCurriculumService_Curriculum(t *testing.T) {
+	svc, course := setupCurriculum(t)
+
+	intro, err := svc.CreateSection(course.Id, dto.SectionRequestDto{Title: "  Intro "})
+	require.NoError(t, err)
+	require.Equal(t, "Intro", intro.Title)
+	tools, err := svc.CreateSection(course.Id, dto.SectionRequestDto{Title: "Tools"})
+	require.NoError(t, err)
+
+	text, err := svc.CreateLesson(course.Id, intro.Id, dto.LessonRequestDto{Title: "Welcome", Type: model.LessonText, Body: "# Hi", Url: "https://ignored.example.com"})
+	require.NoError(t, err)
+	require.Empty(t, text.Url)
+	video, err := svc.CreateLesson(course.Id, intro.Id, dto.LessonRequestDto{Title: "Install", Type: model.LessonVideo, Url: "https://videos.example.com/install", Duration: 12})
+	require.NoError(t, err)
+
+	outline, err := svc.GetCurriculum(course.Id)
+	require.NoError(t, err)
+	require.Len(t, outline.Sections, 2)
+	require.Len(t, outline.Sections[0].Lessons, 2)
+	require.Equal(t, "Install", outline.Sections[0].Lessons[1].Title)
+	require.Equal(t, 12, outline.Sections[0].Lessons[1].Duration)
+	require.NotNil(t, outline.Sections[1].Lessons)
+
+	lesson, err := svc.GetLesson(course.Id, video.Id)
+	require.NoError(t, err)
+	require.Equal(t, "https://videos.example.com/install", lesson.Url)
+
+	updated, err := svc.UpdateLesson(course.Id, text.Id, dto.LessonRequestDto{Title: "Slides", Type: model.LessonFile, Url: "https://files.example.com/slides.pdf"})
+	require.NoError(t, err)
+	require.Equal(t, model.LessonFile, updated.Type)
+	require.Empty(t, updated.Body)
+
+	outline, err = svc.Reorder(course.Id, dto.CurriculumOrderDto{Sections: []dto.SectionOrderDto{
+		{Id: tools.Id, Lessons: []uuid.UUID{video.Id}},
+		{Id: intro.Id, Lessons: []uuid.UUID{text.Id}},
+	}})
+	require.NoError(t, err)
+	require.Equal(t, "Tools", outline.Sections[0].Title)
+	require.Equal(t, video.Id, outline.Sections[0].Lessons[0].Id)
+
+	require.NoError(t, svc.DeleteLesson(course.Id, text.Id))
+	require.NoError(t, svc.DeleteSection(course.Id, tools.Id))
+	_, err = svc.GetLesson(course.Id, video.Id)
+	requireErrorCode(t, err, "LESSON_NOT_FOUND")
+}
+
+func TestCurriculumService_Validation(t *testing.T) {
+	svc, course := setupCurriculum(t)
+	section, err := svc.CreateSection(course.Id, dto.SectionRequestDto{Title: "Intro"})
+	require.NoError(t, err)
+
+	_, err = svc.CreateSection(uuid.New(), dto.SectionRequestDto{Title: "Intro"})
+	requireErrorCode(t, err, "NOT_FOUND")
+	_, err = svc.CreateSection(course.Id, dto.SectionRequestDto{Title: " "})
+	requireErrorCode(t, err, "INVALID_TITLE")
+	_, err = svc.UpdateSection(course.Id, section.Id, dto.SectionRequestDto{Title: strings.Repeat("a", MaxCurriculumTitle+1)})
+	requireErrorCode(t, err, "INVALID_TITLE")
+	_, err = svc.CreateLesson(course.Id, uuid.New(), dto.LessonRequestDto{Title: "A", Type: model.LessonText, Body: "b"})
+	requireErrorCode(t, err, "SECTION_NOT_FOUND")
+	_, err = svc.CreateLesson(course.Id, section.Id, dto.LessonRequestDto{Title: "A", Type: "quiz"})
+	requireErrorCode(t, err, "INVALID_LESSON_TYPE")
+	_, err = svc.CreateLesson(course.Id, section.Id, dto.LessonRequestDto{Title: "A", Type: model.LessonText})
+	requireErrorCode(t, err, "BODY_REQUIRED")
+	_, err = svc.CreateLesson(course.Id, section.Id, dto.LessonRequestDto{Title: "A", Type: model.LessonLink, Url: "javascript:alert(1)"})
+	requireErrorCode(t, err, "INVALID_URL")
+	_, err = svc.CreateLesson(course.Id, section.Id, dto.LessonRequestDto{Title: "A", Type: model.LessonVideo, Url: "https://v.example.com", Duration: -1})
+	requireErrorCode(t, err, "INVALID_DURATION")
+	_, err = svc.Reorder(course.Id, dto.CurriculumOrderDto{})
+	requireErrorCode(t, err, "INVALID_ORDER")
+}
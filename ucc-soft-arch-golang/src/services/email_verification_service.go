@@ -0,0 +1,138 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/emailverification"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/users"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/config"
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/mailer"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/securetoken"
+	"github.com/google/uuid"
+)
+
+// DefaultEmailVerificationTTL is used when EMAIL_VERIFICATION_TTL is not configured.
+const DefaultEmailVerificationTTL = 48 * time.Hour
+
+type IEmailVerificationService interface {
+	// SendVerification mails a fresh verification link to the user.
+	SendVerification(userId uuid.UUID) error
+	// Resend is the public version of SendVerification. Unknown and already
+	// verified emails are ignored so it can't be used to probe accounts.
+	Resend(email string) error
+	// Verify consumes a verification token and marks the email as verified.
+	Verify(token string) error
+}
+
+type emailVerificationService struct {
+	users     users.UsersClient
+	client    emailverification.EmailVerificationClient
+	mailer    mailer.Mailer
+	ttl       time.Duration
+	verifyURL string
+}
+
+func NewEmailVerificationService(usersClient *users.UsersClient, client *emailverification.EmailVerificationClient, mail mailer.Mailer) IEmailVerificationService {
+	envs := config.LoadEnvs(".env")
+	api := envs.Get("API_URL")
+	if api == "" {
+		api = "http://localhost:" + envs.Get("PORT")
+	}
+	return &emailVerificationService{
+		users:     *usersClient,
+		client:    *client,
+		mailer:    mail,
+		ttl:       config.GetDuration(envs, "EMAIL_VERIFICATION_TTL", DefaultEmailVerificationTTL),
+		verifyURL: strings.TrimRight(api, "/") + "/auth/verify-email",
+	}
+}
+
+func (s *emailVerificationService) SendVerification(userId uuid.UUID) error {
+	user, err := s.users.FindById(userId)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerified {
+		return customError.NewError("EMAIL_ALREADY_VERIFIED", "Email is already verified", http.StatusConflict)
+	}
+	return s.send(user)
+}
+
+func (s *emailVerificationService) Resend(email string) error {
+	user, err := s.users.FindByEmail(email)
+	if err != nil {
+		if ce, ok := err.(*customError.Error); ok && ce.Code == "NOT_FOUND" {
+			return nil
+		}
+		return err
+	}
+	if user.EmailVerified {
+		return nil
+	}
+	return s.send(user)
+}
+
+func (s *emailVerificationService) send(user model.User) error {
+	token, err := securetoken.Generate(securetoken.DefaultSize)
+	if err != nil {
+		return customError.NewError("TOKEN_GENERATION_ERROR", "Could not generate verification token", http.StatusInternalServerError)
+	}
+	now := time.Now()
+	// Only the latest link is valid.
+	if err := s.client.InvalidateForUser(user.Id, now); err != nil {
+		return err
+	}
+	_, err = s.client.Create(model.EmailVerification{
+		UserId:    user.Id,
+		TokenHash: securetoken.Hash(token),
+		ExpiresAt: now.Add(s.ttl),
+	})
+	if err != nil {
+		return err
+	}
+
+	link := s.verifyURL + "?token=" + url.QueryEscape(token)
+	err = s.mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Confirm your email",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening the link below within %s:\n\n%s\n",
+			user.Name, s.ttl, link),
+	})
+	if err != nil {
+		return customError.NewError("MAIL_ERROR", "Could not send verification email", http.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (s *emailVerificationService) Verify(token string) error {
+	invalid := customError.NewError("INVALID_VERIFICATION_TOKEN", "Invalid or expired verification token", http.StatusBadRequest)
+	if token == "" {
+		return invalid
+	}
+
+	verification, err := s.client.FindByHash(securetoken.Hash(token))
+	if err != nil {
+		if ce, ok := err.(*customError.Error); ok && ce.Code == "NOT_FOUND" {
+			return invalid
+		}
+		return err
+	}
+	now := time.Now()
+	if verification.UsedAt != nil || now.After(verification.ExpiresAt) {
+		return invalid
+	}
+	marked, err := s.client.MarkUsed(verification.ID, now)
+	if err != nil {
+		return err
+	}
+	if !marked {
+		return invalid
+	}
+	return s.users.MarkEmailVerified(verification.UserId)
+}
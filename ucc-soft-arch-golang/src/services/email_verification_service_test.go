@@ -0,0 +1,74 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	github_com_glebarez_sqlite "github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/emailverification"
+	usersClient "github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/users"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+)
+
+func setupEmailVerification(t *testing.T) (IEmailVerificationService, *captureMailer, *usersClient.UsersClient, model.User) {
+	db, err := gorm.Open(github_com_glebarez_sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	require.NoError(t, db.AutoMigrate(&model.User{}, &model.EmailVerification{}))
+	client := usersClient.NewUsersClient(db)
+	user, err := client.Create(model.User{Email: "verify@test.com", Name: "verify", Password: "x"})
+	require.NoError(t, err)
+
+	mail := &captureMailer{}
+	svc := NewEmailVerificationService(client, emailverification.NewEmailVerificationClient(db), mail)
+	return svc, mail, client, user
+}
+
+func TestEmailVerificationService_VerifyFlow(t *testing.T) {
+	svc, mail, client, user := setupEmailVerification(t)
+
+	require.NoError(t, svc.SendVerification(user.Id))
+	require.Equal(t, user.Email, mail.sent[0].To)
+	require.Contains(t, mail.sent[0].Body, "/auth/verify-email?token=")
+	token := sentToken(t, mail)
+
+	require.NoError(t, svc.Verify(token))
+	stored, err := client.FindById(user.Id)
+	require.NoError(t, err)
+	require.True(t, stored.EmailVerified)
+
+	// single use, and nothing left to send
+	require.Error(t, svc.Verify(token))
+	require.Error(t, svc.SendVerification(user.Id))
+	require.NoError(t, svc.Resend(user.Email))
+	require.Len(t, mail.sent, 1)
+}
+
+func TestEmailVerificationService_ResendInvalidatesPrevious(t *testing.T) {
+	svc, mail, _, user := setupEmailVerification(t)
+	require.NoError(t, svc.SendVerification(user.Id))
+	first := sentToken(t, mail)
+	require.NoError(t, svc.Resend(user.Email))
+	second := sentToken(t, mail)
+
+	require.Error(t, svc.Verify(first))
+	require.NoError(t, svc.Verify(second))
+}
+
+func TestEmailVerificationService_ResendUnknownEmailIsSilent(t *testing.T) {
+	svc, mail, _, _ := setupEmailVerification(t)
+	require.NoError(t, svc.Resend("nobody@test.com"))
+	require.Empty(t, mail.sent)
+}
+
+func TestEmailVerificationService_ExpiredToken(t *testing.T) {
+	svc, mail, _, user := setupEmailVerification(t)
+	svc.(*emailVerificationService).ttl = -time.Minute
+	require.NoError(t, svc.SendVerification(user.Id))
+	require.Error(t, svc.Verify(sentToken(t, mail)))
+	require.Error(t, svc.Verify(""))
+}
@@ -0,0 +1,264 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/impersonations"
+	usersClient "github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/users"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/config"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/users"
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/jwt"
+	"github.com/google/uuid"
+)
+
+// DefaultImpersonationTTL is used when IMPERSONATION_TTL is not configured.
+const DefaultImpersonationTTL = 30 * time.Minute
+
+const MaxImpersonationReason = 500
+
+const (
+	AuditImpersonationStarted = "impersonation_started"
+	AuditImpersonationEnded   = "impersonation_ended"
+	AuditImpersonatedRequest  = "impersonated_request"
+)
+
+type IImpersonationService interface {
+	// Start mints a token that acts as userId on behalf of actorId. Staff
+	// accounts can't be impersonated, nor users with a permission the actor
+	// lacks.
+	Start(actorId uuid.UUID, userId uuid.UUID, reason string, client users.ClientInfoDto) (users.ImpersonationDto, error)
+	// Verify rejects impersonation tokens that were ended or expired, or
+	// whose actor lost the permission to impersonate.
+	Verify(claims *jwt.CustomClaims) error
+	// RecordRequest writes one request made with an impersonation token to
+	// the audit log.
+	RecordRequest(claims *jwt.CustomClaims, request users.RequestInfoDto) error
+	// End ends the impersonation the token belongs to.
+	End(claims *jwt.CustomClaims) error
+}
+
+type impersonationService struct {
+	client      impersonations.ImpersonationsClient
+	users       usersClient.UsersClient
+	permissions IPermissionService
+	audit       IAuditService
+	ttl         time.Duration
+	now         func() time.Time
+}
+
+func NewImpersonationService(client *impersonations.ImpersonationsClient, usersClient *usersClient.UsersClient, permissions IPermissionService, audit IAuditService) IImpersonationService {
+	envs := config.LoadEnvs(".env")
+	return &impersonationService{
+		client:      *client,
+		users:       *usersClient,
+		permissions: permissions,
+		audit:       audit,
+		ttl:         config.GetDuration(envs, "IMPERSONATION_TTL", DefaultImpersonationTTL),
+		now:         time.Now,
+	}
+}
+
+func (s *impersonationService) Start(actorId uuid.UUID, userId uuid.UUID, reason string, client users.ClientInfoDto) (users.ImpersonationDto, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return users.ImpersonationDto{}, customError.NewError("REASON_REQUIRED", "reason is required", http.StatusBadRequest)
+	}
+	if len(reason) > MaxImpersonationReason {
+		return users.ImpersonationDto{}, customError.NewError("INVALID_REASON", fmt.Sprintf("reason can't be longer than %d characters", MaxImpersonationReason), http.StatusBadRequest)
+	}
+	if actorId == userId {
+		return users.ImpersonationDto{}, customError.NewError("CANNOT_IMPERSONATE_SELF", "Admins can't impersonate themselves", http.StatusBadRequest)
+	}
+	target, err := s.users.FindById(userId)
+	if err != nil {
+		return users.ImpersonationDto{}, err
+	}
+	if target.SuspendedAt != nil {
+		return users.ImpersonationDto{}, accountSuspended()
+	}
+	// acting as staff would hand out permissions the actor may not have
+	staff, err := s.isStaff(target.Role)
+	if err != nil {
+		return users.ImpersonationDto{}, err
+	}
+	if staff {
+		return users.ImpersonationDto{}, customError.NewError("CANNOT_IMPERSONATE", "Staff accounts can't be impersonated", http.StatusForbidden)
+	}
+	actor, err := s.users.FindById(actorId)
+	if err != nil {
+		return users.ImpersonationDto{}, err
+	}
+	covered, err := s.covers(actor.Role, target.Role)
+	if err != nil {
+		return users.ImpersonationDto{}, err
+	}
+	if !covered {
+		return users.ImpersonationDto{}, customError.NewError("CANNOT_IMPERSONATE", "The user has permissions you don't have", http.StatusForbidden)
+	}
+
+	impersonation, err := s.client.Create(model.Impersonation{
+		ActorId:   actorId,
+		UserId:    userId,
+		Reason:    reason,
+		Ip:        client.Ip,
+		ExpiresAt: s.now().Add(s.ttl),
+	})
+	if err != nil {
+		return users.ImpersonationDto{}, err
+	}
+
+	claims := jwt.NewCustomClaims(target.Id, target.Role)
+	claims.Version = target.TokenVersion
+	claims.SessionId = impersonation.Id.String()
+	claims.Actor = &jwt.Actor{Subject: actorId}
+	claims.ExpiresAt = impersonation.ExpiresAt.Unix()
+	accessToken := jwt.SignClaims(claims)
+	if accessToken == "" {
+		return users.ImpersonationDto{}, customError.NewError("TOKEN_SIGNING_ERROR", "Could not sign access token", http.StatusInternalServerError)
+	}
+
+	if err := s.audit.Record(model.AuditLog{
+		Event:   AuditImpersonationStarted,
+		ActorId: actorId,
+		UserId:  userId,
+		Ip:      client.Ip,
+		Details: fmt.Sprintf("impersonation=%s reason=%s", impersonation.Id, reason),
+	}); err != nil {
+		return users.ImpersonationDto{}, err
+	}
+	return users.ImpersonationDto{
+		Id:          impersonation.Id,
+		AccessToken: accessToken,
+		ExpiresAt:   impersonation.ExpiresAt,
+		ExpiresIn:   claims.ExpiresAt - claims.IssuedAt,
+		User: users.GetUserDto{
+			Id:            target.Id,
+			Email:         target.Email,
+			Role:          target.Role,
+			UserName:      target.Name,
+			Avatar:        target.Avatar,
+			EmailVerified: target.EmailVerified,
+		},
+	}, nil
+}
+
+func (s *impersonationService) Verify(claims *jwt.CustomClaims) error {
+	impersonation, err := s.find(claims)
+	if err != nil {
+		return err
+	}
+	if impersonation.EndedAt != nil || !s.now().Before(impersonation.ExpiresAt) {
+		return impersonationEnded()
+	}
+	actor, err := s.users.FindById(impersonation.ActorId)
+	if err != nil {
+		if isNotFound(err) {
+			return impersonationEnded()
+		}
+		return err
+	}
+	if actor.SuspendedAt != nil {
+		return impersonationEnded()
+	}
+	allowed, err := s.permissions.HasPermission(actor.Role, model.PermissionUsersImpersonate)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return impersonationEnded()
+	}
+	return nil
+}
+
+func (s *impersonationService) RecordRequest(claims *jwt.CustomClaims, request users.RequestInfoDto) error {
+	return s.audit.Record(model.AuditLog{
+		Event:   AuditImpersonatedRequest,
+		ActorId: claims.Actor.Subject,
+		UserId:  claims.Id,
+		Ip:      request.Ip,
+		Details: fmt.Sprintf("impersonation=%s %s %s", claims.SessionId, request.Method, request.Path),
+	})
+}
+
+func (s *impersonationService) End(claims *jwt.CustomClaims) error {
+	if !claims.IsImpersonation() {
+		return customError.NewError("NOT_IMPERSONATING", "This token is not an impersonation", http.StatusBadRequest)
+	}
+	impersonation, err := s.find(claims)
+	if err != nil {
+		return err
+	}
+	ended, err := s.client.End(impersonation.Id, s.now())
+	if err != nil {
+		return err
+	}
+	if !ended {
+		return impersonationEnded()
+	}
+	return s.audit.Record(model.AuditLog{
+		Event:   AuditImpersonationEnded,
+		ActorId: impersonation.ActorId,
+		UserId:  impersonation.UserId,
+		Details: fmt.Sprintf("impersonation=%s", impersonation.Id),
+	})
+}
+
+// find loads the impersonation of a token and checks it was issued for the
+// same user and actor.
+func (s *impersonationService) find(claims *jwt.CustomClaims) (model.Impersonation, error) {
+	id, err := uuid.Parse(claims.SessionId)
+	if err != nil || claims.Actor == nil {
+		return model.Impersonation{}, impersonationEnded()
+	}
+	impersonation, err := s.client.FindById(id)
+	if err != nil {
+		if isNotFound(err) {
+			return model.Impersonation{}, impersonationEnded()
+		}
+		return model.Impersonation{}, err
+	}
+	if impersonation.UserId != claims.Id || impersonation.ActorId != claims.Actor.Subject {
+		return model.Impersonation{}, impersonationEnded()
+	}
+	return impersonation, nil
+}
+
+func (s *impersonationService) isStaff(role string) (bool, error) {
+	for _, permission := range []string{model.PermissionUsersManage, model.PermissionUsersImpersonate} {
+		allowed, err := s.permissions.HasPermission(role, permission)
+		if err != nil || allowed {
+			return allowed, err
+		}
+	}
+	return false, nil
+}
+
+// covers reports whether actorRole has every permission of targetRole.
+func (s *impersonationService) covers(actorRole string, targetRole string) (bool, error) {
+	roles, err := s.permissions.GetRoles()
+	if err != nil {
+		return false, err
+	}
+	granted := map[string]map[string]bool{}
+	for _, role := range roles {
+		granted[role.Name] = map[string]bool{}
+		for _, permission := range role.Permissions {
+			granted[role.Name][permission.Name] = true
+		}
+	}
+	for permission := range granted[targetRole] {
+		if !granted[actorRole][permission] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func impersonationEnded() error {
+	return customError.NewError("IMPERSONATION_ENDED", "Impersonation has ended", http.StatusUnauthorized)
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	github_com_glebarez_sqlite "github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	auditClient "github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/audit"
+	impersonationsClient "github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/impersonations"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/roles"
+	usersClient "github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/users"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/config"
+	userDtos "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/users"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+)
+
+type impersonationFixture struct {
+	db     *gorm.DB
+	svc    *impersonationService
+	tokens ITokenService
+	audit  IAuditService
+	users  *usersClient.UsersClient
+	admin  model.User
+	target model.User
+}
+
+func setupImpersonation(t *testing.T) impersonationFixture {
+	db, err := gorm.Open(github_com_glebarez_sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	require.NoError(t, db.AutoMigrate(&model.User{}, &model.Permission{}, &model.Role{}, &model.RefreshToken{}, &model.RevokedToken{},
+		&model.Session{}, &model.AuditLog{}, &model.Impersonation{}))
+	require.NoError(t, config.SeedRoles(db))
+
+	client := usersClient.NewUsersClient(db)
+	admin, err := client.Create(model.User{Email: "admin@test.com", Name: "Admin", Password: "x", Role: model.RoleAdmin})
+	require.NoError(t, err)
+	target, err := client.Create(model.User{Email: "student@test.com", Name: "Student", Password: "x", Role: model.RoleStudent})
+	require.NoError(t, err)
+
+	audit := NewAuditService(auditClient.NewAuditClient(db))
+	svc := NewImpersonationService(impersonationsClient.NewImpersonationsClient(db), client, NewPermissionService(roles.NewRolesClient(db)), audit).(*impersonationService)
+	tokens, _ := newTokenStack(db)
+	return impersonationFixture{db: db, svc: svc, tokens: tokens, audit: audit, users: client, admin: admin, target: target}
+}
+
+func TestImpersonationService_StartIssuesActorToken(t *testing.T) {
+	f := setupImpersonation(t)
+
+	impersonation, err := f.svc.Start(f.admin.Id, f.target.Id, "ticket 42", userDtos.ClientInfoDto{Ip: "10.0.0.1"})
+	require.NoError(t, err)
+	require.Equal(t, f.target.Id, impersonation.User.Id)
+
+	claims, err := f.tokens.VerifyAccessToken(impersonation.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, f.target.Id, claims.Id)
+	require.True(t, claims.IsImpersonation())
+	require.Equal(t, f.admin.Id, claims.Actor.Subject)
+
+	logs, err := f.audit.List(AuditImpersonationStarted, f.target.Id, 0)
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	require.Equal(t, f.admin.Id, logs[0].ActorId)
+	require.Contains(t, logs[0].Details, "ticket 42")
+}
+
+func TestImpersonationService_StartRules(t *testing.T) {
+	f := setupImpersonation(t)
+
+	_, err := f.svc.Start(f.admin.Id, f.target.Id, "  ", userDtos.ClientInfoDto{})
+	requireErrorCode(t, err, "REASON_REQUIRED")
+	_, err = f.svc.Start(f.admin.Id, f.admin.Id, "why", userDtos.ClientInfoDto{})
+	requireErrorCode(t, err, "CANNOT_IMPERSONATE_SELF")
+	_, err = f.svc.Start(f.target.Id, f.admin.Id, "why", userDtos.ClientInfoDto{})
+	requireErrorCode(t, err, "CANNOT_IMPERSONATE")
+	_, err = f.svc.Start(f.admin.Id, uuid.New(), "why", userDtos.ClientInfoDto{})
+	requireErrorCode(t, err, "NOT_FOUND")
+
+	now := time.Now()
+	require.NoError(t, f.users.SetSuspended(f.target.Id, &now, "spam"))
+	_, err = f.svc.Start(f.admin.Id, f.target.Id, "why", userDtos.ClientInfoDto{})
+	requireErrorCode(t, err, "ACCOUNT_SUSPENDED")
+}
+
+func TestImpersonationService_StartNeedsTheTargetPermissions(t *testing.T) {
+	f := setupImpersonation(t)
+	var impersonate model.Permission
+	require.NoError(t, f.db.Where("name = ?", model.PermissionUsersImpersonate).First(&impersonate).Error)
+	require.NoError(t, f.db.Create(&model.Role{Name: "support", Permissions: []model.Permission{impersonate}}).Error)
+	support, err := f.users.Create(model.User{Email: "support@test.com", Name: "Support", Password: "x", Role: "support"})
+	require.NoError(t, err)
+	instructor, err := f.users.Create(model.User{Email: "teacher@test.com", Name: "Teacher", Password: "x", Role: model.RoleInstructor})
+	require.NoError(t, err)
+
+	_, err = f.svc.Start(support.Id, instructor.Id, "ticket 7", userDtos.ClientInfoDto{})
+	requireErrorCode(t, err, "CANNOT_IMPERSONATE")
+	_, err = f.svc.Start(support.Id, f.target.Id, "ticket 7", userDtos.ClientInfoDto{})
+	require.NoError(t, err)
+	_, err = f.svc.Start(f.admin.Id, instructor.Id, "ticket 7", userDtos.ClientInfoDto{})
+	require.NoError(t, err)
+}
+
+func TestImpersonationService_EndAndExpiry(t *testing.T) {
+	f := setupImpersonation(t)
+	impersonation, err := f.svc.Start(f.admin.Id, f.target.Id, "ticket 42", userDtos.ClientInfoDto{})
+	require.NoError(t, err)
+	claims, err := f.tokens.VerifyAccessToken(impersonation.AccessToken)
+	require.NoError(t, err)
+
+	require.NoError(t, f.svc.RecordRequest(claims, userDtos.RequestInfoDto{Method: "GET", Path: "/myCourses/x", Ip: "10.0.0.1"}))
+	logs, err := f.audit.List(AuditImpersonatedRequest, f.target.Id, 0)
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	require.Equal(t, f.admin.Id, logs[0].ActorId)
+	require.Contains(t, logs[0].Details, "GET /myCourses/x")
+
+	// an expired impersonation is refused even though the row is still open
+	f.svc.now = func() time.Time { return time.Now().Add(DefaultImpersonationTTL + time.Minute) }
+	requireErrorCode(t, f.svc.Verify(claims), "IMPERSONATION_ENDED")
+	f.svc.now = time.Now
+
+	require.NoError(t, f.svc.End(claims))
+	_, err = f.tokens.VerifyAccessToken(impersonation.AccessToken)
+	requireErrorCode(t, err, "IMPERSONATION_ENDED")
+	requireErrorCode(t, f.svc.End(claims), "IMPERSONATION_ENDED")
+
+	logs, err = f.audit.List(AuditImpersonationEnded, f.target.Id, 0)
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+
+	plain, err := f.tokens.IssueTokens(f.target.Id, f.target.Role, uuid.Nil, userDtos.ClientInfoDto{})
+	require.NoError(t, err)
+	plainClaims, err := f.tokens.VerifyAccessToken(plain.AccessToken)
+	require.NoError(t, err)
+	requireErrorCode(t, f.svc.End(plainClaims), "NOT_IMPERSONATING")
+}
+
+func TestImpersonationService_ActorLosingPermissionEndsIt(t *testing.T) {
+	f := setupImpersonation(t)
+	impersonation, err := f.svc.Start(f.admin.Id, f.target.Id, "ticket 42", userDtos.ClientInfoDto{})
+	require.NoError(t, err)
+
+	require.NoError(t, f.users.SetRole(f.admin.Id, model.RoleStudent))
+	_, err = f.tokens.VerifyAccessToken(impersonation.AccessToken)
+	requireErrorCode(t, err, "IMPERSONATION_ENDED")
+}
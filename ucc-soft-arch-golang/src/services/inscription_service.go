@@ -1,63 +1,103 @@
 package services
 
 import (
+	"time"
+
 	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/inscriptos"
-	courseDto "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/courses"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/progress"
 	dto "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/inscription"
 	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
 	"github.com/google/uuid"
 )
 
 type IInscriptionService interface {
+	// Enroll takes a seat in the course or, when it's full, a place on its
+	// waitlist. Courses with cohorts need the cohort to enroll in.
 	Enroll(dto.EnrollRequestResponseDto) (dto.EnrollRequestResponseDto, error)
-	GetMyCourses(uuid.UUID) (courseDto.GetAllCourses, error)
+	// Unenroll leaves the course or its waitlist. A freed seat goes to the
+	// first user waiting.
+	Unenroll(userID uuid.UUID, courseID uuid.UUID) error
+	// GetMyCourses lists the courses a user is enrolled in with their
+	// progress.
+	GetMyCourses(uuid.UUID) (dto.EnrolledCourses, error)
+	// GetMyStudents lists the students of a course with their progress.
 	GetMyStudents(uuid.UUID) (dto.StudentsInCourse, error)
+	// IsUserEnrolled reports whether the user has a seat in the course.
 	IsUserEnrolled(userID uuid.UUID, courseID uuid.UUID) (bool, error)
 	CourseExist(course_id uuid.UUID) (bool, error)
 }
 
 type inscriptionService struct {
-	client inscriptos.InscriptosClient
+	client   inscriptos.InscriptosClient
+	progress progress.ProgressClient
+	now      func() time.Time
 }
 
-func NewInscriptionService(client *inscriptos.InscriptosClient) IInscriptionService {
-	return &inscriptionService{client: *client}
+func NewInscriptionService(client *inscriptos.InscriptosClient, progressClient *progress.ProgressClient) IInscriptionService {
+	return &inscriptionService{client: *client, progress: *progressClient, now: time.Now}
 }
 
 func (c *inscriptionService) Enroll(data dto.EnrollRequestResponseDto) (dto.EnrollRequestResponseDto, error) {
 	var newEnroll = model.Inscripto{
 		CourseId: data.CourseId,
 		UserId:   data.UserId,
+		CohortId: data.CohortId,
 	}
-	enroll, err := c.client.Enroll(newEnroll)
+	enroll, err := c.client.Enroll(newEnroll, c.now())
 	if err != nil {
 		return dto.EnrollRequestResponseDto{}, err
 	}
-	return dto.EnrollRequestResponseDto{
+	response := dto.EnrollRequestResponseDto{
 		CourseId: enroll.CourseId,
 		UserId:   enroll.UserId,
-	}, nil
+		CohortId: enroll.CohortId,
+		Status:   enroll.Status,
+	}
+	if enroll.Status == model.EnrollmentWaitlisted {
+		positions, err := c.client.WaitlistPositions(enroll.UserId)
+		if err != nil {
+			return dto.EnrollRequestResponseDto{}, err
+		}
+		response.WaitlistPosition = positions[enroll.CourseId]
+	}
+	return response, nil
 }
 
-func (c *inscriptionService) GetMyCourses(id uuid.UUID) (courseDto.GetAllCourses, error) {
+func (c *inscriptionService) Unenroll(userID uuid.UUID, courseID uuid.UUID) error {
+	_, err := c.client.Unenroll(userID, courseID)
+	return err
+}
+
+func (c *inscriptionService) GetMyCourses(id uuid.UUID) (dto.EnrolledCourses, error) {
 	response, err := c.client.GetMyCourses(id)
 	if err != nil {
 		return nil, err
 	}
-	var courses courseDto.GetAllCourses
+	courseIds := make([]uuid.UUID, 0, len(response))
+	for _, data := range response {
+		courseIds = append(courseIds, data.Id)
+	}
+	progresses, err := c.progress.ForUser(id, courseIds)
+	if err != nil {
+		return nil, err
+	}
+	positions, err := c.client.WaitlistPositions(id)
+	if err != nil {
+		return nil, err
+	}
+	var courses dto.EnrolledCourses
 	for _, data := range response {
-		course := courseDto.GetCourseDto{
-			Id:                 data.Id,
-			CategoryID:         data.CategoryID,
-			CourseName:         data.CourseName,
-			CourseDescription:  data.CourseDescription,
-			CoursePrice:        data.CoursePrice,
-			CourseDuration:     data.CourseDuration,
-			CourseCapacity:     data.CourseCapacity,
-			CourseInitDate:     data.CourseInitDate,
-			CourseState:        data.CourseState,
-			CourseImage:        data.CourseImage,
-			CourseCategoryName: data.Category.CategoryName,
+		summary := progresses[data.Id]
+		course := dto.EnrolledCourse{
+			GetCourseDto:     toCourseDto(data),
+			EnrollmentStatus: model.EnrollmentActive,
+			Progress:         summary.Percent(),
+			LastViewedAt:     summary.LastViewedAt,
+			CompletedAt:      summary.CompletedAt,
+		}
+		if position, waiting := positions[data.Id]; waiting {
+			course.EnrollmentStatus = model.EnrollmentWaitlisted
+			course.WaitlistPosition = position
 		}
 		courses = append(courses, course)
 	}
@@ -68,12 +108,20 @@ func (c *inscriptionService) GetMyStudents(id uuid.UUID) (dto.StudentsInCourse,
 	if err != nil {
 		return nil, err
 	}
+	progresses, err := c.progress.ForCourse(id)
+	if err != nil {
+		return nil, err
+	}
 	var students dto.StudentsInCourse
 	for _, data := range response {
+		summary := progresses[data.Id]
 		studentDto := dto.Student{
-			UserId:   data.Id,
-			UserName: data.Name,
-			Avatar:   data.Avatar,
+			UserId:       data.Id,
+			UserName:     data.Name,
+			Avatar:       data.Avatar,
+			Progress:     summary.Percent(),
+			LastViewedAt: summary.LastViewedAt,
+			CompletedAt:  summary.CompletedAt,
 		}
 		students = append(students, studentDto)
 	}
@@ -84,5 +132,5 @@ func (c *inscriptionService) IsUserEnrolled(userID uuid.UUID, courseID uuid.UUID
 	return c.client.IsUserEnrolled(userID, courseID)
 }
 func (c *inscriptionService) CourseExist(course_id uuid.UUID) (bool, error) {
-	return c.client.CourseExist(course_id)
+	return c.client.CourseExist(course_id, c.now())
 }
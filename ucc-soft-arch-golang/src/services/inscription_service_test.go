@@ -9,6 +9,7 @@ import (
 	"gorm.io/gorm"
 
 	inscClient "github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/inscriptos"
+	progressClient "github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/progress"
 	dto "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/inscription"
 	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
 )
@@ -18,7 +19,7 @@ func setupInscriptosClientSQLite(t *testing.T) *inscClient.InscriptosClient {
 	if err != nil {
 		t.Fatalf("failed to open sqlite db: %v", err)
 	}
-	require.NoError(t, db.AutoMigrate(&model.User{}, &model.Category{}, &model.Course{}, &model.Inscripto{}))
+	require.NoError(t, db.AutoMigrate(&model.User{}, &model.Category{}, &model.Course{}, &model.Inscripto{}, &model.Cohort{}, &model.Section{}, &model.Lesson{}, &model.LessonProgress{}, &model.CompletionRule{}))
 	return inscClient.NewInscriptionClient(db)
 }
 
@@ -30,11 +31,11 @@ func seedUser(t *testing.T, db *gorm.DB, email, name string) model.User {
 
 func TestInscriptionService_Enroll_And_Queries(t *testing.T) {
 	client := setupInscriptosClientSQLite(t)
-	svc := NewInscriptionService(client)
+	svc := NewInscriptionService(client, progressClient.NewProgressClient(client.Db))
 
 	cat := model.Category{CategoryName: "Cloud"}
 	require.NoError(t, client.Db.Create(&cat).Error)
-	course := model.Course{CourseName: "Azure 101", CourseDescription: "intro", CoursePrice: 1, CourseDuration: 1, CourseCapacity: 10, CourseInitDate: "2024-01-01", CourseState: true, CategoryID: cat.Id}
+	course := model.Course{CourseName: "Azure 101", CourseDescription: "intro", CoursePrice: 1, CourseDuration: 1, CourseCapacity: 10, CourseInitDate: "2024-01-01", CourseStatus: model.CoursePublished, CategoryID: cat.Id}
 	require.NoError(t, client.Db.Create(&course).Error)
 	user := seedUser(t, client.Db, "a@b.com", "Alice")
 
@@ -49,6 +50,14 @@ func TestInscriptionService_Enroll_And_Queries(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, students, 1)
 	require.Equal(t, "Alice", students[0].UserName)
+	require.Equal(t, 0, students[0].Progress)
+
+	// GetMyCourses
+	mine, err := svc.GetMyCourses(user.Id)
+	require.NoError(t, err)
+	require.Len(t, mine, 1)
+	require.Equal(t, "Azure 101", mine[0].CourseName)
+	require.Nil(t, mine[0].CompletedAt)
 
 	// IsUserEnrolled
 	enrolled, err := svc.IsUserEnrolled(user.Id, course.Id)
@@ -63,3 +72,35 @@ func TestInscriptionService_Enroll_And_Queries(t *testing.T) {
 	require.NoError(t, err)
 	require.False(t, noexist)
 }
+
+func TestInscriptionService_Waitlist(t *testing.T) {
+	client := setupInscriptosClientSQLite(t)
+	svc := NewInscriptionService(client, progressClient.NewProgressClient(client.Db))
+	cat := model.Category{CategoryName: "Cloud"}
+	require.NoError(t, client.Db.Create(&cat).Error)
+	course := model.Course{CourseName: "Azure 101", CourseCapacity: 1, CourseStatus: model.CoursePublished, CategoryID: cat.Id}
+	require.NoError(t, client.Db.Create(&course).Error)
+	alice := seedUser(t, client.Db, "a@b.com", "Alice")
+	bob := seedUser(t, client.Db, "b@b.com", "Bob")
+
+	first, err := svc.Enroll(dto.EnrollRequestResponseDto{CourseId: course.Id, UserId: alice.Id})
+	require.NoError(t, err)
+	require.Equal(t, model.EnrollmentActive, first.Status)
+	require.Zero(t, first.WaitlistPosition)
+	second, err := svc.Enroll(dto.EnrollRequestResponseDto{CourseId: course.Id, UserId: bob.Id})
+	require.NoError(t, err)
+	require.Equal(t, model.EnrollmentWaitlisted, second.Status)
+	require.Equal(t, 1, second.WaitlistPosition)
+
+	mine, err := svc.GetMyCourses(bob.Id)
+	require.NoError(t, err)
+	require.Len(t, mine, 1)
+	require.Equal(t, model.EnrollmentWaitlisted, mine[0].EnrollmentStatus)
+	require.Equal(t, 1, mine[0].WaitlistPosition)
+
+	require.NoError(t, svc.Unenroll(alice.Id, course.Id))
+	mine, err = svc.GetMyCourses(bob.Id)
+	require.NoError(t, err)
+	require.Equal(t, model.EnrollmentActive, mine[0].EnrollmentStatus)
+	require.Zero(t, mine[0].WaitlistPosition)
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/loginattempts"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/users"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/config"
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/google/uuid"
+)
+
+// Defaults used when the LOGIN_* variables are not configured.
+const (
+	DefaultLoginMaxAttempts      = 5
+	DefaultLoginMaxAttemptsPerIp = 20
+	DefaultLoginBackoffBase      = time.Second
+	DefaultLoginLockout          = 15 * time.Minute
+)
+
+const (
+	AuditLoginLocked    = "login_locked"
+	AuditAccountUnlock  = "account_unlocked"
+	emailIdentifier     = "email:"
+	ipAddressIdentifier = "ip:"
+)
+
+type ILoginThrottleService interface {
+	// Check fails with TOO_MANY_ATTEMPTS while the email or the ip is blocked.
+	Check(email string, ip string) error
+	// RegisterFailure counts a failed login for both the email and the ip.
+	RegisterFailure(email string, ip string) error
+	// RegisterSuccess clears the failures of the email.
+	RegisterSuccess(email string, ip string) error
+	// Unlock clears the lockout of a user, on behalf of an admin.
+	Unlock(userId uuid.UUID, adminId uuid.UUID) error
+}
+
+type loginThrottleService struct {
+	client      loginattempts.LoginAttemptsClient
+	users       users.UsersClient
+	audit       IAuditService
+	maxPerEmail int
+	maxPerIp    int
+	backoffBase time.Duration
+	lockout     time.Duration
+}
+
+func NewLoginThrottleService(client *loginattempts.LoginAttemptsClient, usersClient *users.UsersClient, audit IAuditService) ILoginThrottleService {
+	envs := config.LoadEnvs(".env")
+	return &loginThrottleService{
+		client:      *client,
+		users:       *usersClient,
+		audit:       audit,
+		maxPerEmail: config.GetInt(envs, "LOGIN_MAX_ATTEMPTS", DefaultLoginMaxAttempts),
+		maxPerIp:    config.GetInt(envs, "LOGIN_MAX_ATTEMPTS_PER_IP", DefaultLoginMaxAttemptsPerIp),
+		backoffBase: config.GetDuration(envs, "LOGIN_BACKOFF_BASE", DefaultLoginBackoffBase),
+		lockout:     config.GetDuration(envs, "LOGIN_LOCKOUT_DURATION", DefaultLoginLockout),
+	}
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func (l *loginThrottleService) Check(email string, ip string) error {
+	now := time.Now()
+	for _, identifier := range []string{emailIdentifier + normalizeEmail(email), ipAddressIdentifier + ip} {
+		attempt, err := l.client.FindByIdentifier(identifier)
+		if err != nil {
+			return err
+		}
+		if now.Before(attempt.BlockedUntil) {
+			wait := attempt.BlockedUntil.Sub(now).Round(time.Second)
+			if wait < time.Second {
+				wait = time.Second
+			}
+			// the same answer for existing and unknown emails
+			return customError.NewError("TOO_MANY_ATTEMPTS",
+				fmt.Sprintf("Too many failed login attempts, try again in %s", wait),
+				http.StatusTooManyRequests)
+		}
+	}
+	return nil
+}
+
+func (l *loginThrottleService) RegisterFailure(email string, ip string) error {
+	email = normalizeEmail(email)
+	if err := l.registerFailure(emailIdentifier+email, l.maxPerEmail, email, ip); err != nil {
+		return err
+	}
+	return l.registerFailure(ipAddressIdentifier+ip, l.maxPerIp, email, ip)
+}
+
+func (l *loginThrottleService) registerFailure(identifier string, max int, email string, ip string) error {
+	now := time.Now()
+	locked := false
+	attempt, err := l.client.Update(identifier, func(attempt *model.LoginAttempt) {
+		// failures older than a lockout period are forgotten
+		if now.Sub(attempt.LastFailureAt) > l.lockout {
+			attempt.Failures = 0
+		}
+		attempt.Failures++
+		attempt.LastFailureAt = now
+		if attempt.Failures >= max {
+			attempt.BlockedUntil = now.Add(l.lockout)
+			locked = true
+			return
+		}
+		attempt.BlockedUntil = now.Add(l.backoff(attempt.Failures))
+	})
+	if err != nil {
+		return err
+	}
+	if !locked {
+		return nil
+	}
+	entry := model.AuditLog{
+		Event:   AuditLoginLocked,
+		Ip:      ip,
+		Details: fmt.Sprintf("%s locked until %s after %d failed attempts", identifier, attempt.BlockedUntil.UTC().Format(time.RFC3339), attempt.Failures),
+	}
+	if strings.HasPrefix(identifier, emailIdentifier) {
+		entry.Email = email
+		if user, err := l.users.FindByEmail(email); err == nil {
+			entry.UserId = user.Id
+		}
+	}
+	return l.audit.Record(entry)
+}
+
+// backoff doubles the wait after every failure, starting at the second one,
+// and never exceeds the lockout.
+func (l *loginThrottleService) backoff(failures int) time.Duration {
+	if failures < 2 {
+		return 0
+	}
+	delay := l.backoffBase
+	for i := 2; i < failures && delay < l.lockout; i++ {
+		delay *= 2
+	}
+	if delay > l.lockout {
+		return l.lockout
+	}
+	return delay
+}
+
+func (l *loginThrottleService) RegisterSuccess(email string, ip string) error {
+	return l.client.Reset(emailIdentifier + normalizeEmail(email))
+}
+
+func (l *loginThrottleService) Unlock(userId uuid.UUID, adminId uuid.UUID) error {
+	user, err := l.users.FindById(userId)
+	if err != nil {
+		return err
+	}
+	email := normalizeEmail(user.Email)
+	if err := l.client.Reset(emailIdentifier + email); err != nil {
+		return err
+	}
+	return l.audit.Record(model.AuditLog{
+		Event:   AuditAccountUnlock,
+		ActorId: adminId,
+		UserId:  user.Id,
+		Email:   email,
+	})
+}
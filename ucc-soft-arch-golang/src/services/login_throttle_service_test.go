@@ -0,0 +1,114 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	github_com_glebarez_sqlite "github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	auditClient "github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/audit"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/loginattempts"
+	usersClient "github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/users"
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+)
+
+// newTestThrottle locks after 3 failures and skips the backoff so tests
+// don't depend on the clock.
+func newTestThrottle(db *gorm.DB) *loginThrottleService {
+	svc := NewLoginThrottleService(
+		loginattempts.NewLoginAttemptsClient(db),
+		usersClient.NewUsersClient(db),
+		NewAuditService(auditClient.NewAuditClient(db))).(*loginThrottleService)
+	svc.maxPerEmail = 3
+	svc.maxPerIp = 10
+	svc.backoffBase = time.Nanosecond
+	svc.lockout = time.Hour
+	return svc
+}
+
+func requireErrorCode(t *testing.T, err error, code string) {
+	t.Helper()
+	require.Error(t, err)
+	ce, ok := err.(*customError.Error)
+	require.True(t, ok, "expected custom error, got %v", err)
+	require.Equal(t, code, ce.Code)
+}
+
+func setupThrottle(t *testing.T) (*loginThrottleService, *gorm.DB, model.User) {
+	db, err := gorm.Open(github_com_glebarez_sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	require.NoError(t, db.AutoMigrate(&model.User{}, &model.LoginAttempt{}, &model.AuditLog{}))
+	user := model.User{Email: "locked@test.com", Name: "locked", Password: "x"}
+	require.NoError(t, db.Create(&user).Error)
+	return newTestThrottle(db), db, user
+}
+
+func TestLoginThrottle_LocksEmailAndAudits(t *testing.T) {
+	svc, db, user := setupThrottle(t)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, svc.Check(user.Email, "10.0.0.1"))
+		require.NoError(t, svc.RegisterFailure(user.Email, "10.0.0.1"))
+	}
+	// blocked for the email from any ip, and regardless of case
+	requireErrorCode(t, svc.Check("LOCKED@test.com", "10.9.9.9"), "TOO_MANY_ATTEMPTS")
+
+	var entries model.AuditLogs
+	require.NoError(t, db.Where("event = ?", AuditLoginLocked).Find(&entries).Error)
+	require.Len(t, entries, 1)
+	require.Equal(t, user.Id, entries[0].UserId)
+}
+
+func TestLoginThrottle_LocksUnknownEmailsToo(t *testing.T) {
+	svc, _, _ := setupThrottle(t)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, svc.RegisterFailure("nobody@test.com", "10.0.0.1"))
+	}
+	requireErrorCode(t, svc.Check("nobody@test.com", "10.0.0.2"), "TOO_MANY_ATTEMPTS")
+}
+
+func TestLoginThrottle_LocksIp(t *testing.T) {
+	svc, _, _ := setupThrottle(t)
+	for i := 0; i < 10; i++ {
+		require.NoError(t, svc.RegisterFailure(uuid.NewString()+"@test.com", "10.0.0.1"))
+	}
+	requireErrorCode(t, svc.Check("fresh@test.com", "10.0.0.1"), "TOO_MANY_ATTEMPTS")
+	require.NoError(t, svc.Check("fresh@test.com", "10.0.0.2"))
+}
+
+func TestLoginThrottle_SuccessResetsAndUnlock(t *testing.T) {
+	svc, db, user := setupThrottle(t)
+	require.NoError(t, svc.RegisterFailure(user.Email, "10.0.0.1"))
+	require.NoError(t, svc.RegisterFailure(user.Email, "10.0.0.1"))
+	require.NoError(t, svc.RegisterSuccess(user.Email, "10.0.0.1"))
+	// counter started over, two more failures don't lock
+	require.NoError(t, svc.RegisterFailure(user.Email, "10.0.0.1"))
+	require.NoError(t, svc.RegisterFailure(user.Email, "10.0.0.1"))
+	require.NoError(t, svc.Check(user.Email, "10.0.0.1"))
+
+	require.NoError(t, svc.RegisterFailure(user.Email, "10.0.0.1"))
+	requireErrorCode(t, svc.Check(user.Email, "10.0.0.1"), "TOO_MANY_ATTEMPTS")
+
+	admin := uuid.New()
+	require.NoError(t, svc.Unlock(user.Id, admin))
+	require.NoError(t, svc.Check(user.Email, "10.0.0.1"))
+
+	var entry model.AuditLog
+	require.NoError(t, db.Where("event = ?", AuditAccountUnlock).First(&entry).Error)
+	require.Equal(t, admin, entry.ActorId)
+}
+
+func TestLoginThrottle_Backoff(t *testing.T) {
+	svc := &loginThrottleService{backoffBase: time.Second, lockout: 10 * time.Second}
+	require.Equal(t, time.Duration(0), svc.backoff(1))
+	require.Equal(t, time.Second, svc.backoff(2))
+	require.Equal(t, 2*time.Second, svc.backoff(3))
+	require.Equal(t, 8*time.Second, svc.backoff(5))
+	require.Equal(t, 10*time.Second, svc.backoff(6))
+}
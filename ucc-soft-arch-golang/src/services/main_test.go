@@ -0,0 +1,28 @@
+package services
+
+import (
+	"os"
+	"testing"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/password"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestMain(m *testing.M) {
+	// production argon2id parameters make every hash take tens of ms
+	password.SetConfig(password.Config{
+		Algorithm:  password.AlgArgon2id,
+		Argon2:     password.Argon2Params{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32},
+		BcryptCost: bcrypt.MinCost,
+	})
+	os.Exit(m.Run())
+}
+
+// legacyHash hashes like accounts created before argon2id did.
+func legacyHash(plain string) string {
+	hash, err := bcrypt.GenerateFromPassword([]byte(plain), bcrypt.MinCost)
+	if err != nil {
+		panic(err)
+	}
+	return string(hash)
+}
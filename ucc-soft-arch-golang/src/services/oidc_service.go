@@ -0,0 +1,255 @@
+package services
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/identities"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/oauthstates"
+	usersClient "github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/users"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/config"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/users"
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/oidc"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/password"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/securetoken"
+	"github.com/google/uuid"
+)
+
+// DefaultOIDCStateTTL is used when OIDC_STATE_TTL is not configured.
+const DefaultOIDCStateTTL = 10 * time.Minute
+
+type IOIDCService interface {
+	// AuthorizationURL starts an external login and returns the provider URL
+	// the browser has to visit, with the state the callback must bring back.
+	AuthorizationURL() (authURL string, state string, err error)
+	// Callback finishes the login with the code and state the provider sent
+	// back. The external identity is linked to a user by verified email, or a
+	// new user is created, and our own tokens are issued unless the user has
+	// to pass a second factor, like in IAuthService.Login.
+	Callback(code string, state string, client users.ClientInfoDto) (users.LoginResultDto, error)
+}
+
+type oidcService struct {
+	provider     *oidc.Provider
+	users        usersClient.UsersClient
+	identities   identities.IdentitiesClient
+	states       oauthstates.OAuthStatesClient
+	tokenService ITokenService
+	twoFactor    ITwoFactorService
+	stateTTL     time.Duration
+}
+
+// NewOIDCService builds the external login service. A nil provider means
+// OIDC is not configured and every call fails with OIDC_DISABLED.
+func NewOIDCService(provider *oidc.Provider, usersClient *usersClient.UsersClient, identitiesClient *identities.IdentitiesClient, statesClient *oauthstates.OAuthStatesClient, tokenService ITokenService, twoFactor ITwoFactorService) IOIDCService {
+	envs := config.LoadEnvs(".env")
+	return &oidcService{
+		provider:     provider,
+		users:        *usersClient,
+		identities:   *identitiesClient,
+		states:       *statesClient,
+		tokenService: tokenService,
+		twoFactor:    twoFactor,
+		stateTTL:     config.GetDuration(envs, "OIDC_STATE_TTL", DefaultOIDCStateTTL),
+	}
+}
+
+func (s *oidcService) AuthorizationURL() (string, string, error) {
+	if s.provider == nil {
+		return "", "", oidcDisabled()
+	}
+
+	state, err := securetoken.Generate(securetoken.DefaultSize)
+	if err != nil {
+		return "", "", customError.NewError("TOKEN_SIGNING_ERROR", "Could not generate login state", http.StatusInternalServerError)
+	}
+	nonce, err := securetoken.Generate(securetoken.DefaultSize)
+	if err != nil {
+		return "", "", customError.NewError("TOKEN_SIGNING_ERROR", "Could not generate login nonce", http.StatusInternalServerError)
+	}
+	verifier, err := oidc.NewCodeVerifier()
+	if err != nil {
+		return "", "", customError.NewError("TOKEN_SIGNING_ERROR", "Could not generate code verifier", http.StatusInternalServerError)
+	}
+
+	authURL, err := s.provider.AuthCodeURL(state, nonce, oidc.CodeChallenge(verifier))
+	if err != nil {
+		log.Printf("oidc discovery failed: %v", err)
+		return "", "", customError.NewError("OIDC_PROVIDER_ERROR", "Identity provider is unavailable", http.StatusBadGateway)
+	}
+
+	now := time.Now()
+	if err := s.states.DeleteExpired(now); err != nil {
+		return "", "", err
+	}
+	_, err = s.states.Create(model.OAuthState{
+		StateHash:    securetoken.Hash(state),
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		ExpiresAt:    now.Add(s.stateTTL),
+	})
+	if err != nil {
+		return "", "", err
+	}
+	return authURL, state, nil
+}
+
+func (s *oidcService) Callback(code string, state string, client users.ClientInfoDto) (users.LoginResultDto, error) {
+	if s.provider == nil {
+		return users.LoginResultDto{}, oidcDisabled()
+	}
+	invalidState := customError.NewError("INVALID_OIDC_STATE", "Login session is invalid or expired, please try again", http.StatusBadRequest)
+	if code == "" || state == "" {
+		return users.LoginResultDto{}, invalidState
+	}
+
+	stored, err := s.states.FindByHash(securetoken.Hash(state))
+	if err != nil {
+		if ce, ok := err.(*customError.Error); ok && ce.Code == "NOT_FOUND" {
+			return users.LoginResultDto{}, invalidState
+		}
+		return users.LoginResultDto{}, err
+	}
+	now := time.Now()
+	if stored.UsedAt != nil || now.After(stored.ExpiresAt) {
+		return users.LoginResultDto{}, invalidState
+	}
+	marked, err := s.states.MarkUsed(stored.ID, now)
+	if err != nil {
+		return users.LoginResultDto{}, err
+	}
+	if !marked {
+		return users.LoginResultDto{}, invalidState
+	}
+
+	idToken, err := s.provider.Exchange(code, stored.CodeVerifier)
+	if err != nil {
+		log.Printf("oidc code exchange failed: %v", err)
+		return users.LoginResultDto{}, customError.NewError("OIDC_EXCHANGE_FAILED", "Could not complete the login with the identity provider", http.StatusUnauthorized)
+	}
+	claims, err := s.provider.VerifyIDToken(idToken, stored.Nonce)
+	if err != nil {
+		log.Printf("oidc id token rejected: %v", err)
+		return users.LoginResultDto{}, customError.NewError("INVALID_ID_TOKEN", "The identity provider returned an invalid token", http.StatusUnauthorized)
+	}
+
+	user, err := s.findOrLinkUser(claims)
+	if err != nil {
+		return users.LoginResultDto{}, err
+	}
+
+	challenge, err := s.twoFactor.Challenge(user)
+	if err != nil {
+		return users.LoginResultDto{}, err
+	}
+	if challenge != nil {
+		return users.LoginResultDto{Challenge: challenge}, nil
+	}
+
+	tokens, err := s.tokenService.IssueTokens(user.Id, user.Role, uuid.Nil, client)
+	if err != nil {
+		return users.LoginResultDto{}, err
+	}
+	return users.LoginResultDto{
+		User: users.GetUserDto{
+			Id:            user.Id,
+			Email:         user.Email,
+			Role:          user.Role,
+			UserName:      user.Name,
+			Avatar:        user.Avatar,
+			EmailVerified: user.EmailVerified,
+		},
+		Tokens: tokens,
+	}, nil
+}
+
+// findOrLinkUser returns the user already linked to the external identity.
+// Otherwise the identity is linked to the user with the same email, or to a
+// new user, but only if the provider verified that email. A local account
+// is only linked once its own email was verified too.
+func (s *oidcService) findOrLinkUser(claims *oidc.Claims) (model.User, error) {
+	identity, err := s.identities.FindBySubject(s.provider.Issuer(), claims.Subject)
+	if err == nil {
+		return s.users.FindById(identity.UserId)
+	}
+	if ce, ok := err.(*customError.Error); !ok || ce.Code != "NOT_FOUND" {
+		return model.User{}, err
+	}
+
+	email := strings.TrimSpace(claims.Email)
+	if email == "" || !claims.EmailVerified {
+		return model.User{}, customError.NewError("EMAIL_NOT_VERIFIED", "The identity provider did not confirm your email", http.StatusForbidden)
+	}
+
+	user, err := s.users.FindByEmail(email)
+	switch {
+	case err == nil:
+		// whoever registered the address never proved they own it, and
+		// their password would keep working on the linked account
+		if !user.EmailVerified {
+			return model.User{}, customError.NewError("ACCOUNT_NOT_VERIFIED", "An account with this email exists but its email is not verified. Verify it and sign in with your password first", http.StatusConflict)
+		}
+	case isNotFound(err):
+		user, err = s.createExternalUser(email, claims)
+		if err != nil {
+			return model.User{}, err
+		}
+	default:
+		return model.User{}, err
+	}
+
+	_, err = s.identities.Create(model.ExternalIdentity{
+		UserId:   user.Id,
+		Provider: s.provider.Issuer(),
+		Subject:  claims.Subject,
+		Email:    email,
+	})
+	if err != nil {
+		return model.User{}, err
+	}
+	return user, nil
+}
+
+// createExternalUser creates a student with a random password; the user can
+// still set one later through the password reset flow.
+func (s *oidcService) createExternalUser(email string, claims *oidc.Claims) (model.User, error) {
+	name := claims.Name
+	if name == "" {
+		name = claims.PreferredUsername
+	}
+	if name == "" {
+		name = strings.SplitN(email, "@", 2)[0]
+	}
+	random, err := securetoken.Generate(securetoken.DefaultSize)
+	if err != nil {
+		return model.User{}, customError.NewError("TOKEN_SIGNING_ERROR", "Could not create user", http.StatusInternalServerError)
+	}
+	hash, err := password.Hash(random)
+	if err != nil {
+		return model.User{}, customError.NewError("UNEXPECTED_ERROR", "Could not create user", http.StatusInternalServerError)
+	}
+	user := model.User{
+		Email:         email,
+		Name:          name,
+		Password:      hash,
+		Role:          model.RoleStudent,
+		EmailVerified: true,
+	}
+	if claims.Picture != "" {
+		user.Avatar = claims.Picture
+	}
+	return s.users.Create(user)
+}
+
+func isNotFound(err error) bool {
+	ce, ok := err.(*customError.Error)
+	return ok && ce.Code == "NOT_FOUND"
+}
+
+func oidcDisabled() error {
+	return customError.NewError("OIDC_DISABLED", "External login is not configured", http.StatusNotFound)
+}
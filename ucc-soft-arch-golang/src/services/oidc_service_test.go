@@ -0,0 +1,147 @@
+package services
+
+import (
+	userDtos "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/users"
+	"testing"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/identities"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/oauthstates"
+	usersClient "github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/users"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/oidc"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/oidc/oidctest"
+	github_com_glebarez_sqlite "github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupOIDC(t *testing.T) (IOIDCService, *oidctest.Server, *gorm.DB) {
+	db, err := gorm.Open(github_com_glebarez_sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	require.NoError(t, db.AutoMigrate(&model.User{}, &model.RefreshToken{}, &model.RevokedToken{}, &model.Session{}, &model.ExternalIdentity{}, &model.OAuthState{}))
+
+	server := oidctest.NewServer("ucc-client")
+	t.Cleanup(server.Close)
+	tokenService, _ := newTokenStack(db)
+	svc := NewOIDCService(
+		oidc.NewProvider(server.Config("http://localhost:3000/auth/oidc/callback")),
+		usersClient.NewUsersClient(db),
+		identities.NewIdentitiesClient(db),
+		oauthstates.NewOAuthStatesClient(db),
+		tokenService,
+		passwordOnly{})
+	return svc, server, db
+}
+
+// login runs the whole browser round trip against the mock provider.
+func login(t *testing.T, svc IOIDCService, server *oidctest.Server, user oidctest.User) (string, string) {
+	t.Helper()
+	authURL, issued, err := svc.AuthorizationURL()
+	require.NoError(t, err)
+	code, state, err := server.Authorize(authURL, user)
+	require.NoError(t, err)
+	require.Equal(t, issued, state)
+	return code, state
+}
+
+func TestOIDCService_CreatesUserOnFirstLogin(t *testing.T) {
+	svc, server, db := setupOIDC(t)
+	external := oidctest.User{Subject: "sub-1", Email: "new@uni.edu", EmailVerified: true, Name: "New Student"}
+
+	code, state := login(t, svc, server, external)
+	result, err := svc.Callback(code, state, userDtos.ClientInfoDto{})
+	require.NoError(t, err)
+	user, tokens := result.User, result.Tokens
+	require.NotEmpty(t, tokens.AccessToken)
+	require.NotEmpty(t, tokens.RefreshToken)
+	require.Equal(t, "new@uni.edu", user.Email)
+	require.Equal(t, "New Student", user.UserName)
+	require.Equal(t, model.RoleStudent, user.Role)
+	require.True(t, user.EmailVerified)
+
+	// the second login finds the linked identity instead of creating a user
+	code, state = login(t, svc, server, external)
+	again, err := svc.Callback(code, state, userDtos.ClientInfoDto{})
+	require.NoError(t, err)
+	require.Equal(t, user.Id, again.User.Id)
+
+	var count int64
+	require.NoError(t, db.Model(&model.User{}).Count(&count).Error)
+	require.Equal(t, int64(1), count)
+}
+
+func TestOIDCService_LinksExistingUserByVerifiedEmail(t *testing.T) {
+	svc, server, db := setupOIDC(t)
+	existing := model.User{Email: "old@uni.edu", Name: "old", Password: "x", Role: model.RoleInstructor, EmailVerified: true}
+	require.NoError(t, db.Create(&existing).Error)
+
+	code, state := login(t, svc, server, oidctest.User{Subject: "sub-2", Email: "old@uni.edu", EmailVerified: true})
+	result, err := svc.Callback(code, state, userDtos.ClientInfoDto{})
+	require.NoError(t, err)
+	require.Equal(t, existing.Id, result.User.Id)
+	require.Equal(t, model.RoleInstructor, result.User.Role)
+	require.True(t, result.User.EmailVerified)
+
+	var identity model.ExternalIdentity
+	require.NoError(t, db.Where("subject = ?", "sub-2").First(&identity).Error)
+	require.Equal(t, existing.Id, identity.UserId)
+	require.Equal(t, server.URL, identity.Provider)
+}
+
+func TestOIDCService_RefusesToLinkUnverifiedAccount(t *testing.T) {
+	svc, server, db := setupOIDC(t)
+	// someone signed up with the address but never verified it
+	squatter := model.User{Email: "student@uni.edu", Name: "squatter", Password: "x"}
+	require.NoError(t, db.Create(&squatter).Error)
+
+	code, state := login(t, svc, server, oidctest.User{Subject: "sub-5", Email: "student@uni.edu", EmailVerified: true})
+	_, err := svc.Callback(code, state, userDtos.ClientInfoDto{})
+	requireErrorCode(t, err, "ACCOUNT_NOT_VERIFIED")
+
+	var count int64
+	require.NoError(t, db.Model(&model.ExternalIdentity{}).Count(&count).Error)
+	require.Zero(t, count)
+}
+
+func TestOIDCService_RejectsUnverifiedEmail(t *testing.T) {
+	svc, server, db := setupOIDC(t)
+	existing := model.User{Email: "victim@uni.edu", Name: "victim", Password: "x"}
+	require.NoError(t, db.Create(&existing).Error)
+
+	code, state := login(t, svc, server, oidctest.User{Subject: "sub-3", Email: "victim@uni.edu", EmailVerified: false})
+	_, err := svc.Callback(code, state, userDtos.ClientInfoDto{})
+	requireErrorCode(t, err, "EMAIL_NOT_VERIFIED")
+
+	var count int64
+	require.NoError(t, db.Model(&model.ExternalIdentity{}).Count(&count).Error)
+	require.Zero(t, count)
+}
+
+func TestOIDCService_StateIsSingleUse(t *testing.T) {
+	svc, server, _ := setupOIDC(t)
+	external := oidctest.User{Subject: "sub-4", Email: "once@uni.edu", EmailVerified: true}
+
+	code, state := login(t, svc, server, external)
+	_, err := svc.Callback(code, state, userDtos.ClientInfoDto{})
+	require.NoError(t, err)
+
+	_, err = svc.Callback(code, state, userDtos.ClientInfoDto{})
+	requireErrorCode(t, err, "INVALID_OIDC_STATE")
+
+	_, err = svc.Callback(code, "forged-state", userDtos.ClientInfoDto{})
+	requireErrorCode(t, err, "INVALID_OIDC_STATE")
+}
+
+func TestOIDCService_Disabled(t *testing.T) {
+	db, err := gorm.Open(github_com_glebarez_sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	tokenService, _ := newTokenStack(db)
+	svc := NewOIDCService(nil, usersClient.NewUsersClient(db), identities.NewIdentitiesClient(db), oauthstates.NewOAuthStatesClient(db), tokenService, passwordOnly{})
+
+	_, _, err = svc.AuthorizationURL()
+	requireErrorCode(t, err, "OIDC_DISABLED")
+	_, err = svc.Callback("code", "state", userDtos.ClientInfoDto{})
+	requireErrorCode(t, err, "OIDC_DISABLED")
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/passwordhistory"
+	usersClient "github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/users"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/config"
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/password"
+	"github.com/google/uuid"
+)
+
+// Defaults used when the PASSWORD_* variables are not configured.
+const (
+	DefaultPasswordMinLength = 8
+	DefaultPasswordHistory   = 5
+)
+
+// MaxPasswordLength bounds the work a single hash can cost.
+const MaxPasswordLength = 128
+
+type IPasswordPolicyService interface {
+	// Validate checks a new password against the policy. userId is uuid.Nil
+	// for a new account; otherwise the password may not be one of the last
+	// ones of that user.
+	Validate(userId uuid.UUID, plain string) error
+	// Remember records the hash of a password the user just set.
+	Remember(userId uuid.UUID, hash string) error
+}
+
+type passwordPolicyService struct {
+	history   passwordhistory.PasswordHistoryClient
+	users     usersClient.UsersClient
+	minLength int
+	keep      int
+	blocklist password.Blocklist
+}
+
+func NewPasswordPolicyService(history *passwordhistory.PasswordHistoryClient, users *usersClient.UsersClient) IPasswordPolicyService {
+	envs := config.LoadEnvs(".env")
+	blocklist := password.DefaultBlocklist()
+	if path := envs.Get("PASSWORD_BLOCKLIST_FILE"); path != "" {
+		if err := blocklist.LoadFile(path); err != nil {
+			log.Printf("could not load password blocklist %s: %v", path, err)
+		}
+	}
+	return &passwordPolicyService{
+		history:   *history,
+		users:     *users,
+		minLength: config.GetInt(envs, "PASSWORD_MIN_LENGTH", DefaultPasswordMinLength),
+		keep:      config.GetInt(envs, "PASSWORD_HISTORY", DefaultPasswordHistory),
+		blocklist: blocklist,
+	}
+}
+
+func (s *passwordPolicyService) Validate(userId uuid.UUID, plain string) error {
+	length := utf8.RuneCountInString(plain)
+	if length < s.minLength {
+		return weakPassword(fmt.Sprintf("Password must be at least %d characters long", s.minLength))
+	}
+	if length > MaxPasswordLength {
+		return weakPassword(fmt.Sprintf("Password must be at most %d characters long", MaxPasswordLength))
+	}
+	if s.blocklist.Contains(plain) {
+		return weakPassword("This password is too common, please choose another one")
+	}
+	if userId == uuid.Nil || s.keep <= 0 {
+		return nil
+	}
+
+	// the current hash is checked too, it predates the history for
+	// accounts created before the policy
+	user, err := s.users.FindById(userId)
+	if err != nil {
+		return err
+	}
+	if password.Compare(plain, user.Password) {
+		return passwordReused(s.keep)
+	}
+	recent, err := s.history.ListRecent(userId, s.keep)
+	if err != nil {
+		return err
+	}
+	for _, entry := range recent {
+		if password.Compare(plain, entry.Hash) {
+			return passwordReused(s.keep)
+		}
+	}
+	return nil
+}
+
+func (s *passwordPolicyService) Remember(userId uuid.UUID, hash string) error {
+	if s.keep <= 0 {
+		return nil
+	}
+	return s.history.Add(model.PasswordHistory{UserId: userId, Hash: hash}, s.keep)
+}
+
+func weakPassword(message string) error {
+	return customError.NewError("WEAK_PASSWORD", message, http.StatusBadRequest)
+}
+
+func passwordReused(keep int) error {
+	return customError.NewError("PASSWORD_REUSED", fmt.Sprintf("Password can't be one of your last %d passwords", keep), http.StatusBadRequest)
+}
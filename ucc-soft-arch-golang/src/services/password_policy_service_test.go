@@ -0,0 +1,60 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/passwordhistory"
+	usersClient "github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/users"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/password"
+	github_com_glebarez_sqlite "github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func newPasswordPolicy(db *gorm.DB) IPasswordPolicyService {
+	return NewPasswordPolicyService(passwordhistory.NewPasswordHistoryClient(db), usersClient.NewUsersClient(db))
+}
+
+func setupPasswordPolicy(t *testing.T) (*passwordPolicyService, *gorm.DB, model.User) {
+	db, err := gorm.Open(github_com_glebarez_sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	require.NoError(t, db.AutoMigrate(&model.User{}, &model.PasswordHistory{}))
+	hashed := legacyHash("legacy-password")
+	user := model.User{Email: "policy@test.com", Name: "policy", Password: hashed}
+	require.NoError(t, db.Create(&user).Error)
+	return newPasswordPolicy(db).(*passwordPolicyService), db, user
+}
+
+func TestPasswordPolicy_LengthAndBlocklist(t *testing.T) {
+	svc, _, _ := setupPasswordPolicy(t)
+	requireErrorCode(t, svc.Validate(uuid.Nil, "short"), "WEAK_PASSWORD")
+	requireErrorCode(t, svc.Validate(uuid.Nil, string(make([]byte, MaxPasswordLength+1))), "WEAK_PASSWORD")
+	requireErrorCode(t, svc.Validate(uuid.Nil, "Password123"), "WEAK_PASSWORD")
+	require.NoError(t, svc.Validate(uuid.Nil, "correct horse battery staple"))
+
+	svc.minLength = 20
+	requireErrorCode(t, svc.Validate(uuid.Nil, "correct horse"), "WEAK_PASSWORD")
+}
+
+func TestPasswordPolicy_RejectsRecentPasswords(t *testing.T) {
+	svc, _, user := setupPasswordPolicy(t)
+	svc.keep = 2
+
+	// the current hash counts even without any history
+	requireErrorCode(t, svc.Validate(user.Id, "legacy-password"), "PASSWORD_REUSED")
+
+	for _, plain := range []string{"first-passphrase", "second-passphrase", "third-passphrase"} {
+		require.NoError(t, svc.Validate(user.Id, plain))
+		hash, err := password.Hash(plain)
+		require.NoError(t, err)
+		require.NoError(t, svc.Remember(user.Id, hash))
+	}
+	requireErrorCode(t, svc.Validate(user.Id, "third-passphrase"), "PASSWORD_REUSED")
+	requireErrorCode(t, svc.Validate(user.Id, "second-passphrase"), "PASSWORD_REUSED")
+	// only the last two are kept
+	require.NoError(t, svc.Validate(user.Id, "first-passphrase"))
+}
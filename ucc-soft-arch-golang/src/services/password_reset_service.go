@@ -0,0 +1,150 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/passwordreset"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/users"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/config"
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/mailer"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/password"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/securetoken"
+)
+
+// DefaultPasswordResetTTL is used when PASSWORD_RESET_TTL is not configured.
+const DefaultPasswordResetTTL = time.Hour
+
+// DefaultFrontendURL is used to build links sent by email when FRONTEND_URL
+// is not configured.
+const DefaultFrontendURL = "http://localhost:3000"
+
+type IPasswordResetService interface {
+	// RequestReset emails a reset link. Unknown emails are ignored so callers
+	// can't use it to find out which accounts exist.
+	RequestReset(email string) error
+	// ResetPassword consumes a reset token and sets the new password.
+	ResetPassword(token string, password string) error
+}
+
+type passwordResetService struct {
+	users      users.UsersClient
+	client     passwordreset.PasswordResetClient
+	mailer     mailer.Mailer
+	revocation IRevocationService
+	policy     IPasswordPolicyService
+	ttl        time.Duration
+	resetURL   string
+}
+
+func NewPasswordResetService(usersClient *users.UsersClient, client *passwordreset.PasswordResetClient, mail mailer.Mailer, revocation IRevocationService, policy IPasswordPolicyService) IPasswordResetService {
+	envs := config.LoadEnvs(".env")
+	frontend := envs.Get("FRONTEND_URL")
+	if frontend == "" {
+		frontend = DefaultFrontendURL
+	}
+	return &passwordResetService{
+		users:      *usersClient,
+		client:     *client,
+		mailer:     mail,
+		revocation: revocation,
+		policy:     policy,
+		ttl:        config.GetDuration(envs, "PASSWORD_RESET_TTL", DefaultPasswordResetTTL),
+		resetURL:   strings.TrimRight(frontend, "/") + "/auth/reset-password",
+	}
+}
+
+func (s *passwordResetService) RequestReset(email string) error {
+	user, err := s.users.FindByEmail(email)
+	if err != nil {
+		if ce, ok := err.(*customError.Error); ok && ce.Code == "NOT_FOUND" {
+			return nil
+		}
+		return err
+	}
+
+	token, err := securetoken.Generate(securetoken.DefaultSize)
+	if err != nil {
+		return customError.NewError("TOKEN_GENERATION_ERROR", "Could not generate reset token", http.StatusInternalServerError)
+	}
+	now := time.Now()
+	// Only the latest link is valid.
+	if err := s.client.InvalidateForUser(user.Id, now); err != nil {
+		return err
+	}
+	_, err = s.client.Create(model.PasswordReset{
+		UserId:    user.Id,
+		TokenHash: securetoken.Hash(token),
+		ExpiresAt: now.Add(s.ttl),
+	})
+	if err != nil {
+		return err
+	}
+
+	link := s.resetURL + "?token=" + url.QueryEscape(token)
+	err = s.mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nWe received a request to reset your password. Use the link below within %s:\n\n%s\n\nIf you didn't ask for this you can ignore this email.\n",
+			user.Name, s.ttl, link),
+	})
+	if err != nil {
+		return customError.NewError("MAIL_ERROR", "Could not send reset email", http.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (s *passwordResetService) ResetPassword(token string, newPassword string) error {
+	invalid := customError.NewError("INVALID_RESET_TOKEN", "Invalid or expired reset token", http.StatusBadRequest)
+	if token == "" {
+		return invalid
+	}
+	if newPassword == "" {
+		return customError.NewError("PASSWORD_REQUIRED", "password is required", http.StatusBadRequest)
+	}
+
+	reset, err := s.client.FindByHash(securetoken.Hash(token))
+	if err != nil {
+		if ce, ok := err.(*customError.Error); ok && ce.Code == "NOT_FOUND" {
+			return invalid
+		}
+		return err
+	}
+	now := time.Now()
+	if reset.UsedAt != nil || now.After(reset.ExpiresAt) {
+		return invalid
+	}
+	// checked before the token is used up so the user can pick another one
+	if err := s.policy.Validate(reset.UserId, newPassword); err != nil {
+		return err
+	}
+	marked, err := s.client.MarkUsed(reset.ID, now)
+	if err != nil {
+		return err
+	}
+	if !marked {
+		return invalid
+	}
+
+	hashed, err := password.Hash(newPassword)
+	if err != nil {
+		return customError.NewError("UNEXPECTED_ERROR", "Could not hash password", http.StatusInternalServerError)
+	}
+	if _, err := s.users.UpdateUser(model.User{Id: reset.UserId, Password: hashed}); err != nil {
+		return err
+	}
+	if err := s.policy.Remember(reset.UserId, hashed); err != nil {
+		return err
+	}
+	// a reset forced by an admin is done now
+	if err := s.users.SetPasswordResetRequired(reset.UserId, false); err != nil {
+		return err
+	}
+	// Whoever knew the old password must not keep a session.
+	return s.revocation.RevokeAllForUser(reset.UserId)
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	github_com_glebarez_sqlite "github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/passwordreset"
+	usersClient "github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/users"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/mailer"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/password"
+)
+
+type captureMailer struct {
+	sent []mailer.Message
+}
+
+func (m *captureMailer) Send(message mailer.Message) error {
+	m.sent = append(m.sent, message)
+	return nil
+}
+
+var resetTokenPattern = regexp.MustCompile(`token=([A-Za-z0-9_\-%]+)`)
+
+func setupPasswordReset(t *testing.T) (IPasswordResetService, *captureMailer, *usersClient.UsersClient, model.User) {
+	db, err := gorm.Open(github_com_glebarez_sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	require.NoError(t, db.AutoMigrate(&model.User{}, &model.RefreshToken{}, &model.RevokedToken{}, &model.Session{}, &model.PasswordReset{}, &model.PasswordHistory{}))
+	hashed := legacyHash("old-password")
+	client := usersClient.NewUsersClient(db)
+	user, err := client.Create(model.User{Email: "reset@test.com", Name: "reset", Password: hashed})
+	require.NoError(t, err)
+
+	_, revocation := newTokenStack(db)
+	mail := &captureMailer{}
+	svc := NewPasswordResetService(client, passwordreset.NewPasswordResetClient(db), mail, revocation, newPasswordPolicy(db))
+	return svc, mail, client, user
+}
+
+func sentToken(t *testing.T, mail *captureMailer) string {
+	require.NotEmpty(t, mail.sent)
+	match := resetTokenPattern.FindStringSubmatch(mail.sent[len(mail.sent)-1].Body)
+	require.Len(t, match, 2)
+	return match[1]
+}
+
+func TestPasswordResetService_ResetFlow(t *testing.T) {
+	svc, mail, client, user := setupPasswordReset(t)
+
+	require.NoError(t, client.SetPasswordResetRequired(user.Id, true))
+	require.NoError(t, svc.RequestReset(user.Email))
+	require.Equal(t, user.Email, mail.sent[0].To)
+	token := sentToken(t, mail)
+
+	require.NoError(t, svc.ResetPassword(token, "new-password"))
+	stored, err := client.FindById(user.Id)
+	require.NoError(t, err)
+	require.True(t, password.Compare("new-password", stored.Password))
+	require.Equal(t, 1, stored.TokenVersion)
+	require.False(t, stored.PasswordResetRequired)
+
+	// single use
+	require.Error(t, svc.ResetPassword(token, "another-password"))
+}
+
+func TestPasswordResetService_EnforcesPolicy(t *testing.T) {
+	svc, mail, _, user := setupPasswordReset(t)
+	require.NoError(t, svc.RequestReset(user.Email))
+	token := sentToken(t, mail)
+
+	requireErrorCode(t, svc.ResetPassword(token, "short"), "WEAK_PASSWORD")
+	requireErrorCode(t, svc.ResetPassword(token, "old-password"), "PASSWORD_REUSED")
+	// a rejected password doesn't use up the token
+	require.NoError(t, svc.ResetPassword(token, "new-password"))
+}
+
+func TestPasswordResetService_UnknownEmailIsSilent(t *testing.T) {
+	svc, mail, _, _ := setupPasswordReset(t)
+	require.NoError(t, svc.RequestReset("nobody@test.com"))
+	require.Empty(t, mail.sent)
+}
+
+func TestPasswordResetService_NewRequestInvalidatesPrevious(t *testing.T) {
+	svc, mail, _, user := setupPasswordReset(t)
+	require.NoError(t, svc.RequestReset(user.Email))
+	first := sentToken(t, mail)
+	require.NoError(t, svc.RequestReset(user.Email))
+	second := sentToken(t, mail)
+
+	require.Error(t, svc.ResetPassword(first, "new-password"))
+	require.NoError(t, svc.ResetPassword(second, "new-password"))
+}
+
+func TestPasswordResetService_ExpiredToken(t *testing.T) {
+	svc, mail, _, user := setupPasswordReset(t)
+	svc.(*passwordResetService).ttl = -time.Minute
+	require.NoError(t, svc.RequestReset(user.Email))
+	require.Error(t, svc.ResetPassword(sentToken(t, mail), "new-password"))
+}
+
+func TestPasswordResetService_InvalidInput(t *testing.T) {
+	svc, _, _, _ := setupPasswordReset(t)
+	require.Error(t, svc.ResetPassword("", "new-password"))
+	require.Error(t, svc.ResetPassword("unknown", "new-password"))
+	require.Error(t, svc.ResetPassword("unknown", ""))
+}
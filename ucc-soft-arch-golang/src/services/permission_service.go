@@ -0,0 +1,61 @@
+package services
+
+import (
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/roles"
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/jwt"
+)
+
+type IPermissionService interface {
+	HasPermission(role string, permission string) (bool, error)
+	// Allows checks the permission for an authenticated request. Requests
+	// made with an API key also need the permission among the key scopes.
+	Allows(claims *jwt.CustomClaims, permission string) (bool, error)
+	GetRoles() (model.Roles, error)
+	// RoleExists is used to validate role changes.
+	RoleExists(role string) (bool, error)
+}
+
+type permissionService struct {
+	client roles.RolesClient
+}
+
+func NewPermissionService(client *roles.RolesClient) IPermissionService {
+	return &permissionService{client: *client}
+}
+
+func (p *permissionService) HasPermission(role string, permission string) (bool, error) {
+	return p.client.HasPermission(role, permission)
+}
+
+func (p *permissionService) Allows(claims *jwt.CustomClaims, permission string) (bool, error) {
+	if claims.IsAPIKey() {
+		scoped := false
+		for _, scope := range claims.Scopes {
+			if scope == permission {
+				scoped = true
+				break
+			}
+		}
+		if !scoped {
+			return false, nil
+		}
+	}
+	return p.client.HasPermission(claims.Role, permission)
+}
+
+func (p *permissionService) GetRoles() (model.Roles, error) {
+	return p.client.FindAll()
+}
+
+func (p *permissionService) RoleExists(role string) (bool, error) {
+	_, err := p.client.FindByName(role)
+	if err != nil {
+		if ce, ok := err.(*customError.Error); ok && ce.Code == "NOT_FOUND" {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"testing"
+
+	github_com_glebarez_sqlite "github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/roles"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/config"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/jwt"
+)
+
+func TestPermissionService(t *testing.T) {
+	db, err := gorm.Open(github_com_glebarez_sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	require.NoError(t, db.AutoMigrate(&model.Permission{}, &model.Role{}))
+	require.NoError(t, config.SeedRoles(db))
+	svc := NewPermissionService(roles.NewRolesClient(db))
+
+	ok, err := svc.HasPermission(model.RoleModerator, model.PermissionCommentsModerate)
+	require.NoError(t, err)
+	require.True(t, ok)
+	ok, err = svc.HasPermission(model.RoleModerator, model.PermissionCoursesWrite)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	exists, err := svc.RoleExists(model.RoleInstructor)
+	require.NoError(t, err)
+	require.True(t, exists)
+	exists, err = svc.RoleExists("superuser")
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	all, err := svc.GetRoles()
+	require.NoError(t, err)
+	require.NotEmpty(t, all)
+}
+
+func TestPermissionService_AllowsLimitsAPIKeysToScopes(t *testing.T) {
+	db, err := gorm.Open(github_com_glebarez_sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	require.NoError(t, db.AutoMigrate(&model.Permission{}, &model.Role{}))
+	require.NoError(t, config.SeedRoles(db))
+	svc := NewPermissionService(roles.NewRolesClient(db))
+
+	session := jwt.NewCustomClaims(uuid.New(), model.RoleAdmin)
+	ok, err := svc.Allows(session, model.PermissionUsersManage)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	key := &jwt.CustomClaims{Id: uuid.New(), Role: model.RoleAdmin, APIKeyId: uuid.New(), Scopes: []string{model.PermissionAuditRead}}
+	ok, err = svc.Allows(key, model.PermissionAuditRead)
+	require.NoError(t, err)
+	require.True(t, ok)
+	ok, err = svc.Allows(key, model.PermissionUsersManage)
+	require.NoError(t, err)
+	require.False(t, ok, "an admin's key is limited to its scopes")
+
+	// the scope alone isn't enough when the owner's role lacks the permission
+	key.Role = model.RoleStudent
+	ok, err = svc.Allows(key, model.PermissionAuditRead)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"net/http"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/prerequisites"
+	dto "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/courses"
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/google/uuid"
+)
+
+type IPrerequisiteService interface {
+	GetPrerequisites(courseId uuid.UUID) (dto.PrerequisitesDto, error)
+	// SetPrerequisites replaces the prerequisites of a course. It fails with
+	// PREREQUISITE_CYCLE if a course would end up requiring itself.
+	SetPrerequisites(courseId uuid.UUID, request dto.PrerequisitesRequestDto) (dto.PrerequisitesDto, error)
+	// UnmetPrerequisites lists the prerequisites the user hasn't completed.
+	// It's empty when an admin waived them for the user.
+	UnmetPrerequisites(userId uuid.UUID, courseId uuid.UUID) (dto.PrerequisitesDto, error)
+	GrantWaiver(courseId uuid.UUID, userId uuid.UUID, grantedBy uuid.UUID) (dto.PrerequisiteWaiverDto, error)
+	RevokeWaiver(courseId uuid.UUID, userId uuid.UUID) error
+}
+
+type prerequisiteService struct {
+	client prerequisites.PrerequisitesClient
+}
+
+func NewPrerequisiteService(client *prerequisites.PrerequisitesClient) IPrerequisiteService {
+	return &prerequisiteService{client: *client}
+}
+
+func (s *prerequisiteService) GetPrerequisites(courseId uuid.UUID) (dto.PrerequisitesDto, error) {
+	if err := s.requireCourse(courseId); err != nil {
+		return nil, err
+	}
+	courses, err := s.client.GetPrerequisites(courseId)
+	if err != nil {
+		return nil, err
+	}
+	return toPrerequisitesDto(courses), nil
+}
+
+func (s *prerequisiteService) SetPrerequisites(courseId uuid.UUID, request dto.PrerequisitesRequestDto) (dto.PrerequisitesDto, error) {
+	if err := s.requireCourse(courseId); err != nil {
+		return nil, err
+	}
+	ids := []uuid.UUID{}
+	seen := map[uuid.UUID]bool{}
+	for _, id := range request.CourseIds {
+		if id == courseId {
+			return nil, customError.NewError("INVALID_PREREQUISITE", "A course can't require itself", http.StatusBadRequest)
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	found, err := s.client.FindCourses(ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(found) != len(ids) {
+		return nil, customError.NewError("INVALID_PREREQUISITE", "Prerequisites must be existing courses", http.StatusBadRequest)
+	}
+
+	err = s.client.Replace(courseId, ids, func(graph prerequisites.Graph) error {
+		if requires(graph, ids, courseId) {
+			return customError.NewError("PREREQUISITE_CYCLE", "A course can't end up requiring itself", http.StatusConflict)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toPrerequisitesDto(found), nil
+}
+
+func (s *prerequisiteService) UnmetPrerequisites(userId uuid.UUID, courseId uuid.UUID) (dto.PrerequisitesDto, error) {
+	waived, err := s.client.HasWaiver(userId, courseId)
+	if err != nil {
+		return nil, err
+	}
+	if waived {
+		return dto.PrerequisitesDto{}, nil
+	}
+	unmet, err := s.client.Unmet(userId, courseId)
+	if err != nil {
+		return nil, err
+	}
+	return toPrerequisitesDto(unmet), nil
+}
+
+func (s *prerequisiteService) GrantWaiver(courseId uuid.UUID, userId uuid.UUID, grantedBy uuid.UUID) (dto.PrerequisiteWaiverDto, error) {
+	if err := s.requireCourse(courseId); err != nil {
+		return dto.PrerequisiteWaiverDto{}, err
+	}
+	exists, err := s.client.UserExists(userId)
+	if err != nil {
+		return dto.PrerequisiteWaiverDto{}, err
+	}
+	if !exists {
+		return dto.PrerequisiteWaiverDto{}, customError.NewError("NOT_FOUND", "User not found", http.StatusNotFound)
+	}
+	waiver, err := s.client.GrantWaiver(model.PrerequisiteWaiver{CourseId: courseId, UserId: userId, GrantedBy: grantedBy})
+	if err != nil {
+		return dto.PrerequisiteWaiverDto{}, err
+	}
+	return dto.PrerequisiteWaiverDto{
+		CourseId:  waiver.CourseId,
+		UserId:    waiver.UserId,
+		GrantedBy: waiver.GrantedBy,
+		CreatedAt: waiver.CreatedAt,
+	}, nil
+}
+
+func (s *prerequisiteService) RevokeWaiver(courseId uuid.UUID, userId uuid.UUID) error {
+	return s.client.RevokeWaiver(courseId, userId)
+}
+
+func (s *prerequisiteService) requireCourse(courseId uuid.UUID) error {
+	found, err := s.client.FindCourses([]uuid.UUID{courseId})
+	if err != nil {
+		return err
+	}
+	if len(found) == 0 {
+		return customError.NewError("NOT_FOUND", "Course not found", http.StatusNotFound)
+	}
+	return nil
+}
+
+// requires reports whether any of the courses in from requires target,
+// directly or through other courses.
+func requires(graph prerequisites.Graph, from []uuid.UUID, target uuid.UUID) bool {
+	visited := map[uuid.UUID]bool{}
+	pending := append([]uuid.UUID{}, from...)
+	for len(pending) > 0 {
+		current := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if current == target {
+			return true
+		}
+		if visited[current] {
+			continue
+		}
+		visited[current] = true
+		pending = append(pending, graph[current]...)
+	}
+	return false
+}
+
+func toPrerequisitesDto(courses model.Courses) dto.PrerequisitesDto {
+	response := dto.PrerequisitesDto{}
+	for _, course := range courses {
+		response = append(response, dto.PrerequisiteDto{Id: course.Id, CourseName: course.CourseName})
+	}
+	return response
+}
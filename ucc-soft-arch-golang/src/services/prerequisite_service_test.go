@@ -0,0 +1,94 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/prerequisites"
+	dto "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/courses"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+)
+
+func setupPrerequisites(t *testing.T) (IPrerequisiteService, *prerequisites.PrerequisitesClient, []model.Course) {
+	client := setupCourseClientSQLite(t)
+	require.NoError(t, client.Db.AutoMigrate(&model.Inscripto{}, &model.CoursePrerequisite{}, &model.PrerequisiteWaiver{}))
+	cat := seedCategory(t, client, "Programming")
+	courses := []model.Course{
+		seedCourse(t, client, cat, "Basics"),
+		seedCourse(t, client, cat, "Intermediate"),
+		seedCourse(t, client, cat, "Advanced"),
+	}
+	prerequisitesClient := prerequisites.NewPrerequisitesClient(client.Db)
+	return NewPrerequisiteService(prerequisitesClient), prerequisitesClient, courses
+}
+
+func TestPrerequisiteService_SetPrerequisites(t *testing.T) {
+	svc, _, courses := setupPrerequisites(t)
+	basics, intermediate, advanced := courses[0], courses[1], courses[2]
+
+	saved, err := svc.SetPrerequisites(intermediate.Id, dto.PrerequisitesRequestDto{CourseIds: []uuid.UUID{basics.Id, basics.Id}})
+	require.NoError(t, err)
+	require.Len(t, saved, 1)
+	_, err = svc.SetPrerequisites(advanced.Id, dto.PrerequisitesRequestDto{CourseIds: []uuid.UUID{intermediate.Id}})
+	require.NoError(t, err)
+
+	// basics -> advanced -> intermediate -> basics
+	_, err = svc.SetPrerequisites(basics.Id, dto.PrerequisitesRequestDto{CourseIds: []uuid.UUID{advanced.Id}})
+	requireErrorCode(t, err, "PREREQUISITE_CYCLE")
+	_, err = svc.SetPrerequisites(basics.Id, dto.PrerequisitesRequestDto{CourseIds: []uuid.UUID{basics.Id}})
+	requireErrorCode(t, err, "INVALID_PREREQUISITE")
+	_, err = svc.SetPrerequisites(basics.Id, dto.PrerequisitesRequestDto{CourseIds: []uuid.UUID{uuid.New()}})
+	requireErrorCode(t, err, "INVALID_PREREQUISITE")
+	_, err = svc.SetPrerequisites(uuid.New(), dto.PrerequisitesRequestDto{})
+	requireErrorCode(t, err, "NOT_FOUND")
+
+	// once intermediate stops requiring basics the cycle is gone
+	_, err = svc.SetPrerequisites(intermediate.Id, dto.PrerequisitesRequestDto{})
+	require.NoError(t, err)
+	_, err = svc.SetPrerequisites(basics.Id, dto.PrerequisitesRequestDto{CourseIds: []uuid.UUID{advanced.Id}})
+	require.NoError(t, err)
+
+	required, err := svc.GetPrerequisites(basics.Id)
+	require.NoError(t, err)
+	require.Equal(t, dto.PrerequisitesDto{{Id: advanced.Id, CourseName: "Advanced"}}, required)
+}
+
+func TestPrerequisiteService_UnmetPrerequisites(t *testing.T) {
+	svc, client, courses := setupPrerequisites(t)
+	basics, intermediate, advanced := courses[0], courses[1], courses[2]
+	student := seedUser(t, client.Db, "student@example.com", "Student")
+	admin := seedUser(t, client.Db, "admin@example.com", "Admin")
+	_, err := svc.SetPrerequisites(advanced.Id, dto.PrerequisitesRequestDto{CourseIds: []uuid.UUID{basics.Id, intermediate.Id}})
+	require.NoError(t, err)
+
+	unmet, err := svc.UnmetPrerequisites(student.Id, advanced.Id)
+	require.NoError(t, err)
+	require.Len(t, unmet, 2)
+
+	now := time.Now()
+	require.NoError(t, client.Db.Create(&model.Inscripto{UserId: student.Id, CourseId: basics.Id, CompletedAt: &now}).Error)
+	unmet, err = svc.UnmetPrerequisites(student.Id, advanced.Id)
+	require.NoError(t, err)
+	require.Equal(t, dto.PrerequisitesDto{{Id: intermediate.Id, CourseName: "Intermediate"}}, unmet)
+
+	waiver, err := svc.GrantWaiver(advanced.Id, student.Id, admin.Id)
+	require.NoError(t, err)
+	require.Equal(t, admin.Id, waiver.GrantedBy)
+	unmet, err = svc.UnmetPrerequisites(student.Id, advanced.Id)
+	require.NoError(t, err)
+	require.Empty(t, unmet)
+
+	require.NoError(t, svc.RevokeWaiver(advanced.Id, student.Id))
+	unmet, err = svc.UnmetPrerequisites(student.Id, advanced.Id)
+	require.NoError(t, err)
+	require.Len(t, unmet, 1)
+
+	_, err = svc.GrantWaiver(advanced.Id, uuid.New(), admin.Id)
+	requireErrorCode(t, err, "NOT_FOUND")
+	_, err = svc.GrantWaiver(uuid.New(), student.Id, admin.Id)
+	requireErrorCode(t, err, "NOT_FOUND")
+	requireErrorCode(t, svc.RevokeWaiver(advanced.Id, student.Id), "WAIVER_NOT_FOUND")
+}
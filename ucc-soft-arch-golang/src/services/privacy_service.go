@@ -0,0 +1,334 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/privacy"
+	usersClient "github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/users"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/config"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/users"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/google/uuid"
+)
+
+// DefaultAccountDeletionGrace is used when ACCOUNT_DELETION_GRACE is not
+// configured.
+const DefaultAccountDeletionGrace = 30 * 24 * time.Hour
+
+// accountDeletionBatch caps how many accounts one PurgeDue call erases.
+const accountDeletionBatch = 100
+
+const (
+	AuditPersonalDataExported     = "personal_data_exported"
+	AuditAccountDeletionScheduled = "account_deletion_scheduled"
+	AuditAccountDeletionCanceled  = "account_deletion_canceled"
+	AuditAccountErased            = "account_erased"
+	AuditAccountErasureFailed     = "account_erasure_failed"
+)
+
+type IPrivacyService interface {
+	// Export gathers the profile, enrollments, lesson progress, ratings,
+	// comments and sessions of a user. actorId is who asked for it, the user
+	// or an admin.
+	Export(userId uuid.UUID, actorId uuid.UUID) (users.DataExportDto, error)
+	// ScheduleDeletion erases the account once the grace period is over.
+	// Asking again keeps the date already set.
+	ScheduleDeletion(userId uuid.UUID, actorId uuid.UUID) (users.AccountDeletionDto, error)
+	CancelDeletion(userId uuid.UUID, actorId uuid.UUID) error
+	// Erase anonymizes the account right away, see PrivacyClient.Erase.
+	Erase(userId uuid.UUID, actorId uuid.UUID) error
+	// PurgeDue erases the accounts whose grace period is over. An account
+	// that can't be erased is skipped and the failure written to the audit
+	// log, so it doesn't hold back the rest.
+	PurgeDue() (int, error)
+}
+
+type privacyService struct {
+	client privacy.PrivacyClient
+	users  usersClient.UsersClient
+	audit  IAuditService
+	grace  time.Duration
+	now    func() time.Time
+}
+
+func NewPrivacyService(client *privacy.PrivacyClient, usersClient *usersClient.UsersClient, audit IAuditService) IPrivacyService {
+	envs := config.LoadEnvs(".env")
+	return &privacyService{
+		client: *client,
+		users:  *usersClient,
+		audit:  audit,
+		grace:  config.GetDuration(envs, "ACCOUNT_DELETION_GRACE", DefaultAccountDeletionGrace),
+		now:    time.Now,
+	}
+}
+
+func (s *privacyService) Export(userId uuid.UUID, actorId uuid.UUID) (users.DataExportDto, error) {
+	user, err := s.users.FindById(userId)
+	if err != nil {
+		return users.DataExportDto{}, err
+	}
+	enrollments, err := s.client.Enrollments(userId)
+	if err != nil {
+		return users.DataExportDto{}, err
+	}
+	ratings, err := s.client.Ratings(userId)
+	if err != nil {
+		return users.DataExportDto{}, err
+	}
+	comments, err := s.client.Comments(userId)
+	if err != nil {
+		return users.DataExportDto{}, err
+	}
+	progress, err := s.client.LessonProgress(userId)
+	if err != nil {
+		return users.DataExportDto{}, err
+	}
+	sessions, err := s.client.Sessions(userId)
+	if err != nil {
+		return users.DataExportDto{}, err
+	}
+
+	var courseIds, cohortIds []uuid.UUID
+	for _, enrollment := range enrollments {
+		courseIds = append(courseIds, enrollment.CourseId)
+		if enrollment.CohortId != nil {
+			cohortIds = append(cohortIds, *enrollment.CohortId)
+		}
+	}
+	for _, lesson := range progress {
+		courseIds = append(courseIds, lesson.CourseId)
+	}
+	for _, rating := range ratings {
+		courseIds = append(courseIds, rating.CourseId)
+	}
+	for _, comment := range comments {
+		courseIds = append(courseIds, comment.CourseId)
+	}
+	courseNames, err := s.client.CourseNames(courseIds)
+	if err != nil {
+		return users.DataExportDto{}, err
+	}
+	cohortNames, err := s.client.CohortNames(cohortIds)
+	if err != nil {
+		return users.DataExportDto{}, err
+	}
+
+	export := users.DataExportDto{
+		ExportedAt: s.now().UTC(),
+		Profile: users.DataExportProfileDto{
+			Id:                  user.Id,
+			Email:               user.Email,
+			UserName:            user.Name,
+			Avatar:              user.Avatar,
+			Role:                user.Role,
+			EmailVerified:       user.EmailVerified,
+			TwoFactorRequired:   user.TwoFactorRequired,
+			CreatedAt:           user.CreatedAt,
+			UpdatedAt:           user.UpdatedAt,
+			DeletionScheduledAt: user.DeletionScheduledAt,
+		},
+		Enrollments:    make([]users.DataExportEnrollmentDto, 0, len(enrollments)),
+		LessonProgress: make([]users.DataExportLessonProgressDto, 0, len(progress)),
+		Ratings:        make([]users.DataExportRatingDto, 0, len(ratings)),
+		Comments:       make([]users.DataExportCommentDto, 0, len(comments)),
+		Sessions:       make([]users.DataExportSessionDto, 0, len(sessions)),
+	}
+	for _, enrollment := range enrollments {
+		dto := users.DataExportEnrollmentDto{
+			CourseId:    enrollment.CourseId,
+			CourseName:  courseNames[enrollment.CourseId],
+			CohortId:    enrollment.CohortId,
+			Status:      enrollment.Status,
+			EnrolledAt:  enrollment.CreatedAt,
+			CompletedAt: enrollment.CompletedAt,
+		}
+		if enrollment.CohortId != nil {
+			dto.CohortName = cohortNames[*enrollment.CohortId]
+		}
+		export.Enrollments = append(export.Enrollments, dto)
+	}
+	for _, lesson := range progress {
+		export.LessonProgress = append(export.LessonProgress, users.DataExportLessonProgressDto{
+			CourseId:     lesson.CourseId,
+			CourseName:   courseNames[lesson.CourseId],
+			LessonId:     lesson.LessonId,
+			Position:     lesson.Position,
+			LastViewedAt: lesson.LastViewedAt,
+			CompletedAt:  lesson.CompletedAt,
+		})
+	}
+	for _, rating := range ratings {
+		export.Ratings = append(export.Ratings, users.DataExportRatingDto{
+			CourseId:   rating.CourseId,
+			CourseName: courseNames[rating.CourseId],
+			Rating:     rating.Rating,
+			CreatedAt:  rating.CreatedAt,
+			UpdatedAt:  rating.UpdatedAt,
+		})
+	}
+	for _, comment := range comments {
+		export.Comments = append(export.Comments, users.DataExportCommentDto{
+			CourseId:   comment.CourseId,
+			CourseName: courseNames[comment.CourseId],
+			Text:       comment.Text,
+			CreatedAt:  comment.CreatedAt,
+			UpdatedAt:  comment.UpdatedAt,
+		})
+	}
+	for _, session := range sessions {
+		export.Sessions = append(export.Sessions, users.DataExportSessionDto{
+			Id:             session.Id,
+			UserAgent:      session.UserAgent,
+			Ip:             session.Ip,
+			CreatedAt:      session.CreatedAt,
+			LastActivityAt: session.LastActivityAt,
+			ExpiresAt:      session.ExpiresAt,
+			EndedAt:        session.EndedAt,
+		})
+	}
+
+	if err := s.audit.Record(model.AuditLog{
+		Event:   AuditPersonalDataExported,
+		ActorId: actorId,
+		UserId:  userId,
+	}); err != nil {
+		return users.DataExportDto{}, err
+	}
+	return export, nil
+}
+
+func (s *privacyService) ScheduleDeletion(userId uuid.UUID, actorId uuid.UUID) (users.AccountDeletionDto, error) {
+	user, err := s.users.FindById(userId)
+	if err != nil {
+		return users.AccountDeletionDto{}, err
+	}
+	if user.DeletionScheduledAt != nil {
+		return users.AccountDeletionDto{ScheduledAt: *user.DeletionScheduledAt}, nil
+	}
+
+	scheduledAt := s.now().Add(s.grace)
+	if err := s.users.SetDeletionScheduled(userId, &scheduledAt); err != nil {
+		return users.AccountDeletionDto{}, err
+	}
+	if err := s.audit.Record(model.AuditLog{
+		Event:   AuditAccountDeletionScheduled,
+		ActorId: actorId,
+		UserId:  userId,
+		Details: "at=" + scheduledAt.UTC().Format(time.RFC3339),
+	}); err != nil {
+		return users.AccountDeletionDto{}, err
+	}
+	return users.AccountDeletionDto{ScheduledAt: scheduledAt}, nil
+}
+
+func (s *privacyService) CancelDeletion(userId uuid.UUID, actorId uuid.UUID) error {
+	user, err := s.users.FindById(userId)
+	if err != nil {
+		return err
+	}
+	if user.DeletionScheduledAt == nil {
+		return nil
+	}
+	if err := s.users.SetDeletionScheduled(userId, nil); err != nil {
+		return err
+	}
+	return s.audit.Record(model.AuditLog{
+		Event:   AuditAccountDeletionCanceled,
+		ActorId: actorId,
+		UserId:  userId,
+	})
+}
+
+func (s *privacyService) Erase(userId uuid.UUID, actorId uuid.UUID) error {
+	user, err := s.users.FindById(userId)
+	if err != nil {
+		return err
+	}
+	if err := s.client.Erase(userId, emailIdentifier+normalizeEmail(user.Email), s.now()); err != nil {
+		return err
+	}
+	// recorded afterwards so the entry keeps no email either
+	return s.audit.Record(model.AuditLog{
+		Event:   AuditAccountErased,
+		ActorId: actorId,
+		UserId:  userId,
+	})
+}
+
+func (s *privacyService) PurgeDue() (int, error) {
+	due, err := s.users.FindDueForDeletion(s.now(), accountDeletionBatch)
+	if err != nil {
+		return 0, err
+	}
+	erased := 0
+	for _, user := range due {
+		if err := s.Erase(user.Id, uuid.Nil); err != nil {
+			log.Printf("could not erase account %s: %v", user.Id, err)
+			if err := s.audit.Record(model.AuditLog{
+				Event:   AuditAccountErasureFailed,
+				UserId:  user.Id,
+				Details: err.Error(),
+			}); err != nil {
+				log.Printf("could not record failed erasure of %s: %v", user.Id, err)
+			}
+			continue
+		}
+		erased++
+	}
+	return erased, nil
+}
+
+// ExportArchive packs an export into a zip with one JSON file per section.
+func ExportArchive(export users.DataExportDto) ([]byte, error) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	files := []struct {
+		name    string
+		content interface{}
+	}{
+		{"profile.json", export.Profile},
+		{"enrollments.json", export.Enrollments},
+		{"lesson_progress.json", export.LessonProgress},
+		{"ratings.json", export.Ratings},
+		{"comments.json", export.Comments},
+		{"sessions.json", export.Sessions},
+	}
+	for _, file := range files {
+		w, err := archive.CreateHeader(&zip.FileHeader{Name: file.name, Method: zip.Deflate, Modified: export.ExportedAt})
+		if err != nil {
+			return nil, err
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.content); err != nil {
+			return nil, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// RunAccountDeletion erases accounts whose grace period is over every
+// interval until stop is closed.
+func RunAccountDeletion(service IPrivacyService, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if erased, err := service.PurgeDue(); err != nil {
+				log.Printf("account deletion failed: %v", err)
+			} else if erased > 0 {
+				log.Printf("account deletion: erased %d accounts", erased)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+	"time"
+
+	github_com_glebarez_sqlite "github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	auditClient "github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/audit"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/privacy"
+	usersClient "github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/users"
+	userDtos "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/users"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+)
+
+func setupPrivacy(t *testing.T) (*privacyService, *gorm.DB, model.User, model.Course) {
+	db, err := gorm.Open(github_com_glebarez_sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	require.NoError(t, db.AutoMigrate(&model.User{}, &model.Course{}, &model.Inscripto{}, &model.Rating{}, &model.Comment{},
+		&model.Session{}, &model.RefreshToken{}, &model.RevokedToken{}, &model.APIKey{}, &model.TwoFactor{},
+		&model.RecoveryCode{}, &model.TwoFactorChallenge{}, &model.ExternalIdentity{}, &model.PasswordHistory{},
+		&model.PasswordReset{}, &model.EmailVerification{}, &model.CourseInstructor{}, &model.LoginAttempt{}, &model.AuditLog{},
+		&model.LessonProgress{}, &model.PrerequisiteWaiver{}, &model.Cohort{}))
+
+	user := model.User{Email: "student@test.com", Name: "Student", Password: "x"}
+	require.NoError(t, db.Create(&user).Error)
+	course := model.Course{CourseName: "Databases"}
+	require.NoError(t, db.Create(&course).Error)
+	cohort := model.Cohort{CourseId: course.Id, Name: "Spring"}
+	require.NoError(t, db.Create(&cohort).Error)
+	require.NoError(t, db.Create(&model.Inscripto{UserId: user.Id, CourseId: course.Id, CohortId: &cohort.Id, Status: model.EnrollmentActive}).Error)
+	require.NoError(t, db.Create(&model.LessonProgress{UserId: user.Id, CourseId: course.Id, LessonId: uuid.New(), Position: 90}).Error)
+	require.NoError(t, db.Create(&model.Session{UserId: user.Id, UserAgent: "curl", Ip: "10.0.0.1"}).Error)
+	require.NoError(t, db.Create(&model.Rating{UserId: user.Id, CourseId: course.Id, Rating: 5}).Error)
+	require.NoError(t, db.Create(&model.Comment{UserId: user.Id, CourseId: course.Id, Text: "great"}).Error)
+
+	svc := NewPrivacyService(privacy.NewPrivacyClient(db), usersClient.NewUsersClient(db), NewAuditService(auditClient.NewAuditClient(db))).(*privacyService)
+	return svc, db, user, course
+}
+
+func TestPrivacyService_Export(t *testing.T) {
+	svc, db, user, course := setupPrivacy(t)
+
+	export, err := svc.Export(user.Id, user.Id)
+	require.NoError(t, err)
+	require.Equal(t, user.Email, export.Profile.Email)
+	require.Len(t, export.Enrollments, 1)
+	require.Equal(t, course.Id, export.Enrollments[0].CourseId)
+	require.Equal(t, "Databases", export.Enrollments[0].CourseName)
+	require.Equal(t, model.EnrollmentActive, export.Enrollments[0].Status)
+	require.Equal(t, "Spring", export.Enrollments[0].CohortName)
+	require.Len(t, export.LessonProgress, 1)
+	require.Equal(t, 90, export.LessonProgress[0].Position)
+	require.Equal(t, "Databases", export.LessonProgress[0].CourseName)
+	require.Len(t, export.Sessions, 1)
+	require.Equal(t, "curl", export.Sessions[0].UserAgent)
+	require.Len(t, export.Ratings, 1)
+	require.Equal(t, 5, export.Ratings[0].Rating)
+	require.Len(t, export.Comments, 1)
+	require.Equal(t, "great", export.Comments[0].Text)
+
+	var entry model.AuditLog
+	require.NoError(t, db.Where("event = ?", AuditPersonalDataExported).First(&entry).Error)
+	require.Equal(t, user.Id, entry.UserId)
+
+	archive, err := ExportArchive(export)
+	require.NoError(t, err)
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	require.NoError(t, err)
+	var names []string
+	for _, file := range reader.File {
+		names = append(names, file.Name)
+	}
+	require.Equal(t, []string{"profile.json", "enrollments.json", "lesson_progress.json", "ratings.json", "comments.json", "sessions.json"}, names)
+
+	_, err = svc.Export(uuid.New(), user.Id)
+	requireErrorCode(t, err, "NOT_FOUND")
+}
+
+func TestPrivacyService_ScheduleAndCancelDeletion(t *testing.T) {
+	svc, _, user, _ := setupPrivacy(t)
+	now := time.Now()
+	svc.now = func() time.Time { return now }
+
+	deletion, err := svc.ScheduleDeletion(user.Id, user.Id)
+	require.NoError(t, err)
+	require.WithinDuration(t, now.Add(DefaultAccountDeletionGrace), deletion.ScheduledAt, time.Second)
+
+	// asking again doesn't push the date back
+	svc.now = func() time.Time { return now.Add(time.Hour) }
+	again, err := svc.ScheduleDeletion(user.Id, user.Id)
+	require.NoError(t, err)
+	require.WithinDuration(t, deletion.ScheduledAt, again.ScheduledAt, time.Second)
+
+	require.NoError(t, svc.CancelDeletion(user.Id, user.Id))
+	stored, err := svc.users.FindById(user.Id)
+	require.NoError(t, err)
+	require.Nil(t, stored.DeletionScheduledAt)
+	require.NoError(t, svc.CancelDeletion(user.Id, user.Id))
+}
+
+func TestPrivacyService_PurgeDue(t *testing.T) {
+	svc, db, user, _ := setupPrivacy(t)
+	now := time.Now()
+	svc.now = func() time.Time { return now }
+	_, err := svc.ScheduleDeletion(user.Id, user.Id)
+	require.NoError(t, err)
+
+	erased, err := svc.PurgeDue()
+	require.NoError(t, err)
+	require.Zero(t, erased, "the grace period isn't over yet")
+
+	svc.now = func() time.Time { return now.Add(DefaultAccountDeletionGrace + time.Minute) }
+	erased, err = svc.PurgeDue()
+	require.NoError(t, err)
+	require.Equal(t, 1, erased)
+
+	_, err = svc.users.FindById(user.Id)
+	requireErrorCode(t, err, "NOT_FOUND")
+	var comment model.Comment
+	require.NoError(t, db.Where("user_id = ?", user.Id).First(&comment).Error)
+	require.Equal(t, "great", comment.Text)
+	var tombstone model.User
+	require.NoError(t, db.Unscoped().Where("id = ?", user.Id).First(&tombstone).Error)
+	require.Equal(t, model.DeletedUserName, tombstone.Name)
+
+	var entry model.AuditLog
+	require.NoError(t, db.Where("event = ?", AuditAccountErased).First(&entry).Error)
+	require.Empty(t, entry.Email)
+}
+
+func TestPrivacyService_PurgeDueSkipsAccountsThatCantBeErased(t *testing.T) {
+	svc, db, owner, course := setupPrivacy(t)
+	// nobody could take over the course, so the owner can't be erased
+	require.NoError(t, db.Create(&model.CourseInstructor{CourseId: course.Id, UserId: owner.Id, IsOwner: true}).Error)
+	other := model.User{Email: "other@test.com", Name: "Other", Password: "x"}
+	require.NoError(t, db.Create(&other).Error)
+	now := time.Now()
+	svc.now = func() time.Time { return now }
+	for _, id := range []uuid.UUID{owner.Id, other.Id} {
+		_, err := svc.ScheduleDeletion(id, id)
+		require.NoError(t, err)
+	}
+
+	svc.now = func() time.Time { return now.Add(DefaultAccountDeletionGrace + time.Minute) }
+	erased, err := svc.PurgeDue()
+	require.NoError(t, err)
+	require.Equal(t, 1, erased)
+
+	_, err = svc.users.FindById(other.Id)
+	requireErrorCode(t, err, "NOT_FOUND")
+	_, err = svc.users.FindById(owner.Id)
+	require.NoError(t, err)
+	var entry model.AuditLog
+	require.NoError(t, db.Where("event = ? AND user_id = ?", AuditAccountErasureFailed, owner.Id).First(&entry).Error)
+	require.Contains(t, entry.Details, "COURSE_OWNER")
+}
+
+func TestPrivacyService_EraseRevokesTokens(t *testing.T) {
+	svc, db, user, _ := setupPrivacy(t)
+	tokens, _ := newTokenStack(db)
+	pair, err := tokens.IssueTokens(user.Id, user.Role, uuid.Nil, userDtos.ClientInfoDto{})
+	require.NoError(t, err)
+
+	require.NoError(t, svc.Erase(user.Id, uuid.New()))
+	_, err = tokens.VerifyAccessToken(pair.AccessToken)
+	require.Error(t, err)
+	_, err = tokens.ConsumeRefreshToken(pair.RefreshToken)
+	requireErrorCode(t, err, "INVALID_REFRESH_TOKEN")
+}
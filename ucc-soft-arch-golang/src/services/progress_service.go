@@ -0,0 +1,225 @@
+package services
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/curriculum"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/inscriptos"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/progress"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/config"
+	dto "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/inscription"
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/google/uuid"
+)
+
+// DefaultCompletionPercent is used when COURSE_COMPLETION_PERCENT is not
+// configured: a course is completed when every lesson is.
+const DefaultCompletionPercent = 100
+
+type IProgressService interface {
+	// RecordProgress saves where the user is in a lesson and marks the
+	// enrollment completed once the course's completion rules are met.
+	RecordProgress(userId uuid.UUID, courseId uuid.UUID, lessonId uuid.UUID, request dto.LessonProgressRequestDto) (dto.CourseProgressDto, error)
+	GetProgress(userId uuid.UUID, courseId uuid.UUID) (dto.CourseProgressDto, error)
+	// GetCompletionRules returns the rules of a course, or the defaults
+	// when it has none.
+	GetCompletionRules(courseId uuid.UUID) (dto.CompletionRulesDto, error)
+	// UpdateCompletionRules replaces the rules of a course and completes the
+	// enrollments that meet the new ones. Enrollments already completed
+	// stay completed.
+	UpdateCompletionRules(courseId uuid.UUID, rules dto.CompletionRulesDto) (dto.CompletionRulesDto, error)
+}
+
+type progressService struct {
+	client         progress.ProgressClient
+	curriculum     curriculum.CurriculumClient
+	inscriptions   inscriptos.InscriptosClient
+	defaultPercent int
+	now            func() time.Time
+}
+
+func NewProgressService(client *progress.ProgressClient, curriculumClient *curriculum.CurriculumClient, inscriptionsClient *inscriptos.InscriptosClient) IProgressService {
+	envs := config.LoadEnvs(".env")
+	return &progressService{
+		client:         *client,
+		curriculum:     *curriculumClient,
+		inscriptions:   *inscriptionsClient,
+		defaultPercent: min(config.GetInt(envs, "COURSE_COMPLETION_PERCENT", DefaultCompletionPercent), 100),
+		now:            time.Now,
+	}
+}
+
+func (s *progressService) RecordProgress(userId uuid.UUID, courseId uuid.UUID, lessonId uuid.UUID, request dto.LessonProgressRequestDto) (dto.CourseProgressDto, error) {
+	if request.Position < 0 {
+		return dto.CourseProgressDto{}, customError.NewError("INVALID_POSITION", "position can't be negative", http.StatusBadRequest)
+	}
+	if err := s.requireEnrollment(userId, courseId); err != nil {
+		return dto.CourseProgressDto{}, err
+	}
+	if _, err := s.curriculum.GetLesson(courseId, lessonId); err != nil {
+		return dto.CourseProgressDto{}, err
+	}
+
+	now := s.now()
+	record := model.LessonProgress{UserId: userId, CourseId: courseId, LessonId: lessonId, Position: request.Position, LastViewedAt: now}
+	if request.Completed {
+		record.CompletedAt = &now
+	}
+	if _, err := s.client.Record(record); err != nil {
+		return dto.CourseProgressDto{}, err
+	}
+	rules, err := s.GetCompletionRules(courseId)
+	if err != nil {
+		return dto.CourseProgressDto{}, err
+	}
+	return s.evaluate(userId, courseId, rules)
+}
+
+func (s *progressService) GetProgress(userId uuid.UUID, courseId uuid.UUID) (dto.CourseProgressDto, error) {
+	if err := s.requireEnrollment(userId, courseId); err != nil {
+		return dto.CourseProgressDto{}, err
+	}
+	rules, err := s.GetCompletionRules(courseId)
+	if err != nil {
+		return dto.CourseProgressDto{}, err
+	}
+	return s.evaluate(userId, courseId, rules)
+}
+
+func (s *progressService) GetCompletionRules(courseId uuid.UUID) (dto.CompletionRulesDto, error) {
+	rule, found, err := s.client.GetRule(courseId)
+	if err != nil {
+		return dto.CompletionRulesDto{}, err
+	}
+	rules := dto.CompletionRulesDto{MinPercent: s.defaultPercent, RequiredLessons: []uuid.UUID{}}
+	if !found {
+		return rules, nil
+	}
+	rules.MinPercent = rule.MinPercent
+	for _, id := range strings.Split(rule.RequiredLessons, ",") {
+		if lessonId, err := uuid.Parse(id); err == nil {
+			rules.RequiredLessons = append(rules.RequiredLessons, lessonId)
+		}
+	}
+	return rules, nil
+}
+
+func (s *progressService) UpdateCompletionRules(courseId uuid.UUID, rules dto.CompletionRulesDto) (dto.CompletionRulesDto, error) {
+	if rules.MinPercent < 1 || rules.MinPercent > 100 {
+		return dto.CompletionRulesDto{}, customError.NewError("INVALID_PERCENT", "min_percent must be between 1 and 100", http.StatusBadRequest)
+	}
+	exists, err := s.curriculum.CourseExists(courseId)
+	if err != nil {
+		return dto.CompletionRulesDto{}, err
+	}
+	if !exists {
+		return dto.CompletionRulesDto{}, customError.NewError("NOT_FOUND", "Course not found", http.StatusNotFound)
+	}
+	_, lessons, err := s.curriculum.GetOutline(courseId)
+	if err != nil {
+		return dto.CompletionRulesDto{}, err
+	}
+	inCourse := map[uuid.UUID]bool{}
+	for _, lesson := range lessons {
+		inCourse[lesson.Id] = true
+	}
+	required := []uuid.UUID{}
+	seen := map[uuid.UUID]bool{}
+	var ids []string
+	for _, lessonId := range rules.RequiredLessons {
+		if !inCourse[lessonId] {
+			return dto.CompletionRulesDto{}, customError.NewError("INVALID_LESSON", "Required lessons must belong to the course", http.StatusBadRequest)
+		}
+		if seen[lessonId] {
+			continue
+		}
+		seen[lessonId] = true
+		required = append(required, lessonId)
+		ids = append(ids, lessonId.String())
+	}
+	rule := model.CompletionRule{CourseId: courseId, MinPercent: rules.MinPercent, RequiredLessons: strings.Join(ids, ",")}
+	if _, err := s.client.SaveRule(rule); err != nil {
+		return dto.CompletionRulesDto{}, err
+	}
+
+	saved := dto.CompletionRulesDto{MinPercent: rules.MinPercent, RequiredLessons: required}
+	pending, err := s.client.PendingStudents(courseId)
+	if err != nil {
+		return dto.CompletionRulesDto{}, err
+	}
+	for _, userId := range pending {
+		if _, err := s.evaluate(userId, courseId, saved); err != nil {
+			return dto.CompletionRulesDto{}, err
+		}
+	}
+	return saved, nil
+}
+
+// evaluate works out the progress of an enrollment and completes it if the
+// rules are met. Deleted lessons don't count, not even required ones.
+func (s *progressService) evaluate(userId uuid.UUID, courseId uuid.UUID, rules dto.CompletionRulesDto) (dto.CourseProgressDto, error) {
+	_, lessons, err := s.curriculum.GetOutline(courseId)
+	if err != nil {
+		return dto.CourseProgressDto{}, err
+	}
+	completed, err := s.client.CompletedLessons(userId, courseId)
+	if err != nil {
+		return dto.CourseProgressDto{}, err
+	}
+	done := map[uuid.UUID]bool{}
+	for _, lessonId := range completed {
+		done[lessonId] = true
+	}
+
+	response := dto.CourseProgressDto{CourseId: courseId, TotalLessons: len(lessons), CompletedLessons: []uuid.UUID{}}
+	inCourse := map[uuid.UUID]bool{}
+	for _, lesson := range lessons {
+		inCourse[lesson.Id] = true
+		if done[lesson.Id] {
+			response.CompletedLessons = append(response.CompletedLessons, lesson.Id)
+		}
+	}
+	response.Progress = progress.Progress{Lessons: int64(len(lessons)), Completed: int64(len(response.CompletedLessons))}.Percent()
+
+	met := len(lessons) > 0 && response.Progress >= rules.MinPercent
+	for _, lessonId := range rules.RequiredLessons {
+		if inCourse[lessonId] && !done[lessonId] {
+			met = false
+		}
+	}
+	if response.CompletedAt, err = s.client.EnrollmentCompletedAt(userId, courseId); err != nil {
+		return dto.CourseProgressDto{}, err
+	}
+	if response.CompletedAt == nil && met {
+		now := s.now()
+		if _, err := s.client.CompleteEnrollment(userId, courseId, now); err != nil {
+			return dto.CourseProgressDto{}, err
+		}
+		response.CompletedAt = &now
+	}
+
+	last, found, err := s.client.LastViewed(userId, courseId)
+	if err != nil {
+		return dto.CourseProgressDto{}, err
+	}
+	if found {
+		response.LastLessonId = &last.LessonId
+		response.LastPosition = last.Position
+		response.LastViewedAt = &last.LastViewedAt
+	}
+	return response, nil
+}
+
+func (s *progressService) requireEnrollment(userId uuid.UUID, courseId uuid.UUID) error {
+	enrolled, err := s.inscriptions.IsUserEnrolled(userId, courseId)
+	if err != nil {
+		return err
+	}
+	if !enrolled {
+		return customError.NewError("NOT_ENROLLED", "You have to be enrolled in this course", http.StatusForbidden)
+	}
+	return nil
+}
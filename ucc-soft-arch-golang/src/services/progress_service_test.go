@@ -0,0 +1,131 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/curriculum"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/inscriptos"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/progress"
+	dto "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/inscription"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+)
+
+func setupProgress(t *testing.T) (*progressService, model.Course, []model.Lesson, model.User) {
+	client := setupCourseClientSQLite(t)
+	require.NoError(t, client.Db.AutoMigrate(&model.Inscripto{}, &model.Section{}, &model.Lesson{}, &model.LessonProgress{}, &model.CompletionRule{}))
+	course := seedCourse(t, client, seedCategory(t, client, "Programming"), "Golang")
+	user := seedUser(t, client.Db, "student@example.com", "Student")
+	require.NoError(t, client.Db.Create(&model.Inscripto{UserId: user.Id, CourseId: course.Id}).Error)
+
+	section := model.Section{CourseId: course.Id, Title: "Intro"}
+	require.NoError(t, client.Db.Create(&section).Error)
+	var lessons []model.Lesson
+	for i := 0; i < 4; i++ {
+		lesson := model.Lesson{CourseId: course.Id, SectionId: section.Id, Title: "Lesson", Type: model.LessonText, Body: "text", Position: i}
+		require.NoError(t, client.Db.Create(&lesson).Error)
+		lessons = append(lessons, lesson)
+	}
+
+	svc := NewProgressService(progress.NewProgressClient(client.Db), curriculum.NewCurriculumClient(client.Db), inscriptos.NewInscriptionClient(client.Db)).(*progressService)
+	svc.defaultPercent = DefaultCompletionPercent
+	clock := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time {
+		clock = clock.Add(time.Minute)
+		return clock
+	}
+	return svc, course, lessons, user
+}
+
+func TestProgressService_RecordProgress(t *testing.T) {
+	svc, course, lessons, user := setupProgress(t)
+
+	response, err := svc.RecordProgress(user.Id, course.Id, lessons[0].Id, dto.LessonProgressRequestDto{Position: 42})
+	require.NoError(t, err)
+	require.Equal(t, 0, response.Progress)
+	require.Equal(t, 4, response.TotalLessons)
+	require.Equal(t, lessons[0].Id, *response.LastLessonId)
+	require.Equal(t, 42, response.LastPosition)
+
+	for i, lesson := range lessons[:3] {
+		response, err = svc.RecordProgress(user.Id, course.Id, lesson.Id, dto.LessonProgressRequestDto{Completed: true})
+		require.NoError(t, err)
+		require.Len(t, response.CompletedLessons, i+1)
+	}
+	require.Equal(t, 75, response.Progress)
+	require.Nil(t, response.CompletedAt)
+
+	response, err = svc.RecordProgress(user.Id, course.Id, lessons[3].Id, dto.LessonProgressRequestDto{Completed: true})
+	require.NoError(t, err)
+	require.Equal(t, 100, response.Progress)
+	require.NotNil(t, response.CompletedAt)
+	completedAt := *response.CompletedAt
+
+	// reviewing a lesson doesn't undo the completion
+	response, err = svc.GetProgress(user.Id, course.Id)
+	require.NoError(t, err)
+	require.True(t, response.CompletedAt.Equal(completedAt))
+}
+
+func TestProgressService_RecordProgress_Errors(t *testing.T) {
+	svc, course, lessons, user := setupProgress(t)
+
+	_, err := svc.RecordProgress(user.Id, course.Id, lessons[0].Id, dto.LessonProgressRequestDto{Position: -1})
+	requireErrorCode(t, err, "INVALID_POSITION")
+	_, err = svc.RecordProgress(uuid.New(), course.Id, lessons[0].Id, dto.LessonProgressRequestDto{})
+	requireErrorCode(t, err, "NOT_ENROLLED")
+	_, err = svc.RecordProgress(user.Id, course.Id, uuid.New(), dto.LessonProgressRequestDto{})
+	requireErrorCode(t, err, "LESSON_NOT_FOUND")
+	_, err = svc.GetProgress(uuid.New(), course.Id)
+	requireErrorCode(t, err, "NOT_ENROLLED")
+}
+
+func TestProgressService_CompletionRules(t *testing.T) {
+	svc, course, lessons, user := setupProgress(t)
+
+	rules, err := svc.GetCompletionRules(course.Id)
+	require.NoError(t, err)
+	require.Equal(t, DefaultCompletionPercent, rules.MinPercent)
+	require.Empty(t, rules.RequiredLessons)
+
+	for _, lesson := range lessons[:2] {
+		_, err = svc.RecordProgress(user.Id, course.Id, lesson.Id, dto.LessonProgressRequestDto{Completed: true})
+		require.NoError(t, err)
+	}
+
+	// half the course is enough, but the last lesson is required
+	rules, err = svc.UpdateCompletionRules(course.Id, dto.CompletionRulesDto{MinPercent: 50, RequiredLessons: []uuid.UUID{lessons[3].Id, lessons[3].Id}})
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{lessons[3].Id}, rules.RequiredLessons)
+	response, err := svc.GetProgress(user.Id, course.Id)
+	require.NoError(t, err)
+	require.Nil(t, response.CompletedAt)
+
+	// dropping the requirement completes the enrollment right away
+	_, err = svc.UpdateCompletionRules(course.Id, dto.CompletionRulesDto{MinPercent: 50})
+	require.NoError(t, err)
+	completedAt, err := svc.client.EnrollmentCompletedAt(user.Id, course.Id)
+	require.NoError(t, err)
+	require.NotNil(t, completedAt)
+
+	rules, err = svc.GetCompletionRules(course.Id)
+	require.NoError(t, err)
+	require.Equal(t, 50, rules.MinPercent)
+	require.Empty(t, rules.RequiredLessons)
+}
+
+func TestProgressService_UpdateCompletionRules_Errors(t *testing.T) {
+	svc, course, _, _ := setupProgress(t)
+
+	_, err := svc.UpdateCompletionRules(course.Id, dto.CompletionRulesDto{MinPercent: 0})
+	requireErrorCode(t, err, "INVALID_PERCENT")
+	_, err = svc.UpdateCompletionRules(course.Id, dto.CompletionRulesDto{MinPercent: 101})
+	requireErrorCode(t, err, "INVALID_PERCENT")
+	_, err = svc.UpdateCompletionRules(uuid.New(), dto.CompletionRulesDto{MinPercent: 80})
+	requireErrorCode(t, err, "NOT_FOUND")
+	_, err = svc.UpdateCompletionRules(course.Id, dto.CompletionRulesDto{MinPercent: 80, RequiredLessons: []uuid.UUID{uuid.New()}})
+	requireErrorCode(t, err, "INVALID_LESSON")
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/revocation"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/sessions"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/tokens"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/users"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/jwt"
+	"github.com/google/uuid"
+)
+
+type IRevocationService interface {
+	// RevokeToken denylists a single access token until it expires and ends
+	// the session it belongs to.
+	RevokeToken(claims *jwt.CustomClaims) error
+	// RevokeAllForUser invalidates every access and refresh token of a user
+	// and ends all of their sessions.
+	RevokeAllForUser(userId uuid.UUID) error
+	IsRevoked(claims *jwt.CustomClaims) (bool, error)
+	// IsRevokedFor is IsRevoked for callers that already loaded the user the
+	// token was issued to.
+	IsRevokedFor(claims *jwt.CustomClaims, user model.User) (bool, error)
+	// TokenVersion is stamped into new tokens so RevokeAllForUser can reject older ones.
+	TokenVersion(userId uuid.UUID) (int, error)
+	PurgeExpired() (int64, error)
+}
+
+type revocationService struct {
+	client        revocation.RevocationClient
+	usersClient   users.UsersClient
+	refreshClient tokens.RefreshTokensClient
+	sessions      sessions.SessionsClient
+}
+
+func NewRevocationService(client *revocation.RevocationClient, usersClient *users.UsersClient, refreshClient *tokens.RefreshTokensClient, sessionsClient *sessions.SessionsClient) IRevocationService {
+	return &revocationService{
+		client:        *client,
+		usersClient:   *usersClient,
+		refreshClient: *refreshClient,
+		sessions:      *sessionsClient,
+	}
+}
+
+func (r *revocationService) RevokeToken(claims *jwt.CustomClaims) error {
+	err := r.client.Revoke(model.RevokedToken{
+		TokenId:   claims.TokenId,
+		UserId:    claims.Id,
+		ExpiresAt: claims.ExpiresAtTime(),
+	})
+	if err != nil {
+		return err
+	}
+	if sessionId, err := uuid.Parse(claims.SessionId); err == nil {
+		if _, err := r.sessions.End(sessionId, claims.Id, time.Now()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *revocationService) RevokeAllForUser(userId uuid.UUID) error {
+	if err := r.usersClient.IncrementTokenVersion(userId); err != nil {
+		return err
+	}
+	now := time.Now()
+	if _, err := r.sessions.EndAllForUser(userId, uuid.Nil, now); err != nil {
+		return err
+	}
+	return r.refreshClient.RevokeAllForUser(userId, now)
+}
+
+func (r *revocationService) IsRevoked(claims *jwt.CustomClaims) (bool, error) {
+	user, err := r.usersClient.FindById(claims.Id)
+	if err != nil {
+		return false, err
+	}
+	return r.IsRevokedFor(claims, user)
+}
+
+func (r *revocationService) IsRevokedFor(claims *jwt.CustomClaims, user model.User) (bool, error) {
+	if claims.TokenId != "" {
+		revoked, err := r.client.IsRevoked(claims.TokenId)
+		if err != nil || revoked {
+			return revoked, err
+		}
+	}
+	return claims.Version < user.TokenVersion, nil
+}
+
+func (r *revocationService) TokenVersion(userId uuid.UUID) (int, error) {
+	user, err := r.usersClient.FindById(userId)
+	if err != nil {
+		return 0, err
+	}
+	return user.TokenVersion, nil
+}
+
+func (r *revocationService) PurgeExpired() (int64, error) {
+	return r.client.PurgeExpired(time.Now())
+}
+
+// RunRevocationCleanup purges expired denylist entries every interval until
+// stop is closed.
+func RunRevocationCleanup(service IRevocationService, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := service.PurgeExpired(); err != nil {
+				fmt.Println("revocation cleanup: ", err)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/jwt"
+	github_com_glebarez_sqlite "github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupRevocationService(t *testing.T) (IRevocationService, model.User) {
+	db, err := gorm.Open(github_com_glebarez_sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&model.User{}, &model.RefreshToken{}, &model.RevokedToken{}, &model.Session{}))
+	u := model.User{Email: "rev@ex.com", Password: "x", Name: "Rev"}
+	require.NoError(t, db.Create(&u).Error)
+	_, revocation := newTokenStack(db)
+	return revocation, u
+}
+
+func TestRevocationService_RevokeToken(t *testing.T) {
+	svc, u := setupRevocationService(t)
+	claims := jwt.NewCustomClaims(u.Id, "student")
+	revoked, err := svc.IsRevoked(claims)
+	require.NoError(t, err)
+	require.False(t, revoked)
+
+	require.NoError(t, svc.RevokeToken(claims))
+	revoked, err = svc.IsRevoked(claims)
+	require.NoError(t, err)
+	require.True(t, revoked)
+}
+
+func TestRevocationService_RevokeAllForUser(t *testing.T) {
+	svc, u := setupRevocationService(t)
+	before := jwt.NewCustomClaims(u.Id, "student")
+	require.NoError(t, svc.RevokeAllForUser(u.Id))
+
+	revoked, err := svc.IsRevoked(before)
+	require.NoError(t, err)
+	require.True(t, revoked)
+
+	version, err := svc.TokenVersion(u.Id)
+	require.NoError(t, err)
+	after := jwt.NewCustomClaims(u.Id, "student")
+	after.Version = version
+	revoked, err = svc.IsRevoked(after)
+	require.NoError(t, err)
+	require.False(t, revoked)
+}
+
+func TestRevocationService_PurgeExpired(t *testing.T) {
+	svc, u := setupRevocationService(t)
+	expired := jwt.NewCustomClaims(u.Id, "student")
+	expired.ExpiresAt = time.Now().Add(-time.Minute).Unix()
+	require.NoError(t, svc.RevokeToken(expired))
+	require.NoError(t, svc.RevokeToken(jwt.NewCustomClaims(u.Id, "student")))
+
+	purged, err := svc.PurgeExpired()
+	require.NoError(t, err)
+	require.Equal(t, int64(1), purged)
+}
+
+func TestRunRevocationCleanup_StopsWhenClosed(t *testing.T) {
+	svc, _ := setupRevocationService(t)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		RunRevocationCleanup(svc, time.Millisecond, stop)
+		close(done)
+	}()
+	time.Sleep(5 * time.Millisecond)
+	close(stop)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("cleanup loop did not stop")
+	}
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/courses"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/search"
+	dto "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/courses"
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/google/uuid"
+)
+
+const MaxSearchQuery = 200
+
+const (
+	DefaultSuggestions = 5
+	MaxSuggestions     = 20
+)
+
+type ISearchService interface {
+	// SearchCourses returns the courses matching text, most relevant first,
+	// with a score and highlighted snippets.
+	SearchCourses(text string, page int, limit int) (dto.SearchResultsDto, error)
+	// Suggest completes what is being typed with course and category
+	// names. An empty prefix suggests nothing.
+	Suggest(prefix string, limit int) (dto.SuggestionsDto, error)
+}
+
+type searchService struct {
+	client  search.SearchClient
+	courses courses.CourseClient
+	now     func() time.Time
+}
+
+func NewSearchService(client *search.SearchClient, courseClient *courses.CourseClient) ISearchService {
+	return &searchService{client: *client, courses: *courseClient, now: time.Now}
+}
+
+func (s *searchService) SearchCourses(text string, page int, limit int) (dto.SearchResultsDto, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return dto.SearchResultsDto{}, customError.NewError("QUERY_REQUIRED", "q is required", http.StatusBadRequest)
+	}
+	if len(text) > MaxSearchQuery {
+		return dto.SearchResultsDto{}, customError.NewError("INVALID_QUERY", fmt.Sprintf("q can't be longer than %d characters", MaxSearchQuery), http.StatusBadRequest)
+	}
+	if limit <= 0 {
+		limit = DefaultCoursesPageSize
+	}
+	if limit > MaxCoursesPageSize {
+		limit = MaxCoursesPageSize
+	}
+	if page < 1 {
+		page = 1
+	}
+
+	hits, total, err := s.client.Courses(text, s.now(), (page-1)*limit, limit)
+	if err != nil {
+		return dto.SearchResultsDto{}, err
+	}
+	ids := make([]uuid.UUID, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.CourseId)
+	}
+	found, err := s.courses.GetByIds(ids)
+	if err != nil {
+		return dto.SearchResultsDto{}, err
+	}
+	byId := map[uuid.UUID]dto.GetCourseDto{}
+	for _, course := range found {
+		byId[course.Id] = toCourseDto(course)
+	}
+
+	results := dto.SearchResultsDto{Results: []dto.SearchHitDto{}, Total: total, Page: page, Limit: limit}
+	for _, hit := range hits {
+		// deleted between both queries
+		course, ok := byId[hit.CourseId]
+		if !ok {
+			continue
+		}
+		results.Results = append(results.Results, dto.SearchHitDto{
+			Course: course,
+			Score:  hit.Score,
+			Highlights: dto.SearchHighlightsDto{
+				CourseName:  hit.NameSnippet,
+				Description: hit.DescriptionSnippet,
+			},
+		})
+	}
+	return results, nil
+}
+
+func (s *searchService) Suggest(prefix string, limit int) (dto.SuggestionsDto, error) {
+	response := dto.SuggestionsDto{Courses: []dto.SuggestionDto{}, Categories: []dto.SuggestionDto{}}
+	prefix = strings.TrimSpace(prefix)
+	if prefix == "" {
+		return response, nil
+	}
+	if len(prefix) > MaxSearchQuery {
+		return dto.SuggestionsDto{}, customError.NewError("INVALID_QUERY", fmt.Sprintf("q can't be longer than %d characters", MaxSearchQuery), http.StatusBadRequest)
+	}
+	if limit <= 0 {
+		limit = DefaultSuggestions
+	}
+	if limit > MaxSuggestions {
+		limit = MaxSuggestions
+	}
+
+	courseNames, categoryNames, err := s.courses.Suggest(prefix, limit, s.now())
+	if err != nil {
+		return dto.SuggestionsDto{}, err
+	}
+	for _, suggestion := range courseNames {
+		response.Courses = append(response.Courses, dto.SuggestionDto{Id: suggestion.Id, Text: suggestion.Text})
+	}
+	for _, suggestion := range categoryNames {
+		response.Categories = append(response.Categories, dto.SuggestionDto{Id: suggestion.Id, Text: suggestion.Text})
+	}
+	return response, nil
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/search"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+)
+
+func TestSearchService_SearchCourses(t *testing.T) {
+	client := setupCourseClientSQLite(t)
+	svc := NewSearchService(search.NewSearchClient(client.Db), client)
+	cat := seedCategory(t, client, "Programming")
+	golang := seedCourse(t, client, cat, "Golang")
+	seedCourse(t, client, cat, "Java")
+	require.NoError(t, client.Db.Create(&model.Rating{CourseId: golang.Id, UserId: golang.Id, Rating: 5}).Error)
+
+	results, err := svc.SearchCourses("  golang ", 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), results.Total)
+	require.Equal(t, 1, results.Page)
+	require.Equal(t, DefaultCoursesPageSize, results.Limit)
+	require.Len(t, results.Results, 1)
+	hit := results.Results[0]
+	require.Equal(t, golang.Id, hit.Course.Id)
+	require.Equal(t, "Programming", hit.Course.CourseCategoryName)
+	require.InDelta(t, 5.0, hit.Course.RatingAvg, 0.0001)
+	require.Positive(t, hit.Score)
+	require.Equal(t, "<mark>Golang</mark>", hit.Highlights.CourseName)
+
+	// the category matches with less weight than a name
+	results, err = svc.SearchCourses("programming", 1, 1)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), results.Total)
+	require.Len(t, results.Results, 1)
+
+	_, err = svc.SearchCourses(" ", 1, 10)
+	requireErrorCode(t, err, "QUERY_REQUIRED")
+	_, err = svc.SearchCourses(strings.Repeat("go", MaxSearchQuery), 1, 10)
+	requireErrorCode(t, err, "INVALID_QUERY")
+}
+
+func TestSearchService_Suggest(t *testing.T) {
+	client := setupCourseClientSQLite(t)
+	svc := NewSearchService(search.NewSearchClient(client.Db), client)
+	cat := seedCategory(t, client, "Golang tooling")
+	seedCourse(t, client, cat, "Advanced Golang")
+	seedCourse(t, client, cat, "Golang")
+	seedCourse(t, client, cat, "Java")
+
+	suggestions, err := svc.Suggest(" gol", 0)
+	require.NoError(t, err)
+	require.Len(t, suggestions.Courses, 2)
+	require.Equal(t, "Golang", suggestions.Courses[0].Text)
+	require.Equal(t, "Advanced Golang", suggestions.Courses[1].Text)
+	require.Len(t, suggestions.Categories, 1)
+	require.Equal(t, cat.Id, suggestions.Categories[0].Id)
+
+	suggestions, err = svc.Suggest("gol", 1)
+	require.NoError(t, err)
+	require.Len(t, suggestions.Courses, 1)
+
+	suggestions, err = svc.Suggest("", 5)
+	require.NoError(t, err)
+	require.Empty(t, suggestions.Courses)
+	require.NotNil(t, suggestions.Categories)
+
+	_, err = svc.Suggest(strings.Repeat("go", MaxSearchQuery), 5)
+	requireErrorCode(t, err, "INVALID_QUERY")
+}
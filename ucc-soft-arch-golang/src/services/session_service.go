@@ -0,0 +1,186 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/sessions"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/config"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/users"
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/jwt"
+	"github.com/google/uuid"
+)
+
+// SessionActivityResolution is how stale LastActivityAt may get before a
+// request updates it.
+const SessionActivityResolution = time.Minute
+
+// MaxUserAgentLength bounds the user agent stored with a session.
+const MaxUserAgentLength = 512
+
+// Audit events recorded by the session service.
+const (
+	AuditSessionEnded       = "session_ended"
+	AuditOtherSessionsEnded = "other_sessions_ended"
+)
+
+type ISessionService interface {
+	// Start records a new login of the user from the given client.
+	Start(userId uuid.UUID, client users.ClientInfoDto) (model.Session, error)
+	// Refresh extends a session when its refresh token is rotated. Families
+	// issued before sessions existed get a new session.
+	Refresh(sessionId uuid.UUID, userId uuid.UUID, client users.ClientInfoDto) (model.Session, error)
+	// Verify rejects access tokens whose session has ended or expired.
+	Verify(claims *jwt.CustomClaims) error
+	// List returns the active sessions of a user, marking currentId.
+	List(userId uuid.UUID, currentId uuid.UUID) (users.SessionsDto, error)
+	// End signs out one session of the user and revokes its refresh tokens.
+	End(userId uuid.UUID, sessionId uuid.UUID) error
+	// EndOthers signs out every session of the user but currentId.
+	EndOthers(userId uuid.UUID, currentId uuid.UUID) (int64, error)
+}
+
+type sessionService struct {
+	client     sessions.SessionsClient
+	audit      IAuditService
+	refreshTTL time.Duration
+	now        func() time.Time
+}
+
+func NewSessionService(client *sessions.SessionsClient, audit IAuditService) ISessionService {
+	envs := config.LoadEnvs(".env")
+	return &sessionService{
+		client:     *client,
+		audit:      audit,
+		refreshTTL: config.GetDuration(envs, "REFRESH_TOKEN_TTL", DefaultRefreshTokenTTL),
+		now:        time.Now,
+	}
+}
+
+func (s *sessionService) Start(userId uuid.UUID, client users.ClientInfoDto) (model.Session, error) {
+	now := s.now()
+	return s.client.Create(model.Session{
+		UserId:         userId,
+		UserAgent:      truncate(client.UserAgent, MaxUserAgentLength),
+		Ip:             client.Ip,
+		LastActivityAt: now,
+		ExpiresAt:      now.Add(s.refreshTTL),
+	})
+}
+
+func (s *sessionService) Refresh(sessionId uuid.UUID, userId uuid.UUID, client users.ClientInfoDto) (model.Session, error) {
+	session, err := s.client.FindById(sessionId)
+	if err != nil {
+		if isNotFound(err) {
+			return s.Start(userId, client)
+		}
+		return model.Session{}, err
+	}
+	now := s.now()
+	if session.UserId != userId || session.EndedAt != nil || !now.Before(session.ExpiresAt) {
+		return model.Session{}, sessionEnded()
+	}
+	session.ExpiresAt = now.Add(s.refreshTTL)
+	session.LastActivityAt = now
+	userAgent := truncate(client.UserAgent, MaxUserAgentLength)
+	if err := s.client.Extend(session.Id, client.Ip, userAgent, now, session.ExpiresAt); err != nil {
+		return model.Session{}, err
+	}
+	return session, nil
+}
+
+func (s *sessionService) Verify(claims *jwt.CustomClaims) error {
+	// tokens signed before sessions existed carry no sid, they expire on
+	// their own within the access token TTL
+	if claims.SessionId == "" {
+		return nil
+	}
+	sessionId, err := uuid.Parse(claims.SessionId)
+	if err != nil {
+		return sessionEnded()
+	}
+	session, err := s.client.FindById(sessionId)
+	if err != nil {
+		if isNotFound(err) {
+			return sessionEnded()
+		}
+		return err
+	}
+	now := s.now()
+	if session.UserId != claims.Id || session.EndedAt != nil || !now.Before(session.ExpiresAt) {
+		return sessionEnded()
+	}
+	if err := s.client.TouchActivity(session.Id, now, now.Add(-SessionActivityResolution)); err != nil {
+		log.Printf("could not record session activity: %v", err)
+	}
+	return nil
+}
+
+func (s *sessionService) List(userId uuid.UUID, currentId uuid.UUID) (users.SessionsDto, error) {
+	active, err := s.client.ListActive(userId, s.now())
+	if err != nil {
+		return nil, err
+	}
+	result := make(users.SessionsDto, 0, len(active))
+	for _, session := range active {
+		result = append(result, users.SessionDto{
+			Id:             session.Id,
+			UserAgent:      session.UserAgent,
+			Ip:             session.Ip,
+			CreatedAt:      session.CreatedAt,
+			LastActivityAt: session.LastActivityAt,
+			ExpiresAt:      session.ExpiresAt,
+			Current:        session.Id == currentId,
+		})
+	}
+	return result, nil
+}
+
+func (s *sessionService) End(userId uuid.UUID, sessionId uuid.UUID) error {
+	ended, err := s.client.End(sessionId, userId, s.now())
+	if err != nil {
+		return err
+	}
+	// sessions of other users are reported as missing too
+	if !ended {
+		return customError.NewError("NOT_FOUND", "Session not found", http.StatusNotFound)
+	}
+	return s.audit.Record(model.AuditLog{
+		Event:   AuditSessionEnded,
+		ActorId: userId,
+		UserId:  userId,
+		Details: fmt.Sprintf("session=%s", sessionId),
+	})
+}
+
+func (s *sessionService) EndOthers(userId uuid.UUID, currentId uuid.UUID) (int64, error) {
+	ended, err := s.client.EndAllForUser(userId, currentId, s.now())
+	if err != nil {
+		return 0, err
+	}
+	err = s.audit.Record(model.AuditLog{
+		Event:   AuditOtherSessionsEnded,
+		ActorId: userId,
+		UserId:  userId,
+		Details: fmt.Sprintf("kept=%s ended=%d", currentId, ended),
+	})
+	if err != nil {
+		return 0, err
+	}
+	return ended, nil
+}
+
+func sessionEnded() error {
+	return customError.NewError("SESSION_ENDED", "Session has ended, please sign in again", http.StatusUnauthorized)
+}
+
+func truncate(value string, max int) string {
+	if len(value) > max {
+		return value[:max]
+	}
+	return value
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	auditClient "github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/audit"
+	sessionClient "github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/sessions"
+	userDtos "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/users"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/jwt"
+	github_com_glebarez_sqlite "github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupSessions(t *testing.T) (ITokenService, IRevocationService, *gorm.DB, uuid.UUID) {
+	db, err := gorm.Open(github_com_glebarez_sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	require.NoError(t, db.AutoMigrate(&model.User{}, &model.RefreshToken{}, &model.RevokedToken{}, &model.Session{}, &model.AuditLog{}))
+	u := model.User{Email: "sessions@ex.com", Password: "x", Name: "Sessions"}
+	require.NoError(t, db.Create(&u).Error)
+	tokenService, revocation := newTokenStack(db)
+	return tokenService, revocation, db, u.Id
+}
+
+func newSessionService(db *gorm.DB) *sessionService {
+	return NewSessionService(sessionClient.NewSessionsClient(db), NewAuditService(auditClient.NewAuditClient(db))).(*sessionService)
+}
+
+func TestSessionService_LoginStartsSession(t *testing.T) {
+	tokens, _, db, userId := setupSessions(t)
+	pair, err := tokens.IssueTokens(userId, "student", uuid.Nil, userDtos.ClientInfoDto{Ip: "10.0.0.1", UserAgent: "Firefox"})
+	require.NoError(t, err)
+	claims, err := tokens.VerifyAccessToken(pair.AccessToken)
+	require.NoError(t, err)
+	require.NotEmpty(t, claims.SessionId)
+
+	list, err := newSessionService(db).List(userId, uuid.MustParse(claims.SessionId))
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.True(t, list[0].Current)
+	require.Equal(t, "10.0.0.1", list[0].Ip)
+	require.Equal(t, "Firefox", list[0].UserAgent)
+
+	// rotation keeps the session and the sid
+	stored, err := tokens.ConsumeRefreshToken(pair.RefreshToken)
+	require.NoError(t, err)
+	require.Equal(t, claims.SessionId, stored.FamilyId.String())
+	next, err := tokens.IssueTokens(userId, "student", stored.FamilyId, userDtos.ClientInfoDto{Ip: "10.0.0.2"})
+	require.NoError(t, err)
+	nextClaims, err := tokens.VerifyAccessToken(next.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, claims.SessionId, nextClaims.SessionId)
+	list, _ = newSessionService(db).List(userId, uuid.Nil)
+	require.Len(t, list, 1)
+	require.Equal(t, "10.0.0.2", list[0].Ip)
+	require.Equal(t, "Firefox", list[0].UserAgent)
+}
+
+func TestSessionService_EndRejectsTokens(t *testing.T) {
+	tokens, _, db, userId := setupSessions(t)
+	sessions := newSessionService(db)
+	pair, err := tokens.IssueTokens(userId, "student", uuid.Nil, userDtos.ClientInfoDto{})
+	require.NoError(t, err)
+	claims, err := tokens.VerifyAccessToken(pair.AccessToken)
+	require.NoError(t, err)
+
+	err = sessions.End(uuid.New(), uuid.MustParse(claims.SessionId))
+	requireErrorCode(t, err, "NOT_FOUND")
+	require.NoError(t, sessions.End(userId, uuid.MustParse(claims.SessionId)))
+	err = sessions.End(userId, uuid.MustParse(claims.SessionId))
+	requireErrorCode(t, err, "NOT_FOUND")
+
+	_, err = tokens.VerifyAccessToken(pair.AccessToken)
+	requireErrorCode(t, err, "SESSION_ENDED")
+	_, err = tokens.ConsumeRefreshToken(pair.RefreshToken)
+	require.Error(t, err)
+
+	var entry model.AuditLog
+	require.NoError(t, db.Where("event = ?", AuditSessionEnded).First(&entry).Error)
+	require.Equal(t, userId, entry.UserId)
+}
+
+func TestSessionService_EndOthers(t *testing.T) {
+	tokens, _, db, userId := setupSessions(t)
+	sessions := newSessionService(db)
+	current, _ := tokens.IssueTokens(userId, "student", uuid.Nil, userDtos.ClientInfoDto{})
+	other, _ := tokens.IssueTokens(userId, "student", uuid.Nil, userDtos.ClientInfoDto{})
+	currentClaims, err := tokens.VerifyAccessToken(current.AccessToken)
+	require.NoError(t, err)
+
+	ended, err := sessions.EndOthers(userId, uuid.MustParse(currentClaims.SessionId))
+	require.NoError(t, err)
+	require.EqualValues(t, 1, ended)
+
+	_, err = tokens.VerifyAccessToken(current.AccessToken)
+	require.NoError(t, err)
+	_, err = tokens.VerifyAccessToken(other.AccessToken)
+	requireErrorCode(t, err, "SESSION_ENDED")
+}
+
+func TestSessionService_LogoutAndRevokeAllEndSessions(t *testing.T) {
+	tokens, revocation, db, userId := setupSessions(t)
+	sessions := newSessionService(db)
+	first, _ := tokens.IssueTokens(userId, "student", uuid.Nil, userDtos.ClientInfoDto{})
+	_, _ = tokens.IssueTokens(userId, "student", uuid.Nil, userDtos.ClientInfoDto{})
+	claims, err := tokens.VerifyAccessToken(first.AccessToken)
+	require.NoError(t, err)
+
+	require.NoError(t, revocation.RevokeToken(claims))
+	list, err := sessions.List(userId, uuid.Nil)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+
+	require.NoError(t, revocation.RevokeAllForUser(userId))
+	list, err = sessions.List(userId, uuid.Nil)
+	require.NoError(t, err)
+	require.Empty(t, list)
+}
+
+func TestSessionService_Verify(t *testing.T) {
+	_, _, db, userId := setupSessions(t)
+	sessions := newSessionService(db)
+	now := time.Now()
+	sessions.now = func() time.Time { return now }
+	session, err := sessions.Start(userId, userDtos.ClientInfoDto{})
+	require.NoError(t, err)
+
+	// tokens issued before sessions existed have no sid
+	require.NoError(t, sessions.Verify(&jwt.CustomClaims{Id: userId}))
+	requireErrorCode(t, sessions.Verify(&jwt.CustomClaims{Id: userId, SessionId: "garbage"}), "SESSION_ENDED")
+	requireErrorCode(t, sessions.Verify(&jwt.CustomClaims{Id: uuid.New(), SessionId: session.Id.String()}), "SESSION_ENDED")
+
+	now = now.Add(2 * SessionActivityResolution)
+	require.NoError(t, sessions.Verify(&jwt.CustomClaims{Id: userId, SessionId: session.Id.String()}))
+	var stored model.Session
+	require.NoError(t, db.First(&stored, "id = ?", session.Id).Error)
+	require.WithinDuration(t, now, stored.LastActivityAt, time.Millisecond)
+
+	now = now.Add(sessions.refreshTTL)
+	requireErrorCode(t, sessions.Verify(&jwt.CustomClaims{Id: userId, SessionId: session.Id.String()}), "SESSION_ENDED")
+}
+
+func TestSessionService_RefreshLegacyFamilyStartsSession(t *testing.T) {
+	_, _, db, userId := setupSessions(t)
+	sessions := newSessionService(db)
+	legacyFamily := uuid.New()
+	session, err := sessions.Refresh(legacyFamily, userId, userDtos.ClientInfoDto{Ip: "10.0.0.3"})
+	require.NoError(t, err)
+	require.NotEqual(t, legacyFamily, session.Id)
+	require.Equal(t, "10.0.0.3", session.Ip)
+
+	require.NoError(t, sessions.End(userId, session.Id))
+	_, err = sessions.Refresh(session.Id, userId, userDtos.ClientInfoDto{})
+	requireErrorCode(t, err, "SESSION_ENDED")
+}
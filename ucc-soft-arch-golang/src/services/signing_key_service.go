@@ -0,0 +1,195 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/signingkeys"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/config"
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/jwt"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/securetoken"
+)
+
+// Defaults used when the JWT_KEY_* variables are not configured. Keys rotate
+// every DefaultKeyRotationInterval and the next key is published
+// DefaultKeyPrepublish before it starts signing, so other services can fetch
+// it in time.
+const (
+	DefaultSigningAlgorithm    = jwt.AlgHS256
+	DefaultKeyRotationInterval = 30 * 24 * time.Hour
+	DefaultKeyPrepublish       = 48 * time.Hour
+)
+
+// signingKeyRetryDelay is how long RunSigningKeyRotation waits before
+// retrying a failed rotation the first time.
+var signingKeyRetryDelay = time.Minute
+
+type ISigningKeyService interface {
+	// Rotate makes sure the current rotation slot has a signing key and the
+	// next one is published ahead of time, drops expired keys and installs the
+	// result for signing and verifying access tokens.
+	Rotate() error
+	// JWKS returns the public keys other services verify our tokens with.
+	JWKS() jwt.JWKSet
+}
+
+type signingKeyService struct {
+	client           signingkeys.SigningKeysClient
+	algorithm        string
+	secret           []byte
+	encryptionSecret string
+	rotationInterval time.Duration
+	prepublish       time.Duration
+	acceptLegacy     bool
+	now              func() time.Time
+}
+
+// NewSigningKeyService reads JWT_SIGNING_ALG (HS256, RS256 or EdDSA). With
+// HS256 no keys are generated, but keys left from a previous configuration
+// are still accepted until they expire. With asymmetric keys, setting
+// JWT_ACCEPT_LEGACY_HS256=false stops accepting tokens signed with
+// JWT_SECRET, once the last of them has expired.
+func NewSigningKeyService(client *signingkeys.SigningKeysClient) ISigningKeyService {
+	envs := config.LoadEnvs(".env")
+	algorithm := envs.Get("JWT_SIGNING_ALG")
+	if algorithm == "" {
+		algorithm = DefaultSigningAlgorithm
+	}
+	encryptionSecret := envs.Get("JWT_KEY_ENCRYPTION_SECRET")
+	if encryptionSecret == "" {
+		encryptionSecret = string(jwt.Secret())
+	}
+	return &signingKeyService{
+		client:           *client,
+		algorithm:        algorithm,
+		secret:           jwt.Secret(),
+		encryptionSecret: encryptionSecret,
+		rotationInterval: config.GetDuration(envs, "JWT_KEY_ROTATION_INTERVAL", DefaultKeyRotationInterval),
+		prepublish:       config.GetDuration(envs, "JWT_KEY_PREPUBLISH", DefaultKeyPrepublish),
+		acceptLegacy:     config.GetBool(envs, "JWT_ACCEPT_LEGACY_HS256", true),
+		now:              time.Now,
+	}
+}
+
+func (s *signingKeyService) Rotate() error {
+	if s.algorithm != jwt.AlgHS256 && s.algorithm != jwt.AlgRS256 && s.algorithm != jwt.AlgEdDSA {
+		return customError.NewError("INVALID_CONFIG", fmt.Sprintf("Unsupported JWT_SIGNING_ALG %q", s.algorithm), http.StatusInternalServerError)
+	}
+	if s.algorithm == jwt.AlgHS256 && !s.acceptLegacy {
+		return customError.NewError("INVALID_CONFIG", "JWT_ACCEPT_LEGACY_HS256 can only be false with RS256 or EdDSA keys", http.StatusInternalServerError)
+	}
+	now := s.now()
+	if _, err := s.client.DeleteExpired(now); err != nil {
+		return err
+	}
+
+	var slot time.Time
+	if s.algorithm != jwt.AlgHS256 {
+		slot = now.Truncate(s.rotationInterval)
+		if err := s.ensureKey(slot); err != nil {
+			return err
+		}
+		next := slot.Add(s.rotationInterval)
+		if !now.Before(next.Add(-s.prepublish)) {
+			if err := s.ensureKey(next); err != nil {
+				return err
+			}
+		}
+	}
+
+	stored, err := s.client.FindUnexpired(now)
+	if err != nil {
+		return err
+	}
+	keys := &jwt.KeySet{Secret: s.secret, Keys: map[string]*jwt.Key{}, RejectLegacy: !s.acceptLegacy}
+	for _, storedKey := range stored {
+		key, err := s.decode(storedKey)
+		if err != nil {
+			return err
+		}
+		keys.Keys[key.Id] = key
+		if storedKey.Algorithm == s.algorithm && storedKey.ActivatesAt.Equal(slot) {
+			keys.Signing = key
+		}
+	}
+	if s.algorithm != jwt.AlgHS256 && keys.Signing == nil {
+		return customError.NewError("UNEXPECTED_ERROR", "No signing key for the current rotation slot", http.StatusInternalServerError)
+	}
+	jwt.SetKeys(keys)
+	return nil
+}
+
+func (s *signingKeyService) JWKS() jwt.JWKSet {
+	return jwt.CurrentKeys().JWKS()
+}
+
+// ensureKey creates the key of the slot starting at activatesAt. When another
+// instance wins the race its key is used instead.
+func (s *signingKeyService) ensureKey(activatesAt time.Time) error {
+	key, err := jwt.GenerateKey(s.algorithm)
+	if err != nil {
+		return customError.NewError("TOKEN_SIGNING_ERROR", "Could not generate signing key", http.StatusInternalServerError)
+	}
+	pem, err := jwt.MarshalPrivateKey(key)
+	if err != nil {
+		return customError.NewError("TOKEN_SIGNING_ERROR", "Could not encode signing key", http.StatusInternalServerError)
+	}
+	sealed, err := securetoken.Seal(s.encryptionSecret, pem)
+	if err != nil {
+		return customError.NewError("TOKEN_SIGNING_ERROR", "Could not encrypt signing key", http.StatusInternalServerError)
+	}
+	retiresAt := activatesAt.Add(s.rotationInterval)
+	_, err = s.client.CreateIfMissing(model.SigningKey{
+		Kid:         key.Id,
+		Algorithm:   s.algorithm,
+		ActivatesAt: activatesAt,
+		RetiresAt:   retiresAt,
+		// the last token signed with the key is valid for one more TTL
+		ExpiresAt:  retiresAt.Add(jwt.AccessTokenTTL()),
+		PrivateKey: sealed,
+	})
+	return err
+}
+
+func (s *signingKeyService) decode(stored model.SigningKey) (*jwt.Key, error) {
+	pem, err := securetoken.Open(s.encryptionSecret, stored.PrivateKey)
+	if err != nil {
+		return nil, customError.NewError("INVALID_CONFIG", fmt.Sprintf("Could not decrypt signing key %s, check JWT_KEY_ENCRYPTION_SECRET", stored.Kid), http.StatusInternalServerError)
+	}
+	key, err := jwt.ParsePrivateKey(stored.Kid, stored.Algorithm, pem)
+	if err != nil {
+		return nil, customError.NewError("INVALID_CONFIG", fmt.Sprintf("Invalid signing key %s", stored.Kid), http.StatusInternalServerError)
+	}
+	return key, nil
+}
+
+// RunSigningKeyRotation calls Rotate every interval until stop is closed. The
+// interval has to be shorter than JWT_KEY_PREPUBLISH so every instance knows
+// the next key before it starts signing. A failed rotation keeps the keys
+// already installed and is retried sooner, waiting twice as long each time
+// up to the interval.
+func RunSigningKeyRotation(service ISigningKeyService, interval time.Duration, stop <-chan struct{}) {
+	retry := min(signingKeyRetryDelay, interval)
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			wait := interval
+			if err := service.Rotate(); err != nil {
+				log.Printf("signing key rotation failed, retrying in %s: %v", retry, err)
+				wait = retry
+				retry = min(retry*2, interval)
+			} else {
+				retry = min(signingKeyRetryDelay, interval)
+			}
+			timer.Reset(wait)
+		case <-stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/signingkeys"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/jwt"
+	github_com_glebarez_sqlite "github.com/glebarez/sqlite"
+	jwtv3 "github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupSigningKeys(t *testing.T, algorithm string) (*gorm.DB, func(now time.Time) *signingKeyService) {
+	db, err := gorm.Open(github_com_glebarez_sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	require.NoError(t, db.AutoMigrate(&model.SigningKey{}))
+	t.Cleanup(func() { jwt.SetKeys(nil) })
+
+	newService := func(now time.Time) *signingKeyService {
+		svc := NewSigningKeyService(signingkeys.NewSigningKeysClient(db)).(*signingKeyService)
+		svc.algorithm = algorithm
+		svc.encryptionSecret = "test-encryption-secret"
+		svc.rotationInterval = 10 * 24 * time.Hour
+		svc.prepublish = 2 * 24 * time.Hour
+		svc.now = func() time.Time { return now }
+		return svc
+	}
+	return db, newService
+}
+
+func kidOf(t *testing.T, token string) string {
+	t.Helper()
+	parsed, _, err := new(jwtv3.Parser).ParseUnverified(token, &jwt.CustomClaims{})
+	require.NoError(t, err)
+	kid, _ := parsed.Header["kid"].(string)
+	return kid
+}
+
+func TestSigningKeyService_RotatesKeys(t *testing.T) {
+	_, newService := setupSigningKeys(t, jwt.AlgRS256)
+	slot := time.Now().Truncate(10 * 24 * time.Hour)
+
+	require.NoError(t, newService(slot.Add(time.Hour)).Rotate())
+	jwks := newService(slot).JWKS()
+	require.Len(t, jwks.Keys, 1)
+	first := jwks.Keys[0].Kid
+	require.Equal(t, "RSA", jwks.Keys[0].Kty)
+	oldToken := jwt.SignDocument(uuid.New(), "student")
+	require.Equal(t, first, kidOf(t, oldToken))
+
+	// inside the prepublish window the next key is published but not used
+	require.NoError(t, newService(slot.Add(9*24*time.Hour)).Rotate())
+	require.Len(t, jwt.CurrentKeys().JWKS().Keys, 2)
+	require.Equal(t, first, kidOf(t, jwt.SignDocument(uuid.New(), "student")))
+
+	// in the next slot the new key signs and the old one still verifies
+	require.NoError(t, newService(slot.Add(10*24*time.Hour)).Rotate())
+	newToken := jwt.SignDocument(uuid.New(), "student")
+	require.NotEqual(t, first, kidOf(t, newToken))
+	_, err := jwt.ParseToken(newToken)
+	require.NoError(t, err)
+	_, err = jwt.ParseToken(oldToken)
+	require.NoError(t, err)
+
+	// once no token of the old key can be valid it is dropped
+	require.NoError(t, newService(slot.Add(10*24*time.Hour+jwt.AccessTokenTTL())).Rotate())
+	for _, key := range jwt.CurrentKeys().JWKS().Keys {
+		require.NotEqual(t, first, key.Kid)
+	}
+}
+
+func TestSigningKeyService_InstancesAgreeOnTheKey(t *testing.T) {
+	_, newService := setupSigningKeys(t, jwt.AlgEdDSA)
+	now := time.Now()
+
+	require.NoError(t, newService(now).Rotate())
+	first := jwt.CurrentKeys().Signing.Id
+	require.NoError(t, newService(now).Rotate())
+	require.Equal(t, first, jwt.CurrentKeys().Signing.Id)
+	require.Equal(t, jwt.AlgEdDSA, jwt.CurrentKeys().Signing.Algorithm)
+}
+
+func TestSigningKeyService_HS256(t *testing.T) {
+	db, newService := setupSigningKeys(t, jwt.AlgHS256)
+
+	require.NoError(t, newService(time.Now()).Rotate())
+	require.Empty(t, jwt.CurrentKeys().JWKS().Keys)
+	token := jwt.SignDocument(uuid.New(), "student")
+	require.Empty(t, kidOf(t, token))
+	_, err := jwt.ParseToken(token)
+	require.NoError(t, err)
+
+	var count int64
+	require.NoError(t, db.Model(&model.SigningKey{}).Count(&count).Error)
+	require.Zero(t, count)
+}
+
+func TestSigningKeyService_KeysAreEncrypted(t *testing.T) {
+	db, newService := setupSigningKeys(t, jwt.AlgRS256)
+	now := time.Now()
+	require.NoError(t, newService(now).Rotate())
+
+	var stored model.SigningKey
+	require.NoError(t, db.First(&stored).Error)
+	require.NotContains(t, stored.PrivateKey, "PRIVATE KEY")
+
+	other := newService(now)
+	other.encryptionSecret = "another-secret"
+	requireErrorCode(t, other.Rotate(), "INVALID_CONFIG")
+}
+
+func TestSigningKeyService_RejectsUnknownAlgorithm(t *testing.T) {
+	_, newService := setupSigningKeys(t, "none")
+	requireErrorCode(t, newService(time.Now()).Rotate(), "INVALID_CONFIG")
+}
+
+func TestSigningKeyService_StopsAcceptingLegacyTokens(t *testing.T) {
+	_, newService := setupSigningKeys(t, jwt.AlgRS256)
+	now := time.Now()
+	legacy := jwt.SignDocument(uuid.New(), "student")
+	require.Empty(t, kidOf(t, legacy))
+
+	require.NoError(t, newService(now).Rotate())
+	_, err := jwt.ParseToken(legacy)
+	require.NoError(t, err, "accepted by default")
+
+	strict := newService(now)
+	strict.acceptLegacy = false
+	require.NoError(t, strict.Rotate())
+	_, err = jwt.ParseToken(legacy)
+	require.Error(t, err)
+	_, err = jwt.ParseToken(jwt.SignDocument(uuid.New(), "student"))
+	require.NoError(t, err)
+}
+
+func TestSigningKeyService_LegacyCantBeRejectedWithHS256(t *testing.T) {
+	_, newService := setupSigningKeys(t, jwt.AlgHS256)
+	svc := newService(time.Now())
+	svc.acceptLegacy = false
+	requireErrorCode(t, svc.Rotate(), "INVALID_CONFIG")
+}
+
+// flakyRotation fails the first rotations it is asked for.
+type flakyRotation struct {
+	ISigningKeyService
+	failures int
+	calls    chan struct{}
+}
+
+func (f *flakyRotation) Rotate() error {
+	f.calls <- struct{}{}
+	if f.failures > 0 {
+		f.failures--
+		return errors.New("database is down")
+	}
+	return nil
+}
+
+func TestRunSigningKeyRotation_RetriesFailures(t *testing.T) {
+	previous := signingKeyRetryDelay
+	signingKeyRetryDelay = time.Millisecond
+	t.Cleanup(func() { signingKeyRetryDelay = previous })
+
+	service := &flakyRotation{failures: 2, calls: make(chan struct{}, 10)}
+	stop := make(chan struct{})
+	defer close(stop)
+	go RunSigningKeyRotation(service, time.Second, stop)
+
+	select {
+	case <-service.calls:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("the rotation did not run")
+	}
+	// the failures are retried long before the next interval
+	for i := 0; i < 2; i++ {
+		select {
+		case <-service.calls:
+		case <-time.After(500 * time.Millisecond):
+			t.Fatalf("retry %d did not run", i+1)
+		}
+	}
+}
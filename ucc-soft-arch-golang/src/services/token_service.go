@@ -0,0 +1,234 @@
+package services
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/tokens"
+	usersClient "github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/users"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/config"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/users"
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/jwt"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/securetoken"
+	"github.com/google/uuid"
+)
+
+// DefaultRefreshTokenTTL is used when REFRESH_TOKEN_TTL is not configured.
+const DefaultRefreshTokenTTL = 30 * 24 * time.Hour
+
+type ITokenService interface {
+	// IssueTokens signs an access token and stores a new refresh token.
+	// Suspended users are rejected with ACCOUNT_SUSPENDED. A nil
+	// familyId starts a new session (a new login) from client; otherwise the
+	// session with that id is extended. The session id is the token family.
+	IssueTokens(userId uuid.UUID, role string, familyId uuid.UUID, client users.ClientInfoDto) (users.TokenPairDto, error)
+	// ConsumeRefreshToken validates a refresh token and marks it as used.
+	// Presenting an already used token revokes its whole family.
+	ConsumeRefreshToken(refreshToken string) (model.RefreshToken, error)
+	// RevokeRefreshToken ends the session a refresh token belongs to. The
+	// token must have been issued to userId.
+	RevokeRefreshToken(userId uuid.UUID, refreshToken string) error
+	// VerifyAccessToken checks signature, expiry, the revocation denylist,
+	// that the token's session is still active and that the user isn't
+	// suspended.
+	VerifyAccessToken(token string) (*jwt.CustomClaims, error)
+	// VerifyAPIKey checks a key sent in the X-API-Key header, see
+	// IAPIKeyService.Authenticate.
+	VerifyAPIKey(key string) (*jwt.CustomClaims, error)
+	// AuditImpersonation records a request made with an impersonation
+	// token, see IImpersonationService.RecordRequest.
+	AuditImpersonation(claims *jwt.CustomClaims, request users.RequestInfoDto) error
+}
+
+type tokenService struct {
+	client     tokens.RefreshTokensClient
+	users      usersClient.UsersClient
+	revocation IRevocationService
+	apiKeys    IAPIKeyService
+	sessions   ISessionService
+	// impersonation tokens are checked against their impersonation
+	// instead of a session
+	impersonations IImpersonationService
+	refreshTTL     time.Duration
+}
+
+func NewTokenService(client *tokens.RefreshTokensClient, usersClient *usersClient.UsersClient, revocation IRevocationService, apiKeys IAPIKeyService, sessions ISessionService, impersonations IImpersonationService) ITokenService {
+	envs := config.LoadEnvs(".env")
+	return &tokenService{
+		client:         *client,
+		users:          *usersClient,
+		revocation:     revocation,
+		apiKeys:        apiKeys,
+		sessions:       sessions,
+		impersonations: impersonations,
+		refreshTTL:     config.GetDuration(envs, "REFRESH_TOKEN_TTL", DefaultRefreshTokenTTL),
+	}
+}
+
+func (t *tokenService) IssueTokens(userId uuid.UUID, role string, familyId uuid.UUID, client users.ClientInfoDto) (users.TokenPairDto, error) {
+	// the version is read together with the suspension so a token issued
+	// while an admin suspends the user is still revoked afterwards
+	user, err := t.activeUser(userId)
+	if err != nil {
+		return users.TokenPairDto{}, err
+	}
+
+	var session model.Session
+	if familyId == uuid.Nil {
+		session, err = t.sessions.Start(userId, client)
+	} else {
+		session, err = t.sessions.Refresh(familyId, userId, client)
+	}
+	if err != nil {
+		return users.TokenPairDto{}, err
+	}
+	familyId = session.Id
+
+	claims := jwt.NewCustomClaims(userId, role)
+	claims.Version = user.TokenVersion
+	claims.SessionId = session.Id.String()
+	accessToken := jwt.SignClaims(claims)
+	if accessToken == "" {
+		return users.TokenPairDto{}, customError.NewError("TOKEN_SIGNING_ERROR", "Could not sign access token", http.StatusInternalServerError)
+	}
+
+	refreshToken, err := securetoken.Generate(securetoken.DefaultSize)
+	if err != nil {
+		return users.TokenPairDto{}, customError.NewError("TOKEN_SIGNING_ERROR", "Could not generate refresh token", http.StatusInternalServerError)
+	}
+	_, err = t.client.Create(model.RefreshToken{
+		UserId:    userId,
+		FamilyId:  familyId,
+		TokenHash: securetoken.Hash(refreshToken),
+		ExpiresAt: time.Now().Add(t.refreshTTL),
+	})
+	if err != nil {
+		return users.TokenPairDto{}, err
+	}
+
+	return users.TokenPairDto{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    claims.ExpiresAt - claims.IssuedAt,
+	}, nil
+}
+
+func (t *tokenService) ConsumeRefreshToken(refreshToken string) (model.RefreshToken, error) {
+	invalid := customError.NewError("INVALID_REFRESH_TOKEN", "Invalid refresh token", http.StatusUnauthorized)
+	if refreshToken == "" {
+		return model.RefreshToken{}, invalid
+	}
+
+	stored, err := t.client.FindByHash(securetoken.Hash(refreshToken))
+	if err != nil {
+		if ce, ok := err.(*customError.Error); ok && ce.Code == "NOT_FOUND" {
+			return model.RefreshToken{}, invalid
+		}
+		return model.RefreshToken{}, err
+	}
+
+	now := time.Now()
+	if stored.UsedAt != nil || stored.RevokedAt != nil {
+		return model.RefreshToken{}, t.revokeReusedFamily(stored, now)
+	}
+	if now.After(stored.ExpiresAt) {
+		return model.RefreshToken{}, customError.NewError("REFRESH_TOKEN_EXPIRED", "Refresh token expired", http.StatusUnauthorized)
+	}
+
+	marked, err := t.client.MarkUsed(stored.Id, now)
+	if err != nil {
+		return model.RefreshToken{}, err
+	}
+	if !marked {
+		// Someone else consumed it between our read and write.
+		return model.RefreshToken{}, t.revokeReusedFamily(stored, now)
+	}
+	return stored, nil
+}
+
+func (t *tokenService) revokeReusedFamily(stored model.RefreshToken, now time.Time) error {
+	if err := t.endFamily(stored, now); err != nil {
+		return err
+	}
+	return customError.NewError("REFRESH_TOKEN_REUSED", "Refresh token was already used, all sessions of this login were revoked", http.StatusUnauthorized)
+}
+
+func (t *tokenService) RevokeRefreshToken(userId uuid.UUID, refreshToken string) error {
+	stored, err := t.client.FindByHash(securetoken.Hash(refreshToken))
+	if err != nil {
+		if ce, ok := err.(*customError.Error); ok && ce.Code == "NOT_FOUND" {
+			return customError.NewError("INVALID_REFRESH_TOKEN", "Invalid refresh token", http.StatusUnauthorized)
+		}
+		return err
+	}
+	// someone else's token is as good as an unknown one
+	if stored.UserId != userId {
+		return customError.NewError("INVALID_REFRESH_TOKEN", "Invalid refresh token", http.StatusUnauthorized)
+	}
+	return t.endFamily(stored, time.Now())
+}
+
+// endFamily ends the session of a token family. Families issued before
+// sessions existed have none, so their tokens are revoked directly.
+func (t *tokenService) endFamily(stored model.RefreshToken, now time.Time) error {
+	if err := t.sessions.End(stored.UserId, stored.FamilyId); err != nil && !isNotFound(err) {
+		return err
+	}
+	return t.client.RevokeFamily(stored.FamilyId, now)
+}
+
+func (t *tokenService) VerifyAccessToken(token string) (*jwt.CustomClaims, error) {
+	claims, err := jwt.ParseToken(token)
+	if err != nil {
+		return nil, customError.NewError("INVALID_TOKEN", "Invalid token", http.StatusUnauthorized)
+	}
+	// one lookup serves the token version and the suspension checks
+	user, err := t.users.FindById(claims.Id)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, customError.NewError("INVALID_TOKEN", "Invalid token", http.StatusUnauthorized)
+		}
+		return nil, err
+	}
+	revoked, err := t.revocation.IsRevokedFor(claims, user)
+	if err != nil {
+		return nil, customError.NewError("INVALID_TOKEN", "Invalid token", http.StatusUnauthorized)
+	}
+	if revoked {
+		return nil, customError.NewError("TOKEN_REVOKED", "Token has been revoked", http.StatusUnauthorized)
+	}
+	if user.SuspendedAt != nil {
+		return nil, accountSuspended()
+	}
+	if claims.IsImpersonation() {
+		err = t.impersonations.Verify(claims)
+	} else {
+		err = t.sessions.Verify(claims)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// activeUser loads a user and rejects it while it is suspended.
+func (t *tokenService) activeUser(userId uuid.UUID) (model.User, error) {
+	user, err := t.users.FindById(userId)
+	if err != nil {
+		return model.User{}, err
+	}
+	if user.SuspendedAt != nil {
+		return model.User{}, accountSuspended()
+	}
+	return user, nil
+}
+
+func (t *tokenService) VerifyAPIKey(key string) (*jwt.CustomClaims, error) {
+	return t.apiKeys.Authenticate(key)
+}
+
+func (t *tokenService) AuditImpersonation(claims *jwt.CustomClaims, request users.RequestInfoDto) error {
+	return t.impersonations.RecordRequest(claims, request)
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	apiKeysClient "github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/apikeys"
+	auditClient "github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/audit"
+	impersonationsClient "github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/impersonations"
+	revocationClient "github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/revocation"
+	rolesClient "github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/roles"
+	sessionClient "github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/sessions"
+	tokenClient "github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/tokens"
+	userClient "github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/users"
+	userDtos "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/users"
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/securetoken"
+	github_com_glebarez_sqlite "github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// newTokenStack builds the token and revocation services over an already
+// migrated database (users, refresh_tokens, revoked_tokens and sessions
+// tables).
+func newTokenStack(db *gorm.DB) (ITokenService, IRevocationService) {
+	refreshClient := tokenClient.NewRefreshTokensClient(db)
+	sessionsClient := sessionClient.NewSessionsClient(db)
+	revocation := NewRevocationService(
+		revocationClient.NewRevocationClient(db),
+		userClient.NewUsersClient(db),
+		refreshClient,
+		sessionsClient)
+	apiKeys := NewAPIKeyService(
+		apiKeysClient.NewAPIKeysClient(db),
+		userClient.NewUsersClient(db),
+		NewPermissionService(rolesClient.NewRolesClient(db)),
+		NewAuditService(auditClient.NewAuditClient(db)))
+	sessions := NewSessionService(sessionsClient, NewAuditService(auditClient.NewAuditClient(db)))
+	impersonations := NewImpersonationService(
+		impersonationsClient.NewImpersonationsClient(db),
+		userClient.NewUsersClient(db),
+		NewPermissionService(rolesClient.NewRolesClient(db)),
+		NewAuditService(auditClient.NewAuditClient(db)))
+	return NewTokenService(refreshClient, userClient.NewUsersClient(db), revocation, apiKeys, sessions, impersonations), revocation
+}
+
+func setupTokenService(t *testing.T) (ITokenService, *tokenClient.RefreshTokensClient, uuid.UUID) {
+	db, err := gorm.Open(github_com_glebarez_sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(&model.User{}, &model.RefreshToken{}, &model.RevokedToken{}, &model.Session{}, &model.AuditLog{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	u := model.User{Email: "tokens@ex.com", Password: "x", Name: "Tokens"}
+	if err := db.Create(&u).Error; err != nil {
+		t.Fatalf("seed user: %v", err)
+	}
+	svc, _ := newTokenStack(db)
+	return svc, tokenClient.NewRefreshTokensClient(db), u.Id
+}
+
+func TestTokenService_IssueAndVerify(t *testing.T) {
+	svc, _, userId := setupTokenService(t)
+	pair, err := svc.IssueTokens(userId, "admin", uuid.Nil, userDtos.ClientInfoDto{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	claims, err := svc.VerifyAccessToken(pair.AccessToken)
+	if err != nil {
+		t.Fatalf("unexpected verify error: %v", err)
+	}
+	if claims.Id != userId || claims.Role != "admin" {
+		t.Fatalf("unexpected claims: %+v", claims)
+	}
+}
+
+func TestTokenService_ConsumeRefreshToken_KeepsFamily(t *testing.T) {
+	svc, _, userId := setupTokenService(t)
+	pair, _ := svc.IssueTokens(userId, "student", uuid.Nil, userDtos.ClientInfoDto{})
+	stored, err := svc.ConsumeRefreshToken(pair.RefreshToken)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	next, _ := svc.IssueTokens(stored.UserId, "student", stored.FamilyId, userDtos.ClientInfoDto{})
+	rotated, err := svc.ConsumeRefreshToken(next.RefreshToken)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rotated.FamilyId != stored.FamilyId {
+		t.Fatalf("expected rotation to stay in the same family")
+	}
+}
+
+func TestTokenService_ConsumeRefreshToken_Expired(t *testing.T) {
+	svc, client, _ := setupTokenService(t)
+	plain := "expired-token"
+	_, err := client.Create(model.RefreshToken{
+		UserId:    uuid.New(),
+		FamilyId:  uuid.New(),
+		TokenHash: securetoken.Hash(plain),
+		ExpiresAt: time.Now().Add(-time.Minute),
+	})
+	if err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	_, err = svc.ConsumeRefreshToken(plain)
+	if ce, ok := err.(*customError.Error); !ok || ce.Code != "REFRESH_TOKEN_EXPIRED" {
+		t.Fatalf("expected REFRESH_TOKEN_EXPIRED, got %#v", err)
+	}
+}
+
+func TestTokenService_VerifyAccessToken_LoadsTheUserOnce(t *testing.T) {
+	db, err := gorm.Open(github_com_glebarez_sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(&model.User{}, &model.RefreshToken{}, &model.RevokedToken{}, &model.Session{}, &model.AuditLog{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	u := model.User{Email: "once@ex.com", Password: "x", Name: "Once"}
+	if err := db.Create(&u).Error; err != nil {
+		t.Fatalf("seed user: %v", err)
+	}
+	svc, _ := newTokenStack(db)
+	pair, err := svc.IssueTokens(u.Id, "student", uuid.Nil, userDtos.ClientInfoDto{})
+	if err != nil {
+		t.Fatalf("issue tokens: %v", err)
+	}
+
+	userQueries := 0
+	err = db.Callback().Query().After("gorm:query").Register("count_user_queries", func(tx *gorm.DB) {
+		if tx.Statement.Table == "users" {
+			userQueries++
+		}
+	})
+	if err != nil {
+		t.Fatalf("register callback: %v", err)
+	}
+	if _, err := svc.VerifyAccessToken(pair.AccessToken); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if userQueries != 1 {
+		t.Fatalf("expected the user to be loaded once, got %d queries", userQueries)
+	}
+}
+
+func TestTokenService_VerifyAccessToken_Invalid(t *testing.T) {
+	svc, _, _ := setupTokenService(t)
+	if _, err := svc.VerifyAccessToken("not.a.token"); err == nil {
+		t.Fatalf("expected error for invalid token")
+	}
+}
+
+func TestTokenService_RevokeRefreshToken(t *testing.T) {
+	svc, _, userId := setupTokenService(t)
+	pair, _ := svc.IssueTokens(userId, "student", uuid.Nil, userDtos.ClientInfoDto{})
+	if err := svc.RevokeRefreshToken(uuid.New(), pair.RefreshToken); err == nil {
+		t.Fatalf("expected error for a refresh token of another user")
+	}
+	if err := svc.RevokeRefreshToken(userId, pair.RefreshToken); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := svc.ConsumeRefreshToken(pair.RefreshToken); err == nil {
+		t.Fatalf("expected revoked refresh token to be rejected")
+	}
+	if err := svc.RevokeRefreshToken(userId, "unknown"); err == nil {
+		t.Fatalf("expected error for unknown refresh token")
+	}
+}
@@ -0,0 +1,435 @@
+package services
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/twofactor"
+	usersClient "github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/users"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/config"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/users"
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/securetoken"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/totp"
+	"github.com/google/uuid"
+)
+
+// Defaults used when the TWO_FACTOR_* variables are not configured.
+const (
+	DefaultTwoFactorIssuer       = "UCC Cursos"
+	DefaultTwoFactorChallengeTTL = 5 * time.Minute
+	// DefaultTwoFactorMaxAttempts is how many wrong codes burn a challenge.
+	DefaultTwoFactorMaxAttempts = 5
+	RecoveryCodeCount           = 10
+)
+
+const (
+	AuditTwoFactorEnabled  = "two_factor_enabled"
+	AuditTwoFactorDisabled = "two_factor_disabled"
+	AuditTwoFactorRequired = "two_factor_required_changed"
+	AuditRecoveryCodeUsed  = "recovery_code_used"
+)
+
+type ITwoFactorService interface {
+	// BeginEnrollment creates a new secret for a user that has no 2FA yet.
+	BeginEnrollment(userId uuid.UUID) (users.TwoFactorSetupDto, error)
+	// Enable confirms the enrollment with a code from the authenticator and
+	// returns the recovery codes, which are never shown again.
+	Enable(userId uuid.UUID, code string) ([]string, error)
+	// Disable turns 2FA off after checking a TOTP or recovery code. Users
+	// required to use 2FA can't disable it.
+	Disable(userId uuid.UUID, code string) error
+	RegenerateRecoveryCodes(userId uuid.UUID, code string) ([]string, error)
+	// Challenge is called after the password check. It returns nil when the
+	// password is enough, otherwise a challenge to pass to VerifyChallenge.
+	Challenge(user model.User) (*users.TwoFactorChallengeDto, error)
+	// SetupChallenge enrolls a user that must use 2FA but hasn't set it up,
+	// in the middle of a login.
+	SetupChallenge(challengeToken string) (users.TwoFactorSetupDto, error)
+	// VerifyChallenge checks the second factor and issues the session. Wrong
+	// codes count against the same lockout as wrong passwords, which is only
+	// cleared once the second factor passes.
+	VerifyChallenge(dto users.TwoFactorVerifyRequestDto) (users.LoginResultDto, error)
+	SetRequired(userId uuid.UUID, required bool, adminId uuid.UUID) error
+}
+
+type twoFactorService struct {
+	client        twofactor.TwoFactorClient
+	users         usersClient.UsersClient
+	tokenService  ITokenService
+	audit         IAuditService
+	throttle      ILoginThrottleService
+	issuer        string
+	challengeTTL  time.Duration
+	maxAttempts   int
+	requiredRoles map[string]bool
+	now           func() time.Time
+}
+
+func NewTwoFactorService(client *twofactor.TwoFactorClient, usersClient *usersClient.UsersClient, tokenService ITokenService, audit IAuditService, throttle ILoginThrottleService) ITwoFactorService {
+	envs := config.LoadEnvs(".env")
+	issuer := envs.Get("TWO_FACTOR_ISSUER")
+	if issuer == "" {
+		issuer = DefaultTwoFactorIssuer
+	}
+	// TWO_FACTOR_REQUIRED_ROLES forces 2FA on whole roles, e.g. "admin"
+	requiredRoles := map[string]bool{}
+	for _, role := range strings.Split(envs.Get("TWO_FACTOR_REQUIRED_ROLES"), ",") {
+		if role = strings.TrimSpace(role); role != "" {
+			requiredRoles[role] = true
+		}
+	}
+	return &twoFactorService{
+		client:        *client,
+		users:         *usersClient,
+		tokenService:  tokenService,
+		audit:         audit,
+		throttle:      throttle,
+		issuer:        issuer,
+		challengeTTL:  config.GetDuration(envs, "TWO_FACTOR_CHALLENGE_TTL", DefaultTwoFactorChallengeTTL),
+		maxAttempts:   config.GetInt(envs, "TWO_FACTOR_MAX_ATTEMPTS", DefaultTwoFactorMaxAttempts),
+		requiredRoles: requiredRoles,
+		now:           time.Now,
+	}
+}
+
+func (s *twoFactorService) BeginEnrollment(userId uuid.UUID) (users.TwoFactorSetupDto, error) {
+	user, err := s.users.FindById(userId)
+	if err != nil {
+		return users.TwoFactorSetupDto{}, err
+	}
+	current, err := s.client.FindByUser(userId)
+	if err == nil && current.Enabled {
+		return users.TwoFactorSetupDto{}, customError.NewError("TWO_FACTOR_ALREADY_ENABLED", "Two-factor authentication is already enabled", http.StatusConflict)
+	}
+	if err != nil && !isNotFound(err) {
+		return users.TwoFactorSetupDto{}, err
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return users.TwoFactorSetupDto{}, customError.NewError("TOKEN_SIGNING_ERROR", "Could not generate two-factor secret", http.StatusInternalServerError)
+	}
+	if err := s.client.SavePending(userId, secret); err != nil {
+		return users.TwoFactorSetupDto{}, err
+	}
+	return users.TwoFactorSetupDto{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+func (s *twoFactorService) Enable(userId uuid.UUID, code string) ([]string, error) {
+	current, err := s.client.FindByUser(userId)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, twoFactorNotSetUp()
+		}
+		return nil, err
+	}
+	if current.Enabled {
+		return nil, customError.NewError("TWO_FACTOR_ALREADY_ENABLED", "Two-factor authentication is already enabled", http.StatusConflict)
+	}
+	step, ok := totp.Validate(current.Secret, code, s.now())
+	if !ok {
+		return nil, invalidTwoFactorCode()
+	}
+	return s.enable(userId, step)
+}
+
+func (s *twoFactorService) enable(userId uuid.UUID, step int64) ([]string, error) {
+	if err := s.client.Enable(userId, step); err != nil {
+		return nil, err
+	}
+	codes, err := s.newRecoveryCodes(userId)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.audit.Record(model.AuditLog{Event: AuditTwoFactorEnabled, ActorId: userId, UserId: userId}); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (s *twoFactorService) Disable(userId uuid.UUID, code string) error {
+	user, err := s.users.FindById(userId)
+	if err != nil {
+		return err
+	}
+	if s.isRequired(user) {
+		return customError.NewError("TWO_FACTOR_REQUIRED", "Two-factor authentication is required for your account", http.StatusForbidden)
+	}
+	if err := s.checkEnabledCode(userId, code, true); err != nil {
+		return err
+	}
+	if err := s.client.Delete(userId); err != nil {
+		return err
+	}
+	return s.audit.Record(model.AuditLog{Event: AuditTwoFactorDisabled, ActorId: userId, UserId: userId})
+}
+
+func (s *twoFactorService) RegenerateRecoveryCodes(userId uuid.UUID, code string) ([]string, error) {
+	// a recovery code can't be used to mint new ones
+	if err := s.checkEnabledCode(userId, code, false); err != nil {
+		return nil, err
+	}
+	return s.newRecoveryCodes(userId)
+}
+
+func (s *twoFactorService) Challenge(user model.User) (*users.TwoFactorChallengeDto, error) {
+	enabled := false
+	current, err := s.client.FindByUser(user.Id)
+	switch {
+	case err == nil:
+		enabled = current.Enabled
+	case !isNotFound(err):
+		return nil, err
+	}
+	if !enabled && !s.isRequired(user) {
+		return nil, nil
+	}
+
+	token, err := securetoken.Generate(securetoken.DefaultSize)
+	if err != nil {
+		return nil, customError.NewError("TOKEN_SIGNING_ERROR", "Could not generate two-factor challenge", http.StatusInternalServerError)
+	}
+	_, err = s.client.CreateChallenge(model.TwoFactorChallenge{
+		UserId:    user.Id,
+		TokenHash: securetoken.Hash(token),
+		ExpiresAt: s.now().Add(s.challengeTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &users.TwoFactorChallengeDto{
+		ChallengeToken: token,
+		ExpiresIn:      int64(s.challengeTTL / time.Second),
+		SetupRequired:  !enabled,
+	}, nil
+}
+
+func (s *twoFactorService) SetupChallenge(challengeToken string) (users.TwoFactorSetupDto, error) {
+	challenge, err := s.findChallenge(challengeToken)
+	if err != nil {
+		return users.TwoFactorSetupDto{}, err
+	}
+	return s.BeginEnrollment(challenge.UserId)
+}
+
+func (s *twoFactorService) VerifyChallenge(dto users.TwoFactorVerifyRequestDto) (users.LoginResultDto, error) {
+	challenge, err := s.findChallenge(dto.ChallengeToken)
+	if err != nil {
+		return users.LoginResultDto{}, err
+	}
+	user, err := s.users.FindById(challenge.UserId)
+	if err != nil {
+		return users.LoginResultDto{}, err
+	}
+	if err := s.throttle.Check(user.Email, dto.ClientIp); err != nil {
+		return users.LoginResultDto{}, err
+	}
+	current, err := s.client.FindByUser(challenge.UserId)
+	if err != nil {
+		if isNotFound(err) {
+			return users.LoginResultDto{}, twoFactorNotSetUp()
+		}
+		return users.LoginResultDto{}, err
+	}
+
+	var recoveryCodes []string
+	if current.Enabled {
+		err = s.checkCode(current, dto.Code, dto.RecoveryCode)
+	} else {
+		// the user is enrolling during the login: only a TOTP code proves the
+		// authenticator works
+		step, ok := totp.Validate(current.Secret, dto.Code, s.now())
+		if !ok {
+			err = invalidTwoFactorCode()
+		} else {
+			recoveryCodes, err = s.enable(challenge.UserId, step)
+		}
+	}
+	if err != nil {
+		if ce, ok := err.(*customError.Error); ok && ce.Code == "INVALID_TWO_FACTOR_CODE" {
+			if failErr := s.registerChallengeFailure(challenge); failErr != nil {
+				return users.LoginResultDto{}, failErr
+			}
+			if failErr := s.throttle.RegisterFailure(user.Email, dto.ClientIp); failErr != nil {
+				return users.LoginResultDto{}, failErr
+			}
+		}
+		return users.LoginResultDto{}, err
+	}
+
+	marked, err := s.client.MarkChallengeUsed(challenge.ID, s.now())
+	if err != nil {
+		return users.LoginResultDto{}, err
+	}
+	if !marked {
+		return users.LoginResultDto{}, invalidChallenge()
+	}
+	if err := s.throttle.RegisterSuccess(user.Email, dto.ClientIp); err != nil {
+		return users.LoginResultDto{}, err
+	}
+
+	tokens, err := s.tokenService.IssueTokens(user.Id, user.Role, uuid.Nil, users.ClientInfoDto{Ip: dto.ClientIp, UserAgent: dto.UserAgent})
+	if err != nil {
+		return users.LoginResultDto{}, err
+	}
+	return users.LoginResultDto{
+		User: users.GetUserDto{
+			Id:            user.Id,
+			Email:         user.Email,
+			Role:          user.Role,
+			UserName:      user.Name,
+			Avatar:        user.Avatar,
+			EmailVerified: user.EmailVerified,
+		},
+		Tokens:        tokens,
+		RecoveryCodes: recoveryCodes,
+	}, nil
+}
+
+func (s *twoFactorService) SetRequired(userId uuid.UUID, required bool, adminId uuid.UUID) error {
+	if err := s.users.SetTwoFactorRequired(userId, required); err != nil {
+		return err
+	}
+	return s.audit.Record(model.AuditLog{
+		Event:   AuditTwoFactorRequired,
+		ActorId: adminId,
+		UserId:  userId,
+		Details: fmt.Sprintf("required=%t", required),
+	})
+}
+
+func (s *twoFactorService) isRequired(user model.User) bool {
+	return user.TwoFactorRequired || s.requiredRoles[user.Role]
+}
+
+// findChallenge returns a challenge that can still be used.
+func (s *twoFactorService) findChallenge(token string) (model.TwoFactorChallenge, error) {
+	if token == "" {
+		return model.TwoFactorChallenge{}, invalidChallenge()
+	}
+	challenge, err := s.client.FindChallengeByHash(securetoken.Hash(token))
+	if err != nil {
+		if isNotFound(err) {
+			return model.TwoFactorChallenge{}, invalidChallenge()
+		}
+		return model.TwoFactorChallenge{}, err
+	}
+	if challenge.UsedAt != nil || s.now().After(challenge.ExpiresAt) || challenge.Attempts >= s.maxAttempts {
+		return model.TwoFactorChallenge{}, invalidChallenge()
+	}
+	return challenge, nil
+}
+
+func (s *twoFactorService) registerChallengeFailure(challenge model.TwoFactorChallenge) error {
+	attempts, err := s.client.RegisterChallengeFailure(challenge.ID)
+	if err != nil {
+		return err
+	}
+	if attempts >= s.maxAttempts {
+		_, err = s.client.MarkChallengeUsed(challenge.ID, s.now())
+	}
+	return err
+}
+
+// checkEnabledCode verifies a code for a user that already has 2FA enabled.
+func (s *twoFactorService) checkEnabledCode(userId uuid.UUID, code string, allowRecovery bool) error {
+	current, err := s.client.FindByUser(userId)
+	if err != nil {
+		if isNotFound(err) {
+			return twoFactorNotSetUp()
+		}
+		return err
+	}
+	if !current.Enabled {
+		return twoFactorNotSetUp()
+	}
+	if allowRecovery {
+		return s.checkCode(current, code, code)
+	}
+	return s.checkCode(current, code, "")
+}
+
+// checkCode accepts either a TOTP code, once per time step, or an unused
+// recovery code.
+func (s *twoFactorService) checkCode(current model.TwoFactor, code string, recoveryCode string) error {
+	if step, ok := totp.Validate(current.Secret, code, s.now()); ok {
+		used, err := s.client.UseStep(current.UserId, step)
+		if err != nil {
+			return err
+		}
+		if used {
+			return nil
+		}
+		return invalidTwoFactorCode()
+	}
+	if recoveryCode != "" {
+		used, err := s.client.UseRecoveryCode(current.UserId, securetoken.Hash(normalizeRecoveryCode(recoveryCode)), s.now())
+		if err != nil {
+			return err
+		}
+		if used {
+			return s.audit.Record(model.AuditLog{Event: AuditRecoveryCodeUsed, ActorId: current.UserId, UserId: current.UserId})
+		}
+	}
+	return invalidTwoFactorCode()
+}
+
+func (s *twoFactorService) newRecoveryCodes(userId uuid.UUID) ([]string, error) {
+	codes := make([]string, 0, RecoveryCodeCount)
+	hashes := make([]string, 0, RecoveryCodeCount)
+	for i := 0; i < RecoveryCodeCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, customError.NewError("TOKEN_SIGNING_ERROR", "Could not generate recovery codes", http.StatusInternalServerError)
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, securetoken.Hash(normalizeRecoveryCode(code)))
+	}
+	if err := s.client.ReplaceRecoveryCodes(userId, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+const recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+// generateRecoveryCode returns 16 random characters (about 79 bits) grouped
+// as xxxx-xxxx-xxxx-xxxx.
+func generateRecoveryCode() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for i, v := range buf {
+		if i > 0 && i%4 == 0 {
+			b.WriteByte('-')
+		}
+		b.WriteByte(recoveryCodeAlphabet[int(v)%len(recoveryCodeAlphabet)])
+	}
+	return b.String(), nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(code)))
+}
+
+func invalidTwoFactorCode() error {
+	return customError.NewError("INVALID_TWO_FACTOR_CODE", "Invalid two-factor code", http.StatusUnauthorized)
+}
+
+func invalidChallenge() error {
+	return customError.NewError("INVALID_TWO_FACTOR_CHALLENGE", "Two-factor challenge is invalid or expired, please log in again", http.StatusUnauthorized)
+}
+
+func twoFactorNotSetUp() error {
+	return customError.NewError("TWO_FACTOR_NOT_SET_UP", "Two-factor authentication is not set up", http.StatusBadRequest)
+}
@@ -0,0 +1,237 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	auditClient "github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/audit"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/twofactor"
+	usersClient "github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/users"
+	userDtos "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/users"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/totp"
+	github_com_glebarez_sqlite "github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// testClock lets tests move between TOTP time steps.
+type testClock struct{ t time.Time }
+
+func (c *testClock) now() time.Time { return c.t }
+
+func setupTwoFactor(t *testing.T) (*twoFactorService, *testClock, *gorm.DB, model.User) {
+	db, err := gorm.Open(github_com_glebarez_sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	require.NoError(t, db.AutoMigrate(&model.User{}, &model.RefreshToken{}, &model.RevokedToken{}, &model.Session{},
+		&model.TwoFactor{}, &model.RecoveryCode{}, &model.TwoFactorChallenge{}, &model.AuditLog{}, &model.LoginAttempt{}))
+	hashed := legacyHash("secret")
+	user := model.User{Email: "admin@test.com", Name: "admin", Password: hashed, Role: model.RoleAdmin}
+	require.NoError(t, db.Create(&user).Error)
+
+	tokenService, _ := newTokenStack(db)
+	svc := NewTwoFactorService(
+		twofactor.NewTwoFactorClient(db),
+		usersClient.NewUsersClient(db),
+		tokenService,
+		NewAuditService(auditClient.NewAuditClient(db)),
+		allowAllThrottle{}).(*twoFactorService)
+	clock := &testClock{t: time.Unix(1700000000, 0)}
+	svc.now = clock.now
+	return svc, clock, db, user
+}
+
+func codeAt(t *testing.T, secret string, at time.Time) string {
+	t.Helper()
+	code, err := totp.CodeAt(secret, totp.Step(at))
+	require.NoError(t, err)
+	return code
+}
+
+func TestTwoFactorService_EnrollAndTwoStepLogin(t *testing.T) {
+	svc, clock, _, user := setupTwoFactor(t)
+
+	challenge, err := svc.Challenge(user)
+	require.NoError(t, err)
+	require.Nil(t, challenge, "no challenge before 2FA is enabled")
+
+	setup, err := svc.BeginEnrollment(user.Id)
+	require.NoError(t, err)
+	require.Contains(t, setup.ProvisioningURI, "secret="+setup.Secret)
+
+	_, err = svc.Enable(user.Id, "000000")
+	requireErrorCode(t, err, "INVALID_TWO_FACTOR_CODE")
+	recoveryCodes, err := svc.Enable(user.Id, codeAt(t, setup.Secret, clock.t))
+	require.NoError(t, err)
+	require.Len(t, recoveryCodes, RecoveryCodeCount)
+
+	_, err = svc.BeginEnrollment(user.Id)
+	requireErrorCode(t, err, "TWO_FACTOR_ALREADY_ENABLED")
+
+	challenge, err = svc.Challenge(user)
+	require.NoError(t, err)
+	require.NotNil(t, challenge)
+	require.False(t, challenge.SetupRequired)
+
+	// the code used to enable 2FA can't be replayed
+	_, err = svc.VerifyChallenge(userDtos.TwoFactorVerifyRequestDto{ChallengeToken: challenge.ChallengeToken, Code: codeAt(t, setup.Secret, clock.t)})
+	requireErrorCode(t, err, "INVALID_TWO_FACTOR_CODE")
+
+	clock.t = clock.t.Add(totp.Period)
+	result, err := svc.VerifyChallenge(userDtos.TwoFactorVerifyRequestDto{ChallengeToken: challenge.ChallengeToken, Code: codeAt(t, setup.Secret, clock.t)})
+	require.NoError(t, err)
+	require.NotEmpty(t, result.Tokens.AccessToken)
+	require.Equal(t, user.Id, result.User.Id)
+
+	// a challenge is single use
+	_, err = svc.VerifyChallenge(userDtos.TwoFactorVerifyRequestDto{ChallengeToken: challenge.ChallengeToken, Code: codeAt(t, setup.Secret, clock.t)})
+	requireErrorCode(t, err, "INVALID_TWO_FACTOR_CHALLENGE")
+
+	// recovery codes work once
+	challenge, _ = svc.Challenge(user)
+	_, err = svc.VerifyChallenge(userDtos.TwoFactorVerifyRequestDto{ChallengeToken: challenge.ChallengeToken, RecoveryCode: recoveryCodes[0]})
+	require.NoError(t, err)
+	challenge, _ = svc.Challenge(user)
+	_, err = svc.VerifyChallenge(userDtos.TwoFactorVerifyRequestDto{ChallengeToken: challenge.ChallengeToken, RecoveryCode: recoveryCodes[0]})
+	requireErrorCode(t, err, "INVALID_TWO_FACTOR_CODE")
+}
+
+func TestTwoFactorService_ChallengeBurnsAfterMaxAttempts(t *testing.T) {
+	svc, clock, _, user := setupTwoFactor(t)
+	setup, err := svc.BeginEnrollment(user.Id)
+	require.NoError(t, err)
+	_, err = svc.Enable(user.Id, codeAt(t, setup.Secret, clock.t))
+	require.NoError(t, err)
+
+	challenge, err := svc.Challenge(user)
+	require.NoError(t, err)
+	for i := 0; i < DefaultTwoFactorMaxAttempts; i++ {
+		_, err = svc.VerifyChallenge(userDtos.TwoFactorVerifyRequestDto{ChallengeToken: challenge.ChallengeToken, Code: "000000"})
+		requireErrorCode(t, err, "INVALID_TWO_FACTOR_CODE")
+	}
+
+	clock.t = clock.t.Add(totp.Period)
+	_, err = svc.VerifyChallenge(userDtos.TwoFactorVerifyRequestDto{ChallengeToken: challenge.ChallengeToken, Code: codeAt(t, setup.Secret, clock.t)})
+	requireErrorCode(t, err, "INVALID_TWO_FACTOR_CHALLENGE")
+}
+
+func TestTwoFactorService_RequiredByAdmin(t *testing.T) {
+	svc, clock, db, user := setupTwoFactor(t)
+	adminId := uuid.New()
+	require.NoError(t, svc.SetRequired(user.Id, true, adminId))
+	require.NoError(t, db.First(&user, "id = ?", user.Id).Error)
+
+	// the user has no 2FA yet, so the login asks to set it up
+	challenge, err := svc.Challenge(user)
+	require.NoError(t, err)
+	require.NotNil(t, challenge)
+	require.True(t, challenge.SetupRequired)
+
+	setup, err := svc.SetupChallenge(challenge.ChallengeToken)
+	require.NoError(t, err)
+	result, err := svc.VerifyChallenge(userDtos.TwoFactorVerifyRequestDto{ChallengeToken: challenge.ChallengeToken, Code: codeAt(t, setup.Secret, clock.t)})
+	require.NoError(t, err)
+	require.NotEmpty(t, result.Tokens.AccessToken)
+	require.Len(t, result.RecoveryCodes, RecoveryCodeCount)
+
+	clock.t = clock.t.Add(totp.Period)
+	err = svc.Disable(user.Id, codeAt(t, setup.Secret, clock.t))
+	requireErrorCode(t, err, "TWO_FACTOR_REQUIRED")
+
+	var entry model.AuditLog
+	require.NoError(t, db.Where("event = ?", AuditTwoFactorRequired).First(&entry).Error)
+	require.Equal(t, adminId, entry.ActorId)
+}
+
+func TestTwoFactorService_RequiredRoles(t *testing.T) {
+	svc, _, _, user := setupTwoFactor(t)
+	svc.requiredRoles = map[string]bool{model.RoleAdmin: true}
+
+	challenge, err := svc.Challenge(user)
+	require.NoError(t, err)
+	require.NotNil(t, challenge)
+	require.True(t, challenge.SetupRequired)
+}
+
+func TestTwoFactorService_Disable(t *testing.T) {
+	svc, clock, _, user := setupTwoFactor(t)
+	setup, err := svc.BeginEnrollment(user.Id)
+	require.NoError(t, err)
+	recoveryCodes, err := svc.Enable(user.Id, codeAt(t, setup.Secret, clock.t))
+	require.NoError(t, err)
+
+	require.NoError(t, svc.Disable(user.Id, recoveryCodes[1]))
+	challenge, err := svc.Challenge(user)
+	require.NoError(t, err)
+	require.Nil(t, challenge)
+}
+
+func TestAuthService_Login_ReturnsChallengeWhenTwoFactorEnabled(t *testing.T) {
+	svc, clock, db, user := setupTwoFactor(t)
+	setup, err := svc.BeginEnrollment(user.Id)
+	require.NoError(t, err)
+	_, err = svc.Enable(user.Id, codeAt(t, setup.Secret, clock.t))
+	require.NoError(t, err)
+
+	var us IUserService = &fakeUserSvc{}
+	auth := NewAuthService(&us, usersClient.NewUsersClient(db), svc.tokenService, nil, allowAllThrottle{}, svc)
+	result, err := auth.Login(userDtos.LoginRequestDto{Email: user.Email, Password: "secret"})
+	require.NoError(t, err)
+	require.NotNil(t, result.Challenge)
+	require.Empty(t, result.Tokens.AccessToken)
+}
+
+func TestTwoFactorService_WrongCodesCountTowardsLockout(t *testing.T) {
+	svc, clock, db, user := setupTwoFactor(t)
+	setup, err := svc.BeginEnrollment(user.Id)
+	require.NoError(t, err)
+	_, err = svc.Enable(user.Id, codeAt(t, setup.Secret, clock.t))
+	require.NoError(t, err)
+	throttle := newTestThrottle(db)
+	svc.throttle = throttle
+	var us IUserService = &fakeUserSvc{}
+	auth := NewAuthService(&us, usersClient.NewUsersClient(db), svc.tokenService, nil, throttle, svc)
+
+	// two wrong passwords, then the right one: the failures are kept
+	for i := 0; i < 2; i++ {
+		_, err = auth.Login(userDtos.LoginRequestDto{Email: user.Email, Password: "wrong"})
+		requireErrorCode(t, err, "INVALID CREDENTIALS")
+	}
+	result, err := auth.Login(userDtos.LoginRequestDto{Email: user.Email, Password: "secret"})
+	require.NoError(t, err)
+	require.NotNil(t, result.Challenge)
+
+	// a wrong code is the third failure and locks the account
+	_, err = svc.VerifyChallenge(userDtos.TwoFactorVerifyRequestDto{ChallengeToken: result.Challenge.ChallengeToken, Code: "000000"})
+	requireErrorCode(t, err, "INVALID_TWO_FACTOR_CODE")
+	clock.t = clock.t.Add(totp.Period)
+	_, err = svc.VerifyChallenge(userDtos.TwoFactorVerifyRequestDto{ChallengeToken: result.Challenge.ChallengeToken, Code: codeAt(t, setup.Secret, clock.t)})
+	requireErrorCode(t, err, "TOO_MANY_ATTEMPTS")
+	_, err = auth.Login(userDtos.LoginRequestDto{Email: user.Email, Password: "secret"})
+	requireErrorCode(t, err, "TOO_MANY_ATTEMPTS")
+}
+
+func TestTwoFactorService_SecondFactorResetsFailures(t *testing.T) {
+	svc, clock, db, user := setupTwoFactor(t)
+	setup, err := svc.BeginEnrollment(user.Id)
+	require.NoError(t, err)
+	_, err = svc.Enable(user.Id, codeAt(t, setup.Secret, clock.t))
+	require.NoError(t, err)
+	throttle := newTestThrottle(db)
+	svc.throttle = throttle
+	require.NoError(t, throttle.RegisterFailure(user.Email, ""))
+	require.NoError(t, throttle.RegisterFailure(user.Email, ""))
+
+	challenge, err := svc.Challenge(user)
+	require.NoError(t, err)
+	clock.t = clock.t.Add(totp.Period)
+	_, err = svc.VerifyChallenge(userDtos.TwoFactorVerifyRequestDto{ChallengeToken: challenge.ChallengeToken, Code: codeAt(t, setup.Secret, clock.t)})
+	require.NoError(t, err)
+
+	// the earlier failures are gone, so one more doesn't lock
+	require.NoError(t, throttle.RegisterFailure(user.Email, ""))
+	require.NoError(t, throttle.Check(user.Email, ""))
+}
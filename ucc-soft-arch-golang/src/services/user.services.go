@@ -1,15 +1,21 @@
 package services
 
 import (
+	"net/http"
+	"strings"
+
 	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/users"
 	userDomain "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/users"
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
 	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
-	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/bcrypt"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/password"
 	"github.com/google/uuid"
 )
 
 type UserService struct {
-	client users.UsersClient
+	client     users.UsersClient
+	revocation IRevocationService
+	policy     IPasswordPolicyService
 }
 
 type IUserService interface {
@@ -19,12 +25,15 @@ type IUserService interface {
 	UpdateUser(dto userDomain.UpdateRequestDto) (userDomain.UpdateResponseDto, error)
 }
 
-func NewUserService(client *users.UsersClient) IUserService {
-	return &UserService{client: *client}
+func NewUserService(client *users.UsersClient, revocation IRevocationService, policy IPasswordPolicyService) IUserService {
+	return &UserService{client: *client, revocation: revocation, policy: policy}
 }
 
 func (u *UserService) CreateUser(user userDomain.RegisterRequest) (userDomain.RegisterResponse, error) {
-	hassedPassword, err := bcrypt.HasPassword(user.Password)
+	if err := u.policy.Validate(uuid.Nil, user.Password); err != nil {
+		return userDomain.RegisterResponse{}, err
+	}
+	hassedPassword, err := password.Hash(user.Password)
 	if err != nil {
 		return userDomain.RegisterResponse{}, err
 	}
@@ -34,12 +43,16 @@ func (u *UserService) CreateUser(user userDomain.RegisterRequest) (userDomain.Re
 		Email:    user.Email,
 		Name:     user.Username,
 		Avatar:   user.Avatar,
+		Role:     model.RoleStudent,
 	}
 
 	response, err := u.client.Create(newUser)
 	if err != nil {
 		return userDomain.RegisterResponse{}, err
 	}
+	if err := u.policy.Remember(response.Id, hassedPassword); err != nil {
+		return userDomain.RegisterResponse{}, err
+	}
 
 	return userDomain.RegisterResponse{
 		Id:       response.Id,
@@ -56,11 +69,12 @@ func (u *UserService) GetUserById(id uuid.UUID) (userDomain.GetUserDto, error) {
 		return userDomain.GetUserDto{}, err
 	}
 	return userDomain.GetUserDto{
-		Id:       user.Id,
-		Email:    user.Email,
-		Role:     user.Role,
-		UserName: user.Name,
-		Avatar:   user.Avatar,
+		Id:            user.Id,
+		Email:         user.Email,
+		Role:          user.Role,
+		UserName:      user.Name,
+		Avatar:        user.Avatar,
+		EmailVerified: user.EmailVerified,
 	}, nil
 }
 
@@ -71,18 +85,35 @@ func (u *UserService) GetUserByEmail(email string) (userDomain.GetUserDto, error
 	}
 
 	return userDomain.GetUserDto{
-		Id:       user.Id,
-		Email:    user.Email,
-		Role:     user.Role,
-		UserName: user.Name,
-		Avatar:   user.Avatar,
+		Id:            user.Id,
+		Email:         user.Email,
+		Role:          user.Role,
+		UserName:      user.Name,
+		Avatar:        user.Avatar,
+		EmailVerified: user.EmailVerified,
 	}, nil
 }
 func (u *UserService) UpdateUser(dto userDomain.UpdateRequestDto) (userDomain.UpdateResponseDto, error) {
+	current, err := u.client.FindById(dto.Id)
+	if err != nil {
+		return userDomain.UpdateResponseDto{}, err
+	}
+	emailChanged := dto.Email != "" && !strings.EqualFold(dto.Email, current.Email)
+
 	var user model.User
 	user.Id = dto.Id
 	if dto.Password != "" {
-		hassedPassword, _ := bcrypt.HasPassword(dto.Password)
+		// a stolen session must not be enough to take the account over
+		if !password.Compare(dto.CurrentPassword, current.Password) {
+			return userDomain.UpdateResponseDto{}, customError.NewError("INVALID_CURRENT_PASSWORD", "The current password is incorrect", http.StatusForbidden)
+		}
+		if err := u.policy.Validate(dto.Id, dto.Password); err != nil {
+			return userDomain.UpdateResponseDto{}, err
+		}
+		hassedPassword, err := password.Hash(dto.Password)
+		if err != nil {
+			return userDomain.UpdateResponseDto{}, err
+		}
 		dto.Password = hassedPassword
 		user.Password = dto.Password
 	}
@@ -96,16 +127,33 @@ func (u *UserService) UpdateUser(dto userDomain.UpdateRequestDto) (userDomain.Up
 		user.Avatar = dto.Avatar
 	}
 
-	user, err := u.client.UpdateUser(user)
+	user, err = u.client.UpdateUser(user)
 	if err != nil {
 		return userDomain.UpdateResponseDto{}, err
 	}
+	// the new email has to be verified again before enrolling or commenting
+	if emailChanged {
+		if err := u.client.MarkEmailUnverified(user.Id); err != nil {
+			return userDomain.UpdateResponseDto{}, err
+		}
+	}
+
+	// A new password must log out every existing session, including stolen ones.
+	if dto.Password != "" {
+		if err := u.policy.Remember(user.Id, dto.Password); err != nil {
+			return userDomain.UpdateResponseDto{}, err
+		}
+		if err := u.revocation.RevokeAllForUser(user.Id); err != nil {
+			return userDomain.UpdateResponseDto{}, err
+		}
+	}
 
 	return userDomain.UpdateResponseDto{
-		Id:       user.Id,
-		Username: user.Name,
-		Email:    user.Email,
-		Avatar:   user.Avatar,
-		Role:     user.Role,
+		Id:            user.Id,
+		Username:      user.Name,
+		Email:         user.Email,
+		Avatar:        user.Avatar,
+		Role:          user.Role,
+		EmailVerified: current.EmailVerified && !emailChanged,
 	}, nil
 }
@@ -11,27 +11,28 @@ import (
 	usersClient "github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/users"
 	userDto "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/users"
 	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
-	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/bcrypt"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/password"
 )
 
-func setupUsersClientSQLite(t *testing.T) *usersClient.UsersClient {
+func setupUsersClientSQLite(t *testing.T) (*usersClient.UsersClient, IRevocationService) {
 	db, err := gorm.Open(github_com_glebarez_sqlite.Open(":memory:"), &gorm.Config{})
 	if err != nil {
 		t.Fatalf("failed to open sqlite db: %v", err)
 	}
-	require.NoError(t, db.AutoMigrate(&model.User{}))
-	return usersClient.NewUsersClient(db)
+	require.NoError(t, db.AutoMigrate(&model.User{}, &model.RefreshToken{}, &model.RevokedToken{}, &model.Session{}, &model.PasswordHistory{}))
+	_, revocation := newTokenStack(db)
+	return usersClient.NewUsersClient(db), revocation
 }
 
 func TestUserService_Create_Get_Update(t *testing.T) {
-	client := setupUsersClientSQLite(t)
-	svcInterface := NewUserService(client)
+	client, revocation := setupUsersClientSQLite(t)
+	svcInterface := NewUserService(client, revocation, newPasswordPolicy(client.Db))
 	svc := svcInterface.(*UserService)
 
 	// Create
 	reg, err := svc.CreateUser(userDto.RegisterRequest{
 		Email:    "a@b.com",
-		Password: "secret",
+		Password: "s3cret-passphrase",
 		Username: "alice",
 		Avatar:   "pic.png",
 	})
@@ -44,8 +45,8 @@ func TestUserService_Create_Get_Update(t *testing.T) {
 	// Ensure password hashed in DB
 	var inDB model.User
 	require.NoError(t, client.Db.First(&inDB, "id = ?", reg.Id).Error)
-	require.NotEqual(t, "secret", inDB.Password)
-	require.True(t, bcrypt.ComparePassword("secret", inDB.Password))
+	require.NotEqual(t, "s3cret-passphrase", inDB.Password)
+	require.True(t, password.Compare("s3cret-passphrase", inDB.Password))
 
 	// Get by id
 	byID, err := svc.GetUserById(reg.Id)
@@ -69,12 +70,35 @@ func TestUserService_Create_Get_Update(t *testing.T) {
 	require.Equal(t, "alice2", upd.Username)
 	require.Equal(t, "pic2.png", upd.Avatar)
 
+	// a new email has to be verified again
+	require.NoError(t, client.MarkEmailVerified(reg.Id))
+	upd, err = svc.UpdateUser(userDto.UpdateRequestDto{Id: reg.Id, Email: "A@B.com"})
+	require.NoError(t, err)
+	require.True(t, upd.EmailVerified)
+	upd, err = svc.UpdateUser(userDto.UpdateRequestDto{Id: reg.Id, Email: "alice@b.com"})
+	require.NoError(t, err)
+	require.False(t, upd.EmailVerified)
+	require.NoError(t, client.Db.First(&inDB, "id = ?", reg.Id).Error)
+	require.Equal(t, "alice@b.com", inDB.Email)
+	require.False(t, inDB.EmailVerified)
+
+	// the current password is needed to set a new one
+	_, err = svc.UpdateUser(userDto.UpdateRequestDto{Id: reg.Id, Password: "n3w-s3cret-passphrase"})
+	requireErrorCode(t, err, "INVALID_CURRENT_PASSWORD")
+	_, err = svc.UpdateUser(userDto.UpdateRequestDto{Id: reg.Id, Password: "n3w-s3cret-passphrase", CurrentPassword: "wrong"})
+	requireErrorCode(t, err, "INVALID_CURRENT_PASSWORD")
+	require.NoError(t, client.Db.First(&inDB, "id = ?", reg.Id).Error)
+	require.True(t, password.Compare("s3cret-passphrase", inDB.Password))
+
 	// Update password path (ensure hashed)
 	_, err = svc.UpdateUser(userDto.UpdateRequestDto{
-		Id:       reg.Id,
-		Password: "newsecret",
+		Id:              reg.Id,
+		Password:        "n3w-s3cret-passphrase",
+		CurrentPassword: "s3cret-passphrase",
 	})
 	require.NoError(t, err)
 	require.NoError(t, client.Db.First(&inDB, "id = ?", reg.Id).Error)
-	require.True(t, bcrypt.ComparePassword("newsecret", inDB.Password))
+	require.True(t, password.Compare("n3w-s3cret-passphrase", inDB.Password))
+	// changing the password revokes every token issued before
+	require.Equal(t, 1, inDB.TokenVersion)
 }
@@ -0,0 +1,256 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	usersClient "github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/users"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/users"
+	customError "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/errors"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+	"github.com/google/uuid"
+)
+
+// Page sizes of the admin user listing.
+const (
+	DefaultUsersPageSize = 20
+	MaxUsersPageSize     = 100
+	MaxSuspendReason     = 500
+)
+
+const (
+	AuditUserRoleChanged     = "user_role_changed"
+	AuditUserSuspended       = "user_suspended"
+	AuditUserReactivated     = "user_reactivated"
+	AuditPasswordResetForced = "password_reset_forced"
+)
+
+type IUserAdminService interface {
+	// List pages through users matching query (name or email), role and
+	// status ("", "active" or "suspended"). Pages start at 1.
+	List(query string, role string, status string, page int, limit int) (users.AdminUsersPageDto, error)
+	Get(id uuid.UUID) (users.AdminUserDto, error)
+	// ChangeRole moves a user to an existing role. The user's tokens are
+	// revoked so the new role applies right away.
+	ChangeRole(id uuid.UUID, role string, actorId uuid.UUID) (users.AdminUserDto, error)
+	// Suspend keeps the user from signing in and ends all of its sessions.
+	Suspend(id uuid.UUID, reason string, actorId uuid.UUID) (users.AdminUserDto, error)
+	Reactivate(id uuid.UUID, actorId uuid.UUID) (users.AdminUserDto, error)
+	// ForcePasswordReset signs the user out, blocks password logins and
+	// emails a reset link. Logins work again once the password is reset.
+	ForcePasswordReset(id uuid.UUID, actorId uuid.UUID) error
+}
+
+type userAdminService struct {
+	users         usersClient.UsersClient
+	permissions   IPermissionService
+	revocation    IRevocationService
+	passwordReset IPasswordResetService
+	audit         IAuditService
+	now           func() time.Time
+}
+
+func NewUserAdminService(client *usersClient.UsersClient, permissions IPermissionService, revocation IRevocationService, passwordReset IPasswordResetService, audit IAuditService) IUserAdminService {
+	return &userAdminService{
+		users:         *client,
+		permissions:   permissions,
+		revocation:    revocation,
+		passwordReset: passwordReset,
+		audit:         audit,
+		now:           time.Now,
+	}
+}
+
+func (s *userAdminService) List(query string, role string, status string, page int, limit int) (users.AdminUsersPageDto, error) {
+	if status != "" && status != usersClient.StatusActive && status != usersClient.StatusSuspended {
+		return users.AdminUsersPageDto{}, customError.NewError("INVALID_STATUS", "status must be active or suspended", http.StatusBadRequest)
+	}
+	if page < 1 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = DefaultUsersPageSize
+	}
+	if limit > MaxUsersPageSize {
+		limit = MaxUsersPageSize
+	}
+
+	found, total, err := s.users.Search(query, role, status, (page-1)*limit, limit)
+	if err != nil {
+		return users.AdminUsersPageDto{}, err
+	}
+	result := users.AdminUsersPageDto{
+		Users: make([]users.AdminUserDto, 0, len(found)),
+		Total: total,
+		Page:  page,
+		Limit: limit,
+	}
+	for _, user := range found {
+		result.Users = append(result.Users, adminUserDto(user))
+	}
+	return result, nil
+}
+
+func (s *userAdminService) Get(id uuid.UUID) (users.AdminUserDto, error) {
+	user, err := s.users.FindById(id)
+	if err != nil {
+		return users.AdminUserDto{}, err
+	}
+	return adminUserDto(user), nil
+}
+
+func (s *userAdminService) ChangeRole(id uuid.UUID, role string, actorId uuid.UUID) (users.AdminUserDto, error) {
+	role = strings.TrimSpace(role)
+	if role == "" {
+		return users.AdminUserDto{}, customError.NewError("ROLE_REQUIRED", "role is required", http.StatusBadRequest)
+	}
+	// admins can't lock themselves out of the admin area
+	if id == actorId {
+		return users.AdminUserDto{}, cannotModifySelf()
+	}
+	exists, err := s.permissions.RoleExists(role)
+	if err != nil {
+		return users.AdminUserDto{}, err
+	}
+	if !exists {
+		return users.AdminUserDto{}, customError.NewError("INVALID_ROLE", "Role does not exist", http.StatusBadRequest)
+	}
+	user, err := s.users.FindById(id)
+	if err != nil {
+		return users.AdminUserDto{}, err
+	}
+	if user.Role == role {
+		return adminUserDto(user), nil
+	}
+
+	if err := s.users.SetRole(id, role); err != nil {
+		return users.AdminUserDto{}, err
+	}
+	if err := s.revocation.RevokeAllForUser(id); err != nil {
+		return users.AdminUserDto{}, err
+	}
+	if err := s.audit.Record(model.AuditLog{
+		Event:   AuditUserRoleChanged,
+		ActorId: actorId,
+		UserId:  id,
+		Email:   user.Email,
+		Details: fmt.Sprintf("from=%s to=%s", user.Role, role),
+	}); err != nil {
+		return users.AdminUserDto{}, err
+	}
+	user.Role = role
+	return adminUserDto(user), nil
+}
+
+func (s *userAdminService) Suspend(id uuid.UUID, reason string, actorId uuid.UUID) (users.AdminUserDto, error) {
+	reason = strings.TrimSpace(reason)
+	if len(reason) > MaxSuspendReason {
+		return users.AdminUserDto{}, customError.NewError("INVALID_REASON", fmt.Sprintf("reason can't be longer than %d characters", MaxSuspendReason), http.StatusBadRequest)
+	}
+	if id == actorId {
+		return users.AdminUserDto{}, cannotModifySelf()
+	}
+	user, err := s.users.FindById(id)
+	if err != nil {
+		return users.AdminUserDto{}, err
+	}
+	if user.SuspendedAt != nil {
+		return users.AdminUserDto{}, customError.NewError("USER_ALREADY_SUSPENDED", "User is already suspended", http.StatusConflict)
+	}
+
+	now := s.now()
+	// suspended first: tokens issued before the revocation carry the old
+	// version, tokens issued after it are refused
+	if err := s.users.SetSuspended(id, &now, reason); err != nil {
+		return users.AdminUserDto{}, err
+	}
+	if err := s.revocation.RevokeAllForUser(id); err != nil {
+		return users.AdminUserDto{}, err
+	}
+	if err := s.audit.Record(model.AuditLog{
+		Event:   AuditUserSuspended,
+		ActorId: actorId,
+		UserId:  id,
+		Email:   user.Email,
+		Details: reason,
+	}); err != nil {
+		return users.AdminUserDto{}, err
+	}
+	user.SuspendedAt = &now
+	user.SuspendedReason = reason
+	return adminUserDto(user), nil
+}
+
+func (s *userAdminService) Reactivate(id uuid.UUID, actorId uuid.UUID) (users.AdminUserDto, error) {
+	user, err := s.users.FindById(id)
+	if err != nil {
+		return users.AdminUserDto{}, err
+	}
+	if user.SuspendedAt == nil {
+		return users.AdminUserDto{}, customError.NewError("USER_NOT_SUSPENDED", "User is not suspended", http.StatusConflict)
+	}
+	if err := s.users.SetSuspended(id, nil, ""); err != nil {
+		return users.AdminUserDto{}, err
+	}
+	if err := s.audit.Record(model.AuditLog{
+		Event:   AuditUserReactivated,
+		ActorId: actorId,
+		UserId:  id,
+		Email:   user.Email,
+	}); err != nil {
+		return users.AdminUserDto{}, err
+	}
+	user.SuspendedAt = nil
+	user.SuspendedReason = ""
+	return adminUserDto(user), nil
+}
+
+func (s *userAdminService) ForcePasswordReset(id uuid.UUID, actorId uuid.UUID) error {
+	user, err := s.users.FindById(id)
+	if err != nil {
+		return err
+	}
+	if err := s.users.SetPasswordResetRequired(id, true); err != nil {
+		return err
+	}
+	if err := s.revocation.RevokeAllForUser(id); err != nil {
+		return err
+	}
+	if err := s.audit.Record(model.AuditLog{
+		Event:   AuditPasswordResetForced,
+		ActorId: actorId,
+		UserId:  id,
+		Email:   user.Email,
+	}); err != nil {
+		return err
+	}
+	return s.passwordReset.RequestReset(user.Email)
+}
+
+func adminUserDto(user model.User) users.AdminUserDto {
+	return users.AdminUserDto{
+		Id:                    user.Id,
+		Email:                 user.Email,
+		Role:                  user.Role,
+		UserName:              user.Name,
+		Avatar:                user.Avatar,
+		EmailVerified:         user.EmailVerified,
+		TwoFactorRequired:     user.TwoFactorRequired,
+		Suspended:             user.SuspendedAt != nil,
+		SuspendedAt:           user.SuspendedAt,
+		SuspendedReason:       user.SuspendedReason,
+		PasswordResetRequired: user.PasswordResetRequired,
+		DeletionScheduledAt:   user.DeletionScheduledAt,
+		CreatedAt:             user.CreatedAt,
+	}
+}
+
+func accountSuspended() error {
+	return customError.NewError("ACCOUNT_SUSPENDED", "This account has been suspended", http.StatusForbidden)
+}
+
+func cannotModifySelf() error {
+	return customError.NewError("CANNOT_MODIFY_SELF", "Admins can't change their own role or suspend themselves", http.StatusBadRequest)
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"testing"
+
+	github_com_glebarez_sqlite "github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	auditClient "github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/audit"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/passwordreset"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/roles"
+	usersClient "github.com/Guidotss/ucc-soft-arch-golang.git/src/clients/users"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/config"
+	userDtos "github.com/Guidotss/ucc-soft-arch-golang.git/src/domain/dtos/users"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/model"
+)
+
+type userAdminFixture struct {
+	svc    IUserAdminService
+	tokens ITokenService
+	audit  IAuditService
+	mail   *captureMailer
+	users  *usersClient.UsersClient
+	admin  model.User
+	target model.User
+}
+
+func setupUserAdmin(t *testing.T) userAdminFixture {
+	db, err := gorm.Open(github_com_glebarez_sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	require.NoError(t, db.AutoMigrate(&model.User{}, &model.Permission{}, &model.Role{}, &model.RefreshToken{}, &model.RevokedToken{},
+		&model.Session{}, &model.AuditLog{}, &model.PasswordReset{}, &model.PasswordHistory{}))
+	require.NoError(t, config.SeedRoles(db))
+
+	client := usersClient.NewUsersClient(db)
+	admin, err := client.Create(model.User{Email: "admin@test.com", Name: "Admin", Password: "x", Role: model.RoleAdmin})
+	require.NoError(t, err)
+	target, err := client.Create(model.User{Email: "student@test.com", Name: "Student", Password: "x", Role: model.RoleStudent})
+	require.NoError(t, err)
+
+	tokens, revocation := newTokenStack(db)
+	audit := NewAuditService(auditClient.NewAuditClient(db))
+	mail := &captureMailer{}
+	reset := NewPasswordResetService(client, passwordreset.NewPasswordResetClient(db), mail, revocation, newPasswordPolicy(db))
+	svc := NewUserAdminService(client, NewPermissionService(roles.NewRolesClient(db)), revocation, reset, audit)
+	return userAdminFixture{svc: svc, tokens: tokens, audit: audit, mail: mail, users: client, admin: admin, target: target}
+}
+
+func TestUserAdminService_List(t *testing.T) {
+	f := setupUserAdmin(t)
+
+	page, err := f.svc.List("", "", "", 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), page.Total)
+	require.Equal(t, 1, page.Page)
+	require.Equal(t, DefaultUsersPageSize, page.Limit)
+
+	page, err = f.svc.List("STUDENT", "", "", 1, 500)
+	require.NoError(t, err)
+	require.Equal(t, MaxUsersPageSize, page.Limit)
+	require.Len(t, page.Users, 1)
+	require.Equal(t, f.target.Id, page.Users[0].Id)
+
+	page, err = f.svc.List("", model.RoleAdmin, "", 2, 1)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), page.Total)
+	require.Empty(t, page.Users)
+
+	_, err = f.svc.List("", "", "banned", 1, 10)
+	requireErrorCode(t, err, "INVALID_STATUS")
+}
+
+func TestUserAdminService_ChangeRole(t *testing.T) {
+	f := setupUserAdmin(t)
+	pair, err := f.tokens.IssueTokens(f.target.Id, f.target.Role, uuid.Nil, userDtos.ClientInfoDto{})
+	require.NoError(t, err)
+
+	_, err = f.svc.ChangeRole(f.target.Id, "superuser", f.admin.Id)
+	requireErrorCode(t, err, "INVALID_ROLE")
+	_, err = f.svc.ChangeRole(f.admin.Id, model.RoleStudent, f.admin.Id)
+	requireErrorCode(t, err, "CANNOT_MODIFY_SELF")
+	_, err = f.svc.ChangeRole(uuid.New(), model.RoleInstructor, f.admin.Id)
+	requireErrorCode(t, err, "NOT_FOUND")
+
+	user, err := f.svc.ChangeRole(f.target.Id, model.RoleInstructor, f.admin.Id)
+	require.NoError(t, err)
+	require.Equal(t, model.RoleInstructor, user.Role)
+	stored, _ := f.users.FindById(f.target.Id)
+	require.Equal(t, model.RoleInstructor, stored.Role)
+
+	// the old token still carries the old role
+	_, err = f.tokens.VerifyAccessToken(pair.AccessToken)
+	requireErrorCode(t, err, "TOKEN_REVOKED")
+	logs, err := f.audit.List(AuditUserRoleChanged, f.target.Id, 0)
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	require.Equal(t, f.admin.Id, logs[0].ActorId)
+}
+
+func TestUserAdminService_SuspendAndReactivate(t *testing.T) {
+	f := setupUserAdmin(t)
+	pair, err := f.tokens.IssueTokens(f.target.Id, f.target.Role, uuid.Nil, userDtos.ClientInfoDto{})
+	require.NoError(t, err)
+
+	_, err = f.svc.Suspend(f.admin.Id, "", f.admin.Id)
+	requireErrorCode(t, err, "CANNOT_MODIFY_SELF")
+
+	user, err := f.svc.Suspend(f.target.Id, "  spam  ", f.admin.Id)
+	require.NoError(t, err)
+	require.True(t, user.Suspended)
+	require.Equal(t, "spam", user.SuspendedReason)
+	_, err = f.svc.Suspend(f.target.Id, "again", f.admin.Id)
+	requireErrorCode(t, err, "USER_ALREADY_SUSPENDED")
+
+	_, err = f.tokens.VerifyAccessToken(pair.AccessToken)
+	require.Error(t, err)
+	_, err = f.tokens.IssueTokens(f.target.Id, f.target.Role, uuid.Nil, userDtos.ClientInfoDto{})
+	requireErrorCode(t, err, "ACCOUNT_SUSPENDED")
+
+	user, err = f.svc.Reactivate(f.target.Id, f.admin.Id)
+	require.NoError(t, err)
+	require.False(t, user.Suspended)
+	_, err = f.svc.Reactivate(f.target.Id, f.admin.Id)
+	requireErrorCode(t, err, "USER_NOT_SUSPENDED")
+	_, err = f.tokens.IssueTokens(f.target.Id, f.target.Role, uuid.Nil, userDtos.ClientInfoDto{})
+	require.NoError(t, err)
+
+	logs, _ := f.audit.List("", f.target.Id, 0)
+	require.Len(t, logs, 2)
+}
+
+func TestUserAdminService_ForcePasswordReset(t *testing.T) {
+	f := setupUserAdmin(t)
+	pair, err := f.tokens.IssueTokens(f.target.Id, f.target.Role, uuid.Nil, userDtos.ClientInfoDto{})
+	require.NoError(t, err)
+
+	require.NoError(t, f.svc.ForcePasswordReset(f.target.Id, f.admin.Id))
+	stored, _ := f.users.FindById(f.target.Id)
+	require.True(t, stored.PasswordResetRequired)
+	require.Len(t, f.mail.sent, 1)
+	require.Equal(t, f.target.Email, f.mail.sent[0].To)
+	_, err = f.tokens.VerifyAccessToken(pair.AccessToken)
+	requireErrorCode(t, err, "TOKEN_REVOKED")
+
+	requireErrorCode(t, f.svc.ForcePasswordReset(uuid.New(), f.admin.Id), "NOT_FOUND")
+}
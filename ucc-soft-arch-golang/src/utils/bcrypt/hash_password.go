@@ -1,11 +0,0 @@
-package bcrypt
-
-import "golang.org/x/crypto/bcrypt"
-
-func HasPassword(password string) (string, error) {
-	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err != nil {
-		return "", err
-	}
-	return string(hash), nil
-}
@@ -0,0 +1,218 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/config"
+	"github.com/google/uuid"
+)
+
+// Supported signing algorithms. HS256 uses JWT_SECRET and is kept for
+// backward compatibility; the asymmetric ones can be verified by other
+// services through the JWKS.
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+// RSAKeyBits is the size of generated RS256 keys.
+const RSAKeyBits = 2048
+
+// Key is an asymmetric signing key, named in tokens by the kid header.
+type Key struct {
+	Id        string
+	Algorithm string
+	Private   crypto.Signer
+}
+
+// KeySet holds everything tokens are signed and verified with. Tokens are
+// signed with Signing, or with the HS256 Secret when Signing is nil. A token
+// with a kid is verified with that key only; a token without one must be
+// HS256, unless RejectLegacy is set once no such token is in use anymore.
+type KeySet struct {
+	Secret       []byte
+	Signing      *Key
+	Keys         map[string]*Key
+	RejectLegacy bool
+}
+
+// JWK is the public part of a key as published in the JWKS.
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+type settings struct {
+	secret         []byte
+	accessTokenTTL time.Duration
+}
+
+var (
+	loadSettings = sync.OnceValue(func() settings {
+		envs := config.LoadEnvs(".env")
+		return settings{
+			secret:         []byte(envs.Get("JWT_SECRET")),
+			accessTokenTTL: config.GetDuration(envs, "ACCESS_TOKEN_TTL", DefaultAccessTokenTTL),
+		}
+	})
+	current atomic.Pointer[KeySet]
+)
+
+// Secret returns JWT_SECRET, read once from the environment.
+func Secret() []byte {
+	return loadSettings().secret
+}
+
+// CurrentKeys returns the installed key set. Until SetKeys is called tokens
+// are signed with HS256 only.
+func CurrentKeys() *KeySet {
+	if keys := current.Load(); keys != nil {
+		return keys
+	}
+	return &KeySet{Secret: Secret()}
+}
+
+// SetKeys installs the key set used by SignClaims and ParseToken.
+func SetKeys(keys *KeySet) {
+	current.Store(keys)
+}
+
+// GenerateKey creates a new key with a random kid.
+func GenerateKey(algorithm string) (*Key, error) {
+	var private crypto.Signer
+	switch algorithm {
+	case AlgRS256:
+		key, err := rsa.GenerateKey(rand.Reader, RSAKeyBits)
+		if err != nil {
+			return nil, err
+		}
+		private = key
+	case AlgEdDSA:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		private = key
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", algorithm)
+	}
+	return &Key{Id: uuid.NewString(), Algorithm: algorithm, Private: private}, nil
+}
+
+// MarshalPrivateKey encodes the private key as a PKCS #8 PEM block.
+func MarshalPrivateKey(key *Key) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key.Private)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// ParsePrivateKey is the inverse of MarshalPrivateKey.
+func ParsePrivateKey(id string, algorithm string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key := &Key{Id: id, Algorithm: algorithm}
+	switch private := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Private = private
+	case ed25519.PrivateKey:
+		key.Private = private
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", parsed)
+	}
+	if !key.matchesAlgorithm() {
+		return nil, fmt.Errorf("key %s is not a %s key", id, algorithm)
+	}
+	return key, nil
+}
+
+func (k *Key) matchesAlgorithm() bool {
+	switch k.Private.(type) {
+	case *rsa.PrivateKey:
+		return k.Algorithm == AlgRS256
+	case ed25519.PrivateKey:
+		return k.Algorithm == AlgEdDSA
+	}
+	return false
+}
+
+// JWK returns the public key in JWK form.
+func (k *Key) JWK() JWK {
+	jwk := JWK{Use: "sig", Alg: k.Algorithm, Kid: k.Id}
+	switch public := k.Private.Public().(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(public)
+	}
+	return jwk
+}
+
+// JWKS lists the public keys of the set, sorted by kid. The HS256 secret is
+// never published.
+func (s *KeySet) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	for _, key := range s.Keys {
+		set.Keys = append(set.Keys, key.JWK())
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return set
+}
+
+// verificationKey picks the key a token must be verified with from its alg
+// and kid headers. It works with any version of the jwt library.
+func (s *KeySet) verificationKey(alg string, header map[string]interface{}) (interface{}, error) {
+	kid, _ := header["kid"].(string)
+	if kid == "" {
+		if s.RejectLegacy {
+			return nil, fmt.Errorf("tokens without a key id are no longer accepted")
+		}
+		if alg != AlgHS256 {
+			return nil, fmt.Errorf("unexpected signing method: %v", alg)
+		}
+		return s.Secret, nil
+	}
+	key, ok := s.Keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	if key.Algorithm != alg {
+		return nil, fmt.Errorf("unexpected signing method %v for key %s", alg, kid)
+	}
+	return key.Private.Public(), nil
+}
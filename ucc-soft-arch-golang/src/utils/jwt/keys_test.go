@@ -0,0 +1,142 @@
+package jwt
+
+import (
+	"testing"
+
+	jwtv3 "github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+)
+
+func installKeys(t *testing.T, keys *KeySet) {
+	t.Helper()
+	SetKeys(keys)
+	t.Cleanup(func() { SetKeys(nil) })
+}
+
+func mustGenerate(t *testing.T, algorithm string) *Key {
+	t.Helper()
+	key, err := GenerateKey(algorithm)
+	if err != nil {
+		t.Fatalf("generate %s key: %v", algorithm, err)
+	}
+	return key
+}
+
+func TestKeySet_SignsWithAsymmetricKeys(t *testing.T) {
+	for _, algorithm := range []string{AlgRS256, AlgEdDSA} {
+		key := mustGenerate(t, algorithm)
+		installKeys(t, &KeySet{Secret: []byte("secret"), Signing: key, Keys: map[string]*Key{key.Id: key}})
+
+		token := SignDocument(uuid.New(), "student")
+		parsed, _ := jwtv3.Parse(token, nil)
+		if parsed == nil || parsed.Header["kid"] != key.Id || parsed.Header["alg"] != algorithm {
+			t.Fatalf("expected a %s token with kid %s", algorithm, key.Id)
+		}
+		if _, err := ParseToken(token); err != nil {
+			t.Fatalf("%s token rejected: %v", algorithm, err)
+		}
+		if _, err := VerifyToken(token); err != nil {
+			t.Fatalf("%s token rejected by VerifyToken: %v", algorithm, err)
+		}
+	}
+}
+
+func TestKeySet_Rotation(t *testing.T) {
+	old, next := mustGenerate(t, AlgRS256), mustGenerate(t, AlgEdDSA)
+	installKeys(t, &KeySet{Secret: []byte("secret"), Signing: old, Keys: map[string]*Key{old.Id: old}})
+	legacy := signWith(t, &KeySet{Secret: []byte("secret")})
+	oldToken := SignDocument(uuid.New(), "student")
+
+	installKeys(t, &KeySet{Secret: []byte("secret"), Signing: next, Keys: map[string]*Key{old.Id: old, next.Id: next}})
+	if _, err := ParseToken(oldToken); err != nil {
+		t.Fatalf("token of the previous key should still verify: %v", err)
+	}
+	if _, err := ParseToken(legacy); err != nil {
+		t.Fatalf("HS256 tokens should still verify: %v", err)
+	}
+
+	// once the old key is dropped its tokens are rejected
+	installKeys(t, &KeySet{Secret: []byte("secret"), Signing: next, Keys: map[string]*Key{next.Id: next}})
+	if _, err := ParseToken(oldToken); err == nil {
+		t.Fatalf("expected token of an unknown kid to be rejected")
+	}
+}
+
+func TestKeySet_RejectsAlgorithmConfusion(t *testing.T) {
+	key := mustGenerate(t, AlgRS256)
+	installKeys(t, &KeySet{Secret: []byte("secret"), Signing: key, Keys: map[string]*Key{key.Id: key}})
+
+	// an HS256 token naming the RSA key, signed with the secret
+	token := jwtv3.NewWithClaims(jwtv3.SigningMethodHS256, NewCustomClaims(uuid.New(), "admin"))
+	token.Header["kid"] = key.Id
+	signed, err := token.SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	if _, err := ParseToken(signed); err == nil {
+		t.Fatalf("expected HS256 token with an RSA kid to be rejected")
+	}
+
+	// an RS256 token without kid
+	token = jwtv3.NewWithClaims(jwtv3.SigningMethodRS256, NewCustomClaims(uuid.New(), "admin"))
+	signed, err = token.SignedString(key.Private)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	if _, err := ParseToken(signed); err == nil {
+		t.Fatalf("expected RS256 token without kid to be rejected")
+	}
+}
+
+func TestKeySet_JWKS(t *testing.T) {
+	rsaKey, edKey := mustGenerate(t, AlgRS256), mustGenerate(t, AlgEdDSA)
+	set := (&KeySet{Secret: []byte("secret"), Keys: map[string]*Key{rsaKey.Id: rsaKey, edKey.Id: edKey}}).JWKS()
+	if len(set.Keys) != 2 {
+		t.Fatalf("expected 2 keys, got %d", len(set.Keys))
+	}
+	for _, jwk := range set.Keys {
+		switch jwk.Kid {
+		case rsaKey.Id:
+			if jwk.Kty != "RSA" || jwk.Alg != AlgRS256 || jwk.N == "" || jwk.E != "AQAB" {
+				t.Fatalf("unexpected RSA JWK %+v", jwk)
+			}
+		case edKey.Id:
+			if jwk.Kty != "OKP" || jwk.Crv != "Ed25519" || jwk.Alg != AlgEdDSA || jwk.X == "" {
+				t.Fatalf("unexpected Ed25519 JWK %+v", jwk)
+			}
+		default:
+			t.Fatalf("unexpected kid %s", jwk.Kid)
+		}
+	}
+}
+
+func TestMarshalAndParsePrivateKey(t *testing.T) {
+	for _, algorithm := range []string{AlgRS256, AlgEdDSA} {
+		key := mustGenerate(t, algorithm)
+		data, err := MarshalPrivateKey(key)
+		if err != nil {
+			t.Fatalf("marshal: %v", err)
+		}
+		parsed, err := ParsePrivateKey(key.Id, algorithm, data)
+		if err != nil {
+			t.Fatalf("parse: %v", err)
+		}
+		if parsed.JWK() != key.JWK() {
+			t.Fatalf("round trip changed the %s key", algorithm)
+		}
+	}
+	key := mustGenerate(t, AlgRS256)
+	data, _ := MarshalPrivateKey(key)
+	if _, err := ParsePrivateKey(key.Id, AlgEdDSA, data); err == nil {
+		t.Fatalf("expected an RSA key declared as EdDSA to be rejected")
+	}
+}
+
+func signWith(t *testing.T, keys *KeySet) string {
+	t.Helper()
+	token, err := keys.Sign(NewCustomClaims(uuid.New(), "student"))
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	return token
+}
@@ -1,35 +1,105 @@
 package jwt
 
 import (
-	"github.com/Guidotss/ucc-soft-arch-golang.git/src/config"
+	"errors"
+	"time"
+
 	"github.com/golang-jwt/jwt"
 	"github.com/google/uuid"
 )
 
+// DefaultAccessTokenTTL is used when ACCESS_TOKEN_TTL is not configured.
+const DefaultAccessTokenTTL = 15 * time.Minute
+
 type CustomClaims struct {
-	Id   uuid.UUID `json:"id"`
-	Role int       `json:"role"`
+	Id        uuid.UUID `json:"id"`
+	Role      string    `json:"role"`
+	Version   int       `json:"ver,omitempty"`
+	TokenId   string    `json:"jti,omitempty"`
+	SessionId string    `json:"sid,omitempty"`
+	IssuedAt  int64     `json:"iat,omitempty"`
+	ExpiresAt int64     `json:"exp,omitempty"`
+	// Actor is set on impersonation tokens: the token acts as Id but was
+	// requested by Actor.Subject (RFC 8693 "act" claim).
+	Actor *Actor `json:"act,omitempty"`
+	// APIKeyId and Scopes are only set when the request was authenticated
+	// with an API key. They are never part of a signed token.
+	APIKeyId uuid.UUID `json:"-"`
+	Scopes   []string  `json:"-"`
+}
+
+type Actor struct {
+	Subject uuid.UUID `json:"sub"`
+}
+
+// IsImpersonation reports whether the token was minted for an admin acting
+// as the user.
+func (c *CustomClaims) IsImpersonation() bool {
+	return c.Actor != nil
+}
+
+// IsAPIKey reports whether the claims come from an API key instead of a
+// user session.
+func (c *CustomClaims) IsAPIKey() bool {
+	return c.APIKeyId != uuid.Nil
 }
 
+// Valid rejects tokens without an expiration and tokens that already expired.
 func (c *CustomClaims) Valid() error {
+	if c.ExpiresAt == 0 {
+		return errors.New("token has no expiration")
+	}
+	if time.Now().Unix() >= c.ExpiresAt {
+		return errors.New("token is expired")
+	}
 	return nil
 }
 
-func NewCustomClaims(id uuid.UUID, role int) *CustomClaims {
+// ExpiresAtTime returns the expiration claim as a time.Time.
+func (c *CustomClaims) ExpiresAtTime() time.Time {
+	return time.Unix(c.ExpiresAt, 0)
+}
+
+func NewCustomClaims(id uuid.UUID, role string) *CustomClaims {
+	now := time.Now()
 	return &CustomClaims{
-		Id:   id,
-		Role: role,
+		Id:        id,
+		Role:      role,
+		TokenId:   uuid.NewString(),
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(AccessTokenTTL()).Unix(),
 	}
 }
 
-func SignDocument(id uuid.UUID, role int) string {
-	claims := NewCustomClaims(id, role)
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	envs := config.LoadEnvs(".env")
-	secret := []byte(envs.Get("JWT_SECRET"))
-	signedToken, err := token.SignedString(secret)
+// AccessTokenTTL returns how long a signed access token stays valid.
+func AccessTokenTTL() time.Duration {
+	return loadSettings().accessTokenTTL
+}
+
+func SignDocument(id uuid.UUID, role string) string {
+	return SignClaims(NewCustomClaims(id, role))
+}
+
+// SignClaims signs an already built set of claims with the current keys.
+func SignClaims(claims *CustomClaims) string {
+	signedToken, err := CurrentKeys().Sign(claims)
 	if err != nil {
 		return ""
 	}
 	return signedToken
 }
+
+// Sign signs claims with the signing key of the set, stamping its kid, or
+// with HS256 when there is none.
+func (s *KeySet) Sign(claims jwt.Claims) (string, error) {
+	if s.Signing == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.Secret)
+	}
+	method := jwt.GetSigningMethod(s.Signing.Algorithm)
+	if method == nil {
+		return "", errors.New("unsupported signing algorithm " + s.Signing.Algorithm)
+	}
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = s.Signing.Id
+	return token.SignedString(s.Signing.Private)
+}
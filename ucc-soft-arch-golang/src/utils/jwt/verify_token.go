@@ -3,26 +3,37 @@ package jwt
 import (
 	"fmt"
 
-	"github.com/Guidotss/ucc-soft-arch-golang.git/src/config"
+	jwtv3 "github.com/golang-jwt/jwt"
 	"github.com/golang-jwt/jwt/v5"
 )
 
 func VerifyToken(tokenString string) (map[string]interface{}, error) {
+	keys := CurrentKeys()
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		envs := config.LoadEnvs(".env")
-		return []byte(envs.Get("JWT_SECRET")), nil
-	})
-
+		return keys.verificationKey(token.Method.Alg(), token.Header)
+	}, jwt.WithExpirationRequired())
 	if err != nil {
 		return nil, err
 	}
-
 	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
 		return claims, nil
 	}
-
 	return nil, fmt.Errorf("invalid token")
 }
+
+// ParseToken verifies the signature and expiration of an access token and
+// returns its typed claims.
+func ParseToken(tokenString string) (*CustomClaims, error) {
+	keys := CurrentKeys()
+	claims := &CustomClaims{}
+	token, err := jwtv3.ParseWithClaims(tokenString, claims, func(token *jwtv3.Token) (interface{}, error) {
+		return keys.verificationKey(token.Method.Alg(), token.Header)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, fmt.Errorf("invalid token")
+	}
+	return claims, nil
+}
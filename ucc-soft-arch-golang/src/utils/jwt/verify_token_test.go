@@ -2,13 +2,14 @@ package jwt
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 func TestSignAndVerifyToken_Success(t *testing.T) {
 	id := uuid.New()
-	role := 2
+	role := "instructor"
 	token := SignDocument(id, role)
 	if token == "" {
 		t.Fatalf("expected non-empty token")
@@ -28,3 +29,53 @@ func TestVerifyToken_InvalidToken(t *testing.T) {
 		t.Fatalf("expected error for invalid token")
 	}
 }
+
+func TestParseToken_RejectsExpiredToken(t *testing.T) {
+	claims := NewCustomClaims(uuid.New(), "student")
+	claims.ExpiresAt = time.Now().Add(-time.Minute).Unix()
+	token := SignClaims(claims)
+	if _, err := ParseToken(token); err == nil {
+		t.Fatalf("expected error for expired token")
+	}
+	if _, err := VerifyToken(token); err == nil {
+		t.Fatalf("expected VerifyToken to reject expired token")
+	}
+}
+
+func TestParseToken_RejectsTokenWithoutExpiration(t *testing.T) {
+	claims := NewCustomClaims(uuid.New(), "student")
+	claims.ExpiresAt = 0
+	token := SignClaims(claims)
+	if _, err := ParseToken(token); err == nil {
+		t.Fatalf("expected error for token without exp")
+	}
+}
+
+func TestSignDocument_SetsStandardClaims(t *testing.T) {
+	token := SignDocument(uuid.New(), "admin")
+	claims, err := ParseToken(token)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if claims.TokenId == "" || claims.IssuedAt == 0 || claims.ExpiresAt <= claims.IssuedAt {
+		t.Fatalf("expected jti, iat and exp to be set, got %+v", claims)
+	}
+}
+
+func TestParseToken_KeepsActorClaim(t *testing.T) {
+	actor := uuid.New()
+	claims := NewCustomClaims(uuid.New(), "student")
+	claims.Actor = &Actor{Subject: actor}
+	parsed, err := ParseToken(SignClaims(claims))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !parsed.IsImpersonation() || parsed.Actor.Subject != actor {
+		t.Fatalf("expected act claim for %s, got %+v", actor, parsed.Actor)
+	}
+
+	parsed, _ = ParseToken(SignDocument(uuid.New(), "student"))
+	if parsed.IsImpersonation() {
+		t.Fatalf("regular tokens have no act claim")
+	}
+}
@@ -0,0 +1,31 @@
+package mailer
+
+import (
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/config"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers transactional emails (password resets, verifications...).
+type Mailer interface {
+	Send(message Message) error
+}
+
+// NewFromEnv returns an SMTP mailer when SMTP_HOST is configured and a
+// FileMailer otherwise, so local development and tests never send real mail.
+func NewFromEnv(envs config.Envs) Mailer {
+	if envs.Get("SMTP_HOST") != "" {
+		return NewSMTPMailer(
+			envs.Get("SMTP_HOST"),
+			envs.Get("SMTP_PORT"),
+			envs.Get("SMTP_USER"),
+			envs.Get("SMTP_PASSWORD"),
+			envs.Get("SMTP_FROM"),
+		)
+	}
+	return NewFileMailer(envs.Get("MAIL_DIR"))
+}
@@ -0,0 +1,64 @@
+package mailer
+
+import (
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/config"
+)
+
+func TestFileMailer_WritesMessage(t *testing.T) {
+	dir := t.TempDir()
+	m := NewFileMailer(dir)
+	if err := m.Send(Message{To: "a@ex.com", Subject: "Hi", Body: "hello"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if len(files) != 1 {
+		t.Fatalf("expected one message file, got %d", len(files))
+	}
+	content, _ := os.ReadFile(files[0])
+	if !strings.Contains(string(content), "Subject: Hi") || !strings.Contains(string(content), "hello") {
+		t.Fatalf("unexpected message content: %s", content)
+	}
+}
+
+func TestFileMailer_LogsWithoutDir(t *testing.T) {
+	if err := NewFileMailer("").Send(Message{To: "a@ex.com"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestSMTPMailer_Send(t *testing.T) {
+	m := NewSMTPMailer("smtp.example.com", "", "user", "pw", "")
+	var gotAddr string
+	var gotMsg []byte
+	m.send = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		gotAddr, gotMsg = addr, msg
+		return nil
+	}
+	if err := m.Send(Message{To: "b@ex.com", Subject: "Reset", Body: "link"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotAddr != "smtp.example.com:587" {
+		t.Fatalf("unexpected addr %s", gotAddr)
+	}
+	if !strings.Contains(string(gotMsg), "To: b@ex.com") || !strings.Contains(string(gotMsg), "From: user") {
+		t.Fatalf("unexpected message: %s", gotMsg)
+	}
+}
+
+func TestNewFromEnv(t *testing.T) {
+	envs := config.LoadEnvs()
+	t.Setenv("SMTP_HOST", "")
+	if _, ok := NewFromEnv(envs).(*FileMailer); !ok {
+		t.Fatalf("expected FileMailer without SMTP_HOST")
+	}
+	t.Setenv("SMTP_HOST", "smtp.example.com")
+	if _, ok := NewFromEnv(envs).(*SMTPMailer); !ok {
+		t.Fatalf("expected SMTPMailer with SMTP_HOST")
+	}
+}
@@ -0,0 +1,53 @@
+package mailer
+
+import (
+	"fmt"
+	"net/smtp"
+	"strings"
+)
+
+type SMTPMailer struct {
+	host     string
+	port     string
+	user     string
+	password string
+	from     string
+	// send is swapped in tests; defaults to smtp.SendMail
+	send func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+func NewSMTPMailer(host, port, user, password, from string) *SMTPMailer {
+	if port == "" {
+		port = "587"
+	}
+	if from == "" {
+		from = user
+	}
+	return &SMTPMailer{
+		host:     host,
+		port:     port,
+		user:     user,
+		password: password,
+		from:     from,
+		send:     smtp.SendMail,
+	}
+}
+
+func (m *SMTPMailer) Send(message Message) error {
+	var auth smtp.Auth
+	if m.user != "" {
+		auth = smtp.PlainAuth("", m.user, m.password, m.host)
+	}
+	return m.send(m.host+":"+m.port, auth, m.from, []string{message.To}, m.build(message))
+}
+
+func (m *SMTPMailer) build(message Message) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", m.from)
+	fmt.Fprintf(&b, "To: %s\r\n", message.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", message.Subject)
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n\r\n")
+	b.WriteString(message.Body)
+	return []byte(b.String())
+}
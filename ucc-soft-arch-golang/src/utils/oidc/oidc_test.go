@@ -0,0 +1,69 @@
+package oidc_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/oidc"
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/utils/oidc/oidctest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodeChallenge_RFC7636Example(t *testing.T) {
+	require.Equal(t, "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM",
+		oidc.CodeChallenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"))
+}
+
+func TestProvider_AuthorizationCodeFlow(t *testing.T) {
+	server := oidctest.NewServer("client-1")
+	defer server.Close()
+	provider := oidc.NewProvider(server.Config("http://localhost:3000/auth/oidc/callback"))
+
+	verifier, err := oidc.NewCodeVerifier()
+	require.NoError(t, err)
+	authURL, err := provider.AuthCodeURL("state-1", "nonce-1", oidc.CodeChallenge(verifier))
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(authURL, server.URL+"/authorize?"))
+
+	code, state, err := server.Authorize(authURL, oidctest.User{Subject: "sub-1", Email: "a@uni.edu", EmailVerified: true})
+	require.NoError(t, err)
+	require.Equal(t, "state-1", state)
+
+	// a wrong verifier is rejected by the provider
+	_, err = provider.Exchange(code, "not-the-verifier")
+	require.Error(t, err)
+
+	code, _, err = server.Authorize(authURL, oidctest.User{Subject: "sub-1", Email: "a@uni.edu", EmailVerified: true})
+	require.NoError(t, err)
+	idToken, err := provider.Exchange(code, verifier)
+	require.NoError(t, err)
+
+	_, err = provider.VerifyIDToken(idToken, "other-nonce")
+	require.Error(t, err)
+
+	claims, err := provider.VerifyIDToken(idToken, "nonce-1")
+	require.NoError(t, err)
+	require.Equal(t, "sub-1", claims.Subject)
+	require.Equal(t, "a@uni.edu", claims.Email)
+	require.True(t, claims.EmailVerified)
+}
+
+func TestProvider_RejectsOtherAudience(t *testing.T) {
+	server := oidctest.NewServer("client-1")
+	defer server.Close()
+	redirect := "http://localhost:3000/auth/oidc/callback"
+	provider := oidc.NewProvider(server.Config(redirect))
+
+	verifier, _ := oidc.NewCodeVerifier()
+	authURL, err := provider.AuthCodeURL("s", "n", oidc.CodeChallenge(verifier))
+	require.NoError(t, err)
+	code, _, err := server.Authorize(authURL, oidctest.User{Subject: "sub"})
+	require.NoError(t, err)
+	idToken, err := provider.Exchange(code, verifier)
+	require.NoError(t, err)
+
+	cfg := server.Config(redirect)
+	cfg.ClientId = "someone-else"
+	_, err = oidc.NewProvider(cfg).VerifyIDToken(idToken, "n")
+	require.Error(t, err)
+}
@@ -0,0 +1,52 @@
+package password
+
+import (
+	"bufio"
+	_ "embed"
+	"io"
+	"os"
+	"strings"
+)
+
+//go:embed common_passwords.txt
+var commonPasswords string
+
+// Blocklist holds passwords that must not be used, compared case
+// insensitively.
+type Blocklist map[string]struct{}
+
+// DefaultBlocklist returns the embedded list of common passwords.
+func DefaultBlocklist() Blocklist {
+	list := Blocklist{}
+	list.read(strings.NewReader(commonPasswords))
+	return list
+}
+
+// LoadFile adds the passwords in path, one per line; lines starting with #
+// are comments.
+func (b Blocklist) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return b.read(f)
+}
+
+// Contains reports whether password is on the list.
+func (b Blocklist) Contains(password string) bool {
+	_, found := b[strings.ToLower(password)]
+	return found
+}
+
+func (b Blocklist) read(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		b[strings.ToLower(line)] = struct{}{}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,106 @@
+package password
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/Guidotss/ucc-soft-arch-golang.git/src/config"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Supported hash algorithms. New hashes use the configured one; Compare
+// accepts both so hashes made before a switch keep working.
+const (
+	AlgArgon2id = "argon2id"
+	AlgBcrypt   = "bcrypt"
+)
+
+// Config picks the algorithm new passwords are hashed with.
+type Config struct {
+	Algorithm  string
+	Argon2     Argon2Params
+	BcryptCost int
+}
+
+var (
+	loadConfig = sync.OnceValue(func() Config {
+		envs := config.LoadEnvs(".env")
+		algorithm := strings.ToLower(envs.Get("PASSWORD_HASH_ALGORITHM"))
+		if algorithm == "" {
+			algorithm = AlgArgon2id
+		}
+		params := DefaultArgon2Params
+		params.Memory = uint32(config.GetInt(envs, "ARGON2_MEMORY_KIB", int(params.Memory)))
+		params.Iterations = uint32(config.GetInt(envs, "ARGON2_ITERATIONS", int(params.Iterations)))
+		params.Parallelism = uint8(config.GetInt(envs, "ARGON2_PARALLELISM", int(params.Parallelism)))
+		return Config{
+			Algorithm:  algorithm,
+			Argon2:     params,
+			BcryptCost: config.GetInt(envs, "BCRYPT_COST", bcrypt.DefaultCost),
+		}
+	})
+	current atomic.Pointer[Config]
+)
+
+// CurrentConfig returns the installed config, read from the environment
+// unless SetConfig was called.
+func CurrentConfig() Config {
+	if c := current.Load(); c != nil {
+		return *c
+	}
+	return loadConfig()
+}
+
+// SetConfig replaces the config read from the environment.
+func SetConfig(c Config) {
+	current.Store(&c)
+}
+
+// Hash hashes a password with the configured algorithm.
+func Hash(password string) (string, error) {
+	c := CurrentConfig()
+	switch c.Algorithm {
+	case AlgArgon2id:
+		return hashArgon2(password, c.Argon2)
+	case AlgBcrypt:
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), c.BcryptCost)
+		if err != nil {
+			return "", err
+		}
+		return string(hash), nil
+	default:
+		return "", fmt.Errorf("unsupported password hash algorithm %q", c.Algorithm)
+	}
+}
+
+// Compare reports whether password matches an argon2id or bcrypt hash.
+func Compare(password string, hash string) bool {
+	if strings.HasPrefix(hash, argon2Prefix) {
+		return compareArgon2(password, hash)
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// NeedsRehash reports whether a hash was made with another algorithm or
+// weaker parameters than the configured ones. Call it after a successful
+// Compare to upgrade the stored hash.
+func NeedsRehash(hash string) bool {
+	c := CurrentConfig()
+	switch c.Algorithm {
+	case AlgArgon2id:
+		p, _, _, err := decodeArgon2(hash)
+		if err != nil {
+			return true
+		}
+		want := c.Argon2
+		return p.Memory < want.Memory || p.Iterations < want.Iterations ||
+			p.Parallelism != want.Parallelism || p.KeyLength < want.KeyLength
+	case AlgBcrypt:
+		cost, err := bcrypt.Cost([]byte(hash))
+		return err != nil || cost < c.BcryptCost
+	default:
+		return false
+	}
+}
@@ -0,0 +1,78 @@
+package password
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// cheap parameters keep the tests fast
+var testArgon2 = Argon2Params{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func TestHashAndCompare_Argon2id(t *testing.T) {
+	SetConfig(Config{Algorithm: AlgArgon2id, Argon2: testArgon2, BcryptCost: bcrypt.MinCost})
+	hash, err := Hash("S3cret!")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$"))
+	require.True(t, Compare("S3cret!", hash))
+	require.False(t, Compare("wrong", hash))
+	require.False(t, NeedsRehash(hash))
+
+	other, err := Hash("S3cret!")
+	require.NoError(t, err)
+	require.NotEqual(t, hash, other, "every hash gets its own salt")
+}
+
+func TestCompare_AcceptsBcryptHashes(t *testing.T) {
+	SetConfig(Config{Algorithm: AlgArgon2id, Argon2: testArgon2, BcryptCost: bcrypt.MinCost})
+	legacy, err := bcrypt.GenerateFromPassword([]byte("S3cret!"), bcrypt.MinCost)
+	require.NoError(t, err)
+	require.True(t, Compare("S3cret!", string(legacy)))
+	require.False(t, Compare("wrong", string(legacy)))
+	require.True(t, NeedsRehash(string(legacy)))
+}
+
+func TestNeedsRehash_WeakerParams(t *testing.T) {
+	SetConfig(Config{Algorithm: AlgArgon2id, Argon2: testArgon2})
+	hash, err := Hash("S3cret!")
+	require.NoError(t, err)
+
+	stronger := testArgon2
+	stronger.Iterations = 2
+	SetConfig(Config{Algorithm: AlgArgon2id, Argon2: stronger})
+	require.True(t, NeedsRehash(hash))
+	require.True(t, Compare("S3cret!", hash), "old parameters still verify")
+}
+
+func TestHash_Bcrypt(t *testing.T) {
+	SetConfig(Config{Algorithm: AlgBcrypt, BcryptCost: bcrypt.MinCost})
+	hash, err := Hash("S3cret!")
+	require.NoError(t, err)
+	require.True(t, Compare("S3cret!", hash))
+	require.False(t, NeedsRehash(hash))
+
+	SetConfig(Config{Algorithm: AlgBcrypt, BcryptCost: bcrypt.MinCost + 1})
+	require.True(t, NeedsRehash(hash))
+}
+
+func TestCompare_MalformedArgon2Hash(t *testing.T) {
+	require.False(t, Compare("x", "$argon2id$v=19$m=1,t=1,p=1$!!$!!"))
+	require.False(t, Compare("x", "$argon2id$garbage"))
+}
+
+func TestBlocklist(t *testing.T) {
+	list := DefaultBlocklist()
+	require.True(t, list.Contains("password123"))
+	require.True(t, list.Contains("PassWord123"))
+	require.False(t, list.Contains("correct horse battery staple"))
+	require.False(t, list.Contains("# Most common passwords from public breach compilations, lower case."))
+
+	path := t.TempDir() + "/extra.txt"
+	require.NoError(t, os.WriteFile(path, []byte("# extra\nHunter2Hunter2\n"), 0o600))
+	require.NoError(t, list.LoadFile(path))
+	require.True(t, list.Contains("hunter2hunter2"))
+	require.Error(t, list.LoadFile(path+".missing"))
+}
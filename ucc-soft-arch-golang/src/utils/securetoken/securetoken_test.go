@@ -0,0 +1,40 @@
+package securetoken
+
+import "testing"
+
+func TestGenerate_ReturnsDistinctTokens(t *testing.T) {
+	a, err := Generate(DefaultSize)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b, _ := Generate(DefaultSize)
+	if a == "" || a == b {
+		t.Fatalf("expected two distinct non-empty tokens")
+	}
+}
+
+func TestHash_IsDeterministic(t *testing.T) {
+	if Hash("abc") != Hash("abc") {
+		t.Fatalf("expected same hash for same input")
+	}
+	if Hash("abc") == Hash("abd") {
+		t.Fatalf("expected different hashes for different inputs")
+	}
+	if len(Hash("abc")) != 64 {
+		t.Fatalf("expected hex encoded sha256")
+	}
+}
+
+func TestSealAndOpen(t *testing.T) {
+	sealed, err := Seal("secret", []byte("private key"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	opened, err := Open("secret", sealed)
+	if err != nil || string(opened) != "private key" {
+		t.Fatalf("expected the original value back, got %q (%v)", opened, err)
+	}
+	if _, err := Open("other secret", sealed); err == nil {
+		t.Fatalf("expected a wrong secret to fail")
+	}
+}
@@ -0,0 +1,57 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// rfcSecret is the SHA-1 key of the RFC 6238 test vectors.
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestCodeAt_RFC6238Vectors(t *testing.T) {
+	// the RFC lists 8 digit codes, we keep the last 6
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+	for unix, want := range vectors {
+		got, err := CodeAt(rfcSecret, Step(time.Unix(unix, 0)))
+		require.NoError(t, err)
+		require.Equal(t, want, got, "time %d", unix)
+	}
+}
+
+func TestValidate_AcceptsSkewAndReturnsStep(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	previous, _ := CodeAt(rfcSecret, Step(now)-1)
+
+	step, ok := Validate(rfcSecret, previous, now)
+	require.True(t, ok)
+	require.Equal(t, Step(now)-1, step)
+
+	tooOld, _ := CodeAt(rfcSecret, Step(now)-3)
+	_, ok = Validate(rfcSecret, tooOld, now)
+	require.False(t, ok)
+
+	_, ok = Validate(rfcSecret, "12345", now)
+	require.False(t, ok)
+}
+
+func TestGenerateSecretAndURI(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+	require.Len(t, secret, 32)
+
+	uri := ProvisioningURI("UCC Cursos", "a@b.com", secret)
+	require.True(t, strings.HasPrefix(uri, "otpauth://totp/UCC%20Cursos:a@b.com?"))
+	require.Contains(t, uri, "secret="+secret)
+	require.Contains(t, uri, "issuer=UCC%20Cursos")
+}